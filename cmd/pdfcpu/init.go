@@ -25,8 +25,11 @@ import (
 func initCommandMap() {
 	annotsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"list":   {processListAnnotationsCommand, nil, "", ""},
-		"remove": {processRemoveAnnotationsCommand, nil, "", ""},
+		"check":      {processCheckAnnotationsCommand, nil, "", ""},
+		"clamp":      {processClampAnnotationsCommand, nil, "", ""},
+		"duplicates": {processDuplicateAnnotationsCommand, nil, "", ""},
+		"list":       {processListAnnotationsCommand, nil, "", ""},
+		"remove":     {processRemoveAnnotationsCommand, nil, "", ""},
 	} {
 		annotsCmdMap.register(k, v)
 	}
@@ -53,29 +56,45 @@ func initCommandMap() {
 	fontsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
 		"cheatsheet": {processCreateCheatSheetFontsCommand, nil, "", ""},
+		"embed":      {processEmbedFontsCommand, nil, "", ""},
+		"glyphs":     {processListMissingGlyphsCommand, nil, "", ""},
 		"install":    {processInstallFontsCommand, nil, "", ""},
 		"list":       {processListFontsCommand, nil, "", ""},
+		"measure":    {processMeasureTextCommand, nil, "", ""},
+		"tounicode":  {processRepairToUnicodeCommand, nil, "", ""},
+		"type3":      {processConvertType3FontsCommand, nil, "", ""},
 	} {
 		fontsCmdMap.register(k, v)
 	}
 
 	formCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"list":      {processListFormFieldsCommand, nil, "", ""},
-		"remove":    {processRemoveFormFieldsCommand, nil, "", ""},
-		"lock":      {processLockFormCommand, nil, "", ""},
-		"unlock":    {processUnlockFormCommand, nil, "", ""},
-		"reset":     {processResetFormCommand, nil, "", ""},
-		"export":    {processExportFormCommand, nil, "", ""},
-		"fill":      {processFillFormCommand, nil, "", ""},
-		"multifill": {processMultiFillFormCommand, nil, "", ""},
+		"list":        {processListFormFieldsCommand, nil, "", ""},
+		"remove":      {processRemoveFormFieldsCommand, nil, "", ""},
+		"lock":        {processLockFormCommand, nil, "", ""},
+		"unlock":      {processUnlockFormCommand, nil, "", ""},
+		"reset":       {processResetFormCommand, nil, "", ""},
+		"export":      {processExportFormCommand, nil, "", ""},
+		"fill":        {processFillFormCommand, nil, "", ""},
+		"multifill":   {processMultiFillFormCommand, nil, "", ""},
+		"resources":   {processListFormDefaultResourceIssuesCommand, nil, "", ""},
+		"repair":      {processRepairFormDefaultResourcesCommand, nil, "", ""},
+		"appearances": {processRegenerateAppearancesCommand, nil, "", ""},
+		"calc":        {processListCalculationOrderCommand, nil, "", ""},
+		"setcalc":     {processSetCalculationOrderCommand, nil, "", ""},
+		"actions":     {processListFieldActionsCommand, nil, "", ""},
+		"flatten":     {processFlattenFormCommand, nil, "", ""},
+		"positions":   {processExportFieldPositionsCommand, nil, "", ""},
 	} {
 		formCmdMap.register(k, v)
 	}
 
 	imagesCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"list": {processListImagesCommand, nil, "", ""},
+		"check":    {processCheckImagesCommand, nil, "", ""},
+		"list":     {processListImagesCommand, nil, "", ""},
+		"pixelate": {processPixelateCommand, nil, "", ""},
+		"dpi":      {processListResolutionsCommand, nil, "", ""},
 	} {
 		imagesCmdMap.register(k, v)
 	}
@@ -91,16 +110,31 @@ func initCommandMap() {
 
 	pagesCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"insert": {processInsertPagesCommand, nil, "", ""},
-		"remove": {processRemovePagesCommand, nil, "", ""},
+		"insert":    {processInsertPagesCommand, nil, "", ""},
+		"remove":    {processRemovePagesCommand, nil, "", ""},
+		"repair":    {processRepairPagesCommand, nil, "", ""},
+		"hash":      {processHashPagesCommand, nil, "", ""},
+		"empty":     {processDegeneratePagesCommand, nil, "", ""},
+		"oversized": {processOversizedPagesCommand, nil, "", ""},
+		"template":  {processFillTemplateCommand, nil, "", ""},
+		"contents":  {processSplitContentCommand, nil, "", ""},
+		"rotation":  {processPageRotationsCommand, nil, "", ""},
+		"count":     {processCountPagesCommand, nil, "", ""},
+		"truncate":  {processTruncatePagesCommand, nil, "", ""},
+		"resources": {processShareResourcesCommand, nil, "", ""},
+		"operators": {processListNonStandardOperatorsCommand, nil, "", ""},
+		"scanned":   {processCheckScannedCommand, nil, "", ""},
+		"tile":      {processTilePageCommand, nil, "", ""},
+		"number":    {processAddPageNumbersCommand, nil, "", ""},
 	} {
 		pagesCmdMap.register(k, v)
 	}
 
 	permissionsCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"list": {processListPermissionsCommand, nil, "", ""},
-		"set":  {processSetPermissionsCommand, nil, "", ""},
+		"list":  {processListPermissionsCommand, nil, "", ""},
+		"set":   {processSetPermissionsCommand, nil, "", ""},
+		"clear": {processClearRestrictionsCommand, nil, "", ""},
 	} {
 		permissionsCmdMap.register(k, v)
 	}
@@ -126,39 +160,92 @@ func initCommandMap() {
 
 	stampCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"add":    {processAddStampsCommand, nil, "", ""},
-		"remove": {processRemoveStampsCommand, nil, "", ""},
-		"update": {processUpdateStampsCommand, nil, "", ""},
+		"add":     {processAddStampsCommand, nil, "", ""},
+		"recolor": {processRecolorStampsCommand, nil, "", ""},
+		"remove":  {processRemoveStampsCommand, nil, "", ""},
+		"update":  {processUpdateStampsCommand, nil, "", ""},
 	} {
 		stampCmdMap.register(k, v)
 	}
 
+	timestampCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"prepare": {processPrepareTimeStampCommand, nil, "", ""},
+		"embed":   {processEmbedTimeStampCommand, nil, "", ""},
+	} {
+		timestampCmdMap.register(k, v)
+	}
+
+	viewerPrefCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"list":  {processListViewerPreferencesCommand, nil, "", ""},
+		"print": {processSetPrintPreferencesCommand, nil, "", ""},
+	} {
+		viewerPrefCmdMap.register(k, v)
+	}
+
 	watermarkCmdMap := newCommandMap()
 	for k, v := range map[string]command{
-		"add":    {processAddWatermarksCommand, nil, "", ""},
-		"remove": {processRemoveWatermarksCommand, nil, "", ""},
-		"update": {processUpdateWatermarksCommand, nil, "", ""},
+		"add":     {processAddWatermarksCommand, nil, "", ""},
+		"recolor": {processRecolorWatermarksCommand, nil, "", ""},
+		"remove":  {processRemoveWatermarksCommand, nil, "", ""},
+		"update":  {processUpdateWatermarksCommand, nil, "", ""},
 	} {
 		watermarkCmdMap.register(k, v)
 	}
 
+	destinationsCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"list":    {processListBrokenDestinationsCommand, nil, "", ""},
+		"fix":     {processFixBrokenDestinationsCommand, nil, "", ""},
+		"convert": {processConvertDestinationsCommand, nil, "", ""},
+	} {
+		destinationsCmdMap.register(k, v)
+	}
+
+	referencesCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"list": {processListDanglingReferencesCommand, nil, "", ""},
+		"fix":  {processNullDanglingReferencesCommand, nil, "", ""},
+	} {
+		referencesCmdMap.register(k, v)
+	}
+
+	structureCmdMap := newCommandMap()
+	for k, v := range map[string]command{
+		"order":    {processListReadingOrderIssuesCommand, nil, "", ""},
+		"tag":      {processAutoTagCommand, nil, "", ""},
+		"lang":     {processSetStructLangCommand, nil, "", ""},
+		"decor":    {processListArtifactCandidatesCommand, nil, "", ""},
+		"artifact": {processTagArtifactsCommand, nil, "", ""},
+		"strip":    {processStripStructureCommand, nil, "", ""},
+	} {
+		structureCmdMap.register(k, v)
+	}
+
 	cmdMap = newCommandMap()
 
 	for k, v := range map[string]command{
 		"annotations":   {nil, annotsCmdMap, usageAnnots, usageLongAnnots},
+		"assemble":      {processAssembleCommand, nil, usageAssemble, usageLongAssemble},
 		"attachments":   {nil, attachCmdMap, usageAttach, usageLongAttach},
 		"booklet":       {processBookletCommand, nil, usageBooklet, usageLongBooklet},
 		"boxes":         {nil, boxesCmdMap, usageBoxes, usageLongBoxes},
 		"changeopw":     {processChangeOwnerPasswordCommand, nil, usageChangeOwnerPW, usageLongChangeOwnerPW},
 		"changeupw":     {processChangeUserPasswordCommand, nil, usageChangeUserPW, usageLongChangeUserPW},
 		"collect":       {processCollectCommand, nil, usageCollect, usageLongCollect},
+		"compact":       {processGarbageCollectCommand, nil, usageCompact, usageLongCompact},
 		"config":        {printConfiguration, nil, usageConfig, usageLongConfig},
 		"create":        {processCreateCommand, nil, usageCreate, usageLongCreate},
 		"crop":          {processCropCommand, nil, usageCrop, usageLongCrop},
 		"decrypt":       {processDecryptCommand, nil, usageDecrypt, usageLongDecrypt},
+		"destinations":  {nil, destinationsCmdMap, usageDestinations, usageLongDestinations},
+		"dss":           {processAddDSSCommand, nil, usageDSS, usageLongDSS},
 		"dump":          {processDumpCommand, nil, "", ""},
 		"encrypt":       {processEncryptCommand, nil, usageEncrypt, usageLongEncrypt},
+		"estimate":      {processEstimateCostCommand, nil, usageEstimate, usageLongEstimate},
 		"extract":       {processExtractCommand, nil, usageExtract, usageLongExtract},
+		"features":      {processListVersionFeaturesCommand, nil, usageFeatures, usageLongFeatures},
 		"fonts":         {nil, fontsCmdMap, usageFonts, usageLongFonts},
 		"form":          {nil, formCmdMap, usageForm, usageLongForm},
 		"grid":          {processGridCommand, nil, usageGrid, usageLongGrid},
@@ -168,6 +255,7 @@ func initCommandMap() {
 		"info":          {processInfoCommand, nil, usageInfo, usageLongInfo},
 		"keywords":      {nil, keywordsCmdMap, usageKeywords, usageLongKeywords},
 		"merge":         {processMergeCommand, nil, usageMerge, usageLongMerge},
+		"normalize":     {processNormalizeRotationCommand, nil, usageNormalize, usageLongNormalize},
 		"nup":           {processNUpCommand, nil, usageNUp, usageLongNUp},
 		"optimize":      {processOptimizeCommand, nil, usageOptimize, usageLongOptimize},
 		"pages":         {nil, pagesCmdMap, usagePages, usageLongPages},
@@ -175,13 +263,20 @@ func initCommandMap() {
 		"permissions":   {nil, permissionsCmdMap, usagePerm, usageLongPerm},
 		"portfolio":     {nil, portfolioCmdMap, usagePortfolio, usageLongPortfolio},
 		"properties":    {nil, propertiesCmdMap, usageProperties, usageLongProperties},
+		"redact":        {processRedactCommand, nil, usageRedact, usageLongRedact},
+		"references":    {nil, referencesCmdMap, usageReferences, usageLongReferences},
 		"resize":        {processResizeCommand, nil, usageResize, usageLongResize},
 		"rotate":        {processRotateCommand, nil, usageRotate, usageLongRotate},
 		"selectedpages": {printSelectedPages, nil, usageSelectedPages, usageLongSelectedPages},
 		"split":         {processSplitCommand, nil, usageSplit, usageLongSplit},
 		"stamp":         {nil, stampCmdMap, usageStamp, usageLongStamp},
+		"structure":     {nil, structureCmdMap, usageStructure, usageLongStructure},
+		"timestamp":     {nil, timestampCmdMap, usageTimeStamp, usageLongTimeStamp},
+		"thumbnail":     {processSetXMPThumbnailCommand, nil, usageThumbnail, usageLongThumbnail},
 		"trim":          {processTrimCommand, nil, usageTrim, usageLongTrim},
+		"unsign":        {processUnsignCommand, nil, usageUnsign, usageLongUnsign},
 		"validate":      {processValidateCommand, nil, usageValidate, usageLongValidate},
+		"viewerpref":    {nil, viewerPrefCmdMap, usageViewerPref, usageLongViewerPref},
 		"watermark":     {nil, watermarkCmdMap, usageWatermark, usageLongWatermark},
 		"version":       {printVersion, nil, usageVersion, usageLongVersion},
 	} {
@@ -60,6 +60,14 @@ func ensureJSONExtension(filename string) {
 	}
 }
 
+func ensureManifestExtension(filename string) {
+	s := strings.ToLower(filename)
+	if !hasJSONExtension(s) && !strings.HasSuffix(s, ".yaml") && !strings.HasSuffix(s, ".yml") {
+		fmt.Fprintf(os.Stderr, "%s needs extension \".json\", \".yaml\" or \".yml\".\n", filename)
+		os.Exit(1)
+	}
+}
+
 func hasCSVExtension(filename string) bool {
 	return strings.HasSuffix(strings.ToLower(filename), ".csv")
 }
@@ -217,6 +225,26 @@ func processOptimizeCommand(conf *model.Configuration) {
 	process(cli.OptimizeCommand(inFile, outFile, conf))
 }
 
+func processGarbageCollectCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageCompact)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.GarbageCollectCommand(inFile, outFile, conf))
+}
+
 func processSplitCommand(conf *model.Configuration) {
 	if mode == "" {
 		mode = "span"
@@ -255,7 +283,7 @@ func processMergeCommand(conf *model.Configuration) {
 	if mode == "" {
 		mode = "create"
 	}
-	mode = extractModeCompletion(mode, []string{"create", "append"})
+	mode = extractModeCompletion(mode, []string{"create", "append", "select"})
 	if mode == "" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageMerge)
 		os.Exit(1)
@@ -266,6 +294,11 @@ func processMergeCommand(conf *model.Configuration) {
 		os.Exit(1)
 	}
 
+	if mode == "select" {
+		processMergeSelectedCommand(conf)
+		return
+	}
+
 	filesIn := []string{}
 	outFile := ""
 	for i, arg := range flag.Args() {
@@ -311,6 +344,42 @@ func processMergeCommand(conf *model.Configuration) {
 	process(cmd)
 }
 
+func processMergeSelectedCommand(conf *model.Configuration) {
+	outFile := flag.Arg(0)
+	ensurePDFExtension(outFile)
+
+	sels := []model.MergeSelection{}
+
+	for _, arg := range flag.Args()[1:] {
+		if arg == outFile {
+			fmt.Fprintf(os.Stderr, "%s may appear as inFile or outFile only\n", outFile)
+			os.Exit(1)
+		}
+		if hasPDFExtension(arg) {
+			sels = append(sels, model.MergeSelection{InFile: arg})
+			continue
+		}
+		// Page selection for the preceding inFile.
+		if len(sels) == 0 || sels[len(sels)-1].PageSelection != nil {
+			fmt.Fprintf(os.Stderr, "%s\n\n", usageMerge)
+			os.Exit(1)
+		}
+		pages, err := api.ParsePageSelection(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "problem with page selection %s: %v\n", arg, err)
+			os.Exit(1)
+		}
+		sels[len(sels)-1].PageSelection = pages
+	}
+
+	if len(sels) == 0 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageMerge)
+		os.Exit(1)
+	}
+
+	process(cli.MergeSelectedCommand(sels, outFile, conf))
+}
+
 func extractModeCompletion(modePrefix string, modes []string) string {
 	var modeStr string
 	for _, mode := range modes {
@@ -326,7 +395,9 @@ func extractModeCompletion(modePrefix string, modes []string) string {
 }
 
 func processExtractCommand(conf *model.Configuration) {
-	mode = extractModeCompletion(mode, []string{"image", "font", "page", "content", "meta"})
+	if mode != "icc" {
+		mode = extractModeCompletion(mode, []string{"image", "font", "page", "content", "meta", "eps", "text", "bundle"})
+	}
 	if len(flag.Args()) != 2 || mode == "" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageExtract)
 		os.Exit(1)
@@ -363,6 +434,18 @@ func processExtractCommand(conf *model.Configuration) {
 	case "meta":
 		cmd = cli.ExtractMetadataCommand(inFile, outDir, conf)
 
+	case "eps":
+		cmd = cli.ExtractEPSCommand(inFile, outDir, pages, conf)
+
+	case "icc":
+		cmd = cli.ExtractICCProfilesCommand(inFile, outDir, conf)
+
+	case "text":
+		cmd = cli.ExtractParagraphsCommand(inFile, outDir, pages, conf)
+
+	case "bundle":
+		cmd = cli.ExtractBundleCommand(inFile, outDir, conf)
+
 	default:
 		fmt.Fprintf(os.Stderr, "unknown extract mode: %s\n", mode)
 		os.Exit(1)
@@ -372,6 +455,32 @@ func processExtractCommand(conf *model.Configuration) {
 	process(cmd)
 }
 
+func processNormalizeRotationCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageNormalize)
+		os.Exit(1)
+	}
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := ""
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.NormalizeRotationCommand(inFile, outFile, pages, conf))
+}
+
 func processTrimCommand(conf *model.Configuration) {
 	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages == "" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageTrim)
@@ -581,6 +690,26 @@ func processSetPermissionsCommand(conf *model.Configuration) {
 	process(cli.SetPermissionsCommand(inFile, "", conf))
 }
 
+func processClearRestrictionsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usagePermClear)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.ClearRestrictionsCommand(inFile, outFile, conf))
+}
+
 func processDecryptCommand(conf *model.Configuration) {
 	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
 		fmt.Fprintf(os.Stderr, "%s\n\n", usageDecrypt)
@@ -917,6 +1046,56 @@ func processRemoveWatermarksCommand(conf *model.Configuration) {
 	removeWatermarks(conf, false)
 }
 
+func parseUnitFloat(s, name string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || f > 1 {
+		fmt.Fprintf(os.Stderr, "%s must be a number between 0.0 and 1.0: %s\n", name, s)
+		os.Exit(1)
+	}
+	return f
+}
+
+func recolorWatermarks(conf *model.Configuration, onTop bool) {
+	if len(flag.Args()) < 3 || len(flag.Args()) > 4 {
+		s := usageWatermarkRecolor
+		if onTop {
+			s = usageStampRecolor
+		}
+		fmt.Fprintf(os.Stderr, "%s\n\n", s)
+		os.Exit(1)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v", err)
+		os.Exit(1)
+	}
+
+	gray := parseUnitFloat(flag.Arg(0), "gray")
+	opacity := parseUnitFloat(flag.Arg(1), "opacity")
+
+	inFile := flag.Arg(2)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := ""
+	if len(flag.Args()) == 4 {
+		outFile = flag.Arg(3)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.RecolorWatermarksCommand(inFile, outFile, selectedPages, gray, opacity, conf))
+}
+
+func processRecolorStampsCommand(conf *model.Configuration) {
+	recolorWatermarks(conf, true)
+}
+
+func processRecolorWatermarksCommand(conf *model.Configuration) {
+	recolorWatermarks(conf, false)
+}
+
 func ensureImageExtension(filename string) {
 	if !model.ImageFileName(filename) {
 		fmt.Fprintf(os.Stderr, "%s needs an image extension (.jpg, .jpeg, .png, .tif, .tiff, .webp)\n", filename)
@@ -1040,16 +1219,9 @@ func processRemovePagesCommand(conf *model.Configuration) {
 	process(cli.RemovePagesCommand(inFile, outFile, pages, conf))
 }
 
-func abs(i int) int {
-	if i < 0 {
-		return -i
-	}
-	return i
-}
-
-func processRotateCommand(conf *model.Configuration) {
-	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
-		fmt.Fprintf(os.Stderr, "%s\n\n", usageRotate)
+func processHashPagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesHash)
 		os.Exit(1)
 	}
 
@@ -1058,107 +1230,364 @@ func processRotateCommand(conf *model.Configuration) {
 		ensurePDFExtension(inFile)
 	}
 
-	rotation, err := strconv.Atoi(flag.Arg(1))
-	if err != nil || abs(rotation)%90 > 0 {
-		fmt.Fprintf(os.Stderr, "rotation must be a multiple of 90: %s\n", flag.Arg(1))
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
 		os.Exit(1)
 	}
 
-	outFile := ""
-	if len(flag.Args()) == 3 {
-		outFile = flag.Arg(2)
+	process(cli.HashPagesCommand(inFile, pages, conf))
+}
+
+func processRepairPagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesRepair)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
 		ensurePDFExtension(outFile)
 	}
 
-	selectedPages, err := api.ParsePageSelection(selectedPages)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+	process(cli.RepairPagesCommand(inFile, outFile, conf))
+}
+
+func processDegeneratePagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesEmpty)
 		os.Exit(1)
 	}
 
-	process(cli.RotateCommand(inFile, outFile, rotation, selectedPages, conf))
-}
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
 
-func parseAfterNUpDetails(nup *model.NUp, argInd int, filenameOut string) []string {
-	if nup.PageGrid {
-		cols, err := strconv.Atoi(flag.Arg(argInd))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(1)
-		}
-		rows, err := strconv.Atoi(flag.Arg(argInd + 1))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(1)
-		}
-		if err = pdfcpu.ParseNUpGridDefinition(cols, rows, nup); err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(1)
-		}
-		argInd += 2
-	} else {
-		n, err := strconv.Atoi(flag.Arg(argInd))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
+	switch mode {
+
+	case "", "list":
+		if len(flag.Args()) > 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesEmpty)
 			os.Exit(1)
 		}
-		if err = pdfcpu.ParseNUpValue(n, nup); err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", err)
-			os.Exit(1)
+		process(cli.ListDegeneratePagesCommand(inFile, conf))
+
+	case "remove":
+		outFile := inFile
+		if len(flag.Args()) == 2 {
+			outFile = flag.Arg(1)
+			ensurePDFExtension(outFile)
 		}
-		argInd++
+		process(cli.RemoveDegeneratePagesCommand(inFile, outFile, conf))
+
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesEmpty)
+		os.Exit(1)
 	}
+}
 
-	filenameIn := flag.Arg(argInd)
-	if !hasPDFExtension(filenameIn) && !model.ImageFileName(filenameIn) {
-		fmt.Fprintf(os.Stderr, "inFile has to be a PDF or one or a sequence of image files: %s\n", filenameIn)
+func processOversizedPagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesOversized)
 		os.Exit(1)
 	}
 
-	filenamesIn := []string{filenameIn}
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
 
-	if hasPDFExtension(filenameIn) {
-		if len(flag.Args()) > argInd+1 {
-			usage := usageNUp
-			if nup.PageGrid {
-				usage = usageGrid
-			}
-			fmt.Fprintf(os.Stderr, "%s\n\n", usage)
-			os.Exit(1)
-		}
-		if filenameIn == filenameOut {
-			fmt.Fprintln(os.Stderr, "inFile and outFile can't be the same.")
+	switch mode {
+
+	case "", "list":
+		if len(flag.Args()) > 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesOversized)
 			os.Exit(1)
 		}
-	} else {
-		nup.ImgInputFile = true
-		for i := argInd + 1; i < len(flag.Args()); i++ {
-			arg := flag.Args()[i]
-			ensureImageExtension(arg)
-			filenamesIn = append(filenamesIn, arg)
+		process(cli.ListOversizedPagesCommand(inFile, conf))
+
+	case "clamp":
+		outFile := inFile
+		if len(flag.Args()) == 2 {
+			outFile = flag.Arg(1)
+			ensurePDFExtension(outFile)
 		}
-	}
+		process(cli.ClampPagesCommand(inFile, outFile, conf))
 
-	return filenamesIn
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesOversized)
+		os.Exit(1)
+	}
 }
 
-func processNUpCommand(conf *model.Configuration) {
-	if len(flag.Args()) < 3 {
-		fmt.Fprintf(os.Stderr, "%s\n\n", usageNUp)
+func processSplitContentCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesContents)
 		os.Exit(1)
 	}
 
-	processDiplayUnit(conf)
-
-	pages, err := api.ParsePageSelection(selectedPages)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
-		os.Exit(1)
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
 	}
 
-	nup := model.DefaultNUpConfig()
-	nup.InpUnit = conf.Unit
-	argInd := 1
+	switch mode {
+
+	case "", "list":
+		if len(flag.Args()) > 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesContents)
+			os.Exit(1)
+		}
+		process(cli.ListSplitContentCommand(inFile, conf))
+
+	case "fix":
+		outFile := inFile
+		if len(flag.Args()) == 2 {
+			outFile = flag.Arg(1)
+			ensurePDFExtension(outFile)
+		}
+		process(cli.ResplitContentCommand(inFile, outFile, conf))
+
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesContents)
+		os.Exit(1)
+	}
+}
+
+func processFillTemplateCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesTemplate)
+		os.Exit(1)
+	}
+
+	pageNr := 1
+	if selectedPages != "" {
+		i, err := strconv.Atoi(selectedPages)
+		if err != nil || i < 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesTemplate)
+			os.Exit(1)
+		}
+		pageNr = i
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	inFileData := flag.Arg(1)
+	if !hasJSONExtension(inFileData) && !hasCSVExtension(inFileData) {
+		fmt.Fprintf(os.Stderr, "%s needs extension \".json\" or \".csv\".\n", inFileData)
+		os.Exit(1)
+	}
+
+	outFile := flag.Arg(2)
+	ensurePDFExtension(outFile)
+
+	process(cli.FillTemplateCommand(inFile, inFileData, outFile, pageNr, conf))
+}
+
+func processAddPageNumbersCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesNumber)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	pn, err := pdfcpu.ParsePageNumberingDetails(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(1)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.AddPageNumbersCommand(inFile, outFile, pn, conf))
+}
+
+func processTilePageCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 4 || len(flag.Args()) > 5 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesTile)
+		os.Exit(1)
+	}
+
+	pageNr := 1
+	if selectedPages != "" {
+		i, err := strconv.Atoi(selectedPages)
+		if err != nil || i < 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesTile)
+			os.Exit(1)
+		}
+		pageNr = i
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	rows, err := strconv.Atoi(flag.Arg(1))
+	if err != nil || rows < 1 {
+		fmt.Fprintf(os.Stderr, "rows must be a positive integer: %s\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	cols, err := strconv.Atoi(flag.Arg(2))
+	if err != nil || cols < 1 {
+		fmt.Fprintf(os.Stderr, "cols must be a positive integer: %s\n", flag.Arg(2))
+		os.Exit(1)
+	}
+
+	outFile := flag.Arg(3)
+	ensurePDFExtension(outFile)
+
+	var overlap float64
+	if len(flag.Args()) == 5 {
+		if overlap, err = strconv.ParseFloat(flag.Arg(4), 64); err != nil || overlap < 0 {
+			fmt.Fprintf(os.Stderr, "overlap must be a non negative number: %s\n", flag.Arg(4))
+			os.Exit(1)
+		}
+	}
+
+	process(cli.TilePageCommand(inFile, outFile, pageNr, rows, cols, overlap, conf))
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func processRotateCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageRotate)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	rotation, err := strconv.Atoi(flag.Arg(1))
+	if err != nil || abs(rotation)%90 > 0 {
+		fmt.Fprintf(os.Stderr, "rotation must be a multiple of 90: %s\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	outFile := ""
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.RotateCommand(inFile, outFile, rotation, selectedPages, conf))
+}
+
+func parseAfterNUpDetails(nup *model.NUp, argInd int, filenameOut string) []string {
+	if nup.PageGrid {
+		cols, err := strconv.Atoi(flag.Arg(argInd))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		rows, err := strconv.Atoi(flag.Arg(argInd + 1))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		if err = pdfcpu.ParseNUpGridDefinition(cols, rows, nup); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		argInd += 2
+	} else {
+		n, err := strconv.Atoi(flag.Arg(argInd))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		if err = pdfcpu.ParseNUpValue(n, nup); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		argInd++
+	}
+
+	filenameIn := flag.Arg(argInd)
+	if !hasPDFExtension(filenameIn) && !model.ImageFileName(filenameIn) {
+		fmt.Fprintf(os.Stderr, "inFile has to be a PDF or one or a sequence of image files: %s\n", filenameIn)
+		os.Exit(1)
+	}
+
+	filenamesIn := []string{filenameIn}
+
+	if hasPDFExtension(filenameIn) {
+		if len(flag.Args()) > argInd+1 {
+			usage := usageNUp
+			if nup.PageGrid {
+				usage = usageGrid
+			}
+			fmt.Fprintf(os.Stderr, "%s\n\n", usage)
+			os.Exit(1)
+		}
+		if filenameIn == filenameOut {
+			fmt.Fprintln(os.Stderr, "inFile and outFile can't be the same.")
+			os.Exit(1)
+		}
+	} else {
+		nup.ImgInputFile = true
+		for i := argInd + 1; i < len(flag.Args()); i++ {
+			arg := flag.Args()[i]
+			ensureImageExtension(arg)
+			filenamesIn = append(filenamesIn, arg)
+		}
+	}
+
+	return filenamesIn
+}
+
+func processNUpCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 3 {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageNUp)
+		os.Exit(1)
+	}
+
+	processDiplayUnit(conf)
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	nup := model.DefaultNUpConfig()
+	nup.InpUnit = conf.Unit
+	argInd := 1
 
 	outFile := flag.Arg(0)
 	if !hasPDFExtension(outFile) {
@@ -1301,7 +1730,53 @@ func processInfoCommand(conf *model.Configuration) {
 }
 
 func processListFontsCommand(conf *model.Configuration) {
-	process(cli.ListFontsCommand(conf))
+	if len(flag.Args()) > 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFontsList)
+		os.Exit(1)
+	}
+
+	if len(flag.Args()) == 0 {
+		process(cli.ListFontsCommand(conf))
+		return
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListDocumentFontsCommand(inFile, conf))
+}
+
+func processMeasureTextCommand(conf *model.Configuration) {
+	if (len(flag.Args()) != 3 && len(flag.Args()) != 5) || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageFontsMeasure)
+		os.Exit(1)
+	}
+
+	fontName := flag.Arg(0)
+
+	fontSize, err := strconv.Atoi(flag.Arg(1))
+	if err != nil || fontSize <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid font size: %s\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	text := flag.Arg(2)
+
+	var box []float64
+	if len(flag.Args()) == 5 {
+		for _, s := range flag.Args()[3:] {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil || f <= 0 {
+				fmt.Fprintf(os.Stderr, "invalid box dimension: %s\n", s)
+				os.Exit(1)
+			}
+			box = append(box, f)
+		}
+	}
+
+	process(cli.MeasureTextCommand(fontName, fontSize, text, box, conf))
 }
 
 func processInstallFontsCommand(conf *model.Configuration) {
@@ -1323,17 +1798,9 @@ func processInstallFontsCommand(conf *model.Configuration) {
 	process(cli.InstallFontsCommand(fileNames, conf))
 }
 
-func processCreateCheatSheetFontsCommand(conf *model.Configuration) {
-	fileNames := []string{}
-	if len(flag.Args()) > 0 {
-		fileNames = append(fileNames, flag.Args()...)
-	}
-	process(cli.CreateCheatSheetsFontsCommand(fileNames, conf))
-}
-
-func processListKeywordsCommand(conf *model.Configuration) {
-	if len(flag.Args()) != 1 || selectedPages != "" {
-		fmt.Fprintf(os.Stderr, "usage: %s\n", usageKeywordsList)
+func processEmbedFontsCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFontsEmbed)
 		os.Exit(1)
 	}
 
@@ -1341,13 +1808,123 @@ func processListKeywordsCommand(conf *model.Configuration) {
 	if conf.CheckFileNameExt {
 		ensurePDFExtension(inFile)
 	}
-	process(cli.ListKeywordsCommand(inFile, conf))
-}
 
-func processAddKeywordsCommand(conf *model.Configuration) {
-	if len(flag.Args()) < 2 || selectedPages != "" {
-		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageKeywordsAdd)
-		os.Exit(1)
+	outFile := inFile
+	fontFiles := map[string]string{}
+
+	for i := 1; i < len(flag.Args()); i++ {
+		arg := flag.Arg(i)
+		if i == 1 && hasPDFExtension(arg) {
+			outFile = arg
+			continue
+		}
+		j := strings.Index(arg, "=")
+		if j <= 0 || j == len(arg)-1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFontsEmbed)
+			os.Exit(1)
+		}
+		fontFiles[arg[:j]] = arg[j+1:]
+	}
+
+	if len(fontFiles) == 0 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFontsEmbed)
+		os.Exit(1)
+	}
+
+	process(cli.EmbedFontsCommand(inFile, outFile, fontFiles, conf))
+}
+
+func processListMissingGlyphsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageFontsGlyphs)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ListMissingGlyphsCommand(inFile, selectedPages, conf))
+}
+
+func processRepairToUnicodeCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageFontsToUnicode)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.RepairToUnicodeCommand(inFile, outFile, conf))
+}
+
+func processConvertType3FontsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageFontsType3)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ConvertType3FontsCommand(inFile, outFile, pages, conf))
+}
+
+func processCreateCheatSheetFontsCommand(conf *model.Configuration) {
+	fileNames := []string{}
+	if len(flag.Args()) > 0 {
+		fileNames = append(fileNames, flag.Args()...)
+	}
+	process(cli.CreateCheatSheetsFontsCommand(fileNames, conf))
+}
+
+func processListKeywordsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageKeywordsList)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+	process(cli.ListKeywordsCommand(inFile, conf))
+}
+
+func processAddKeywordsCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageKeywordsAdd)
+		os.Exit(1)
 	}
 
 	var inFile string
@@ -1653,6 +2230,102 @@ func processListAnnotationsCommand(conf *model.Configuration) {
 
 	process(cli.ListAnnotationsCommand(inFile, selectedPages, conf))
 }
+func processCheckAnnotationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsCheck)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.CheckAnnotationsCommand(inFile, selectedPages, conf))
+}
+
+func processClampAnnotationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsClamp)
+		os.Exit(1)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	inFile, outFile := "", ""
+
+	var annotTypes []string
+
+	for i, arg := range flag.Args() {
+		if i == 0 {
+			inFile = arg
+			if conf.CheckFileNameExt {
+				ensurePDFExtension(inFile)
+			}
+			continue
+		}
+		if i == 1 {
+			if hasPDFExtension(arg) {
+				outFile = arg
+				continue
+			}
+		}
+		annotTypes = append(annotTypes, arg)
+	}
+
+	process(cli.ClampAnnotationsCommand(inFile, outFile, selectedPages, annotTypes, conf))
+}
+
+func processDuplicateAnnotationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsDuplicates)
+		os.Exit(1)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	switch mode {
+
+	case "", "list":
+		if len(flag.Args()) > 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsDuplicates)
+			os.Exit(1)
+		}
+		process(cli.ListDuplicateAnnotationsCommand(inFile, selectedPages, conf))
+
+	case "remove":
+		outFile := inFile
+		if len(flag.Args()) == 2 {
+			outFile = flag.Arg(1)
+			ensurePDFExtension(outFile)
+		}
+		process(cli.RemoveDuplicateAnnotationsCommand(inFile, outFile, selectedPages, conf))
+
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsDuplicates)
+		os.Exit(1)
+	}
+}
+
 func processRemoveAnnotationsCommand(conf *model.Configuration) {
 	if len(flag.Args()) < 1 {
 		fmt.Fprintf(os.Stderr, "usage: %s\n", usageAnnotsRemove)
@@ -1783,6 +2456,21 @@ func processCreateCommand(conf *model.Configuration) {
 	process(cli.CreateCommand(inFileJSON, inFile, outFile, conf))
 }
 
+func processAssembleCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageAssemble)
+		os.Exit(1)
+	}
+
+	manifestFile := flag.Arg(0)
+	ensureManifestExtension(manifestFile)
+
+	outFile := flag.Arg(1)
+	ensurePDFExtension(outFile)
+
+	process(cli.AssembleCommand(manifestFile, outFile, conf))
+}
+
 func processListFormFieldsCommand(conf *model.Configuration) {
 	if len(flag.Args()) < 1 || selectedPages != "" {
 		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormListFields)
@@ -2056,3 +2744,849 @@ func processResizeCommand(conf *model.Configuration) {
 
 	process(cli.ResizeCommand(inFile, outFile, selectedPages, rc, conf))
 }
+
+func processAddDSSCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageDSS)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.AddDSSCommand(inFile, flag.Args()[1:], conf))
+}
+
+func processListViewerPreferencesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageViewerPrefList)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListViewerPreferencesCommand(inFile, conf))
+}
+
+func processSetPrintPreferencesCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageViewerPrefPrint)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	pp, err := pdfcpu.ParsePrintPreferences(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.SetPrintPreferencesCommand(inFile, outFile, pp, conf))
+}
+
+func processPrepareTimeStampCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageTimeStampPrepare)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.PrepareTimeStampCommand(inFile, outFile, conf))
+}
+
+func processEmbedTimeStampCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageTimeStampEmbed)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.EmbedTimeStampCommand(inFile, flag.Arg(1), conf))
+}
+
+func processSetXMPThumbnailCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageThumbnail)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	imageFile := flag.Arg(1)
+	if !model.ImageFileName(imageFile) {
+		fmt.Fprintf(os.Stderr, "%s is not an image file\n", imageFile)
+		os.Exit(1)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.SetXMPThumbnailCommand(inFile, imageFile, outFile, conf))
+}
+
+func processUnsignCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageUnsign)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.UnsignCommand(inFile, outFile, conf))
+}
+
+func processListBrokenDestinationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageDestinationsList)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListBrokenDestinationsCommand(inFile, conf))
+}
+
+func processFixBrokenDestinationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageDestinationsFix)
+		os.Exit(1)
+	}
+
+	var remove bool
+
+	switch mode {
+	case "", "redirect":
+	case "remove":
+		remove = true
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageDestinationsFix)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.FixBrokenDestinationsCommand(inFile, outFile, remove, conf))
+}
+
+func processConvertDestinationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageDestinationsConvert)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	switch mode {
+	case "", "explicit":
+		process(cli.InlineNamedDestinationsCommand(inFile, outFile, conf))
+	case "named":
+		process(cli.NameExplicitDestinationsCommand(inFile, outFile, conf))
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageDestinationsConvert)
+		os.Exit(1)
+	}
+}
+
+func processListReadingOrderIssuesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureOrder)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListReadingOrderIssuesCommand(inFile, conf))
+}
+
+func processAutoTagCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureTag)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.AutoTagCommand(inFile, outFile, conf))
+}
+
+func processRedactCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageRedact)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	inFileJSON := flag.Arg(1)
+	ensureJSONExtension(inFileJSON)
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.RedactCommand(inFile, inFileJSON, outFile, conf))
+}
+
+func parseRects(s string, u types.DisplayUnit) ([]types.Rectangle, error) {
+	var rr []types.Rectangle
+	for _, s := range strings.SplitAfter(s, "]") {
+		if s = strings.Trim(strings.TrimSpace(s), ","); s == "" {
+			continue
+		}
+		b, err := model.ParseBox(strings.TrimSpace(s), u)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil || b.Rect == nil {
+			return nil, errors.Errorf("pdfcpu: invalid rect: %s", s)
+		}
+		rr = append(rr, *b.Rect)
+	}
+	if len(rr) == 0 {
+		return nil, errors.New("pdfcpu: missing rects")
+	}
+	return rr, nil
+}
+
+func processPixelateCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 4 || len(flag.Args()) > 5 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageImagesPixelate)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	pageNr, err := strconv.Atoi(flag.Arg(1))
+	if err != nil || pageNr < 1 {
+		fmt.Fprintf(os.Stderr, "invalid page number: %s\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	rects, err := parseRects(flag.Arg(3), conf.Unit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 5 {
+		outFile = flag.Arg(4)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.PixelateCommand(inFile, outFile, pageNr, flag.Arg(2), rects, conf))
+}
+
+func processSetStructLangCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 3 || len(flag.Args()) > 4 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureLang)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	var sel pdfcpu.StructElementSelection
+
+	if s := strings.TrimSpace(flag.Arg(2)); strings.HasPrefix(s, "[") {
+		rects, err := parseRects(s, conf.Unit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		pageNr, err := strconv.Atoi(selectedPages)
+		if err != nil || pageNr < 1 {
+			fmt.Fprintf(os.Stderr, "please provide a single page number for rect: -p page\n")
+			os.Exit(1)
+		}
+		sel.PageNr, sel.Region = pageNr, &rects[0]
+	} else {
+		objNr, err := strconv.Atoi(s)
+		if err != nil || objNr < 1 || selectedPages != "" {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureLang)
+			os.Exit(1)
+		}
+		sel.ObjNr = objNr
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 4 {
+		outFile = flag.Arg(3)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.SetStructLangCommand(inFile, outFile, sel, flag.Arg(1), conf))
+}
+
+func processPageRotationsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesRotation)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	switch mode {
+
+	case "", "list":
+		if len(flag.Args()) > 1 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesRotation)
+			os.Exit(1)
+		}
+		process(cli.ListPageRotationsCommand(inFile, conf))
+
+	case "fix":
+		outFile := inFile
+		if len(flag.Args()) == 2 {
+			outFile = flag.Arg(1)
+			ensurePDFExtension(outFile)
+		}
+		process(cli.NormalizeRotateInheritanceCommand(inFile, outFile, conf))
+
+	default:
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesRotation)
+		os.Exit(1)
+	}
+}
+
+func processListDanglingReferencesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageReferencesList)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListDanglingReferencesCommand(inFile, conf))
+}
+
+func processNullDanglingReferencesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageReferencesFix)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.NullDanglingReferencesCommand(inFile, outFile, conf))
+}
+
+func processCountPagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesCount)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.CountPagesCommand(inFile, conf))
+}
+
+func processTruncatePagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesTruncate)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	maxPages, err := strconv.Atoi(flag.Arg(1))
+	if err != nil || maxPages < 1 {
+		fmt.Fprintf(os.Stderr, "maxPages must be a positive integer: %s\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.TruncatePagesCommand(inFile, outFile, maxPages, conf))
+}
+
+func processListVersionFeaturesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageFeatures)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListVersionFeaturesCommand(inFile, conf))
+}
+
+func processEstimateCostCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "%s\n\n", usageEstimate)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.EstimateCostCommand(inFile, conf))
+}
+
+func processShareResourcesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesResources)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.ShareResourcesCommand(inFile, outFile, conf))
+}
+
+func processListNonStandardOperatorsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesOperators)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ListNonStandardOperatorsCommand(inFile, pages, conf))
+}
+
+func processCheckScannedCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usagePagesScanned)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.CheckScannedCommand(inFile, conf))
+}
+
+func processListArtifactCandidatesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureDecor)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	pages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ListArtifactCandidatesCommand(inFile, pages, conf))
+}
+
+func processTagArtifactsCommand(conf *model.Configuration) {
+	if len(flag.Args()) < 2 || len(flag.Args()) > 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureArtifact)
+		os.Exit(1)
+	}
+
+	pageNr, err := strconv.Atoi(selectedPages)
+	if err != nil || pageNr < 1 {
+		fmt.Fprintf(os.Stderr, "please provide a single page number: -p page\n")
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	rr, err := pdfcpu.ParseArtifactRanges(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 3 {
+		outFile = flag.Arg(2)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.TagArtifactsCommand(inFile, outFile, pageNr, rr, conf))
+}
+
+func processListFormDefaultResourceIssuesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormResources)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListFormDefaultResourceIssuesCommand(inFile, conf))
+}
+
+func processRepairFormDefaultResourcesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormRepair)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.RepairFormDefaultResourcesCommand(inFile, outFile, conf))
+}
+
+func processRegenerateAppearancesCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormAppearances)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.RegenerateAppearancesCommand(inFile, outFile, conf))
+}
+
+func processListCalculationOrderCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormCalc)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListCalculationOrderCommand(inFile, conf))
+}
+
+func processSetCalculationOrderCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormSetCalc)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	var fieldIDs []string
+	outFile := inFile
+
+	if len(flag.Args()) > 1 {
+		s := flag.Arg(1)
+		if hasPDFExtension(s) {
+			outFile = s
+		} else {
+			fieldIDs = append(fieldIDs, s)
+		}
+	}
+
+	if len(flag.Args()) > 2 {
+		for i := 2; i < len(flag.Args()); i++ {
+			fieldIDs = append(fieldIDs, flag.Arg(i))
+		}
+	}
+
+	process(cli.SetCalculationOrderCommand(inFile, outFile, fieldIDs, conf))
+}
+
+func processListFieldActionsCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormActions)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	process(cli.ListFieldActionsCommand(inFile, conf))
+}
+
+func processFlattenFormCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormFlatten)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile, outFileJSON := inFile, ""
+
+	for i := 1; i < len(flag.Args()); i++ {
+		s := flag.Arg(i)
+		if hasJSONExtension(s) {
+			outFileJSON = s
+			continue
+		}
+		ensurePDFExtension(s)
+		outFile = s
+	}
+
+	if outFileJSON == "" {
+		outFileJSON = strings.TrimSuffix(outFile, filepath.Ext(outFile)) + ".json"
+	}
+
+	process(cli.FlattenFormCommand(inFile, outFile, outFileJSON, conf))
+}
+
+func processStripStructureCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageStructureStrip)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	outFile := inFile
+	if len(flag.Args()) == 2 {
+		outFile = flag.Arg(1)
+		ensurePDFExtension(outFile)
+	}
+
+	process(cli.StripStructureCommand(inFile, outFile, conf))
+}
+
+func processCheckImagesCommand(conf *model.Configuration) {
+	if len(flag.Args()) != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageImagesCheck)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.CheckImagesCommand(inFile, selectedPages, conf))
+}
+
+func processListResolutionsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s\n", usageImagesDPI)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	var minDPI float64
+	if len(flag.Args()) == 2 {
+		f, err := strconv.ParseFloat(flag.Arg(1), 64)
+		if err != nil || f <= 0 {
+			fmt.Fprintf(os.Stderr, "minDPI must be a positive number: %s\n", flag.Arg(1))
+			os.Exit(1)
+		}
+		minDPI = f
+	}
+
+	selectedPages, err := api.ParsePageSelection(selectedPages)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "problem with flag selectedPages: %v\n", err)
+		os.Exit(1)
+	}
+
+	process(cli.ListResolutionsCommand(inFile, selectedPages, minDPI, conf))
+}
+
+func processExportFieldPositionsCommand(conf *model.Configuration) {
+	if len(flag.Args()) == 0 || len(flag.Args()) > 3 || selectedPages != "" {
+		fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormPositions)
+		os.Exit(1)
+	}
+
+	inFile := flag.Arg(0)
+	if conf.CheckFileNameExt {
+		ensurePDFExtension(inFile)
+	}
+
+	var dpi int
+	outFileJSON := "out.json"
+
+	for _, arg := range flag.Args()[1:] {
+		if hasJSONExtension(arg) {
+			outFileJSON = arg
+			continue
+		}
+		i, err := strconv.Atoi(arg)
+		if err != nil || i <= 0 {
+			fmt.Fprintf(os.Stderr, "usage: %s\n\n", usageFormPositions)
+			os.Exit(1)
+		}
+		dpi = i
+	}
+
+	process(cli.ExportFieldPositionsCommand(inFile, outFileJSON, dpi, conf))
+}
@@ -26,41 +26,55 @@ Usage:
 The commands are:
 
    annotations   list, remove page annotations
+   assemble      create PDF from a JSON or YAML manifest
    attachments   list, add, remove, extract embedded file attachments
    booklet       arrange pages onto larger sheets of paper to make a booklet or zine
    boxes         list, add, remove page boundaries for selected pages
    changeopw     change owner password
    changeupw     change user password
    collect       create custom sequence of selected pages
+   compact       remove unreferenced objects without optimizing
    config        print configuration
    create        create PDF content including forms via JSON
    crop          set cropbox for selected pages
    decrypt       remove password protection
+   destinations  list, fix broken destinations, convert named and explicit destinations
+   dss           add validation material for long-term signature validation
    encrypt       set password protection		
-   extract       extract images, fonts, content, pages or metadata
+   estimate      report metrics predictive of processing cost
+   extract       extract images, fonts, content, pages, metadata or ICC profiles
+   features      report version gated features used vs declared version
    fonts         install, list supported fonts, create cheat sheets
    form          list, remove fields, lock, unlock, reset, export, fill form via JSON or CSV
    grid          rearrange pages or images for enhanced browsing experience
-   images        list images for selected pages
+   images        list images for selected pages, pixelate image regions
    import        import/convert images to PDF
    info          print file info
    keywords      list, add, remove keywords
    merge         concatenate PDFs
+   normalize     bake page rotation into page content
    nup           rearrange pages or images for reduced number of pages
    optimize      optimize PDF by getting rid of redundant page resources
-   pages         insert, remove selected pages
+   pages         insert, remove, repair, hash, empty, oversized, template, contents pages
    paper         print list of supported paper sizes
    permissions   list, set user access permissions
    portfolio     list, add, remove, extract portfolio entries with optional description
    properties    list, add, remove document properties
+   redact        remove content within areas listed in a JSON manifest
+   references    list dangling references, replace them with null
    resize        scale selected pages
    rotate        rotate selected pages
    selectedpages print definition of the -pages flag
    split         split up a PDF by span or bookmark
    stamp         add, remove, update Unicode text, image or PDF stamps for selected pages
+   structure     check the structure tree of tagged PDFs
+   timestamp     prepare, embed RFC 3161 document timestamps
+   thumbnail     set the XMP document thumbnail
    trim          create trimmed version of selected pages
+   unsign        remove all signatures and signature fields
    validate      validate PDF against PDF 32000-1:2008 (PDF 1.7)
    version       print version
+   viewerpref    list viewer preferences, set print preferences
    watermark     add, remove, update Unicode text, image or PDF watermarks for selected pages
 
    All instantly recognizable command prefixes are supported eg. val for validation
@@ -100,8 +114,58 @@ relaxed ... (default) like strict but doesn't complain about common seen spec vi
      stats ... appends a stats line to a csv file with information about the usage of root and page entries.
                useful for batch optimization and debugging PDFs.
     inFile ... input pdf file
+   outFile ... output pdf file
+
+Set jpegQuality (1..100) in your config to re-encode gray and RGB JPEG images using this quality.
+Images growing in size by re-encoding are left as is.
+Set canonicalInfoDict in your config to write the document info dict in canonical key order with text values trimmed.`
+
+	usageRedact     = "usage: pdfcpu redact inFile inFileJSON [outFile]" + generalFlags
+	usageLongRedact = `Remove all content of inFile within the areas listed in inFileJSON, cover these areas with black boxes and write the result to outFile.
+
+        inFile ... input pdf file
+    inFileJSON ... input json file listing the areas to be redacted
+       outFile ... output pdf file
+
+An area is a rectangle given by its lower left and upper right corner in user space:
+
+   {
+      "redactions": [
+         {"page": 1, "rects": [[100, 700, 300, 720], [100, 100, 200, 150]]}
+      ]
+   }
+
+Glyphs intersecting an area get removed, paths and images lying entirely within an area get removed,
+images partially covered get their samples within the area cleared.
+Annotations other than widgets intersecting an area get removed.`
+
+	usageCompact     = "usage: pdfcpu compact inFile [outFile]" + generalFlags
+	usageLongCompact = `Read inFile, remove all objects unreachable from the document catalog and the document information dictionary
+and write the result to outFile.
+
+Unlike optimize this leaves the document as is: streams don't get recompressed, duplicate resources remain,
+object numbers are kept and object streams are used only if inFile does.
+
+    inFile ... input pdf file
    outFile ... output pdf file`
 
+	usageEstimate     = "usage: pdfcpu estimate inFile" + generalFlags
+	usageLongEstimate = `Report metrics predictive of the cost of processing inFile:
+file size, page count, object count, image count, total image pixel count and whether inFile is encrypted.
+
+inFile gets scanned without reading stream data, decrypting or validating, so this is cheap even for large files.
+
+    inFile ... input pdf file`
+
+	usageFeatures     = "usage: pdfcpu features inFile" + generalFlags
+	usageLongFeatures = `Report the PDF version gated features used by inFile along with the minimum version they require compared to the declared version:
+object streams, xref streams, transparency, JPX images, 3D annotations, AES-128 and AES-256 encryption.
+
+Under-declared files use features not available in their declared version.
+Over-declared files do not use any of the scanned features requiring their declared version and may be candidates for a downgrade.
+
+    inFile ... input pdf file`
+
 	usageSplit     = "usage: pdfcpu split [-m(ode) span|bookmark] inFile outDir [span]" + generalFlags
 	usageLongSplit = `Generate a set of PDFs for the input file in outDir according to given span value or along bookmarks.
 
@@ -119,20 +183,26 @@ The split modes are:
                    span will be ignored.
                    Assumption: inFile contains an outline dictionary.`
 
-	usageMerge     = "usage: pdfcpu merge [-m(ode) create|append] [-s(ort)] outFile inFile..." + generalFlags
+	usageMerge = "usage: pdfcpu merge [-m(ode) create|append] [-s(ort)] outFile inFile..." +
+		"\n       pdfcpu merge -m(ode) select outFile inFile [pages] [inFile [pages]]..." + generalFlags
 	usageLongMerge = `Concatenate a sequence of PDFs/inFiles into outFile.
 
       mode ... merge mode (defaults to create)
       sort ... sort inFiles by file name
    outFile ... output pdf file
     inFile ... a list of pdf files subject to concatenation.
+     pages ... page sequence of the preceding inFile like for "pdfcpu collect", defaults to all pages.
     
 The merge modes are:
 
     create ... outFile will be created and possibly overwritten (default).
 
     append ... if outFile does not exist, it will be created (like in default mode).
-               if outFile already exists, inFiles will be appended to outFile.`
+               if outFile already exists, inFiles will be appended to outFile.
+
+    select ... like create, merging the selected pages of each inFile in the order given.
+
+    Eg. pdfcpu merge -m select out.pdf a.pdf 2-5 b.pdf 1 c.pdf 10-12`
 
 	usagePageSelection = `'-pages' selects pages for processing and is a comma separated list of expressions:
 
@@ -154,8 +224,8 @@ The merge modes are:
 
         e.g. -3,5,7- or 4-7,!6 or 1-,!5 or odd,n1`
 
-	usageExtract     = "usage: pdfcpu extract -m(ode) i(mage)|f(ont)|c(ontent)|p(age)|m(eta) [-p(ages) selectedPages] inFile outDir" + generalFlags
-	usageLongExtract = `Export inFile's images, fonts, content or pages into outDir.
+	usageExtract     = "usage: pdfcpu extract -m(ode) i(mage)|f(ont)|c(ontent)|p(age)|m(eta)|e(ps)|icc|t(ext)|b(undle) [-p(ages) selectedPages] inFile outDir" + generalFlags
+	usageLongExtract = `Export inFile's images, fonts, content, text, pages or a structured bundle into outDir.
 
       mode ... extraction mode
      pages ... Please refer to "pdfcpu selectedpages"
@@ -169,6 +239,15 @@ The merge modes are:
 content ... extract raw page content
    page ... extract single page PDFs
    meta ... extract all metadata (page selection does not apply)
+    eps ... extract pages as Encapsulated PostScript (no transparency, shadings or patterns)
+    icc ... extract ICC profiles of ICCBased color spaces and output intents (page selection does not apply)
+   text ... extract text organized into paragraphs including bounding boxes as JSON
+            fragmented text runs like one run per glyph get merged into words and lines
+ bundle ... export bundle.json describing metadata, pages, text, annotations, images and fonts
+            plus metadata.xml and the images/ and fonts/ it refers to (page selection does not apply)
+
+Set extractSoftMasks in your config to also extract the soft masks of images as grayscale images named <image id>_smask.
+Set applySoftMasks in your config to combine images and their soft masks into RGBA PNGs with transparency.
    
 `
 
@@ -224,16 +303,22 @@ content ... extract raw page content
            pdfcpu portfolio add test.pdf "test.mp3, Test sound file" "test.mkv, Test video file"
     `
 
-	usagePermList = "pdfcpu permissions list [-upw userpw] [-opw ownerpw] inFile"
-	usagePermSet  = "pdfcpu permissions set [-perm none|print|all] [-upw userpw] -opw ownerpw inFile" + generalFlags
+	usagePermList  = "pdfcpu permissions list [-upw userpw] [-opw ownerpw] inFile"
+	usagePermSet   = "pdfcpu permissions set [-perm none|print|all] [-upw userpw] -opw ownerpw inFile"
+	usagePermClear = "pdfcpu permissions clear [-opw ownerpw] inFile [outFile]" + generalFlags
 
 	usagePerm = "usage: " + usagePermList +
-		"\n       " + usagePermSet
+		"\n       " + usagePermSet +
+		"\n       " + usagePermClear
 
 	usageLongPerm = `Manage user access permissions.
 
       perm ... user access permissions
-    inFile ... input pdf file`
+    inFile ... input pdf file
+   outFile ... output pdf file
+
+clear removes the encryption of a file opening with an empty user password
+together with all its user access restrictions.`
 
 	usageEncrypt     = "usage: pdfcpu encrypt [-m(ode) rc4|aes] [-key 40|128|256] [-perm none|print|all] [-upw userpw] -opw ownerpw inFile [outFile]" + generalFlags
 	usageLongEncrypt = `Setup password protection based on user and owner password.
@@ -333,6 +418,14 @@ content ... extract raw page content
                     
    aligntext:        l|left, c|center, r|right, j|justified (for text watermarks only)
 
+   textbox:          (width height) in given display unit eg. '200 100'
+                     word-wrap text to fit width (for text watermarks only),
+                     points sets the font size, scalefactor does not apply.
+
+   shrinktofit:      reduce font size until text fits the textbox height (on/off, true/false, t/f)
+
+   linespacing:      line height multiplier for text, 0.0 < f, eg. 1.5
+
    fillcolor:        color value to be used when rendering text, see also rendermode
                      for backwards compatibility "color" is also accepted.
    
@@ -379,17 +472,20 @@ All configuration string parameters support completion.
 e.g. "pos:bl, off: 20 5"   "rot:45"                 "op:0.5, sc:0.5 abs, rot:0"
      "d:2"                 "sc:.75 abs, points:48"  "rot:-90, scale:0.75 rel"
      "f:Courier, sc:0.75, str: 0.5 0.0 0.0, rot:20"
+     "textbox:200 100, sh:on, al:j, rot:0, pos:tl"
 
 
 `
 
-	usageStampAdd    = "pdfcpu stamp add    [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
-	usageStampUpdate = "pdfcpu stamp update [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
-	usageStampRemove = "pdfcpu stamp remove [-p(ages) selectedPages] inFile [outFile]" + generalFlags
+	usageStampAdd     = "pdfcpu stamp add    [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
+	usageStampUpdate  = "pdfcpu stamp update [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
+	usageStampRemove  = "pdfcpu stamp remove [-p(ages) selectedPages] inFile [outFile]"
+	usageStampRecolor = "pdfcpu stamp recolor [-p(ages) selectedPages] gray opacity inFile [outFile]" + generalFlags
 
 	usageStamp = "usage: " + usageStampAdd +
 		"\n       " + usageStampUpdate +
-		"\n       " + usageStampRemove
+		"\n       " + usageStampRemove +
+		"\n       " + usageStampRecolor
 
 	usageLongStamp = `Process stamping for selected pages. 
 
@@ -401,18 +497,24 @@ e.g. "pos:bl, off: 20 5"   "rot:45"                 "op:0.5, sc:0.5 abs, rot:0"
        file ... image or pdf file
 description ... fontname, points, position, offset, scalefactor, aligntext, rotation, 
                 diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border
+       gray ... gray level for recoloring: 0.0 (black) .. 1.0 (white)
+    opacity ... opacity for recoloring: 0.0 .. 1.0
      inFile ... input pdf file
     outFile ... output pdf file
 
+Use recolor to render existing stamps in gray for draft printing.
+
 ` + usageStampMode + usageWMDescription
 
-	usageWatermarkAdd    = "pdfcpu watermark add    [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
-	usageWatermarkUpdate = "pdfcpu watermark update [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
-	usageWatermarkRemove = "pdfcpu watermark remove [-p(ages) selectedPages] inFile [outFile]" + generalFlags
+	usageWatermarkAdd     = "pdfcpu watermark add    [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
+	usageWatermarkUpdate  = "pdfcpu watermark update [-p(ages) selectedPages] -m(ode) text|image|pdf -- string|file description inFile [outFile]"
+	usageWatermarkRemove  = "pdfcpu watermark remove [-p(ages) selectedPages] inFile [outFile]"
+	usageWatermarkRecolor = "pdfcpu watermark recolor [-p(ages) selectedPages] gray opacity inFile [outFile]" + generalFlags
 
 	usageWatermark = "usage: " + usageWatermarkAdd +
 		"\n       " + usageWatermarkUpdate +
-		"\n       " + usageWatermarkRemove
+		"\n       " + usageWatermarkRemove +
+		"\n       " + usageWatermarkRecolor
 
 	usageLongWatermark = `Process watermarking for selected pages. 
 
@@ -422,9 +524,13 @@ description ... fontname, points, position, offset, scalefactor, aligntext, rota
        file ... image or pdf file
 description ... fontname, points, position, offset, scalefactor, aligntext, rotation,
                 diagonal, opacity, rendermode, strokecolor, fillcolor, bgcolor, margins, border
+       gray ... gray level for recoloring: 0.0 (black) .. 1.0 (white)
+    opacity ... opacity for recoloring: 0.0 .. 1.0
      inFile ... input pdf file
     outFile ... output pdf file
 
+Use recolor to render existing watermarks in gray for draft printing.
+
 ` + usageWatermarkMode + usageWMDescription
 
 	usageImportImages     = "usage: pdfcpu import -- [description] outFile imageFile..." + generalFlags
@@ -480,18 +586,110 @@ description ... dimensions, format, position, offset, scale factor, boxes
        "f:A4, pos:c, dpi:300"                     ... render the image centered on A4 respecting a destination resolution of 300 dpi.
        `
 
-	usagePagesInsert = "pdfcpu pages insert [-p(ages) selectedPages] [-m(ode) before|after] inFile [outFile]"
-	usagePagesRemove = "pdfcpu pages remove  -p(ages) selectedPages  inFile [outFile]" + generalFlags
+	usagePagesInsert    = "pdfcpu pages insert [-p(ages) selectedPages] [-m(ode) before|after] inFile [outFile]"
+	usagePagesRemove    = "pdfcpu pages remove  -p(ages) selectedPages  inFile [outFile]"
+	usagePagesRepair    = "pdfcpu pages repair inFile [outFile]"
+	usagePagesHash      = "pdfcpu pages hash [-p(ages) selectedPages] inFile" + generalFlags
+	usagePagesEmpty     = "pdfcpu pages empty [-m(ode) list|remove] inFile [outFile]" + generalFlags
+	usagePagesOversized = "pdfcpu pages oversized [-m(ode) list|clamp] inFile [outFile]" + generalFlags
+	usagePagesTemplate  = "pdfcpu pages template [-p(ages) pageNr] inFile inFileData outFile" + generalFlags
+	usagePagesContents  = "pdfcpu pages contents [-m(ode) list|fix] inFile [outFile]" + generalFlags
+	usagePagesRotation  = "pdfcpu pages rotation [-m(ode) list|fix] inFile [outFile]" + generalFlags
+	usagePagesCount     = "pdfcpu pages count inFile" + generalFlags
+	usagePagesTruncate  = "pdfcpu pages truncate inFile maxPages [outFile]" + generalFlags
+	usagePagesResources = "pdfcpu pages resources inFile [outFile]" + generalFlags
+	usagePagesOperators = "pdfcpu pages operators [-p(ages) selectedPages] inFile" + generalFlags
+	usagePagesScanned   = "pdfcpu pages scanned inFile" + generalFlags
+	usagePagesTile      = "pdfcpu pages tile [-p(ages) pageNr] inFile rows cols outFile [overlap]" + generalFlags
+	usagePagesNumber    = "pdfcpu pages number description inFile [outFile]" + generalFlags
 
 	usagePages = "usage: " + usagePagesInsert +
-		"\n       " + usagePagesRemove
+		"\n       " + usagePagesRemove +
+		"\n       " + usagePagesRepair +
+		"\n       " + usagePagesHash +
+		"\n       " + usagePagesEmpty +
+		"\n       " + usagePagesOversized +
+		"\n       " + usagePagesTemplate +
+		"\n       " + usagePagesContents +
+		"\n       " + usagePagesRotation +
+		"\n       " + usagePagesCount +
+		"\n       " + usagePagesTruncate +
+		"\n       " + usagePagesResources +
+		"\n       " + usagePagesOperators +
+		"\n       " + usagePagesScanned +
+		"\n       " + usagePagesTile +
+		"\n       " + usagePagesNumber
 
 	usageLongPages = `Manage pages.
 
-      pages ... Please refer to "pdfcpu selectedpages"
-       mode ... before, after (default: before)
+      pages ... Please refer to "pdfcpu selectedpages", template, tile: the page number (default: 1)
+       mode ... insert: before, after (default: before)
+                empty: list, remove (default: list)
+                oversized: list, clamp (default: list)
+                contents: list, fix (default: list)
+                rotation: list, fix (default: list)
      inFile ... input pdf file
     outFile ... output pdf file
+ inFileData ... json or csv data file for template
+   maxPages ... the number of pages to keep
+  rows cols ... the tile grid
+    overlap ... the overlap of adjacent tiles in points (default: 0)
+description ... number: comma separated configuration string of page numbering and text stamp parameters
+
+   repair ... set missing /Type entries, add a /MediaBox (Letter) to pages without own or inherited media box
+              and an empty /Contents to pages without resolvable content.
+              Kids causing cycles get removed, pages referenced more than once are kept and reported.
+
+     hash ... print a SHA-256 hash over content, resources and page boundaries for each selected page.
+              Hashes ignore object numbers, whitespace and stream compression and may be used to detect changed pages.
+
+    empty ... list or remove degenerate pages: pages with missing, zero or negative media box dimensions
+              and pages without content and annotations.
+
+oversized ... list pages exceeding 14400 user units in width or height or scale them down to fit.
+              Page content, page boundaries and annotations get scaled along.
+
+ template ... write one copy of the template page for each record of inFileData.
+              Text tokens like {{name}} get replaced by the values of the record, following text on the same line gets moved along.
+              Provide JSON as an array of objects or CSV with the token names in the first row.
+              Values need to be covered by the font in use, annotations and form fields are not carried over.
+
+ contents ... list or fix multi part page content streams divided within a token, eg. within an operator or a string.
+              fix moves each division to the preceding token boundary.
+              Set mergeContentStreams in your config to merge the parts into a single content stream instead.
+
+ rotation ... list the rotation in effect for each page resolving /Rotate inheritance along the page tree
+              or fix by setting /Rotate on each page and removing it from intermediate page tree nodes.
+              Values get normalized to 0, 90, 180 or 270, values not a multiple of 90 become 0.
+
+    count ... print the page count as recorded in the page tree root without reading all of inFile if possible.
+              Set maxPages in your config to make all commands reject files with more pages early on.
+
+ truncate ... remove all pages following page maxPages, maxPages in your config is ignored.
+
+resources ... share equal extended graphics states, fonts and color spaces among pages
+              and move equal resource dictionaries of sibling pages up the page tree to be inherited.
+              Reduces the object count of generated files repeating the same resources on every page.
+
+operators ... list content stream operators used by selected pages which are deprecated or not part of the standard,
+              eg. vendor specific extensions which may not render in all viewers, along with the number of occurrences.
+              Page content, form XObjects and annotation appearances get scanned.
+              Occurrences within BX/EX compatibility sections, which viewers are supposed to ignore, are counted separately.
+
+  scanned ... detect a scan wrapped into PDF without OCR: no page shows text and at least one page consists of images only.
+              Prints the share of image only pages as confidence, an invisible OCR text layer counts as text.
+
+     tile ... split a huge page into a grid of rows x cols pages, eg. for tiled rendering of engineering drawings.
+              Each tile page shows its clipped region of the page sharing the original content and resources.
+              Tiles get ordered by rows from top to bottom and within a row from left to right.
+
+   number ... stamp page numbers onto pages, eg. footer page numbers for a document with unnumbered front matter.
+              start: physical page number of the first numbered page (default: 1)
+              first: number displayed on the start page (default: 1)
+               skip: space separated page selection of pages left unnumbered like section dividers, these pages count nevertheless
+               text: %p ... displayed page number, %P ... last displayed page number (default: %p)
+              All other parameters get passed on to the text stamp, see "pdfcpu help stamp" (default: "pos:bc, off:0 20, scale:1 abs, points:10, rot:0").
+              eg. pdfcpu pages number "start:3, skip:5 9, text:Page %p of %P, pos:br, off:-20 20" in.pdf out.pdf
 
 `
 
@@ -503,6 +701,17 @@ description ... dimensions, format, position, offset, scale factor, boxes
    rotation ... a multiple of 90 degrees for clockwise rotation
     outFile ... output pdf file
 
+`
+
+	usageNormalize     = "usage: pdfcpu normalize [-p(ages) selectedPages] inFile [outFile]" + generalFlags
+	usageLongNormalize = `Bake the rotation of selected pages into their content.
+Processed pages have rotation 0 and media boxes reflecting their visual orientation.
+Annotation rectangles and appearances get rotated along.
+
+      pages ... Please refer to "pdfcpu selectedpages"
+     inFile ... input pdf file
+    outFile ... output pdf file
+
 `
 
 	usageNUp     = "usage: pdfcpu nup [-p(ages) selectedPages] -- [description] outFile n inFile|imageFiles..." + generalFlags
@@ -768,16 +977,46 @@ Examples: pdfcpu grid out.pdf 1 10 in.pdf
    pages ... Please refer to "pdfcpu selectedpages"
   inFile ... input pdf file`
 
-	usageFontsList       = "pdfcpu fonts list"
+	usageFontsList       = "pdfcpu fonts list [inFile]"
 	usageFontsInstall    = "pdfcpu fonts install fontFiles..."
 	usageFontsCheatSheet = "pdfcpu fonts cheatsheet fontFiles..."
+	usageFontsEmbed      = "pdfcpu fonts embed inFile [outFile] fontName=fontFile..."
+	usageFontsGlyphs     = "pdfcpu fonts glyphs [-p(ages) selectedPages] inFile"
+	usageFontsToUnicode  = "pdfcpu fonts tounicode inFile [outFile]"
+	usageFontsType3      = "pdfcpu fonts type3 [-p(ages) selectedPages] inFile [outFile]"
+	usageFontsMeasure    = "pdfcpu fonts measure fontName fontSize text [width height]"
 
 	usageFonts = "usage: " + usageFontsList +
 		"\n       " + usageFontsInstall +
-		"\n       " + usageFontsCheatSheet
+		"\n       " + usageFontsCheatSheet +
+		"\n       " + usageFontsEmbed +
+		"\n       " + usageFontsGlyphs +
+		"\n       " + usageFontsToUnicode +
+		"\n       " + usageFontsType3 +
+		"\n       " + usageFontsMeasure
 	usageLongFonts = `Print a list of supported fonts (includes the 14 PDF core fonts).
+Print a list of the fonts used by inFile: subsetted or fully embedded, font program size and distinct glyphs used.
 Install given True Type fonts(.ttf) or True Type collections(.ttc) for usage in stamps/watermarks.
-Create single page PDF cheat sheets in current dir.`
+Create single page PDF cheat sheets in current dir.
+Embed True Type fonts(.ttf, .otf) into matching non embedded fonts, subsetted to the glyphs used.
+Report char codes not mapping to a glyph of the embedded font program (would render as .notdef).
+Generate missing or broken ToUnicode CMaps for fonts using a standard Latin encoding (improves text extraction).
+Convert text shown using Type3 fonts into vector outlines by drawing the glyph procedures as forms.
+Measure the width, ascent and descent of a single line of text and check whether it fits a box.
+
+       pages ... Please refer to "pdfcpu selectedpages"
+      inFile ... input pdf file
+     outFile ... output pdf file
+    fontName ... base font name as referenced by inFile eg. Arial,Bold
+    fontFile ... True Type font file (.ttf) or OpenType font file using TrueType outlines (.otf),
+                 OpenType fonts using CFF outlines are not supported
+    fontSize ... font size in points
+        text ... single line of text
+       width ... box width in points
+      height ... box height in points, reports the largest font size fitting the box
+
+    Eg. pdfcpu fonts embed in.pdf out.pdf Arial=arial.ttf Arial,Bold=arialbd.ttf
+        pdfcpu fonts measure Helvetica 24 "Confidential" 150 30`
 
 	usageKeywordsList   = "pdfcpu keywords list    inFile"
 	usageKeywordsAdd    = "pdfcpu keywords add     inFile keyword..."
@@ -917,11 +1156,17 @@ Examples:
      
 ` + usageBoxDescription
 
-	usageAnnotsList   = "pdfcpu annotations list   [-p(ages) selectedPages] inFile"
-	usageAnnotsRemove = "pdfcpu annotations remove [-p(ages) selectedPages] inFile [outFile] [objNr|annotId|annotType]..." + generalFlags
+	usageAnnotsList       = "pdfcpu annotations list       [-p(ages) selectedPages] inFile"
+	usageAnnotsRemove     = "pdfcpu annotations remove     [-p(ages) selectedPages] inFile [outFile] [objNr|annotId|annotType]..." + generalFlags
+	usageAnnotsCheck      = "pdfcpu annotations check      [-p(ages) selectedPages] inFile"
+	usageAnnotsClamp      = "pdfcpu annotations clamp      [-p(ages) selectedPages] inFile [outFile] [annotType]..." + generalFlags
+	usageAnnotsDuplicates = "pdfcpu annotations duplicates [-m(ode) list|remove] [-p(ages) selectedPages] inFile [outFile]" + generalFlags
 
 	usageAnnots = "usage: " + usageAnnotsList +
-		"\n       " + usageAnnotsRemove
+		"\n       " + usageAnnotsRemove +
+		"\n       " + usageAnnotsCheck +
+		"\n       " + usageAnnotsClamp +
+		"\n       " + usageAnnotsDuplicates
 
 	usageLongAnnots = `Manage annotations.
    
@@ -957,18 +1202,64 @@ Examples:
 
       Remove annotations by type, id and obj# and write to out.pdf:
          pdfcpu annot remove in.pdf out.pdf Link 30 Text someId
+
+      Report annotations lying (partly) outside the crop box of their page or overlapping other annotations
+      (hidden annotations and popups are not checked):
+         pdfcpu annot check in.pdf
+
+      Clamp annotations lying partly outside the crop box of their page to the crop box
+      and remove annotations lying completely outside (all annotation types except Popup):
+         pdfcpu annot clamp in.pdf out.pdf
+
+      Clamp Link and Popup annotations only:
+         pdfcpu annot clamp in.pdf out.pdf Link Popup
+
+      List exact duplicate annotations (same subtype, rect and contents on the same page,
+      popups and form field widgets are not taken into account):
+         pdfcpu annot duplicates in.pdf
+
+      Remove exact duplicate annotations keeping the first one and write to out.pdf:
+         pdfcpu annot duplicates -m remove in.pdf out.pdf
       `
 
 	usageImagesList = "pdfcpu images list [-p(ages) selectedPages] inFile..." + generalFlags
 
-	usageImages = "usage: " + usageImagesList
+	usageImagesPixelate = "pdfcpu images pixelate inFile page id rects [outFile]" + generalFlags
+
+	usageImagesCheck = "pdfcpu images check [-p(ages) selectedPages] inFile" + generalFlags
+
+	usageImagesDPI = "pdfcpu images dpi [-p(ages) selectedPages] inFile [minDPI]" + generalFlags
 
-	usageLongImages = `Manage keywords.
+	usageImages = "usage: " + usageImagesList +
+		"\n       " + usageImagesPixelate +
+		"\n       " + usageImagesCheck +
+		"\n       " + usageImagesDPI
+
+	usageLongImages = `Manage images.
 
      pages ... Please refer to "pdfcpu selectedpages"
     inFile ... input pdf file
+      page ... page number
+        id ... image id as listed by "pdfcpu images list"
+     rects ... regions to be pixelated in user space of page, eg. "[100 100 200 200] [300 300 350 400]"
+   outFile ... output pdf file
+    minDPI ... flag pages with a lower resolution, eg. 300 for archival scans
+
+  pixelate ... pixelate regions of an image as rendered on page, eg. for obscuring faces.
+               The image gets re-encoded in place, so all renderings of the image are affected.
+               Supported are Flate, LZW and RunLength encoded images with 8 bits per component and gray or RGB JPEGs.
+
+     check ... decode all images and their soft masks and report images failing to decode, being truncated
+               or having sample data inconsistent with /Width, /Height, /BitsPerComponent and /ColorSpace.
+               JPX, JBIG2 and CCITTFax encoded images are not checked.
+
+       dpi ... print the pixel dimensions and the effective resolution as rendered of the main image of each page,
+               the image covering the largest area, eg. for checking the quality of scans.
     
     Example: pdfcpu images list -p "1-5" gallery.pdf
+             pdfcpu images pixelate gallery.pdf 2 Im1 "[100 100 200 200]"
+             pdfcpu images check gallery.pdf
+             pdfcpu images dpi scan.pdf 300
     `
 
 	usageCreate     = "usage: pdfcpu create inFileJSON [inFile] outFile" + generalFlags
@@ -1012,6 +1303,14 @@ For more info on json syntax & samples please refer to :
 	usageFormExport       = "pdfcpu form export inFile [outFileJSON]"
 	usageFormFill         = "pdfcpu form fill inFile inFileJSON [outFile]"
 	usageFormMultiFill    = "pdfcpu form multifill [-m(ode) single|merge] inFile inFileData outDir [outName]"
+	usageFormResources    = "pdfcpu form resources   inFile"
+	usageFormRepair       = "pdfcpu form repair      inFile [outFile]"
+	usageFormAppearances  = "pdfcpu form appearances inFile [outFile]"
+	usageFormCalc         = "pdfcpu form calc    inFile"
+	usageFormSetCalc      = "pdfcpu form setcalc inFile [outFile] [fieldID...]"
+	usageFormActions      = "pdfcpu form actions inFile"
+	usageFormFlatten      = "pdfcpu form flatten inFile [outFile] [outFileJSON]"
+	usageFormPositions    = "pdfcpu form positions inFile [dpi] [outFileJSON]"
 
 	usageForm = "usage: " + usageFormListFields +
 		"\n       " + usageFormRemoveFields +
@@ -1020,17 +1319,27 @@ For more info on json syntax & samples please refer to :
 		"\n       " + usageFormReset +
 		"\n       " + usageFormExport +
 		"\n\n       " + usageFormFill +
-		"\n       " + usageFormMultiFill + generalFlags
+		"\n       " + usageFormMultiFill +
+		"\n\n       " + usageFormResources +
+		"\n       " + usageFormRepair +
+		"\n       " + usageFormAppearances +
+		"\n\n       " + usageFormCalc +
+		"\n       " + usageFormSetCalc +
+		"\n       " + usageFormActions +
+		"\n\n       " + usageFormFlatten +
+		"\n       " + usageFormPositions + generalFlags
 
 	usageLongForm = `Manage PDF forms.
 
-      mode       ... output mode (defaults to single)
-      inFile     ... input pdf file
-      inFileData ... input CSV or JSON file
-      outDir     ... output directory
-      outFile    ... output pdf file
-      fieldID    ... as listed by pdfcpu form list
-      outName    ... base output name
+      mode        ... output mode (defaults to single)
+      inFile      ... input pdf file
+      inFileData  ... input CSV or JSON file
+      outDir      ... output directory
+      outFile     ... output pdf file
+      outFileJSON ... output json file (defaults to outFile with extension .json)
+      fieldID     ... as listed by pdfcpu form list
+      dpi         ... resolution of the rendered page in pixels per inch
+      outName     ... base output name
 
 
 The output modes are:
@@ -1091,6 +1400,29 @@ Supported usecases:
             The first line identifies fields via id in in.json.
          e) "pdfcpu form multifill -m merge in.pdf in.csv outDir" creates a single output PDF in outDir.
 
+  10) Fix fields showing no text until retyped:
+         "pdfcpu form resources in.pdf" lists fonts of field default appearances (/DA) missing in the default resources (/AcroForm /DR).
+         "pdfcpu form repair in.pdf" adds these fonts to the default resources, using core fonts like Helvetica as fallback.
+
+  11) Make filled values visible in all viewers:
+         "pdfcpu form appearances in.pdf" regenerates the appearance streams of all text and choice fields from their values
+         and selects the appearance states of checkboxes and radio buttons instead of relying on /NeedAppearances.
+
+  12) Fix the calculation order of computed fields eg. after merging forms:
+         "pdfcpu form actions in.pdf" lists the keystroke, format, validate and calculate actions (/AA) of all fields.
+         "pdfcpu form calc in.pdf" lists the fields in calculation order (/AcroForm /CO).
+         "pdfcpu form setcalc in.pdf subtotal tax total" calculates "subtotal" first, then "tax" and finally "total".
+         "pdfcpu form setcalc in.pdf" removes the calculation order.
+
+  13) Archive a filled form:
+         "pdfcpu form flatten in.pdf out.pdf" exports the field data to out.json and draws the field appearances into the pages of out.pdf.
+         The result has no form any more but the data is kept in a sidecar file.
+
+  14) Overlay HTML inputs onto rasterized pages:
+         "pdfcpu form positions in.pdf fields.json" exports the page and the position of each field widget
+         relative to the upper left corner of the page as displayed normalized to 0..1, taking into account crop box and page rotation.
+         "pdfcpu form positions in.pdf 150 fields.json" exports positions in pixels for pages rendered with 150 dpi.
+
 
    (For syntax and details please refer to pdfcpu/pkg/api/test/form_test.go)`
 
@@ -1128,4 +1460,215 @@ description ... scalefactor, dimensions, enforce:f/t, formsize(=papersize), bgco
          pdfcpu resize "dim:400 200, enforce:true" in.pdf out.pdf
             Resize pages to 400 x 200 points, enforce orientation.
 `
+
+	usageThumbnail     = "usage: pdfcpu thumbnail inFile imageFile [outFile]" + generalFlags
+	usageLongThumbnail = `Set an image as document thumbnail in the XMP metadata (xmp:Thumbnails), eg. for document management systems showing previews.
+The image gets embedded base64 encoded as JPEG, other image formats get converted. An existing XMP thumbnail gets replaced.
+
+      inFile ... input pdf file
+   imageFile ... image file
+     outFile ... output pdf file`
+
+	usageUnsign     = "usage: pdfcpu unsign inFile [outFile]" + generalFlags
+	usageLongUnsign = `Remove all signature fields including their signatures and any document permissions (DocMDP, usage rights).
+This invalidates existing signatures and turns a signed document into an editable one.
+
+    inFile ... input pdf file
+   outFile ... output pdf file`
+
+	usageDSS     = "usage: pdfcpu dss inFile validationFile..." + generalFlags
+	usageLongDSS = `Add certificates, OCSP responses and CRLs to the Document Security Store (/DSS) for long-term validation.
+The DSS is written as an incremental update, existing signatures stay valid.
+
+           inFile ... input pdf file
+   validationFile ... DER or PEM encoded validation material:
+                        .cer, .crt, .der, .pem ... certificate
+                        .ocsp                  ... OCSP response
+                        .crl                   ... certificate revocation list
+
+`
+
+	usageDestinationsList    = "pdfcpu destinations list inFile"
+	usageDestinationsFix     = "pdfcpu destinations fix [-m(ode) redirect|remove] inFile [outFile]" + generalFlags
+	usageDestinationsConvert = "pdfcpu destinations convert [-m(ode) explicit|named] inFile [outFile]" + generalFlags
+
+	usageDestinations = "usage: " + usageDestinationsList +
+		"\n       " + usageDestinationsFix +
+		"\n       " + usageDestinationsConvert
+
+	usageLongDestinations = `Manage destinations pointing to nonexistent pages and convert between named and explicit destinations.
+
+      mode ... fix: redirect, remove (default: redirect)
+               convert: explicit, named (default: explicit)
+    inFile ... input pdf file
+   outFile ... output pdf file
+
+   list ... list all destinations of outline items, links, the open action and named destinations
+            pointing to a page which is out of range or unresolvable.
+
+    fix ... redirect broken destinations to the nearest valid page or remove them.
+            Page indices get clamped to the page range, unresolvable page objects get replaced by the page of the link or page 1.
+            remove drops links and the open action, outline items remain without destination.
+
+convert ... rewrite the destinations of outline items, links and the open action for consumers handling one kind of destination only.
+            explicit replaces named destinations by the explicit destinations they resolve to,
+            named destinations remain available for references from other documents.
+            named replaces explicit destinations by named destinations added to the Dests name tree (eg. page3, page3-2),
+            existing named destinations get reused for identical destinations.
+
+`
+
+	usageReferencesList = "pdfcpu references list inFile"
+	usageReferencesFix  = "pdfcpu references fix  inFile [outFile]" + generalFlags
+
+	usageReferences = "usage: " + usageReferencesList +
+		"\n       " + usageReferencesFix
+
+	usageLongReferences = `Diagnose indirect references pointing to missing or free objects (dangling references).
+
+   inFile ... input pdf file
+  outFile ... output pdf file
+
+     list ... list all dangling references along with the object and the key path they appear at, eg. obj#12 /Resources/Font/F1
+              inFile gets read without validation, so this works for files failing validation.
+
+      fix ... replace all dangling references with null, which is what a reference to a nonexistent object means per spec.
+              Each replacement gets reported. Use this when a few dangling references make validation fail.
+
+`
+
+	usageStructureOrder    = "pdfcpu structure order    inFile"
+	usageStructureTag      = "pdfcpu structure tag      inFile [outFile]" + generalFlags
+	usageStructureLang     = "pdfcpu structure lang     [-p(ages) page] inFile lang (objNr | rect) [outFile]" + generalFlags
+	usageStructureDecor    = "pdfcpu structure decor    [-p(ages) selectedPages] inFile"
+	usageStructureArtifact = "pdfcpu structure artifact -p(ages) page inFile ranges [outFile]" + generalFlags
+	usageStructureStrip    = "pdfcpu structure strip    inFile [outFile]" + generalFlags
+
+	usageStructure = "usage: " + usageStructureOrder +
+		"\n       " + usageStructureTag +
+		"\n       " + usageStructureLang +
+		"\n       " + usageStructureDecor +
+		"\n       " + usageStructureArtifact +
+		"\n       " + usageStructureStrip
+
+	usageLongStructure = `Check the structure tree (/StructTreeRoot) of tagged PDFs.
+
+    pages ... page containing rect, page to tag artifacts of or pages to list decorative content of
+   inFile ... input pdf file
+     lang ... language identifier, eg. "fr" or "en-US", "" removes /Lang
+    objNr ... object number of a struct element
+     rect ... region in user space of the marked content of struct elements, eg. "[100 100 300 200]"
+   ranges ... comma separated list of content operation ranges as listed by decor, eg. "0-3,7"
+  outFile ... output pdf file
+
+   order ... list struct elements out of reading order.
+             The logical order of the structure tree gets compared against the positions of the marked content on the page
+             assuming a top to bottom, left to right reading order. Moving up and to the right is considered to be a column break.
+
+     tag ... generate a basic structure tree for an untagged document (best effort).
+             Text objects become paragraphs (/P) or headings (/H1, /H2) depending on their font size relative to body text,
+             images become figures (/Figure). Content within form XObjects remains untagged.
+
+    lang ... set the language (/Lang) of struct elements for multilingual documents, eg. a French quote in an English document.
+             Struct elements get selected by object number or by the region containing the start of their marked content.
+
+    Example: pdfcpu structure lang -p 2 in.pdf fr "[50 400 550 500]" out.pdf
+
+   decor ... list untagged content likely to be decorative: page backgrounds and XObjects like logos
+             or header and footer graphics repeated on several pages.
+
+artifact ... wrap content operation ranges of a page into /Artifact marked content so screen readers skip them.
+             Ranges must not break the nesting of marked content, text objects or path objects.
+
+    Example: pdfcpu structure artifact -p 1 in.pdf "0-3,7" out.pdf
+
+   strip ... remove the structure tree (/StructTreeRoot), /MarkInfo and all struct element references from content streams
+             resulting in an untagged document, eg. for a non-accessible derivative or to reduce file size.
+             The file size saved gets reported.
+
+`
+
+	usageViewerPrefList  = "pdfcpu viewerpref list inFile"
+	usageViewerPrefPrint = "pdfcpu viewerpref print inFile description [outFile]" + generalFlags
+
+	usageViewerPref = "usage: " + usageViewerPrefList +
+		"\n       " + usageViewerPrefPrint
+
+	usageLongViewerPref = `Manage viewer preferences (/ViewerPreferences).
+
+         inFile ... input pdf file
+        outFile ... output pdf file
+    description ... scaling, area, clip
+
+   list ... list all viewer preferences.
+
+  print ... set print related viewer preferences:
+
+       scaling ... page scaling applied by print dialogs (/PrintScaling): none, appdefault
+          area ... page boundary rendered when printing (/PrintArea): media, crop, trim, bleed, art
+          clip ... page boundary clipping the content when printing (/PrintClip): media, crop, trim, bleed, art
+
+      Examples:
+
+         pdfcpu viewerpref print in.pdf "scaling:none"
+            Print at actual size by default, eg. for forms.
+
+         pdfcpu viewerpref print in.pdf "scal:none, area:crop, clip:crop" out.pdf
+
+`
+
+	usageTimeStampPrepare = "pdfcpu timestamp prepare inFile [outFile]"
+	usageTimeStampEmbed   = "pdfcpu timestamp embed inFile tokenFile" + generalFlags
+
+	usageTimeStamp = "usage: " + usageTimeStampPrepare +
+		"\n       " + usageTimeStampEmbed
+
+	usageLongTimeStamp = `Manage document timestamps (/Type /DocTimeStamp) for long-term validation.
+
+       inFile ... input pdf file
+      outFile ... output pdf file
+    tokenFile ... DER encoded RFC 3161 timestamp token
+
+   prepare ... append an incremental update with an invisible document timestamp signature field
+               reserving 8192 bytes for the token and print the SHA-256 digest of its byte range.
+               Submit this digest to your timestamp authority.
+     embed ... write the timestamp token received into the prepared signature.
+
+`
+
+	usageAssemble     = "usage: pdfcpu assemble manifestFile outFile" + generalFlags
+	usageLongAssemble = `Produce outFile as described by a JSON or YAML manifest.
+
+   manifestFile ... input json or yaml file
+        outFile ... output pdf file
+
+The manifest is an ordered list of operations:
+
+       insert ... append pages of file, all pages unless pages is given
+        blank ... append count blank pages of paperSize (defaults to A4)
+       rotate ... rotate pages assembled so far by rotation degrees clockwise
+    watermark ... add a watermark to pages assembled so far using mode (text, image, pdf),
+                  text or file, desc (see "pdfcpu watermark") and onTop (stamp).
+
+pages is a page selection like for the -pages flag, please refer to "pdfcpu selectedpages".
+Relative file names are resolved against the directory of manifestFile.
+Operations are applied in order: rotations and watermarks apply to the pages assembled by preceding operations.
+
+A sample yaml manifest:
+
+operations:
+  - op: insert
+    file: cover.pdf
+  - op: insert
+    file: report.pdf
+    pages: 2-5
+  - op: blank
+    count: 1
+  - op: rotate
+    pages: "6"
+    rotation: 90
+  - op: watermark
+    pages: 2-
+    text: Draft
+    desc: "scale:.5, op:.3"`
 )
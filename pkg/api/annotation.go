@@ -61,6 +61,250 @@ func ListAnnotationsFile(inFile string, selectedPages []string, conf *model.Conf
 	return ListAnnotations(f, selectedPages, conf)
 }
 
+// AnnotationIssues returns a list of annotations of rs lying (partly) outside the CropBox of their page
+// or overlapping other annotations.
+func AnnotationIssues(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: AnnotationIssues: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHECKANNOTATIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.AnnotationIssues(ctx, pages)
+}
+
+// AnnotationIssuesFile returns a list of annotations of inFile lying (partly) outside the CropBox of their page
+// or overlapping other annotations.
+func AnnotationIssuesFile(inFile string, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return AnnotationIssues(f, selectedPages, conf)
+}
+
+// ClampAnnotations clamps annotations of selected pages of rs lying partly outside the CropBox of their page to the CropBox,
+// removes annotations lying completely outside and writes the result to w.
+// Only annotations of annotTypes are processed, by default all annotation types except popups.
+func ClampAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages, annotTypes []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ClampAnnotations: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ClampAnnotations: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CLAMPANNOTATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.ClampAnnotations(ctx, pages, annotTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// ClampAnnotationsFile clamps annotations of selected pages of inFile lying partly outside the CropBox of their page to the CropBox,
+// removes annotations lying completely outside and writes the result to outFile.
+func ClampAnnotationsFile(inFile, outFile string, selectedPages, annotTypes []string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ClampAnnotations(f1, f2, selectedPages, annotTypes, conf)
+}
+
+// DuplicateAnnotations returns a list of exact duplicate annotations of selected pages of rs,
+// annotations sharing subtype, rect and contents with a preceding annotation of the same page.
+func DuplicateAnnotations(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: DuplicateAnnotations: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDUPLICATEANNOTATIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.DuplicateAnnotations(ctx, pages, false)
+}
+
+// DuplicateAnnotationsFile returns a list of exact duplicate annotations of selected pages of inFile.
+func DuplicateAnnotationsFile(inFile string, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DuplicateAnnotations(f, selectedPages, conf)
+}
+
+// RemoveDuplicateAnnotations collapses exact duplicate annotations of selected pages of rs to their first occurrence
+// and writes the result to w.
+func RemoveDuplicateAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RemoveDuplicateAnnotations: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RemoveDuplicateAnnotations: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEDUPLICATEANNOTATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.DuplicateAnnotations(ctx, pages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// RemoveDuplicateAnnotationsFile collapses exact duplicate annotations of selected pages of inFile to their first occurrence
+// and writes the result to outFile.
+func RemoveDuplicateAnnotationsFile(inFile, outFile string, selectedPages []string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RemoveDuplicateAnnotations(f1, f2, selectedPages, conf)
+}
+
 // AddAnnotations adds annotations for selected pages in rs and writes the result to w.
 func AddAnnotations(rs io.ReadSeeker, w io.Writer, selectedPages []string, ann model.AnnotationRenderer, conf *model.Configuration) error {
 	if conf == nil {
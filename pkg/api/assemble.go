@@ -0,0 +1,183 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+func assembleWatermark(op model.AssembleOp, u types.DisplayUnit) (*model.Watermark, error) {
+	switch op.Mode {
+	case "image":
+		return pdfcpu.ParseImageWatermarkDetails(op.File, op.Desc, op.OnTop, u)
+	case "pdf":
+		return pdfcpu.ParsePDFWatermarkDetails(op.File, op.Desc, op.OnTop, u)
+	}
+	return pdfcpu.ParseTextWatermarkDetails(op.Text, op.Desc, op.OnTop, u)
+}
+
+func assembleInsert(ctx *model.Context, op model.AssembleOp, conf *model.Configuration) error {
+	sel := model.MergeSelection{InFile: op.File}
+	var err error
+	if sel.PageSelection, err = ParsePageSelection(op.Pages); err != nil {
+		return err
+	}
+	ctxSrc, pages, err := readSelectedPages(sel, conf)
+	if err != nil {
+		return err
+	}
+	if err := pdfcpu.AddPages(ctxSrc, ctx, pages, true); err != nil {
+		return err
+	}
+	ctx.PageCount += len(pages)
+	return nil
+}
+
+func assembleBlank(ctx *model.Context, op model.AssembleOp) error {
+	dim, err := op.PageDim()
+	if err != nil {
+		return err
+	}
+	if err := ctx.AppendBlankPages(op.Count, types.RectForDim(dim.Width, dim.Height)); err != nil {
+		return err
+	}
+	ctx.PageCount += op.Count
+	return nil
+}
+
+func assembleModification(ctx *model.Context, op model.AssembleOp) error {
+	selectedPages, err := ParsePageSelection(op.Pages)
+	if err != nil {
+		return err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if op.Op == "rotate" {
+		return pdfcpu.RotatePages(ctx, pages, op.Rotation)
+	}
+
+	wm, err := assembleWatermark(op, ctx.Unit)
+	if err != nil {
+		return err
+	}
+
+	return pdfcpu.AddWatermarks(ctx, pages, wm)
+}
+
+func assemble(ctx *model.Context, m *model.AssembleManifest, conf *model.Configuration) error {
+	for _, op := range m.Operations {
+		var err error
+
+		switch op.Op {
+
+		case "insert":
+			log.CLI.Printf("insert %s %s\n", op.File, op.Pages)
+			err = assembleInsert(ctx, op, conf)
+
+		case "blank":
+			log.CLI.Printf("blank %d\n", op.Count)
+			err = assembleBlank(ctx, op)
+
+		default:
+			log.CLI.Printf("%s %s\n", op.Op, op.Pages)
+			err = assembleModification(ctx, op)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Assemble produces a document as described by manifest m and writes the result to w.
+// All operations get applied in the order specified in a single pass,
+// the page selections of rotate and watermark refer to the pages assembled so far.
+func Assemble(m *model.AssembleManifest, w io.Writer, conf *model.Configuration) error {
+	if m == nil {
+		return errors.New("pdfcpu: Assemble: Please provide m")
+	}
+
+	if w == nil {
+		return errors.New("pdfcpu: Assemble: Please provide w")
+	}
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ASSEMBLE
+	conf.OptimizeDuplicateContentStreams = false
+
+	ctx, err := pdfcpu.CreateContextWithXRefTable(conf, types.PaperSize["A4"])
+	if err != nil {
+		return err
+	}
+
+	if err := assemble(ctx, m, conf); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err := ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// AssembleFile produces a document as described by the JSON or YAML manifestFile and writes the result to outFile.
+// An existing outFile will be overwritten.
+func AssembleFile(manifestFile, outFile string, conf *model.Configuration) (err error) {
+	m, err := model.ReadAssembleManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		cerr := f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	log.CLI.Printf("writing %s...\n", outFile)
+	return Assemble(m, f, conf)
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pkg/errors"
+)
+
+// ListBrokenDestinations returns a list of all destinations of rs pointing to a page which is out of range or unresolvable.
+func ListBrokenDestinations(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ListBrokenDestinations: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTBROKENDESTINATIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.ListBrokenDestinations(ctx)
+}
+
+// ListBrokenDestinationsFile returns a list of all destinations of inFile pointing to a page which is out of range or unresolvable.
+func ListBrokenDestinationsFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ListBrokenDestinations(f, conf)
+}
+
+// FixBrokenDestinations removes all broken destinations of rs or redirects them to the nearest valid page and writes the result to w.
+// The result is a list of all destinations fixed.
+func FixBrokenDestinations(rs io.ReadSeeker, w io.Writer, remove bool, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FixBrokenDestinations: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: FixBrokenDestinations: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FIXBROKENDESTINATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.FixBrokenDestinations(ctx, remove)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// FixBrokenDestinationsFile removes all broken destinations of inFile or redirects them to the nearest valid page and writes the result to outFile.
+// The result is a list of all destinations fixed.
+func FixBrokenDestinationsFile(inFile, outFile string, remove bool, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return FixBrokenDestinations(f1, f2, remove, conf)
+}
+
+// InlineNamedDestinations replaces all named destinations referred to by outline items, links and the open action by explicit destinations of rs and writes the result to w.
+// The result is a list of all destinations replaced.
+func InlineNamedDestinations(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: InlineNamedDestinations: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: InlineNamedDestinations: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.INLINENAMEDDESTINATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.InlineNamedDestinations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// InlineNamedDestinationsFile replaces all named destinations referred to by outline items, links and the open action by explicit destinations of inFile and writes the result to outFile.
+// The result is a list of all destinations replaced.
+func InlineNamedDestinationsFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return InlineNamedDestinations(f1, f2, conf)
+}
+
+// NameExplicitDestinations replaces all explicit destinations of outline items, links and the open action by named destinations of rs and writes the result to w.
+// The result is a list of all destinations replaced.
+func NameExplicitDestinations(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NameExplicitDestinations: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: NameExplicitDestinations: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NAMEEXPLICITDESTINATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.NameExplicitDestinations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// NameExplicitDestinationsFile replaces all explicit destinations of outline items, links and the open action by named destinations of inFile and writes the result to outFile.
+// The result is a list of all destinations replaced.
+func NameExplicitDestinationsFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return NameExplicitDestinations(f1, f2, conf)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pkg/errors"
+)
+
+// derBlocks returns the DER encoded blocks of PEM input or bb itself.
+func derBlocks(bb []byte) [][]byte {
+	var bbs [][]byte
+	for rest := bb; ; {
+		var b *pem.Block
+		b, rest = pem.Decode(rest)
+		if b == nil {
+			break
+		}
+		bbs = append(bbs, b.Bytes)
+	}
+	if len(bbs) == 0 {
+		bbs = [][]byte{bb}
+	}
+	return bbs
+}
+
+// ReadValidationData reads DER or PEM encoded validation material from files.
+// The file extension determines the type:
+//
+//	.ocsp			OCSP response
+//	.crl			certificate revocation list
+//	.cer .crt .der .pem	X.509 certificate
+func ReadValidationData(fileNames []string) (pdfcpu.ValidationData, error) {
+	var vd pdfcpu.ValidationData
+
+	for _, fn := range fileNames {
+		bb, err := os.ReadFile(fn)
+		if err != nil {
+			return vd, err
+		}
+		switch strings.ToLower(filepath.Ext(fn)) {
+		case ".ocsp":
+			vd.OCSPs = append(vd.OCSPs, bb)
+		case ".crl":
+			vd.CRLs = append(vd.CRLs, derBlocks(bb)...)
+		case ".cer", ".crt", ".der", ".pem":
+			vd.Certs = append(vd.Certs, derBlocks(bb)...)
+		default:
+			return vd, errors.Errorf("pdfcpu: unsupported validation data file: %s", fn)
+		}
+	}
+
+	return vd, nil
+}
+
+// AddDSS adds the validation material vd to the Document Security Store of rws and appends a PDF increment.
+// Existing signatures stay valid.
+// If vri is true a VRI entry is added for every signature.
+func AddDSS(rws io.ReadWriteSeeker, vd pdfcpu.ValidationData, vri bool, conf *model.Configuration) error {
+	if rws == nil {
+		return errors.New("pdfcpu: AddDSS: Please provide rws")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDDSS
+
+	ctx, _, _, err := readAndValidate(rws, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if *ctx.HeaderVersion < model.V14 {
+		return errors.New("Increment writing not supported for PDF version < V1.4 (Hint: Use pdfcpu optimize then try again)")
+	}
+
+	ctx.Write.Increment = true
+	ctx.Write.Offset = ctx.Read.FileSize
+
+	if err := pdfcpu.AddDSS(ctx, vd, vri); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if _, err = rws.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+
+	return WriteIncrement(ctx, rws)
+}
+
+// AddDSSFile adds the validation material read from validationFiles to the Document Security Store of inFile
+// and writes the result to outFile.
+// See ReadValidationData for supported files.
+func AddDSSFile(inFile, outFile string, validationFiles []string, vri bool, conf *model.Configuration) error {
+	vd, err := ReadValidationData(validationFiles)
+	if err != nil {
+		return err
+	}
+
+	if outFile != "" && inFile != outFile {
+		bb, err := os.ReadFile(inFile)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(outFile, bb, 0644); err != nil {
+			return err
+		}
+		inFile = outFile
+	}
+
+	log.CLI.Printf("writing %s...\n", inFile)
+
+	f, err := os.OpenFile(inFile, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return AddDSS(f, vd, vri, conf)
+}
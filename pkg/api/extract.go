@@ -17,6 +17,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -130,12 +131,15 @@ func ExtractImagesFile(inFile, outDir string, selectedPages []string, conf *mode
 	defer f.Close()
 	log.CLI.Printf("extracting images from %s into %s/ ...\n", inFile, outDir)
 	fileName := strings.TrimSuffix(filepath.Base(inFile), ".pdf")
-	return ExtractImages(f, selectedPages, pdfcpu.WriteImageToDisk(outDir, fileName), conf)
+	return ExtractImages(f, selectedPages, pdfcpu.WriteImageToDiskUsing(outDir, fileName, conf), conf)
 }
 
-func writeFonts(ff []pdfcpu.Font, outDir, fileName string) error {
+func writeFonts(conf *model.Configuration, ff []pdfcpu.Font, outDir, fileName string, pageNr int, index *int) error {
 	for _, f := range ff {
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_%s.%s", fileName, f.Name, f.Type))
+		*index++
+		defName := fmt.Sprintf("%s_%s.%s", fileName, f.Name, f.Type)
+		n := model.OutputName{Base: fileName, Page: pageNr, Index: *index, From: pageNr, To: pageNr, Ext: f.Type}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 		log.CLI.Printf("writing %s\n", outFile)
 		w, err := os.Create(outFile)
 		if err != nil {
@@ -178,6 +182,8 @@ func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 
 	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
 
+	var index int
+
 	for i, v := range pages {
 		if !v {
 			continue
@@ -186,7 +192,7 @@ func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 		if err != nil {
 			return err
 		}
-		if err := writeFonts(ff, outDir, fileName); err != nil {
+		if err := writeFonts(conf, ff, outDir, fileName, i, &index); err != nil {
 			return err
 		}
 	}
@@ -195,7 +201,7 @@ func ExtractFonts(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 	if err != nil {
 		return err
 	}
-	if err := writeFonts(ff, outDir, fileName); err != nil {
+	if err := writeFonts(conf, ff, outDir, fileName, 0, &index); err != nil {
 		return err
 	}
 
@@ -250,15 +256,17 @@ func ExtractPages(rs io.ReadSeeker, outDir, fileName string, selectedPages []str
 
 	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
 
-	for i, v := range pages {
-		if !v {
-			continue
-		}
+	var index int
+
+	for _, i := range sortedPages(pages) {
 		ctxNew, err := pdfcpu.ExtractPage(ctx, i)
 		if err != nil {
 			return err
 		}
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_page_%d.pdf", fileName, i))
+		index++
+		defName := fmt.Sprintf("%s_page_%d.pdf", fileName, i)
+		n := model.OutputName{Base: fileName, Page: i, Index: index, From: i, To: i, Ext: "pdf"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 		log.CLI.Printf("writing %s\n", outFile)
 		if err := WriteContextFile(ctxNew, outFile); err != nil {
 			return err
@@ -310,10 +318,9 @@ func ExtractContent(rs io.ReadSeeker, outDir, fileName string, selectedPages []s
 
 	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
 
-	for p, v := range pages {
-		if !v {
-			continue
-		}
+	var index int
+
+	for _, p := range sortedPages(pages) {
 		r, err := pdfcpu.ExtractPageContent(ctx, p)
 		if err != nil {
 			return err
@@ -321,7 +328,10 @@ func ExtractContent(rs io.ReadSeeker, outDir, fileName string, selectedPages []s
 		if r == nil {
 			continue
 		}
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_Content_page_%d.txt", fileName, p))
+		index++
+		defName := fmt.Sprintf("%s_Content_page_%d.txt", fileName, p)
+		n := model.OutputName{Base: fileName, Page: p, Index: index, From: p, To: p, Ext: "txt"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 		log.CLI.Printf("writing %s\n", outFile)
 		f, err := os.Create(outFile)
 		if err != nil {
@@ -353,6 +363,75 @@ func ExtractContentFile(inFile, outDir string, selectedPages []string, conf *mod
 	return ExtractContent(f, outDir, inFile, selectedPages, conf)
 }
 
+// ExtractPagesEPS generates EPS files from rs into outDir for selected pages.
+func ExtractPagesEPS(rs io.ReadSeeker, outDir, fileName string, selectedPages []string, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractPagesEPS: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
+
+	var index int
+
+	for _, p := range sortedPages(pages) {
+		r, err := pdfcpu.ExtractPageEPS(ctx, p)
+		if err != nil {
+			return err
+		}
+		index++
+		defName := fmt.Sprintf("%s_page_%d.eps", fileName, p)
+		n := model.OutputName{Base: fileName, Page: p, Index: index, From: p, To: p, Ext: "eps"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
+		log.CLI.Printf("writing %s\n", outFile)
+		f, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(f, r); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	model.TimingStats("write EPS", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExtractPagesEPSFile generates EPS files from inFile into outDir for selected pages.
+func ExtractPagesEPSFile(inFile, outDir string, selectedPages []string, conf *model.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting EPS pages from %s into %s/ ...\n", inFile, outDir)
+	return ExtractPagesEPS(f, outDir, inFile, selectedPages, conf)
+}
+
 // ExtractMetadata dumps all metadata dict entries for rs into outDir.
 func ExtractMetadata(rs io.ReadSeeker, outDir, fileName string, conf *model.Configuration) error {
 	if rs == nil {
@@ -377,8 +456,10 @@ func ExtractMetadata(rs io.ReadSeeker, outDir, fileName string, conf *model.Conf
 
 	if len(mm) > 0 {
 		fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
-		for _, m := range mm {
-			outFile := filepath.Join(outDir, fmt.Sprintf("%s_Metadata_%s_%d_%d.txt", fileName, m.ParentType, m.ParentObjNr, m.ObjNr))
+		for i, m := range mm {
+			defName := fmt.Sprintf("%s_Metadata_%s_%d_%d.txt", fileName, m.ParentType, m.ParentObjNr, m.ObjNr)
+			n := model.OutputName{Base: fileName, Index: i + 1, Ext: "txt"}
+			outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 			log.CLI.Printf("writing %s\n", outFile)
 			f, err := os.Create(outFile)
 			if err != nil {
@@ -410,3 +491,214 @@ func ExtractMetadataFile(inFile, outDir string, conf *model.Configuration) error
 	log.CLI.Printf("extracting metadata from %s into %s/ ...\n", inFile, outDir)
 	return ExtractMetadata(f, outDir, filepath.Base(inFile), conf)
 }
+
+// ExportBundle writes a structured representation of rs into outDir:
+// bundle.json describing metadata, pages, text, annotations, images and fonts plus the extracted XMP metadata, images and fonts.
+func ExportBundle(rs io.ReadSeeker, outDir string, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExportBundle: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTBUNDLE
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+
+	if err := pdfcpu.ExportBundle(ctx, outDir); err != nil {
+		return err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	model.TimingStats("write bundle", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExportBundleFile writes a structured representation of inFile into outDir.
+func ExportBundleFile(inFile, outDir string, conf *model.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("exporting bundle from %s into %s/ ...\n", inFile, outDir)
+	return ExportBundle(f, outDir, conf)
+}
+
+// ListICCProfiles returns a list of all ICC profiles embedded in rs including their number of color components and description.
+func ListICCProfiles(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ListICCProfiles: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.ListICCProfiles(ctx)
+}
+
+// ListICCProfilesFile returns a list of all ICC profiles embedded in inFile including their number of color components and description.
+func ListICCProfilesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ListICCProfiles(f, conf)
+}
+
+// ExtractICCProfiles dumps all embedded ICC profiles of ICCBased color spaces and output intents for rs into outDir.
+func ExtractICCProfiles(rs io.ReadSeeker, outDir, fileName string, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: ExtractICCProfiles: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	fromWrite := time.Now()
+
+	pp, err := pdfcpu.ExtractICCProfiles(ctx)
+	if err != nil {
+		return err
+	}
+
+	fileName = strings.TrimSuffix(filepath.Base(fileName), ".pdf")
+	for i, p := range pp {
+		defName := fmt.Sprintf("%s_%s_%d.icc", fileName, p.Usage, p.ObjNr)
+		n := model.OutputName{Base: fileName, Index: i + 1, Ext: "icc"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
+		log.CLI.Printf("writing %s (N=%d %s)\n", outFile, p.N, p.Description)
+		f, err := os.Create(outFile)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(f, p); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	model.TimingStats("write ICC profiles", durRead, durVal, durOpt, durWrite, durTotal)
+	return nil
+}
+
+// ExtractICCProfilesFile dumps all embedded ICC profiles of ICCBased color spaces and output intents for inFile into outDir.
+func ExtractICCProfilesFile(inFile, outDir string, conf *model.Configuration) error {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	log.CLI.Printf("extracting ICC profiles from %s into %s/ ...\n", inFile, outDir)
+	return ExtractICCProfiles(f, outDir, filepath.Base(inFile), conf)
+}
+
+// Paragraphs returns the text of selected pages of rs organized into blocks of adjacent lines.
+func Paragraphs(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]pdfcpu.PageText, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: Paragraphs: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTPARAGRAPHS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.Paragraphs(ctx, sortedPages(pages))
+}
+
+// ExportParagraphs writes the paragraph structure of selected pages of rs originating from source as JSON to w.
+func ExportParagraphs(rs io.ReadSeeker, w io.Writer, source string, selectedPages []string, conf *model.Configuration) error {
+	if w == nil {
+		return errors.New("pdfcpu: ExportParagraphs: Please provide w")
+	}
+
+	pp, err := Paragraphs(rs, selectedPages, conf)
+	if err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(pdfcpu.DocumentText{Source: filepath.Base(source), Pages: pp}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bb)
+	return err
+}
+
+// ExtractParagraphsFile writes the paragraph structure of selected pages of inFile as JSON into outDir.
+func ExtractParagraphsFile(inFile, outDir string, selectedPages []string, conf *model.Configuration) (err error) {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	fileName := strings.TrimSuffix(filepath.Base(inFile), ".pdf")
+	defName := fileName + "_text.json"
+	outFile := filepath.Join(outDir, conf.OutputFileName(defName, model.OutputName{Base: fileName, Index: 1, Ext: "json"}))
+
+	if f2, err = os.Create(outFile); err != nil {
+		f1.Close()
+		return err
+	}
+	log.CLI.Printf("writing %s...\n", outFile)
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		err = f1.Close()
+	}()
+
+	return ExportParagraphs(f1, f2, inFile, selectedPages, conf)
+}
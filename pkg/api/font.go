@@ -19,9 +19,11 @@ package api
 import (
 	"bytes"
 	"fmt"
-
+	"io"
+	"os"
 	"path/filepath"
 	"sort"
+	"time"
 	"unicode/utf8"
 
 	"github.com/ex-preman/pdfcpu/pkg/font"
@@ -86,6 +88,45 @@ func InstallFonts(fileNames []string) error {
 	return font.LoadUserFonts()
 }
 
+func ensureSupportedFont(fontName string) error {
+	if !font.SupportedFont(fontName) {
+		return errors.Errorf("pdfcpu: font %s not available", fontName)
+	}
+	return nil
+}
+
+// encodeText returns text byte encoded for core fonts.
+func encodeText(fontName, text string) string {
+	if font.IsCoreFont(fontName) && utf8.ValidString(text) {
+		return model.DecodeUTF8ToByte(text)
+	}
+	return text
+}
+
+// MeasureText returns width, ascent and descent in user space units for text rendered using fontName and fontSize.
+func MeasureText(fontName string, fontSize int, text string) (width, ascent, descent float64, err error) {
+	if err := ensureSupportedFont(fontName); err != nil {
+		return 0, 0, 0, err
+	}
+	if fontSize <= 0 {
+		return 0, 0, 0, errors.Errorf("pdfcpu: invalid font size: %d", fontSize)
+	}
+	width, ascent, descent = font.MeasureText(fontName, fontSize, encodeText(fontName, text))
+	return width, ascent, descent, nil
+}
+
+// MaxFontSize returns the largest font size for text rendered using fontName as a single line fitting into a box of width w and height h.
+// The line height is ascent + descent as returned by MeasureText.
+func MaxFontSize(fontName, text string, w, h float64) (int, error) {
+	if err := ensureSupportedFont(fontName); err != nil {
+		return 0, err
+	}
+	if w <= 0 || h <= 0 {
+		return 0, errors.Errorf("pdfcpu: invalid box dimensions: %.2f x %.2f", w, h)
+	}
+	return font.MaxFontSize(fontName, encodeText(fontName, text), w, h), nil
+}
+
 func rowLabel(xRefTable *model.XRefTable, i int, td model.TextDescriptor, baseFontName, baseFontKey string, buf *bytes.Buffer, mb *types.Rectangle, left bool) {
 	x := 39.
 	if !left {
@@ -245,3 +286,329 @@ func CreateCheatSheetsUserFonts(fontNames []string) error {
 	}
 	return nil
 }
+
+// EmbedFonts embeds the TrueType font files of fontFiles (keyed by font name) into matching non embedded fonts of rs
+// and writes the result to w. Font programs are subsetted to the glyphs used.
+func EmbedFonts(rs io.ReadSeeker, w io.Writer, fontFiles map[string]string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: EmbedFonts: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: EmbedFonts: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EMBEDFONTS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdf.EmbedFonts(ctx, fontFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// EmbedFontsFile embeds the TrueType font files of fontFiles (keyed by font name) into matching non embedded fonts of inFile
+// and writes the result to outFile. Font programs are subsetted to the glyphs used.
+func EmbedFontsFile(inFile, outFile string, fontFiles map[string]string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return EmbedFonts(f1, f2, fontFiles, conf)
+}
+
+// ListDocumentFonts returns a list of the fonts of rs including embedded font program size,
+// subset state and the number of distinct glyphs used.
+func ListDocumentFonts(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ListDocumentFonts: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDOCUMENTFONTS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return pdf.ListDocumentFonts(ctx)
+}
+
+// ListDocumentFontsFile returns a list of the fonts of inFile including embedded font program size,
+// subset state and the number of distinct glyphs used.
+func ListDocumentFontsFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListDocumentFonts(f, conf)
+}
+
+// ListMissingGlyphs returns a report of char codes shown on selected pages of rs that do not map to a glyph
+// of the embedded font program and would render as .notdef.
+func ListMissingGlyphs(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ListMissingGlyphs: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+		conf.Cmd = model.LISTMISSINGGLYPHS
+	}
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdf.ListMissingGlyphs(ctx, pages)
+}
+
+// ListMissingGlyphsFile returns a report of char codes shown on selected pages of inFile that do not map to a glyph
+// of the embedded font program and would render as .notdef.
+func ListMissingGlyphsFile(inFile string, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if len(selectedPages) == 0 {
+		log.CLI.Printf("pages: all\n")
+	}
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ListMissingGlyphs(f, selectedPages, conf)
+}
+
+// RepairToUnicode generates ToUnicode CMaps for fonts of rs using a standard Latin encoding but missing a valid ToUnicode CMap
+// and writes the result to w. This makes extracted text proper Unicode.
+func RepairToUnicode(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RepairToUnicode: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RepairToUnicode: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REPAIRTOUNICODE
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdf.RepairToUnicode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// RepairToUnicodeFile generates ToUnicode CMaps for fonts of inFile using a standard Latin encoding but missing a valid ToUnicode CMap
+// and writes the result to outFile. This makes extracted text proper Unicode.
+func RepairToUnicodeFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RepairToUnicode(f1, f2, conf)
+}
+
+// ConvertType3Fonts replaces text shown on selected pages of rs using Type3 fonts by vector outlines
+// and writes the result to w.
+func ConvertType3Fonts(rs io.ReadSeeker, w io.Writer, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ConvertType3Fonts: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ConvertType3Fonts: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CONVERTTYPE3FONTS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdf.ConvertType3Fonts(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// ConvertType3FontsFile replaces text shown on selected pages of inFile using Type3 fonts by vector outlines
+// and writes the result to outFile.
+func ConvertType3FontsFile(inFile, outFile string, selectedPages []string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ConvertType3Fonts(f1, f2, selectedPages, conf)
+}
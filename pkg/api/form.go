@@ -383,6 +383,191 @@ func ResetFormFieldsFile(inFile, outFile string, fieldIDs []string, conf *model.
 	return ResetFormFields(f1, f2, fieldIDs, conf)
 }
 
+// FormDefaultResourceIssues returns a list of fonts referenced by field default appearances of rs missing in the form's default resources.
+func FormDefaultResourceIssues(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FormDefaultResourceIssues: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTFORMDRISSUES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return form.DefaultResourceIssues(ctx)
+}
+
+// FormDefaultResourceIssuesFile returns a list of fonts referenced by field default appearances of inFile missing in the form's default resources.
+func FormDefaultResourceIssuesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return FormDefaultResourceIssues(f, conf)
+}
+
+// RepairFormDefaultResources adds all fonts referenced by field default appearances of rs missing in the form's default resources
+// and writes the result to w.
+func RepairFormDefaultResources(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RepairFormDefaultResources: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RepairFormDefaultResources: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REPAIRFORMDR
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := form.RepairDefaultResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// RepairFormDefaultResourcesFile adds all fonts referenced by field default appearances of inFile missing in the form's default resources
+// and writes the result to outFile.
+func RepairFormDefaultResourcesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RepairFormDefaultResources(f1, f2, conf)
+}
+
+// RegenerateAppearances regenerates the appearances of all form fields of rs from their current values and writes the result to w.
+func RegenerateAppearances(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RegenerateAppearances: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RegenerateAppearances: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REGENERATEAPPEARANCES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := form.RegenerateAppearances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// RegenerateAppearancesFile regenerates the appearances of all form fields of inFile from their current values and writes the result to outFile.
+func RegenerateAppearancesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RegenerateAppearances(f1, f2, conf)
+}
+
 // ExportForm extracts form data originating from source from rs and writes the result to w.
 func ExportForm(rs io.ReadSeeker, w io.Writer, source string, conf *model.Configuration) error {
 	if conf == nil {
@@ -638,7 +823,9 @@ func multiFillFormJSON(inFilePDF string, rd io.Reader, outDir, fileName string,
 			}
 		}
 
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_%02d.pdf", fileName, i+1))
+		defName := fmt.Sprintf("%s_%02d.pdf", fileName, i+1)
+		n := model.OutputName{Base: fileName, Index: i + 1, Ext: "pdf"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 		log.CLI.Printf("writing %s\n", outFile)
 
 		if err := WriteContextFile(ctx, outFile); err != nil {
@@ -744,7 +931,9 @@ func multiFillFormCSV(inFilePDF string, rd io.Reader, outDir, fileName string, m
 			}
 		}
 
-		outFile := filepath.Join(outDir, fmt.Sprintf("%s_%02d.pdf", fileName, i+1))
+		defName := fmt.Sprintf("%s_%02d.pdf", fileName, i+1)
+		n := model.OutputName{Base: fileName, Index: i + 1, Ext: "pdf"}
+		outFile := filepath.Join(outDir, conf.OutputFileName(defName, n))
 		log.CLI.Printf("writing %s\n", outFile)
 		if err := WriteContextFile(ctx, outFile); err != nil {
 			return err
@@ -817,3 +1006,321 @@ func MultiFillFormFile(inFilePDF, inFileData, outDir, outFilePDF string, merge b
 
 	return MultiFillForm(inFilePDF, f, outDir, outFileBase, format, merge, conf)
 }
+
+// CalculationOrder returns the ids of all fields of rs listed in the calculation order of the form.
+func CalculationOrder(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: CalculationOrder: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTCALCORDER
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return form.CalculationOrder(ctx)
+}
+
+// CalculationOrderFile returns the ids of all fields of inFile listed in the calculation order of the form.
+func CalculationOrderFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return CalculationOrder(f, conf)
+}
+
+// SetCalculationOrder sets the calculation order of the form of rs to the fields fieldIDs and writes the result to w.
+// All fields need a calculate action. An empty fieldIDs removes the calculation order.
+func SetCalculationOrder(rs io.ReadSeeker, w io.Writer, fieldIDs []string, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: SetCalculationOrder: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: SetCalculationOrder: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SETCALCORDER
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if ctx.AcroForm == nil {
+		return errors.New("pdfcpu: no form available")
+	}
+
+	if err := form.SetCalculationOrder(ctx, fieldIDs); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// SetCalculationOrderFile sets the calculation order of the form of inFile to the fields fieldIDs and writes the result to outFile.
+func SetCalculationOrderFile(inFile, outFile string, fieldIDs []string, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return SetCalculationOrder(f1, f2, fieldIDs, conf)
+}
+
+// FieldActions returns a list of the keystroke, format, validate and calculate actions of all form fields of rs.
+func FieldActions(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FieldActions: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTFIELDACTIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return form.FieldActions(ctx)
+}
+
+// FieldActionsFile returns a list of the keystroke, format, validate and calculate actions of all form fields of inFile.
+func FieldActionsFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return FieldActions(f, conf)
+}
+
+// FlattenForm exports the form data of rs to wJSON unless nil, draws the field appearances into the page content,
+// removes the form and writes the result to w.
+func FlattenForm(rs io.ReadSeeker, w, wJSON io.Writer, source string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FlattenForm: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: FlattenForm: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FLATTENFORM
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	if wJSON != nil {
+		ok, err := form.ExportForm(ctx.XRefTable, source, wJSON)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.New("no form fields exported")
+		}
+	}
+
+	ss, err := form.Flatten(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// FlattenFormFile exports the form data of inFile to outFileJSON unless empty, flattens the form and writes the result to outFile.
+func FlattenFormFile(inFile, outFile, outFileJSON string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2, f3 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	var wJSON io.Writer
+	if outFileJSON != "" {
+		if f3, err = os.Create(outFileJSON); err != nil {
+			f2.Close()
+			f1.Close()
+			return nil, err
+		}
+		log.CLI.Printf("writing %s...\n", outFileJSON)
+		wJSON = f3
+	}
+
+	defer func() {
+		if f3 != nil {
+			if err1 := f3.Close(); err == nil {
+				err = err1
+			}
+		}
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return FlattenForm(f1, f2, wJSON, inFile, conf)
+}
+
+// FieldPositions returns the positions of all form field widgets of rs on their rendered pages
+// relative to the upper left corner of the page as displayed.
+// Positions are normalized to 0..1 for dpi <= 0 or in pixels for rendering with dpi otherwise.
+func FieldPositions(rs io.ReadSeeker, dpi int, conf *model.Configuration) ([]form.FieldPosition, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: FieldPositions: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXPORTFIELDPOSITIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return form.FieldPositions(ctx, dpi)
+}
+
+// ExportFieldPositions writes the positions of all form field widgets of rs on their rendered pages as JSON to w.
+func ExportFieldPositions(rs io.ReadSeeker, w io.Writer, dpi int, conf *model.Configuration) error {
+	if w == nil {
+		return errors.New("pdfcpu: ExportFieldPositions: Please provide w")
+	}
+
+	fps, err := FieldPositions(rs, dpi, conf)
+	if err != nil {
+		return err
+	}
+
+	bb, err := json.MarshalIndent(fps, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bb)
+	return err
+}
+
+// ExportFieldPositionsFile writes the positions of all form field widgets of inFile on their rendered pages to outFileJSON.
+func ExportFieldPositionsFile(inFile, outFileJSON string, dpi int, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	if f2, err = os.Create(outFileJSON); err != nil {
+		f1.Close()
+		return err
+	}
+	log.CLI.Printf("writing %s...\n", outFileJSON)
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+	}()
+
+	return ExportFieldPositions(f1, f2, dpi, conf)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/ex-preman/pdfcpu/pkg/log"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 	"github.com/pkg/errors"
 )
 
@@ -81,3 +82,162 @@ func ListImagesFile(inFiles []string, selectedPages []string, conf *model.Config
 	}
 	return ss, nil
 }
+
+// ImageIssues returns a list of images of rs failing to decode, being truncated
+// or having sample data inconsistent with their declared geometry.
+func ImageIssues(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ImageIssues: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHECKIMAGES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.ImageIssues(ctx, pages)
+}
+
+// ImageIssuesFile returns a list of images of inFile failing to decode, being truncated
+// or having sample data inconsistent with their declared geometry.
+func ImageIssuesFile(inFile string, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ImageIssues(f, selectedPages, conf)
+}
+
+// PageResolutions returns the effective resolution of the main image of selected pages of rs
+// flagging resolutions below minDPI.
+func PageResolutions(rs io.ReadSeeker, selectedPages []string, minDPI float64, conf *model.Configuration) ([]pdfcpu.PageResolution, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: PageResolutions: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTRESOLUTIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.PageResolutions(ctx, pages, minDPI)
+}
+
+// PageResolutionsFile returns the effective resolution of the main image of selected pages of inFile
+// flagging resolutions below minDPI.
+func PageResolutionsFile(inFile string, selectedPages []string, minDPI float64, conf *model.Configuration) ([]pdfcpu.PageResolution, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PageResolutions(f, selectedPages, minDPI, conf)
+}
+
+// Pixelate pixelates the regions of the image id intersecting rects as rendered on page pageNr of rs and writes the result to w.
+// For blockSize <= 0 the block size gets derived from the size of a region.
+func Pixelate(rs io.ReadSeeker, w io.Writer, pageNr int, id string, rects []types.Rectangle, blockSize int, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: Pixelate: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: Pixelate: Please provide w")
+	}
+	if len(rects) == 0 {
+		return nil, errors.New("pdfcpu: Pixelate: Please provide rects")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.PIXELATE
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.Pixelate(ctx, pageNr, id, rects, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// PixelateFile pixelates the regions of the image id intersecting rects as rendered on page pageNr of inFile and writes the result to outFile.
+func PixelateFile(inFile, outFile string, pageNr int, id string, rects []types.Rectangle, blockSize int, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return Pixelate(f1, f2, pageNr, id, rects, blockSize, conf)
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pkg/errors"
 )
 
 // Info returns information about rs.
@@ -59,3 +60,58 @@ func InfoFile(inFile string, selectedPages []string, conf *model.Configuration)
 	defer f.Close()
 	return Info(f, selectedPages, conf)
 }
+
+// EstimateCost returns metrics of rs predictive of its processing cost
+// like page count, total image pixel count, object count, file size and whether rs is encrypted.
+// rs gets scanned without reading all of it.
+func EstimateCost(rs io.ReadSeeker, conf *model.Configuration) (*pdfcpu.CostEstimate, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: EstimateCost: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ESTIMATECOST
+
+	return pdfcpu.EstimateCost(rs, conf)
+}
+
+// EstimateCostFile returns metrics of inFile predictive of its processing cost.
+func EstimateCostFile(inFile string, conf *model.Configuration) (*pdfcpu.CostEstimate, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return EstimateCost(f, conf)
+}
+
+// VersionFeatures returns the version gated features used by rs like object streams, AES encryption, transparency, JPX images and 3D annotations
+// along with the minimum PDF version required compared to the declared version.
+// rs does not get validated so under-declared files get reported instead of rejected.
+func VersionFeatures(rs io.ReadSeeker, conf *model.Configuration) (*pdfcpu.VersionReport, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: VersionFeatures: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTVERSIONFEATURES
+
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.VersionFeatures(ctx), nil
+}
+
+// VersionFeaturesFile returns the version gated features used by inFile along with the minimum PDF version required.
+func VersionFeaturesFile(inFile string, conf *model.Configuration) (*pdfcpu.VersionReport, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return VersionFeatures(f, conf)
+}
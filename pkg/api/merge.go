@@ -24,6 +24,7 @@ import (
 	"github.com/ex-preman/pdfcpu/pkg/log"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 	"github.com/pkg/errors"
 )
 
@@ -205,3 +206,96 @@ func MergeAppendFile(inFiles []string, outFile string, conf *model.Configuration
 
 	return Merge(destFile, inFiles, f, conf)
 }
+
+func readSelectedPages(sel model.MergeSelection, conf *model.Configuration) (*model.Context, []int, error) {
+	f, err := os.Open(sel.InFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	ctx, _, _, _, err := readValidateAndOptimize(f, conf, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(sel.PageSelection) == 0 {
+		return ctx, PagesForPageRange(1, ctx.PageCount), nil
+	}
+
+	pages, err := PagesForPageCollection(ctx.PageCount, sel.PageSelection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(pages) == 0 {
+		return nil, nil, errors.Errorf("pdfcpu: no pages selected for %s", sel.InFile)
+	}
+
+	return ctx, pages, nil
+}
+
+// MergeSelected merges the selected pages of a sequence of PDF files in the order specified and writes the result to w.
+func MergeSelected(sels []model.MergeSelection, w io.Writer, conf *model.Configuration) error {
+	if len(sels) == 0 {
+		return errors.New("pdfcpu: MergeSelected: Please provide sels")
+	}
+
+	if w == nil {
+		return errors.New("pdfcpu: MergeSelected: Please provide w")
+	}
+
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.MERGESELECTED
+
+	ctxDest, err := pdfcpu.CreateContextWithXRefTable(conf, types.PaperSize["A4"])
+	if err != nil {
+		return err
+	}
+
+	for _, sel := range sels {
+		log.CLI.Printf("%s %v\n", sel.InFile, sel.PageSelection)
+
+		ctx, pages, err := readSelectedPages(sel, conf)
+		if err != nil {
+			return err
+		}
+
+		if err := pdfcpu.AddPages(ctx, ctxDest, pages, true); err != nil {
+			return err
+		}
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err := ValidateContext(ctxDest); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctxDest, w)
+}
+
+// MergeSelectedFile merges the selected pages of a sequence of PDF files in the order specified and writes the result to outFile.
+// An existing outFile will be overwritten.
+func MergeSelectedFile(sels []model.MergeSelection, outFile string, conf *model.Configuration) (err error) {
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		cerr := f.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+
+	log.CLI.Printf("writing %s...\n", outFile)
+	return MergeSelected(sels, f, conf)
+}
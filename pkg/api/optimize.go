@@ -41,6 +41,12 @@ func Optimize(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) error {
 		return err
 	}
 
+	if conf.JPEGQuality > 0 {
+		if err = pdfcpu.RecompressJPEGImages(ctx); err != nil {
+			return err
+		}
+	}
+
 	log.Stats.Printf("XRefTable:\n%s\n", ctx)
 	fromWrite := time.Now()
 
@@ -107,3 +113,159 @@ func OptimizeFile(inFile, outFile string, conf *model.Configuration) (err error)
 
 	return Optimize(f1, f2, conf)
 }
+
+// GarbageCollect frees all objects of rs unreachable from the document catalog and the document information dictionary
+// and writes the result to w. Unlike Optimize this leaves streams, duplicate resources and object numbers untouched.
+// Object streams and xref streams are written only if rs uses them.
+// The result is a list of all objects freed.
+func GarbageCollect(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: GarbageCollect: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: GarbageCollect: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.GARBAGECOLLECT
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.GarbageCollect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the object grouping of rs.
+	ctx.WriteObjectStream = ctx.Read.UsingObjectStreams
+	ctx.WriteXRefStream = ctx.Read.UsingXRefStreams || ctx.Read.UsingObjectStreams
+
+	return ss, WriteContext(ctx, w)
+}
+
+// GarbageCollectFile frees all objects of inFile unreachable from the document catalog and the document information dictionary
+// and writes the result to outFile.
+// The result is a list of all objects freed.
+func GarbageCollectFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return GarbageCollect(f1, f2, conf)
+}
+
+// ShareResources replaces equal extended graphics states, fonts and color spaces of different pages of rs by a single shared object,
+// moves equal resource dictionaries of sibling pages up the page tree and writes the result to w.
+// The result is a list of all modifications made.
+func ShareResources(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ShareResources: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ShareResources: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SHARERESOURCES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.ShareResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// ShareResourcesFile shares equal resources among the pages of inFile and writes the result to outFile.
+// The result is a list of all modifications made.
+func ShareResourcesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ShareResources(f1, f2, conf)
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 	"github.com/pkg/errors"
@@ -203,6 +204,731 @@ func RemovePagesFile(inFile, outFile string, selectedPages []string, conf *model
 	return RemovePages(f1, f2, selectedPages, conf)
 }
 
+// PageHashes returns a SHA-256 hash over content, resources and page boundaries for selected pages of rs.
+// Use these hashes to detect pages changed between versions of a document.
+func PageHashes(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) (map[int]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: PageHashes: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.HASHPAGES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.PageHashes(ctx, pages)
+}
+
+// PageHashesFile returns a SHA-256 hash over content, resources and page boundaries for selected pages of inFile.
+func PageHashesFile(inFile string, selectedPages []string, conf *model.Configuration) (map[int]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PageHashes(f, selectedPages, conf)
+}
+
+// NonStandardOperators returns a report of deprecated or non standard content stream operators used by selected pages of rs
+// along with the number of occurrences per page.
+func NonStandardOperators(rs io.ReadSeeker, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NonStandardOperators: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTOPERATORS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.NonStandardOperators(ctx, pages)
+}
+
+// NonStandardOperatorsFile returns a report of deprecated or non standard content stream operators used by selected pages of inFile.
+func NonStandardOperatorsFile(inFile string, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NonStandardOperators(f, selectedPages, conf)
+}
+
+// IsScannedDocument returns true if rs looks like a scan wrapped into PDF without OCR
+// along with the fraction of image only pages as confidence.
+func IsScannedDocument(rs io.ReadSeeker, conf *model.Configuration) (bool, float64, error) {
+	if rs == nil {
+		return false, 0, errors.New("pdfcpu: IsScannedDocument: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHECKSCANNED
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return false, 0, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return false, 0, err
+	}
+
+	ok, confidence := pdfcpu.IsScannedDocument(ctx)
+
+	return ok, confidence, nil
+}
+
+// IsScannedDocumentFile returns true if inFile looks like a scan wrapped into PDF without OCR
+// along with the fraction of image only pages as confidence.
+func IsScannedDocumentFile(inFile string, conf *model.Configuration) (bool, float64, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return false, 0, err
+	}
+	defer f.Close()
+
+	return IsScannedDocument(f, conf)
+}
+
+// TilePage splits page pageNr of rs into a grid of rows x cols pages overlapping by overlap user space units
+// and writes the result to w.
+func TilePage(rs io.ReadSeeker, w io.Writer, pageNr, rows, cols int, overlap float64, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: TilePage: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: TilePage: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.TILEPAGE
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	ctxDest, err := pdfcpu.TilePage(ctx, pageNr, rows, cols, overlap)
+	if err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctxDest); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctxDest, w)
+}
+
+// TilePageFile splits page pageNr of inFile into a grid of rows x cols pages overlapping by overlap user space units
+// and writes the result to outFile.
+func TilePageFile(inFile, outFile string, pageNr, rows, cols int, overlap float64, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return TilePage(f1, f2, pageNr, rows, cols, overlap, conf)
+}
+
+// RepairPages fixes pages of rs missing required entries and writes the result to w.
+// The result is a list of all repairs made.
+func RepairPages(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RepairPages: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RepairPages: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REPAIRPAGES
+
+	// Broken pages fail validation, so validate after repairing.
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.RepairPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = OptimizeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// RepairPagesFile fixes pages of inFile missing required entries and writes the result to outFile.
+// The result is a list of all repairs made.
+func RepairPagesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RepairPages(f1, f2, conf)
+}
+
+// DegeneratePages returns a list of all pages of rs with missing, zero or negative media box dimensions
+// and all pages without content and annotations.
+func DegeneratePages(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: DegeneratePages: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDEGENERATEPAGES
+
+	// Degenerate pages may fail validation.
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	_, ss, err := pdfcpu.DegeneratePages(ctx)
+
+	return ss, err
+}
+
+// DegeneratePagesFile returns a list of all degenerate pages of inFile.
+func DegeneratePagesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DegeneratePages(f, conf)
+}
+
+// RemoveDegeneratePages removes all pages of rs with missing, zero or negative media box dimensions
+// and all pages without content and annotations and writes the result to w.
+// The result is a list of all pages removed.
+func RemoveDegeneratePages(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: RemoveDegeneratePages: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: RemoveDegeneratePages: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEDEGENERATEPAGES
+
+	// Degenerate pages may fail validation, so skip validating pages about to be removed.
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	pages, ss, err := pdfcpu.DegeneratePages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pages) >= ctx.PageCount {
+		return nil, errors.New("pdfcpu: all pages are degenerate")
+	}
+
+	if err = OptimizeContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// WriteContext prunes the page tree for REMOVEPAGES.
+	ctx.Cmd = model.REMOVEPAGES
+	ctx.Write.SelectedPages = pages
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// TruncatePages removes all pages of rs following page maxPages and writes the result to w.
+// conf.MaxPages does not apply.
+func TruncatePages(rs io.ReadSeeker, w io.Writer, maxPages int, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: TruncatePages: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: TruncatePages: Please provide w")
+	}
+	if maxPages < 1 {
+		return errors.Errorf("pdfcpu: TruncatePages: invalid maxPages: %d", maxPages)
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.TRUNCATEPAGES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	if ctx.PageCount > maxPages {
+		// WriteContext prunes the page tree for REMOVEPAGES.
+		ctx.Cmd = model.REMOVEPAGES
+		ctx.Write.SelectedPages = types.IntSet{}
+		for i := maxPages + 1; i <= ctx.PageCount; i++ {
+			ctx.Write.SelectedPages[i] = true
+		}
+	}
+
+	return WriteContext(ctx, w)
+}
+
+// TruncatePagesFile removes all pages of inFile following page maxPages and writes the result to outFile.
+func TruncatePagesFile(inFile, outFile string, maxPages int, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return TruncatePages(f1, f2, maxPages, conf)
+}
+
+// RemoveDegeneratePagesFile removes all degenerate pages of inFile and writes the result to outFile.
+// The result is a list of all pages removed.
+func RemoveDegeneratePagesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RemoveDegeneratePages(f1, f2, conf)
+}
+
+// OversizedPages returns a list of all pages of rs with a media box width or height exceeding 14400 user units.
+func OversizedPages(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: OversizedPages: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTOVERSIZEDPAGES
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.OversizedPages(ctx)
+}
+
+// OversizedPagesFile returns a list of all pages of inFile with a media box width or height exceeding 14400 user units.
+func OversizedPagesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return OversizedPages(f, conf)
+}
+
+// ClampPages scales down all pages of rs exceeding 14400 user units in width or height and writes the result to w.
+// The result is a list of all pages scaled.
+func ClampPages(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ClampPages: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ClampPages: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CLAMPPAGES
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.ClampPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// ClampPagesFile scales down all pages of inFile exceeding 14400 user units in width or height and writes the result to outFile.
+// The result is a list of all pages scaled.
+func ClampPagesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ClampPages(f1, f2, conf)
+}
+
+// SplitContentPages returns a list of all content stream part boundaries of rs dividing a lexical token.
+func SplitContentPages(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: SplitContentPages: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTSPLITCONTENT
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.SplitContentPages(ctx)
+}
+
+// SplitContentPagesFile returns a list of all content stream part boundaries of inFile dividing a lexical token.
+func SplitContentPagesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return SplitContentPages(f, conf)
+}
+
+// ResplitContentPages moves content stream part boundaries of rs dividing a lexical token to the preceding token boundary and writes the result to w.
+// The result is a list of all pages fixed.
+func ResplitContentPages(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ResplitContentPages: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ResplitContentPages: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.RESPLITCONTENT
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.ResplitContentPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	return ss, WriteContext(ctx, w)
+}
+
+// ResplitContentPagesFile moves content stream part boundaries of inFile dividing a lexical token to the preceding token boundary and writes the result to outFile.
+// The result is a list of all pages fixed.
+func ResplitContentPagesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ResplitContentPages(f1, f2, conf)
+}
+
 // PageCount returns rs's page count.
 func PageCount(rs io.ReadSeeker, conf *model.Configuration) (int, error) {
 	ctx, err := ReadContext(rs, conf)
@@ -226,6 +952,27 @@ func PageCountFile(inFile string) (int, error) {
 	return PageCount(f, model.NewDefaultConfiguration())
 }
 
+// ReadPageCount returns rs's page count as recorded in its page tree root.
+// Unlike PageCount this avoids reading and validating all of rs where possible,
+// use it for cheap checks before doing expensive processing.
+func ReadPageCount(rs io.ReadSeeker, conf *model.Configuration) (int, error) {
+	if rs == nil {
+		return 0, errors.New("pdfcpu: ReadPageCount: Please provide rs")
+	}
+	return pdfcpu.ReadPageCount(rs, conf)
+}
+
+// ReadPageCountFile returns inFile's page count as recorded in its page tree root.
+func ReadPageCountFile(inFile string, conf *model.Configuration) (int, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return ReadPageCount(f, conf)
+}
+
 // PageDims returns a sorted slice of mediaBox dimensions for rs.
 func PageDims(rs io.ReadSeeker, conf *model.Configuration) ([]types.Dim, error) {
 	ctx, err := ReadContext(rs, conf)
@@ -140,6 +140,95 @@ func SetPermissionsFile(inFile, outFile string, conf *model.Configuration) (err
 	return SetPermissions(f1, f2, conf)
 }
 
+// ClearRestrictions removes the encryption of rs together with all user access restrictions and writes the result to w.
+// rs has to open with an empty user password unless the owner password is provided.
+// The result is a list of the restrictions cleared.
+func ClearRestrictions(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: ClearRestrictions: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: ClearRestrictions: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CLEARRESTRICTIONS
+	if conf.OwnerPW == "" && conf.UserPW != "" {
+		return nil, errors.New("pdfcpu: ClearRestrictions: without owner password rs has to open with an empty user password")
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		if errors.Cause(err) == pdfcpu.ErrWrongPassword {
+			return nil, errors.New("pdfcpu: ClearRestrictions: this file needs a user password, please provide the owner password with -opw")
+		}
+		return nil, err
+	}
+
+	ss := pdfcpu.Restrictions(ctx)
+	if len(ss) == 0 {
+		ss = []string{"no restrictions"}
+	}
+
+	fromWrite := time.Now()
+	if err = WriteContext(ctx, w); err != nil {
+		return nil, err
+	}
+
+	durWrite := time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return ss, nil
+}
+
+// ClearRestrictionsFile removes the encryption of inFile together with all user access restrictions and writes the result to outFile.
+// inFile has to open with an empty user password unless the owner password is provided.
+// The result is a list of the restrictions cleared.
+func ClearRestrictionsFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return ClearRestrictions(f1, f2, conf)
+}
+
 // GetPermissions returns the permissions for rs.
 func GetPermissions(rs io.ReadSeeker, conf *model.Configuration) (*int16, error) {
 	if conf == nil {
@@ -24,6 +24,7 @@ import (
 	"github.com/ex-preman/pdfcpu/pkg/log"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pkg/errors"
 )
 
 // Rotate rotates selected pages of rs clockwise by rotation degrees and writes the result to w.
@@ -116,3 +117,220 @@ func RotateFile(inFile, outFile string, rotation int, selectedPages []string, co
 
 	return Rotate(f1, f2, rotation, selectedPages, conf)
 }
+
+// NormalizeRotation bakes the rotation of selected pages of rs into their content and writes the result to w.
+// All processed pages end up with rotation 0 and media boxes reflecting their visual orientation.
+// The result is a list of the pages processed.
+func NormalizeRotation(rs io.ReadSeeker, w io.Writer, selectedPages []string, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NormalizeRotation: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: NormalizeRotation: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NORMALIZEROTATION
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	from := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.NormalizeRotation(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+	durNormalize := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = WriteContext(ctx, w); err != nil {
+		return nil, err
+	}
+
+	durWrite := durNormalize + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "normalize rotation, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return ss, nil
+}
+
+// NormalizeRotationFile bakes the rotation of selected pages of inFile into their content and writes the result to outFile.
+// The result is a list of the pages processed.
+func NormalizeRotationFile(inFile, outFile string, selectedPages []string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return NormalizeRotation(f1, f2, selectedPages, conf)
+}
+
+// PageRotations returns a list of the rotation in effect for each page of rs resolving /Rotate inheritance.
+func PageRotations(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: PageRotations: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTPAGEROTATIONS
+
+	ctx, _, _, err := readAndValidate(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	return pdfcpu.PageRotations(ctx)
+}
+
+// PageRotationsFile returns a list of the rotation in effect for each page of inFile resolving /Rotate inheritance.
+func PageRotationsFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PageRotations(f, conf)
+}
+
+// NormalizeRotateInheritance sets /Rotate on each page of rs to the rotation in effect,
+// removes /Rotate from intermediate page tree nodes and writes the result to w.
+// The result is a list of all pages and nodes modified.
+func NormalizeRotateInheritance(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NormalizeRotateInheritance: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: NormalizeRotateInheritance: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FIXPAGEROTATIONS
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.NormalizeRotateInheritance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// NormalizeRotateInheritanceFile sets /Rotate on each page of inFile to the rotation in effect,
+// removes /Rotate from intermediate page tree nodes and writes the result to outFile.
+// The result is a list of all pages and nodes modified.
+func NormalizeRotateInheritanceFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return NormalizeRotateInheritance(f1, f2, conf)
+}
@@ -60,12 +60,20 @@ func spanFileName(fileName string, from, thru int) string {
 	return fn + "-" + strconv.Itoa(thru) + ".pdf"
 }
 
-func splitOutPath(outDir, fileName string, forBookmark bool, from, thru int) string {
-	p := filepath.Join(outDir, fileName+".pdf")
+func splitOutPath(conf *model.Configuration, outDir, fileName string, forBookmark bool, index, from, thru int) string {
+	defName := fileName + ".pdf"
 	if !forBookmark {
-		p = filepath.Join(outDir, spanFileName(fileName, from, thru))
+		defName = spanFileName(fileName, from, thru)
 	}
-	return p
+	n := model.OutputName{
+		Base:  strings.TrimSuffix(filepath.Base(fileName), ".pdf"),
+		Page:  from,
+		Index: index,
+		From:  from,
+		To:    thru,
+		Ext:   "pdf",
+	}
+	return filepath.Join(outDir, conf.OutputFileName(defName, n))
 }
 
 func writePageSpan(ctx *model.Context, from, thru int, outPath string) error {
@@ -160,13 +168,13 @@ func writePageSpansSplitAlongBookmarks(ctx *model.Context, outDir string) error
 		return err
 	}
 
-	for _, bm := range bms {
+	for i, bm := range bms {
 		fileName := strings.Replace(bm.Title, " ", "_", -1)
 		from, thru := bm.PageFrom, bm.PageThru
 		if thru == 0 {
 			thru = ctx.PageCount
 		}
-		path := splitOutPath(outDir, fileName, forBookmark, from, thru)
+		path := splitOutPath(ctx.Configuration, outDir, fileName, forBookmark, i+1, from, thru)
 		if err := writePageSpan(ctx, from, thru, path); err != nil {
 			return err
 		}
@@ -181,7 +189,7 @@ func writePageSpans(ctx *model.Context, span int, outDir, fileName string) error
 	for i := 0; i < ctx.PageCount/span; i++ {
 		start := i * span
 		from, thru := start+1, start+span
-		path := splitOutPath(outDir, fileName, forBookmark, from, thru)
+		path := splitOutPath(ctx.Configuration, outDir, fileName, forBookmark, i+1, from, thru)
 		if err := writePageSpan(ctx, from, thru, path); err != nil {
 			return err
 		}
@@ -191,7 +199,7 @@ func writePageSpans(ctx *model.Context, span int, outDir, fileName string) error
 	if ctx.PageCount%span > 0 {
 		start := (ctx.PageCount / span) * span
 		from, thru := start+1, ctx.PageCount
-		path := splitOutPath(outDir, fileName, forBookmark, from, thru)
+		path := splitOutPath(ctx.Configuration, outDir, fileName, forBookmark, ctx.PageCount/span+1, from, thru)
 		if err := writePageSpan(ctx, from, thru, path); err != nil {
 			return err
 		}
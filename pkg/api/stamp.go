@@ -121,6 +121,112 @@ func AddWatermarksMapFile(inFile, outFile string, m map[int]*model.Watermark, co
 	return AddWatermarksMap(f1, f2, m, conf)
 }
 
+// AddPageNumbers stamps page numbers configured by pn onto the pages of rs and writes the result to w.
+func AddPageNumbers(rs io.ReadSeeker, w io.Writer, pn *pdfcpu.PageNumbering, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: AddPageNumbers: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: AddPageNumbers: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDPAGENUMBERS
+
+	if pn == nil {
+		pn = pdfcpu.DefaultPageNumbering()
+	}
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+
+	skip, err := PagesForPageSelection(ctx.PageCount, pn.Skip, false)
+	if err != nil {
+		return err
+	}
+
+	m, err := pdfcpu.PageNumberWatermarks(pn, ctx.PageCount, skip, conf.Unit)
+	if err != nil {
+		return err
+	}
+
+	if err = pdfcpu.AddWatermarksMap(ctx, m); err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durStamp := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durStamp + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "page numbers, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// AddPageNumbersFile stamps page numbers configured by pn onto the pages of inFile and writes the result to outFile.
+func AddPageNumbersFile(inFile, outFile string, pn *pdfcpu.PageNumbering, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return AddPageNumbers(f1, f2, pn, conf)
+}
+
 // AddWatermarksSliceMap adds watermarks in m to corresponding pages in rs and writes the result to w.
 func AddWatermarksSliceMap(rs io.ReadSeeker, w io.Writer, m map[int][]*model.Watermark, conf *model.Configuration) error {
 	if conf == nil {
@@ -558,3 +664,95 @@ func UpdatePDFWatermarksFile(inFile, outFile string, selectedPages []string, onT
 	}
 	return AddWatermarksFile(inFile, outFile, selectedPages, wm, conf)
 }
+
+// RecolorWatermarks renders watermarks of all pages selected in rs using gray level gray and opacity opacity and writes the result to w.
+func RecolorWatermarks(rs io.ReadSeeker, w io.Writer, selectedPages []string, gray, opacity float64, conf *model.Configuration) error {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.RECOLORWATERMARKS
+
+	fromStart := time.Now()
+	ctx, durRead, durVal, durOpt, err := readValidateAndOptimize(rs, conf, fromStart)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	from := time.Now()
+	pages, err := PagesForPageSelection(ctx.PageCount, selectedPages, true)
+	if err != nil {
+		return err
+	}
+
+	if err = pdfcpu.RecolorWatermarks(ctx, pages, gray, opacity); err != nil {
+		return err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	durStamp := time.Since(from).Seconds()
+	fromWrite := time.Now()
+
+	if err = WriteContext(ctx, w); err != nil {
+		return err
+	}
+
+	durWrite := durStamp + time.Since(fromWrite).Seconds()
+	durTotal := time.Since(fromStart).Seconds()
+	logOperationStats(ctx, "watermark, write", durRead, durVal, durOpt, durWrite, durTotal)
+
+	return nil
+}
+
+// RecolorWatermarksFile renders watermarks of all selected pages of inFile using gray level gray and opacity opacity and writes the result to outFile.
+func RecolorWatermarksFile(inFile, outFile string, selectedPages []string, gray, opacity float64, conf *model.Configuration) (err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return RecolorWatermarks(f1, f2, selectedPages, gray, opacity, conf)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/form"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pkg/errors"
+)
+
+// ReadTemplateRecords reads template records from rd.
+// JSON input is expected to be an array of objects, CSV input holds the token names in its first row.
+func ReadTemplateRecords(rd io.Reader, format form.DataFormat) ([]map[string]string, error) {
+	if format == form.JSON {
+		var records []map[string]string
+		if err := json.NewDecoder(rd).Decode(&records); err != nil {
+			return nil, errors.Errorf("pdfcpu: invalid json input: %v", err)
+		}
+		return records, nil
+	}
+
+	csvLines, err := parseCSVLines(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	names := csvLines[0]
+	var records []map[string]string
+
+	for _, line := range csvLines[1:] {
+		rec := map[string]string{}
+		for i, name := range names {
+			if i < len(line) {
+				rec[strings.TrimSpace(name)] = line[i]
+			}
+		}
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+// FillTemplatePage creates one copy of page pageNr of rs for each record,
+// replaces the tokens like {{name}} in the page content with the record values and writes the result to w.
+func FillTemplatePage(rs io.ReadSeeker, w io.Writer, pageNr int, records []map[string]string, conf *model.Configuration) error {
+	if rs == nil {
+		return errors.New("pdfcpu: FillTemplatePage: Please provide rs")
+	}
+	if w == nil {
+		return errors.New("pdfcpu: FillTemplatePage: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FILLTEMPLATE
+
+	ctx, _, _, _, err := readValidateAndOptimize(rs, conf, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid template page number: %d", pageNr)
+	}
+
+	ctxDest, err := pdfcpu.FillTemplatePage(ctx, pageNr, records)
+	if err != nil {
+		return err
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctxDest); err != nil {
+			return err
+		}
+	}
+
+	return WriteContext(ctxDest, w)
+}
+
+// FillTemplatePageFile creates one copy of page pageNr of inFile for each record of inFileData (JSON or CSV),
+// replaces the tokens like {{name}} in the page content with the record values and writes the result to outFile.
+func FillTemplatePageFile(inFile, inFileData, outFile string, pageNr int, conf *model.Configuration) (err error) {
+	var f0, f1, f2 *os.File
+
+	format := form.JSON
+	if strings.HasSuffix(strings.ToLower(inFileData), ".csv") {
+		format = form.CSV
+	}
+
+	if f0, err = os.Open(inFileData); err != nil {
+		return err
+	}
+
+	records, err := ReadTemplateRecords(f0, format)
+	f0.Close()
+	if err != nil {
+		return err
+	}
+
+	if f1, err = os.Open(inFile); err != nil {
+		return err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return FillTemplatePage(f1, f2, pageNr, records, conf)
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package test
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
@@ -413,3 +416,216 @@ func TestAddLinkAnnotationWithDest(t *testing.T) {
 		t.Fatalf("%s add: %v\n", msg, err)
 	}
 }
+
+func TestAnnotationIssues(t *testing.T) {
+	msg := "TestAnnotationIssues"
+
+	fn := "test.pdf"
+	inFile := filepath.Join(inDir, fn)
+	outFile := filepath.Join(outDir, "AnnotationIssues.pdf")
+
+	// A clean file has no annotation issues.
+	ss, err := api.AnnotationIssuesFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no issues, got: %v\n", msg, ss)
+	}
+
+	partlyOutside := model.NewLinkAnnotation(*types.NewRectangle(-50, 200, 50, 300), nil, nil, "https://pdfcpu.io", "ID3", 0, nil, false)
+	outside := model.NewLinkAnnotation(*types.NewRectangle(10000, 10000, 10100, 10100), nil, nil, "https://pdfcpu.io", "ID4", 0, nil, false)
+
+	// textAnn and linkAnn overlap.
+	m := map[int][]model.AnnotationRenderer{1: {textAnn, linkAnn, partlyOutside, outside}}
+	if err := api.AddAnnotationsMapFile(inFile, outFile, m, nil, false); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	if ss, err = api.AnnotationIssuesFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := []string{"(id: ID2) overlaps", "(id: ID3): /Rect", "partly outside CropBox", "(id: ID4): /Rect"}
+	if len(ss) != 3 {
+		t.Fatalf("%s: want 3 issues, got: %v\n", msg, ss)
+	}
+	s := strings.Join(ss, "\n")
+	for _, w := range want {
+		if !strings.Contains(s, w) {
+			t.Fatalf("%s: missing issue %q in: %v\n", msg, w, ss)
+		}
+	}
+}
+
+func TestClampAnnotations(t *testing.T) {
+	msg := "TestClampAnnotations"
+
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "ClampAnnotations.pdf")
+
+	partlyOutside := model.NewLinkAnnotation(*types.NewRectangle(-50, 200, 50, 300), nil, nil, "https://pdfcpu.io", "ID3", 0, nil, false)
+	outside := model.NewLinkAnnotation(*types.NewRectangle(10000, 10000, 10100, 10100), nil, nil, "https://pdfcpu.io", "ID4", 0, nil, false)
+
+	m := map[int][]model.AnnotationRenderer{1: {textAnn, partlyOutside, outside}}
+	if err := api.AddAnnotationsMapFile(inFile, outFile, m, nil, false); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	// Text annotations only: nothing to clamp.
+	ss, err := api.ClampAnnotationsFile(outFile, "", nil, []string{"Text"}, nil)
+	if err != nil {
+		t.Fatalf("%s clamp: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s clamp: want no repairs, got: %v\n", msg, ss)
+	}
+
+	if _, err := api.ClampAnnotationsFile(outFile, "", nil, []string{"NoAnnotType"}, nil); err == nil {
+		t.Fatalf("%s clamp: want error for unknown annotation type\n", msg)
+	}
+
+	if ss, err = api.ClampAnnotationsFile(outFile, "", nil, nil, nil); err != nil {
+		t.Fatalf("%s clamp: %v\n", msg, err)
+	}
+	if len(ss) != 2 || !strings.Contains(ss[0], "clamped to") || !strings.Contains(ss[1], "removed") {
+		t.Fatalf("%s clamp: want 2 repairs, got: %v\n", msg, ss)
+	}
+
+	// The clamped link lies within the page and the link outside is gone.
+	if ss, err = api.AnnotationIssuesFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no issues, got: %v\n", msg, ss)
+	}
+
+	if i, _, err := api.ListAnnotationsFile(outFile, nil, nil); err != nil || i != 2 {
+		t.Fatalf("%s list: want 2 annotations, got: %d %v\n", msg, i, err)
+	}
+}
+
+func TestDuplicateAnnotations(t *testing.T) {
+	msg := "TestDuplicateAnnotations"
+
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "DuplicateAnnotations.pdf")
+
+	// Exact duplicates of textAnn and linkAnn differing by id only.
+	dupText := model.NewTextAnnotation(*types.NewRectangle(0, 0, 100, 100), "Test Content", "ID5", "Title1", 0, &color.Gray, nil, "", "", false, "Comment")
+	dupLink := model.NewLinkAnnotation(*types.NewRectangle(0, 0, 100, 100), nil, nil, "https://pdfcpu.io", "ID6", 0, nil, false)
+
+	// Same rect and subtype as textAnn but different contents.
+	otherText := model.NewTextAnnotation(*types.NewRectangle(0, 0, 100, 100), "Other Content", "ID7", "Title1", 0, &color.Gray, nil, "", "", false, "Comment")
+
+	m := map[int][]model.AnnotationRenderer{
+		1: {textAnn, linkAnn, dupText, otherText, dupLink},
+		2: {textAnn},
+	}
+	if err := api.AddAnnotationsMapFile(inFile, outFile, m, nil, false); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	ss, err := api.DuplicateAnnotationsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s list: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s list: want 2 duplicates, got: %v\n", msg, ss)
+	}
+
+	// Listing leaves the file untouched.
+	if i, _, err := api.ListAnnotationsFile(outFile, nil, nil); err != nil || i != 6 {
+		t.Fatalf("%s list: want 6 annotations, got: %d %v\n", msg, i, err)
+	}
+
+	if ss, err = api.RemoveDuplicateAnnotationsFile(outFile, "", []string{"2"}, nil); err != nil {
+		t.Fatalf("%s remove: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s remove: want no duplicates on page 2, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.RemoveDuplicateAnnotationsFile(outFile, "", nil, nil); err != nil {
+		t.Fatalf("%s remove: %v\n", msg, err)
+	}
+	if len(ss) != 2 || !strings.Contains(ss[0], "removed duplicate") {
+		t.Fatalf("%s remove: want 2 removals, got: %v\n", msg, ss)
+	}
+
+	if i, _, err := api.ListAnnotationsFile(outFile, nil, nil); err != nil || i != 4 {
+		t.Fatalf("%s list: want 4 annotations, got: %d %v\n", msg, i, err)
+	}
+
+	if ss, err = api.DuplicateAnnotationsFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s list: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s list: want no duplicates, got: %v\n", msg, ss)
+	}
+}
+
+func TestMaxAnnotations(t *testing.T) {
+	msg := "TestMaxAnnotations"
+	inFile := filepath.Join(inDir, "annotTest.pdf")
+
+	conf := model.NewDefaultConfiguration()
+	conf.MaxAnnotations = 1000
+	if err := api.ValidateFile(inFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Annotations of previous revisions no longer referenced count too.
+	i, _, err := api.ListAnnotationsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	conf.MaxAnnotations = i
+	if err := api.ValidateFile(inFile, conf); !errors.Is(err, pdfcpu.ErrMaxAnnotationsExceeded) {
+		t.Fatalf("%s: want ErrMaxAnnotationsExceeded, got: %v\n", msg, err)
+	}
+
+	// Files without annotations are not affected.
+	conf.MaxAnnotations = 1
+	if err := api.ValidateFile(filepath.Join(inDir, "test.pdf"), conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestMaxAnnotationsObjectStreams(t *testing.T) {
+	msg := "TestMaxAnnotationsObjectStreams"
+	inFile := filepath.Join(inDir, "annotTest.pdf")
+	outFile := filepath.Join(outDir, "annotTestObjStm.pdf")
+
+	// Write annotTest.pdf packing all annotations into object streams.
+	conf := model.NewDefaultConfiguration()
+	conf.WriteObjectStream = true
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !ctx.Read.UsingObjectStreams {
+		t.Fatalf("%s: want object streams\n", msg)
+	}
+
+	conf = model.NewDefaultConfiguration()
+	conf.MaxAnnotations = 1000
+	if err := api.ValidateFile(outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Reading aborts while decoding the object streams.
+	conf.MaxAnnotations = 1
+	f, err := os.Open(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+	if _, err := api.ReadContext(f, conf); !errors.Is(err, pdfcpu.ErrMaxAnnotationsExceeded) {
+		t.Fatalf("%s: want ErrMaxAnnotationsExceeded, got: %v\n", msg, err)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -168,6 +169,90 @@ func TestPageCount(t *testing.T) {
 	}
 }
 
+func TestEstimateCost(t *testing.T) {
+	msg := "TestEstimateCost"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "go.pdf")
+
+	ce, err := api.EstimateCostFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n, err := api.PageCountFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ce.PageCount != n || ce.ImageCount != 2 || ce.ImagePixels < 1536*1152 || ce.Encrypted {
+		t.Fatalf("%s: unexpected estimate: %v\n", msg, ce.List())
+	}
+
+	conf := model.NewAESConfiguration("upw", "opw", 256)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf = model.NewAESConfiguration("upw", "opw", 256)
+	ce1, err := api.EstimateCostFile(outFile, conf)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ce1.PageCount != n || ce1.ImageCount != 2 || ce1.ImagePixels != ce.ImagePixels || !ce1.Encrypted {
+		t.Fatalf("%s: unexpected estimate: %v\n", msg, ce1.List())
+	}
+}
+
+func TestVersionFeatures(t *testing.T) {
+	msg := "TestVersionFeatures"
+	inFile := filepath.Join(inDir, "testImage.pdf")
+	outFile := filepath.Join(outDir, "testImage.pdf")
+
+	hasFeature := func(vr *pdfcpu.VersionReport, name string) bool {
+		for _, f := range vr.Features {
+			if f.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	// testImage.pdf embeds a JPX image.
+	vr, err := api.VersionFeaturesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !hasFeature(vr, pdfcpu.FeatureJPX) || vr.Required != model.V15 || vr.UnderDeclared() {
+		t.Fatalf("%s: unexpected report: %v\n", msg, vr.List())
+	}
+
+	// Declare 1.4 for a file using 1.5 features.
+	bb, err := os.ReadFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb = bytes.Replace(bb, []byte("%PDF-1.7"), []byte("%PDF-1.4"), 1)
+	if err := os.WriteFile(outFile, bb, os.ModePerm); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if vr, err = api.VersionFeaturesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if vr.Declared != model.V14 || !vr.UnderDeclared() {
+		t.Fatalf("%s: want under-declared, got: %v\n", msg, vr.List())
+	}
+
+	conf := model.NewAESConfiguration("upw", "opw", 256)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	conf = model.NewAESConfiguration("upw", "opw", 256)
+	if vr, err = api.VersionFeaturesFile(outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !hasFeature(vr, pdfcpu.FeatureAES256) || vr.Required != model.V17 {
+		t.Fatalf("%s: unexpected report: %v\n", msg, vr.List())
+	}
+}
+
 func TestPageDimensions(t *testing.T) {
 	msg := "TestPageDimensions"
 	for _, fn := range AllPDFs(t, inDir) {
@@ -190,6 +275,176 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestDanglingReferences(t *testing.T) {
+	msg := "TestDanglingReferences"
+	inFile := filepath.Join(inDir, "T4.pdf")
+
+	ss, err := api.DanglingReferencesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 || ss[0] != "obj#1280 /JT: (1277 0 R) points to missing object" {
+		t.Fatalf("%s: want 1 dangling reference, got: %v\n", msg, ss)
+	}
+
+	ctx, err := api.ReadContextFile(filepath.Join(inDir, "Acroforms2.pdf"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if drs := pdfcpu.DanglingReferences(ctx); len(drs) > 0 {
+		t.Fatalf("%s: want no dangling references, got: %v\n", msg, drs)
+	}
+
+	// Reference a missing object from within an array of the first page.
+	d, pageIndRef, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Foo"] = types.Array{types.Integer(1), *types.NewIndirectRef(*ctx.Size+10, 0)}
+
+	drs := pdfcpu.DanglingReferences(ctx)
+	if len(drs) != 1 || drs[0].ObjNr != pageIndRef.ObjectNumber.Value() || drs[0].Path != "/Foo[1]" {
+		t.Fatalf("%s: want dangling reference at /Foo[1], got: %v\n", msg, drs)
+	}
+}
+
+func TestNullDanglingReferences(t *testing.T) {
+	msg := "TestNullDanglingReferences"
+	inFile := filepath.Join(inDir, "T4.pdf")
+	outFile := filepath.Join(outDir, "T4.pdf")
+
+	ss, err := api.NullDanglingReferencesFile(inFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("%s: want 1 replaced reference, got: %v\n", msg, ss)
+	}
+
+	ss, err = api.DanglingReferencesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no dangling references, got: %v\n", msg, ss)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func metadataFilter(t *testing.T, fileName string) string {
+	t.Helper()
+	msg := "metadataFilter"
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ctx.RootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing metadata stream: %v\n", msg, err)
+	}
+	if f := sd.NameEntry("Filter"); f != nil {
+		return *f
+	}
+	return ""
+}
+
+func TestCompressMetadata(t *testing.T) {
+	msg := "TestCompressMetadata"
+	inFile := filepath.Join(inDir, "T4.pdf")
+	outFile := filepath.Join(outDir, "T4.pdf")
+
+	for _, tt := range []struct {
+		compressMetadata int
+		filter           string
+	}{
+		{model.MetadataUncompress, ""},
+		{model.MetadataCompress, "FlateDecode"},
+	} {
+		conf := model.NewDefaultConfiguration()
+		conf.CompressMetadata = tt.compressMetadata
+		if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if f := metadataFilter(t, outFile); f != tt.filter {
+			t.Fatalf("%s %s: want filter %q, got %q\n", msg, conf.CompressMetadataString(), tt.filter, f)
+		}
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
+
+// catalogInObjStm returns a minimal PDF with catalog and page tree living in an object stream.
+// The xref is either a cross-reference stream or a hybrid xref table marking the compressed objects free (hidden) or omitting them.
+func catalogInObjStm(hybrid string) []byte {
+	objs := []string{
+		"<</Type/Catalog/Pages 2 0 R>>",
+		"<</Type/Pages/Kids[3 0 R]/Count 1>>",
+		"<</Type/Page/Parent 2 0 R/MediaBox[0 0 200 200]>>",
+	}
+
+	var hdr, body string
+	for i, o := range objs {
+		hdr += fmt.Sprintf("%d %d ", i+1, len(body))
+		body += o + "\n"
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+
+	off4 := b.Len()
+	fmt.Fprintf(&b, "4 0 obj\n<</Type/ObjStm/N 3/First %d/Length %d>>\nstream\n%s%s\nendstream\nendobj\n", len(hdr), len(hdr+body), hdr, body)
+
+	off5 := b.Len()
+	xref := []byte{0, 0, 0, 255}
+	for i := 0; i < 3; i++ {
+		xref = append(xref, 2, 0, 4, byte(i))
+	}
+	xref = append(xref, 1, byte(off4>>8), byte(off4), 0, 1, byte(off5>>8), byte(off5), 0)
+
+	root := "/Root 1 0 R"
+	if hybrid != "" {
+		root = ""
+	}
+	fmt.Fprintf(&b, "5 0 obj\n<</Type/XRef/Size 6/W[1 2 1]%s/Length %d>>\nstream\n%s\nendstream\nendobj\n", root, len(xref), xref)
+
+	if hybrid == "" {
+		fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", off5)
+		return b.Bytes()
+	}
+
+	offXRef := b.Len()
+	b.WriteString("xref\n0 1\n0000000000 65535 f\r\n")
+	if hybrid == "hidden" {
+		b.WriteString("1 3\n0000000000 00000 f\r\n0000000000 00000 f\r\n0000000000 00000 f\r\n")
+	}
+	fmt.Fprintf(&b, "4 2\n%010d 00000 n\r\n%010d 00000 n\r\n", off4, off5)
+	fmt.Fprintf(&b, "trailer\n<</Size 6/Root 1 0 R/XRefStm %d>>\nstartxref\n%d\n%%%%EOF\n", off5, offXRef)
+
+	return b.Bytes()
+}
+
+func TestCatalogInObjectStream(t *testing.T) {
+	for _, hybrid := range []string{"", "omitted", "hidden"} {
+		msg := "TestCatalogInObjectStream " + hybrid
+
+		ctx, err := api.ReadContext(bytes.NewReader(catalogInObjStm(hybrid)), model.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.ValidateContext(ctx); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if ctx.PageCount != 1 {
+			t.Fatalf("%s: want 1 page, got: %d\n", msg, ctx.PageCount)
+		}
+	}
+}
+
 func TestManipulateContext(t *testing.T) {
 	msg := "TestManipulateContext"
 	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
@@ -218,6 +473,65 @@ func TestManipulateContext(t *testing.T) {
 	}
 }
 
+func pageTreeDepths(t *testing.T, ctx *model.Context, ir types.IndirectRef, depth, maxKids int, depths map[int]bool) {
+	t.Helper()
+	d, err := ctx.DereferenceDict(ir)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if *d.Type() == "Page" {
+		depths[depth] = true
+		return
+	}
+	kids := d.ArrayEntry("Kids")
+	if len(kids) > maxKids {
+		t.Fatalf("page tree node obj#%d: %d kids, want <= %d\n", ir.ObjectNumber, len(kids), maxKids)
+	}
+	for _, o := range kids {
+		pageTreeDepths(t, ctx, o.(types.IndirectRef), depth+1, maxKids, depths)
+	}
+}
+
+func TestBalancePageTree(t *testing.T) {
+	msg := "TestBalancePageTree"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "balanced.pdf")
+
+	conf := model.NewDefaultConfiguration()
+	conf.BalancePageTree = true
+	conf.PageTreeBranchingFactor = 4
+
+	// Write inFile using a balanced page tree with at most 4 kids per node.
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx.PageCount != 52 {
+		t.Fatalf("%s: pageCount want:52 got:%d\n", msg, ctx.PageCount)
+	}
+
+	root, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// 52 pages need 3 levels of page tree nodes, all pages at the same depth.
+	depths := map[int]bool{}
+	pageTreeDepths(t, ctx, *root, 0, 4, depths)
+	if len(depths) != 1 || !depths[3] {
+		t.Fatalf("%s: unbalanced page tree, page depths: %v\n", msg, depths)
+	}
+}
+
 func TestInfo(t *testing.T) {
 	msg := "TestInfo"
 	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
@@ -17,12 +17,14 @@
 package test
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/color"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 // Acrobat Reader "Bookmarks" = Mac Preview "Table of Contents".
@@ -76,3 +78,216 @@ func TestAddBookmarkTree2Levels(t *testing.T) {
 		t.Fatalf("%s addBookmarks: %v\n", msg, err)
 	}
 }
+
+func TestBrokenDestinations(t *testing.T) {
+	msg := "TestBrokenDestinations"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "brokenDestinations.pdf")
+
+	bms := []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "Page 1"},
+		{PageFrom: 2, Title: "Page 2"},
+	}
+
+	if err := api.AddBookmarksFile(inFile, outFile, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Point bookmark 2 to a missing page object, a link beyond the last page and the open action in front of the first page.
+	ir, err := ctx.Outlines()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*ir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, err = ctx.DereferenceDict(*d.IndirectRefEntry("Last")); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Dest"] = types.Array{*types.NewIndirectRef(99999, 0), types.Name("Fit")}
+
+	if d, _, _, err = ctx.PageDict(2, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Annots"] = types.Array{types.Dict{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Link"),
+		"Rect":    types.NewRectangle(0, 0, 100, 100).Array(),
+		"Dest":    types.Array{types.Integer(99), types.Name("Fit")},
+	}}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	root["OpenAction"] = types.Array{types.Integer(-1), types.Name("Fit")}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.ListBrokenDestinationsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 3 {
+		t.Fatalf("%s: want 3 broken destinations, got: %v\n", msg, ss)
+	}
+
+	outFile1 := filepath.Join(outDir, "brokenDestinationsRemoved.pdf")
+	if ss, err = api.FixBrokenDestinationsFile(outFile, outFile1, true, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 3 {
+		t.Fatalf("%s: want 3 removed destinations, got: %v\n", msg, ss)
+	}
+	if ss, err = api.ListBrokenDestinationsFile(outFile1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no broken destinations, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.FixBrokenDestinationsFile(outFile, "", false, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := []string{
+		`outline item "Page 2": page object obj#99999 missing, redirected to page 1`,
+		fmt.Sprintf("page 2 link: page index 99 out of range, redirected to page %d", ctx.PageCount),
+		"open action: page index -1 out of range, redirected to page 1",
+	}
+	if len(ss) != len(want) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, ss)
+	}
+	for i := range want {
+		if ss[i] != want[i] {
+			t.Fatalf("%s: want %s, got: %s\n", msg, want[i], ss[i])
+		}
+	}
+
+	// Nothing left to fix.
+	if ss, err = api.ListBrokenDestinationsFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no broken destinations, got: %v\n", msg, ss)
+	}
+}
+
+func TestConvertDestinations(t *testing.T) {
+	msg := "TestConvertDestinations"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "convertDestinations.pdf")
+
+	bms := []pdfcpu.Bookmark{
+		{PageFrom: 1, Title: "Page 1"},
+		{PageFrom: 2, Title: "Page 2"},
+	}
+
+	if err := api.AddBookmarksFile(inFile, outFile, bms, nil); err != nil {
+		t.Fatalf("%s addBookmarks: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Add two links to page 3 sharing the same destination.
+	_, ir, _, err := ctx.PageDict(3, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	link := func(y float64) types.Dict {
+		return types.Dict{
+			"Type":    types.Name("Annot"),
+			"Subtype": types.Name("Link"),
+			"Rect":    types.NewRectangle(0, y, 100, y+100).Array(),
+			"Dest":    types.Array{*ir, types.Name("Fit")},
+		}
+	}
+	d["Annots"] = types.Array{link(0), link(200)}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	outFile1 := filepath.Join(outDir, "convertDestinationsNamed.pdf")
+	ss, err := api.NameExplicitDestinationsFile(outFile, outFile1, nil)
+	if err != nil {
+		t.Fatalf("%s named: %v\n", msg, err)
+	}
+	want := []string{
+		`outline item "Page 1": named "page1"`,
+		`outline item "Page 2": named "page2"`,
+		`page 2 link: named "page3"`,
+		`page 2 link: named "page3"`,
+	}
+	if len(ss) != len(want) {
+		t.Fatalf("%s named: want %v, got: %v\n", msg, want, ss)
+	}
+	for i := range want {
+		if ss[i] != want[i] {
+			t.Fatalf("%s named: want %s, got: %s\n", msg, want[i], ss[i])
+		}
+	}
+
+	// All destinations are named now.
+	if ss, err = api.NameExplicitDestinationsFile(outFile1, "", nil); err != nil {
+		t.Fatalf("%s named: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s named: want no explicit destinations, got: %v\n", msg, ss)
+	}
+
+	// And resolvable.
+	if ss, err = api.ListBrokenDestinationsFile(outFile1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no broken destinations, got: %v\n", msg, ss)
+	}
+
+	outFile2 := filepath.Join(outDir, "convertDestinationsExplicit.pdf")
+	if ss, err = api.InlineNamedDestinationsFile(outFile1, outFile2, nil); err != nil {
+		t.Fatalf("%s explicit: %v\n", msg, err)
+	}
+	if len(ss) != 4 {
+		t.Fatalf("%s explicit: want 4 inlined destinations, got: %v\n", msg, ss)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile2); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, _, _, err = ctx.PageDict(2, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	_, ir, _, err = ctx.PageDict(3, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) != 2 {
+		t.Fatalf("%s: want 2 links, got: %v %v\n", msg, annots, err)
+	}
+	for _, o := range annots {
+		d1, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a, ok := d1["Dest"].(types.Array)
+		if !ok || len(a) != 2 || a[0] != *ir {
+			t.Fatalf("%s: want explicit destination to page 3, got: %v\n", msg, d1["Dest"])
+		}
+	}
+}
@@ -163,3 +163,66 @@ func TestEncryption(t *testing.T) {
 		testEncryption(t, fileName, "aes", 256)
 	}
 }
+
+func TestClearRestrictions(t *testing.T) {
+	msg := "TestClearRestrictions"
+	inFile := filepath.Join(inDir, "5116.DCT_Filter.pdf")
+	outFile := filepath.Join(outDir, "restricted.pdf")
+
+	// Encrypt file using an empty user password restricting all permissions.
+	conf := model.NewAESConfiguration("", "opw", 256)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+
+	// Clear restrictions w/o passwords.
+	ss, err := api.ClearRestrictionsFile(outFile, "", nil)
+	if err != nil {
+		t.Fatalf("%s: clear restrictions %s: %v\n", msg, outFile, err)
+	}
+	if len(ss) == 0 || ss[0] != "print" {
+		t.Fatalf("%s: unexpected restrictions: %v\n", msg, ss)
+	}
+
+	// Ensure full access.
+	p, err := api.GetPermissionsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: get permissions %s: %v\n", msg, outFile, err)
+	}
+	if p != nil {
+		t.Fatalf("%s: %s is still encrypted\n", msg, outFile)
+	}
+
+	// Clearing restrictions of a file needing a user password should fail.
+	conf = model.NewAESConfiguration("upw", "opw", 256)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+	if _, err := api.ClearRestrictionsFile(outFile, "", nil); err == nil {
+		t.Fatalf("%s: clear restrictions w/o upw %s\n", msg, outFile)
+	}
+
+	// Providing the user password only is not enough.
+	conf = model.NewAESConfiguration("upw", "", 256)
+	if _, err := api.ClearRestrictionsFile(outFile, "", conf); err == nil {
+		t.Fatalf("%s: clear restrictions w/o opw %s\n", msg, outFile)
+	}
+
+	// Using the owner password clearing restrictions works.
+	conf = model.NewAESConfiguration("", "opw", 256)
+	if _, err := api.ClearRestrictionsFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: clear restrictions %s: %v\n", msg, outFile, err)
+	}
+
+	// Clearing restrictions leaves the user password of conf alone.
+	conf = model.NewAESConfiguration("upw", "opw", 256)
+	if err := api.EncryptFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: encrypt %s: %v\n", msg, outFile, err)
+	}
+	if _, err := api.ClearRestrictionsFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: clear restrictions %s: %v\n", msg, outFile, err)
+	}
+	if conf.UserPW != "upw" {
+		t.Fatalf("%s: user password overwritten: %q\n", msg, conf.UserPW)
+	}
+}
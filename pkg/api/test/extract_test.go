@@ -17,14 +17,22 @@ limitations under the License.
 package test
 
 import (
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
+	"math"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 func TestExtractImages(t *testing.T) {
@@ -78,6 +86,104 @@ func TestExtractImagesLowLevel(t *testing.T) {
 	}
 }
 
+func TestExtractSoftMasks(t *testing.T) {
+	msg := "TestExtractSoftMasks"
+	inFile := filepath.Join(inDir, "pike-stanford.pdf")
+
+	names := func(conf *model.Configuration) map[string]model.Image {
+		m := map[string]model.Image{}
+		digest := func(img model.Image, singleImgPerPage bool, maxPageDigits int) error {
+			m[img.Name] = img
+			return nil
+		}
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		if err := api.ExtractImages(f, []string{"56"}, digest, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return m
+	}
+
+	// By default soft masks are not extracted.
+	if _, ok := names(nil)["Im5_smask"]; ok {
+		t.Fatalf("%s: unexpected soft mask\n", msg)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ExtractSoftMasks = true
+	m := names(conf)
+	if _, ok := m["Im5"]; !ok {
+		t.Fatalf("%s: missing image Im5, got: %v\n", msg, m)
+	}
+	sm, ok := m["Im5_smask"]
+	if !ok {
+		t.Fatalf("%s: missing soft mask Im5_smask, got: %v\n", msg, m)
+	}
+
+	img, err := png.Decode(sm)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, ok := img.(*image.Gray); !ok {
+		t.Fatalf("%s: want grayscale soft mask, got: %T\n", msg, img)
+	}
+}
+
+func TestApplySoftMasks(t *testing.T) {
+	msg := "TestApplySoftMasks"
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+
+	// Im0 is a JPEG image with a soft mask.
+	extract := func(conf *model.Configuration) model.Image {
+		var img *model.Image
+		digest := func(i model.Image, singleImgPerPage bool, maxPageDigits int) error {
+			if i.Name == "Im0" {
+				img = &i
+			}
+			return nil
+		}
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		if err := api.ExtractImages(f, []string{"1"}, digest, conf); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if img == nil {
+			t.Fatalf("%s: missing image Im0\n", msg)
+		}
+		return *img
+	}
+
+	// By default the soft mask is ignored.
+	if img := extract(nil); img.FileType != "jpg" {
+		t.Fatalf("%s: want jpg, got: %s\n", msg, img.FileType)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ApplySoftMasks = true
+	img := extract(conf)
+	if img.FileType != "png" {
+		t.Fatalf("%s: want png, got: %s\n", msg, img.FileType)
+	}
+
+	im, err := png.Decode(img)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	nrgba, ok := im.(*image.NRGBA)
+	if !ok {
+		t.Fatalf("%s: want RGBA image with transparency, got: %T\n", msg, im)
+	}
+	if b := nrgba.Bounds(); b.Dx() != 463 || b.Dy() != 737 {
+		t.Fatalf("%s: want 463x737, got: %dx%d\n", msg, b.Dx(), b.Dy())
+	}
+}
+
 func TestExtractFonts(t *testing.T) {
 	msg := "TestExtractFonts"
 	// Extract fonts for all pages into outDir.
@@ -229,3 +335,341 @@ func TestExtractMetadataLowLevel(t *testing.T) {
 			md.ObjNr, md.ParentObjNr, md.ParentType, string(bb))
 	}
 }
+
+func TestExtractPagesEPS(t *testing.T) {
+	msg := "TestExtractPagesEPS"
+	// Extract pages 1-2 as EPS into outDir.
+	for _, fn := range []string{"5116.DCT_Filter.pdf", "TheGoProgrammingLanguageCh1.pdf"} {
+		inFile := filepath.Join(inDir, fn)
+		if err := api.ExtractPagesEPSFile(inFile, outDir, []string{"1-2"}, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, inFile, err)
+		}
+		for i := 1; i <= 2; i++ {
+			outFile := filepath.Join(outDir, fmt.Sprintf("%s_page_%d.eps", strings.TrimSuffix(fn, ".pdf"), i))
+			bb, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatalf("%s %s: %v\n", msg, outFile, err)
+			}
+			s := string(bb)
+			if !strings.HasPrefix(s, "%!PS-Adobe-3.0 EPSF-3.0\n") || !strings.Contains(s, "%%BoundingBox: 0 0 ") {
+				t.Fatalf("%s %s: missing EPS header\n", msg, outFile)
+			}
+			if !strings.HasSuffix(s, "%%EOF\n") {
+				t.Fatalf("%s %s: missing EOF\n", msg, outFile)
+			}
+		}
+	}
+}
+
+func TestExtractICCProfiles(t *testing.T) {
+	msg := "TestExtractICCProfiles"
+	inFile := filepath.Join(inDir, "testImage.pdf")
+
+	ss, err := api.ListICCProfilesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	want := []string{
+		"obj#10 ICCBased N=4: Generic CMYK Profile",
+		"obj#19 ICCBased N=3: sRGB IEC61966-2.1",
+	}
+	if len(ss) != len(want) {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, ss)
+	}
+	for i := range want {
+		if ss[i] != want[i] {
+			t.Fatalf("%s: want %s, got: %s\n", msg, want[i], ss[i])
+		}
+	}
+
+	// Extract ICC profiles of output intents too.
+	for _, fn := range []string{"testImage.pdf", "TheGoProgrammingLanguageCh1.pdf"} {
+		fn = filepath.Join(inDir, fn)
+		if err := api.ExtractICCProfilesFile(fn, outDir, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+	}
+
+	fn := filepath.Join(outDir, "TheGoProgrammingLanguageCh1_OutputIntent_8627.icc")
+	bb, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, fn, err)
+	}
+	if len(bb) < 40 || string(bb[36:40]) != "acsp" {
+		t.Fatalf("%s %s: missing ICC profile signature\n", msg, fn)
+	}
+}
+
+func TestExtractParagraphs(t *testing.T) {
+	msg := "TestExtractParagraphs"
+	inFile := filepath.Join(inDir, "pike-stanford.pdf")
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	defer f.Close()
+
+	pp, err := api.Paragraphs(f, []string{"3"}, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(pp) != 1 || pp[0].Page != 3 {
+		t.Fatalf("%s: want page 3, got: %v\n", msg, pp)
+	}
+
+	want := []string{"Outline", "1. History", "2. A niche", "3. Tour of Go", "4. Status"}
+	bb := pp[0].Blocks
+	if len(bb) < len(want) {
+		t.Fatalf("%s: want at least %d blocks, got: %v\n", msg, len(want), bb)
+	}
+	for i, s := range want {
+		if bb[i].Text != s {
+			t.Fatalf("%s: block %d: want %q, got: %q\n", msg, i, s, bb[i].Text)
+		}
+	}
+	if bb[0].FontSize <= bb[1].FontSize {
+		t.Fatalf("%s: want title font size > %.2f, got: %.2f\n", msg, bb[1].FontSize, bb[0].FontSize)
+	}
+
+	// Lines of a paragraph get joined into a single block.
+	if pp, err = api.Paragraphs(f, []string{"2"}, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if b := pp[0].Blocks[1]; len(b.Lines) != 5 || !strings.HasPrefix(b.Text, "Russ Cox Robert Griesemer") {
+		t.Fatalf("%s: want block of 5 lines, got: %v\n", msg, b)
+	}
+
+	if err := api.ExtractParagraphsFile(inFile, outDir, nil, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	fn := filepath.Join(outDir, "pike-stanford_text.json")
+	if _, err := os.Stat(fn); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestExtractParagraphsFragmentedRuns(t *testing.T) {
+	msg := "TestExtractParagraphsFragmentedRuns"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "fragmentedRuns.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	ir, err := pdffont.EnsureFontDict(ctx.XRefTable, "Helvetica", "", "", false, false, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Show each glyph by a separate string operand, draw "World" twice for a fake bold effect.
+	widths := map[rune]float64{'H': .722, 'e': .556, 'l': .222, 'o': .556, 'W': .944, 'r': .333, 'd': .556}
+	var sb strings.Builder
+	sb.WriteString("BT /F0 12 Tf ")
+	x := 100.
+	for i, r := range "Hello World" {
+		if r == ' ' {
+			x += 4
+			continue
+		}
+		fmt.Fprintf(&sb, "1 0 0 1 %.3f 700 Tm (%c) Tj ", x, r)
+		if i > 5 {
+			fmt.Fprintf(&sb, "1 0 0 1 %.3f 700 Tm (%c) Tj ", x+.3, r)
+		}
+		x += widths[r]*12 + .2
+	}
+	sb.WriteString("ET")
+
+	sd, err := ctx.NewStreamDictForBuf([]byte(sb.String()))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	contents, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *contents
+	d["Resources"] = types.Dict{"Font": types.Dict{"F0": *ir}}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	f, err := os.Open(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	defer f.Close()
+
+	pp, err := api.Paragraphs(f, []string{"1"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(pp) != 1 || len(pp[0].Blocks) != 1 || len(pp[0].Blocks[0].Lines) != 1 {
+		t.Fatalf("%s: want a single line, got: %v\n", msg, pp)
+	}
+	if s := pp[0].Blocks[0].Text; s != "Hello World" {
+		t.Fatalf("%s: want \"Hello World\", got: %q\n", msg, s)
+	}
+}
+
+func TestExportBundle(t *testing.T) {
+	msg := "TestExportBundle"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	dir := filepath.Join(outDir, "bundle")
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ExportBundleFile(inFile, dir, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	bb, err := os.ReadFile(filepath.Join(dir, pdfcpu.BundleFile))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var b pdfcpu.Bundle
+	if err := json.Unmarshal(bb, &b); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if b.PageCount != 25 || len(b.Pages) != 25 {
+		t.Fatalf("%s: want 25 pages, got: %d %d\n", msg, b.PageCount, len(b.Pages))
+	}
+	if b.Metadata.Author != "Alan Kay" || b.Metadata.XMP != pdfcpu.BundleXMPFile {
+		t.Fatalf("%s: unexpected metadata: %v\n", msg, b.Metadata)
+	}
+
+	p := b.Pages[0]
+	if len(p.Blocks) == 0 || p.Blocks[0].Text != "The Center of “Why?”" {
+		t.Fatalf("%s: unexpected text on page 1: %v\n", msg, p.Blocks)
+	}
+	if len(p.Images) != 1 || p.Images[0].Width != 1100 || p.Images[0].Height != 427 {
+		t.Fatalf("%s: unexpected images on page 1: %v\n", msg, p.Images)
+	}
+	if len(b.Fonts) == 0 {
+		t.Fatalf("%s: missing fonts\n", msg)
+	}
+
+	// All assets referred to must be part of the bundle.
+	files := []string{b.Metadata.XMP}
+	for _, p := range b.Pages {
+		for _, img := range p.Images {
+			files = append(files, img.File)
+		}
+	}
+	for _, f := range b.Fonts {
+		if f.File != "" {
+			files = append(files, f.File)
+		}
+	}
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
+
+func TestImageIssues(t *testing.T) {
+	msg := "TestImageIssues"
+
+	for _, fn := range []string{"testImage.pdf", "go.pdf", "Acroforms2.pdf", "T6.pdf"} {
+		fn = filepath.Join(inDir, fn)
+		ss, err := api.ImageIssuesFile(fn, nil, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if len(ss) > 0 {
+			t.Fatalf("%s %s: want no issues, got: %v\n", msg, fn, ss)
+		}
+	}
+
+	// Truncate the Flate encoded image and widen the DCT encoded image of go.pdf.
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goCorruptImages.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	for _, entry := range ctx.Table {
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || sd.Subtype() == nil || *sd.Subtype() != "Image" {
+			continue
+		}
+		switch {
+		case sd.HasSoleFilterNamed("FlateDecode"):
+			if err := sd.Decode(); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			sd.Content = sd.Content[:len(sd.Content)/2]
+			if err := sd.Encode(); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+		case sd.HasSoleFilterNamed("DCTDecode"):
+			sd.Dict["Width"] = types.Integer(*sd.IntEntry("Width") + 1)
+		}
+		entry.Object = sd
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	ss, err := api.ImageIssuesFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+	if len(ss) != 2 || !strings.Contains(ss[0], "truncated") || !strings.Contains(ss[1], "inconsistent with /Width /Height") {
+		t.Fatalf("%s: want truncated and inconsistent image, got: %v\n", msg, ss)
+	}
+}
+
+func TestPageResolutions(t *testing.T) {
+	msg := "TestPageResolutions"
+	outFile := filepath.Join(outDir, "scan.pdf")
+
+	// Render the image using 1 pixel per point resulting in 72 dpi.
+	imgFiles := []string{filepath.Join(resDir, "mountain.jpg")}
+	if err := api.ImportImagesFile(imgFiles, outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	prs, err := api.PageResolutionsFile(outFile, nil, 300, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(prs) != 1 {
+		t.Fatalf("%s: want 1 page resolution, got: %d\n", msg, len(prs))
+	}
+
+	pr := prs[0]
+	if pr.Width != 1667 || pr.Height != 2646 {
+		t.Fatalf("%s: want 1667x2646 pixels, got: %dx%d\n", msg, pr.Width, pr.Height)
+	}
+	if math.Abs(pr.DPIX-72) > 0.5 || math.Abs(pr.DPIY-72) > 0.5 {
+		t.Fatalf("%s: want 72 dpi, got: %.2f x %.2f\n", msg, pr.DPIX, pr.DPIY)
+	}
+	if !pr.BelowMin {
+		t.Fatalf("%s: want resolution below 300 dpi\n", msg)
+	}
+
+	if prs, err = api.PageResolutionsFile(outFile, nil, 72, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if prs[0].BelowMin {
+		t.Fatalf("%s: want resolution not below 72 dpi\n", msg)
+	}
+}
@@ -18,8 +18,10 @@ package test
 
 import (
 	"fmt"
+	"os"
 
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
@@ -27,6 +29,7 @@ import (
 	pdf "github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/color"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/draw"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
@@ -155,3 +158,370 @@ func TestUserFontDemoPDF(t *testing.T) {
 		}
 	}
 }
+
+func TestEmbedFonts(t *testing.T) {
+	msg := "TestEmbedFonts"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goEmbeddedFonts.pdf")
+	fontFile := filepath.Join(inDir, "fonts", "Roboto-Regular.ttf")
+
+	fontFiles := map[string]string{"Arial": fontFile, "Arial,Bold": fontFile}
+
+	ss, err := api.EmbedFontsFile(inFile, outFile, fontFiles, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) == 0 {
+		t.Fatalf("%s: no fonts embedded\n", msg)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+
+	found := 0
+	for _, entry := range ctx.Table {
+		d, ok := entry.Object.(types.Dict)
+		if !ok || d.Type() == nil || *d.Type() != "Font" {
+			continue
+		}
+		bf := d.NameEntry("BaseFont")
+		if bf == nil || !strings.HasSuffix(*bf, "+Roboto-Regular") {
+			continue
+		}
+		found++
+		fd, err := ctx.DereferenceDict(d["FontDescriptor"])
+		if err != nil || fd == nil {
+			t.Fatalf("%s %s: missing font descriptor\n", msg, *bf)
+		}
+		if _, found := fd.Find("FontFile2"); !found {
+			t.Fatalf("%s %s: font not embedded\n", msg, *bf)
+		}
+	}
+	if found == 0 {
+		t.Fatalf("%s: embedded fonts not found\n", msg)
+	}
+}
+
+func TestEmbedFontsCFF(t *testing.T) {
+	msg := "TestEmbedFontsCFF"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goEmbeddedCFF.pdf")
+
+	// An OpenType font file using CFF outlines.
+	fontFile := filepath.Join(outDir, "cff.otf")
+	if err := os.WriteFile(fontFile, append([]byte("OTTO"), make([]byte, 8)...), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	_, err := api.EmbedFontsFile(inFile, outFile, map[string]string{"Arial": fontFile}, nil)
+	if err == nil || !strings.Contains(err.Error(), "OpenType CFF") {
+		t.Fatalf("%s: want OpenType CFF error, got: %v\n", msg, err)
+	}
+}
+
+func TestDocumentFontsGlyphsUsed(t *testing.T) {
+	msg := "TestDocumentFontsGlyphsUsed"
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	helvetica, err := pdffont.EnsureFontDict(ctx.XRefTable, "Helvetica", "", "", false, false, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	courier, err := pdffont.EnsureFontDict(ctx.XRefTable, "Courier", "", "", false, false, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	newStream := func(s string, d types.Dict) types.IndirectRef {
+		sd, err := ctx.NewStreamDictForBuf([]byte(s))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for k, v := range d {
+			sd.Dict[k] = v
+		}
+		if err := sd.Encode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		return *ir
+	}
+
+	// A form without font resources showing text using the font in effect when painted.
+	form := newStream("BT (XY) Tj ET", types.Dict{
+		"Type":      types.Name("XObject"),
+		"Subtype":   types.Name("Form"),
+		"BBox":      types.NewRectangle(0, 0, 100, 100).Array(),
+		"Resources": types.Dict{},
+	})
+
+	// Courier gets set by gs within q/Q, Helvetica is in effect again afterwards.
+	contents := newStream("BT /F0 12 Tf (ab) Tj ET q /GS0 gs BT (cde) Tj ET Q BT (f) Tj ET /X0 Do", nil)
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = contents
+	d["Resources"] = types.Dict{
+		"Font":      types.Dict{"F0": *helvetica},
+		"ExtGState": types.Dict{"GS0": types.Dict{"Type": types.Name("ExtGState"), "Font": types.Array{*courier, types.Float(12)}}},
+		"XObject":   types.Dict{"X0": form},
+	}
+
+	ff, err := pdf.DocumentFonts(ctx)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := map[int]int{helvetica.ObjectNumber.Value(): 5, courier.ObjectNumber.Value(): 3}
+	found := 0
+	for _, fi := range ff {
+		n, ok := want[fi.ObjNr]
+		if !ok {
+			continue
+		}
+		found++
+		if fi.GlyphsUsed != n {
+			t.Fatalf("%s: %s: want %d glyphs used, got: %d\n", msg, fi.Name, n, fi.GlyphsUsed)
+		}
+	}
+	if found != len(want) {
+		t.Fatalf("%s: want %d fonts, got: %d\n", msg, len(want), found)
+	}
+}
+
+func TestListMissingGlyphs(t *testing.T) {
+	msg := "TestListMissingGlyphs"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goEmbeddedRoboto.pdf")
+	fontFile := filepath.Join(inDir, "fonts", "Roboto-Regular.ttf")
+
+	if _, err := api.EmbedFontsFile(inFile, outFile, map[string]string{"Arial": fontFile}, nil); err != nil {
+		t.Fatalf("%s embed: %v\n", msg, err)
+	}
+
+	// Subsetting keeps all glyphs used.
+	ss, err := api.ListMissingGlyphsFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: unexpected missing glyphs: %v\n", msg, ss)
+	}
+
+	// Remap a char code to a glyph not contained in the font program.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, entry := range ctx.Table {
+		d, ok := entry.Object.(types.Dict)
+		if !ok || d.Type() == nil || *d.Type() != "Font" {
+			continue
+		}
+		if bf := d.NameEntry("BaseFont"); bf != nil && strings.HasSuffix(*bf, "+Roboto-Regular") {
+			d.Update("Encoding", types.Dict(map[string]types.Object{
+				"BaseEncoding": types.Name("WinAnsiEncoding"),
+				"Differences":  types.Array{types.Integer('o'), types.Name("uniF8FF")},
+			}))
+		}
+	}
+
+	pages, err := api.PagesForPageSelection(ctx.PageCount, nil, true)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err = pdf.ListMissingGlyphs(ctx, pages)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) < 2 || !strings.Contains(ss[1], "code 0x6F") {
+		t.Fatalf("%s: missing glyph not detected: %v\n", msg, ss)
+	}
+}
+
+func TestListDocumentFonts(t *testing.T) {
+	msg := "TestListDocumentFonts"
+	inFile := filepath.Join(inDir, "go.pdf")
+
+	ss, err := api.ListDocumentFontsFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var full, subset int
+	for _, s := range ss {
+		if strings.Contains(s, ": full, embedded") {
+			full++
+		}
+		if strings.Contains(s, "+Courier New (TrueType): subset, embedded") {
+			subset++
+		}
+	}
+	if full != 2 || subset != 1 {
+		t.Fatalf("%s: want 2 fully embedded and 1 subsetted TrueType font, got: %v\n", msg, ss)
+	}
+	if !strings.HasPrefix(ss[len(ss)-1], "fully embedded font programs:") {
+		t.Fatalf("%s: missing total, got: %v\n", msg, ss)
+	}
+}
+
+func TestConvertType3Fonts(t *testing.T) {
+	msg := "TestConvertType3Fonts"
+	inFile := filepath.Join(inDir, "read.go.pdf")
+	outFile := filepath.Join(outDir, "readGoOutlines.pdf")
+
+	ss, err := api.ConvertType3FontsFile(inFile, outFile, []string{"1-2"}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s: want 2 pages converted, got: %v\n", msg, ss)
+	}
+
+	// Type3 fonts are no longer used for showing text on converted pages.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i := 1; i <= 2; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if !strings.Contains(string(bb), "/T3_5_131 Do") {
+			t.Fatalf("%s: page %d: missing glyph form\n", msg, i)
+		}
+	}
+
+	if ss, err = api.ConvertType3FontsFile(outFile, "", []string{"1-2"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want nothing left to convert, got: %v\n", msg, ss)
+	}
+}
+
+func TestRepairToUnicode(t *testing.T) {
+	msg := "TestRepairToUnicode"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goToUnicode.pdf")
+
+	ss, err := api.RepairToUnicodeFile(inFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) == 0 {
+		t.Fatalf("%s: no ToUnicode CMaps generated\n", msg)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+
+	for _, entry := range ctx.Table {
+		d, ok := entry.Object.(types.Dict)
+		if !ok || d.Type() == nil || *d.Type() != "Font" {
+			continue
+		}
+		if bf := d.NameEntry("BaseFont"); bf == nil || *bf != "Arial" || *d.Subtype() != "TrueType" {
+			continue
+		}
+		sd, _, err := ctx.DereferenceStreamDict(d["ToUnicode"])
+		if err != nil || sd == nil {
+			t.Fatalf("%s: missing ToUnicode\n", msg)
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		// WinAnsiEncoding
+		for _, s := range []string{"<41> <0041>", "<92> <2019>", "<E4> <00E4>"} {
+			if !strings.Contains(string(sd.Content), s) {
+				t.Fatalf("%s: ToUnicode missing %s\n", msg, s)
+			}
+		}
+	}
+
+	// Valid ToUnicode CMaps are kept.
+	if ss, err = api.RepairToUnicodeFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: unexpected ToUnicode generation: %v\n", msg, ss)
+	}
+}
+
+func TestMeasureText(t *testing.T) {
+	msg := "TestMeasureText"
+	fontName, text := "Helvetica", "Confidential"
+
+	w, a, d, err := api.MeasureText(fontName, 24, text)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if w <= 0 || a <= 0 || d <= 0 {
+		t.Fatalf("%s: invalid metrics: %.2f %.2f %.2f\n", msg, w, a, d)
+	}
+
+	// Twice the font size renders twice as wide.
+	w2, _, _, err := api.MeasureText(fontName, 48, text)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if w2 < 2*w-.01 || w2 > 2*w+.01 {
+		t.Fatalf("%s: want width %.2f, got %.2f\n", msg, 2*w, w2)
+	}
+
+	// The largest font size fitting a box fits while the next size does not.
+	bw, bh := 150., 100.
+	fs, err := api.MaxFontSize(fontName, text, bw, bh)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if w, a, d, _ = api.MeasureText(fontName, fs, text); w > bw || a+d > bh {
+		t.Fatalf("%s: font size %d does not fit\n", msg, fs)
+	}
+	if w, a, d, _ = api.MeasureText(fontName, fs+1, text); w <= bw && a+d <= bh {
+		t.Fatalf("%s: font size %d fits\n", msg, fs+1)
+	}
+
+	// Non ASCII text gets measured rune by rune.
+	text = "Grüße"
+	if w, _, _, err = api.MeasureText(fontName, 24, text); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	var gw int
+	for _, r := range text {
+		gw += font.CharWidth(fontName, r)
+	}
+	if w1 := font.UserSpaceUnits(float64(gw), 24); w < w1-.01 || w > w1+.01 {
+		t.Fatalf("%s: %s: want width %.2f, got %.2f\n", msg, text, w1, w)
+	}
+
+	if _, _, _, err = api.MeasureText("NoSuchFont", 24, text); err == nil {
+		t.Fatalf("%s: expected error for unknown font\n", msg)
+	}
+}
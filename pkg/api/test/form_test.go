@@ -17,11 +17,20 @@ limitations under the License.
 package test
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/form"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/primitives"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 /**************************************************************
@@ -134,6 +143,296 @@ func TestUnlockFormFields(t *testing.T) {
 	}
 }
 
+func TestRepairFormDefaultResources(t *testing.T) {
+	msg := "TestRepairFormDefaultResources"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+	outFile := filepath.Join(outDir, "english.pdf")
+
+	ss, err := api.FormDefaultResourceIssuesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no issues, got: %v\n", msg, ss)
+	}
+
+	// Break the form by removing its default resources.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	root, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(root["AcroForm"])
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing AcroForm: %v\n", msg, err)
+	}
+	d.Delete("DR")
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ss, err = api.FormDefaultResourceIssuesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) < 2 || ss[0] != "missing /AcroForm /DR" {
+		t.Fatalf("%s: want missing /DR and fonts, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.RepairFormDefaultResourcesFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) < 2 {
+		t.Fatalf("%s: want fonts added, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.FormDefaultResourceIssuesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no issues after repair, got: %v\n", msg, ss)
+	}
+
+	// Nothing left to repair.
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = form.RepairDefaultResources(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no repairs, got: %v\n", msg, ss)
+	}
+}
+
+func formField(t *testing.T, ctx *model.Context, id string) types.Dict {
+	t.Helper()
+	for _, o := range ctx.AcroForm.ArrayEntry("Fields") {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if s := d.StringOrHexLiteralEntry("T"); s != nil && *s == id {
+			return d
+		}
+	}
+	t.Fatalf("missing field %s\n", id)
+	return nil
+}
+
+func TestRegenerateAppearances(t *testing.T) {
+	msg := "TestRegenerateAppearances"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+	outFile := filepath.Join(outDir, "english.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Change values leaving stale appearances behind.
+	tf := formField(t, ctx, "firstName1")
+	tf["V"] = types.StringLiteral("Frederic")
+	cb := formField(t, ctx, "cb11")
+	cb["V"] = types.Name("Off")
+	cb["AS"] = types.Name("Off")
+	_, yes := primitives.CalcCheckBoxASNames(cb)
+	cb["V"] = yes
+
+	ss, err := form.RegenerateAppearances(ctx)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for _, s := range ss {
+		if strings.Contains(s, "skipped") {
+			t.Fatalf("%s: %s\n", msg, s)
+		}
+	}
+
+	if cb["AS"] != yes {
+		t.Fatalf("%s: want AS %s, got: %v\n", msg, yes, cb["AS"])
+	}
+
+	sd, _, err := ctx.DereferenceStreamDict(tf.DictEntry("AP")["N"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing appearance: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.Contains(string(sd.Content), "Frederic") {
+		t.Fatalf("%s: stale appearance: %s\n", msg, sd.Content)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestCalculationOrder(t *testing.T) {
+	msg := "TestCalculationOrder"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+	outFile := filepath.Join(outDir, "calc.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Turn two text fields into computed fields.
+	for _, id := range []string{"firstName1", "lastName1"} {
+		d := formField(t, ctx, id)
+		d["AA"] = types.Dict{
+			"C": types.Dict{
+				"S":  types.Name("JavaScript"),
+				"JS": types.StringLiteral("event.value = " + id + ";"),
+			},
+		}
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.FieldActionsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	// dob1 comes with keystroke and format actions.
+	for _, want := range []string{
+		"firstName1: calculate: JavaScript: event.value = firstName1;",
+		"lastName1: calculate: JavaScript: event.value = lastName1;",
+		`dob1: format: JavaScript: AFDate_FormatEx("dd.mm.yyyy");`,
+	} {
+		if !strings.Contains(strings.Join(ss, "\n"), want) {
+			t.Fatalf("%s: missing field action %s: %v\n", msg, want, ss)
+		}
+	}
+
+	// Fields without calculate action are not allowed.
+	if err := api.SetCalculationOrderFile(outFile, "", []string{"lastName1", "dob1"}, nil); err == nil {
+		t.Fatalf("%s: missing error for field without calculate action\n", msg)
+	}
+
+	if err := api.SetCalculationOrderFile(outFile, "", []string{"lastName1", "firstName1"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err = api.CalculationOrderFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 || ss[0] != "lastName1" || ss[1] != "firstName1" {
+		t.Fatalf("%s: unexpected calculation order: %v\n", msg, ss)
+	}
+
+	// Remove the calculation order.
+	if err := api.SetCalculationOrderFile(outFile, "", nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ss, err = api.CalculationOrderFile(outFile, nil); err != nil || len(ss) > 0 {
+		t.Fatalf("%s: unexpected calculation order: %v %v\n", msg, ss, err)
+	}
+}
+
+func TestFlattenForm(t *testing.T) {
+	msg := "TestFlattenForm"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+	outFile := filepath.Join(outDir, "englishFlattened.pdf")
+	outFileJSON := filepath.Join(outDir, "englishFlattened.json")
+
+	if _, err := api.FlattenFormFile(inFile, outFile, outFileJSON, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// The field data is kept in the sidecar.
+	bb, err := os.ReadFile(outFileJSON)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !strings.Contains(string(bb), `"firstName1"`) {
+		t.Fatalf("%s: missing field data: %s\n", msg, outFileJSON)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.AcroForm != nil {
+		t.Fatalf("%s: form not removed\n", msg)
+	}
+	if _, found := ctx.PageAnnots[1][model.AnnWidget]; found {
+		t.Fatalf("%s: widgets not removed\n", msg)
+	}
+}
+
+func TestFieldPositions(t *testing.T) {
+	msg := "TestFieldPositions"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+	outFile := filepath.Join(outDir, "englishRotated.pdf")
+
+	positions := func(inFile string, dpi int) []form.FieldPosition {
+		f, err := os.Open(inFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		defer f.Close()
+		fps, err := api.FieldPositions(f, dpi, nil)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(fps) == 0 {
+			t.Fatalf("%s: missing field positions\n", msg)
+		}
+		return fps
+	}
+
+	eq := func(f1, f2 float64) bool {
+		return math.Abs(f1-f2) < 0.001
+	}
+
+	fps := positions(inFile, 0)
+	for _, fp := range fps {
+		if fp.Page != 1 || fp.X < 0 || fp.Y < 0 || fp.X+fp.Width > 1 || fp.Y+fp.Height > 1 {
+			t.Fatalf("%s: %s not normalized: %v\n", msg, fp.ID, fp)
+		}
+	}
+
+	// At 72 dpi pixels correspond to user space units.
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	w, h := dims[0].Width, dims[0].Height
+	for i, fp := range positions(inFile, 72) {
+		fp0 := fps[i]
+		if fp.ID != fp0.ID || !eq(fp.X, fp0.X*w) || !eq(fp.Y, fp0.Y*h) || !eq(fp.Width, fp0.Width*w) || !eq(fp.Height, fp0.Height*h) {
+			t.Fatalf("%s: %s: %v inconsistent with %v\n", msg, fp.ID, fp, fp0)
+		}
+	}
+
+	// Rotating the page by 90 degrees clockwise turns the left edge into the top edge.
+	if err := api.RotateFile(inFile, outFile, 90, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i, fp := range positions(outFile, 0) {
+		fp0 := fps[i]
+		if fp.ID != fp0.ID || !eq(fp.X, 1-fp0.Y-fp0.Height) || !eq(fp.Y, fp0.X) || !eq(fp.Width, fp0.Height) || !eq(fp.Height, fp0.Width) {
+			t.Fatalf("%s: %s: %v inconsistent with %v\n", msg, fp.ID, fp, fp0)
+		}
+	}
+}
+
 func TestExportForm(t *testing.T) {
 
 	inDir := filepath.Join(samplesDir, "form", "demoSinglePage")
@@ -274,3 +573,24 @@ func TestMultiFillFormCSVMerged(t *testing.T) {
 		}
 	}
 }
+
+func TestMaxFormFields(t *testing.T) {
+	msg := "TestMaxFormFields"
+	inFile := filepath.Join(samplesDir, "form", "demoSinglePage", "english.pdf")
+
+	conf := model.NewDefaultConfiguration()
+	conf.MaxFormFields = 1000
+	if err := api.ValidateFile(inFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf.MaxFormFields = 1
+	if err := api.ValidateFile(inFile, conf); !errors.Is(err, pdfcpu.ErrMaxFormFieldsExceeded) {
+		t.Fatalf("%s: want ErrMaxFormFieldsExceeded, got: %v\n", msg, err)
+	}
+
+	// The limit applies to all commands, not just validation.
+	if _, err := api.ListFormFieldsFile([]string{inFile}, conf); !errors.Is(err, pdfcpu.ErrMaxFormFieldsExceeded) {
+		t.Fatalf("%s: want ErrMaxFormFieldsExceeded, got: %v\n", msg, err)
+	}
+}
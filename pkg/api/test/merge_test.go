@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 )
 
 func TestMergeCreateNew(t *testing.T) {
@@ -78,3 +79,101 @@ func TestMergeToBufNew(t *testing.T) {
 		t.Fatalf("%s: write: %v\n", msg, err)
 	}
 }
+
+func TestMergeSelected(t *testing.T) {
+	msg := "TestMergeSelected"
+	sels := []model.MergeSelection{
+		{InFile: filepath.Join(inDir, "go.pdf"), PageSelection: []string{"2-5"}},
+		{InFile: filepath.Join(inDir, "Acroforms2.pdf")},
+		{InFile: filepath.Join(inDir, "go.pdf"), PageSelection: []string{"10-12", "1"}},
+	}
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	// Merge the selected pages of each inFile in the order specified and write the result to outFile.
+	if err := api.MergeSelectedFile(sels, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCountFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// 4 + 3 + 4 pages
+	if n != 11 {
+		t.Fatalf("%s: page count: want 11, got %d\n", msg, n)
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	msg := "TestAssemble"
+
+	// Relative file names get resolved against the directory of the manifest.
+	dir, err := filepath.Abs(inDir)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	manifest := `{
+	"operations": [
+		{"op": "insert", "file": "` + filepath.ToSlash(filepath.Join(dir, "Acroforms2.pdf")) + `", "pages": "1"},
+		{"op": "insert", "file": "` + filepath.ToSlash(filepath.Join(dir, "go.pdf")) + `", "pages": "2-5"},
+		{"op": "blank", "count": 2, "paperSize": "LetterL"},
+		{"op": "rotate", "pages": "6", "rotation": 90},
+		{"op": "watermark", "pages": "2-", "text": "Draft", "desc": "scale:.5, op:.3"}
+	]
+}`
+	manifestFile := filepath.Join(outDir, "assemble.json")
+	if err := os.WriteFile(manifestFile, []byte(manifest), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	// Produce outFile as described by manifestFile.
+	if err := api.AssembleFile(manifestFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n, err := api.PageCountFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// 1 + 4 + 2 pages
+	if n != 7 {
+		t.Fatalf("%s: page count: want 7, got %d\n", msg, n)
+	}
+
+	// Operations apply in manifest order: rotate page 1 before appending page 2.
+	m := &model.AssembleManifest{Operations: []model.AssembleOp{
+		{Op: "insert", File: filepath.Join(inDir, "go.pdf"), Pages: "1"},
+		{Op: "rotate", Pages: "1-", Rotation: 90},
+		{Op: "insert", File: filepath.Join(inDir, "go.pdf"), Pages: "2"},
+	}}
+	var buf bytes.Buffer
+	if err := api.Assemble(m, &buf, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ctx, err := api.ReadContext(bytes.NewReader(buf.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i, want := range []int{90, 0} {
+		_, _, inhPAttrs, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if inhPAttrs.Rotate != want {
+			t.Fatalf("%s: page %d: want rotation %d, got %d\n", msg, i+1, want, inhPAttrs.Rotate)
+		}
+	}
+
+	// Rotations need pages to rotate.
+	m.Operations = m.Operations[1:]
+	if err := api.Assemble(m, &buf, nil); err == nil {
+		t.Fatalf("%s: want error for rotate before insert\n", msg)
+	}
+}
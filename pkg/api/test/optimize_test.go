@@ -17,10 +17,15 @@ limitations under the License.
 package test
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 func TestOptimize(t *testing.T) {
@@ -41,3 +46,367 @@ func TestOptimize(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestOptimizeMergeContentStreams(t *testing.T) {
+	msg := "TestOptimizeMergeContentStreams"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// Split page content between operands and operator and within a string.
+	var a types.Array
+	for _, s := range []string{"q 0 0 m 100 100", "l S", "BT /F0 12 Tf (split", " string) Tj ET Q"} {
+		sd, _ := ctx.NewStreamDictForBuf([]byte(s))
+		if err := sd.Encode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a = append(a, *ir)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = a
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	contents := func() types.Object {
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d, _, _, err := ctx.PageDict(1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		o, err := ctx.Dereference(d["Contents"])
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if sd, ok := o.(types.StreamDict); ok {
+			if err := sd.Decode(); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			return sd
+		}
+		return o
+	}
+
+	// By default multi part content streams are left as is.
+	if err := api.OptimizeFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if a, ok := contents().(types.Array); !ok || len(a) != 4 {
+		t.Fatalf("%s: want content array of 4 streams\n", msg)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.MergeContentStreams = true
+	if err := api.OptimizeFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, ok := contents().(types.StreamDict)
+	if !ok {
+		t.Fatalf("%s: want single content stream\n", msg)
+	}
+	want := "q 0 0 m 100 100\nl S\nBT /F0 12 Tf (split string) Tj ET Q"
+	if string(sd.Content) != want {
+		t.Fatalf("%s: want:\n%s\ngot:\n%s\n", msg, want, sd.Content)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func mergedPageContent(t *testing.T, msg string, parts []string) string {
+	t.Helper()
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "mergedContent.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	var a types.Array
+	for _, s := range parts {
+		sd, _ := ctx.NewStreamDictForBuf([]byte(s))
+		if err := sd.Encode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a = append(a, *ir)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = a
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.MergeContentStreams = true
+	if err := api.OptimizeFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, _, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(d["Contents"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: want single content stream: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	return string(sd.Content)
+}
+
+func TestOptimizeMergeContentStreamsWithinToken(t *testing.T) {
+	for _, tt := range []struct {
+		msg   string
+		parts []string
+		want  string
+	}{
+		{"TestMergeWithinString",
+			[]string{"BT /F0 12 Tf (split (nested", ") string) Tj", "ET"},
+			"BT /F0 12 Tf (split (nested) string) Tj\nET"},
+		{"TestMergeWithinEscape",
+			[]string{"BT /F0 12 Tf (escaped \\", ") paren) Tj ET"},
+			"BT /F0 12 Tf (escaped \\) paren) Tj ET"},
+		{"TestMergeWithinHexString",
+			[]string{"BT /F0 12 Tf <4142", "43> Tj ET"},
+			"BT /F0 12 Tf <414243> Tj ET"},
+		{"TestMergeWithinInlineImage",
+			[]string{"q 8 0 0 8 0 0 cm BI /W 2 /H 1 /BPC 8 /CS /G ID a", "b E", "I Q"},
+			"q 8 0 0 8 0 0 cm BI /W 2 /H 1 /BPC 8 /CS /G ID ab EI Q"},
+		{"TestMergeAfterInlineImage",
+			[]string{"q 8 0 0 8 0 0 cm BI /W 2 /H 1 /BPC 8 /CS /G ID ab EI", "Q"},
+			"q 8 0 0 8 0 0 cm BI /W 2 /H 1 /BPC 8 /CS /G ID ab EI\nQ"},
+		{"TestMergeDictDelimiter",
+			[]string{"/OC <", "</MCID 0>> BDC EMC"},
+			"/OC <</MCID 0>> BDC EMC"},
+	} {
+		if got := mergedPageContent(t, tt.msg, tt.parts); got != tt.want {
+			t.Fatalf("%s: want:\n%s\ngot:\n%s\n", tt.msg, tt.want, got)
+		}
+	}
+}
+
+func TestGarbageCollect(t *testing.T) {
+	msg := "TestGarbageCollect"
+	inFile := filepath.Join(inDir, "bookletTest.pdf")
+	outFile := filepath.Join(outDir, "bookletTest.pdf")
+
+	// bookletTest.pdf contains a single orphaned content stream: obj#133.
+	// The result lists each object freed followed by a summary.
+	ss, err := api.GarbageCollectFile(inFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 || !strings.HasPrefix(ss[0], "obj#133: stream") || !strings.HasPrefix(ss[1], "freed 1 unreferenced objects") {
+		t.Fatalf("%s: want obj#133 freed, got: %v\n", msg, ss)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	n1, err := api.PageCountFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n2, err := api.PageCountFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n1 != n2 {
+		t.Fatalf("%s: want %d pages, got: %d\n", msg, n1, n2)
+	}
+
+	if ss, err = api.GarbageCollectFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want nothing to free, got: %v\n", msg, ss)
+	}
+}
+
+func TestShareResources(t *testing.T) {
+	msg := "TestShareResources"
+	inFile := filepath.Join(inDir, "WaldenFull.pdf")
+	outFile1 := filepath.Join(outDir, "WaldenFullOptimized.pdf")
+	outFile2 := filepath.Join(outDir, "WaldenFull.pdf")
+
+	// WaldenFull.pdf repeats the same extended graphics state on every page.
+	if err := api.OptimizeFile(inFile, outFile1, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.ShareResourcesFile(inFile, outFile2, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) == 0 {
+		t.Fatalf("%s: want shared resources\n", msg)
+	}
+
+	if err := api.ValidateFile(outFile2, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ce1, err := api.EstimateCostFile(outFile1, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ce2, err := api.EstimateCostFile(outFile2, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ce2.ObjectCount >= ce1.ObjectCount || ce2.PageCount != ce1.PageCount {
+		t.Fatalf("%s: want fewer objects than %d, got: %d\n", msg, ce1.ObjectCount, ce2.ObjectCount)
+	}
+
+	if ss, err = api.ShareResourcesFile(outFile2, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want nothing to share, got: %v\n", msg, ss)
+	}
+}
+
+func TestOptimizeJPEGQuality(t *testing.T) {
+	msg := "TestOptimizeJPEGQuality"
+	inFile := filepath.Join(inDir, "go.pdf")
+	outFile := filepath.Join(outDir, "goJPEGQuality.pdf")
+
+	// jpegSize returns the size of the JPEG images of f.
+	jpegSize := func(f string) int {
+		ctx, err := api.ReadContextFile(f)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, f, err)
+		}
+		var n int
+		for _, entry := range ctx.Table {
+			if sd, ok := entry.Object.(types.StreamDict); ok && sd.HasSoleFilterNamed("DCTDecode") {
+				n += len(sd.Raw)
+			}
+		}
+		return n
+	}
+
+	size := jpegSize(inFile)
+
+	// By default JPEG images are left as is.
+	if err := api.OptimizeFile(inFile, outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n := jpegSize(outFile); n != size {
+		t.Fatalf("%s: want %d bytes of JPEG images, got: %d\n", msg, size, n)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.JPEGQuality = 20
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n := jpegSize(outFile)
+	if n == 0 || n >= size {
+		t.Fatalf("%s: want less than %d bytes of JPEG images, got: %d\n", msg, size, n)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ss, err := api.ImageIssuesFile(outFile, nil, nil)
+	if err != nil || len(ss) > 0 {
+		t.Fatalf("%s: want sound images, got: %v %v\n", msg, ss, err)
+	}
+
+	// Images growing by re-encoding are left as is.
+	conf.JPEGQuality = 100
+	if err := api.OptimizeFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n1 := jpegSize(outFile); n1 != n {
+		t.Fatalf("%s: want %d bytes of JPEG images, got: %d\n", msg, n, n1)
+	}
+}
+
+func TestOptimizeCanonicalInfoDict(t *testing.T) {
+	msg := "TestOptimizeCanonicalInfoDict"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		t.Fatalf("%s: missing info dict: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(types.StringLiteral(" Me\t"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Zeta"] = types.StringLiteral("z ")
+	d["Author"] = *ir
+	d["Alpha"] = types.StringLiteral("a")
+	d["Title"] = types.StringLiteral("  My Title  ")
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.CanonicalInfoDict = true
+	if err := api.OptimizeFile(outFile, "", conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	i := bytes.Index(bb, []byte("<</Title(My Title)"))
+	if i < 0 {
+		t.Fatalf("%s: want info dict starting with trimmed title\n", msg)
+	}
+	info := string(bb[i : i+bytes.Index(bb[i:], []byte(">>"))])
+	prev := -1
+	for _, k := range []string{"/Title", "/Author(Me)", "/Producer", "/ModDate", "/Alpha(a)", "/Zeta(z)"} {
+		j := strings.Index(info, k)
+		if j <= prev {
+			t.Fatalf("%s: want %s in canonical position, got: %s\n", msg, k, info)
+		}
+		prev = j
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
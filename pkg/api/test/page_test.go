@@ -17,10 +17,16 @@ limitations under the License.
 package test
 
 import (
+	"errors"
+	"math"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 func TestInsertRemovePages(t *testing.T) {
@@ -63,3 +69,563 @@ func TestInsertRemovePages(t *testing.T) {
 		t.Fatalf("%s %s: pageCount want:%d got:%d\n", msg, inFile, n1, n2)
 	}
 }
+
+func TestRepairPages(t *testing.T) {
+	msg := "TestRepairPages"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// Break some pages, page 1 last since page lookup relies on /Type.
+	for i, f := range []func(d types.Dict){
+		func(d types.Dict) { d["Contents"] = *types.NewIndirectRef(*ctx.Size+10, 0) },
+		func(d types.Dict) { d.Delete("Contents") },
+		func(d types.Dict) { d.Delete("Type"); d.Delete("MediaBox") },
+	} {
+		d, _, _, err := ctx.PageDict(3-i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		f(d)
+	}
+
+	ss, err := pdfcpu.RepairPages(ctx)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Type, MediaBox for page 1, Contents for pages 2 and 3.
+	if len(ss) != 4 {
+		t.Fatalf("%s: want 4 repairs, got: %v\n", msg, ss)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Nothing left to repair.
+	if ss, err = api.RepairPagesFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no repairs, got: %v\n", msg, ss)
+	}
+}
+
+func TestRepairPagesDuplicates(t *testing.T) {
+	msg := "TestRepairPagesDuplicates"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	pageCount := ctx.PageCount
+
+	// Reference page 1 twice and add a cycle back to the page tree root.
+	root, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	_, pageIndRef, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Kids"] = append(d.ArrayEntry("Kids"), *pageIndRef, *root)
+
+	ss, err := pdfcpu.RepairPages(ctx)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var kept, removed bool
+	for _, s := range ss {
+		kept = kept || strings.Contains(s, "kept duplicate kid")
+		removed = removed || strings.Contains(s, "removed cyclic kid")
+	}
+	if !kept || !removed {
+		t.Fatalf("%s: unexpected repairs: %v\n", msg, ss)
+	}
+
+	// The duplicate page is kept.
+	if ctx.PageCount != pageCount+1 {
+		t.Fatalf("%s: want %d pages, got: %d\n", msg, pageCount+1, ctx.PageCount)
+	}
+	if c := d.IntEntry("Count"); c == nil || *c != pageCount+1 {
+		t.Fatalf("%s: want /Count %d, got: %v\n", msg, pageCount+1, d["Count"])
+	}
+}
+
+func TestPageHashes(t *testing.T) {
+	msg := "TestPageHashes"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	m1, err := api.PageHashesFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// Rewriting with different object numbers and compression preserves all hashes.
+	conf := model.NewDefaultConfiguration()
+	conf.WriteObjectStream = false
+	conf.WriteXRefStream = false
+	if err := api.OptimizeFile(inFile, outFile, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m2, err := api.PageHashesFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+	if len(m1) != 3 || len(m2) != 3 {
+		t.Fatalf("%s: want 3 hashes, got: %d %d\n", msg, len(m1), len(m2))
+	}
+	for pageNr, s := range m1 {
+		if m2[pageNr] != s {
+			t.Fatalf("%s: page %d: hash changed after rewrite\n", msg, pageNr)
+		}
+	}
+
+	// Rotating page 2 changes its hash only.
+	if err := api.RotateFile(outFile, "", 90, []string{"2"}, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	m2, err = api.PageHashesFile(outFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+	for pageNr, s := range m1 {
+		if changed := m2[pageNr] != s; changed != (pageNr == 2) {
+			t.Fatalf("%s: page %d: unexpected hash change: %t\n", msg, pageNr, changed)
+		}
+	}
+}
+
+func TestDegeneratePages(t *testing.T) {
+	msg := "TestDegeneratePages"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ss, err := api.DegeneratePagesFile(inFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no degenerate pages, got: %v\n", msg, ss)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// Zero area media box for page 2, negative height for page 3.
+	for i, a := range []types.Array{
+		types.NewNumberArray(0, 0, 0, 0),
+		types.NewNumberArray(0, 0, 100, -50),
+	} {
+		d, _, _, err := ctx.PageDict(i+2, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d.Update("MediaBox", a)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ss, err = api.DegeneratePagesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s: want 2 degenerate pages, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.RemoveDegeneratePagesFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s: want 2 pages removed, got: %v\n", msg, ss)
+	}
+
+	n, err := api.PageCountFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != 1 {
+		t.Fatalf("%s: want 1 page, got: %d\n", msg, n)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestClampPages(t *testing.T) {
+	msg := "TestClampPages"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d.Update("MediaBox", types.NewNumberArray(0, 0, 20000, 10000))
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.OversizedPagesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("%s: want 1 oversized page, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.ClampPagesFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("%s: want 1 page scaled, got: %v\n", msg, ss)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	_, _, inhPAttrs, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if r := inhPAttrs.MediaBox; r.Width() != 14400 || r.Height() != 7200 {
+		t.Fatalf("%s: want 14400 x 7200, got: %v\n", msg, r)
+	}
+
+	if ss, err = api.OversizedPagesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no oversized pages, got: %v\n", msg, ss)
+	}
+}
+
+func TestSplitContentPages(t *testing.T) {
+	msg := "TestSplitContentPages"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// Divide page content within a string and within an operator.
+	var a types.Array
+	for _, s := range []string{"q 0 0 m 100 100 l S BT /F0 12 Tf (spl", "it) T", "j ET Q"} {
+		sd, _ := ctx.NewStreamDictForBuf([]byte(s))
+		if err := sd.Encode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a = append(a, *ir)
+	}
+
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = a
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.SplitContentPagesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s: want 2 split boundaries, got: %v\n", msg, ss)
+	}
+
+	if ss, err = api.ResplitContentPagesFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 {
+		t.Fatalf("%s: want 1 page fixed, got: %v\n", msg, ss)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, _, _, err = ctx.PageDict(1, false); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if a, err = ctx.DereferenceArray(d["Contents"]); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := []string{"q 0 0 m 100 100 l S BT /F0 12 Tf ", "(split) ", "Tj ET Q"}
+	if len(a) != len(want) {
+		t.Fatalf("%s: want %d content parts, got %d\n", msg, len(want), len(a))
+	}
+	for i, o := range a {
+		sd, _, err := ctx.DereferenceStreamDict(o)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if string(sd.Content) != want[i] {
+			t.Fatalf("%s: part %d want:%q got:%q\n", msg, i+1, want[i], sd.Content)
+		}
+	}
+
+	// Nothing left to fix.
+	if ss, err = api.SplitContentPagesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no split boundaries, got: %v\n", msg, ss)
+	}
+}
+
+func TestMaxPages(t *testing.T) {
+	msg := "TestMaxPages"
+
+	for _, fn := range []string{"Acroforms2.pdf", "CenterOfWhy.pdf", "annotTest.pdf", "5116.DCT_Filter.pdf"} {
+		inFile := filepath.Join(inDir, fn)
+
+		n1, err := api.PageCountFile(inFile)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		n2, err := api.ReadPageCountFile(inFile, nil)
+		if err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+		if n1 != n2 {
+			t.Fatalf("%s %s: pageCount want:%d got:%d\n", msg, fn, n1, n2)
+		}
+
+		conf := model.NewDefaultConfiguration()
+		conf.MaxPages = n1
+		if err := api.ValidateFile(inFile, conf); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		conf.MaxPages = n1 - 1
+		if n1 > 1 {
+			if err := api.ValidateFile(inFile, conf); !errors.Is(err, pdfcpu.ErrMaxPagesExceeded) {
+				t.Fatalf("%s %s: want ErrMaxPagesExceeded, got: %v\n", msg, fn, err)
+			}
+		}
+	}
+
+	// Truncate to the first 2 pages.
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	conf := model.NewDefaultConfiguration()
+	conf.MaxPages = 1
+	if err := api.TruncatePagesFile(inFile, outFile, 2, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	n, err := api.PageCountFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if n != 2 {
+		t.Fatalf("%s: pageCount want:2 got:%d\n", msg, n)
+	}
+}
+
+func TestNonStandardOperators(t *testing.T) {
+	msg := "TestNonStandardOperators"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	ss, err := api.NonStandardOperatorsFile(inFile, nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) > 0 {
+		t.Fatalf("%s: want no non standard operators, got: %v\n", msg, ss)
+	}
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Replace the content of page 2.
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.StreamDictIndRef([]byte("q 1 0 0 1 0 0 cm (x) PS BX 1 foo 2 foo EX 3 foo Q"))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *ir
+
+	ss, err = pdfcpu.NonStandardOperators(ctx, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := []string{
+		"page 2: PS (deprecated): 1",
+		"page 2: foo: 3 (2 within BX/EX)",
+	}
+	if len(ss) != len(want) || ss[0] != want[0] || ss[1] != want[1] {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, ss)
+	}
+}
+
+func TestIsScannedDocument(t *testing.T) {
+	msg := "TestIsScannedDocument"
+
+	ok, _, err := api.IsScannedDocumentFile(filepath.Join(inDir, "Acroforms2.pdf"), nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ok {
+		t.Fatalf("%s: want text document\n", msg)
+	}
+
+	outFile := filepath.Join(outDir, "scanned.pdf")
+	imgFiles := []string{filepath.Join(resDir, "mountain.jpg"), filepath.Join(resDir, "logoSmall.png")}
+	if err := api.ImportImagesFile(imgFiles, outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ok, confidence, err := api.IsScannedDocumentFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !ok || confidence != 1 {
+		t.Fatalf("%s: want scanned document with confidence 1, got: %t %.2f\n", msg, ok, confidence)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Add an invisible OCR text layer to page 2.
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	bb = append(bb, []byte(" BT 3 Tr (pdfcpu) Tj ET")...)
+	ir, err := ctx.StreamDictIndRef(bb)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *ir
+
+	k, err := pdfcpu.PageContentKind(ctx, 2)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if k != pdfcpu.ContentMixed {
+		t.Fatalf("%s: want %s, got: %s\n", msg, pdfcpu.ContentMixed, k)
+	}
+
+	ok, confidence = pdfcpu.IsScannedDocument(ctx)
+	if ok || confidence != 0.5 {
+		t.Fatalf("%s: want no scanned document with confidence 0.5, got: %t %.2f\n", msg, ok, confidence)
+	}
+}
+
+func TestTilePage(t *testing.T) {
+	msg := "TestTilePage"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "tiles.pdf")
+
+	dims, err := api.PageDimsFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	rows, cols, overlap := 2, 3, 10.
+	if err := api.TilePageFile(inFile, outFile, 1, rows, cols, overlap, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != rows*cols {
+		t.Fatalf("%s: want %d pages, got: %d\n", msg, rows*cols, ctx.PageCount)
+	}
+
+	w := (dims[0].Width + float64(cols-1)*overlap) / float64(cols)
+	h := (dims[0].Height + float64(rows-1)*overlap) / float64(rows)
+
+	dims, err = ctx.PageDims()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var content types.Object
+
+	for i, dim := range dims {
+		if math.Abs(dim.Width-w) > 0.01 || math.Abs(dim.Height-h) > 0.01 {
+			t.Fatalf("%s: page %d: want %.2f x %.2f, got: %.2f x %.2f\n", msg, i+1, w, h, dim.Width, dim.Height)
+		}
+		d, _, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		a, err := ctx.DereferenceArray(d["Contents"])
+		if err != nil || len(a) < 3 {
+			t.Fatalf("%s: page %d: invalid content: %v\n", msg, i+1, d["Contents"])
+		}
+		// The original content is shared among all tiles.
+		if content == nil {
+			content = a[1]
+		} else if a[1] != content {
+			t.Fatalf("%s: page %d: want shared content %v, got: %v\n", msg, i+1, content, a[1])
+		}
+	}
+
+	if err := api.TilePageFile(inFile, outFile, 1, 2, 2, 10000, nil); err == nil {
+		t.Fatalf("%s: want error for overlap exceeding the tile size\n", msg)
+	}
+}
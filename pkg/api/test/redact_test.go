@@ -0,0 +1,183 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func pageParagraphs(t *testing.T, msg, inFile string, page string) []pdfcpu.TextBlock {
+	t.Helper()
+	f, err := os.Open(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	defer f.Close()
+
+	pp, err := api.Paragraphs(f, []string{page}, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	return pp[0].Blocks
+}
+
+func TestRedact(t *testing.T) {
+	msg := "TestRedact"
+	inFile := filepath.Join(inDir, "pike-stanford.pdf")
+	outFile := filepath.Join(outDir, "pike-stanford_redacted.pdf")
+	inFileJSON := filepath.Join(outDir, "redact.json")
+
+	bb := pageParagraphs(t, msg, inFile, "3")
+
+	// Redact the page title.
+	m := pdfcpu.RedactionManifest{Redactions: []pdfcpu.Redaction{{Page: 3, Rects: [][4]float64{bb[0].BBox}}}}
+	bs, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.WriteFile(inFileJSON, bs, os.ModePerm); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.RedactFile(inFile, inFileJSON, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(ss) != 1 || !strings.Contains(ss[0], "removed 7 glyphs") {
+		t.Fatalf("%s: want 7 glyphs removed, got: %v\n", msg, ss)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb1 := pageParagraphs(t, msg, outFile, "3")
+	if len(bb1) != len(bb)-1 {
+		t.Fatalf("%s: want %d blocks, got: %v\n", msg, len(bb)-1, bb1)
+	}
+	for i, b := range bb1 {
+		if b.Text != bb[i+1].Text {
+			t.Fatalf("%s: block %d: want %q, got: %q\n", msg, i, bb[i+1].Text, b.Text)
+		}
+	}
+
+	// Pages not listed remain untouched.
+	if bb, bb1 = pageParagraphs(t, msg, inFile, "2"), pageParagraphs(t, msg, outFile, "2"); len(bb) != len(bb1) {
+		t.Fatalf("%s: page 2: want %d blocks, got: %d\n", msg, len(bb), len(bb1))
+	}
+}
+
+func TestRedactUnmeasuredText(t *testing.T) {
+	msg := "TestRedactUnmeasuredText"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "test_redacted.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// /Fx is unresolvable, /Fy has no widths, /Fh is a core font.
+	fy, err := ctx.IndRefForNewObject(types.Dict(map[string]types.Object{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("TrueType"),
+		"BaseFont": types.Name("NoSuchFont"),
+	}))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	fh, err := ctx.IndRefForNewObject(types.Dict(map[string]types.Object{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+	}))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	content := "BT /Fx 12 Tf 100 700 Td (WWW) Tj ET " +
+		"BT /Fx 12 Tf 100 500 Td (WWW) Tj ET " +
+		"BT /Fy 12 Tf 100 650 Td (AB) Tj ET " +
+		"BT /Fh 12 Tf 100 680 Td (ii) Tj ET"
+	ir, err := ctx.StreamDictIndRef([]byte(content))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *ir
+	d["Resources"] = types.Dict(map[string]types.Object{"Font": types.Dict(map[string]types.Object{
+		"Fx": *types.NewIndirectRef(*ctx.Size+10, 0),
+		"Fy": *fy,
+		"Fh": *fh,
+	})})
+
+	// Estimated glyph widths would place the unmeasured text left of the area.
+	m := pdfcpu.RedactionManifest{Redactions: []pdfcpu.Redaction{{Page: 1, Rects: [][4]float64{{130, 640, 200, 720}}}}}
+	ss, err := pdfcpu.Redact(ctx, m)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 || !strings.Contains(ss[0], "removed 5 glyphs") {
+		t.Fatalf("%s: want 5 glyphs removed, got: %v\n", msg, ss)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestPixelate(t *testing.T) {
+	msg := "TestPixelate"
+	inFile := filepath.Join(inDir, "testImage.pdf")
+	outFile := filepath.Join(outDir, "testImage_pixelated.pdf")
+
+	rects := []types.Rectangle{*types.NewRectangle(0, 0, 200, 200), *types.NewRectangle(300, 300, 400, 400)}
+
+	ss, err := api.PixelateFile(inFile, outFile, 2, "Im2", rects, 0, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(ss) != 2 || !strings.Contains(ss[0], "pixelated image Im2") {
+		t.Fatalf("%s: want 2 regions pixelated, got: %v\n", msg, ss)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// JPX encoded images are not supported.
+	if _, err := api.PixelateFile(inFile, outFile, 1, "Im1", rects, 0, nil); err == nil {
+		t.Fatalf("%s: want error for unsupported image encoding\n", msg)
+	}
+
+	if _, err := api.PixelateFile(inFile, outFile, 2, "Im9", rects, 0, nil); err == nil {
+		t.Fatalf("%s: want error for unknown image\n", msg)
+	}
+}
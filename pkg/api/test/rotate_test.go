@@ -17,10 +17,12 @@ limitations under the License.
 package test
 
 import (
+	"fmt"
 	"path/filepath"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 func TestRotate(t *testing.T) {
@@ -41,3 +43,119 @@ func TestRotate(t *testing.T) {
 		t.Fatalf("%s: %v\n", msg, err)
 	}
 }
+
+func TestNormalizeRotation(t *testing.T) {
+	msg := "TestNormalizeRotation"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+	outFile := filepath.Join(outDir, "NormalizedRotation.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	_, _, inhPAttrs, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	w, h := inhPAttrs.MediaBox.Width(), inhPAttrs.MediaBox.Height()
+
+	if err := api.RotateFile(inFile, outFile, 90, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.NormalizeRotationFile(outFile, "", nil, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) == 0 {
+		t.Fatalf("%s: no pages normalized\n", msg)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	for i := 1; i <= ctx.PageCount; i++ {
+		_, _, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if inhPAttrs.Rotate != 0 {
+			t.Fatalf("%s: page %d: want rotation 0, got %d\n", msg, i, inhPAttrs.Rotate)
+		}
+		if i == 1 && (inhPAttrs.MediaBox.Width() != h || inhPAttrs.MediaBox.Height() != w) {
+			t.Fatalf("%s: page 1: want %.2f x %.2f, got %s\n", msg, h, w, inhPAttrs.MediaBox)
+		}
+	}
+}
+
+func TestNormalizeRotateInheritance(t *testing.T) {
+	msg := "TestNormalizeRotateInheritance"
+	inFile := filepath.Join(inDir, "pike-stanford.pdf")
+	outFile := filepath.Join(outDir, "RotateInheritance.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Page 1 inherits from the page tree root, page 2 uses a non normalized value.
+	root, err := ctx.Pages()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict["Rotate"] = types.Integer(90)
+
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Rotate"] = types.Integer(-90)
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.PageRotationsFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != ctx.PageCount+1 {
+		t.Fatalf("%s: want %d lines, got: %v\n", msg, ctx.PageCount+1, ss)
+	}
+	want := fmt.Sprintf("page 1: 90 inherited from obj#%d", root.ObjectNumber.Value())
+	if ss[0] != want || ss[1] != "page 2: 270 (/Rotate -90)" {
+		t.Fatalf("%s: want %q and page 2: 270, got: %v\n", msg, want, ss[:2])
+	}
+
+	if _, err := api.NormalizeRotateInheritanceFile(outFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if rootDict, err = ctx.DereferenceDict(*root); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := rootDict.Find("Rotate"); found {
+		t.Fatalf("%s: want /Rotate removed from page tree root\n", msg)
+	}
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		want := 90
+		if i == 2 {
+			want = 270
+		}
+		if r := d.IntEntry("Rotate"); r == nil || *r != want {
+			t.Fatalf("%s: page %d: want /Rotate %d, got: %v\n", msg, i, want, r)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/color"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestAddSignatureField(t *testing.T) {
+	msg := "TestAddSignatureField"
+
+	for _, fn := range []string{"test.pdf", "Acroforms2.pdf"} {
+		inFile := filepath.Join(inDir, fn)
+		outFile := filepath.Join(outDir, "sigField_"+fn)
+
+		sfa := model.NewSignatureFieldAnnotation(
+			*types.NewRectangle(50, 50, 250, 110),
+			"Signature1",
+			"Sign here",
+			"",
+			model.AnnPrint,
+			&color.LightGray,
+			&color.Black)
+
+		if err := api.AddSignatureFieldFile(inFile, outFile, 1, sfa, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s validate: %v\n", msg, fn, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s %s read: %v\n", msg, fn, err)
+		}
+
+		fields, err := ctx.DereferenceArray(ctx.AcroForm["Fields"])
+		if err != nil {
+			t.Fatalf("%s %s fields: %v\n", msg, fn, err)
+		}
+
+		var found bool
+		for _, o := range fields {
+			d, err := ctx.DereferenceDict(o)
+			if err != nil {
+				t.Fatalf("%s %s field: %v\n", msg, fn, err)
+			}
+			if ft := d.NameEntry("FT"); ft != nil && *ft == "Sig" {
+				if _, ok := d.Find("V"); ok {
+					t.Fatalf("%s %s: signature field must not have a value\n", msg, fn)
+				}
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%s %s: missing signature field\n", msg, fn)
+		}
+
+		// Adding a field with the same name again fails.
+		if err := api.AddSignatureFieldFile(outFile, "", 1, sfa, nil); err == nil {
+			t.Fatalf("%s %s: expected duplicate field name error\n", msg, fn)
+		}
+	}
+}
+
+func TestAddSignatureFieldQualifiedName(t *testing.T) {
+	msg := "TestAddSignatureFieldQualifiedName"
+	inFile := filepath.Join(inDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	sfa := model.NewSignatureFieldAnnotation(
+		*types.NewRectangle(50, 50, 250, 110),
+		"Parent1",
+		"Sign here",
+		"",
+		model.AnnPrint,
+		nil,
+		&color.Black)
+
+	if err := pdfcpu.AddSignatureField(ctx, 1, sfa); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	// Turn the new field into the parent of a field with the fully qualified name Parent1.Child.
+	kid, err := ctx.IndRefForNewObject(types.Dict(map[string]types.Object{"T": types.StringLiteral("Child")}))
+	if err != nil {
+		t.Fatalf("%s kid: %v\n", msg, err)
+	}
+	fields, err := ctx.DereferenceArray(ctx.AcroForm["Fields"])
+	if err != nil {
+		t.Fatalf("%s fields: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(fields[len(fields)-1])
+	if err != nil {
+		t.Fatalf("%s field: %v\n", msg, err)
+	}
+	d.Insert("Kids", types.Array{*kid})
+
+	// Adding a field named like a nested field fails without allocating any objects.
+	size := *ctx.Size
+	sfa.FieldName = "Parent1.Child"
+	if err := pdfcpu.AddSignatureField(ctx, 1, sfa); err == nil {
+		t.Fatalf("%s: expected duplicate field name error\n", msg)
+	}
+	if *ctx.Size != size {
+		t.Fatalf("%s: objects allocated: want size %d, got: %d\n", msg, size, *ctx.Size)
+	}
+
+	// Partial names may be reused across different parents.
+	sfa.FieldName = "Child"
+	if err := pdfcpu.AddSignatureField(ctx, 1, sfa); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+}
+
+func TestUnsign(t *testing.T) {
+	msg := "TestUnsign"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "signed.pdf")
+
+	sfa := model.NewSignatureFieldAnnotation(
+		*types.NewRectangle(50, 50, 250, 110),
+		"Signature1",
+		"Sign here",
+		"",
+		model.AnnPrint,
+		nil,
+		&color.Black)
+
+	if err := api.AddSignatureFieldFile(inFile, outFile, 1, sfa, nil); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	// Fake a signature value and some signature related document permissions.
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	fields, err := ctx.DereferenceArray(ctx.AcroForm["Fields"])
+	if err != nil {
+		t.Fatalf("%s fields: %v\n", msg, err)
+	}
+	sigDict := types.Dict(map[string]types.Object{
+		"Type":      types.Name("Sig"),
+		"Filter":    types.Name("Adobe.PPKLite"),
+		"SubFilter": types.Name("adbe.pkcs7.detached"),
+		"Contents":  types.HexLiteral("00000000"),
+		"ByteRange": types.NewIntegerArray(0, 10, 20, 10),
+	})
+	sigIndRef, err := ctx.IndRefForNewObject(sigDict)
+	if err != nil {
+		t.Fatalf("%s sigDict: %v\n", msg, err)
+	}
+	for _, o := range fields {
+		d, _ := ctx.DereferenceDict(o)
+		if ft := d.NameEntry("FT"); ft != nil && *ft == "Sig" {
+			d.Insert("V", *sigIndRef)
+		}
+	}
+	ctx.AcroForm.Insert("SigFlags", types.Integer(3))
+	ctx.RootDict.Insert("Perms", types.Dict(map[string]types.Object{"DocMDP": *sigIndRef}))
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s write: %v\n", msg, err)
+	}
+
+	ss, err := api.UnsignFile(outFile, "", nil)
+	if err != nil {
+		t.Fatalf("%s unsign: %v\n", msg, err)
+	}
+	if len(ss) != 1 || ss[0] != "Signature1 (signed)" {
+		t.Fatalf("%s: unexpected result: %v\n", msg, ss)
+	}
+
+	ctx, err = api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	if _, found := ctx.RootDict.Find("Perms"); found {
+		t.Fatalf("%s: Perms not removed\n", msg)
+	}
+	if ctx.SignatureExist || ctx.AppendOnly {
+		t.Fatalf("%s: SigFlags not removed\n", msg)
+	}
+	if ss, err = api.UnsignFile(outFile, "", nil); err != nil || len(ss) > 0 {
+		t.Fatalf("%s: signature fields not removed: %v %v\n", msg, ss, err)
+	}
+}
+
+func TestDocTimeStamp(t *testing.T) {
+	msg := "TestDocTimeStamp"
+
+	for _, fn := range []string{"test.pdf", "Acroforms2.pdf"} {
+		inFile := filepath.Join(inDir, fn)
+		outFile := filepath.Join(outDir, "docTimeStamp_"+fn)
+		tokenFile := filepath.Join(outDir, "token.der")
+
+		digest, err := api.PrepareDocTimeStampFile(inFile, outFile, 0, nil)
+		if err != nil {
+			t.Fatalf("%s %s: prepare: %v\n", msg, fn, err)
+		}
+		if len(digest) != sha256.Size {
+			t.Fatalf("%s %s: invalid digest: %x\n", msg, fn, digest)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		// Any bytes will do since pdfcpu does not parse the token.
+		if err := os.WriteFile(tokenFile, digest, 0644); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		if err := api.EmbedDocTimeStampFile(outFile, tokenFile, nil); err != nil {
+			t.Fatalf("%s %s: embed: %v\n", msg, fn, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, fn, err)
+		}
+
+		// The placeholder has been consumed.
+		if err := api.EmbedDocTimeStampFile(outFile, tokenFile, nil); err != pdfcpu.ErrNoPendingDocTimeStamp {
+			t.Fatalf("%s %s: want ErrNoPendingDocTimeStamp, got: %v\n", msg, fn, err)
+		}
+	}
+}
+
+func TestAddDSS(t *testing.T) {
+	msg := "TestAddDSS"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "dss.pdf")
+	tokenFile := filepath.Join(outDir, "token.der")
+
+	// Start with a timestamped file.
+	if _, err := api.PrepareDocTimeStampFile(inFile, outFile, 0, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := os.WriteFile(tokenFile, []byte("token"), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.EmbedDocTimeStampFile(outFile, tokenFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	bb, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	vd := pdfcpu.ValidationData{
+		Certs: [][]byte{[]byte("cert1"), []byte("cert2")},
+		OCSPs: [][]byte{[]byte("ocsp")},
+	}
+
+	for i := 0; i < 2; i++ {
+		f, err := os.OpenFile(outFile, os.O_RDWR, 0644)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if err := api.AddDSS(f, vd, true, nil); err != nil {
+			f.Close()
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		f.Close()
+	}
+
+	bb1, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if !bytes.HasPrefix(bb1, bb) {
+		t.Fatalf("%s: DSS not written as increment\n", msg)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	dss, err := ctx.DereferenceDict(rootDict["DSS"])
+	if err != nil || dss == nil {
+		t.Fatalf("%s: missing DSS: %v\n", msg, err)
+	}
+
+	// Validation material added twice is stored once.
+	if certs := dss.ArrayEntry("Certs"); len(certs) != 2 {
+		t.Fatalf("%s: want 2 certs, got: %d\n", msg, len(certs))
+	}
+	if ocsps := dss.ArrayEntry("OCSPs"); len(ocsps) != 1 {
+		t.Fatalf("%s: want 1 OCSP response, got: %d\n", msg, len(ocsps))
+	}
+	if vri := dss.DictEntry("VRI"); len(vri) != 1 {
+		t.Fatalf("%s: want 1 VRI entry, got: %d\n", msg, len(vri))
+	}
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 )
 
 func TestSplitSpan1(t *testing.T) {
@@ -48,6 +50,27 @@ func TestSplitSpan2(t *testing.T) {
 	}
 }
 
+func TestSplitOutputNameTemplate(t *testing.T) {
+	msg := "TestSplitOutputNameTemplate"
+	fileName := "Acroforms2.pdf"
+	inFile := filepath.Join(inDir, fileName)
+
+	conf := model.NewDefaultConfiguration()
+	conf.OutputNameTemplate = "{base}-part{index}-p{from}-{to}.{ext}"
+
+	// Create dual page files of inFile in outDir named according to conf.OutputNameTemplate.
+	span := 2
+	if err := api.SplitFile(inFile, outDir, span, conf); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, fn := range []string{"Acroforms2-part1-p1-2.pdf", "Acroforms2-part2-p3-3.pdf"} {
+		if _, err := os.Stat(filepath.Join(outDir, fn)); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+	}
+}
+
 func TestSplit0ByBookmark(t *testing.T) {
 	msg := "TestSplit0ByBookmark"
 	fileName := "5116.DCT_Filter.pdf"
@@ -19,6 +19,7 @@ package test
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ex-preman/pdfcpu/pkg/api"
@@ -575,3 +576,160 @@ func TestRecycleWM(t *testing.T) {
 		t.Fatalf("%s %s: %v\n", msg, outFile, err)
 	}
 }
+
+func TestRecolorWatermarks(t *testing.T) {
+	msg := "TestRecolorWatermarks"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "recolorWM.pdf")
+
+	desc := "fillcolor:#FF0000, strokecolor:#0000FF, rendermode:2, opacity:1"
+	if err := api.AddTextWatermarksFile(inFile, outFile, nil, false, "Draft", desc, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	if err := api.RecolorWatermarksFile(outFile, "", nil, 0.8, 0.3, nil); err != nil {
+		t.Fatalf("%s %s: %v\n", msg, outFile, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var forms, extGStates int
+
+	for _, entry := range ctx.Table {
+		switch o := entry.Object.(type) {
+
+		case types.Dict:
+			if t := o.Type(); t == nil || *t != "ExtGState" {
+				continue
+			}
+			if ca := o["ca"]; ca != types.Float(0.3) {
+				t.Fatalf("%s: want ca 0.3, got %v\n", msg, ca)
+			}
+			extGStates++
+
+		case types.StreamDict:
+			if _, ok := o.Find("OC"); !ok {
+				continue
+			}
+			if err := o.Decode(); err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			s := string(o.Content)
+			if strings.Contains(s, " rg") || strings.Contains(s, " RG") || !strings.Contains(s, "0.8 g") {
+				t.Fatalf("%s: watermark not recolored: %s\n", msg, s)
+			}
+			forms++
+		}
+	}
+
+	if forms == 0 || extGStates == 0 {
+		t.Fatalf("%s: watermark resources missing\n", msg)
+	}
+}
+
+func TestAddTextBoxWatermarks(t *testing.T) {
+	msg := "TestAddTextBoxWatermarks"
+	inFile := filepath.Join(inDir, "test.pdf")
+	outFile := filepath.Join(outDir, "textBoxWM.pdf")
+
+	text := "This is a long stamp text which does not fit on a single line and needs to be word-wrapped. " +
+		"It also has to shrink in order to fit into the given height."
+
+	for _, align := range []string{"l", "c", "r", "j"} {
+		desc := "textbox:200 100, shrinktofit:on, linespacing:1.2, points:24, rot:0, pos:tl, bgcol:#E0E0E0, aligntext:" + align
+		if err := api.AddTextWatermarksFile(inFile, outFile, nil, true, text, desc, nil); err != nil {
+			t.Fatalf("%s %s: %v\n", msg, outFile, err)
+		}
+
+		if err := api.ValidateFile(outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		ctx, err := api.ReadContextFile(outFile)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+
+		found := false
+		for _, entry := range ctx.Table {
+			sd, ok := entry.Object.(types.StreamDict)
+			if !ok {
+				continue
+			}
+			if _, ok := sd.Find("OC"); !ok {
+				continue
+			}
+			bb, err := types.RectForArray(sd.ArrayEntry("BBox"))
+			if err != nil {
+				t.Fatalf("%s: %v\n", msg, err)
+			}
+			if bb.Width() < 199 || bb.Width() > 201 || bb.Height() < 99 || bb.Height() > 101 {
+				t.Fatalf("%s %s: want text box 200 x 100, got %.2f x %.2f\n", msg, align, bb.Width(), bb.Height())
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("%s: missing form\n", msg)
+		}
+	}
+}
+
+func TestAddPageNumbers(t *testing.T) {
+	msg := "TestAddPageNumbers"
+	inFile := filepath.Join(inDir, "CenterOfWhy.pdf")
+	outFile := filepath.Join(outDir, "pageNumbers.pdf")
+
+	// Leave the first 2 pages unnumbered, count but skip page 5 and 9.
+	pn, err := pdfcpu.ParsePageNumberingDetails("start:3, skip:5 9, text:Page %p of %P, pos:br, off:-20 20")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.AddPageNumbersFile(inFile, outFile, pn, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Collect the text of all stamps.
+	nrs := map[string]bool{}
+	for _, entry := range ctx.Table {
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok {
+			continue
+		}
+		if _, ok := sd.Find("OC"); !ok {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for i := 1; i <= 23; i++ {
+			if strings.Contains(string(sd.Content), fmt.Sprintf("(Page %d of 23)", i)) {
+				nrs[fmt.Sprintf("%d", i)] = true
+			}
+		}
+	}
+
+	if len(nrs) != 21 || !nrs["1"] || nrs["3"] || nrs["7"] || !nrs["23"] {
+		t.Fatalf("%s: unexpected page numbers: %v\n", msg, nrs)
+	}
+
+	if _, err := pdfcpu.ParsePageNumberingDetails("start:0"); err == nil {
+		t.Fatalf("%s: want error for invalid start page\n", msg)
+	}
+}
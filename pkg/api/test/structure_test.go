@@ -0,0 +1,351 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// writeTaggedTestFile writes a single page tagged PDF of two paragraphs
+// with the structure tree listing the marked content sequences by mcids.
+func writeTaggedTestFile(t *testing.T, msg, outFile string, mcids ...int) {
+	t.Helper()
+
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	// MCID 0 at the top, MCID 1 below.
+	content := "/P <</MCID 0>> BDC 72 700 200 10 re f EMC " +
+		"/P <</MCID 1>> BDC 72 680 200 10 re f EMC"
+	sd, _ := ctx.NewStreamDictForBuf([]byte(content))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	d, pageIndRef, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *ir
+
+	rootIndRef, err := ctx.IndRefForNewObject(types.Dict{"Type": types.Name("StructTreeRoot")})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	var kids types.Array
+	for _, mcid := range mcids {
+		ir, err := ctx.IndRefForNewObject(types.Dict{
+			"Type": types.Name("StructElem"),
+			"S":    types.Name("P"),
+			"P":    *rootIndRef,
+			"Pg":   *pageIndRef,
+			"K":    types.Integer(mcid),
+		})
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		kids = append(kids, *ir)
+	}
+
+	root, err := ctx.DereferenceDict(*rootIndRef)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	root["K"] = kids
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	catalog["StructTreeRoot"] = *rootIndRef
+	catalog["MarkInfo"] = types.Dict{"Marked": types.Boolean(true)}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+}
+
+func TestReadingOrderIssues(t *testing.T) {
+	msg := "TestReadingOrderIssues"
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	for _, tt := range []struct {
+		mcids []int
+		want  int
+	}{
+		{[]int{0, 1}, 0},
+		{[]int{1, 0}, 1},
+	} {
+		writeTaggedTestFile(t, msg, outFile, tt.mcids...)
+
+		ss, err := api.ReadingOrderIssuesFile(outFile, model.NewDefaultConfiguration())
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if len(ss) != tt.want {
+			t.Fatalf("%s %v: want %d issues, got: %v\n", msg, tt.mcids, tt.want, ss)
+		}
+	}
+}
+
+func TestAutoTag(t *testing.T) {
+	msg := "TestAutoTag"
+	inFile := filepath.Join(inDir, "go-lecture.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ss, err := api.AutoTagFile(inFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(ss) == 0 {
+		t.Fatalf("%s: want pages tagged\n", msg)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := catalog.Find("StructTreeRoot"); !found {
+		t.Fatalf("%s: missing StructTreeRoot\n", msg)
+	}
+
+	if _, err := api.ReadingOrderIssuesFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if _, err := api.AutoTagFile(outFile, "", nil); err != pdfcpu.ErrAlreadyTagged {
+		t.Fatalf("%s: want %v, got: %v\n", msg, pdfcpu.ErrAlreadyTagged, err)
+	}
+}
+
+func TestStripStructure(t *testing.T) {
+	msg := "TestStripStructure"
+	inFile := filepath.Join(inDir, "go-lecture.pdf")
+	tmpFile := filepath.Join(outDir, "tagged.pdf")
+	outFile := filepath.Join(outDir, "untagged.pdf")
+
+	if _, err := api.AutoTagFile(inFile, tmpFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.StripStructureFile(tmpFile, outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) < 2 || ss[0] != "removed /StructTreeRoot" || !strings.HasPrefix(ss[len(ss)-1], "file size:") {
+		t.Fatalf("%s: unexpected result: %v\n", msg, ss)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateContext(ctx); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := catalog.Find("StructTreeRoot"); found {
+		t.Fatalf("%s: StructTreeRoot not removed\n", msg)
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if _, found := d.Find("StructParents"); found {
+			t.Fatalf("%s: page %d: StructParents not removed\n", msg, i)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if strings.Contains(string(bb), "MCID") {
+			t.Fatalf("%s: page %d: marked content struct references not removed\n", msg, i)
+		}
+	}
+
+	if _, err := api.StripStructureFile(outFile, "", nil); err != pdfcpu.ErrNotTagged {
+		t.Fatalf("%s: want %v, got: %v\n", msg, pdfcpu.ErrNotTagged, err)
+	}
+}
+
+func TestSetStructLang(t *testing.T) {
+	msg := "TestSetStructLang"
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	writeTaggedTestFile(t, msg, outFile, 0, 1)
+
+	// Select the upper paragraph by region.
+	sel := pdfcpu.StructElementSelection{PageNr: 1, Region: types.NewRectangle(60, 695, 300, 715)}
+	ss, err := api.SetStructLangFile(outFile, "", sel, "fr", nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 1 || !strings.Contains(ss[0], "(MCID 0): set /Lang fr") {
+		t.Fatalf("%s: want MCID 0 set, got: %v\n", msg, ss)
+	}
+
+	var objNr int
+	if _, err := fmt.Sscanf(ss[0], "obj %d:", &objNr); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d, err := ctx.DereferenceDict(*types.NewIndirectRef(objNr, 0))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if s := d.StringEntry("Lang"); s == nil || *s != "fr" {
+		t.Fatalf("%s: want /Lang fr, got: %v\n", msg, d)
+	}
+
+	// Remove /Lang selecting by object number.
+	if _, err := api.SetStructLangFile(outFile, "", pdfcpu.StructElementSelection{ObjNr: objNr}, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if d, err = ctx.DereferenceDict(*types.NewIndirectRef(objNr, 0)); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if _, found := d.Find("Lang"); found {
+		t.Fatalf("%s: want /Lang removed, got: %v\n", msg, d)
+	}
+
+	if _, err := api.SetStructLangFile(outFile, "", sel, "not a language", nil); err == nil {
+		t.Fatalf("%s: want error for invalid language identifier\n", msg)
+	}
+}
+
+func TestArtifacts(t *testing.T) {
+	msg := "TestArtifacts"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	sd, _ := ctx.NewStreamDictForBuf([]byte("0 0 10 10 re f"))
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.Insert("BBox", types.NewNumberArray(0, 0, 10, 10))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	logo, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// A page background, an artifact already tagged, a logo and some text.
+	content := "q 0 0 10000 10000 re f Q /Artifact BMC 0 0 5 5 re f EMC q 1 0 0 1 20 20 cm /Logo Do Q BT (x) Tj ET"
+
+	for pageNr := 1; pageNr <= 2; pageNr++ {
+		d, _, _, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		ir, err := ctx.StreamDictIndRef([]byte(content))
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		d["Contents"] = *ir
+		d["Resources"] = types.Dict{"XObject": types.Dict{"Logo": *logo}}
+	}
+
+	ss, err := pdfcpu.ArtifactCandidates(ctx, types.IntSet{1: true})
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	want := []string{
+		"page 1: ops 1-2: background covering 100% of the page",
+		fmt.Sprintf("page 1: ops 10: /Logo (obj#%d) repeated on 2 pages", logo.ObjectNumber.Value()),
+	}
+	if len(ss) != len(want) || ss[0] != want[0] || ss[1] != want[1] {
+		t.Fatalf("%s: want %v, got: %v\n", msg, want, ss)
+	}
+
+	for _, s := range []string{"12", "4-5", "1", "3-5,4"} {
+		rr, err := pdfcpu.ParseArtifactRanges(s)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		if _, err := pdfcpu.TagArtifacts(ctx, 1, rr); err == nil {
+			t.Fatalf("%s: want error for ops %s\n", msg, s)
+		}
+	}
+
+	rr, err := pdfcpu.ParseArtifactRanges("1-2,10")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ss, err = pdfcpu.TagArtifacts(ctx, 1, rr); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 {
+		t.Fatalf("%s: want 2 ranges tagged, got: %v\n", msg, ss)
+	}
+
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	// Only the candidates of page 2 remain.
+	if ss, err = api.ArtifactCandidatesFile(outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if len(ss) != 2 || !strings.HasPrefix(ss[0], "page 2:") || !strings.HasPrefix(ss[1], "page 2:") {
+		t.Fatalf("%s: want candidates of page 2 only, got: %v\n", msg, ss)
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestFillTemplatePage(t *testing.T) {
+	msg := "TestFillTemplatePage"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	tplFile := filepath.Join(outDir, "template.pdf")
+	csvFile := filepath.Join(outDir, "template.csv")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	// Turn page 2 into a certificate template using Helvetica.
+	ctx, err := api.ReadContextFile(inFile)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+
+	d, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	fontDict := types.Dict(map[string]types.Object{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+	})
+	d["Resources"] = types.Dict(map[string]types.Object{
+		"Font": types.Dict(map[string]types.Object{"F1": fontDict}),
+	})
+
+	sd, _ := ctx.NewStreamDictForBuf([]byte("BT /F1 12 Tf 72 700 Td (Dear {{name}},) Tj 100 0 Td (signed) Tj 0 -20 Td [({{ date) -50 (}}) 100 (.)] TJ ET"))
+	if err := sd.Encode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	d["Contents"] = *ir
+
+	if err := api.WriteContextFile(ctx, tplFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := os.WriteFile(csvFile, []byte("name,date\nJo,2023-01-01\nJane Doe,2023-02-02\n"), 0644); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.FillTemplatePageFile(tplFile, csvFile, outFile, 2, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if ctx, err = api.ReadContextFile(outFile); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if ctx.PageCount != 2 {
+		t.Fatalf("%s: want 2 pages, got: %d\n", msg, ctx.PageCount)
+	}
+
+	// "{{name}}" is 46.044 wide in Helvetica 12, "Jo" is 12.672 wide.
+	for i, want := range [][]string{
+		{"(Dear Jo,) Tj", "66.628 0 Td", "33.372 -20 Td", "[(2023-01-01) 100 (.)] TJ"},
+		{"(Dear Jane Doe,) Tj", "105.316 0 Td", "-5.316 -20 Td", "[(2023-02-02) 100 (.)] TJ"},
+	} {
+		d, _, _, err := ctx.PageDict(i+1, false)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		bb, err := ctx.PageContent(d)
+		if err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		for _, s := range want {
+			if !strings.Contains(string(bb), s) {
+				t.Fatalf("%s: page %d: want %q in content:\n%s\n", msg, i+1, s, bb)
+			}
+		}
+	}
+}
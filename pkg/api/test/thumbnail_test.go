@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/jpeg"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+)
+
+func xmpThumbnails(t *testing.T, msg, fileName string) [][][]byte {
+	t.Helper()
+
+	ctx, err := api.ReadContextFile(fileName)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ctx.RootDict["Metadata"])
+	if err != nil || sd == nil {
+		t.Fatalf("%s: missing metadata: %v\n", msg, err)
+	}
+	if err := sd.Decode(); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	re := regexp.MustCompile(`<xmpGImg:width>(\d+)</xmpGImg:width>\s*<xmpGImg:height>(\d+)</xmpGImg:height>\s*<xmpGImg:format>JPEG</xmpGImg:format>\s*<xmpGImg:image>([^<]+)</xmpGImg:image>`)
+	return re.FindAllSubmatch(sd.Content, -1)
+}
+
+func TestSetXMPThumbnail(t *testing.T) {
+	msg := "TestSetXMPThumbnail"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "thumbnail.pdf")
+
+	// Set a PNG thumbnail, then replace it by a JPEG thumbnail.
+	for _, fn := range []string{"logoSmall.png", "mountain.jpg"} {
+		if err := api.SetXMPThumbnailFile(inFile, filepath.Join(resDir, fn), outFile, nil); err != nil {
+			t.Fatalf("%s: %v\n", msg, err)
+		}
+		inFile = outFile
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	mm := xmpThumbnails(t, msg, outFile)
+	if len(mm) != 1 {
+		t.Fatalf("%s: want 1 thumbnail, got: %d\n", msg, len(mm))
+	}
+
+	bb, err := base64.StdEncoding.DecodeString(string(mm[0][3]))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	c, err := jpeg.DecodeConfig(bytes.NewReader(bb))
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+	if c.Width != 1667 || c.Height != 2646 || string(mm[0][1]) != "1667" || string(mm[0][2]) != "2646" {
+		t.Fatalf("%s: want 1667x2646 thumbnail, got: %dx%d (%sx%s)\n", msg, c.Width, c.Height, mm[0][1], mm[0][2])
+	}
+}
+
+func TestSetXMPThumbnailWithoutMetadata(t *testing.T) {
+	msg := "TestSetXMPThumbnailWithoutMetadata"
+	outFile := filepath.Join(outDir, "thumbnailNoMetadata.pdf")
+
+	imgFile := filepath.Join(resDir, "logoSmall.png")
+	if err := api.ImportImagesFile([]string{imgFile}, outFile, nil, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.SetXMPThumbnailFile(outFile, imgFile, "", nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if mm := xmpThumbnails(t, msg, outFile); len(mm) != 1 {
+		t.Fatalf("%s: want 1 thumbnail, got: %d\n", msg, len(mm))
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+)
+
+func TestSetPrintPreferences(t *testing.T) {
+	msg := "TestSetPrintPreferences"
+	inFile := filepath.Join(inDir, "Acroforms2.pdf")
+	outFile := filepath.Join(outDir, "test.pdf")
+
+	pp, err := pdfcpu.ParsePrintPreferences("scal:none, area:crop, clip:crop")
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	if err := api.SetPrintPreferencesFile(inFile, outFile, pp, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	ss, err := api.ListViewerPreferencesFile(outFile, nil)
+	if err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	want := []string{"PrintArea: /CropBox", "PrintClip: /CropBox", "PrintScaling: /None"}
+	if !reflect.DeepEqual(ss, want) {
+		t.Fatalf("%s: want %v, got %v\n", msg, want, ss)
+	}
+
+	if err := api.ValidateFile(outFile, nil); err != nil {
+		t.Fatalf("%s: %v\n", msg, err)
+	}
+
+	for _, s := range []string{"", "scaling:fit", "area:page", "foo:bar"} {
+		if _, err := pdfcpu.ParsePrintPreferences(s); err == nil {
+			t.Fatalf("%s: want error for %q\n", msg, s)
+		}
+	}
+}
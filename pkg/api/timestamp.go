@@ -0,0 +1,220 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+func readAll(rs io.ReadSeeker) ([]byte, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(rs)
+}
+
+func writeAt(ws io.WriteSeeker, off int64, bb []byte) error {
+	if _, err := ws.Seek(off, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := ws.Write(bb)
+	return err
+}
+
+// PrepareDocTimeStamp appends a PDF increment to rws containing a document timestamp signature (/Type /DocTimeStamp)
+// with a placeholder of tokenSize bytes for an RFC 3161 timestamp token.
+// The result is the SHA-256 digest of the signed byte range to be submitted to a timestamp authority.
+// Use EmbedDocTimeStamp to insert the token received.
+func PrepareDocTimeStamp(rws io.ReadWriteSeeker, tokenSize int, conf *model.Configuration) ([]byte, error) {
+	if rws == nil {
+		return nil, errors.New("pdfcpu: PrepareDocTimeStamp: Please provide rws")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.PREPARETIMESTAMP
+
+	ctx, _, _, err := readAndValidate(rws, conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if *ctx.HeaderVersion < model.V14 {
+		return nil, errors.New("Increment writing not supported for PDF version < V1.4 (Hint: Use pdfcpu optimize then try again)")
+	}
+
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+
+	ctx.Write.Increment = true
+	ctx.Write.Offset = ctx.Read.FileSize
+
+	sigIndRef, err := pdfcpu.AddDocTimeStampField(ctx, tokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Stats.Printf("XRefTable:\n%s\n", ctx)
+
+	if _, err = rws.Seek(0, io.SeekEnd); err != nil {
+		return nil, err
+	}
+
+	if err := WriteIncrement(ctx, rws); err != nil {
+		return nil, err
+	}
+
+	// Fill in the byte range now that the final file size is known.
+	bb, err := readAll(rws)
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := pdfcpu.LocateSignatureSlots(bb, ctx.Write.Table[sigIndRef.ObjectNumber.Value()])
+	if err != nil {
+		return nil, err
+	}
+
+	br := ss.ByteRange(int64(len(bb)))
+	brBytes, err := ss.ByteRangeBytes(br)
+	if err != nil {
+		return nil, err
+	}
+	copy(bb[ss.ByteRangeStart:], brBytes)
+
+	if err := writeAt(rws, ss.ByteRangeStart, brBytes); err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	h.Write(bb[br[0]:br[1]])
+	h.Write(bb[br[2]:])
+
+	return h.Sum(nil), nil
+}
+
+// PrepareDocTimeStampFile appends a document timestamp placeholder to inFile and writes the result to outFile.
+// The result is the SHA-256 digest of the signed byte range to be submitted to a timestamp authority.
+func PrepareDocTimeStampFile(inFile, outFile string, tokenSize int, conf *model.Configuration) ([]byte, error) {
+	if outFile != "" && inFile != outFile {
+		bb, err := os.ReadFile(inFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(outFile, bb, 0644); err != nil {
+			return nil, err
+		}
+		inFile = outFile
+	}
+
+	log.CLI.Printf("writing %s...\n", inFile)
+
+	f, err := os.OpenFile(inFile, os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return PrepareDocTimeStamp(f, tokenSize, conf)
+}
+
+// EmbedDocTimeStamp writes the RFC 3161 timestamp token into the placeholder created by PrepareDocTimeStamp.
+// rws is patched in place, the signed byte range stays untouched.
+func EmbedDocTimeStamp(rws io.ReadWriteSeeker, token []byte, conf *model.Configuration) error {
+	if rws == nil {
+		return errors.New("pdfcpu: EmbedDocTimeStamp: Please provide rws")
+	}
+	if len(token) == 0 {
+		return errors.New("pdfcpu: EmbedDocTimeStamp: Please provide token")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EMBEDTIMESTAMP
+
+	ctx, err := ReadContext(rws, conf)
+	if err != nil {
+		return err
+	}
+
+	objNr, err := pdfcpu.PendingDocTimeStamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	e := ctx.Table[objNr]
+	if e.Offset == nil || e.Compressed {
+		return errors.Errorf("pdfcpu: obj#%d: signature dict must not be compressed", objNr)
+	}
+
+	bb, err := readAll(rws)
+	if err != nil {
+		return err
+	}
+
+	ss, err := pdfcpu.LocateSignatureSlots(bb, *e.Offset)
+	if err != nil {
+		return err
+	}
+
+	br := ss.ByteRange(int64(len(bb)))
+	a := e.Object.(types.Dict).ArrayEntry("ByteRange")
+	if len(a) != 4 {
+		return errors.Errorf("pdfcpu: obj#%d: corrupt /ByteRange", objNr)
+	}
+	for i, o := range a {
+		if j, ok := o.(types.Integer); !ok || int64(j) != br[i] {
+			return errors.Errorf("pdfcpu: obj#%d: /ByteRange does not match placeholder - file modified after preparing?", objNr)
+		}
+	}
+
+	s := hex.EncodeToString(token)
+	if max := int(ss.ContentsEnd-ss.ContentsStart) - 2; len(s) > max {
+		return errors.Errorf("pdfcpu: timestamp token exceeds reserved size of %d bytes: %d", max/2, len(token))
+	}
+
+	return writeAt(rws, ss.ContentsStart+1, []byte(s))
+}
+
+// EmbedDocTimeStampFile writes the RFC 3161 timestamp token read from tokenFile into the placeholder of inFile.
+func EmbedDocTimeStampFile(inFile, tokenFile string, conf *model.Configuration) error {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return err
+	}
+
+	log.CLI.Printf("writing %s...\n", inFile)
+
+	f, err := os.OpenFile(inFile, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return EmbedDocTimeStamp(f, token, conf)
+}
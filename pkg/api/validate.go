@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pkg/errors"
 )
@@ -162,3 +163,118 @@ func DumpObjectFile(inFile string, objNr int, hex bool, conf *model.Configuratio
 
 	return DumpObject(f, objNr, hex, conf)
 }
+
+// DanglingReferences returns a list of all indirect references of rs pointing to a missing or free object.
+// rs gets read without validation.
+func DanglingReferences(rs io.ReadSeeker, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: DanglingReferences: Please provide rs")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDANGLINGREFS
+
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+	for _, dr := range pdfcpu.DanglingReferences(ctx) {
+		ss = append(ss, dr.String())
+	}
+
+	return ss, nil
+}
+
+// DanglingReferencesFile returns a list of all indirect references of inFile pointing to a missing or free object.
+func DanglingReferencesFile(inFile string, conf *model.Configuration) ([]string, error) {
+	f, err := os.Open(inFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DanglingReferences(f, conf)
+}
+
+// NullDanglingReferences replaces all indirect references of rs pointing to a missing or free object with null
+// and writes the result to w.
+// rs gets read without validation, the result gets validated unless conf.ValidationMode is ValidationNone.
+// The result is a list of all references replaced.
+func NullDanglingReferences(rs io.ReadSeeker, w io.Writer, conf *model.Configuration) ([]string, error) {
+	if rs == nil {
+		return nil, errors.New("pdfcpu: NullDanglingReferences: Please provide rs")
+	}
+	if w == nil {
+		return nil, errors.New("pdfcpu: NullDanglingReferences: Please provide w")
+	}
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NULLDANGLINGREFS
+
+	ctx, err := ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+	for _, dr := range pdfcpu.NullDanglingReferences(ctx) {
+		ss = append(ss, dr.String())
+	}
+
+	if conf.ValidationMode != model.ValidationNone {
+		if err = ValidateContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, WriteContext(ctx, w)
+}
+
+// NullDanglingReferencesFile replaces all indirect references of inFile pointing to a missing or free object with null
+// and writes the result to outFile.
+// The result is a list of all references replaced.
+func NullDanglingReferencesFile(inFile, outFile string, conf *model.Configuration) (ss []string, err error) {
+	var f1, f2 *os.File
+
+	if f1, err = os.Open(inFile); err != nil {
+		return nil, err
+	}
+
+	tmpFile := inFile + ".tmp"
+	if outFile != "" && inFile != outFile {
+		tmpFile = outFile
+		log.CLI.Printf("writing %s...\n", outFile)
+	} else {
+		log.CLI.Printf("writing %s...\n", inFile)
+	}
+	if f2, err = os.Create(tmpFile); err != nil {
+		f1.Close()
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			f2.Close()
+			f1.Close()
+			if outFile == "" || inFile == outFile {
+				os.Remove(tmpFile)
+			}
+			return
+		}
+		if err = f2.Close(); err != nil {
+			return
+		}
+		if err = f1.Close(); err != nil {
+			return
+		}
+		if outFile == "" || inFile == outFile {
+			err = os.Rename(tmpFile, inFile)
+		}
+	}()
+
+	return NullDanglingReferences(f1, f2, conf)
+}
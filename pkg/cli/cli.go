@@ -18,7 +18,11 @@ limitations under the License.
 package cli
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/ex-preman/pdfcpu/pkg/api"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pkg/errors"
 )
@@ -37,6 +41,11 @@ func Optimize(cmd *Command) ([]string, error) {
 	return nil, api.OptimizeFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
 }
 
+// GarbageCollect frees unreferenced objects of inFile and writes the result to outFile.
+func GarbageCollect(cmd *Command) ([]string, error) {
+	return api.GarbageCollectFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
 // Encrypt inFile and write result to outFile.
 func Encrypt(cmd *Command) ([]string, error) {
 	return nil, api.EncryptFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
@@ -67,6 +76,11 @@ func SetPermissions(cmd *Command) ([]string, error) {
 	return nil, api.SetPermissionsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
 }
 
+// ClearRestrictions removes the encryption and user access restrictions of inFile and writes the result to outFile.
+func ClearRestrictions(cmd *Command) ([]string, error) {
+	return api.ClearRestrictionsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
 // Split inFile into single page PDFs and write result files to outDir.
 func Split(cmd *Command) ([]string, error) {
 	return nil, api.SplitFile(*cmd.InFile, *cmd.OutDir, cmd.Span, cmd.Conf)
@@ -77,6 +91,11 @@ func Trim(cmd *Command) ([]string, error) {
 	return nil, api.TrimFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Conf)
 }
 
+// NormalizeRotation bakes the rotation of selected pages of inFile into their content and writes the result to outFile.
+func NormalizeRotation(cmd *Command) ([]string, error) {
+	return api.NormalizeRotationFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Conf)
+}
+
 // Rotate selected pages of inFile and write result to outFile.
 func Rotate(cmd *Command) ([]string, error) {
 	return nil, api.RotateFile(*cmd.InFile, *cmd.OutFile, cmd.Rotation, cmd.PageSelection, cmd.Conf)
@@ -123,6 +142,197 @@ func RemovePages(cmd *Command) ([]string, error) {
 	return nil, api.RemovePagesFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Conf)
 }
 
+// HashPages returns a content hash for selected pages of inFile.
+func HashPages(cmd *Command) ([]string, error) {
+	m, err := api.PageHashesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+
+	pageNrs := make([]int, 0, len(m))
+	for pageNr := range m {
+		pageNrs = append(pageNrs, pageNr)
+	}
+	sort.Ints(pageNrs)
+
+	ss := make([]string, len(pageNrs))
+	for i, pageNr := range pageNrs {
+		ss[i] = fmt.Sprintf("page %d: %s", pageNr, m[pageNr])
+	}
+
+	return ss, nil
+}
+
+// RepairPages fixes pages of inFile missing required entries and writes the result to outFile.
+func RepairPages(cmd *Command) ([]string, error) {
+	return api.RepairPagesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// TruncatePages removes all pages of inFile following page maxPages and writes the result to outFile.
+func TruncatePages(cmd *Command) ([]string, error) {
+	return nil, api.TruncatePagesFile(*cmd.InFile, *cmd.OutFile, cmd.IntVals[0], cmd.Conf)
+}
+
+// CountPages returns the page count of inFile as recorded in its page tree root.
+func CountPages(cmd *Command) ([]string, error) {
+	n, err := api.ReadPageCountFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("pages: %d", n)}, nil
+}
+
+// ShareResources shares equal resources among the pages of inFile and writes the result to outFile.
+func ShareResources(cmd *Command) ([]string, error) {
+	return api.ShareResourcesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListNonStandardOperators returns a list of deprecated or non standard content stream operators used by selected pages of inFile.
+func ListNonStandardOperators(cmd *Command) ([]string, error) {
+	return api.NonStandardOperatorsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
+// CheckScanned reports whether inFile looks like a scan wrapped into PDF without OCR.
+func CheckScanned(cmd *Command) ([]string, error) {
+	ok, confidence, err := api.IsScannedDocumentFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("scanned: %t (%.0f%% of pages image only)", ok, confidence*100)}, nil
+}
+
+// ListDegeneratePages returns a list of pages of inFile with degenerate media boxes or without content.
+func ListDegeneratePages(cmd *Command) ([]string, error) {
+	return api.DegeneratePagesFile(*cmd.InFile, cmd.Conf)
+}
+
+// RemoveDegeneratePages removes pages of inFile with degenerate media boxes or without content and writes the result to outFile.
+func RemoveDegeneratePages(cmd *Command) ([]string, error) {
+	return api.RemoveDegeneratePagesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListOversizedPages returns a list of pages of inFile exceeding the maximum page dimensions.
+func ListOversizedPages(cmd *Command) ([]string, error) {
+	return api.OversizedPagesFile(*cmd.InFile, cmd.Conf)
+}
+
+// ClampPages scales down pages of inFile exceeding the maximum page dimensions and writes the result to outFile.
+func ClampPages(cmd *Command) ([]string, error) {
+	return api.ClampPagesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListPageRotations returns a list of the rotation in effect for each page of inFile.
+func ListPageRotations(cmd *Command) ([]string, error) {
+	return api.PageRotationsFile(*cmd.InFile, cmd.Conf)
+}
+
+// NormalizeRotateInheritance sets the rotation in effect on each page of inFile and writes the result to outFile.
+func NormalizeRotateInheritance(cmd *Command) ([]string, error) {
+	return api.NormalizeRotateInheritanceFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListSplitContent returns a list of content stream parts of inFile divided within a lexical token.
+func ListSplitContent(cmd *Command) ([]string, error) {
+	return api.SplitContentPagesFile(*cmd.InFile, cmd.Conf)
+}
+
+// ResplitContent moves content stream part boundaries of inFile to token boundaries and writes the result to outFile.
+func ResplitContent(cmd *Command) ([]string, error) {
+	return api.ResplitContentPagesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListBrokenDestinations returns a list of destinations of inFile pointing to nonexistent pages.
+func ListBrokenDestinations(cmd *Command) ([]string, error) {
+	return api.ListBrokenDestinationsFile(*cmd.InFile, cmd.Conf)
+}
+
+// FixBrokenDestinations removes or redirects destinations of inFile pointing to nonexistent pages and writes the result to outFile.
+func FixBrokenDestinations(cmd *Command) ([]string, error) {
+	return api.FixBrokenDestinationsFile(*cmd.InFile, *cmd.OutFile, cmd.BoolVal, cmd.Conf)
+}
+
+// InlineNamedDestinations replaces named destinations of inFile by explicit destinations and writes the result to outFile.
+func InlineNamedDestinations(cmd *Command) ([]string, error) {
+	return api.InlineNamedDestinationsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// NameExplicitDestinations replaces explicit destinations of inFile by named destinations and writes the result to outFile.
+func NameExplicitDestinations(cmd *Command) ([]string, error) {
+	return api.NameExplicitDestinationsFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListReadingOrderIssues returns a list of struct elements of inFile out of reading order.
+func ListReadingOrderIssues(cmd *Command) ([]string, error) {
+	return api.ReadingOrderIssuesFile(*cmd.InFile, cmd.Conf)
+}
+
+// Redact removes all content of inFile within the areas listed in inFileJSON and writes the result to outFile.
+func Redact(cmd *Command) ([]string, error) {
+	return api.RedactFile(*cmd.InFile, *cmd.InFileJSON, *cmd.OutFile, cmd.Conf)
+}
+
+// Pixelate pixelates regions of an image of inFile and writes the result to outFile.
+func Pixelate(cmd *Command) ([]string, error) {
+	return api.PixelateFile(*cmd.InFile, *cmd.OutFile, cmd.IntVals[0], cmd.StringVals[0], cmd.Rects, 0, cmd.Conf)
+}
+
+// SetStructLang sets the language of selected struct elements of inFile and writes the result to outFile.
+func SetStructLang(cmd *Command) ([]string, error) {
+	sel := pdfcpu.StructElementSelection{ObjNr: cmd.IntVals[0], PageNr: cmd.IntVals[1]}
+	if len(cmd.Rects) > 0 {
+		sel.Region = &cmd.Rects[0]
+	}
+	return api.SetStructLangFile(*cmd.InFile, *cmd.OutFile, sel, cmd.StringVals[0], cmd.Conf)
+}
+
+// ListArtifactCandidates returns a list of untagged content of selected pages of inFile likely to be decorative.
+func ListArtifactCandidates(cmd *Command) ([]string, error) {
+	return api.ArtifactCandidatesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
+// TagArtifacts wraps content operation ranges of a page of inFile into /Artifact marked content and writes the result to outFile.
+func TagArtifacts(cmd *Command) ([]string, error) {
+	var rr []pdfcpu.ArtifactRange
+	for i := 1; i+1 < len(cmd.IntVals); i += 2 {
+		rr = append(rr, pdfcpu.ArtifactRange{From: cmd.IntVals[i], Thru: cmd.IntVals[i+1]})
+	}
+	return api.TagArtifactsFile(*cmd.InFile, *cmd.OutFile, cmd.IntVals[0], rr, cmd.Conf)
+}
+
+// ListDanglingReferences returns a list of all references of inFile to missing or free objects.
+func ListDanglingReferences(cmd *Command) ([]string, error) {
+	return api.DanglingReferencesFile(*cmd.InFile, cmd.Conf)
+}
+
+// NullDanglingReferences replaces all references of inFile to missing or free objects with null and writes the result to outFile.
+func NullDanglingReferences(cmd *Command) ([]string, error) {
+	return api.NullDanglingReferencesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// EstimateCost returns metrics of inFile predictive of its processing cost.
+func EstimateCost(cmd *Command) ([]string, error) {
+	ce, err := api.EstimateCostFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return ce.List(), nil
+}
+
+// AutoTag generates a basic structure tree for inFile and writes the result to outFile.
+func AutoTag(cmd *Command) ([]string, error) {
+	return api.AutoTagFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// StripStructure removes the structure tree of the tagged document inFile and writes the result to outFile.
+func StripStructure(cmd *Command) ([]string, error) {
+	return api.StripStructureFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// RecolorWatermarks renders watermarks or stamps of selected pages of inFile in gray and writes the result to outFile.
+func RecolorWatermarks(cmd *Command) ([]string, error) {
+	return nil, api.RecolorWatermarksFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.FloatVals[0], cmd.FloatVals[1], cmd.Conf)
+}
+
 // MergeCreate merges inFiles in the order specified and writes the result to outFile.
 func MergeCreate(cmd *Command) ([]string, error) {
 	return nil, api.MergeCreateFile(cmd.InFiles, *cmd.OutFile, cmd.Conf)
@@ -133,6 +343,16 @@ func MergeAppend(cmd *Command) ([]string, error) {
 	return nil, api.MergeAppendFile(cmd.InFiles, *cmd.OutFile, cmd.Conf)
 }
 
+// MergeSelected merges selected pages of files in the order specified and writes the result to outFile.
+func MergeSelected(cmd *Command) ([]string, error) {
+	return nil, api.MergeSelectedFile(cmd.MergeSels, *cmd.OutFile, cmd.Conf)
+}
+
+// Assemble produces a document as described by a JSON or YAML manifest file.
+func Assemble(cmd *Command) ([]string, error) {
+	return nil, api.AssembleFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
 // ExtractImages dumps embedded image resources from inFile into outDir for selected pages.
 func ExtractImages(cmd *Command) ([]string, error) {
 	return nil, api.ExtractImagesFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
@@ -153,11 +373,31 @@ func ExtractContent(cmd *Command) ([]string, error) {
 	return nil, api.ExtractContentFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
 }
 
+// ExtractEPS generates EPS files from inFile in outDir for selected pages.
+func ExtractEPS(cmd *Command) ([]string, error) {
+	return nil, api.ExtractPagesEPSFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
+}
+
+// ExtractParagraphs writes the paragraph structure of selected pages of inFile as JSON into outDir.
+func ExtractParagraphs(cmd *Command) ([]string, error) {
+	return nil, api.ExtractParagraphsFile(*cmd.InFile, *cmd.OutDir, cmd.PageSelection, cmd.Conf)
+}
+
+// ExtractBundle exports inFile as structured bundle of JSON and extracted assets into outDir.
+func ExtractBundle(cmd *Command) ([]string, error) {
+	return nil, api.ExportBundleFile(*cmd.InFile, *cmd.OutDir, cmd.Conf)
+}
+
 // ExtractMetadata dumps all metadata dict entries for inFile into outDir.
 func ExtractMetadata(cmd *Command) ([]string, error) {
 	return nil, api.ExtractMetadataFile(*cmd.InFile, *cmd.OutDir, cmd.Conf)
 }
 
+// ExtractICCProfiles dumps all embedded ICC profiles of inFile into outDir.
+func ExtractICCProfiles(cmd *Command) ([]string, error) {
+	return nil, api.ExtractICCProfilesFile(*cmd.InFile, *cmd.OutDir, cmd.Conf)
+}
+
 // ListAttachments returns a list of embedded file attachments for inFile.
 func ListAttachments(cmd *Command) ([]string, error) {
 	return api.ListAttachmentsFile(*cmd.InFile, cmd.Conf)
@@ -193,6 +433,59 @@ func ListFonts(cmd *Command) ([]string, error) {
 	return api.ListFonts()
 }
 
+// ConvertType3Fonts converts text of inFile shown using Type3 fonts to vector outlines and writes the result to outFile.
+func ConvertType3Fonts(cmd *Command) ([]string, error) {
+	return api.ConvertType3FontsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Conf)
+}
+
+// ListDocumentFonts returns a list of the fonts of inFile including embedded font program size and subset state.
+func ListDocumentFonts(cmd *Command) ([]string, error) {
+	return api.ListDocumentFontsFile(*cmd.InFile, cmd.Conf)
+}
+
+// MeasureText returns width, ascent and descent of text and the largest font size fitting a box if specified.
+func MeasureText(cmd *Command) ([]string, error) {
+	fontName, text, fontSize := cmd.StringVals[0], cmd.StringVals[1], cmd.IntVals[0]
+
+	w, a, d, err := api.MeasureText(fontName, fontSize, text)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := []string{
+		fmt.Sprintf("width:   %.2f", w),
+		fmt.Sprintf("ascent:  %.2f", a),
+		fmt.Sprintf("descent: %.2f", d),
+	}
+
+	if len(cmd.FloatVals) == 2 {
+		bw, bh := cmd.FloatVals[0], cmd.FloatVals[1]
+		fs, err := api.MaxFontSize(fontName, text, bw, bh)
+		if err != nil {
+			return nil, err
+		}
+		fits := w <= bw && a+d <= bh
+		ss = append(ss, fmt.Sprintf("fits %.2f x %.2f: %t", bw, bh, fits), fmt.Sprintf("max font size: %d", fs))
+	}
+
+	return ss, nil
+}
+
+// EmbedFonts embeds font files into matching non embedded fonts of inFile and writes the result to outFile.
+func EmbedFonts(cmd *Command) ([]string, error) {
+	return api.EmbedFontsFile(*cmd.InFile, *cmd.OutFile, cmd.StringMap, cmd.Conf)
+}
+
+// ListMissingGlyphs returns a report of char codes of inFile not mapping to a glyph of the embedded font program.
+func ListMissingGlyphs(cmd *Command) ([]string, error) {
+	return api.ListMissingGlyphsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
+// RepairToUnicode generates missing or broken ToUnicode CMaps of inFile and writes the result to outFile.
+func RepairToUnicode(cmd *Command) ([]string, error) {
+	return api.RepairToUnicodeFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
 // InstallFonts installs True Type fonts into the pdfcpu pconfig dir.
 func InstallFonts(cmd *Command) ([]string, error) {
 	return nil, api.InstallFonts(cmd.InFiles)
@@ -265,11 +558,63 @@ func RemoveAnnotations(cmd *Command) ([]string, error) {
 	return nil, api.RemoveAnnotationsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.StringVals, cmd.IntVals, cmd.Conf, incr)
 }
 
+// CheckAnnotations returns a list of misplaced annotations of inFile.
+func CheckAnnotations(cmd *Command) ([]string, error) {
+	return api.AnnotationIssuesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
+// ClampAnnotations clamps annotations of inFile lying outside their page to the crop box and writes the result to outFile.
+func ClampAnnotations(cmd *Command) ([]string, error) {
+	return api.ClampAnnotationsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.StringVals, cmd.Conf)
+}
+
+// ListDuplicateAnnotations returns a list of exact duplicate annotations of inFile.
+func ListDuplicateAnnotations(cmd *Command) ([]string, error) {
+	return api.DuplicateAnnotationsFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
+// RemoveDuplicateAnnotations removes exact duplicate annotations of inFile and writes the result to outFile.
+func RemoveDuplicateAnnotations(cmd *Command) ([]string, error) {
+	return api.RemoveDuplicateAnnotationsFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Conf)
+}
+
 // ListImages returns inFiles embedded images.
 func ListImages(cmd *Command) ([]string, error) {
 	return api.ListImagesFile(cmd.InFiles, cmd.PageSelection, cmd.Conf)
 }
 
+// ListResolutions returns a list of the effective resolutions of the main image of selected pages of inFile.
+func ListResolutions(cmd *Command) ([]string, error) {
+	minDPI := cmd.FloatVals[0]
+
+	prs, err := api.PageResolutionsFile(*cmd.InFile, cmd.PageSelection, minDPI, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ss  []string
+		low int
+	)
+	for _, pr := range prs {
+		ss = append(ss, pr.String())
+		if pr.BelowMin {
+			low++
+		}
+	}
+
+	if minDPI > 0 {
+		ss = append(ss, fmt.Sprintf("%d pages below %.0f dpi", low, minDPI))
+	}
+
+	return ss, nil
+}
+
+// CheckImages returns a list of corrupt images of inFile.
+func CheckImages(cmd *Command) ([]string, error) {
+	return api.ImageIssuesFile(*cmd.InFile, cmd.PageSelection, cmd.Conf)
+}
+
 // Dump known object to stdout.
 func Dump(cmd *Command) ([]string, error) {
 	hex := cmd.IntVals[0] == 1
@@ -308,6 +653,46 @@ func ResetFormFields(cmd *Command) ([]string, error) {
 	return nil, api.ResetFormFieldsFile(*cmd.InFile, *cmd.OutFile, cmd.StringVals, cmd.Conf)
 }
 
+// ListFormDefaultResourceIssues returns a list of fonts of field default appearances of inFile missing in the form's default resources.
+func ListFormDefaultResourceIssues(cmd *Command) ([]string, error) {
+	return api.FormDefaultResourceIssuesFile(*cmd.InFile, cmd.Conf)
+}
+
+// RepairFormDefaultResources adds fonts of field default appearances of inFile missing in the form's default resources and writes the result to outFile.
+func RepairFormDefaultResources(cmd *Command) ([]string, error) {
+	return api.RepairFormDefaultResourcesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// RegenerateAppearances regenerates the appearances of all form fields of inFile and writes the result to outFile.
+func RegenerateAppearances(cmd *Command) ([]string, error) {
+	return api.RegenerateAppearancesFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// ListCalculationOrder returns the ids of all fields of inFile in calculation order.
+func ListCalculationOrder(cmd *Command) ([]string, error) {
+	return api.CalculationOrderFile(*cmd.InFile, cmd.Conf)
+}
+
+// SetCalculationOrder sets the calculation order of inFile's form and writes the result to outFile.
+func SetCalculationOrder(cmd *Command) ([]string, error) {
+	return nil, api.SetCalculationOrderFile(*cmd.InFile, *cmd.OutFile, cmd.StringVals, cmd.Conf)
+}
+
+// ListFieldActions returns a list of format, calculate and validate actions of the form fields of inFile.
+func ListFieldActions(cmd *Command) ([]string, error) {
+	return api.FieldActionsFile(*cmd.InFile, cmd.Conf)
+}
+
+// FlattenForm exports inFile's form data as outFileJSON, flattens the form and writes the result to outFile.
+func FlattenForm(cmd *Command) ([]string, error) {
+	return api.FlattenFormFile(*cmd.InFile, *cmd.OutFile, *cmd.OutFileJSON, cmd.Conf)
+}
+
+// ExportFieldPositions writes the positions of inFile's form field widgets on their rendered pages to outFileJSON.
+func ExportFieldPositions(cmd *Command) ([]string, error) {
+	return nil, api.ExportFieldPositionsFile(*cmd.InFile, *cmd.OutFileJSON, cmd.IntVals[0], cmd.Conf)
+}
+
 // ExportFormFields returns a representation of inFile's form as outFileJSON.
 func ExportFormFields(cmd *Command) ([]string, error) {
 	return nil, api.ExportFormFile(*cmd.InFile, *cmd.OutFileJSON, cmd.Conf)
@@ -327,3 +712,66 @@ func MultiFillFormFields(cmd *Command) ([]string, error) {
 func Resize(cmd *Command) ([]string, error) {
 	return nil, api.ResizeFile(*cmd.InFile, *cmd.OutFile, cmd.PageSelection, cmd.Resize, cmd.Conf)
 }
+
+// Unsign removes all signatures from inFile and writes the result to outFile.
+func Unsign(cmd *Command) ([]string, error) {
+	return api.UnsignFile(*cmd.InFile, *cmd.OutFile, cmd.Conf)
+}
+
+// AddDSS adds validation material to the Document Security Store of inFile.
+func AddDSS(cmd *Command) ([]string, error) {
+	return nil, api.AddDSSFile(*cmd.InFile, "", cmd.InFiles, false, cmd.Conf)
+}
+
+// ListViewerPreferences returns the viewer preferences of inFile.
+func ListViewerPreferences(cmd *Command) ([]string, error) {
+	return api.ListViewerPreferencesFile(*cmd.InFile, cmd.Conf)
+}
+
+// SetPrintPreferences sets the print related viewer preferences of inFile and writes the result to outFile.
+func SetPrintPreferences(cmd *Command) ([]string, error) {
+	return nil, api.SetPrintPreferencesFile(*cmd.InFile, *cmd.OutFile, cmd.PrintPrefs, cmd.Conf)
+}
+
+// FillTemplate clones a template page for each data record and substitutes its tokens.
+func FillTemplate(cmd *Command) ([]string, error) {
+	return nil, api.FillTemplatePageFile(*cmd.InFile, *cmd.InFileJSON, *cmd.OutFile, cmd.IntVals[0], cmd.Conf)
+}
+
+// TilePage splits a page of inFile into a grid of tiles and writes the result to outFile.
+func TilePage(cmd *Command) ([]string, error) {
+	return nil, api.TilePageFile(*cmd.InFile, *cmd.OutFile, cmd.IntVals[0], cmd.IntVals[1], cmd.IntVals[2], cmd.FloatVals[0], cmd.Conf)
+}
+
+// AddPageNumbers stamps page numbers onto inFile and writes the result to outFile.
+func AddPageNumbers(cmd *Command) ([]string, error) {
+	return nil, api.AddPageNumbersFile(*cmd.InFile, *cmd.OutFile, cmd.PageNumbering, cmd.Conf)
+}
+
+// SetXMPThumbnail sets an image as XMP document thumbnail of inFile and writes the result to outFile.
+func SetXMPThumbnail(cmd *Command) ([]string, error) {
+	return nil, api.SetXMPThumbnailFile(*cmd.InFile, cmd.InFiles[0], *cmd.OutFile, cmd.Conf)
+}
+
+// ListVersionFeatures returns the version gated features used by inFile along with the minimum version required.
+func ListVersionFeatures(cmd *Command) ([]string, error) {
+	vr, err := api.VersionFeaturesFile(*cmd.InFile, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return vr.List(), nil
+}
+
+// PrepareTimeStamp adds a document timestamp placeholder to inFile and returns the digest to be timestamped.
+func PrepareTimeStamp(cmd *Command) ([]string, error) {
+	digest, err := api.PrepareDocTimeStampFile(*cmd.InFile, *cmd.OutFile, 0, cmd.Conf)
+	if err != nil {
+		return nil, err
+	}
+	return []string{fmt.Sprintf("SHA-256 digest of byte range: %x", digest)}, nil
+}
+
+// EmbedTimeStamp embeds a timestamp token into the document timestamp placeholder of inFile.
+func EmbedTimeStamp(cmd *Command) ([]string, error) {
+	return nil, api.EmbedDocTimeStampFile(*cmd.InFile, cmd.StringVals[0], cmd.Conf)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 // Command represents an execution context.
@@ -40,81 +41,157 @@ type Command struct {
 	Rotation       int
 	BoolVal        bool
 	IntVals        []int
+	FloatVals      []float64
 	StringVals     []string
 	StringMap      map[string]string
 	Input          io.ReadSeeker
 	Inputs         []io.ReadSeeker
 	Output         io.Writer
 	Box            *model.Box
+	Rects          []types.Rectangle
 	Import         *pdfcpu.Import
+	PageNumbering  *pdfcpu.PageNumbering
 	NUp            *model.NUp
 	PageBoundaries *model.PageBoundaries
 	Resize         *model.Resize
+	PrintPrefs     *model.PrintPreferences
 	Watermark      *model.Watermark
+	MergeSels      []model.MergeSelection
 	Conf           *model.Configuration
 }
 
 var cmdMap = map[model.CommandMode]func(cmd *Command) ([]string, error){
-	model.VALIDATE:                Validate,
-	model.OPTIMIZE:                Optimize,
-	model.SPLIT:                   Split,
-	model.MERGECREATE:             MergeCreate,
-	model.MERGEAPPEND:             MergeAppend,
-	model.EXTRACTIMAGES:           ExtractImages,
-	model.EXTRACTFONTS:            ExtractFonts,
-	model.EXTRACTPAGES:            ExtractPages,
-	model.EXTRACTCONTENT:          ExtractContent,
-	model.EXTRACTMETADATA:         ExtractMetadata,
-	model.TRIM:                    Trim,
-	model.ADDWATERMARKS:           AddWatermarks,
-	model.REMOVEWATERMARKS:        RemoveWatermarks,
-	model.LISTATTACHMENTS:         processAttachments,
-	model.ADDATTACHMENTS:          processAttachments,
-	model.ADDATTACHMENTSPORTFOLIO: processAttachments,
-	model.REMOVEATTACHMENTS:       processAttachments,
-	model.EXTRACTATTACHMENTS:      processAttachments,
-	model.ENCRYPT:                 processEncryption,
-	model.DECRYPT:                 processEncryption,
-	model.CHANGEUPW:               processEncryption,
-	model.CHANGEOPW:               processEncryption,
-	model.LISTPERMISSIONS:         processPermissions,
-	model.SETPERMISSIONS:          processPermissions,
-	model.IMPORTIMAGES:            ImportImages,
-	model.INSERTPAGESBEFORE:       processPages,
-	model.INSERTPAGESAFTER:        processPages,
-	model.REMOVEPAGES:             processPages,
-	model.ROTATE:                  Rotate,
-	model.NUP:                     NUp,
-	model.BOOKLET:                 Booklet,
-	model.INFO:                    Info,
-	model.CHEATSHEETSFONTS:        CreateCheatSheetsFonts,
-	model.INSTALLFONTS:            InstallFonts,
-	model.LISTFONTS:               ListFonts,
-	model.LISTKEYWORDS:            processKeywords,
-	model.ADDKEYWORDS:             processKeywords,
-	model.REMOVEKEYWORDS:          processKeywords,
-	model.LISTPROPERTIES:          processProperties,
-	model.ADDPROPERTIES:           processProperties,
-	model.REMOVEPROPERTIES:        processProperties,
-	model.COLLECT:                 Collect,
-	model.LISTBOXES:               processPageBoundaries,
-	model.ADDBOXES:                processPageBoundaries,
-	model.REMOVEBOXES:             processPageBoundaries,
-	model.CROP:                    processPageBoundaries,
-	model.LISTANNOTATIONS:         processPageAnnotations,
-	model.REMOVEANNOTATIONS:       processPageAnnotations,
-	model.LISTIMAGES:              processImages,
-	model.DUMP:                    Dump,
-	model.CREATE:                  Create,
-	model.LISTFORMFIELDS:          processForm,
-	model.REMOVEFORMFIELDS:        processForm,
-	model.LOCKFORMFIELDS:          processForm,
-	model.UNLOCKFORMFIELDS:        processForm,
-	model.RESETFORMFIELDS:         processForm,
-	model.EXPORTFORMFIELDS:        processForm,
-	model.FILLFORMFIELDS:          processForm,
-	model.MULTIFILLFORMFIELDS:     processForm,
-	model.RESIZE:                  Resize,
+	model.VALIDATE:                   Validate,
+	model.OPTIMIZE:                   Optimize,
+	model.SPLIT:                      Split,
+	model.MERGECREATE:                MergeCreate,
+	model.MERGEAPPEND:                MergeAppend,
+	model.EXTRACTIMAGES:              ExtractImages,
+	model.EXTRACTFONTS:               ExtractFonts,
+	model.EXTRACTPAGES:               ExtractPages,
+	model.EXTRACTCONTENT:             ExtractContent,
+	model.EXTRACTMETADATA:            ExtractMetadata,
+	model.TRIM:                       Trim,
+	model.ADDWATERMARKS:              AddWatermarks,
+	model.REMOVEWATERMARKS:           RemoveWatermarks,
+	model.LISTATTACHMENTS:            processAttachments,
+	model.ADDATTACHMENTS:             processAttachments,
+	model.ADDATTACHMENTSPORTFOLIO:    processAttachments,
+	model.REMOVEATTACHMENTS:          processAttachments,
+	model.EXTRACTATTACHMENTS:         processAttachments,
+	model.ENCRYPT:                    processEncryption,
+	model.DECRYPT:                    processEncryption,
+	model.CHANGEUPW:                  processEncryption,
+	model.CHANGEOPW:                  processEncryption,
+	model.LISTPERMISSIONS:            processPermissions,
+	model.SETPERMISSIONS:             processPermissions,
+	model.CLEARRESTRICTIONS:          processPermissions,
+	model.IMPORTIMAGES:               ImportImages,
+	model.INSERTPAGESBEFORE:          processPages,
+	model.INSERTPAGESAFTER:           processPages,
+	model.REMOVEPAGES:                processPages,
+	model.ROTATE:                     Rotate,
+	model.NUP:                        NUp,
+	model.BOOKLET:                    Booklet,
+	model.INFO:                       Info,
+	model.CHEATSHEETSFONTS:           CreateCheatSheetsFonts,
+	model.INSTALLFONTS:               InstallFonts,
+	model.LISTFONTS:                  ListFonts,
+	model.LISTKEYWORDS:               processKeywords,
+	model.ADDKEYWORDS:                processKeywords,
+	model.REMOVEKEYWORDS:             processKeywords,
+	model.LISTPROPERTIES:             processProperties,
+	model.ADDPROPERTIES:              processProperties,
+	model.REMOVEPROPERTIES:           processProperties,
+	model.COLLECT:                    Collect,
+	model.LISTBOXES:                  processPageBoundaries,
+	model.ADDBOXES:                   processPageBoundaries,
+	model.REMOVEBOXES:                processPageBoundaries,
+	model.CROP:                       processPageBoundaries,
+	model.LISTANNOTATIONS:            processPageAnnotations,
+	model.REMOVEANNOTATIONS:          processPageAnnotations,
+	model.LISTIMAGES:                 processImages,
+	model.DUMP:                       Dump,
+	model.CREATE:                     Create,
+	model.LISTFORMFIELDS:             processForm,
+	model.REMOVEFORMFIELDS:           processForm,
+	model.LOCKFORMFIELDS:             processForm,
+	model.UNLOCKFORMFIELDS:           processForm,
+	model.RESETFORMFIELDS:            processForm,
+	model.EXPORTFORMFIELDS:           processForm,
+	model.FILLFORMFIELDS:             processForm,
+	model.MULTIFILLFORMFIELDS:        processForm,
+	model.RESIZE:                     Resize,
+	model.UNSIGN:                     Unsign,
+	model.EMBEDFONTS:                 EmbedFonts,
+	model.LISTMISSINGGLYPHS:          ListMissingGlyphs,
+	model.REPAIRTOUNICODE:            RepairToUnicode,
+	model.MERGESELECTED:              MergeSelected,
+	model.ASSEMBLE:                   Assemble,
+	model.REPAIRPAGES:                RepairPages,
+	model.RECOLORWATERMARKS:          RecolorWatermarks,
+	model.MEASURETEXT:                MeasureText,
+	model.EXTRACTEPS:                 ExtractEPS,
+	model.NORMALIZEROTATION:          NormalizeRotation,
+	model.HASHPAGES:                  HashPages,
+	model.PREPARETIMESTAMP:           PrepareTimeStamp,
+	model.EMBEDTIMESTAMP:             EmbedTimeStamp,
+	model.ADDDSS:                     AddDSS,
+	model.LISTDEGENERATEPAGES:        ListDegeneratePages,
+	model.REMOVEDEGENERATEPAGES:      RemoveDegeneratePages,
+	model.LISTOVERSIZEDPAGES:         ListOversizedPages,
+	model.CLAMPPAGES:                 ClampPages,
+	model.LISTVIEWERPREFERENCES:      ListViewerPreferences,
+	model.SETPRINTPREFERENCES:        SetPrintPreferences,
+	model.FILLTEMPLATE:               FillTemplate,
+	model.LISTDOCUMENTFONTS:          ListDocumentFonts,
+	model.CONVERTTYPE3FONTS:          ConvertType3Fonts,
+	model.LISTSPLITCONTENT:           ListSplitContent,
+	model.RESPLITCONTENT:             ResplitContent,
+	model.LISTBROKENDESTINATIONS:     ListBrokenDestinations,
+	model.FIXBROKENDESTINATIONS:      FixBrokenDestinations,
+	model.EXTRACTICCPROFILES:         ExtractICCProfiles,
+	model.LISTREADINGORDERISSUES:     ListReadingOrderIssues,
+	model.AUTOTAG:                    AutoTag,
+	model.EXTRACTPARAGRAPHS:          ExtractParagraphs,
+	model.REDACT:                     Redact,
+	model.PIXELATE:                   Pixelate,
+	model.SETSTRUCTLANG:              SetStructLang,
+	model.LISTPAGEROTATIONS:          ListPageRotations,
+	model.FIXPAGEROTATIONS:           NormalizeRotateInheritance,
+	model.GARBAGECOLLECT:             GarbageCollect,
+	model.LISTDANGLINGREFS:           ListDanglingReferences,
+	model.NULLDANGLINGREFS:           NullDanglingReferences,
+	model.TRUNCATEPAGES:              TruncatePages,
+	model.COUNTPAGES:                 CountPages,
+	model.ESTIMATECOST:               EstimateCost,
+	model.SHARERESOURCES:             ShareResources,
+	model.LISTOPERATORS:              ListNonStandardOperators,
+	model.LISTARTIFACTCANDIDATES:     ListArtifactCandidates,
+	model.TAGARTIFACTS:               TagArtifacts,
+	model.LISTFORMDRISSUES:           processForm,
+	model.REPAIRFORMDR:               processForm,
+	model.REGENERATEAPPEARANCES:      processForm,
+	model.LISTCALCORDER:              processForm,
+	model.SETCALCORDER:               processForm,
+	model.LISTFIELDACTIONS:           processForm,
+	model.FLATTENFORM:                processForm,
+	model.STRIPSTRUCTURE:             StripStructure,
+	model.CHECKIMAGES:                processImages,
+	model.CHECKANNOTATIONS:           processPageAnnotations,
+	model.CLAMPANNOTATIONS:           processPageAnnotations,
+	model.EXPORTFIELDPOSITIONS:       processForm,
+	model.CHECKSCANNED:               CheckScanned,
+	model.TILEPAGE:                   TilePage,
+	model.LISTRESOLUTIONS:            processImages,
+	model.SETXMPTHUMBNAIL:            SetXMPThumbnail,
+	model.LISTVERSIONFEATURES:        ListVersionFeatures,
+	model.ADDPAGENUMBERS:             AddPageNumbers,
+	model.LISTDUPLICATEANNOTATIONS:   processPageAnnotations,
+	model.REMOVEDUPLICATEANNOTATIONS: processPageAnnotations,
+	model.INLINENAMEDDESTINATIONS:    InlineNamedDestinations,
+	model.NAMEEXPLICITDESTINATIONS:   NameExplicitDestinations,
+	model.EXTRACTBUNDLE:              ExtractBundle,
 }
 
 // ValidateCommand creates a new command to validate a file.
@@ -142,6 +219,19 @@ func OptimizeCommand(inFile, outFile string, conf *model.Configuration) *Command
 		Conf:    conf}
 }
 
+// GarbageCollectCommand creates a new command to free unreferenced objects of a file.
+func GarbageCollectCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.GARBAGECOLLECT
+	return &Command{
+		Mode:    model.GARBAGECOLLECT,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
 // SplitCommand creates a new command to split a file into single page files.
 func SplitCommand(inFile, dirNameOut string, span int, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -184,6 +274,34 @@ func MergeAppendCommand(inFiles []string, outFile string, conf *model.Configurat
 		Conf:    conf}
 }
 
+// MergeSelectedCommand creates a new command to merge selected pages of files.
+// Outfile will be created. An existing outFile will be overwritten.
+func MergeSelectedCommand(sels []model.MergeSelection, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.MERGESELECTED
+	return &Command{
+		Mode:      model.MERGESELECTED,
+		MergeSels: sels,
+		OutFile:   &outFile,
+		Conf:      conf}
+}
+
+// AssembleCommand creates a new command to assemble a document as described by manifestFile.
+// Outfile will be created. An existing outFile will be overwritten.
+func AssembleCommand(manifestFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ASSEMBLE
+	return &Command{
+		Mode:    model.ASSEMBLE,
+		InFile:  &manifestFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
 // ExtractImagesCommand creates a new command to extract embedded images.
 // (experimental)
 func ExtractImagesCommand(inFile string, outDir string, pageSelection []string, conf *model.Configuration) *Command {
@@ -242,6 +360,47 @@ func ExtractContentCommand(inFile string, outDir string, pageSelection []string,
 		Conf:          conf}
 }
 
+// ExtractEPSCommand creates a new command to extract pages as EPS files.
+func ExtractEPSCommand(inFile string, outDir string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTEPS
+	return &Command{
+		Mode:          model.EXTRACTEPS,
+		InFile:        &inFile,
+		OutDir:        &outDir,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ExtractBundleCommand creates a new command to export inFile as structured bundle into outDir.
+func ExtractBundleCommand(inFile string, outDir string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTBUNDLE
+	return &Command{
+		Mode:   model.EXTRACTBUNDLE,
+		InFile: &inFile,
+		OutDir: &outDir,
+		Conf:   conf}
+}
+
+// ExtractParagraphsCommand creates a new command to extract the paragraph structure of pages as JSON.
+func ExtractParagraphsCommand(inFile string, outDir string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTPARAGRAPHS
+	return &Command{
+		Mode:          model.EXTRACTPARAGRAPHS,
+		InFile:        &inFile,
+		OutDir:        &outDir,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
 // ExtractMetadataCommand creates a new command to extract metadata streams.
 func ExtractMetadataCommand(inFile string, outDir string, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -255,6 +414,19 @@ func ExtractMetadataCommand(inFile string, outDir string, conf *model.Configurat
 		Conf:   conf}
 }
 
+// ExtractICCProfilesCommand creates a new command to extract embedded ICC profiles.
+func ExtractICCProfilesCommand(inFile string, outDir string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXTRACTICCPROFILES
+	return &Command{
+		Mode:   model.EXTRACTICCPROFILES,
+		InFile: &inFile,
+		OutDir: &outDir,
+		Conf:   conf}
+}
+
 // TrimCommand creates a new command to trim the pages of a file.
 func TrimCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -418,6 +590,19 @@ func SetPermissionsCommand(inFile, outFile string, conf *model.Configuration) *C
 		Conf:    conf}
 }
 
+// ClearRestrictionsCommand creates a new command to remove the encryption and user access restrictions of a file.
+func ClearRestrictionsCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CLEARRESTRICTIONS
+	return &Command{
+		Mode:    model.CLEARRESTRICTIONS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
 // AddWatermarksCommand creates a new command to add Watermarks to a file.
 func AddWatermarksCommand(inFile, outFile string, pageSelection []string, wm *model.Watermark, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -447,6 +632,21 @@ func RemoveWatermarksCommand(inFile, outFile string, pageSelection []string, con
 		Conf:          conf}
 }
 
+// RecolorWatermarksCommand creates a new command to recolor watermarks of a file using a gray level and opacity.
+func RecolorWatermarksCommand(inFile, outFile string, pageSelection []string, gray, opacity float64, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.RECOLORWATERMARKS
+	return &Command{
+		Mode:          model.RECOLORWATERMARKS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		FloatVals:     []float64{gray, opacity},
+		Conf:          conf}
+}
+
 // ImportImagesCommand creates a new command to import images.
 func ImportImagesCommand(imageFiles []string, outFile string, imp *pdfcpu.Import, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -493,243 +693,735 @@ func RemovePagesCommand(inFile, outFile string, pageSelection []string, conf *mo
 		Conf:          conf}
 }
 
-// RotateCommand creates a new command to rotate pages.
-func RotateCommand(inFile, outFile string, rotation int, pageSelection []string, conf *model.Configuration) *Command {
+// RepairPagesCommand creates a new command to fix pages missing required entries.
+func RepairPagesCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.ROTATE
+	conf.Cmd = model.REPAIRPAGES
 	return &Command{
-		Mode:          model.ROTATE,
-		InFile:        &inFile,
-		OutFile:       &outFile,
-		PageSelection: pageSelection,
-		Rotation:      rotation,
-		Conf:          conf}
+		Mode:    model.REPAIRPAGES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// NUpCommand creates a new command to render PDFs or image files in n-up fashion.
-func NUpCommand(inFiles []string, outFile string, pageSelection []string, nUp *model.NUp, conf *model.Configuration) *Command {
+// TruncatePagesCommand creates a new command to remove all pages following page maxPages.
+func TruncatePagesCommand(inFile, outFile string, maxPages int, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.NUP
+	conf.Cmd = model.TRUNCATEPAGES
 	return &Command{
-		Mode:          model.NUP,
-		InFiles:       inFiles,
-		OutFile:       &outFile,
-		PageSelection: pageSelection,
-		NUp:           nUp,
-		Conf:          conf}
+		Mode:    model.TRUNCATEPAGES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		IntVals: []int{maxPages},
+		Conf:    conf}
 }
 
-// BookletCommand creates a new command to render PDFs or image files in booklet fashion.
-func BookletCommand(inFiles []string, outFile string, pageSelection []string, nup *model.NUp, conf *model.Configuration) *Command {
+// CountPagesCommand creates a new command to cheaply evaluate the page count.
+func CountPagesCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.BOOKLET
+	conf.Cmd = model.COUNTPAGES
 	return &Command{
-		Mode:          model.BOOKLET,
-		InFiles:       inFiles,
-		OutFile:       &outFile,
-		PageSelection: pageSelection,
-		NUp:           nup,
-		Conf:          conf}
+		Mode:   model.COUNTPAGES,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// InfoCommand creates a new command to output information about inFile.
-func InfoCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+// ShareResourcesCommand creates a new command to share equal resources among pages.
+func ShareResourcesCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.INFO
+	conf.Cmd = model.SHARERESOURCES
 	return &Command{
-		Mode:          model.INFO,
-		InFile:        &inFile,
-		PageSelection: pageSelection,
-		Conf:          conf}
+		Mode:    model.SHARERESOURCES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// ListFontsCommand returns a list of supported fonts.
-func ListFontsCommand(conf *model.Configuration) *Command {
+// ListNonStandardOperatorsCommand creates a new command to list deprecated or non standard content stream operators.
+func ListNonStandardOperatorsCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.LISTFONTS
+	conf.Cmd = model.LISTOPERATORS
 	return &Command{
-		Mode: model.LISTFONTS,
-		Conf: conf}
+		Mode:          model.LISTOPERATORS,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
 }
 
-// InstallFontsCommand installs true type fonts for embedding.
-func InstallFontsCommand(fontFiles []string, conf *model.Configuration) *Command {
+// CheckScannedCommand creates a new command to detect scans wrapped into PDF without OCR.
+func CheckScannedCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.INSTALLFONTS
+	conf.Cmd = model.CHECKSCANNED
 	return &Command{
-		Mode:    model.INSTALLFONTS,
-		InFiles: fontFiles,
-		Conf:    conf}
+		Mode:   model.CHECKSCANNED,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// CreateCheatSheetsFontsCommand creates single page PDF cheat sheets in current dir.
-func CreateCheatSheetsFontsCommand(fontFiles []string, conf *model.Configuration) *Command {
+// HashPagesCommand creates a new command to compute content hashes for selected pages.
+func HashPagesCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.CHEATSHEETSFONTS
+	conf.Cmd = model.HASHPAGES
 	return &Command{
-		Mode:    model.CHEATSHEETSFONTS,
-		InFiles: fontFiles,
-		Conf:    conf}
+		Mode:          model.HASHPAGES,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
 }
 
-// ListKeywordsCommand create a new command to list keywords.
-func ListKeywordsCommand(inFile string, conf *model.Configuration) *Command {
+// ListDegeneratePagesCommand creates a new command to list pages with degenerate media boxes or without content.
+func ListDegeneratePagesCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.LISTKEYWORDS
+	conf.Cmd = model.LISTDEGENERATEPAGES
 	return &Command{
-		Mode:   model.LISTKEYWORDS,
+		Mode:   model.LISTDEGENERATEPAGES,
 		InFile: &inFile,
 		Conf:   conf}
 }
 
-// AddKeywordsCommand creates a new command to add keywords.
-func AddKeywordsCommand(inFile, outFile string, keywords []string, conf *model.Configuration) *Command {
+// RemoveDegeneratePagesCommand creates a new command to remove pages with degenerate media boxes or without content.
+func RemoveDegeneratePagesCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.ADDKEYWORDS
+	conf.Cmd = model.REMOVEDEGENERATEPAGES
 	return &Command{
-		Mode:       model.ADDKEYWORDS,
-		InFile:     &inFile,
-		OutFile:    &outFile,
-		StringVals: keywords,
-		Conf:       conf}
+		Mode:    model.REMOVEDEGENERATEPAGES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// RemoveKeywordsCommand creates a new command to remove keywords.
-func RemoveKeywordsCommand(inFile, outFile string, keywords []string, conf *model.Configuration) *Command {
+// ListOversizedPagesCommand creates a new command to list pages exceeding the maximum page dimensions.
+func ListOversizedPagesCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.REMOVEKEYWORDS
+	conf.Cmd = model.LISTOVERSIZEDPAGES
 	return &Command{
-		Mode:       model.REMOVEKEYWORDS,
-		InFile:     &inFile,
-		OutFile:    &outFile,
-		StringVals: keywords,
-		Conf:       conf}
+		Mode:   model.LISTOVERSIZEDPAGES,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// ListPropertiesCommand creates a new command to list document properties.
-func ListPropertiesCommand(inFile string, conf *model.Configuration) *Command {
+// ClampPagesCommand creates a new command to scale down pages exceeding the maximum page dimensions.
+func ClampPagesCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.LISTPROPERTIES
+	conf.Cmd = model.CLAMPPAGES
 	return &Command{
-		Mode:   model.LISTPROPERTIES,
-		InFile: &inFile,
-		Conf:   conf}
+		Mode:    model.CLAMPPAGES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// AddPropertiesCommand creates a new command to add document properties.
-func AddPropertiesCommand(inFile, outFile string, properties map[string]string, conf *model.Configuration) *Command {
+// ListPageRotationsCommand creates a new command to list the rotation in effect for each page.
+func ListPageRotationsCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.ADDPROPERTIES
+	conf.Cmd = model.LISTPAGEROTATIONS
 	return &Command{
-		Mode:      model.ADDPROPERTIES,
-		InFile:    &inFile,
-		OutFile:   &outFile,
-		StringMap: properties,
-		Conf:      conf}
+		Mode:   model.LISTPAGEROTATIONS,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// RemovePropertiesCommand creates a new command to remove document properties.
-func RemovePropertiesCommand(inFile, outFile string, propKeys []string, conf *model.Configuration) *Command {
+// NormalizeRotateInheritanceCommand creates a new command to set the rotation in effect on each page.
+func NormalizeRotateInheritanceCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.REMOVEPROPERTIES
+	conf.Cmd = model.FIXPAGEROTATIONS
 	return &Command{
-		Mode:       model.REMOVEPROPERTIES,
-		InFile:     &inFile,
-		OutFile:    &outFile,
-		StringVals: propKeys,
-		Conf:       conf}
+		Mode:    model.FIXPAGEROTATIONS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// CollectCommand creates a new command to create a custom PDF page sequence.
-func CollectCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
+// ListSplitContentCommand creates a new command to list content stream parts divided within a lexical token.
+func ListSplitContentCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.COLLECT
+	conf.Cmd = model.LISTSPLITCONTENT
 	return &Command{
-		Mode:          model.COLLECT,
-		InFile:        &inFile,
-		OutFile:       &outFile,
-		PageSelection: pageSelection,
-		Conf:          conf}
+		Mode:   model.LISTSPLITCONTENT,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// ListBoxesCommand creates a new command to list page boundaries for selected pages.
-func ListBoxesCommand(inFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+// ResplitContentCommand creates a new command to move content stream part boundaries to token boundaries.
+func ResplitContentCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.LISTBOXES
+	conf.Cmd = model.RESPLITCONTENT
 	return &Command{
-		Mode:           model.LISTBOXES,
-		InFile:         &inFile,
-		PageSelection:  pageSelection,
-		PageBoundaries: pb,
-		Conf:           conf}
+		Mode:    model.RESPLITCONTENT,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
 }
 
-// AddBoxesCommand creates a new command to add page boundaries for selected pages.
-func AddBoxesCommand(inFile, outFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+// ListBrokenDestinationsCommand creates a new command to list destinations pointing to nonexistent pages.
+func ListBrokenDestinationsCommand(inFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.ADDBOXES
+	conf.Cmd = model.LISTBROKENDESTINATIONS
 	return &Command{
-		Mode:           model.ADDBOXES,
-		InFile:         &inFile,
-		OutFile:        &outFile,
-		PageSelection:  pageSelection,
-		PageBoundaries: pb,
-		Conf:           conf}
+		Mode:   model.LISTBROKENDESTINATIONS,
+		InFile: &inFile,
+		Conf:   conf}
 }
 
-// RemoveBoxesCommand creates a new command to remove page boundaries for selected pages.
-func RemoveBoxesCommand(inFile, outFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+// FixBrokenDestinationsCommand creates a new command to remove or redirect destinations pointing to nonexistent pages.
+func FixBrokenDestinationsCommand(inFile, outFile string, remove bool, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.REMOVEBOXES
+	conf.Cmd = model.FIXBROKENDESTINATIONS
 	return &Command{
-		Mode:           model.REMOVEBOXES,
-		InFile:         &inFile,
-		OutFile:        &outFile,
-		PageSelection:  pageSelection,
-		PageBoundaries: pb,
-		Conf:           conf}
+		Mode:    model.FIXBROKENDESTINATIONS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		BoolVal: remove,
+		Conf:    conf}
 }
 
-// CropCommand creates a new command to apply a cropBox to selected pages.
-func CropCommand(inFile, outFile string, pageSelection []string, box *model.Box, conf *model.Configuration) *Command {
+// InlineNamedDestinationsCommand creates a new command to replace named destinations by explicit destinations.
+func InlineNamedDestinationsCommand(inFile, outFile string, conf *model.Configuration) *Command {
 	if conf == nil {
 		conf = model.NewDefaultConfiguration()
 	}
-	conf.Cmd = model.CROP
+	conf.Cmd = model.INLINENAMEDDESTINATIONS
+	return &Command{
+		Mode:    model.INLINENAMEDDESTINATIONS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// NameExplicitDestinationsCommand creates a new command to replace explicit destinations by named destinations.
+func NameExplicitDestinationsCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NAMEEXPLICITDESTINATIONS
+	return &Command{
+		Mode:    model.NAMEEXPLICITDESTINATIONS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// ListReadingOrderIssuesCommand creates a new command to list struct elements out of reading order.
+func ListReadingOrderIssuesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTREADINGORDERISSUES
+	return &Command{
+		Mode:   model.LISTREADINGORDERISSUES,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// AutoTagCommand creates a new command to generate a basic structure tree for an untagged document.
+func AutoTagCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.AUTOTAG
+	return &Command{
+		Mode:    model.AUTOTAG,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// StripStructureCommand creates a new command to remove the structure tree of a tagged document.
+func StripStructureCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.STRIPSTRUCTURE
+	return &Command{
+		Mode:    model.STRIPSTRUCTURE,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// RedactCommand creates a new command to redact areas of pages listed in a JSON manifest.
+func RedactCommand(inFile, inFileJSON, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REDACT
+	return &Command{
+		Mode:       model.REDACT,
+		InFile:     &inFile,
+		InFileJSON: &inFileJSON,
+		OutFile:    &outFile,
+		Conf:       conf}
+}
+
+// PixelateCommand creates a new command to pixelate regions of an image.
+func PixelateCommand(inFile, outFile string, pageNr int, id string, rects []types.Rectangle, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.PIXELATE
+	return &Command{
+		Mode:       model.PIXELATE,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		IntVals:    []int{pageNr},
+		StringVals: []string{id},
+		Rects:      rects,
+		Conf:       conf}
+}
+
+// SetStructLangCommand creates a new command to set the language of struct elements.
+func SetStructLangCommand(inFile, outFile string, sel pdfcpu.StructElementSelection, lang string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SETSTRUCTLANG
+	cmd := &Command{
+		Mode:       model.SETSTRUCTLANG,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		IntVals:    []int{sel.ObjNr, sel.PageNr},
+		StringVals: []string{lang},
+		Conf:       conf}
+	if sel.Region != nil {
+		cmd.Rects = []types.Rectangle{*sel.Region}
+	}
+	return cmd
+}
+
+// ListArtifactCandidatesCommand creates a new command to list untagged content of selected pages likely to be decorative.
+func ListArtifactCandidatesCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTARTIFACTCANDIDATES
+	return &Command{
+		Mode:          model.LISTARTIFACTCANDIDATES,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// TagArtifactsCommand creates a new command to wrap content operation ranges of a page into /Artifact marked content.
+func TagArtifactsCommand(inFile, outFile string, pageNr int, rr []pdfcpu.ArtifactRange, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.TAGARTIFACTS
+	cmd := &Command{
+		Mode:    model.TAGARTIFACTS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		IntVals: []int{pageNr},
+		Conf:    conf}
+	for _, r := range rr {
+		cmd.IntVals = append(cmd.IntVals, r.From, r.Thru)
+	}
+	return cmd
+}
+
+// ListDanglingReferencesCommand creates a new command to list references to missing or free objects.
+func ListDanglingReferencesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDANGLINGREFS
+	return &Command{
+		Mode:   model.LISTDANGLINGREFS,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// NullDanglingReferencesCommand creates a new command to replace references to missing or free objects with null.
+func NullDanglingReferencesCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NULLDANGLINGREFS
+	return &Command{
+		Mode:    model.NULLDANGLINGREFS,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// EstimateCostCommand creates a new command to report metrics predictive of the processing cost.
+func EstimateCostCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ESTIMATECOST
+	return &Command{
+		Mode:   model.ESTIMATECOST,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// RotateCommand creates a new command to rotate pages.
+func RotateCommand(inFile, outFile string, rotation int, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ROTATE
+	return &Command{
+		Mode:          model.ROTATE,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Rotation:      rotation,
+		Conf:          conf}
+}
+
+// NormalizeRotationCommand creates a new command to bake the rotation of selected pages into their content.
+func NormalizeRotationCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NORMALIZEROTATION
+	return &Command{
+		Mode:          model.NORMALIZEROTATION,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// NUpCommand creates a new command to render PDFs or image files in n-up fashion.
+func NUpCommand(inFiles []string, outFile string, pageSelection []string, nUp *model.NUp, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.NUP
+	return &Command{
+		Mode:          model.NUP,
+		InFiles:       inFiles,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		NUp:           nUp,
+		Conf:          conf}
+}
+
+// BookletCommand creates a new command to render PDFs or image files in booklet fashion.
+func BookletCommand(inFiles []string, outFile string, pageSelection []string, nup *model.NUp, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.BOOKLET
+	return &Command{
+		Mode:          model.BOOKLET,
+		InFiles:       inFiles,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		NUp:           nup,
+		Conf:          conf}
+}
+
+// InfoCommand creates a new command to output information about inFile.
+func InfoCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.INFO
+	return &Command{
+		Mode:          model.INFO,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ListFontsCommand returns a list of supported fonts.
+func ListFontsCommand(conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTFONTS
+	return &Command{
+		Mode: model.LISTFONTS,
+		Conf: conf}
+}
+
+// ListDocumentFontsCommand creates a new command to list the fonts of inFile.
+func ListDocumentFontsCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDOCUMENTFONTS
+	return &Command{
+		Mode:   model.LISTDOCUMENTFONTS,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// MeasureTextCommand creates a new command to measure text rendered using a font and font size.
+// If box holds a width and a height the largest font size fitting the box gets reported too.
+func MeasureTextCommand(fontName string, fontSize int, text string, box []float64, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.MEASURETEXT
+	return &Command{
+		Mode:       model.MEASURETEXT,
+		StringVals: []string{fontName, text},
+		IntVals:    []int{fontSize},
+		FloatVals:  box,
+		Conf:       conf}
+}
+
+// InstallFontsCommand installs true type fonts for embedding.
+func InstallFontsCommand(fontFiles []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.INSTALLFONTS
+	return &Command{
+		Mode:    model.INSTALLFONTS,
+		InFiles: fontFiles,
+		Conf:    conf}
+}
+
+// EmbedFontsCommand creates a new command to embed font files into matching non embedded fonts.
+func EmbedFontsCommand(inFile, outFile string, fontFiles map[string]string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EMBEDFONTS
+	return &Command{
+		Mode:      model.EMBEDFONTS,
+		InFile:    &inFile,
+		OutFile:   &outFile,
+		StringMap: fontFiles,
+		Conf:      conf}
+}
+
+// ListMissingGlyphsCommand creates a new command to list glyphs missing in embedded fonts for selected pages.
+func ListMissingGlyphsCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTMISSINGGLYPHS
+	return &Command{
+		Mode:          model.LISTMISSINGGLYPHS,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// RepairToUnicodeCommand creates a new command to generate missing or broken ToUnicode CMaps.
+func RepairToUnicodeCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REPAIRTOUNICODE
+	return &Command{
+		Mode:    model.REPAIRTOUNICODE,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// ConvertType3FontsCommand creates a new command to convert text shown using Type3 fonts to vector outlines.
+func ConvertType3FontsCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CONVERTTYPE3FONTS
+	return &Command{
+		Mode:          model.CONVERTTYPE3FONTS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// CreateCheatSheetsFontsCommand creates single page PDF cheat sheets in current dir.
+func CreateCheatSheetsFontsCommand(fontFiles []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHEATSHEETSFONTS
+	return &Command{
+		Mode:    model.CHEATSHEETSFONTS,
+		InFiles: fontFiles,
+		Conf:    conf}
+}
+
+// ListKeywordsCommand create a new command to list keywords.
+func ListKeywordsCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTKEYWORDS
+	return &Command{
+		Mode:   model.LISTKEYWORDS,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// AddKeywordsCommand creates a new command to add keywords.
+func AddKeywordsCommand(inFile, outFile string, keywords []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDKEYWORDS
+	return &Command{
+		Mode:       model.ADDKEYWORDS,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		StringVals: keywords,
+		Conf:       conf}
+}
+
+// RemoveKeywordsCommand creates a new command to remove keywords.
+func RemoveKeywordsCommand(inFile, outFile string, keywords []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEKEYWORDS
+	return &Command{
+		Mode:       model.REMOVEKEYWORDS,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		StringVals: keywords,
+		Conf:       conf}
+}
+
+// ListPropertiesCommand creates a new command to list document properties.
+func ListPropertiesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTPROPERTIES
+	return &Command{
+		Mode:   model.LISTPROPERTIES,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// AddPropertiesCommand creates a new command to add document properties.
+func AddPropertiesCommand(inFile, outFile string, properties map[string]string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDPROPERTIES
+	return &Command{
+		Mode:      model.ADDPROPERTIES,
+		InFile:    &inFile,
+		OutFile:   &outFile,
+		StringMap: properties,
+		Conf:      conf}
+}
+
+// RemovePropertiesCommand creates a new command to remove document properties.
+func RemovePropertiesCommand(inFile, outFile string, propKeys []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEPROPERTIES
+	return &Command{
+		Mode:       model.REMOVEPROPERTIES,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		StringVals: propKeys,
+		Conf:       conf}
+}
+
+// CollectCommand creates a new command to create a custom PDF page sequence.
+func CollectCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.COLLECT
+	return &Command{
+		Mode:          model.COLLECT,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ListBoxesCommand creates a new command to list page boundaries for selected pages.
+func ListBoxesCommand(inFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTBOXES
+	return &Command{
+		Mode:           model.LISTBOXES,
+		InFile:         &inFile,
+		PageSelection:  pageSelection,
+		PageBoundaries: pb,
+		Conf:           conf}
+}
+
+// AddBoxesCommand creates a new command to add page boundaries for selected pages.
+func AddBoxesCommand(inFile, outFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDBOXES
+	return &Command{
+		Mode:           model.ADDBOXES,
+		InFile:         &inFile,
+		OutFile:        &outFile,
+		PageSelection:  pageSelection,
+		PageBoundaries: pb,
+		Conf:           conf}
+}
+
+// RemoveBoxesCommand creates a new command to remove page boundaries for selected pages.
+func RemoveBoxesCommand(inFile, outFile string, pageSelection []string, pb *model.PageBoundaries, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEBOXES
+	return &Command{
+		Mode:           model.REMOVEBOXES,
+		InFile:         &inFile,
+		OutFile:        &outFile,
+		PageSelection:  pageSelection,
+		PageBoundaries: pb,
+		Conf:           conf}
+}
+
+// CropCommand creates a new command to apply a cropBox to selected pages.
+func CropCommand(inFile, outFile string, pageSelection []string, box *model.Box, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CROP
 	return &Command{
 		Mode:          model.CROP,
 		InFile:        &inFile,
@@ -768,6 +1460,61 @@ func RemoveAnnotationsCommand(inFile, outFile string, pageSelection []string, id
 		Conf:          conf}
 }
 
+// CheckAnnotationsCommand creates a new command to check the annotations of selected pages for placement issues.
+func CheckAnnotationsCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHECKANNOTATIONS
+	return &Command{
+		Mode:          model.CHECKANNOTATIONS,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ClampAnnotationsCommand creates a new command to clamp annotations of selected pages to the crop box.
+func ClampAnnotationsCommand(inFile, outFile string, pageSelection []string, annotTypes []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CLAMPANNOTATIONS
+	return &Command{
+		Mode:          model.CLAMPANNOTATIONS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		StringVals:    annotTypes,
+		Conf:          conf}
+}
+
+// ListDuplicateAnnotationsCommand creates a new command to list exact duplicate annotations of selected pages.
+func ListDuplicateAnnotationsCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTDUPLICATEANNOTATIONS
+	return &Command{
+		Mode:          model.LISTDUPLICATEANNOTATIONS,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// RemoveDuplicateAnnotationsCommand creates a new command to remove exact duplicate annotations of selected pages.
+func RemoveDuplicateAnnotationsCommand(inFile, outFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REMOVEDUPLICATEANNOTATIONS
+	return &Command{
+		Mode:          model.REMOVEDUPLICATEANNOTATIONS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
 // ListImagesCommand creates a new command to list annotations for selected pages.
 func ListImagesCommand(inFiles []string, pageSelection []string, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -781,6 +1528,33 @@ func ListImagesCommand(inFiles []string, pageSelection []string, conf *model.Con
 		Conf:          conf}
 }
 
+// CheckImagesCommand creates a new command to check the images of selected pages for corrupt image data.
+func CheckImagesCommand(inFile string, pageSelection []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.CHECKIMAGES
+	return &Command{
+		Mode:          model.CHECKIMAGES,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		Conf:          conf}
+}
+
+// ListResolutionsCommand creates a new command to list the effective resolution of the main image of selected pages.
+func ListResolutionsCommand(inFile string, pageSelection []string, minDPI float64, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTRESOLUTIONS
+	return &Command{
+		Mode:          model.LISTRESOLUTIONS,
+		InFile:        &inFile,
+		PageSelection: pageSelection,
+		FloatVals:     []float64{minDPI},
+		Conf:          conf}
+}
+
 // DumpCommand creates a new command to dump objects on stdout.
 func DumpCommand(inFilePDF string, vals []int, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -876,6 +1650,110 @@ func ResetFormCommand(inFile, outFile string, fieldIDs []string, conf *model.Con
 		Conf:       conf}
 }
 
+// ListFormDefaultResourceIssuesCommand creates a new command to list fonts of field default appearances missing in the form's default resources.
+func ListFormDefaultResourceIssuesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTFORMDRISSUES
+	return &Command{
+		Mode:   model.LISTFORMDRISSUES,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// RepairFormDefaultResourcesCommand creates a new command to add fonts of field default appearances missing in the form's default resources.
+func RepairFormDefaultResourcesCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REPAIRFORMDR
+	return &Command{
+		Mode:    model.REPAIRFORMDR,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// RegenerateAppearancesCommand creates a new command to regenerate the appearances of all form fields.
+func RegenerateAppearancesCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.REGENERATEAPPEARANCES
+	return &Command{
+		Mode:    model.REGENERATEAPPEARANCES,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// ListCalculationOrderCommand creates a new command to list the calculation order of a form.
+func ListCalculationOrderCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTCALCORDER
+	return &Command{
+		Mode:   model.LISTCALCORDER,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// SetCalculationOrderCommand creates a new command to set the calculation order of a form.
+func SetCalculationOrderCommand(inFile, outFile string, fieldIDs []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SETCALCORDER
+	return &Command{
+		Mode:       model.SETCALCORDER,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		StringVals: fieldIDs,
+		Conf:       conf}
+}
+
+// ListFieldActionsCommand creates a new command to list the format, calculate and validate actions of form fields.
+func ListFieldActionsCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTFIELDACTIONS
+	return &Command{
+		Mode:   model.LISTFIELDACTIONS,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// FlattenFormCommand creates a new command to flatten a PDF form exporting its data.
+func FlattenFormCommand(inFile, outFile, outFileJSON string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FLATTENFORM
+	return &Command{
+		Mode:        model.FLATTENFORM,
+		InFile:      &inFile,
+		OutFile:     &outFile,
+		OutFileJSON: &outFileJSON,
+		Conf:        conf}
+}
+
+// ExportFieldPositionsCommand creates a new command to export the positions of form field widgets on their rendered pages.
+func ExportFieldPositionsCommand(inFile, outFileJSON string, dpi int, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EXPORTFIELDPOSITIONS
+	return &Command{
+		Mode:        model.EXPORTFIELDPOSITIONS,
+		InFile:      &inFile,
+		OutFileJSON: &outFileJSON,
+		IntVals:     []int{dpi},
+		Conf:        conf}
+}
+
 // ExportFormCommand creates a new command to export a PDF form.
 func ExportFormCommand(inFilePDF, outFileJSON string, conf *model.Configuration) *Command {
 	if conf == nil {
@@ -933,3 +1811,151 @@ func ResizeCommand(inFile, outFile string, pageSelection []string, resize *model
 		Resize:        resize,
 		Conf:          conf}
 }
+
+// PrepareTimeStampCommand creates a new command to add a document timestamp placeholder.
+func PrepareTimeStampCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.PREPARETIMESTAMP
+	return &Command{
+		Mode:    model.PREPARETIMESTAMP,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// EmbedTimeStampCommand creates a new command to embed a timestamp token into a document timestamp placeholder.
+func EmbedTimeStampCommand(inFile, tokenFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.EMBEDTIMESTAMP
+	return &Command{
+		Mode:       model.EMBEDTIMESTAMP,
+		InFile:     &inFile,
+		StringVals: []string{tokenFile},
+		Conf:       conf}
+}
+
+// AddDSSCommand creates a new command to add validation material to the Document Security Store.
+func AddDSSCommand(inFile string, validationFiles []string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDDSS
+	return &Command{
+		Mode:    model.ADDDSS,
+		InFile:  &inFile,
+		InFiles: validationFiles,
+		Conf:    conf}
+}
+
+// ListViewerPreferencesCommand creates a new command to list the viewer preferences.
+func ListViewerPreferencesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTVIEWERPREFERENCES
+	return &Command{
+		Mode:   model.LISTVIEWERPREFERENCES,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// SetPrintPreferencesCommand creates a new command to set the print related viewer preferences.
+func SetPrintPreferencesCommand(inFile, outFile string, pp *model.PrintPreferences, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SETPRINTPREFERENCES
+	return &Command{
+		Mode:       model.SETPRINTPREFERENCES,
+		InFile:     &inFile,
+		OutFile:    &outFile,
+		PrintPrefs: pp,
+		Conf:       conf}
+}
+
+// FillTemplateCommand creates a new command to clone a template page for each record of inFileData and substitute its tokens.
+func FillTemplateCommand(inFile, inFileData, outFile string, pageNr int, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.FILLTEMPLATE
+	return &Command{
+		Mode:       model.FILLTEMPLATE,
+		InFile:     &inFile,
+		InFileJSON: &inFileData,
+		OutFile:    &outFile,
+		IntVals:    []int{pageNr},
+		Conf:       conf}
+}
+
+// TilePageCommand creates a new command to split a page into a grid of tiles.
+func TilePageCommand(inFile, outFile string, pageNr, rows, cols int, overlap float64, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.TILEPAGE
+	return &Command{
+		Mode:      model.TILEPAGE,
+		InFile:    &inFile,
+		OutFile:   &outFile,
+		IntVals:   []int{pageNr, rows, cols},
+		FloatVals: []float64{overlap},
+		Conf:      conf}
+}
+
+// AddPageNumbersCommand creates a new command to number pages.
+func AddPageNumbersCommand(inFile, outFile string, pn *pdfcpu.PageNumbering, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.ADDPAGENUMBERS
+	return &Command{
+		Mode:          model.ADDPAGENUMBERS,
+		InFile:        &inFile,
+		OutFile:       &outFile,
+		PageNumbering: pn,
+		Conf:          conf}
+}
+
+// SetXMPThumbnailCommand creates a new command to set the XMP document thumbnail.
+func SetXMPThumbnailCommand(inFile, imageFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.SETXMPTHUMBNAIL
+	return &Command{
+		Mode:    model.SETXMPTHUMBNAIL,
+		InFile:  &inFile,
+		InFiles: []string{imageFile},
+		OutFile: &outFile,
+		Conf:    conf}
+}
+
+// ListVersionFeaturesCommand creates a new command to report the version gated features used vs the declared version.
+func ListVersionFeaturesCommand(inFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.LISTVERSIONFEATURES
+	return &Command{
+		Mode:   model.LISTVERSIONFEATURES,
+		InFile: &inFile,
+		Conf:   conf}
+}
+
+// UnsignCommand creates a new command to remove all signatures.
+func UnsignCommand(inFile, outFile string, conf *model.Configuration) *Command {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+	conf.Cmd = model.UNSIGN
+	return &Command{
+		Mode:    model.UNSIGN,
+		InFile:  &inFile,
+		OutFile: &outFile,
+		Conf:    conf}
+}
@@ -46,6 +46,18 @@ func processPageAnnotations(cmd *Command) (out []string, err error) {
 
 	case model.REMOVEANNOTATIONS:
 		out, err = RemoveAnnotations(cmd)
+
+	case model.CHECKANNOTATIONS:
+		out, err = CheckAnnotations(cmd)
+
+	case model.CLAMPANNOTATIONS:
+		out, err = ClampAnnotations(cmd)
+
+	case model.LISTDUPLICATEANNOTATIONS:
+		out, err = ListDuplicateAnnotations(cmd)
+
+	case model.REMOVEDUPLICATEANNOTATIONS:
+		out, err = RemoveDuplicateAnnotations(cmd)
 	}
 
 	return out, err
@@ -131,6 +143,9 @@ func processPermissions(cmd *Command) (out []string, err error) {
 
 	case model.SETPERMISSIONS:
 		return SetPermissions(cmd)
+
+	case model.CLEARRESTRICTIONS:
+		return ClearRestrictions(cmd)
 	}
 
 	return nil, nil
@@ -173,6 +188,12 @@ func processImages(cmd *Command) (out []string, err error) {
 
 	case model.LISTIMAGES:
 		return ListImages(cmd)
+
+	case model.CHECKIMAGES:
+		return CheckImages(cmd)
+
+	case model.LISTRESOLUTIONS:
+		return ListResolutions(cmd)
 	}
 
 	return nil, nil
@@ -204,6 +225,30 @@ func processForm(cmd *Command) (out []string, err error) {
 
 	case model.MULTIFILLFORMFIELDS:
 		return MultiFillFormFields(cmd)
+
+	case model.LISTFORMDRISSUES:
+		return ListFormDefaultResourceIssues(cmd)
+
+	case model.REPAIRFORMDR:
+		return RepairFormDefaultResources(cmd)
+
+	case model.REGENERATEAPPEARANCES:
+		return RegenerateAppearances(cmd)
+
+	case model.LISTCALCORDER:
+		return ListCalculationOrder(cmd)
+
+	case model.SETCALCORDER:
+		return SetCalculationOrder(cmd)
+
+	case model.LISTFIELDACTIONS:
+		return ListFieldActions(cmd)
+
+	case model.FLATTENFORM:
+		return FlattenForm(cmd)
+
+	case model.EXPORTFIELDPOSITIONS:
+		return ExportFieldPositions(cmd)
 	}
 
 	return nil, nil
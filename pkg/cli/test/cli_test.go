@@ -163,7 +163,7 @@ func TestUnknownCommand(t *testing.T) {
 	inFile := filepath.Join(outDir, "go.pdf")
 
 	cmd := &cli.Command{
-		Mode:   99,
+		Mode:   999,
 		InFile: &inFile,
 		Conf:   conf}
 
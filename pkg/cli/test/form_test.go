@@ -20,7 +20,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ex-preman/pdfcpu/pkg/api"
 	"github.com/ex-preman/pdfcpu/pkg/cli"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/color"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
 
 /**************************************************************
@@ -50,6 +54,104 @@ func TestRemoveFormFields(t *testing.T) {
 	}
 }
 
+// writeSignedFile creates a signature field on page 1 of inFile
+// and fakes a signature value, a field lock and some signature related document permissions.
+func writeSignedFile(t *testing.T, inFile, outFile string) {
+	t.Helper()
+	msg := "writeSignedFile"
+
+	sfa := model.NewSignatureFieldAnnotation(
+		*types.NewRectangle(50, 50, 250, 110),
+		"Signature1",
+		"Sign here",
+		"",
+		model.AnnPrint,
+		nil,
+		&color.Black)
+
+	if err := api.AddSignatureFieldFile(inFile, outFile, 1, sfa, nil); err != nil {
+		t.Fatalf("%s add: %v\n", msg, err)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	fields, err := ctx.DereferenceArray(ctx.AcroForm["Fields"])
+	if err != nil {
+		t.Fatalf("%s fields: %v\n", msg, err)
+	}
+	sigIndRef, err := ctx.IndRefForNewObject(types.Dict(map[string]types.Object{
+		"Type":      types.Name("Sig"),
+		"Filter":    types.Name("Adobe.PPKLite"),
+		"SubFilter": types.Name("adbe.pkcs7.detached"),
+		"Contents":  types.HexLiteral("00000000"),
+		"ByteRange": types.NewIntegerArray(0, 10, 20, 10),
+	}))
+	if err != nil {
+		t.Fatalf("%s sigDict: %v\n", msg, err)
+	}
+	for _, o := range fields {
+		d, _ := ctx.DereferenceDict(o)
+		if ft := d.NameEntry("FT"); ft != nil && *ft == "Sig" {
+			d.Insert("V", *sigIndRef)
+			d.Insert("Lock", types.Dict(map[string]types.Object{
+				"Type":   types.Name("SigFieldLock"),
+				"Action": types.Name("All"),
+			}))
+		}
+	}
+	ctx.AcroForm.Insert("SigFlags", types.Integer(3))
+	ctx.RootDict.Insert("Perms", types.Dict(map[string]types.Object{"DocMDP": *sigIndRef}))
+	if err := api.WriteContextFile(ctx, outFile); err != nil {
+		t.Fatalf("%s write: %v\n", msg, err)
+	}
+}
+
+func TestUnsignCommand(t *testing.T) {
+
+	msg := "TestUnsignCommand"
+	inFile := filepath.Join(outDir, "signed.pdf")
+	outFile := filepath.Join(outDir, "unsigned.pdf")
+
+	writeSignedFile(t, filepath.Join(inDir, "test.pdf"), inFile)
+
+	cmd := cli.UnsignCommand(inFile, outFile, conf)
+	ss, err := cli.Process(cmd)
+	if err != nil {
+		t.Fatalf("%s %s: %v\n", msg, inFile, err)
+	}
+	if len(ss) != 1 || ss[0] != "Signature1 (signed)" {
+		t.Fatalf("%s: unexpected result: %v\n", msg, ss)
+	}
+
+	ctx, err := api.ReadContextFile(outFile)
+	if err != nil {
+		t.Fatalf("%s read: %v\n", msg, err)
+	}
+	if _, found := ctx.RootDict.Find("Perms"); found {
+		t.Fatalf("%s: Perms not removed\n", msg)
+	}
+	if ctx.SignatureExist || ctx.AppendOnly {
+		t.Fatalf("%s: SigFlags not removed\n", msg)
+	}
+	fields, err := ctx.DereferenceArray(ctx.AcroForm["Fields"])
+	if err != nil {
+		t.Fatalf("%s fields: %v\n", msg, err)
+	}
+	for _, o := range fields {
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			t.Fatalf("%s field: %v\n", msg, err)
+		}
+		for _, k := range []string{"V", "Lock"} {
+			if _, found := d.Find(k); found {
+				t.Fatalf("%s: field entry %s not removed\n", msg, k)
+			}
+		}
+	}
+}
+
 func TestResetFormFields(t *testing.T) {
 
 	for _, tt := range []struct {
@@ -0,0 +1,221 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package font
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Glyphs represents the glyph inventory of a TrueType font program.
+type Glyphs struct {
+	GlyphCount  int               // maxp: numGlyphs
+	UnicodeMap  map[uint32]uint16 // cmap (3,1) or (0,x): Unicode character to glyph index
+	SymbolicMap map[uint32]uint16 // cmap (3,0) or (1,0): char code to glyph index
+	empty       map[uint16]bool   // glyphs without outline data
+}
+
+// HasGlyph returns true if gid is a valid glyph index.
+func (g Glyphs) HasGlyph(gid uint16) bool {
+	return gid > 0 && int(gid) < g.GlyphCount
+}
+
+// HasOutline returns true if glyph gid carries outline data.
+func (g Glyphs) HasOutline(gid uint16) bool {
+	return g.HasGlyph(gid) && !g.empty[gid]
+}
+
+func (t table) cmapFormat0() map[uint32]uint16 {
+	m := map[uint32]uint16{}
+	for c := 0; c < 256 && 6+c < len(t.data); c++ {
+		if gid := uint16(t.data[6+c]); gid > 0 {
+			m[uint32(c)] = gid
+		}
+	}
+	return m
+}
+
+func (t table) cmapFormat6() map[uint32]uint16 {
+	m := map[uint32]uint16{}
+	firstCode, entryCount := int(t.uint16(6)), int(t.uint16(8))
+	for i := 0; i < entryCount && 10+2*i+2 <= len(t.data); i++ {
+		if gid := t.uint16(10 + 2*i); gid > 0 {
+			m[uint32(firstCode+i)] = gid
+		}
+	}
+	return m
+}
+
+func (t table) cmap(f uint16) map[uint32]uint16 {
+	fd := &ttf{ToUnicode: map[uint16]uint32{}, Planes: map[int]bool{}, Chars: map[uint32]uint16{}}
+	switch f {
+	case 0:
+		return t.cmapFormat0()
+	case 6:
+		return t.cmapFormat6()
+	case 4:
+		t.parseCMapFormat4(fd)
+	case 12:
+		t.parseCMapFormat12(fd)
+	}
+	return fd.Chars
+}
+
+func (t table) parseCharToGlyphMappings(g *Glyphs) error {
+	// table "cmap"
+
+	type subTable struct {
+		format uint16
+		t      table
+	}
+	m := map[string]subTable{}
+
+	for i := 0; i < int(t.uint16(2)); i++ {
+		off := 4 + i*8
+		if off+8 > len(t.data) {
+			return fmt.Errorf("pdfcpu: corrupt cmap table")
+		}
+		pf, enc, o := t.uint16(off), t.uint16(off+2), t.uint32(off+4)
+		if int(o)+8 > len(t.data) {
+			return fmt.Errorf("pdfcpu: corrupt cmap table")
+		}
+		f := t.uint16(int(o))
+		if f != 0 && f != 4 && f != 6 && f != 12 {
+			continue
+		}
+		l := uint32(t.uint16(int(o) + 2))
+		if f == 12 {
+			l = t.uint32(int(o) + 4)
+		}
+		if int(o+l) > len(t.data) {
+			// Tolerate wrong subtable lengths.
+			l = uint32(len(t.data)) - o
+		}
+		m[fmt.Sprintf("p%02d.e%02d", pf, enc)] = subTable{f, table{off: o, size: l, data: t.data[o : o+l]}}
+	}
+
+	for _, k := range []string{"p03.e10", "p00.e04", "p00.e06", "p03.e01", "p00.e03", "p00.e00", "p00.e01"} {
+		if st, ok := m[k]; ok {
+			g.UnicodeMap = st.t.cmap(st.format)
+			break
+		}
+	}
+
+	for _, k := range []string{"p03.e00", "p01.e00"} {
+		if st, ok := m[k]; ok {
+			g.SymbolicMap = st.t.cmap(st.format)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (g *Glyphs) parseGlyphLocations(tables map[string]*table) error {
+	head, loca, glyf := tables["head"], tables["loca"], tables["glyf"]
+	if head == nil || loca == nil || glyf == nil || len(head.data) < 54 {
+		return fmt.Errorf("pdfcpu: missing glyph data")
+	}
+
+	indexToLocFormat := int(head.int16(50))
+
+	entrySize := 2
+	if indexToLocFormat == 1 {
+		entrySize = 4
+	}
+	if len(loca.data) < (g.GlyphCount+1)*entrySize {
+		return fmt.Errorf("pdfcpu: corrupt loca table")
+	}
+
+	g.empty = map[uint16]bool{}
+	for gid := 0; gid < g.GlyphCount; gid++ {
+		from, thru := glyfOffset(loca, gid, indexToLocFormat), glyfOffset(loca, gid+1, indexToLocFormat)
+		if thru <= from || from >= len(glyf.data) {
+			g.empty[uint16(gid)] = true
+		}
+	}
+
+	return nil
+}
+
+func embeddedTables(bb []byte) (map[string]*table, error) {
+	if len(bb) < 12 {
+		return nil, fmt.Errorf("pdfcpu: corrupt font program")
+	}
+
+	st := string(bb[:4])
+	if st == sfntVersionCFF {
+		return nil, fmt.Errorf("pdfcpu: OpenType CFF font programs are unsupported")
+	}
+	if st != sfntVersionTrueType && st != sfntVersionTrueTypeApple {
+		return nil, fmt.Errorf("pdfcpu: unrecognized font format")
+	}
+
+	c := int(binary.BigEndian.Uint16(bb[4:]))
+	if len(bb) < 12+c*16 {
+		return nil, fmt.Errorf("pdfcpu: corrupt font program")
+	}
+
+	// Embedded font programs are taken as is: no checksum verification, last table may be unpadded.
+	tables := map[string]*table{}
+	for j := 0; j < c; j++ {
+		b := bb[12+j*16:]
+		tag := string(b[:4])
+		o := binary.BigEndian.Uint32(b[8:])
+		l := binary.BigEndian.Uint32(b[12:])
+		if uint64(o)+uint64(l) > uint64(len(bb)) {
+			return nil, fmt.Errorf("pdfcpu: corrupt table: %s", tag)
+		}
+		tables[tag] = &table{off: o, size: l, padded: l, data: bb[o : o+l]}
+	}
+
+	return tables, nil
+}
+
+// ParseGlyphs returns the glyph inventory of the TrueType font program bb.
+func ParseGlyphs(bb []byte) (g *Glyphs, err error) {
+	defer func() {
+		// Embedded font programs may be arbitrarily broken.
+		if r := recover(); r != nil {
+			g, err = nil, fmt.Errorf("pdfcpu: corrupt font program")
+		}
+	}()
+
+	tables, err := embeddedTables(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	maxp, ok := tables["maxp"]
+	if !ok || len(maxp.data) < 6 {
+		return nil, fmt.Errorf("pdfcpu: missing maxp table")
+	}
+
+	g = &Glyphs{GlyphCount: int(maxp.uint16(4))}
+
+	if t, ok := tables["cmap"]; ok && len(t.data) >= 4 {
+		if err := t.parseCharToGlyphMappings(g); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.parseGlyphLocations(tables); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
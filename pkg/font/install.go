@@ -732,6 +732,59 @@ func InstallTrueTypeCollection(fontDir, fn string) error {
 	return nil
 }
 
+// ParseTrueTypeFontFile returns the metrics and the font program of the TrueType font file fontFile.
+func ParseTrueTypeFontFile(fontFile string) (*TTFLight, []byte, error) {
+	f, err := os.Open(fontFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	header, tables, err := headerAndTables(fontFile, f, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fd := ttf{}
+	for _, v := range []string{"head", "OS/2", "post", "name", "hhea", "maxp", "hmtx", "cmap"} {
+		if err := parse(tables, v, &fd); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	bb, err := createTTF(header, tables)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fl := &TTFLight{
+		PostscriptName:  fd.PostscriptName,
+		Protected:       fd.Protected,
+		UnitsPerEm:      fd.UnitsPerEm,
+		Ascent:          fd.Ascent,
+		Descent:         fd.Descent,
+		CapHeight:       fd.CapHeight,
+		FirstChar:       fd.FirstChar,
+		LastChar:        fd.LastChar,
+		UnicodeRange:    fd.UnicodeRange,
+		LLx:             fd.LLx,
+		LLy:             fd.LLy,
+		URx:             fd.URx,
+		URy:             fd.URy,
+		ItalicAngle:     fd.ItalicAngle,
+		FixedPitch:      fd.FixedPitch,
+		Bold:            fd.Bold,
+		HorMetricsCount: fd.HorMetricsCount,
+		GlyphCount:      fd.GlyphCount,
+		GlyphWidths:     fd.GlyphWidths,
+		Chars:           fd.Chars,
+		ToUnicode:       fd.ToUnicode,
+		Planes:          fd.Planes,
+	}
+
+	return fl, bb, nil
+}
+
 // InstallTrueTypeFont saves an internal representation of TrueType font fontName to the pdfcpu config dir.
 func InstallTrueTypeFont(fontDir, fontName string) error {
 	f, err := os.Open(fontName)
@@ -1011,6 +1064,14 @@ func Subset(fontName string, usedGIDs map[uint16]bool) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	return SubsetFontFile(fontName, bb, usedGIDs)
+}
+
+// SubsetFontFile creates a new font file for the TrueType font program bb based on usedGIDs.
+func SubsetFontFile(fontName string, bb []byte, usedGIDs map[uint16]bool) ([]byte, error) {
+	if len(bb) < 12 {
+		return nil, errors.Errorf("pdfcpu: corrupt font file: %s", fontName)
+	}
 
 	header := bb[:12]
 	tableCount := int(binary.BigEndian.Uint16(header[4:]))
@@ -339,6 +339,35 @@ func Size(text, fontName string, width float64) int {
 	return fontScalingFactor(float64(w), width)
 }
 
+// MeasureText returns width, ascent and descent in user space units for a given text string, font name and font size.
+// For core fonts text is expected to be byte encoded.
+func MeasureText(fontName string, fontSize int, text string) (width, ascent, descent float64) {
+	return TextWidth(text, fontName, fontSize), Ascent(fontName, fontSize), Descent(fontName, fontSize)
+}
+
+// MaxFontSize returns the largest font size in points for rendering a given text string
+// using a given font name as a single line fitting into a box of user space width w and height h.
+// For core fonts text is expected to be byte encoded.
+func MaxFontSize(fontName, text string, w, h float64) int {
+	// Text metrics for font size 1000 are in glyph space units.
+	gw, ascent, descent := MeasureText(fontName, 1000, text)
+
+	// The line height is ascent + descent.
+	var fs int
+	if lh := ascent + descent; lh > 0 {
+		fs = int(math.Floor(GlyphSpaceUnits(h, 1) / lh))
+	}
+	if gw > 0 {
+		if fs1 := int(math.Floor(GlyphSpaceUnits(w, 1) / gw)); fs1 < fs {
+			fs = fs1
+		}
+	}
+	if fs < 0 {
+		fs = 0
+	}
+	return fs
+}
+
 // UserSpaceFontBBox returns the font box for given font name and font size in user space coordinates.
 func UserSpaceFontBBox(fontName string, fontSize int) *types.Rectangle {
 	fontBBox := BoundingBox(fontName)
@@ -319,8 +319,20 @@ func AddAnnotation(
 		return false, err
 	}
 
+	return addAnnotationToPage(ctx, annotIndRef, pageDictIndRef, pageDict, pageNr, ar, incr)
+}
+
+func addAnnotationToPage(
+	ctx *model.Context,
+	annotIndRef *types.IndirectRef,
+	pageDictIndRef *types.IndirectRef,
+	pageDict types.Dict,
+	pageNr int,
+	ar model.AnnotationRenderer,
+	incr bool) (bool, error) {
+
 	// Add annotation to xreftable page annotation cache.
-	err = addAnnotationToCache(ctx, ar, pageNr, annotIndRef.ObjectNumber.Value())
+	err := addAnnotationToCache(ctx, ar, pageNr, annotIndRef.ObjectNumber.Value())
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// annotKey identifies annotations considered exact duplicates of each other.
+type annotKey struct {
+	subtype  string
+	rect     types.Rectangle
+	contents string
+}
+
+type keptAnnot struct {
+	id    string
+	ir    *types.IndirectRef
+	popup *types.IndirectRef
+}
+
+// contentsText returns the decoded /Contents of the annotation d.
+func contentsText(xRefTable *model.XRefTable, d types.Dict) (string, error) {
+	o, err := xRefTable.Dereference(d["Contents"])
+	if err != nil || o == nil {
+		return "", err
+	}
+	return model.Text(o)
+}
+
+func duplicatePageAnnotations(ctx *model.Context, pageNr int, remove bool) ([]string, error) {
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return nil, err
+	}
+
+	var ss []string
+
+	kept := map[annotKey]keptAnnot{}
+
+	// Duplicates to be removed along with their popups.
+	removed := map[int]bool{}
+	popups := map[types.IndirectRef]bool{}
+
+	for i, o := range annots {
+		d1, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d1 == nil {
+			continue
+		}
+
+		subtype := d1.NameEntry("Subtype")
+		if subtype == nil || *subtype == "Popup" || *subtype == "Widget" {
+			// Popups go with their parent, removing widgets would break the form.
+			continue
+		}
+
+		r := normalizedAnnotRect(ctx.XRefTable, d1)
+		if r == nil {
+			continue
+		}
+
+		contents, err := contentsText(ctx.XRefTable, d1)
+		if err != nil {
+			return nil, err
+		}
+
+		k := annotKey{subtype: *subtype, rect: *r, contents: contents}
+
+		var ir *types.IndirectRef
+		if ir1, ok := o.(types.IndirectRef); ok {
+			ir = &ir1
+		}
+		popup := d1.IndirectRefEntry("Popup")
+
+		ka, found := kept[k]
+		if !found {
+			kept[k] = keptAnnot{id: annotID(o, d1, i), ir: ir, popup: popup}
+			continue
+		}
+
+		s := "duplicate"
+		if remove {
+			s = "removed duplicate"
+			removed[i] = true
+			if popup != nil && (ka.popup == nil || *popup != *ka.popup) {
+				popups[*popup] = true
+			}
+		}
+		ss = append(ss, fmt.Sprintf("page %d: %s: %s of %s", pageNr, annotID(o, d1, i), s, ka.id))
+	}
+
+	if len(removed) == 0 {
+		return ss, nil
+	}
+
+	// Annotations still referenced by /Annots must not be freed.
+	inUse := map[types.IndirectRef]bool{}
+	for i, o := range annots {
+		if ir, ok := o.(types.IndirectRef); ok && !removed[i] && !popups[ir] {
+			inUse[ir] = true
+		}
+	}
+
+	annots1 := types.Array{}
+	for i, o := range annots {
+		ir, ok := o.(types.IndirectRef)
+		if !removed[i] && !(ok && popups[ir]) {
+			annots1 = append(annots1, o)
+			continue
+		}
+		if ok && inUse[ir] {
+			continue
+		}
+		if err := uncacheAnnotation(ctx, pageNr, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, updatePageAnnots(ctx, d, annots1)
+}
+
+// DuplicateAnnotations returns a list of all exact duplicate annotations of selected pages,
+// annotations sharing /Subtype, /Rect and /Contents with a preceding annotation of the same page.
+// If remove is true, duplicates get removed along with their popups keeping the first annotation only.
+// Popups and form field widgets are not taken into account.
+func DuplicateAnnotations(ctx *model.Context, selectedPages types.IntSet, remove bool) ([]string, error) {
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+
+	for _, i := range pageNrs {
+		ss1, err := duplicatePageAnnotations(ctx, i, remove)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, ss1...)
+	}
+
+	if remove && len(ss) > 0 {
+		ctx.EnsureVersionForWriting()
+	}
+
+	return ss, nil
+}
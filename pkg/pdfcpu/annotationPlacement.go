@@ -0,0 +1,346 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+type placedAnnot struct {
+	id   string
+	rect *types.Rectangle
+}
+
+// annotID identifies the i-th annotation o of a page for reporting.
+func annotID(o types.Object, d types.Dict, i int) string {
+	s := "?"
+	if n := d.NameEntry("Subtype"); n != nil {
+		s = *n
+	}
+
+	if ir, ok := o.(types.IndirectRef); ok {
+		s += fmt.Sprintf(" obj#%d", ir.ObjectNumber.Value())
+	} else {
+		s += fmt.Sprintf(" #%d", i+1)
+	}
+
+	if nm := d.StringOrHexLiteralEntry("NM"); nm != nil {
+		s += fmt.Sprintf(" (id: %s)", *nm)
+	}
+
+	return s
+}
+
+// normalizedAnnotRect returns the normalized rectangle of the annotation d or nil.
+func normalizedAnnotRect(xRefTable *model.XRefTable, d types.Dict) *types.Rectangle {
+	a, err := xRefTable.DereferenceArray(d["Rect"])
+	if err != nil || len(a) != 4 {
+		return nil
+	}
+	r, err := types.RectForArray(a)
+	if err != nil {
+		return nil
+	}
+	return types.NewRectangle(
+		math.Min(r.LL.X, r.UR.X), math.Min(r.LL.Y, r.UR.Y),
+		math.Max(r.LL.X, r.UR.X), math.Max(r.LL.Y, r.UR.Y))
+}
+
+// overlap returns true if r1 and r2 share an area.
+func overlap(r1, r2 *types.Rectangle) bool {
+	return math.Min(r1.UR.X, r2.UR.X) > math.Max(r1.LL.X, r2.LL.X) &&
+		math.Min(r1.UR.Y, r2.UR.Y) > math.Max(r1.LL.Y, r2.LL.Y)
+}
+
+// within returns true if r lies within r2.
+func within(r, r2 *types.Rectangle) bool {
+	return r.LL.X >= r2.LL.X && r.LL.Y >= r2.LL.Y && r.UR.X <= r2.UR.X && r.UR.Y <= r2.UR.Y
+}
+
+func pageAnnotationIssues(xRefTable *model.XRefTable, pageNr int, cropBox *types.Rectangle) ([]string, error) {
+	d, _, _, err := xRefTable.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	annots, err := xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return nil, err
+	}
+
+	var (
+		ss     []string
+		placed []placedAnnot
+	)
+
+	for i, o := range annots {
+		d1, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d1 == nil {
+			continue
+		}
+
+		if n := d1.NameEntry("Subtype"); n != nil && *n == "Popup" {
+			continue
+		}
+		if f := d1.IntEntry("F"); f != nil && model.AnnotationFlags(*f)&(model.AnnHidden|model.AnnNoView) > 0 {
+			continue
+		}
+
+		id := annotID(o, d1, i)
+
+		r := normalizedAnnotRect(xRefTable, d1)
+		if r == nil {
+			ss = append(ss, fmt.Sprintf("page %d: %s: missing or invalid /Rect", pageNr, id))
+			continue
+		}
+
+		if cropBox != nil && !within(r, cropBox) {
+			s := "partly outside"
+			if !overlap(r, cropBox) {
+				s = "outside"
+			}
+			ss = append(ss, fmt.Sprintf("page %d: %s: /Rect %s %s CropBox %s", pageNr, id, r.ShortString(), s, cropBox.ShortString()))
+		}
+
+		for _, pa := range placed {
+			if overlap(r, pa.rect) {
+				ss = append(ss, fmt.Sprintf("page %d: %s overlaps %s", pageNr, id, pa.id))
+			}
+		}
+
+		placed = append(placed, placedAnnot{id: id, rect: r})
+	}
+
+	return ss, nil
+}
+
+// AnnotationIssues returns a list of all annotations of selected pages
+// whose /Rect lies (partly) outside the CropBox of their page or overlaps other annotations of the same page.
+// Hidden annotations and popups are not checked.
+func AnnotationIssues(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	pbs, err := ctx.PageBoundaries()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+
+	for _, i := range pageNrs {
+		ss1, err := pageAnnotationIssues(ctx.XRefTable, i, pbs[i-1].CropBox())
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, ss1...)
+	}
+
+	return ss, nil
+}
+
+// clampAnnotTypes returns the set of annotation types to be clamped.
+// All annotation types except popups are clamped by default.
+func clampAnnotTypes(annotTypes []string) (map[string]bool, error) {
+	m := map[string]bool{}
+
+	if len(annotTypes) == 0 {
+		for k := range model.AnnotTypes {
+			m[k] = k != "Popup"
+		}
+		return m, nil
+	}
+
+	for _, s := range annotTypes {
+		if _, ok := model.AnnotTypes[s]; !ok {
+			return nil, errors.Errorf("pdfcpu: ClampAnnotations: unknown annotation type: %s", s)
+		}
+		m[s] = true
+	}
+
+	return m, nil
+}
+
+func uncacheAnnotation(ctx *model.Context, pageNr int, o types.Object) error {
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		return nil
+	}
+	// Annotations failing validation may not be cached.
+	_ = removeAnnotationFromCache(ctx, pageNr, ir.ObjectNumber.Value())
+	return ctx.FreeObject(ir.ObjectNumber.Value())
+}
+
+func clampPageAnnotations(ctx *model.Context, pageNr int, cropBox *types.Rectangle, annotTypes map[string]bool) ([]string, error) {
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return nil, err
+	}
+
+	var ss []string
+
+	// Annotations to be removed along with their popups.
+	removed := map[int]bool{}
+	popups := map[types.IndirectRef]bool{}
+
+	for i, o := range annots {
+		d1, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d1 == nil {
+			continue
+		}
+
+		subtype := d1.NameEntry("Subtype")
+		if subtype == nil || !annotTypes[*subtype] {
+			continue
+		}
+
+		r := normalizedAnnotRect(ctx.XRefTable, d1)
+		if r == nil || within(r, cropBox) {
+			continue
+		}
+
+		id := annotID(o, d1, i)
+
+		if overlap(r, cropBox) {
+			r1 := types.NewRectangle(
+				math.Max(r.LL.X, cropBox.LL.X), math.Max(r.LL.Y, cropBox.LL.Y),
+				math.Min(r.UR.X, cropBox.UR.X), math.Min(r.UR.Y, cropBox.UR.Y))
+			d1["Rect"] = r1.Array()
+			ss = append(ss, fmt.Sprintf("page %d: %s: /Rect %s clamped to %s", pageNr, id, r.ShortString(), r1.ShortString()))
+			continue
+		}
+
+		if *subtype == "Widget" {
+			// Removing widgets would break the form.
+			ss = append(ss, fmt.Sprintf("page %d: %s: /Rect %s outside CropBox, kept (form field widget)", pageNr, id, r.ShortString()))
+			continue
+		}
+
+		removed[i] = true
+		if ir := d1.IndirectRefEntry("Popup"); ir != nil {
+			popups[*ir] = true
+		}
+		ss = append(ss, fmt.Sprintf("page %d: %s: /Rect %s outside CropBox, removed", pageNr, id, r.ShortString()))
+	}
+
+	if len(removed) == 0 {
+		return ss, nil
+	}
+
+	annots1 := types.Array{}
+	for i, o := range annots {
+		ir, ok := o.(types.IndirectRef)
+		if !removed[i] && !(ok && popups[ir]) {
+			annots1 = append(annots1, o)
+			continue
+		}
+		if err := uncacheAnnotation(ctx, pageNr, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, updatePageAnnots(ctx, d, annots1)
+}
+
+// updatePageAnnots sets annots as /Annots of the page dict d, an empty annots removes /Annots.
+func updatePageAnnots(ctx *model.Context, d types.Dict, annots types.Array) error {
+	if ir, ok := d["Annots"].(types.IndirectRef); ok {
+		if len(annots) > 0 {
+			if entry, found := ctx.FindTableEntryForIndRef(&ir); found {
+				entry.Object = annots
+				return nil
+			}
+		} else if err := ctx.FreeObject(ir.ObjectNumber.Value()); err != nil {
+			return err
+		}
+	}
+
+	if len(annots) == 0 {
+		d.Delete("Annots")
+	} else {
+		d["Annots"] = annots
+	}
+
+	return nil
+}
+
+// ClampAnnotations clamps the /Rect of all annotations of selected pages lying partly outside the CropBox of their page
+// to the CropBox and removes annotations lying completely outside along with their popups.
+// Only annotations of annotTypes are processed, by default all annotation types except popups.
+// Form field widgets lying completely outside the CropBox are kept.
+// The result is a list of all repairs made.
+func ClampAnnotations(ctx *model.Context, selectedPages types.IntSet, annotTypes []string) ([]string, error) {
+	m, err := clampAnnotTypes(annotTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	pbs, err := ctx.PageBoundaries()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+
+	for _, i := range pageNrs {
+		cropBox := pbs[i-1].CropBox()
+		if cropBox == nil {
+			continue
+		}
+		ss1, err := clampPageAnnotations(ctx, i, cropBox, m)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, ss1...)
+	}
+
+	if len(ss) > 0 {
+		ctx.EnsureVersionForWriting()
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,426 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// Minimum share of the page area covered by a page background.
+const backgroundCoverage = 0.9
+
+// ArtifactRange is a range of content operations of a page to be wrapped into an /Artifact marked content sequence.
+type ArtifactRange struct {
+	From, Thru int // zero based indices of the first and the last content operation
+}
+
+func (r ArtifactRange) String() string {
+	if r.From == r.Thru {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.Thru)
+}
+
+// ParseArtifactRanges parses a comma separated list of content operation ranges, eg. "0-3,7".
+func ParseArtifactRanges(s string) ([]ArtifactRange, error) {
+	var rr []ArtifactRange
+
+	for _, s := range strings.Split(s, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		from, thru := s, s
+		if i := strings.Index(s, "-"); i >= 0 {
+			from, thru = s[:i], s[i+1:]
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, errors.Errorf("pdfcpu: invalid content operation range: %s", s)
+		}
+		j, err := strconv.Atoi(strings.TrimSpace(thru))
+		if err != nil || i < 0 || j < i {
+			return nil, errors.Errorf("pdfcpu: invalid content operation range: %s", s)
+		}
+		rr = append(rr, ArtifactRange{From: i, Thru: j})
+	}
+
+	if len(rr) == 0 {
+		return nil, errors.New("pdfcpu: missing content operation ranges")
+	}
+
+	return rr, nil
+}
+
+// artifactCandidate is untagged page content likely to be decorative.
+type artifactCandidate struct {
+	r      ArtifactRange
+	bb     types.Rectangle // bounding box in user space
+	objNr  int             // object number of the XObject drawn, 0 for filled paths
+	name   string          // resource name of the XObject drawn
+	tagged bool            // XObjects within marked content count as repeated but are no candidates
+}
+
+func emptyBox() types.Rectangle {
+	return types.Rectangle{LL: types.Point{X: math.Inf(1), Y: math.Inf(1)}, UR: types.Point{X: math.Inf(-1), Y: math.Inf(-1)}}
+}
+
+func unionBox(r, r1 types.Rectangle) types.Rectangle {
+	return types.Rectangle{
+		LL: types.Point{X: math.Min(r.LL.X, r1.LL.X), Y: math.Min(r.LL.Y, r1.LL.Y)},
+		UR: types.Point{X: math.Max(r.UR.X, r1.UR.X), Y: math.Max(r.UR.Y, r1.UR.Y)},
+	}
+}
+
+// coverage returns the share of the area of box covered by r.
+func coverage(r, box types.Rectangle) float64 {
+	w := math.Min(r.UR.X, box.UR.X) - math.Max(r.LL.X, box.LL.X)
+	h := math.Min(r.UR.Y, box.UR.Y) - math.Max(r.LL.Y, box.LL.Y)
+	if w <= 0 || h <= 0 || box.Width() <= 0 || box.Height() <= 0 {
+		return 0
+	}
+	return w * h / (box.Width() * box.Height())
+}
+
+// pathBox returns the bounding box in user space of a path construction operation.
+func pathBox(op model.ContentOp, ctm matrix.Matrix) types.Rectangle {
+	ff, _ := numbers(op.Operands)
+
+	if op.Operator == "re" {
+		if len(ff) != 4 {
+			return emptyBox()
+		}
+		r := types.NewRectangle(math.Min(ff[0], ff[0]+ff[2]), math.Min(ff[1], ff[1]+ff[3]), math.Max(ff[0], ff[0]+ff[2]), math.Max(ff[1], ff[1]+ff[3]))
+		return transformedBox(ctm, *r)
+	}
+
+	bb := emptyBox()
+	for i := 0; i+1 < len(ff); i += 2 {
+		p := ctm.Transform(types.Point{X: ff[i], Y: ff[i+1]})
+		bb = unionBox(bb, types.Rectangle{LL: p, UR: p})
+	}
+	return bb
+}
+
+func xObjectBox(ctx *model.Context, sd *types.StreamDict, ctm matrix.Matrix) types.Rectangle {
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return transformedBox(ctm, unitSquare)
+	}
+
+	if a, err := ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		if ff, ok := numbers(a); ok {
+			ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ctm)
+		}
+	}
+
+	bbox := unitSquare
+	if a, err := ctx.DereferenceArray(sd.Dict["BBox"]); err == nil && len(a) == 4 {
+		if ff, ok := numbers(a); ok {
+			bbox = *types.NewRectangle(math.Min(ff[0], ff[2]), math.Min(ff[1], ff[3]), math.Max(ff[0], ff[2]), math.Max(ff[1], ff[3]))
+		}
+	}
+
+	return transformedBox(ctm, bbox)
+}
+
+// artifactCandidates returns all filled paths outside of marked content and text objects and all XObjects of ops.
+func artifactCandidates(ctx *model.Context, ops []model.ContentOp, resDict types.Dict) ([]artifactCandidate, error) {
+	var cc []artifactCandidate
+
+	ts := &textSizer{ctm: matrix.IdentMatrix, tm: matrix.IdentMatrix, tlm: matrix.IdentMatrix}
+
+	depth, text, pathStart := 0, false, -1
+	bb := emptyBox()
+
+	for i, op := range ops {
+		ts.process(op)
+
+		switch op.Operator {
+
+		case "BMC", "BDC":
+			depth++
+
+		case "EMC":
+			if depth > 0 {
+				depth--
+			}
+
+		case "BT":
+			text = true
+
+		case "ET":
+			text = false
+
+		case "m", "l", "c", "v", "y", "h", "re":
+			if pathStart < 0 {
+				pathStart, bb = i, emptyBox()
+			}
+			bb = unionBox(bb, pathBox(op, ts.ctm))
+
+		case "f", "F", "f*", "B", "B*", "b", "b*":
+			if pathStart >= 0 && depth == 0 && !text && !math.IsInf(bb.LL.X, 1) {
+				cc = append(cc, artifactCandidate{r: ArtifactRange{From: pathStart, Thru: i}, bb: bb})
+			}
+			pathStart = -1
+
+		case "S", "s", "n":
+			pathStart = -1
+
+		case "Do":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			n, ok := op.Operands[0].(types.Name)
+			if !ok {
+				continue
+			}
+			ir, err := resourceIndRef(ctx.XRefTable, resDict, "XObject", n.Value())
+			if err != nil {
+				return nil, err
+			}
+			if ir == nil {
+				continue
+			}
+			sd, _, err := ctx.DereferenceStreamDict(*ir)
+			if err != nil {
+				return nil, err
+			}
+			if sd == nil {
+				continue
+			}
+			cc = append(cc, artifactCandidate{
+				r:      ArtifactRange{From: i, Thru: i},
+				bb:     xObjectBox(ctx, sd, ts.ctm),
+				objNr:  ir.ObjectNumber.Value(),
+				name:   n.Value(),
+				tagged: depth > 0,
+			})
+		}
+	}
+
+	return cc, nil
+}
+
+func pageArtifactCandidates(ctx *model.Context, pageNr int) ([]artifactCandidate, *types.Rectangle, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, nil, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps(bb)
+
+	cc, err := artifactCandidates(ctx, ops, inhPAttrs.Resources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	box := inhPAttrs.CropBox
+	if box == nil {
+		box = inhPAttrs.MediaBox
+	}
+
+	return cc, box, nil
+}
+
+// ArtifactCandidates returns a report of untagged content of the selected pages likely to be decorative:
+// page backgrounds and XObjects like logos or header and footer graphics repeated on several pages.
+// Content within marked content sequences is considered to be tagged already.
+// Each content operation range reported may be passed on to TagArtifacts.
+func ArtifactCandidates(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	var ss []string
+
+	pageCandidates := make([][]artifactCandidate, ctx.PageCount+1)
+	pageBoxes := make([]*types.Rectangle, ctx.PageCount+1)
+
+	// XObject object numbers mapped to the pages drawing them.
+	xObjPages := map[int]types.IntSet{}
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		cc, box, err := pageArtifactCandidates(ctx, pageNr)
+		if err != nil {
+			return nil, err
+		}
+		pageCandidates[pageNr], pageBoxes[pageNr] = cc, box
+		for _, c := range cc {
+			if c.objNr == 0 {
+				continue
+			}
+			if xObjPages[c.objNr] == nil {
+				xObjPages[c.objNr] = types.IntSet{}
+			}
+			xObjPages[c.objNr][pageNr] = true
+		}
+	}
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		if selectedPages != nil && !selectedPages[pageNr] {
+			continue
+		}
+		for _, c := range pageCandidates[pageNr] {
+			if c.tagged {
+				continue
+			}
+			if box := pageBoxes[pageNr]; box != nil {
+				if f := coverage(c.bb, *box); f >= backgroundCoverage {
+					ss = append(ss, fmt.Sprintf("page %d: ops %s: background covering %.0f%% of the page", pageNr, c.r, f*100))
+					continue
+				}
+			}
+			if n := len(xObjPages[c.objNr]); c.objNr > 0 && n > 1 {
+				ss = append(ss, fmt.Sprintf("page %d: ops %s: /%s (obj#%d) repeated on %d pages", pageNr, c.r, c.name, c.objNr, n))
+			}
+		}
+	}
+
+	return ss, nil
+}
+
+// checkArtifactRange returns an error if wrapping r into a marked content sequence would break the nesting of ops.
+func checkArtifactRange(ops []model.ContentOp, r ArtifactRange) error {
+	var depth, text int
+	path := false
+
+	for i, op := range ops[:r.Thru+1] {
+		if i == r.From {
+			if depth > 0 {
+				return errors.Errorf("ops %s overlap marked content", r)
+			}
+			if path {
+				return errors.Errorf("ops %s start within a path object", r)
+			}
+			text = 0
+		}
+
+		switch op.Operator {
+		case "BMC", "BDC":
+			depth++
+		case "EMC":
+			depth--
+		case "BT":
+			text++
+		case "ET":
+			text--
+		case "m", "l", "c", "v", "y", "h", "re", "W", "W*":
+			path = true
+		case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+			path = false
+		}
+
+		if i >= r.From && (depth < 0 || text < 0) {
+			return errors.Errorf("ops %s break the nesting of content operations", r)
+		}
+	}
+
+	if depth != 0 || text != 0 {
+		return errors.Errorf("ops %s break the nesting of content operations", r)
+	}
+	if path {
+		return errors.Errorf("ops %s end within a path object", r)
+	}
+
+	return nil
+}
+
+// TagArtifacts wraps the content operation ranges rr of page pageNr into /Artifact marked content sequences
+// so assistive technology like screen readers skips decorative content.
+// Ranges refer to the page content as reported by ArtifactCandidates and must not break the nesting of
+// marked content, text objects or path objects.
+// The result is a list of ranges tagged.
+func TagArtifacts(ctx *model.Context, pageNr int, rr []ArtifactRange) ([]string, error) {
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: TagArtifacts: invalid page number: %d", pageNr)
+	}
+
+	d, _, _, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: TagArtifacts: page %d not found", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	rr = append([]ArtifactRange(nil), rr...)
+	sort.Slice(rr, func(i, j int) bool { return rr[i].From < rr[j].From })
+
+	for i, r := range rr {
+		if r.From < 0 || r.Thru < r.From || r.Thru >= len(ops) {
+			return nil, errors.Errorf("pdfcpu: TagArtifacts: page %d: ops %s out of range (%d operations)", pageNr, r, len(ops))
+		}
+		if i > 0 && r.From <= rr[i-1].Thru {
+			return nil, errors.Errorf("pdfcpu: TagArtifacts: page %d: ops %s overlap ops %s", pageNr, r, rr[i-1])
+		}
+		if err := checkArtifactRange(ops, r); err != nil {
+			return nil, errors.Errorf("pdfcpu: TagArtifacts: page %d: %v", pageNr, err)
+		}
+	}
+
+	var ss []string
+
+	ops1 := make([]model.ContentOp, 0, len(ops)+2*len(rr))
+	from := 0
+	for _, r := range rr {
+		ops1 = append(ops1, ops[from:r.From]...)
+		ops1 = append(ops1, model.ContentOp{Operator: "BMC", Operands: []types.Object{types.Name("Artifact")}})
+		ops1 = append(ops1, ops[r.From:r.Thru+1]...)
+		ops1 = append(ops1, model.ContentOp{Operator: "EMC"})
+		from = r.Thru + 1
+		ss = append(ss, fmt.Sprintf("page %d: ops %s tagged as artifact", pageNr, r))
+	}
+	ops1 = append(ops1, ops[from:]...)
+
+	sd, _ := ctx.NewStreamDictForBuf(model.ContentOpsBytes(ops1))
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+
+	d["Contents"] = *ir
+
+	return ss, nil
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// Minimum ratios of heading font size to body text font size.
+const (
+	headingH1Ratio = 1.6
+	headingH2Ratio = 1.25
+)
+
+// ErrAlreadyTagged indicates a document already carrying a structure tree.
+var ErrAlreadyTagged = errors.New("pdfcpu: document is already tagged")
+
+// tagBlock is a run of content operations making up a single struct element.
+type tagBlock struct {
+	from, thru int     // range of content operations
+	s          string  // structure type, "" for untagged content
+	size       float64 // max effective font size for text blocks
+	shows      int     // number of text showing operations
+}
+
+type taggedPage struct {
+	pageNr int
+	d      types.Dict
+	ir     *types.IndirectRef
+	ops    []model.ContentOp
+	bb     []tagBlock
+}
+
+type textSizer struct {
+	ctm, tm, tlm matrix.Matrix
+	ctms         []matrix.Matrix
+	fontSize     float64
+	leading      float64
+}
+
+func (ts *textSizer) nextLine(tx, ty float64) {
+	ts.tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(ts.tlm)
+	ts.tm = ts.tlm
+}
+
+// size returns the current font size in user space.
+func (ts *textSizer) size() float64 {
+	m := ts.tm.Multiply(ts.ctm)
+	return ts.fontSize * math.Sqrt(math.Abs(m[0][0]*m[1][1]-m[0][1]*m[1][0]))
+}
+
+// process updates the graphics and text state for op and returns true for text showing operations.
+func (ts *textSizer) process(op model.ContentOp) bool {
+	ff, _ := numbers(op.Operands)
+
+	switch op.Operator {
+
+	case "q":
+		ts.ctms = append(ts.ctms, ts.ctm)
+
+	case "Q":
+		if len(ts.ctms) > 0 {
+			ts.ctm, ts.ctms = ts.ctms[len(ts.ctms)-1], ts.ctms[:len(ts.ctms)-1]
+		}
+
+	case "cm":
+		if len(ff) == 6 {
+			ts.ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ts.ctm)
+		}
+
+	case "BT":
+		ts.tm, ts.tlm = matrix.IdentMatrix, matrix.IdentMatrix
+
+	case "Tf":
+		if len(op.Operands) == 2 {
+			if f, ok := number(op.Operands[1]); ok {
+				ts.fontSize = f
+			}
+		}
+
+	case "TL":
+		if len(ff) == 1 {
+			ts.leading = ff[0]
+		}
+
+	case "Td":
+		if len(ff) == 2 {
+			ts.nextLine(ff[0], ff[1])
+		}
+
+	case "TD":
+		if len(ff) == 2 {
+			ts.leading = -ff[1]
+			ts.nextLine(ff[0], ff[1])
+		}
+
+	case "Tm":
+		if len(ff) == 6 {
+			ts.tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+			ts.tm = ts.tlm
+		}
+
+	case "T*":
+		ts.nextLine(0, -ts.leading)
+
+	case "'", "\"":
+		ts.nextLine(0, -ts.leading)
+		return true
+
+	case "Tj", "TJ":
+		return true
+	}
+
+	return false
+}
+
+func imageXObject(ctx *model.Context, xObjs types.Dict, op model.ContentOp) bool {
+	if xObjs == nil || len(op.Operands) != 1 {
+		return false
+	}
+	n, ok := op.Operands[0].(types.Name)
+	if !ok {
+		return false
+	}
+	sd, _, err := ctx.DereferenceStreamDict(xObjs[n.Value()])
+	if err != nil || sd == nil {
+		return false
+	}
+	s := sd.Subtype()
+	return s != nil && *s == "Image"
+}
+
+// tagBlocks partitions the content of a page into text blocks, images and untagged content.
+// Text objects (BT..ET) make up text blocks, image XObjects and inline images make up figures.
+func tagBlocks(ctx *model.Context, ops []model.ContentOp, xObjs types.Dict) []tagBlock {
+	var (
+		bb []tagBlock
+		b  *tagBlock
+	)
+
+	ts := &textSizer{ctm: matrix.IdentMatrix, tm: matrix.IdentMatrix, tlm: matrix.IdentMatrix}
+
+	untagged := func(i int) {
+		if len(bb) > 0 && bb[len(bb)-1].s == "" {
+			bb[len(bb)-1].thru = i
+			return
+		}
+		bb = append(bb, tagBlock{from: i, thru: i})
+	}
+
+	for i, op := range ops {
+		show := ts.process(op)
+
+		if b != nil {
+			// Within a text object.
+			b.thru = i
+			if show {
+				b.shows++
+				b.size = math.Max(b.size, ts.size())
+			}
+			if op.Operator == "ET" {
+				bb = append(bb, *b)
+				b = nil
+			}
+			continue
+		}
+
+		switch {
+		case op.Operator == "BT":
+			b = &tagBlock{from: i, thru: i, s: "P"}
+		case op.Operator == "BI", op.Operator == "Do" && imageXObject(ctx, xObjs, op):
+			bb = append(bb, tagBlock{from: i, thru: i, s: "Figure"})
+		default:
+			untagged(i)
+		}
+	}
+
+	if b != nil {
+		// Unterminated text object.
+		b.s = ""
+		bb = append(bb, *b)
+	}
+
+	// Text objects without visible text remain untagged.
+	for i := range bb {
+		if bb[i].s == "P" && bb[i].shows == 0 {
+			bb[i].s = ""
+		}
+	}
+
+	return bb
+}
+
+// bodyTextSize returns the most frequently used effective font size.
+func bodyTextSize(pp []*taggedPage) float64 {
+	m := map[float64]int{}
+	for _, p := range pp {
+		for _, b := range p.bb {
+			if b.s == "P" {
+				m[math.Round(b.size*2)/2] += b.shows
+			}
+		}
+	}
+
+	var size float64
+	n := 0
+	for k, v := range m {
+		if v > n || v == n && k < size {
+			size, n = k, v
+		}
+	}
+
+	return size
+}
+
+// classifyHeadings promotes text blocks using fonts significantly larger than body text to headings.
+func classifyHeadings(pp []*taggedPage) {
+	body := bodyTextSize(pp)
+	if body == 0 {
+		return
+	}
+	for _, p := range pp {
+		for i, b := range p.bb {
+			if b.s != "P" {
+				continue
+			}
+			switch {
+			case b.size >= headingH1Ratio*body:
+				p.bb[i].s = "H1"
+			case b.size >= headingH2Ratio*body:
+				p.bb[i].s = "H2"
+			}
+		}
+	}
+}
+
+func markedContentOps(ops []model.ContentOp, bb []tagBlock) []model.ContentOp {
+	ops1 := make([]model.ContentOp, 0, len(ops)+2*len(bb))
+	mcid := 0
+	for _, b := range bb {
+		if b.s != "" {
+			d := types.Dict{"MCID": types.Integer(mcid)}
+			ops1 = append(ops1, model.ContentOp{Operator: "BDC", Operands: []types.Object{types.Name(b.s), d}})
+			mcid++
+		}
+		ops1 = append(ops1, ops[b.from:b.thru+1]...)
+		if b.s != "" {
+			ops1 = append(ops1, model.ContentOp{Operator: "EMC"})
+		}
+	}
+	return ops1
+}
+
+func loadTaggedPage(ctx *model.Context, pageNr int) (*taggedPage, error) {
+	d, ir, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	var xObjs types.Dict
+	if inhPAttrs.Resources != nil {
+		if xObjs, err = ctx.DereferenceDict(inhPAttrs.Resources["XObject"]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &taggedPage{pageNr: pageNr, d: d, ir: ir, ops: ops, bb: tagBlocks(ctx, ops, xObjs)}, nil
+}
+
+func (p *taggedPage) tag(ctx *model.Context, docIndRef types.IndirectRef, key int) (types.Array, types.Array, error) {
+	var kids, parents types.Array
+
+	mcid := 0
+	for _, b := range p.bb {
+		if b.s == "" {
+			continue
+		}
+		ir, err := ctx.IndRefForNewObject(types.Dict{
+			"Type": types.Name("StructElem"),
+			"S":    types.Name(b.s),
+			"P":    docIndRef,
+			"Pg":   *p.ir,
+			"K":    types.Integer(mcid),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		kids = append(kids, *ir)
+		parents = append(parents, *ir)
+		mcid++
+	}
+
+	if mcid == 0 {
+		return nil, nil, nil
+	}
+
+	sd, _ := ctx.NewStreamDictForBuf(model.ContentOpsBytes(markedContentOps(p.ops, p.bb)))
+	if err := sd.Encode(); err != nil {
+		return nil, nil, err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.d["Contents"] = *ir
+	p.d["StructParents"] = types.Integer(key)
+
+	return kids, parents, nil
+}
+
+func (p *taggedPage) stats() string {
+	var pars, heads, figs int
+	for _, b := range p.bb {
+		switch b.s {
+		case "P":
+			pars++
+		case "H1", "H2":
+			heads++
+		case "Figure":
+			figs++
+		}
+	}
+	return fmt.Sprintf("page %d: tagged %d paragraphs, %d headings, %d figures", p.pageNr, pars, heads, figs)
+}
+
+// AutoTag generates a basic structure tree for an untagged document.
+// Text objects become paragraphs or headings depending on their font size relative to body text
+// and images become figures, each one wrapped in a marked content sequence of the page content.
+// All struct elements are children of a single Document element in content stream order.
+// Content within form XObjects and annotation appearances remains untagged.
+// The result is a list of pages tagged.
+func AutoTag(ctx *model.Context) ([]string, error) {
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, found := root.Find("StructTreeRoot"); found {
+		return nil, ErrAlreadyTagged
+	}
+
+	var pp []*taggedPage
+	for i := 1; i <= ctx.PageCount; i++ {
+		p, err := loadTaggedPage(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			pp = append(pp, p)
+		}
+	}
+
+	classifyHeadings(pp)
+
+	rootIndRef, err := ctx.IndRefForNewObject(types.Dict{"Type": types.Name("StructTreeRoot")})
+	if err != nil {
+		return nil, err
+	}
+
+	docIndRef, err := ctx.IndRefForNewObject(types.Dict{
+		"Type": types.Name("StructElem"),
+		"S":    types.Name("Document"),
+		"P":    *rootIndRef,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		kids, nums types.Array
+		ss         []string
+	)
+
+	for _, p := range pp {
+		key := len(nums) / 2
+		kk, parents, err := p.tag(ctx, *docIndRef, key)
+		if err != nil {
+			return nil, err
+		}
+		if kk == nil {
+			continue
+		}
+		kids = append(kids, kk...)
+		nums = append(nums, types.Integer(key), parents)
+		ss = append(ss, p.stats())
+	}
+
+	doc, _ := ctx.DereferenceDict(*docIndRef)
+	doc["K"] = kids
+
+	parentTreeIndRef, err := ctx.IndRefForNewObject(types.Dict{"Nums": nums})
+	if err != nil {
+		return nil, err
+	}
+
+	d, _ := ctx.DereferenceDict(*rootIndRef)
+	d["K"] = *docIndRef
+	d["ParentTree"] = *parentTreeIndRef
+	d["ParentTreeNextKey"] = types.Integer(len(nums) / 2)
+
+	root["StructTreeRoot"] = *rootIndRef
+	root["MarkInfo"] = types.Dict{"Marked": types.Boolean(true)}
+
+	return ss, nil
+}
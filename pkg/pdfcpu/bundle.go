@@ -0,0 +1,354 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// The layout of an export bundle.
+const (
+	BundleFile      = "bundle.json"
+	BundleImageDir  = "images"
+	BundleFontDir   = "fonts"
+	BundleXMPFile   = "metadata.xml"
+	bundleDirPerm   = 0755
+	bundleIndention = "\t"
+)
+
+// BundleMetadata represents the document metadata of an export bundle.
+type BundleMetadata struct {
+	Title        string            `json:"title,omitempty"`
+	Author       string            `json:"author,omitempty"`
+	Subject      string            `json:"subject,omitempty"`
+	Keywords     string            `json:"keywords,omitempty"`
+	Creator      string            `json:"creator,omitempty"`
+	Producer     string            `json:"producer,omitempty"`
+	CreationDate string            `json:"creationDate,omitempty"`
+	ModDate      string            `json:"modDate,omitempty"`
+	Properties   map[string]string `json:"properties,omitempty"` // custom info dict entries
+	XMP          string            `json:"xmp,omitempty"`        // path of the XMP document metadata relative to the bundle
+}
+
+// BundleAnnotation represents an annotation of an export bundle page.
+type BundleAnnotation struct {
+	Type     string     `json:"type"`
+	ObjNr    int        `json:"objNr,omitempty"`
+	ID       string     `json:"id,omitempty"`
+	Rect     [4]float64 `json:"rect"`
+	Contents string     `json:"contents,omitempty"`
+	URI      string     `json:"uri,omitempty"`
+}
+
+// BundleImage represents an image used by an export bundle page.
+type BundleImage struct {
+	ObjNr  int    `json:"objNr"`
+	Name   string `json:"name"` // resource name
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	File   string `json:"file,omitempty"` // path relative to the bundle, empty for images not extractable
+}
+
+// BundleFont represents a font used by an export bundle.
+type BundleFont struct {
+	ObjNr int    `json:"objNr"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	File  string `json:"file,omitempty"` // path relative to the bundle, empty for fonts not embedded or not extractable
+}
+
+// BundlePage represents a page of an export bundle.
+type BundlePage struct {
+	Page        int                `json:"page"`
+	MediaBox    [4]float64         `json:"mediaBox"`
+	Rotate      int                `json:"rotate,omitempty"`
+	Blocks      []TextBlock        `json:"blocks"`
+	Annotations []BundleAnnotation `json:"annotations"`
+	Images      []BundleImage      `json:"images"`
+	Fonts       []int              `json:"fonts"` // obj#s of the fonts used
+}
+
+// Bundle is the structured representation of a document written by ExportBundle.
+type Bundle struct {
+	Version   string         `json:"version"`
+	PageCount int            `json:"pageCount"`
+	Metadata  BundleMetadata `json:"metadata"`
+	Pages     []BundlePage   `json:"pages"`
+	Fonts     []BundleFont   `json:"fonts"`
+}
+
+type bundleWriter struct {
+	ctx      *model.Context
+	dir      string
+	imgFiles map[int]string // bundle paths by image obj#
+	fonts    map[int]*BundleFont
+}
+
+func (bw *bundleWriter) write(rel string, bb []byte) error {
+	path := filepath.Join(bw.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), bundleDirPerm); err != nil {
+		return err
+	}
+	log.CLI.Printf("writing %s\n", path)
+	return os.WriteFile(path, bb, 0644)
+}
+
+func (bw *bundleWriter) metadata() (BundleMetadata, error) {
+	ctx := bw.ctx
+
+	md := BundleMetadata{
+		Title:        ctx.Title,
+		Author:       ctx.Author,
+		Subject:      ctx.Subject,
+		Keywords:     ctx.Keywords,
+		Creator:      ctx.Creator,
+		Producer:     ctx.Producer,
+		CreationDate: ctx.CreationDate,
+		ModDate:      ctx.ModDate,
+	}
+	if len(ctx.Properties) > 0 {
+		md.Properties = ctx.Properties
+	}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		return md, err
+	}
+	sd, err := catalogMetadata(ctx, root)
+	if err != nil || sd == nil {
+		return md, err
+	}
+	if err := bw.write(BundleXMPFile, sd.Content); err != nil {
+		return md, err
+	}
+	md.XMP = BundleXMPFile
+
+	return md, nil
+}
+
+func (bw *bundleWriter) annotations(d types.Dict) ([]BundleAnnotation, error) {
+	aa := []BundleAnnotation{}
+
+	annots, err := bw.ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return aa, err
+	}
+
+	for _, o := range annots {
+		d1, err := bw.ctx.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d1 == nil || d1.Subtype() == nil {
+			continue
+		}
+
+		a := BundleAnnotation{Type: *d1.Subtype()}
+		if ir, ok := o.(types.IndirectRef); ok {
+			a.ObjNr = ir.ObjectNumber.Value()
+		}
+		if nm := d1.StringOrHexLiteralEntry("NM"); nm != nil {
+			a.ID = *nm
+		}
+		if r := normalizedAnnotRect(bw.ctx.XRefTable, d1); r != nil {
+			a.Rect = [4]float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y}
+		}
+		if a.Contents, err = contentsText(bw.ctx.XRefTable, d1); err != nil {
+			return nil, err
+		}
+		if act, err := bw.ctx.DereferenceDict(d1["A"]); err == nil && act != nil {
+			if s := act.NameEntry("S"); s != nil && *s == "URI" {
+				if o, err := bw.ctx.Dereference(act["URI"]); err == nil && o != nil {
+					a.URI, _ = model.Text(o)
+				}
+			}
+		}
+
+		aa = append(aa, a)
+	}
+
+	return aa, nil
+}
+
+func (bw *bundleWriter) pageImages(pageNr int) ([]BundleImage, error) {
+	m, err := ExtractPageImages(bw.ctx, pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	objNrs := make([]int, 0, len(m))
+	for objNr := range m {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	ii := []BundleImage{}
+
+	for _, objNr := range objNrs {
+		img := m[objNr]
+		bi := BundleImage{ObjNr: objNr, Name: img.Name, Width: img.Width, Height: img.Height}
+		if imgObj, ok := bw.ctx.Optimize.ImageObjects[objNr]; ok && imgObj.ImageDict != nil {
+			// Only image stubs come with dimensions.
+			if w := imgObj.ImageDict.IntEntry("Width"); w != nil {
+				bi.Width = *w
+			}
+			if h := imgObj.ImageDict.IntEntry("Height"); h != nil {
+				bi.Height = *h
+			}
+		}
+
+		rel, ok := bw.imgFiles[objNr]
+		if !ok && img.Reader != nil {
+			var buf bytes.Buffer
+			if _, err := buf.ReadFrom(img); err != nil {
+				return nil, err
+			}
+			rel = filepath.Join(BundleImageDir, fmt.Sprintf("%d_%s.%s", objNr, img.Name, img.FileType))
+			if err := bw.write(rel, buf.Bytes()); err != nil {
+				return nil, err
+			}
+			bw.imgFiles[objNr] = rel
+		}
+		bi.File = filepath.ToSlash(rel)
+
+		ii = append(ii, bi)
+	}
+
+	return ii, nil
+}
+
+func (bw *bundleWriter) addFont(objNr int, fo *model.FontObject) error {
+	if _, ok := bw.fonts[objNr]; ok {
+		return nil
+	}
+
+	bf := &BundleFont{ObjNr: objNr, Name: fo.FontName, Type: fo.SubType()}
+	bw.fonts[objNr] = bf
+
+	f, err := ExtractFont(bw.ctx, *fo, objNr)
+	if err != nil || f == nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(f); err != nil {
+		return err
+	}
+	rel := filepath.Join(BundleFontDir, fmt.Sprintf("%d_%s.%s", objNr, f.Name, f.Type))
+	if err := bw.write(rel, buf.Bytes()); err != nil {
+		return err
+	}
+	bf.File = filepath.ToSlash(rel)
+
+	return nil
+}
+
+func (bw *bundleWriter) page(pageNr int, pb model.PageBoundaries) (*BundlePage, error) {
+	d, _, _, err := bw.ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &BundlePage{Page: pageNr, Rotate: pb.Rot}
+	if r := pb.MediaBox(); r != nil {
+		p.MediaBox = [4]float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y}
+	}
+
+	if p.Blocks, err = PageParagraphs(bw.ctx, pageNr); err != nil {
+		return nil, err
+	}
+	if p.Blocks == nil {
+		p.Blocks = []TextBlock{}
+	}
+
+	if d != nil {
+		if p.Annotations, err = bw.annotations(d); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Images, err = bw.pageImages(pageNr); err != nil {
+		return nil, err
+	}
+
+	p.Fonts = FontObjNrs(bw.ctx, pageNr)
+	sort.Ints(p.Fonts)
+	for _, objNr := range p.Fonts {
+		if err := bw.addFont(objNr, bw.ctx.Optimize.FontObjects[objNr]); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// ExportBundle writes a structured representation of ctx into dir for indexing documents without keeping the PDF:
+// bundle.json describing metadata, pages including their text organized into paragraphs, annotations, images and fonts
+// along with the XMP document metadata, all images and all embedded fonts as separate files,
+// see BundleXMPFile, BundleImageDir and BundleFontDir.
+// Requires an optimized context.
+func ExportBundle(ctx *model.Context, dir string) error {
+	if err := ctx.EnsurePageCount(); err != nil {
+		return err
+	}
+
+	pbs, err := ctx.PageBoundaries()
+	if err != nil {
+		return err
+	}
+
+	bw := &bundleWriter{ctx: ctx, dir: dir, imgFiles: map[int]string{}, fonts: map[int]*BundleFont{}}
+
+	b := Bundle{Version: ctx.VersionString(), PageCount: ctx.PageCount, Pages: []BundlePage{}, Fonts: []BundleFont{}}
+
+	if b.Metadata, err = bw.metadata(); err != nil {
+		return err
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		p, err := bw.page(i, pbs[i-1])
+		if err != nil {
+			return err
+		}
+		b.Pages = append(b.Pages, *p)
+	}
+
+	objNrs := make([]int, 0, len(bw.fonts))
+	for objNr := range bw.fonts {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+	for _, objNr := range objNrs {
+		b.Fonts = append(b.Fonts, *bw.fonts[objNr])
+	}
+
+	bb, err := json.MarshalIndent(b, "", bundleIndention)
+	if err != nil {
+		return err
+	}
+
+	return bw.write(BundleFile, bb)
+}
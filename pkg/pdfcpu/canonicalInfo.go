@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// infoDictKeys are the standard document info dict entries in the order of the PDF specification, see 14.3.3.
+var infoDictKeys = []string{"Title", "Author", "Subject", "Keywords", "Creator", "Producer", "CreationDate", "ModDate", "Trapped"}
+
+// canonicalInfoDictKeys returns the keys of d starting with the standard entries in spec order followed by all custom entries sorted.
+func canonicalInfoDictKeys(d types.Dict) []string {
+	keys := make([]string, 0, len(d))
+
+	std := map[string]bool{}
+	for _, k := range infoDictKeys {
+		std[k] = true
+		if _, found := d[k]; found {
+			keys = append(keys, k)
+		}
+	}
+
+	var custom []string
+	for k := range d {
+		if !std[k] {
+			custom = append(custom, k)
+		}
+	}
+	sort.Strings(custom)
+
+	return append(keys, custom...)
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalizeInfoDict turns all text entries of d into direct objects with leading and trailing whitespace trimmed.
+func canonicalizeInfoDict(xRefTable *model.XRefTable, d types.Dict) error {
+	for k, v := range d {
+		o, err := xRefTable.Dereference(v)
+		if err != nil {
+			return err
+		}
+
+		switch o.(type) {
+		case types.StringLiteral, types.HexLiteral:
+		default:
+			continue
+		}
+
+		s, err := model.Text(o)
+		if err != nil {
+			return err
+		}
+
+		s1 := strings.TrimSpace(s)
+		if s1 == s && o == v {
+			continue
+		}
+
+		var s2 *string
+		if isASCII(s1) {
+			s2, err = types.Escape(s1)
+		} else {
+			s2, err = types.EscapeUTF16String(s1)
+		}
+		if err != nil {
+			return err
+		}
+
+		d[k] = types.StringLiteral(*s2)
+	}
+
+	return nil
+}
+
+// canonicalInfoDictString returns the PDF representation of d with its keys in canonical order.
+func canonicalInfoDictString(d types.Dict) string {
+	var sb strings.Builder
+	sb.WriteString("<<")
+	for _, k := range canonicalInfoDictKeys(d) {
+		// Render single entries the way they are written as part of any dict.
+		s := types.Dict{k: d[k]}.PDFString()
+		sb.WriteString(s[2 : len(s)-2])
+	}
+	sb.WriteString(">>")
+	return sb.String()
+}
+
+// writeCanonicalInfoDict writes the document info dict d with text values trimmed and its keys in canonical order
+// for reproducible metadata comparison.
+func writeCanonicalInfoDict(ctx *model.Context, ir types.IndirectRef, d types.Dict) error {
+	objNr := int(ir.ObjectNumber)
+	genNr := int(ir.GenerationNumber)
+
+	if ctx.Write.HasWriteOffset(objNr) {
+		log.Write.Printf("writeCanonicalInfoDict end: object #%d already written.\n", objNr)
+		return nil
+	}
+
+	if err := canonicalizeInfoDict(ctx.XRefTable, d); err != nil {
+		return err
+	}
+
+	if ctx.EncKey != nil {
+		if _, err := encryptDeepObject(d, objNr, genNr, ctx.EncKey, ctx.AES4Strings, ctx.E.R); err != nil {
+			return err
+		}
+	}
+
+	if err := writeObject(ctx, objNr, genNr, canonicalInfoDictString(d)); err != nil {
+		return err
+	}
+
+	for _, v := range d {
+		if _, _, err := writeDeepObject(ctx, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// ContentKind classifies the content of a page.
+type ContentKind int
+
+// The kinds of page content.
+const (
+	ContentEmpty     ContentKind = iota // neither text nor images, eg. blank pages or vector graphics only
+	ContentImageOnly                    // images without text
+	ContentText                         // text without images
+	ContentMixed                        // text and images
+)
+
+func (k ContentKind) String() string {
+	switch k {
+	case ContentImageOnly:
+		return "image only"
+	case ContentText:
+		return "text"
+	case ContentMixed:
+		return "text and images"
+	}
+	return "empty"
+}
+
+type contentKindScan struct {
+	xRefTable *model.XRefTable
+	visited   map[int]bool
+	text      bool
+	images    bool
+}
+
+func (sc *contentKindScan) scanXObject(ir types.IndirectRef, resDict types.Dict) error {
+	objNr := ir.ObjectNumber.Value()
+	if sc.visited[objNr] {
+		return nil
+	}
+	sc.visited[objNr] = true
+
+	sd, _, err := sc.xRefTable.DereferenceStreamDict(ir)
+	if err != nil || sd == nil {
+		return err
+	}
+
+	st := sd.Subtype()
+	if st == nil {
+		return nil
+	}
+	if *st == "Image" {
+		sc.images = true
+		return nil
+	}
+	if *st != "Form" {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if d, err := sc.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resDict = d
+	}
+
+	return sc.scan(sd.Content, resDict)
+}
+
+func (sc *contentKindScan) scan(content []byte, resDict types.Dict) error {
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps(content)
+
+	if err := shownText(sc.xRefTable, resDict, ops, nil, func(font *types.IndirectRef, bb []byte) {
+		if len(bb) > 0 {
+			sc.text = true
+		}
+	}, nil); err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		if op.Operator == "BI" {
+			sc.images = true
+			continue
+		}
+		if op.Operator != "Do" || len(op.Operands) != 1 {
+			continue
+		}
+		n, ok := op.Operands[0].(types.Name)
+		if !ok {
+			continue
+		}
+		ir, err := resourceIndRef(sc.xRefTable, resDict, "XObject", n.Value())
+		if err != nil {
+			return err
+		}
+		if ir == nil {
+			continue
+		}
+		if err := sc.scanXObject(*ir, resDict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PageContentKind classifies the content of page pageNr by the presence of text showing operators and images.
+// Page content and form XObjects get scanned, annotations are ignored.
+// Text rendered invisible like an OCR text layer counts as text.
+func PageContentKind(ctx *model.Context, pageNr int) (ContentKind, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return ContentEmpty, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		if err == model.ErrNoContent {
+			return ContentEmpty, nil
+		}
+		return ContentEmpty, err
+	}
+
+	sc := &contentKindScan{xRefTable: ctx.XRefTable, visited: map[int]bool{}}
+	if err := sc.scan(bb, inhPAttrs.Resources); err != nil {
+		return ContentEmpty, err
+	}
+
+	switch {
+	case sc.text && sc.images:
+		return ContentMixed, nil
+	case sc.text:
+		return ContentText, nil
+	case sc.images:
+		return ContentImageOnly, nil
+	}
+
+	return ContentEmpty, nil
+}
+
+// IsScannedDocument returns true if ctx looks like a scan wrapped into PDF without OCR,
+// ie. no page has extractable text and at least one page is image only,
+// along with the fraction of image only pages as confidence.
+// Pages failing to classify count as not being image only.
+func IsScannedDocument(ctx *model.Context) (bool, float64) {
+	if ctx.PageCount == 0 {
+		return false, 0
+	}
+
+	var imageOnly int
+	scanned := true
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		k, err := PageContentKind(ctx, i)
+		if err != nil {
+			log.Info.Printf("pdfcpu: IsScannedDocument: page %d: %v\n", i, err)
+			continue
+		}
+		switch k {
+		case ContentImageOnly:
+			imageOnly++
+		case ContentText, ContentMixed:
+			scanned = false
+		}
+	}
+
+	return scanned && imageOnly > 0, float64(imageOnly) / float64(ctx.PageCount)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// deprecatedContentOperators contains operators no longer part of the standard, see 32000-1:2008 8.8.2.
+var deprecatedContentOperators = map[string]bool{"PS": true}
+
+// operatorUsage counts the occurrences of a non standard operator.
+type operatorUsage struct {
+	count       int // total occurrences
+	compatCount int // occurrences within a BX/EX compatibility section
+}
+
+type operatorScan struct {
+	xRefTable *model.XRefTable
+	usage     map[string]*operatorUsage
+	visited   map[int]bool
+}
+
+func (sc *operatorScan) scanFormXObject(ir types.IndirectRef, resDict types.Dict) error {
+	objNr := ir.ObjectNumber.Value()
+	if sc.visited[objNr] {
+		return nil
+	}
+	sc.visited[objNr] = true
+
+	sd, _, err := sc.xRefTable.DereferenceStreamDict(ir)
+	if err != nil || sd == nil {
+		return err
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return nil
+	}
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if d, err := sc.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resDict = d
+	}
+
+	return sc.scan(sd.Content, resDict)
+}
+
+func (sc *operatorScan) scan(content []byte, resDict types.Dict) error {
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps(content)
+
+	compat := 0
+
+	for _, op := range ops {
+
+		switch op.Operator {
+		case "BX":
+			compat++
+		case "EX":
+			if compat > 0 {
+				compat--
+			}
+		}
+
+		if !contentOperators[op.Operator] {
+			u := sc.usage[op.Operator]
+			if u == nil {
+				u = &operatorUsage{}
+				sc.usage[op.Operator] = u
+			}
+			u.count++
+			if compat > 0 {
+				u.compatCount++
+			}
+			continue
+		}
+
+		if op.Operator != "Do" || len(op.Operands) != 1 {
+			continue
+		}
+		n, ok := op.Operands[0].(types.Name)
+		if !ok {
+			continue
+		}
+		ir, err := resourceIndRef(sc.xRefTable, resDict, "XObject", n.Value())
+		if err != nil {
+			return err
+		}
+		if ir == nil {
+			continue
+		}
+		if err := sc.scanFormXObject(*ir, resDict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sc *operatorScan) scanAnnots(pageDict types.Dict) error {
+	annots, err := sc.xRefTable.DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range annots {
+		d, err := sc.xRefTable.DereferenceDict(o)
+		if err != nil || d == nil {
+			continue
+		}
+		ap, err := sc.xRefTable.DereferenceDict(d["AP"])
+		if err != nil || ap == nil {
+			continue
+		}
+		for _, k := range []string{"N", "R", "D"} {
+			o, found := ap.Find(k)
+			if !found {
+				continue
+			}
+			if ir, ok := o.(types.IndirectRef); ok {
+				if d, err := sc.xRefTable.DereferenceDict(ir); err != nil || d == nil {
+					if err := sc.scanFormXObject(ir, nil); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			// Appearance subdictionary.
+			d, err := sc.xRefTable.DereferenceDict(o)
+			if err != nil || d == nil {
+				continue
+			}
+			for _, o := range d {
+				if ir, ok := o.(types.IndirectRef); ok {
+					if err := sc.scanFormXObject(ir, nil); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u operatorUsage) String() string {
+	s := fmt.Sprintf("%d", u.count)
+	if u.compatCount > 0 {
+		s += fmt.Sprintf(" (%d within BX/EX)", u.compatCount)
+	}
+	return s
+}
+
+// NonStandardOperators returns a report of all operators used by the selected pages
+// which are deprecated or not part of the standard set of 32000-1:2008 Annex A.2 like vendor specific extensions.
+// Such operators don't render in all viewers.
+// Page content, form XObjects and annotation appearances get scanned.
+// Viewers shall ignore unknown operators within a BX/EX compatibility section, occurrences within such sections get reported separately.
+func NonStandardOperators(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	var ss []string
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		if selectedPages != nil && !selectedPages[pageNr] {
+			continue
+		}
+
+		d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+
+		sc := &operatorScan{xRefTable: ctx.XRefTable, usage: map[string]*operatorUsage{}, visited: map[int]bool{}}
+
+		bb, err := ctx.PageContent(d)
+		if err != nil && err != model.ErrNoContent {
+			return nil, err
+		}
+		if err := sc.scan(bb, inhPAttrs.Resources); err != nil {
+			return nil, err
+		}
+		if err := sc.scanAnnots(d); err != nil {
+			return nil, err
+		}
+
+		ops := make([]string, 0, len(sc.usage))
+		for op := range sc.usage {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+
+		for _, op := range ops {
+			s := op
+			if deprecatedContentOperators[op] {
+				s += " (deprecated)"
+			}
+			ss = append(ss, fmt.Sprintf("page %d: %s: %s", pageNr, s, *sc.usage[op]))
+		}
+	}
+
+	return ss, nil
+}
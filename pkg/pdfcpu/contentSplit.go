@@ -0,0 +1,373 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func contentWhitespace(c byte) bool {
+	return c == 0x00 || c == 0x09 || c == 0x0A || c == 0x0C || c == 0x0D || c == 0x20
+}
+
+func contentDelimiter(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+func contentRegular(c byte) bool {
+	return !contentWhitespace(c) && !contentDelimiter(c)
+}
+
+const (
+	contentNormal = iota
+	contentComment
+	contentLiteral
+	contentHex
+	contentInlineImage
+)
+
+// contentScanner keeps track of the lexical state of a content stream scanned in consecutive parts.
+type contentScanner struct {
+	i     int // offset of the next byte to scan
+	state int
+	depth int // literal string nesting level
+	start int // offset of the current string
+	tok   int // offset of the current regular token, -1 if none
+	bi    int // offset of the current inline image
+}
+
+func newContentScanner() *contentScanner {
+	return &contentScanner{tok: -1}
+}
+
+// scan continues scanning bb, the content scanned so far extended by the next part.
+// Unless final scanning pauses in front of bytes whose meaning depends on the following part.
+func (s *contentScanner) scan(bb []byte, final bool) {
+	for ; s.i < len(bb); s.i++ {
+		i, c := s.i, bb[s.i]
+
+		switch s.state {
+
+		case contentComment:
+			if c == 0x0A || c == 0x0D {
+				s.state = contentNormal
+			}
+
+		case contentLiteral:
+			switch c {
+			case '\\':
+				if i+1 == len(bb) && !final {
+					return
+				}
+				s.i++
+			case '(':
+				s.depth++
+			case ')':
+				if s.depth--; s.depth == 0 {
+					s.state = contentNormal
+				}
+			}
+
+		case contentHex:
+			if c == '>' {
+				s.state = contentNormal
+			}
+
+		case contentInlineImage:
+			if c != 'E' {
+				continue
+			}
+			if i+2 >= len(bb) && !final {
+				return
+			}
+			if i+1 < len(bb) && bb[i+1] == 'I' && contentWhitespace(bb[i-1]) &&
+				(i+2 == len(bb) || !contentRegular(bb[i+2])) {
+				s.state = contentNormal
+				s.i++
+			}
+
+		case contentNormal:
+			if contentRegular(c) {
+				if s.tok < 0 {
+					s.tok = i
+				}
+				continue
+			}
+			if c == '<' && i+1 == len(bb) && !final {
+				return
+			}
+			if s.tok >= 0 {
+				switch string(bb[s.tok:i]) {
+				case "BI":
+					s.bi = s.tok
+				case "ID":
+					if contentWhitespace(c) {
+						s.state = contentInlineImage
+					}
+				}
+				s.tok = -1
+			}
+			switch c {
+			case '%':
+				s.state = contentComment
+			case '(':
+				s.state, s.depth, s.start = contentLiteral, 1, i
+			case '<':
+				if i+1 < len(bb) && bb[i+1] == '<' {
+					s.i++
+				} else {
+					s.state, s.start = contentHex, i
+				}
+			}
+		}
+	}
+}
+
+// tail returns the offset of the token bb ends with
+// and whether bb ends within a string, a hex string or an inline image.
+// The offset is len(bb) if bb ends with whitespace, a delimiter or a comment.
+// s remains untouched.
+func (s contentScanner) tail(bb []byte) (int, bool) {
+	s.scan(bb, true)
+
+	switch s.state {
+	case contentLiteral, contentHex:
+		return s.start, true
+	case contentInlineImage:
+		return s.bi, true
+	}
+
+	if s.tok >= 0 {
+		return s.tok, false
+	}
+
+	return len(bb), false
+}
+
+// contentTail scans the content stream part bb and returns the offset of the token bb ends with
+// and whether bb ends within a string, a hex string or an inline image.
+// The offset is len(bb) if bb ends with whitespace, a delimiter or a comment.
+func contentTail(bb []byte) (int, bool) {
+	return newContentScanner().tail(bb)
+}
+
+// contentOperators contains all content stream operators, see 32000-1:2008 Annex A.2.
+var contentOperators = map[string]bool{}
+
+func init() {
+	for _, op := range []string{
+		"b", "B", "b*", "B*", "BDC", "BI", "BMC", "BT", "BX", "c", "cm", "CS", "cs", "d", "d0", "d1", "Do", "DP",
+		"EI", "EMC", "ET", "EX", "f", "F", "f*", "G", "g", "gs", "h", "i", "ID", "j", "J", "K", "k", "l", "m", "M",
+		"MP", "n", "q", "Q", "re", "RG", "rg", "ri", "s", "S", "SC", "sc", "SCN", "scn", "sh", "T*", "Tc", "Td", "TD",
+		"Tf", "Tj", "TJ", "TL", "Tm", "Tr", "Ts", "Tw", "Tz", "v", "w", "W", "W*", "y", "'", "\"",
+	} {
+		contentOperators[op] = true
+	}
+}
+
+// contentToken returns true if s is a number, a keyword or an operator.
+func contentToken(s string) bool {
+	if contentOperators[s] || s == "true" || s == "false" || s == "null" {
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// splitWithinToken returns true if the content stream parts bb and next are divided within a lexical token.
+// A division between regular characters is considered to be within a token only
+// if one of both sides does not make up a valid token on its own, like in "T" "j".
+func splitWithinToken(bb, next []byte) bool {
+	return newContentScanner().splitWithinToken(bb, next)
+}
+
+// splitWithinToken returns true if bb, the content scanned so far, and the following part next are divided within a lexical token.
+// Scanning continues where the last call left off.
+func (s *contentScanner) splitWithinToken(bb, next []byte) bool {
+	s.scan(bb, false)
+	i, open := s.tail(bb)
+	if open {
+		return true
+	}
+	if i == len(bb) || len(next) == 0 || !contentRegular(next[0]) {
+		return false
+	}
+	if i > 0 && bb[i-1] == '/' {
+		// Name
+		return false
+	}
+	j := 0
+	for j < len(next) && contentRegular(next[j]) {
+		j++
+	}
+	return !contentToken(string(bb[i:])) || !contentToken(string(next[:j]))
+}
+
+func pageContentParts(ctx *model.Context, d types.Dict) (types.Array, [][]byte, error) {
+	o, err := ctx.Dereference(d["Contents"])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a, ok := o.(types.Array)
+	if !ok || len(a) < 2 {
+		return nil, nil, nil
+	}
+
+	bbs := make([][]byte, len(a))
+
+	for i, o := range a {
+		sd, _, err := ctx.DereferenceStreamDict(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sd == nil {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			if err == filter.ErrUnsupportedFilter {
+				return nil, nil, nil
+			}
+			return nil, nil, err
+		}
+		bbs[i] = sd.Content
+	}
+
+	return a, bbs, nil
+}
+
+// splitContentBoundaries returns the indices of all content stream parts divided from their successor within a lexical token.
+func splitContentBoundaries(bbs [][]byte) []int {
+	var ii []int
+	var buf []byte
+	s := newContentScanner()
+	for i := 0; i < len(bbs)-1; i++ {
+		buf = append(buf, bbs[i]...)
+		if len(buf) > 0 && s.splitWithinToken(buf, bbs[i+1]) {
+			ii = append(ii, i)
+		}
+	}
+	return ii
+}
+
+// SplitContentPages returns all pages with content stream parts divided within a lexical token.
+// The result is a list describing each affected part boundary.
+func SplitContentPages(ctx *model.Context) ([]string, error) {
+	var ss []string
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		_, bbs, err := pageContentParts(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range splitContentBoundaries(bbs) {
+			ss = append(ss, fmt.Sprintf("page %d: content parts %d and %d split within a token", i, j+1, j+2))
+		}
+	}
+
+	return ss, nil
+}
+
+func resplitPageContent(ctx *model.Context, d types.Dict) (int, error) {
+	a, bbs, err := pageContentParts(ctx, d)
+	if err != nil || a == nil {
+		return 0, err
+	}
+
+	var (
+		a1        types.Array
+		n         int
+		prepended bool
+	)
+
+	for i, bb := range bbs {
+		changed := prepended
+		prepended = false
+
+		if i < len(bbs)-1 && len(bb) > 0 && splitWithinToken(bb, bbs[i+1]) {
+			// Move the incomplete token on to the next part.
+			j, _ := contentTail(bb)
+			bbs[i+1] = append(append([]byte{}, bb[j:]...), bbs[i+1]...)
+			bb, changed, prepended = bb[:j], true, true
+			n++
+		}
+
+		if !changed {
+			a1 = append(a1, a[i])
+			continue
+		}
+
+		if len(bb) == 0 {
+			continue
+		}
+
+		sd, _ := ctx.NewStreamDictForBuf(bb)
+		if err := sd.Encode(); err != nil {
+			return 0, err
+		}
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			return 0, err
+		}
+		a1 = append(a1, *ir)
+	}
+
+	if n > 0 {
+		d["Contents"] = a1
+	}
+
+	return n, nil
+}
+
+// ResplitContentPages moves content stream part boundaries divided within a lexical token to the preceding token boundary.
+// Affected parts are replaced by new content streams since parts may be shared with other pages.
+// The result is a list of all pages fixed.
+func ResplitContentPages(ctx *model.Context) ([]string, error) {
+	var ss []string
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		d, _, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil {
+			continue
+		}
+		n, err := resplitPageContent(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			ss = append(ss, fmt.Sprintf("page %d: resplit %d content part boundaries", i, n))
+		}
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// CostEstimate holds metrics predictive of the processing cost of a PDF file.
+type CostEstimate struct {
+	FileSize    int64 // in bytes
+	PageCount   int
+	ObjectCount int   // number of objects in use
+	ImageCount  int   // number of image XObjects
+	ImagePixels int64 // total pixel count over all image XObjects
+	Encrypted   bool
+}
+
+// List returns a report of ce.
+func (ce CostEstimate) List() []string {
+	return []string{
+		fmt.Sprintf("file size:    %s (%d bytes)", types.ByteSize(ce.FileSize), ce.FileSize),
+		fmt.Sprintf("pages:        %d", ce.PageCount),
+		fmt.Sprintf("objects:      %d", ce.ObjectCount),
+		fmt.Sprintf("images:       %d", ce.ImageCount),
+		fmt.Sprintf("image pixels: %d", ce.ImagePixels),
+		fmt.Sprintf("encrypted:    %t", ce.Encrypted),
+	}
+}
+
+func peekInt(ctx *model.Context, o types.Object) int64 {
+	if ir, ok := o.(types.IndirectRef); ok {
+		if o, ok = peekObject(ctx, ir); !ok {
+			return 0
+		}
+	}
+	switch o := o.(type) {
+	case types.Integer:
+		return int64(o.Value())
+	case types.Float:
+		return int64(o.Value())
+	}
+	return 0
+}
+
+// EstimateCost returns metrics predictive of the processing cost of rs.
+// rs gets scanned without loading any stream content, decrypting or validating.
+// Stream objects never live in object streams, so all image XObjects are taken into account even for encrypted files.
+// Only the page count of an encrypted file using object streams needs a full Read.
+func EstimateCost(rs io.ReadSeeker, conf *model.Configuration) (*CostEstimate, error) {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	ctx, err := model.NewContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = readXRefTable(ctx); err != nil {
+		return nil, errors.Wrap(err, "EstimateCost: xRefTable failed")
+	}
+
+	ce := &CostEstimate{FileSize: ctx.Read.FileSize, Encrypted: ctx.Encrypt != nil}
+
+	for objNr, entry := range ctx.Table {
+		if objNr == 0 || entry.Free {
+			continue
+		}
+		ce.ObjectCount++
+		if entry.Compressed || entry.Offset == nil || entry.Generation == nil {
+			continue
+		}
+		o, err := ParseObject(ctx, *entry.Offset, objNr, *entry.Generation)
+		if err != nil {
+			continue
+		}
+		sd, ok := o.(types.StreamDict)
+		if !ok || sd.Subtype() == nil || *sd.Subtype() != "Image" {
+			continue
+		}
+		ce.ImageCount++
+		ce.ImagePixels += peekInt(ctx, sd.Dict["Width"]) * peekInt(ctx, sd.Dict["Height"])
+	}
+
+	if pageCount, ok := peekPageCount(ctx); ok {
+		ce.PageCount = pageCount
+		return ce, nil
+	}
+
+	if _, err = rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if ce.PageCount, err = ReadPageCount(rs, conf); err != nil {
+		return nil, err
+	}
+
+	return ce, nil
+}
@@ -49,59 +49,130 @@ var (
 
 	// Needed permission bits for pdfcpu commands.
 	perm = map[model.CommandMode]struct{ extract, modify int }{
-		model.VALIDATE:                {0, 0},
-		model.INFO:                    {0, 0},
-		model.OPTIMIZE:                {0, 0},
-		model.SPLIT:                   {1, 0},
-		model.MERGECREATE:             {0, 0},
-		model.MERGEAPPEND:             {0, 0},
-		model.EXTRACTIMAGES:           {1, 0},
-		model.EXTRACTFONTS:            {1, 0},
-		model.EXTRACTPAGES:            {1, 0},
-		model.EXTRACTCONTENT:          {1, 0},
-		model.EXTRACTMETADATA:         {1, 0},
-		model.TRIM:                    {0, 1},
-		model.LISTATTACHMENTS:         {0, 0},
-		model.EXTRACTATTACHMENTS:      {1, 0},
-		model.ADDATTACHMENTS:          {0, 1},
-		model.ADDATTACHMENTSPORTFOLIO: {0, 1},
-		model.REMOVEATTACHMENTS:       {0, 1},
-		model.LISTPERMISSIONS:         {0, 0},
-		model.SETPERMISSIONS:          {0, 0},
-		model.ADDWATERMARKS:           {0, 1},
-		model.REMOVEWATERMARKS:        {0, 1},
-		model.IMPORTIMAGES:            {0, 1},
-		model.INSERTPAGESBEFORE:       {0, 1},
-		model.INSERTPAGESAFTER:        {0, 1},
-		model.REMOVEPAGES:             {0, 1},
-		model.LISTKEYWORDS:            {0, 0},
-		model.ADDKEYWORDS:             {0, 1},
-		model.REMOVEKEYWORDS:          {0, 1},
-		model.LISTPROPERTIES:          {0, 0},
-		model.ADDPROPERTIES:           {0, 1},
-		model.REMOVEPROPERTIES:        {0, 1},
-		model.COLLECT:                 {1, 0},
-		model.CROP:                    {0, 1},
-		model.LISTBOXES:               {0, 0},
-		model.ADDBOXES:                {0, 1},
-		model.REMOVEBOXES:             {0, 1},
-		model.LISTANNOTATIONS:         {0, 1},
-		model.ADDANNOTATIONS:          {0, 1},
-		model.REMOVEANNOTATIONS:       {0, 1},
-		model.ROTATE:                  {0, 1},
-		model.NUP:                     {0, 1},
-		model.BOOKLET:                 {0, 1},
-		model.ADDBOOKMARKS:            {0, 1},
-		model.LISTIMAGES:              {0, 1},
-		model.CREATE:                  {0, 0},
-		model.DUMP:                    {0, 1},
-		model.LISTFORMFIELDS:          {0, 0},
-		model.REMOVEFORMFIELDS:        {0, 1},
-		model.LOCKFORMFIELDS:          {0, 1},
-		model.UNLOCKFORMFIELDS:        {0, 1},
-		model.RESETFORMFIELDS:         {0, 1},
-		model.EXPORTFORMFIELDS:        {0, 1},
-		model.FILLFORMFIELDS:          {0, 1},
+		model.VALIDATE:                   {0, 0},
+		model.INFO:                       {0, 0},
+		model.OPTIMIZE:                   {0, 0},
+		model.SPLIT:                      {1, 0},
+		model.MERGECREATE:                {0, 0},
+		model.MERGEAPPEND:                {0, 0},
+		model.EXTRACTIMAGES:              {1, 0},
+		model.EXTRACTFONTS:               {1, 0},
+		model.EXTRACTPAGES:               {1, 0},
+		model.EXTRACTCONTENT:             {1, 0},
+		model.EXTRACTMETADATA:            {1, 0},
+		model.TRIM:                       {0, 1},
+		model.LISTATTACHMENTS:            {0, 0},
+		model.EXTRACTATTACHMENTS:         {1, 0},
+		model.ADDATTACHMENTS:             {0, 1},
+		model.ADDATTACHMENTSPORTFOLIO:    {0, 1},
+		model.REMOVEATTACHMENTS:          {0, 1},
+		model.LISTPERMISSIONS:            {0, 0},
+		model.SETPERMISSIONS:             {0, 0},
+		model.ADDWATERMARKS:              {0, 1},
+		model.REMOVEWATERMARKS:           {0, 1},
+		model.IMPORTIMAGES:               {0, 1},
+		model.INSERTPAGESBEFORE:          {0, 1},
+		model.INSERTPAGESAFTER:           {0, 1},
+		model.REMOVEPAGES:                {0, 1},
+		model.LISTKEYWORDS:               {0, 0},
+		model.ADDKEYWORDS:                {0, 1},
+		model.REMOVEKEYWORDS:             {0, 1},
+		model.LISTPROPERTIES:             {0, 0},
+		model.ADDPROPERTIES:              {0, 1},
+		model.REMOVEPROPERTIES:           {0, 1},
+		model.COLLECT:                    {1, 0},
+		model.CROP:                       {0, 1},
+		model.LISTBOXES:                  {0, 0},
+		model.ADDBOXES:                   {0, 1},
+		model.REMOVEBOXES:                {0, 1},
+		model.LISTANNOTATIONS:            {0, 1},
+		model.ADDANNOTATIONS:             {0, 1},
+		model.REMOVEANNOTATIONS:          {0, 1},
+		model.ROTATE:                     {0, 1},
+		model.NUP:                        {0, 1},
+		model.BOOKLET:                    {0, 1},
+		model.ADDBOOKMARKS:               {0, 1},
+		model.LISTIMAGES:                 {0, 1},
+		model.CREATE:                     {0, 0},
+		model.DUMP:                       {0, 1},
+		model.LISTFORMFIELDS:             {0, 0},
+		model.REMOVEFORMFIELDS:           {0, 1},
+		model.LOCKFORMFIELDS:             {0, 1},
+		model.UNLOCKFORMFIELDS:           {0, 1},
+		model.RESETFORMFIELDS:            {0, 1},
+		model.EXPORTFORMFIELDS:           {0, 1},
+		model.FILLFORMFIELDS:             {0, 1},
+		model.ADDSIGNATUREFIELD:          {0, 1},
+		model.UNSIGN:                     {0, 1},
+		model.EMBEDFONTS:                 {0, 1},
+		model.LISTMISSINGGLYPHS:          {0, 0},
+		model.REPAIRTOUNICODE:            {0, 1},
+		model.MERGESELECTED:              {0, 0},
+		model.ASSEMBLE:                   {0, 0},
+		model.REPAIRPAGES:                {0, 1},
+		model.RECOLORWATERMARKS:          {0, 1},
+		model.EXTRACTEPS:                 {1, 0},
+		model.NORMALIZEROTATION:          {0, 1},
+		model.CLEARRESTRICTIONS:          {0, 0},
+		model.HASHPAGES:                  {0, 0},
+		model.PREPARETIMESTAMP:           {0, 1},
+		model.EMBEDTIMESTAMP:             {0, 1},
+		model.ADDDSS:                     {0, 1},
+		model.LISTDEGENERATEPAGES:        {0, 0},
+		model.REMOVEDEGENERATEPAGES:      {0, 1},
+		model.LISTOVERSIZEDPAGES:         {0, 0},
+		model.CLAMPPAGES:                 {0, 1},
+		model.LISTVIEWERPREFERENCES:      {0, 0},
+		model.SETPRINTPREFERENCES:        {0, 1},
+		model.FILLTEMPLATE:               {0, 1},
+		model.LISTDOCUMENTFONTS:          {0, 0},
+		model.CONVERTTYPE3FONTS:          {0, 1},
+		model.LISTSPLITCONTENT:           {0, 0},
+		model.RESPLITCONTENT:             {0, 1},
+		model.LISTBROKENDESTINATIONS:     {0, 0},
+		model.FIXBROKENDESTINATIONS:      {0, 1},
+		model.EXTRACTICCPROFILES:         {1, 0},
+		model.LISTREADINGORDERISSUES:     {0, 0},
+		model.AUTOTAG:                    {0, 1},
+		model.EXTRACTPARAGRAPHS:          {1, 0},
+		model.REDACT:                     {0, 1},
+		model.PIXELATE:                   {0, 1},
+		model.SETSTRUCTLANG:              {0, 1},
+		model.LISTPAGEROTATIONS:          {0, 0},
+		model.FIXPAGEROTATIONS:           {0, 1},
+		model.GARBAGECOLLECT:             {0, 1},
+		model.LISTDANGLINGREFS:           {0, 0},
+		model.NULLDANGLINGREFS:           {0, 1},
+		model.TRUNCATEPAGES:              {0, 1},
+		model.COUNTPAGES:                 {0, 0},
+		model.ESTIMATECOST:               {0, 0},
+		model.SHARERESOURCES:             {0, 1},
+		model.LISTOPERATORS:              {0, 0},
+		model.LISTARTIFACTCANDIDATES:     {0, 0},
+		model.TAGARTIFACTS:               {0, 1},
+		model.LISTFORMDRISSUES:           {0, 0},
+		model.REPAIRFORMDR:               {0, 1},
+		model.REGENERATEAPPEARANCES:      {0, 1},
+		model.LISTCALCORDER:              {0, 1},
+		model.SETCALCORDER:               {0, 1},
+		model.LISTFIELDACTIONS:           {0, 1},
+		model.FLATTENFORM:                {0, 1},
+		model.STRIPSTRUCTURE:             {0, 1},
+		model.CHECKIMAGES:                {0, 1},
+		model.CHECKANNOTATIONS:           {0, 1},
+		model.CLAMPANNOTATIONS:           {0, 1},
+		model.EXPORTFIELDPOSITIONS:       {0, 1},
+		model.CHECKSCANNED:               {0, 0},
+		model.TILEPAGE:                   {0, 1},
+		model.LISTRESOLUTIONS:            {0, 0},
+		model.SETXMPTHUMBNAIL:            {0, 1},
+		model.LISTVERSIONFEATURES:        {0, 0},
+		model.ADDPAGENUMBERS:             {0, 1},
+		model.LISTDUPLICATEANNOTATIONS:   {0, 0},
+		model.REMOVEDUPLICATEANNOTATIONS: {0, 1},
+		model.INLINENAMEDDESTINATIONS:    {0, 1},
+		model.NAMEEXPLICITDESTINATIONS:   {0, 1},
+		model.EXTRACTBUNDLE:              {1, 0},
 	}
 
 	ErrUnknownEncryption = errors.New("pdfcpu: PDF 2.0 encryption not supported")
@@ -585,6 +656,39 @@ func addPermissionsToInfoDigest(ctx *model.Context, ss *[]string) {
 	}
 }
 
+// Restrictions returns a list of the user access permissions denied.
+func Restrictions(ctx *model.Context) (list []string) {
+
+	if ctx.E == nil {
+		return nil
+	}
+
+	p := ctx.E.P
+
+	for _, r := range []struct {
+		bit  int
+		desc string
+	}{
+		{0x0004, "print"},
+		{0x0008, "modify"},
+		{0x0010, "extract"},
+		{0x0020, "add or modify annotations"},
+		{0x0100, "fill in form fields"},
+		{0x0200, "extract for accessibility"},
+		{0x0400, "assemble"},
+		{0x0800, "print high-level"},
+	} {
+		if ctx.E.R < 3 && r.bit > 0x0020 {
+			break
+		}
+		if p&r.bit == 0 {
+			list = append(list, r.desc)
+		}
+	}
+
+	return list
+}
+
 // Permissions returns a list of set permissions.
 func Permissions(ctx *model.Context) (list []string) {
 
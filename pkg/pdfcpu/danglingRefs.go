@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// DanglingReference is an indirect reference to a missing or free object.
+type DanglingReference struct {
+	ObjNr int               // object containing the reference, 0 for the trailer
+	Path  string            // location of the reference within the object, eg. /Resources/Font/F1 or /Kids[2]
+	Ref   types.IndirectRef // the dangling reference
+	Free  bool              // the object referenced is free as opposed to missing
+}
+
+func (dr DanglingReference) String() string {
+	s := "missing"
+	if dr.Free {
+		s = "free"
+	}
+	loc := "trailer"
+	if dr.ObjNr > 0 {
+		loc = fmt.Sprintf("obj#%d", dr.ObjNr)
+	}
+	return fmt.Sprintf("%s %s: %s points to %s object", loc, dr.Path, dr.Ref, s)
+}
+
+// refVisitor gets called for each indirect reference found at path.
+// set replaces the reference within its container.
+type refVisitor func(ir types.IndirectRef, path string, set func(o types.Object))
+
+// walkRefs calls visit for each indirect reference within o without following references.
+func walkRefs(o types.Object, path string, set func(o types.Object), visit refVisitor) {
+	switch o := o.(type) {
+
+	case types.IndirectRef:
+		visit(o, path, set)
+
+	case types.Dict:
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			k := k
+			walkRefs(o[k], path+"/"+k, func(v types.Object) { o[k] = v }, visit)
+		}
+
+	case types.StreamDict:
+		walkRefs(o.Dict, path, nil, visit)
+
+	case types.Array:
+		for i, v := range o {
+			i := i
+			walkRefs(v, fmt.Sprintf("%s[%d]", path, i), func(v types.Object) { o[i] = v }, visit)
+		}
+	}
+}
+
+// dangling returns true if ir points to a missing or free object.
+func dangling(xRefTable *model.XRefTable, ir types.IndirectRef) (bool, bool) {
+	entry, found := xRefTable.FindTableEntryForIndRef(&ir)
+	if !found {
+		return true, false
+	}
+	if entry.Free {
+		return true, true
+	}
+	return entry.Object == nil, false
+}
+
+// walkAllRefs calls visit for each indirect reference of the trailer and all objects in use.
+func walkAllRefs(ctx *model.Context, visit func(objNr int, ir types.IndirectRef, path string, set func(o types.Object))) {
+	trailer := []struct {
+		key string
+		ir  **types.IndirectRef
+	}{
+		{"Root", &ctx.Root},
+		{"Info", &ctx.Info},
+		{"Encrypt", &ctx.Encrypt},
+	}
+	for _, e := range trailer {
+		if *e.ir == nil {
+			continue
+		}
+		ir := e.ir
+		visit(0, **ir, "/"+e.key, func(o types.Object) { *ir = nil })
+	}
+
+	objNrs := make([]int, 0, len(ctx.Table))
+	for objNr := range ctx.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		entry := ctx.Table[objNr]
+		if objNr == 0 || entry.Free || entry.Object == nil {
+			continue
+		}
+		if ctx.Read.IsObjectStreamObject(objNr) || ctx.Read.IsXRefStreamObject(objNr) {
+			continue
+		}
+		objNr := objNr
+		walkRefs(entry.Object, "", func(o types.Object) { entry.Object = o }, func(ir types.IndirectRef, path string, set func(o types.Object)) {
+			visit(objNr, ir, path, set)
+		})
+	}
+}
+
+// DanglingReferences returns all indirect references of the trailer and all objects in use pointing to a missing or free object.
+func DanglingReferences(ctx *model.Context) []DanglingReference {
+	var drs []DanglingReference
+
+	walkAllRefs(ctx, func(objNr int, ir types.IndirectRef, path string, set func(o types.Object)) {
+		if ok, free := dangling(ctx.XRefTable, ir); ok {
+			drs = append(drs, DanglingReference{ObjNr: objNr, Path: path, Ref: ir, Free: free})
+		}
+	})
+
+	return drs
+}
+
+// NullDanglingReferences replaces all indirect references of the trailer and all objects in use pointing to a missing or free object with null.
+// Per spec a reference to a nonexistent object is treated as a reference to the null object,
+// so this makes the referencing objects accessible to validation and processing.
+// A dangling trailer /Info gets dropped, a dangling /Root is left alone since there is no document without a catalog.
+// The result is a list of all references replaced.
+func NullDanglingReferences(ctx *model.Context) []DanglingReference {
+	var drs []DanglingReference
+
+	walkAllRefs(ctx, func(objNr int, ir types.IndirectRef, path string, set func(o types.Object)) {
+		if objNr == 0 && path == "/Root" {
+			return
+		}
+		if ok, free := dangling(ctx.XRefTable, ir); ok {
+			dr := DanglingReference{ObjNr: objNr, Path: path, Ref: ir, Free: free}
+			log.Info.Printf("pdfcpu: replacing with null: %s\n", dr)
+			set(nil)
+			drs = append(drs, dr)
+		}
+	})
+
+	return drs
+}
@@ -0,0 +1,462 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// destRef is a reference to a destination from an outline item, a link, the open action or a named destination entry.
+type destRef struct {
+	where    string
+	name     string // The key of a named destination entry.
+	dest     types.Object
+	fallback int                // Page number for redirecting destinations to unresolvable page objects.
+	set      func(types.Object) // Replaces the destination.
+	remove   func() error       // Removes the destination including its referrer if applicable.
+}
+
+type destChecker struct {
+	ctx     *model.Context
+	pageNrs map[int]int // page numbers by page object number
+	pageIRs []types.IndirectRef
+	refs    []destRef
+	finish  []func() error
+}
+
+// pageNumbers returns page numbers by page object number and the page dict indirect references of ctx.
+func pageNumbers(ctx *model.Context) (map[int]int, []types.IndirectRef, error) {
+	m := map[int]int{}
+	var irs []types.IndirectRef
+	for i := 1; i <= ctx.PageCount; i++ {
+		_, ir, _, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ir == nil {
+			ir = types.NewIndirectRef(0, 0)
+		}
+		m[ir.ObjectNumber.Value()] = i
+		irs = append(irs, *ir)
+	}
+	return m, irs, nil
+}
+
+func newDestChecker(ctx *model.Context) (*destChecker, error) {
+	pageNrs, pageIRs, err := pageNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &destChecker{ctx: ctx, pageNrs: pageNrs, pageIRs: pageIRs}, nil
+}
+
+// destination returns the explicit destination array or the name of a named destination for o.
+func (dc *destChecker) destination(o types.Object) (types.Array, *string, error) {
+	o, err := dc.ctx.Dereference(o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if d, ok := o.(types.Dict); ok {
+		if o, err = dc.ctx.Dereference(d["D"]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var s string
+
+	switch o := o.(type) {
+	case types.Array:
+		return o, nil, nil
+	case types.Name:
+		s = o.Value()
+	case types.StringLiteral:
+		s = o.Value()
+	case types.HexLiteral:
+		s = o.Value()
+	default:
+		return nil, nil, nil
+	}
+
+	return nil, &s, nil
+}
+
+// namedDestinationValue returns the value of the named destination name or nil.
+func (dc *destChecker) namedDestinationValue(name string) types.Object {
+	if n := dc.ctx.Names["Dests"]; n != nil {
+		if o, ok := n.Value(name); ok {
+			return o
+		}
+	}
+	d, err := dc.ctx.Catalog()
+	if err != nil {
+		return nil
+	}
+	dests, err := dc.ctx.DereferenceDict(d["Dests"])
+	if err != nil || dests == nil {
+		return nil
+	}
+	o, _ := dests.Find(name)
+	return o
+}
+
+func (dc *destChecker) namedDestination(name string) bool {
+	return dc.namedDestinationValue(name) != nil
+}
+
+// check returns why the destination of r is broken and the page number it may be redirected to.
+func (dc *destChecker) check(r destRef) (string, int, types.Array, error) {
+	a, name, err := dc.destination(r.dest)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	if name != nil {
+		// Broken named destination entries get reported on their own.
+		if dc.namedDestination(*name) {
+			return "", 0, nil, nil
+		}
+		return fmt.Sprintf("unknown named destination %q", *name), r.fallback, nil, nil
+	}
+
+	if len(a) == 0 {
+		return "missing page", r.fallback, a, nil
+	}
+
+	o := a[0]
+	if ir, ok := o.(types.IndirectRef); ok {
+		if _, ok := dc.pageNrs[ir.ObjectNumber.Value()]; ok {
+			return "", 0, a, nil
+		}
+		if o, err = dc.ctx.Dereference(ir); err != nil {
+			return "", 0, nil, err
+		}
+		if o == nil {
+			return fmt.Sprintf("page object obj#%d missing", ir.ObjectNumber.Value()), r.fallback, a, nil
+		}
+		return fmt.Sprintf("obj#%d is no page", ir.ObjectNumber.Value()), r.fallback, a, nil
+	}
+
+	if i, ok := o.(types.Integer); ok {
+		switch {
+		case i.Value() < 0:
+			return fmt.Sprintf("page index %d out of range", i.Value()), 1, a, nil
+		case i.Value() >= dc.ctx.PageCount:
+			return fmt.Sprintf("page index %d out of range", i.Value()), dc.ctx.PageCount, a, nil
+		}
+		return "", 0, a, nil
+	}
+
+	return "missing page", r.fallback, a, nil
+}
+
+func (dc *destChecker) collectNamedDestinations() error {
+	if n := dc.ctx.Names["Dests"]; n != nil {
+		if err := n.Process(dc.ctx.XRefTable, func(xRefTable *model.XRefTable, k string, v types.Object) error {
+			dc.refs = append(dc.refs, destRef{
+				where:    fmt.Sprintf("named destination %q", k),
+				name:     k,
+				dest:     v,
+				fallback: 1,
+				remove: func() error {
+					empty, _, err := xRefTable.Names["Dests"].Remove(xRefTable, k)
+					if err == nil && empty {
+						err = xRefTable.RemoveNameTree("Dests")
+					}
+					return err
+				},
+			})
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	d, err := dc.ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	dests, err := dc.ctx.DereferenceDict(d["Dests"])
+	if err != nil || dests == nil {
+		return err
+	}
+	keys := make([]string, 0, len(dests))
+	for k := range dests {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		k, v := k, dests[k]
+		dc.refs = append(dc.refs, destRef{
+			where:    fmt.Sprintf("named destination %q", k),
+			name:     k,
+			dest:     v,
+			fallback: 1,
+			set:      func(o types.Object) { dests[k] = o },
+			remove:   func() error { dests.Delete(k); return nil },
+		})
+	}
+
+	return nil
+}
+
+// actionDestRef returns a destRef for the destination entry or the GoTo action of d.
+func (dc *destChecker) actionDestRef(d types.Dict, where string, fallback int, remove func() error) (*destRef, error) {
+	if o, found := d.Find("Dest"); found {
+		return &destRef{where: where, dest: o, fallback: fallback, set: func(o types.Object) { d["Dest"] = o }, remove: remove}, nil
+	}
+	act, err := dc.ctx.DereferenceDict(d["A"])
+	if err != nil || act == nil {
+		return nil, err
+	}
+	if s := act.NameEntry("S"); s == nil || *s != "GoTo" {
+		return nil, nil
+	}
+	return &destRef{where: where, dest: act["D"], fallback: fallback, set: func(o types.Object) { act["D"] = o }, remove: remove}, nil
+}
+
+func (dc *destChecker) collectOutlineItems(ir *types.IndirectRef, visited map[int]bool) error {
+	for ir != nil && !visited[ir.ObjectNumber.Value()] {
+		visited[ir.ObjectNumber.Value()] = true
+
+		d, err := dc.ctx.DereferenceDict(*ir)
+		if err != nil || d == nil {
+			return err
+		}
+
+		s, _ := model.Text(d["Title"])
+		remove := func() error {
+			d.Delete("Dest")
+			d.Delete("A")
+			return nil
+		}
+		r, err := dc.actionDestRef(d, fmt.Sprintf("outline item %q", outlineItemTitle(s)), 1, remove)
+		if err != nil {
+			return err
+		}
+		if r != nil {
+			dc.refs = append(dc.refs, *r)
+		}
+
+		if err := dc.collectOutlineItems(d.IndirectRefEntry("First"), visited); err != nil {
+			return err
+		}
+
+		ir = d.IndirectRefEntry("Next")
+	}
+	return nil
+}
+
+func (dc *destChecker) collectLinks(pageNr int) error {
+	d, _, _, err := dc.ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return err
+	}
+
+	annots, err := dc.ctx.DereferenceArray(d["Annots"])
+	if err != nil || annots == nil {
+		return err
+	}
+
+	drop := map[int]bool{}
+
+	for i, o := range annots {
+		annotDict, err := dc.ctx.DereferenceDict(o)
+		if err != nil || annotDict == nil {
+			continue
+		}
+		if st := annotDict.Subtype(); st == nil || *st != "Link" {
+			continue
+		}
+		i := i
+		r, err := dc.actionDestRef(annotDict, fmt.Sprintf("page %d link", pageNr), pageNr, func() error { drop[i] = true; return nil })
+		if err != nil {
+			return err
+		}
+		if r != nil {
+			dc.refs = append(dc.refs, *r)
+		}
+	}
+
+	dc.finish = append(dc.finish, func() error {
+		if len(drop) == 0 {
+			return nil
+		}
+		var a types.Array
+		for i, o := range annots {
+			if !drop[i] {
+				a = append(a, o)
+			}
+		}
+		if len(a) == 0 {
+			d.Delete("Annots")
+			return nil
+		}
+		d.Update("Annots", a)
+		return nil
+	})
+
+	return nil
+}
+
+func (dc *destChecker) collectOpenAction() error {
+	d, err := dc.ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	o, err := dc.ctx.Dereference(d["OpenAction"])
+	if err != nil || o == nil {
+		return err
+	}
+
+	remove := func() error { d.Delete("OpenAction"); return nil }
+
+	if a, ok := o.(types.Array); ok {
+		dc.refs = append(dc.refs, destRef{where: "open action", dest: a, fallback: 1, set: func(o types.Object) { d["OpenAction"] = o }, remove: remove})
+		return nil
+	}
+
+	act, ok := o.(types.Dict)
+	if !ok {
+		return nil
+	}
+	if s := act.NameEntry("S"); s == nil || *s != "GoTo" {
+		return nil
+	}
+	dc.refs = append(dc.refs, destRef{where: "open action", dest: act["D"], fallback: 1, set: func(o types.Object) { act["D"] = o }, remove: remove})
+
+	return nil
+}
+
+func (dc *destChecker) collect() error {
+	// Named destinations first in order to take care of their referrers on removal.
+	if err := dc.collectNamedDestinations(); err != nil {
+		return err
+	}
+
+	ir, err := dc.ctx.Outlines()
+	if err != nil {
+		return err
+	}
+	if ir != nil {
+		d, err := dc.ctx.DereferenceDict(*ir)
+		if err != nil {
+			return err
+		}
+		if d != nil {
+			if err := dc.collectOutlineItems(d.IndirectRefEntry("First"), map[int]bool{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := 1; i <= dc.ctx.PageCount; i++ {
+		if err := dc.collectLinks(i); err != nil {
+			return err
+		}
+	}
+
+	return dc.collectOpenAction()
+}
+
+func brokenDestinations(ctx *model.Context, fix, remove bool) ([]string, error) {
+	if ctx.PageCount == 0 {
+		return nil, nil
+	}
+
+	dc, err := newDestChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dc.collect(); err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, r := range dc.refs {
+		reason, pageNr, a, err := dc.check(r)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+
+		s := fmt.Sprintf("%s: %s", r.where, reason)
+
+		switch {
+
+		case !fix:
+
+		case remove:
+			if err := r.remove(); err != nil {
+				return nil, err
+			}
+			s += ", removed"
+
+		default:
+			ir := dc.pageIRs[pageNr-1]
+			if len(a) == 0 && r.set == nil {
+				if err := r.remove(); err != nil {
+					return nil, err
+				}
+				s += ", removed"
+				break
+			}
+			if len(a) > 0 {
+				a[0] = ir
+			} else {
+				r.set(types.Array{ir, types.Name("Fit")})
+			}
+			s += fmt.Sprintf(", redirected to page %d", pageNr)
+		}
+
+		ss = append(ss, s)
+	}
+
+	if fix {
+		for _, f := range dc.finish {
+			if err := f(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ss, nil
+}
+
+// ListBrokenDestinations returns all destinations of outline items, links, the open action and named destinations
+// pointing to a page which is out of range or unresolvable.
+func ListBrokenDestinations(ctx *model.Context) ([]string, error) {
+	return brokenDestinations(ctx, false, false)
+}
+
+// FixBrokenDestinations removes all broken destinations or redirects them to the nearest valid page.
+// Page indices get clamped to the page range, unresolvable page objects are replaced by the page of the link or page 1.
+// Removing drops links and the open action, outline items remain without destination.
+// The result is a list of all destinations fixed.
+func FixBrokenDestinations(ctx *model.Context, remove bool) ([]string, error) {
+	return brokenDestinations(ctx, true, remove)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// namedDest is a named destination entry either of the Dests name tree or of the Dests dict of the catalog.
+type namedDest struct {
+	name string
+	dict bool // true for entries of the catalog Dests dict (PDF 1.1), which get referred to by name objects.
+}
+
+// referrer returns the object referring to nd.
+func (nd namedDest) referrer() types.Object {
+	if nd.dict {
+		return types.Name(nd.name)
+	}
+	return types.StringLiteral(nd.name)
+}
+
+func collectedDestinations(ctx *model.Context) (*destChecker, error) {
+	if ctx.PageCount == 0 {
+		return nil, nil
+	}
+
+	dc, err := newDestChecker(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dc.collect(); err != nil {
+		return nil, err
+	}
+
+	return dc, nil
+}
+
+// InlineNamedDestinations replaces all named destinations referred to by outline items, links and the open action
+// by the explicit destinations they resolve to.
+// The named destinations remain available for external references like GoToR actions of other documents.
+// The result is a list of all destinations replaced.
+func InlineNamedDestinations(ctx *model.Context) ([]string, error) {
+	dc, err := collectedDestinations(ctx)
+	if err != nil || dc == nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, r := range dc.refs {
+		if r.name != "" || r.set == nil {
+			continue
+		}
+
+		_, name, err := dc.destination(r.dest)
+		if err != nil {
+			return nil, err
+		}
+		if name == nil {
+			continue
+		}
+
+		var a types.Array
+		if o := dc.namedDestinationValue(*name); o != nil {
+			if a, _, err = dc.destination(o); err != nil {
+				return nil, err
+			}
+		}
+		if len(a) == 0 {
+			ss = append(ss, fmt.Sprintf("%s: unknown named destination %q, skipped", r.where, *name))
+			continue
+		}
+
+		// Each referrer gets its own copy.
+		r.set(append(types.Array{}, a...))
+		ss = append(ss, fmt.Sprintf("%s: named destination %q inlined", r.where, *name))
+	}
+
+	if len(ss) > 0 {
+		ctx.EnsureVersionForWriting()
+	}
+
+	return ss, nil
+}
+
+// uniqueDestName returns a name for a destination to page pageNr not in use yet.
+func uniqueDestName(pageNr int, taken map[string]bool) string {
+	s := fmt.Sprintf("page%d", pageNr)
+	for i := 2; taken[s]; i++ {
+		s = fmt.Sprintf("page%d-%d", pageNr, i)
+	}
+	taken[s] = true
+	return s
+}
+
+// NameExplicitDestinations replaces all explicit destinations of outline items, links and the open action
+// by named destinations resolving to them.
+// Existing named destinations get reused for identical explicit destinations,
+// all others get added to the Dests name tree using names like page3 or page3-2 for different destinations to the same page.
+// Broken destinations are skipped.
+// The result is a list of all destinations replaced.
+func NameExplicitDestinations(ctx *model.Context) ([]string, error) {
+	dc, err := collectedDestinations(ctx)
+	if err != nil || dc == nil {
+		return nil, err
+	}
+
+	// Named destinations by the PDF representation of their explicit destination.
+	named := map[string]namedDest{}
+	taken := map[string]bool{}
+
+	for _, r := range dc.refs {
+		if r.name == "" {
+			continue
+		}
+		taken[r.name] = true
+		a, _, err := dc.destination(r.dest)
+		if err != nil {
+			return nil, err
+		}
+		if len(a) == 0 {
+			continue
+		}
+		if _, ok := named[a.PDFString()]; !ok {
+			// Entries of the catalog Dests dict come with a setter, name tree entries don't.
+			named[a.PDFString()] = namedDest{name: r.name, dict: r.set != nil}
+		}
+	}
+
+	var (
+		ss    []string
+		added bool
+	)
+
+	for _, r := range dc.refs {
+		if r.name != "" || r.set == nil {
+			continue
+		}
+
+		reason, _, a, err := dc.check(r)
+		if err != nil {
+			return nil, err
+		}
+		if a == nil {
+			// Named destinations are taken care of already.
+			continue
+		}
+		if reason != "" {
+			ss = append(ss, fmt.Sprintf("%s: %s, skipped", r.where, reason))
+			continue
+		}
+
+		k := a.PDFString()
+		nd, ok := named[k]
+		if !ok {
+			var pageNr int
+			switch o := a[0].(type) {
+			case types.IndirectRef:
+				pageNr = dc.pageNrs[o.ObjectNumber.Value()]
+			case types.Integer:
+				pageNr = o.Value() + 1
+			}
+
+			if err := ctx.LocateNameTree("Dests", true); err != nil {
+				return nil, err
+			}
+			ir, err := ctx.IndRefForNewObject(append(types.Array{}, a...))
+			if err != nil {
+				return nil, err
+			}
+			nd = namedDest{name: uniqueDestName(pageNr, taken)}
+			if err := ctx.Names["Dests"].Add(ctx.XRefTable, nd.name, *ir); err != nil {
+				return nil, err
+			}
+			named[k] = nd
+			added = true
+		}
+
+		r.set(nd.referrer())
+		ss = append(ss, fmt.Sprintf("%s: named %q", r.where, nd.name))
+	}
+
+	if added {
+		// Sync the Dests name tree dict in case ctx gets validated before writing.
+		if err := ctx.BindNameTrees(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ss) > 0 {
+		ctx.EnsureVersionForWriting()
+	}
+
+	return ss, nil
+}
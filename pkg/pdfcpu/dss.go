@@ -0,0 +1,257 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// ValidationData holds DER encoded validation material for the Document Security Store (DSS), see ETSI EN 319 142-1.
+type ValidationData struct {
+	Certs [][]byte // X.509 certificates
+	OCSPs [][]byte // OCSP responses
+	CRLs  [][]byte // certificate revocation lists
+}
+
+type dssWriter struct {
+	ctx  *model.Context
+	seen map[[32]byte]types.IndirectRef // streams already part of the DSS.
+}
+
+// incrDict returns the dict for key of d creating it if missing and marks the object holding it for writing.
+func (dw *dssWriter) incrDict(d types.Dict, holder int, key string) (types.Dict, int, error) {
+	o, found := d.Find(key)
+	if !found {
+		d1 := types.NewDict()
+		d.Insert(key, d1)
+		dw.ctx.Write.IncrementWithObjNr(holder)
+		return d1, holder, nil
+	}
+
+	d1, err := dw.ctx.DereferenceDict(o)
+	if err != nil || d1 == nil {
+		return nil, 0, errors.Errorf("pdfcpu: corrupt /%s", key)
+	}
+
+	if ir, ok := o.(types.IndirectRef); ok {
+		holder = ir.ObjectNumber.Value()
+	}
+	dw.ctx.Write.IncrementWithObjNr(holder)
+
+	return d1, holder, nil
+}
+
+// collect records all streams of the DSS array for key.
+func (dw *dssWriter) collect(d types.Dict, key string) error {
+	a, err := dw.ctx.DereferenceArray(d[key])
+	if err != nil {
+		return err
+	}
+	for _, o := range a {
+		ir, ok := o.(types.IndirectRef)
+		if !ok {
+			continue
+		}
+		sd, _, err := dw.ctx.DereferenceStreamDict(ir)
+		if err != nil || sd == nil {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			continue
+		}
+		dw.seen[sha256.Sum256(sd.Content)] = ir
+	}
+	return nil
+}
+
+// add appends new streams for bbs to the DSS array for key and returns the refs of all streams for bbs.
+func (dw *dssWriter) add(d types.Dict, key string, bbs [][]byte) (types.Array, error) {
+	if len(bbs) == 0 {
+		return nil, nil
+	}
+
+	var irs types.Array
+	var added types.Array
+
+	for _, bb := range bbs {
+		h := sha256.Sum256(bb)
+		if ir, ok := dw.seen[h]; ok {
+			irs = append(irs, ir)
+			continue
+		}
+		ir, err := dw.ctx.StreamDictIndRef(bb)
+		if err != nil {
+			return nil, err
+		}
+		dw.ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+		dw.seen[h] = *ir
+		irs = append(irs, *ir)
+		added = append(added, *ir)
+	}
+
+	if len(added) == 0 {
+		return irs, nil
+	}
+
+	o, found := d.Find(key)
+	if !found {
+		d.Insert(key, added)
+		return irs, nil
+	}
+
+	a, err := dw.ctx.DereferenceArray(o)
+	if err != nil {
+		return nil, err
+	}
+
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		d.Update(key, append(a, added...))
+		return irs, nil
+	}
+
+	entry, ok := dw.ctx.FindTableEntryForIndRef(&ir)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: can't dereference DSS /%s indirect reference(obj#:%d)", key, ir.ObjectNumber)
+	}
+	entry.Object = append(a, added...)
+	dw.ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+
+	return irs, nil
+}
+
+func signatureContents(d types.Dict) []byte {
+	t := d.Type()
+	if t == nil || (*t != "Sig" && *t != "DocTimeStamp") {
+		return nil
+	}
+
+	var bb []byte
+	switch o := d["Contents"].(type) {
+	case types.HexLiteral:
+		bb, _ = o.Bytes()
+	case types.StringLiteral:
+		bb, _ = types.Unescape(o.Value(), false)
+	}
+
+	// Skip signature placeholders.
+	for _, b := range bb {
+		if b != 0 {
+			return bb
+		}
+	}
+
+	return nil
+}
+
+// signatureVRIKeys returns the VRI keys (uppercase hex SHA-1 of /Contents) of all signature values.
+func signatureVRIKeys(ctx *model.Context) []string {
+	var keys []string
+	for _, e := range ctx.Table {
+		if e == nil || e.Free {
+			continue
+		}
+		d, ok := e.Object.(types.Dict)
+		if !ok {
+			continue
+		}
+		if bb := signatureContents(d); bb != nil {
+			keys = append(keys, strings.ToUpper(fmt.Sprintf("%x", sha1.Sum(bb))))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddDSS adds vd to the Document Security Store of ctx.
+// An existing DSS gets extended, validation material already present is skipped.
+// If vri is true a VRI entry referencing vd is added for every signature.
+// All objects touched are marked for writing a PDF increment leaving existing signatures intact.
+func AddDSS(ctx *model.Context, vd ValidationData, vri bool) error {
+	if len(vd.Certs)+len(vd.OCSPs)+len(vd.CRLs) == 0 {
+		return errors.New("pdfcpu: missing validation data")
+	}
+
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	dw := &dssWriter{ctx: ctx, seen: map[[32]byte]types.IndirectRef{}}
+
+	dss, holder, err := dw.incrDict(rootDict, ctx.Root.ObjectNumber.Value(), "DSS")
+	if err != nil {
+		return err
+	}
+
+	for _, k := range []string{"Certs", "OCSPs", "CRLs"} {
+		if err := dw.collect(dss, k); err != nil {
+			return err
+		}
+	}
+
+	certs, err := dw.add(dss, "Certs", vd.Certs)
+	if err != nil {
+		return err
+	}
+	ocsps, err := dw.add(dss, "OCSPs", vd.OCSPs)
+	if err != nil {
+		return err
+	}
+	crls, err := dw.add(dss, "CRLs", vd.CRLs)
+	if err != nil {
+		return err
+	}
+
+	if !vri {
+		return nil
+	}
+
+	keys := signatureVRIKeys(ctx)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	vriDict, _, err := dw.incrDict(dss, holder, "VRI")
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		d := types.NewDict()
+		if len(certs) > 0 {
+			d.Insert("Cert", certs)
+		}
+		if len(ocsps) > 0 {
+			d.Insert("OCSP", ocsps)
+		}
+		if len(crls) > 0 {
+			d.Insert("CRL", crls)
+		}
+		vriDict.Update(k, d)
+	}
+
+	return nil
+}
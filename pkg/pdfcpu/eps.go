@@ -0,0 +1,999 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// maxEPSFormDepth limits the nesting of form XObjects rendered into EPS.
+const maxEPSFormDepth = 16
+
+const epsProlog = `/pdfcpu_dict 16 dict def
+pdfcpu_dict begin
+/pdfcpu_v { currentpoint 6 2 roll curveto } bind def
+/pdfcpu_y { 2 copy curveto } bind def
+/pdfcpu_re { 4 2 roll moveto 1 index 0 rlineto 0 exch rlineto neg 0 rlineto closepath } bind def
+/pdfcpu_reencode { exch findfont dup length dict begin { 1 index /FID ne { def } { pop pop } ifelse } forall
+  dup null ne { /Encoding exch def } { pop } ifelse currentdict end definefont pop } bind def
+/pdfcpu_img { /pdfcpu_mask exch def currentfile /ASCIIHexDecode filter dup /pdfcpu_hex exch def
+  exch { /DCTDecode filter } if 1 index exch /DataSource exch put
+  pdfcpu_mask { imagemask } { image } ifelse pdfcpu_hex flushfile } bind def
+end
+`
+
+// epsColorSpace describes a PDF color space in terms of PostScript Level 2 device color spaces.
+type epsColorSpace struct {
+	n       int            // number of components
+	tint    bool           // Separation or DeviceN rendered as gray
+	lab     bool           // Lab rendered as gray
+	pattern bool           // Pattern rendered as medium gray
+	base    *epsColorSpace // base color space of Indexed
+	hival   int            // Indexed max index
+	lookup  []byte         // Indexed lookup table
+}
+
+var (
+	epsDeviceGray = epsColorSpace{n: 1}
+	epsDeviceRGB  = epsColorSpace{n: 3}
+	epsDeviceCMYK = epsColorSpace{n: 4}
+)
+
+func epsDeviceColor(n int, vv []float64) string {
+	if len(vv) < n {
+		return "0 setgray"
+	}
+	switch n {
+	case 3:
+		return fmt.Sprintf("%s %s %s setrgbcolor", epsNum(vv[0]), epsNum(vv[1]), epsNum(vv[2]))
+	case 4:
+		return fmt.Sprintf("%s %s %s %s setcmykcolor", epsNum(vv[0]), epsNum(vv[1]), epsNum(vv[2]), epsNum(vv[3]))
+	}
+	return epsNum(vv[0]) + " setgray"
+}
+
+// setColor returns the PostScript code setting color vv of cs.
+func (cs epsColorSpace) setColor(vv []float64) string {
+	switch {
+
+	case cs.pattern:
+		return "0.5 setgray"
+
+	case cs.base != nil:
+		if len(vv) == 0 {
+			return "0 setgray"
+		}
+		i := int(vv[0])
+		if i < 0 {
+			i = 0
+		}
+		if i > cs.hival {
+			i = cs.hival
+		}
+		n := cs.base.n
+		if (i+1)*n > len(cs.lookup) {
+			return "0 setgray"
+		}
+		comps := make([]float64, n)
+		for j := 0; j < n; j++ {
+			comps[j] = float64(cs.lookup[i*n+j]) / 255
+		}
+		return cs.base.setColor(comps)
+
+	case cs.tint:
+		t := 0.
+		for _, v := range vv {
+			t = math.Max(t, v)
+		}
+		return epsNum(1-t) + " setgray"
+
+	case cs.lab:
+		if len(vv) == 0 {
+			return "0 setgray"
+		}
+		return epsNum(vv[0]/100) + " setgray"
+	}
+
+	return epsDeviceColor(cs.n, vv)
+}
+
+// initialColor returns the PostScript code setting the initial color of cs.
+func (cs epsColorSpace) initialColor() string {
+	if cs.tint {
+		return "0 setgray"
+	}
+	if cs.base != nil || cs.pattern {
+		return cs.setColor([]float64{0})
+	}
+	vv := make([]float64, cs.n)
+	if cs.n == 4 {
+		vv[3] = 1
+	}
+	return cs.setColor(vv)
+}
+
+// imageColorSpace returns the PostScript color space used for sample data of cs.
+func (cs epsColorSpace) imageColorSpace() (string, bool) {
+	if cs.base != nil {
+		base, ok := cs.base.imageColorSpace()
+		if !ok || cs.base.base != nil {
+			return "", false
+		}
+		return fmt.Sprintf("[/Indexed %s %d <%s>]", base, cs.hival, hex.EncodeToString(cs.lookup)), true
+	}
+	if cs.pattern || cs.lab || cs.tint && cs.n > 1 {
+		return "", false
+	}
+	switch cs.n {
+	case 3:
+		return "/DeviceRGB", true
+	case 4:
+		return "/DeviceCMYK", true
+	}
+	return "/DeviceGray", true
+}
+
+type epsGState struct {
+	fillCS, strokeCS epsColorSpace
+	fill, stroke     string // PostScript code setting the current colors
+	font             *epsFont
+	fontSize         float64
+	charSpacing      float64
+	wordSpacing      float64
+	hScale           float64
+	leading          float64
+	rise             float64
+	renderMode       int
+}
+
+type epsWriter struct {
+	ctx      *model.Context
+	b        bytes.Buffer     // page content
+	setup    bytes.Buffer     // font definitions
+	fonts    map[int]*epsFont // fonts by font dict object number
+	embedded map[string]bool  // embedded font programs by font name
+	gs       epsGState
+	stack    []epsGState
+	tm       matrix.Matrix
+	tlm      matrix.Matrix
+	clip     string // pending clipping operator
+	depth    int
+}
+
+func newEPSWriter(ctx *model.Context) *epsWriter {
+	return &epsWriter{
+		ctx:      ctx,
+		fonts:    map[int]*epsFont{},
+		embedded: map[string]bool{},
+		gs: epsGState{
+			fillCS:   epsDeviceGray,
+			strokeCS: epsDeviceGray,
+			fill:     "0 setgray",
+			stroke:   "0 setgray",
+			hScale:   1,
+		},
+		tm:  matrix.IdentMatrix,
+		tlm: matrix.IdentMatrix,
+	}
+}
+
+func epsNum(f float64) string {
+	f = math.Round(f*10000) / 10000
+	if f == 0 {
+		// Avoid -0
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func epsNums(ff []float64) string {
+	ss := make([]string, len(ff))
+	for i, f := range ff {
+		ss[i] = epsNum(f)
+	}
+	return strings.Join(ss, " ")
+}
+
+func epsMatrix(m matrix.Matrix) string {
+	return fmt.Sprintf("[%s]", epsNums([]float64{m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1]}))
+}
+
+func epsNumber(o types.Object) (float64, bool) {
+	switch o := o.(type) {
+	case types.Integer:
+		return float64(o.Value()), true
+	case types.Float:
+		return o.Value(), true
+	}
+	return 0, false
+}
+
+// numOperands returns the operands of op if op has n numeric operands.
+func numOperands(op model.ContentOp, n int) ([]float64, bool) {
+	if len(op.Operands) != n {
+		return nil, false
+	}
+	ff := make([]float64, n)
+	for i, o := range op.Operands {
+		f, ok := epsNumber(o)
+		if !ok {
+			return nil, false
+		}
+		ff[i] = f
+	}
+	return ff, true
+}
+
+func nameOperand(op model.ContentOp) (string, bool) {
+	if len(op.Operands) != 1 {
+		return "", false
+	}
+	n, ok := op.Operands[0].(types.Name)
+	return n.Value(), ok
+}
+
+func stringBytes(o types.Object) ([]byte, bool) {
+	switch o := o.(type) {
+	case types.StringLiteral:
+		bb, err := types.Unescape(o.Value(), false)
+		return bb, err == nil
+	case types.HexLiteral:
+		bb, err := o.Bytes()
+		return bb, err == nil
+	}
+	return nil, false
+}
+
+func (ew *epsWriter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&ew.b, format, args...)
+}
+
+func (ew *epsWriter) writeHex(bb []byte) {
+	for len(bb) > 0 {
+		n := 32
+		if n > len(bb) {
+			n = len(bb)
+		}
+		ew.b.WriteString(hex.EncodeToString(bb[:n]))
+		ew.b.WriteByte('\n')
+		bb = bb[n:]
+	}
+	ew.b.WriteString(">\n")
+}
+
+func (ew *epsWriter) colorSpace(o types.Object, resDict types.Dict, depth int) epsColorSpace {
+	o, _ = ew.ctx.Dereference(o)
+
+	switch o := o.(type) {
+
+	case types.Name:
+		switch o.Value() {
+		case "DeviceGray", "G", "CalGray":
+			return epsDeviceGray
+		case "DeviceRGB", "RGB", "CalRGB":
+			return epsDeviceRGB
+		case "DeviceCMYK", "CMYK":
+			return epsDeviceCMYK
+		case "Pattern":
+			return epsColorSpace{n: 1, pattern: true}
+		}
+		if resDict != nil && depth < 4 {
+			if d, err := ew.ctx.DereferenceDict(resDict["ColorSpace"]); err == nil && d != nil {
+				if o1, found := d.Find(o.Value()); found {
+					return ew.colorSpace(o1, nil, depth+1)
+				}
+			}
+		}
+
+	case types.Array:
+		if len(o) == 0 {
+			break
+		}
+		n, _ := o[0].(types.Name)
+		switch n {
+		case "DeviceGray", "CalGray", "DeviceRGB", "CalRGB", "DeviceCMYK", "Pattern":
+			return ew.colorSpace(n, nil, depth+1)
+		case "Lab":
+			return epsColorSpace{n: 3, lab: true}
+		case "ICCBased":
+			if len(o) > 1 {
+				if sd, _, err := ew.ctx.DereferenceStreamDict(o[1]); err == nil && sd != nil {
+					if i := sd.IntEntry("N"); i != nil && (*i == 1 || *i == 3 || *i == 4) {
+						return epsColorSpace{n: *i}
+					}
+				}
+			}
+			return epsDeviceRGB
+		case "Indexed", "I":
+			if len(o) == 4 && depth < 4 {
+				return ew.indexedColorSpace(o, resDict, depth)
+			}
+		case "Separation":
+			return epsColorSpace{n: 1, tint: true}
+		case "DeviceN":
+			if len(o) > 1 {
+				if a, err := ew.ctx.DereferenceArray(o[1]); err == nil && len(a) > 0 {
+					return epsColorSpace{n: len(a), tint: true}
+				}
+			}
+			return epsColorSpace{n: 1, tint: true}
+		}
+	}
+
+	return epsDeviceGray
+}
+
+func (ew *epsWriter) indexedColorSpace(a types.Array, resDict types.Dict, depth int) epsColorSpace {
+	base := ew.colorSpace(a[1], resDict, depth+1)
+
+	hival, err := ew.ctx.DereferenceNumber(a[2])
+	if err != nil {
+		return epsDeviceGray
+	}
+
+	var lookup []byte
+	o, _ := ew.ctx.Dereference(a[3])
+	switch o := o.(type) {
+	case types.StreamDict:
+		if err := o.Decode(); err == nil {
+			lookup = o.Content
+		}
+	default:
+		lookup, _ = stringBytes(o)
+	}
+
+	return epsColorSpace{n: 1, base: &base, hival: int(hival), lookup: lookup}
+}
+
+// colorOperands returns the numeric operands of a color operator, skipping a trailing pattern name.
+func colorOperands(op model.ContentOp) ([]float64, bool) {
+	var ff []float64
+	for _, o := range op.Operands {
+		if _, ok := o.(types.Name); ok {
+			return ff, true
+		}
+		f, ok := epsNumber(o)
+		if !ok {
+			return nil, false
+		}
+		ff = append(ff, f)
+	}
+	return ff, false
+}
+
+func (ew *epsWriter) setColor(op model.ContentOp, resDict types.Dict) {
+	fill := op.Operator == strings.ToLower(op.Operator)
+	cs, color := &ew.gs.strokeCS, &ew.gs.stroke
+	if fill {
+		cs, color = &ew.gs.fillCS, &ew.gs.fill
+	}
+
+	switch op.Operator {
+
+	case "g", "G":
+		*cs = epsDeviceGray
+
+	case "rg", "RG":
+		*cs = epsDeviceRGB
+
+	case "k", "K":
+		*cs = epsDeviceCMYK
+
+	case "cs", "CS":
+		if len(op.Operands) == 1 {
+			*cs = ew.colorSpace(op.Operands[0], resDict, 0)
+			*color = cs.initialColor()
+		}
+		return
+	}
+
+	ff, pattern := colorOperands(op)
+	if pattern {
+		*color = "0.5 setgray"
+		return
+	}
+	if ff != nil {
+		*color = cs.setColor(ff)
+	}
+}
+
+func (ew *epsWriter) paint(fill string, stroke bool) {
+	clip := ew.clip
+	ew.clip = ""
+
+	if clip != "" {
+		ew.printf("gsave ")
+	}
+
+	switch {
+	case fill != "" && stroke:
+		ew.printf("gsave %s %s grestore %s stroke", ew.gs.fill, fill, ew.gs.stroke)
+	case fill != "":
+		ew.printf("%s %s", ew.gs.fill, fill)
+	case stroke:
+		ew.printf("%s stroke", ew.gs.stroke)
+	}
+
+	if clip != "" {
+		ew.printf(" grestore %s newpath", clip)
+	}
+
+	ew.printf("\n")
+}
+
+func (ew *epsWriter) endPath() {
+	if ew.clip != "" {
+		ew.printf("%s ", ew.clip)
+		ew.clip = ""
+	}
+	ew.printf("newpath\n")
+}
+
+func (ew *epsWriter) extGState(name string, resDict types.Dict) {
+	ir, err := resourceIndRef(ew.ctx.XRefTable, resDict, "ExtGState", name)
+	if err != nil || ir == nil {
+		return
+	}
+	d, err := ew.ctx.DereferenceDict(*ir)
+	if err != nil || d == nil {
+		return
+	}
+
+	// Transparency is not supported.
+
+	if f, err := ew.ctx.DereferenceNumber(d["LW"]); err == nil {
+		ew.printf("%s setlinewidth\n", epsNum(f))
+	}
+	if f, err := ew.ctx.DereferenceNumber(d["LC"]); err == nil {
+		ew.printf("%d setlinecap\n", int(f))
+	}
+	if f, err := ew.ctx.DereferenceNumber(d["LJ"]); err == nil {
+		ew.printf("%d setlinejoin\n", int(f))
+	}
+	if f, err := ew.ctx.DereferenceNumber(d["ML"]); err == nil {
+		ew.printf("%s setmiterlimit\n", epsNum(f))
+	}
+	if a, err := ew.ctx.DereferenceArray(d["D"]); err == nil && len(a) == 2 {
+		ew.setDash(a[0], a[1])
+	}
+	if a, err := ew.ctx.DereferenceArray(d["Font"]); err == nil && len(a) == 2 {
+		if ir, ok := a[0].(types.IndirectRef); ok {
+			if f, err := ew.ctx.DereferenceNumber(a[1]); err == nil {
+				ew.gs.font = ew.fontForIndRef(ir)
+				ew.gs.fontSize = f
+			}
+		}
+	}
+}
+
+func (ew *epsWriter) setDash(o, phase types.Object) {
+	a, err := ew.ctx.DereferenceArray(o)
+	if err != nil {
+		return
+	}
+	p, err := ew.ctx.DereferenceNumber(phase)
+	if err != nil {
+		return
+	}
+	ff := make([]float64, 0, len(a))
+	for _, o := range a {
+		f, err := ew.ctx.DereferenceNumber(o)
+		if err != nil {
+			return
+		}
+		ff = append(ff, f)
+	}
+	ew.printf("[%s] %s setdash\n", epsNums(ff), epsNum(p))
+}
+
+func (ew *epsWriter) pathOp(op model.ContentOp) {
+	ops := map[string]struct {
+		n    int
+		psOp string
+	}{
+		"m":  {2, "moveto"},
+		"l":  {2, "lineto"},
+		"c":  {6, "curveto"},
+		"v":  {4, "pdfcpu_v"},
+		"y":  {4, "pdfcpu_y"},
+		"re": {4, "pdfcpu_re"},
+	}
+	o := ops[op.Operator]
+	if ff, ok := numOperands(op, o.n); ok {
+		ew.printf("%s %s\n", epsNums(ff), o.psOp)
+	}
+}
+
+func (ew *epsWriter) graphicsStateOp(op model.ContentOp, resDict types.Dict) {
+	switch op.Operator {
+
+	case "q":
+		ew.stack = append(ew.stack, ew.gs)
+		ew.printf("gsave\n")
+
+	case "Q":
+		if len(ew.stack) == 0 {
+			return
+		}
+		ew.gs = ew.stack[len(ew.stack)-1]
+		ew.stack = ew.stack[:len(ew.stack)-1]
+		ew.printf("grestore\n")
+
+	case "cm":
+		if ff, ok := numOperands(op, 6); ok {
+			ew.printf("[%s] concat\n", epsNums(ff))
+		}
+
+	case "w":
+		if ff, ok := numOperands(op, 1); ok {
+			ew.printf("%s setlinewidth\n", epsNum(ff[0]))
+		}
+
+	case "J":
+		if ff, ok := numOperands(op, 1); ok {
+			ew.printf("%d setlinecap\n", int(ff[0]))
+		}
+
+	case "j":
+		if ff, ok := numOperands(op, 1); ok {
+			ew.printf("%d setlinejoin\n", int(ff[0]))
+		}
+
+	case "M":
+		if ff, ok := numOperands(op, 1); ok {
+			ew.printf("%s setmiterlimit\n", epsNum(ff[0]))
+		}
+
+	case "d":
+		if len(op.Operands) == 2 {
+			ew.setDash(op.Operands[0], op.Operands[1])
+		}
+
+	case "gs":
+		if name, ok := nameOperand(op); ok {
+			ew.extGState(name, resDict)
+		}
+	}
+}
+
+func (ew *epsWriter) paintOp(op model.ContentOp) {
+	switch op.Operator {
+	case "S":
+		ew.paint("", true)
+	case "s":
+		ew.printf("closepath ")
+		ew.paint("", true)
+	case "f", "F":
+		ew.paint("fill", false)
+	case "f*":
+		ew.paint("eofill", false)
+	case "B":
+		ew.paint("fill", true)
+	case "B*":
+		ew.paint("eofill", true)
+	case "b":
+		ew.printf("closepath ")
+		ew.paint("fill", true)
+	case "b*":
+		ew.printf("closepath ")
+		ew.paint("eofill", true)
+	case "n":
+		ew.endPath()
+	}
+}
+
+func (ew *epsWriter) renderOp(op model.ContentOp, resDict types.Dict) error {
+	switch op.Operator {
+
+	case "q", "Q", "cm", "w", "J", "j", "M", "d", "gs":
+		ew.graphicsStateOp(op, resDict)
+
+	case "m", "l", "c", "v", "y", "re":
+		ew.pathOp(op)
+
+	case "h":
+		ew.printf("closepath\n")
+
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+		ew.paintOp(op)
+
+	case "W":
+		ew.clip = "clip"
+
+	case "W*":
+		ew.clip = "eoclip"
+
+	case "g", "G", "rg", "RG", "k", "K", "cs", "CS", "sc", "SC", "scn", "SCN":
+		ew.setColor(op, resDict)
+
+	case "BT", "ET", "Tc", "Tw", "Tz", "TL", "Ts", "Tr", "Tf", "Td", "TD", "Tm", "T*", "Tj", "TJ", "'", "\"":
+		ew.textOp(op, resDict)
+
+	case "Do":
+		if name, ok := nameOperand(op); ok {
+			return ew.renderXObject(name, resDict)
+		}
+
+	case "BI":
+		return ew.renderInlineImage(op, resDict)
+
+	case "sh":
+		log.Info.Println("pdfcpu: EPS: skipping shading")
+	}
+
+	return nil
+}
+
+func (ew *epsWriter) render(bb []byte, resDict types.Dict) error {
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		// Render whatever could be parsed.
+		log.Info.Printf("pdfcpu: EPS: %v\n", err)
+	}
+
+	for _, op := range ops {
+		if err := ew.renderOp(op, resDict); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ew *epsWriter) renderForm(sd *types.StreamDict, resDict types.Dict) error {
+	if ew.depth >= maxEPSFormDepth {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		log.Info.Printf("pdfcpu: EPS: skipping form: %v\n", err)
+		return nil
+	}
+
+	if d, err := ew.ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resDict = d
+	}
+
+	gs, stack, tm, tlm := ew.gs, ew.stack, ew.tm, ew.tlm
+	ew.stack = nil
+
+	ew.printf("gsave\n")
+
+	if a := sd.ArrayEntry("Matrix"); len(a) == 6 {
+		if ff, ok := numOperands(model.ContentOp{Operands: a}, 6); ok {
+			ew.printf("[%s] concat\n", epsNums(ff))
+		}
+	}
+
+	if a, err := ew.ctx.DereferenceArray(sd.Dict["BBox"]); err == nil && len(a) == 4 {
+		if r, err := types.RectForArray(a); err == nil && r != nil {
+			ew.printf("%s %s %s %s pdfcpu_re clip newpath\n", epsNum(r.LL.X), epsNum(r.LL.Y), epsNum(r.Width()), epsNum(r.Height()))
+		}
+	}
+
+	ew.depth++
+	err := ew.render(sd.Content, resDict)
+	ew.depth--
+
+	ew.printf("grestore\n")
+
+	ew.gs, ew.stack, ew.tm, ew.tlm = gs, stack, tm, tlm
+
+	return err
+}
+
+func (ew *epsWriter) renderXObject(name string, resDict types.Dict) error {
+	ir, err := resourceIndRef(ew.ctx.XRefTable, resDict, "XObject", name)
+	if err != nil || ir == nil {
+		return err
+	}
+
+	sd, _, err := ew.ctx.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		return err
+	}
+
+	st := sd.Subtype()
+	if st == nil {
+		return nil
+	}
+
+	switch *st {
+	case "Form":
+		return ew.renderForm(sd, resDict)
+	case "Image":
+		ew.renderImage(sd, resDict)
+	}
+
+	return nil
+}
+
+var inlineImageKeys = map[string]string{
+	"W":   "Width",
+	"H":   "Height",
+	"BPC": "BitsPerComponent",
+	"CS":  "ColorSpace",
+	"D":   "Decode",
+	"DP":  "DecodeParms",
+	"F":   "Filter",
+	"IM":  "ImageMask",
+	"I":   "Interpolate",
+}
+
+var inlineImageNames = map[string]string{
+	"AHx":  "ASCIIHexDecode",
+	"A85":  "ASCII85Decode",
+	"LZW":  "LZWDecode",
+	"Fl":   "FlateDecode",
+	"RL":   "RunLengthDecode",
+	"CCF":  "CCITTFaxDecode",
+	"DCT":  "DCTDecode",
+	"G":    "DeviceGray",
+	"RGB":  "DeviceRGB",
+	"CMYK": "DeviceCMYK",
+	"I":    "Indexed",
+}
+
+func expandInlineImageNames(o types.Object) types.Object {
+	switch o := o.(type) {
+	case types.Name:
+		if s, ok := inlineImageNames[o.Value()]; ok {
+			return types.Name(s)
+		}
+	case types.Array:
+		a := make(types.Array, len(o))
+		for i, o1 := range o {
+			a[i] = expandInlineImageNames(o1)
+		}
+		return a
+	}
+	return o
+}
+
+func (ew *epsWriter) renderInlineImage(op model.ContentOp, resDict types.Dict) error {
+	if len(op.Operands) == 0 {
+		return nil
+	}
+	d1, ok := op.Operands[0].(types.Dict)
+	if !ok {
+		return nil
+	}
+
+	d := types.Dict{}
+	for k, v := range d1 {
+		if k1, ok := inlineImageKeys[k]; ok {
+			k = k1
+		}
+		if k == "Filter" || k == "ColorSpace" {
+			v = expandInlineImageNames(v)
+		}
+		d[k] = v
+	}
+
+	sd := types.NewStreamDict(d, 0, nil, nil, nil)
+	sd.Raw = op.Data
+
+	fp, err := pdfFilterPipeline(ew.ctx, d)
+	if err != nil {
+		log.Info.Printf("pdfcpu: EPS: skipping inline image: %v\n", err)
+		return nil
+	}
+	sd.FilterPipeline = fp
+
+	ew.renderImage(&sd, resDict)
+
+	return nil
+}
+
+func (ew *epsWriter) imageDecode(sd *types.StreamDict, cs epsColorSpace, mask bool, bpc int) []float64 {
+	var ff []float64
+	if a, err := ew.ctx.DereferenceArray(sd.Dict["Decode"]); err == nil && a != nil {
+		for _, o := range a {
+			f, err := ew.ctx.DereferenceNumber(o)
+			if err != nil {
+				return nil
+			}
+			ff = append(ff, f)
+		}
+	}
+
+	switch {
+
+	case mask:
+		if len(ff) != 2 {
+			ff = []float64{0, 1}
+		}
+
+	case cs.base != nil:
+		if len(ff) != 2 {
+			ff = []float64{0, float64(int(1)<<uint(bpc) - 1)}
+		}
+
+	default:
+		if len(ff) != 2*cs.n {
+			ff = make([]float64, 2*cs.n)
+			for i := 0; i < cs.n; i++ {
+				ff[2*i+1] = 1
+			}
+		}
+		if cs.tint {
+			// Rendered as inverted gray.
+			ff[0], ff[1] = ff[1], ff[0]
+		}
+	}
+
+	return ff
+}
+
+func (ew *epsWriter) renderImage(sd *types.StreamDict, resDict types.Dict) {
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil || *w <= 0 || *h <= 0 {
+		return
+	}
+
+	mask := false
+	if b := sd.BooleanEntry("ImageMask"); b != nil && *b {
+		mask = true
+	}
+
+	bpc := 1
+	if !mask {
+		bpc = 8
+		if i := sd.IntEntry("BitsPerComponent"); i != nil {
+			bpc = *i
+		}
+	}
+	if bpc > 8 {
+		log.Info.Printf("pdfcpu: EPS: skipping image with %d bits per component\n", bpc)
+		return
+	}
+
+	dct := false
+	for _, f := range sd.FilterPipeline {
+		if f.Name == "JPXDecode" {
+			log.Info.Println("pdfcpu: EPS: skipping JPX image")
+			return
+		}
+		if f.Name == "DCTDecode" {
+			dct = true
+		}
+	}
+
+	cs := epsDeviceGray
+	csName := ""
+	if !mask {
+		cs = ew.colorSpace(sd.Dict["ColorSpace"], resDict, 0)
+		s, ok := cs.imageColorSpace()
+		if !ok {
+			log.Info.Println("pdfcpu: EPS: skipping image using unsupported color space")
+			return
+		}
+		csName = s
+	}
+
+	decode := ew.imageDecode(sd, cs, mask, bpc)
+
+	// DCT encoded data is passed through.
+	if err := sd.Decode(); err != nil {
+		log.Info.Printf("pdfcpu: EPS: skipping image: %v\n", err)
+		return
+	}
+
+	ew.printf("gsave\n")
+	if mask {
+		ew.printf("%s\n", ew.gs.fill)
+	} else {
+		ew.printf("%s setcolorspace\n", csName)
+	}
+
+	interpolate := ""
+	if b := sd.BooleanEntry("Interpolate"); b != nil && *b {
+		interpolate = " /Interpolate true"
+	}
+
+	ew.printf("<< /ImageType 1 /Width %d /Height %d /BitsPerComponent %d /Decode [%s] /ImageMatrix [%d 0 0 %d 0 %d]%s >> %t %t pdfcpu_img\n",
+		*w, *h, bpc, epsNums(decode), *w, -*h, *h, interpolate, dct, mask)
+	ew.writeHex(sd.Content)
+	ew.printf("grestore\n")
+}
+
+func epsRotation(r *types.Rectangle, rot int) (string, float64, float64) {
+	switch rot {
+	case 90:
+		return fmt.Sprintf("[0 -1 1 0 %s %s] concat", epsNum(-r.LL.Y), epsNum(r.UR.X)), r.Height(), r.Width()
+	case 180:
+		return fmt.Sprintf("[-1 0 0 -1 %s %s] concat", epsNum(r.UR.X), epsNum(r.UR.Y)), r.Width(), r.Height()
+	case 270:
+		return fmt.Sprintf("[0 1 -1 0 %s %s] concat", epsNum(r.UR.Y), epsNum(-r.LL.X)), r.Height(), r.Width()
+	}
+	return fmt.Sprintf("%s %s translate", epsNum(-r.LL.X), epsNum(-r.LL.Y)), r.Width(), r.Height()
+}
+
+// ExtractPageEPS renders the content of page pageNr as Encapsulated PostScript.
+// Transparency, shadings and patterns are not supported.
+// Fonts whose font program is not an embedded Type 1 font get substituted by core fonts, each substitution gets logged.
+func ExtractPageEPS(ctx *model.Context, pageNr int) (io.Reader, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || inhPAttrs == nil {
+		return nil, errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	r := inhPAttrs.CropBox
+	if r == nil {
+		r = inhPAttrs.MediaBox
+	}
+	if r == nil {
+		return nil, errors.Errorf("pdfcpu: page %d: missing mediaBox", pageNr)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return nil, err
+	}
+
+	resDict := inhPAttrs.Resources
+	if o, found := d.Find("Resources"); found {
+		if resDict, err = ctx.DereferenceDict(o); err != nil {
+			return nil, err
+		}
+	}
+
+	ew := newEPSWriter(ctx)
+	if err := ew.render(bb, resDict); err != nil {
+		return nil, err
+	}
+
+	rot := (inhPAttrs.Rotate%360 + 360) % 360
+	concat, w, h := epsRotation(r, rot)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&buf, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(w)), int(math.Ceil(h)))
+	fmt.Fprintf(&buf, "%%%%HiResBoundingBox: 0 0 %s %s\n", epsNum(w), epsNum(h))
+	fmt.Fprintf(&buf, "%%%%Creator: %s\n", model.VersionStr)
+	fmt.Fprintf(&buf, "%%%%Title: page %d\n", pageNr)
+	fmt.Fprintf(&buf, "%%%%LanguageLevel: 2\n")
+	fmt.Fprintf(&buf, "%%%%Pages: 1\n")
+	fmt.Fprintf(&buf, "%%%%EndComments\n")
+	fmt.Fprintf(&buf, "%%%%BeginProlog\n%s%%%%EndProlog\n", epsProlog)
+	fmt.Fprintf(&buf, "%%%%BeginSetup\npdfcpu_dict begin\n")
+	buf.Write(ew.setup.Bytes())
+	fmt.Fprintf(&buf, "end\n%%%%EndSetup\n")
+	fmt.Fprintf(&buf, "%%%%Page: 1 1\n")
+	fmt.Fprintf(&buf, "pdfcpu_dict begin save\n")
+	fmt.Fprintf(&buf, "%s\n", concat)
+	fmt.Fprintf(&buf, "%s %s %s %s pdfcpu_re clip newpath\n", epsNum(r.LL.X), epsNum(r.LL.Y), epsNum(r.Width()), epsNum(r.Height()))
+	buf.Write(ew.b.Bytes())
+	fmt.Fprintf(&buf, "restore end\nshowpage\n%%%%Trailer\n%%%%EOF\n")
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
@@ -0,0 +1,599 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/internal/corefont/metrics"
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var type1FontName = regexp.MustCompile(`/FontName\s*/([^\s/\[\]{}()<>%]+)`)
+
+// epsFont is a PostScript font standing in for a PDF font.
+type epsFont struct {
+	name     string          // PostScript font name
+	coreFont string          // substituted core font
+	twoByte  bool            // composite font using 2 byte char codes
+	widths   map[int]float64 // glyph widths in text space units
+	dw       float64         // default glyph width in text space units
+	glyphs   map[byte]string // glyph names by char code
+	recode   map[int]byte    // WinAnsi char codes by char code of composite fonts
+}
+
+func (f *epsFont) width(code int) float64 {
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	if f.coreFont != "" && !f.twoByte {
+		glyphName, ok := f.glyphs[byte(code)]
+		if !ok {
+			return float64(metrics.CoreFontCharWidth(f.coreFont, code)) / 1000
+		}
+		if w, ok := metrics.CoreFontMetrics[f.coreFont].W[glyphName]; ok {
+			return float64(w) / 1000
+		}
+	}
+	return f.dw
+}
+
+// epsCoreFont returns the core font best matching baseFont.
+func epsCoreFont(baseFont string) string {
+	if font.IsCoreFont(baseFont) {
+		return baseFont
+	}
+
+	s := strings.ToLower(baseFont)
+
+	containsAny := func(ss ...string) bool {
+		for _, s1 := range ss {
+			if strings.Contains(s, s1) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if containsAny("symbol") {
+		return "Symbol"
+	}
+	if containsAny("zapf", "dingbat") {
+		return "ZapfDingbats"
+	}
+
+	family := "Helvetica"
+	switch {
+	case containsAny("courier", "mono"):
+		family = "Courier"
+	case containsAny("times", "roman", "georgia", "garamond", "cambria", "book") || containsAny("serif") && !containsAny("sans"):
+		family = "Times"
+	}
+
+	bold := containsAny("bold", "black", "heavy", "demi")
+	italic := containsAny("italic", "oblique")
+
+	if family == "Times" {
+		switch {
+		case bold && italic:
+			return "Times-BoldItalic"
+		case bold:
+			return "Times-Bold"
+		case italic:
+			return "Times-Italic"
+		}
+		return "Times-Roman"
+	}
+
+	switch {
+	case bold && italic:
+		return family + "-BoldOblique"
+	case bold:
+		return family + "-Bold"
+	case italic:
+		return family + "-Oblique"
+	}
+	return family
+}
+
+func epsEncoding(m map[byte]string) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < 256; i++ {
+		if i%16 == 0 {
+			sb.WriteString("\n")
+		}
+		glyphName, ok := m[byte(i)]
+		if !ok || glyphName == "" {
+			glyphName = ".notdef"
+		}
+		sb.WriteString("/" + glyphName + " ")
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+func winAnsiGlyphNames() map[byte]string {
+	m := map[byte]string{}
+	for code, glyphName := range metrics.WinAnsiGlyphMap {
+		if code >= 0 && code <= 0xFF {
+			m[byte(code)] = glyphName
+		}
+	}
+	return m
+}
+
+// embedType1 writes the Type 1 font program sd into the document setup and returns its font name.
+func (ew *epsWriter) embedType1(sd *types.StreamDict) (string, bool) {
+	if err := sd.Decode(); err != nil {
+		return "", false
+	}
+
+	l1, l2 := sd.IntEntry("Length1"), sd.IntEntry("Length2")
+	if l1 == nil || l2 == nil || *l1 < 0 || *l2 < 0 || *l1+*l2 > len(sd.Content) {
+		return "", false
+	}
+
+	clearText := sd.Content[:*l1]
+	m := type1FontName.FindSubmatch(clearText)
+	if m == nil {
+		return "", false
+	}
+	fontName := string(m[1])
+
+	if ew.embedded[fontName] {
+		return fontName, true
+	}
+	ew.embedded[fontName] = true
+
+	fmt.Fprintf(&ew.setup, "%%%%BeginResource: font %s\n", fontName)
+	ew.setup.Write(clearText)
+	ew.setup.WriteString("\n")
+
+	bb := sd.Content[*l1 : *l1+*l2]
+	for len(bb) > 0 {
+		n := 32
+		if n > len(bb) {
+			n = len(bb)
+		}
+		ew.setup.WriteString(hex.EncodeToString(bb[:n]))
+		ew.setup.WriteString("\n")
+		bb = bb[n:]
+	}
+
+	trailer := bytes.TrimSpace(sd.Content[*l1+*l2:])
+	if len(trailer) > 0 {
+		ew.setup.Write(trailer)
+	} else {
+		for i := 0; i < 8; i++ {
+			ew.setup.WriteString(strings.Repeat("0", 64) + "\n")
+		}
+		ew.setup.WriteString("cleartomark")
+	}
+	ew.setup.WriteString("\n%%EndResource\n")
+
+	return fontName, true
+}
+
+// simpleFontWidths adds the glyph widths of the simple font d scaled to text space units to widths.
+func simpleFontWidths(xRefTable *model.XRefTable, d types.Dict, scale float64, widths map[int]float64) {
+	fc, err := xRefTable.DereferenceNumber(d["FirstChar"])
+	if err != nil {
+		return
+	}
+	a, err := xRefTable.DereferenceArray(d["Widths"])
+	if err != nil {
+		return
+	}
+	for i, o := range a {
+		if w, err := xRefTable.DereferenceNumber(o); err == nil {
+			widths[int(fc)+i] = w * scale
+		}
+	}
+}
+
+// logFontSubstitution tells the user about the core font rendering the font objNr.
+func logFontSubstitution(objNr int, baseFont, coreFont string) {
+	log.CLI.Printf("EPS: font obj#%d (%s) is not an embedded Type 1 font, substituting %s\n", objNr, baseFont, coreFont)
+}
+
+func (ew *epsWriter) simpleFont(d types.Dict, objNr int, psName string) *epsFont {
+	f := &epsFont{name: psName, widths: map[int]float64{}}
+
+	scale := .001
+	st := d.Subtype()
+	type3 := st != nil && *st == "Type3"
+	if type3 {
+		if a, err := ew.ctx.DereferenceArray(d["FontMatrix"]); err == nil && len(a) == 6 {
+			if sx, err := ew.ctx.DereferenceNumber(a[0]); err == nil {
+				scale = sx
+			}
+		}
+	}
+
+	simpleFontWidths(ew.ctx.XRefTable, d, scale, f.widths)
+
+	fd, _ := ew.ctx.DereferenceDict(d["FontDescriptor"])
+	if fd != nil {
+		if mw, err := ew.ctx.DereferenceNumber(fd["MissingWidth"]); err == nil {
+			f.dw = mw * scale
+		}
+	}
+
+	f.glyphs, _ = pdffont.SimpleFontGlyphNames(ew.ctx.XRefTable, d)
+
+	src := ""
+	if fd != nil && !type3 {
+		if sd, _, err := ew.ctx.DereferenceStreamDict(fd["FontFile"]); err == nil && sd != nil {
+			if fontName, ok := ew.embedType1(sd); ok {
+				src = fontName
+			}
+		}
+	}
+
+	if src == "" {
+		baseFont := "Helvetica"
+		if fn := d.NameEntry("BaseFont"); fn != nil && !type3 {
+			baseFont = stripSubsetPrefix(*fn)
+		}
+		src = epsCoreFont(baseFont)
+		f.coreFont = src
+		if type3 {
+			logFontSubstitution(objNr, "Type3", src)
+		} else {
+			logFontSubstitution(objNr, baseFont, src)
+		}
+		if src == "Symbol" || src == "ZapfDingbats" {
+			// Use the built-in encoding.
+			f.glyphs = nil
+		}
+	}
+
+	enc := "null"
+	if f.glyphs != nil {
+		enc = epsEncoding(f.glyphs)
+	}
+	fmt.Fprintf(&ew.setup, "/%s /%s %s pdfcpu_reencode\n", psName, src, enc)
+
+	return f
+}
+
+// cidFontWidths adds the glyph widths of the CIDFont d in text space units to widths
+// and sets dw to the default glyph width if present.
+func cidFontWidths(xRefTable *model.XRefTable, d types.Dict, widths map[int]float64, dw *float64) {
+	if w, err := xRefTable.DereferenceNumber(d["DW"]); err == nil {
+		*dw = w / 1000
+	}
+
+	a, err := xRefTable.DereferenceArray(d["W"])
+	if err != nil {
+		return
+	}
+
+	for i := 0; i+1 < len(a); {
+		c1, err := xRefTable.DereferenceNumber(a[i])
+		if err != nil {
+			return
+		}
+		if ww, err := xRefTable.DereferenceArray(a[i+1]); err == nil && ww != nil {
+			for j, o := range ww {
+				if w, err := xRefTable.DereferenceNumber(o); err == nil {
+					widths[int(c1)+j] = w / 1000
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(a) {
+			return
+		}
+		c2, err := xRefTable.DereferenceNumber(a[i+1])
+		if err != nil {
+			return
+		}
+		w, err := xRefTable.DereferenceNumber(a[i+2])
+		if err != nil {
+			return
+		}
+		for c := int(c1); c <= int(c2) && c <= 0xFFFF; c++ {
+			widths[c] = w / 1000
+		}
+		i += 3
+	}
+}
+
+// compositeFont substitutes a core font for the Type0 font d using its ToUnicode CMap.
+func (ew *epsWriter) compositeFont(d types.Dict, objNr int, psName string) *epsFont {
+	f := &epsFont{name: psName, twoByte: true, widths: map[int]float64{}, dw: 1, recode: map[int]byte{}}
+
+	if a, err := ew.ctx.DereferenceArray(d["DescendantFonts"]); err == nil && len(a) > 0 {
+		if df, err := ew.ctx.DereferenceDict(a[0]); err == nil && df != nil {
+			cidFontWidths(ew.ctx.XRefTable, df, f.widths, &f.dw)
+		}
+	}
+
+	if tu, err := pdffont.ToUnicodeMap(ew.ctx.XRefTable, d); err == nil {
+		for code, s := range tu {
+			for _, r := range s {
+				if b, ok := charmap.Windows1252.EncodeRune(r); ok {
+					f.recode[code] = b
+				}
+				break
+			}
+		}
+	}
+
+	baseFont := "Helvetica"
+	if fn := d.NameEntry("BaseFont"); fn != nil {
+		baseFont = stripSubsetPrefix(*fn)
+	}
+	src := epsCoreFont(baseFont)
+	if src == "Symbol" || src == "ZapfDingbats" {
+		src = "Helvetica"
+	}
+	logFontSubstitution(objNr, baseFont, src)
+
+	fmt.Fprintf(&ew.setup, "/%s /%s %s pdfcpu_reencode\n", psName, src, epsEncoding(winAnsiGlyphNames()))
+
+	return f
+}
+
+func (ew *epsWriter) defaultFont() *epsFont {
+	if f, ok := ew.fonts[0]; ok {
+		return f
+	}
+	f := &epsFont{name: "PF0", coreFont: "Helvetica", widths: map[int]float64{}, glyphs: winAnsiGlyphNames()}
+	log.CLI.Println("EPS: unresolvable font, substituting Helvetica")
+	fmt.Fprintf(&ew.setup, "/PF0 /Helvetica %s pdfcpu_reencode\n", epsEncoding(f.glyphs))
+	ew.fonts[0] = f
+	return f
+}
+
+func (ew *epsWriter) fontForIndRef(ir types.IndirectRef) *epsFont {
+	objNr := ir.ObjectNumber.Value()
+	if f, ok := ew.fonts[objNr]; ok {
+		return f
+	}
+
+	d, err := ew.ctx.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return ew.defaultFont()
+	}
+
+	psName := fmt.Sprintf("PF%d", objNr)
+
+	var f *epsFont
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		f = ew.compositeFont(d, objNr, psName)
+	} else {
+		f = ew.simpleFont(d, objNr, psName)
+	}
+
+	ew.fonts[objNr] = f
+	return f
+}
+
+func (ew *epsWriter) fontForName(name string, resDict types.Dict) *epsFont {
+	ir, err := resourceIndRef(ew.ctx.XRefTable, resDict, "Font", name)
+	if err != nil || ir == nil {
+		return ew.defaultFont()
+	}
+	return ew.fontForIndRef(*ir)
+}
+
+func (ew *epsWriter) translateText(tx, ty float64) {
+	ew.tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(ew.tlm)
+	ew.tm = ew.tlm
+}
+
+func (ew *epsWriter) showText(bb []byte) {
+	f := ew.gs.font
+	if f == nil {
+		f = ew.defaultFont()
+	}
+
+	var codes []int
+	if f.twoByte {
+		for i := 0; i+1 < len(bb); i += 2 {
+			codes = append(codes, int(bb[i])<<8+int(bb[i+1]))
+		}
+	} else {
+		for _, b := range bb {
+			codes = append(codes, int(b))
+		}
+	}
+
+	fs, th := ew.gs.fontSize, ew.gs.hScale
+
+	var (
+		advances []float64
+		total    float64
+	)
+	psBytes := make([]byte, 0, len(codes))
+
+	for _, c := range codes {
+		tx := f.width(c)*fs + ew.gs.charSpacing
+		if !f.twoByte && c == 0x20 {
+			tx += ew.gs.wordSpacing
+		}
+		advances = append(advances, tx)
+		total += tx
+
+		b := byte(c)
+		if f.twoByte {
+			b = 0x20
+			if b1, ok := f.recode[c]; ok {
+				b = b1
+			}
+		}
+		psBytes = append(psBytes, b)
+	}
+
+	mode := ew.gs.renderMode
+	if len(codes) > 0 && fs != 0 && mode != 3 && mode != 7 {
+		ew.printf("gsave %s concat [%s 0 0 1 0 %s] concat /%s %s selectfont\n", epsMatrix(ew.tm), epsNum(th), epsNum(ew.gs.rise), f.name, epsNum(fs))
+		switch mode {
+		case 1, 2, 5, 6:
+			x := 0.
+			for i, b := range psBytes {
+				ew.printf("%s 0 moveto <%02x> true charpath\n", epsNum(x), b)
+				x += advances[i]
+			}
+			if mode == 1 || mode == 5 {
+				ew.printf("%s stroke\n", ew.gs.stroke)
+			} else {
+				ew.printf("gsave %s fill grestore %s stroke\n", ew.gs.fill, ew.gs.stroke)
+			}
+		default:
+			ew.printf("%s 0 0 moveto <%s> [%s] xshow\n", ew.gs.fill, hex.EncodeToString(psBytes), epsNums(advances))
+		}
+		ew.printf("grestore\n")
+	}
+
+	ew.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {total * th, 0, 1}}.Multiply(ew.tm)
+}
+
+func (ew *epsWriter) showTextArray(a types.Array) {
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			ew.showText(bb)
+			continue
+		}
+		if f, ok := epsNumber(o); ok {
+			tx := -f / 1000 * ew.gs.fontSize * ew.gs.hScale
+			ew.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.Multiply(ew.tm)
+		}
+	}
+}
+
+func (ew *epsWriter) textStateOp(op model.ContentOp, resDict types.Dict) {
+	if op.Operator == "Tf" {
+		if len(op.Operands) != 2 {
+			return
+		}
+		name, ok := op.Operands[0].(types.Name)
+		if !ok {
+			return
+		}
+		if fs, ok := epsNumber(op.Operands[1]); ok {
+			ew.gs.font = ew.fontForName(name.Value(), resDict)
+			ew.gs.fontSize = fs
+		}
+		return
+	}
+
+	ff, ok := numOperands(op, 1)
+	if !ok {
+		return
+	}
+
+	switch op.Operator {
+	case "Tc":
+		ew.gs.charSpacing = ff[0]
+	case "Tw":
+		ew.gs.wordSpacing = ff[0]
+	case "Tz":
+		ew.gs.hScale = ff[0] / 100
+	case "TL":
+		ew.gs.leading = ff[0]
+	case "Ts":
+		ew.gs.rise = ff[0]
+	case "Tr":
+		ew.gs.renderMode = int(ff[0])
+	}
+}
+
+func (ew *epsWriter) textOp(op model.ContentOp, resDict types.Dict) {
+	switch op.Operator {
+
+	case "BT":
+		ew.tm, ew.tlm = matrix.IdentMatrix, matrix.IdentMatrix
+
+	case "ET":
+
+	case "Tc", "Tw", "Tz", "TL", "Ts", "Tr", "Tf":
+		ew.textStateOp(op, resDict)
+
+	case "Td", "TD":
+		ff, ok := numOperands(op, 2)
+		if !ok {
+			return
+		}
+		if op.Operator == "TD" {
+			ew.gs.leading = -ff[1]
+		}
+		ew.translateText(ff[0], ff[1])
+
+	case "Tm":
+		ff, ok := numOperands(op, 6)
+		if !ok {
+			return
+		}
+		ew.tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+		ew.tm = ew.tlm
+
+	case "T*":
+		ew.translateText(0, -ew.gs.leading)
+
+	case "Tj":
+		if len(op.Operands) == 1 {
+			if bb, ok := stringBytes(op.Operands[0]); ok {
+				ew.showText(bb)
+			}
+		}
+
+	case "TJ":
+		if len(op.Operands) == 1 {
+			if a, ok := op.Operands[0].(types.Array); ok {
+				ew.showTextArray(a)
+			}
+		}
+
+	case "'":
+		ew.translateText(0, -ew.gs.leading)
+		if len(op.Operands) == 1 {
+			if bb, ok := stringBytes(op.Operands[0]); ok {
+				ew.showText(bb)
+			}
+		}
+
+	case "\"":
+		if len(op.Operands) != 3 {
+			return
+		}
+		if aw, ok := epsNumber(op.Operands[0]); ok {
+			ew.gs.wordSpacing = aw
+		}
+		if ac, ok := epsNumber(op.Operands[1]); ok {
+			ew.gs.charSpacing = ac
+		}
+		ew.translateText(0, -ew.gs.leading)
+		if bb, ok := stringBytes(op.Operands[2]); ok {
+			ew.showText(bb)
+		}
+	}
+}
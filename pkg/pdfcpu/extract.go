@@ -18,13 +18,20 @@ package pdfcpu
 
 import (
 	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/ex-preman/pdfcpu/pkg/filter"
 	"github.com/ex-preman/pdfcpu/pkg/log"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/hhrutter/tiff"
 	"github.com/pkg/errors"
 )
 
@@ -387,10 +394,134 @@ func ExtractImage(ctx *model.Context, sd *types.StreamDict, thumb bool, resource
 		FileType: t,
 	}
 
+	if r != nil && ctx.Configuration != nil && ctx.ApplySoftMasks {
+		if err := applySoftMask(ctx.XRefTable, sd, img); err != nil {
+			return nil, err
+		}
+	}
+
 	return img, nil
 }
 
+// softMaskDict returns the soft mask of the image sd or nil.
+func softMaskDict(xRefTable *model.XRefTable, sd *types.StreamDict) (*types.StreamDict, int, error) {
+	ir := sd.IndirectRefEntry("SMask")
+	if ir == nil {
+		return nil, 0, nil
+	}
+
+	sm, _, err := xRefTable.DereferenceStreamDict(*ir)
+	if err != nil || sm == nil {
+		return nil, 0, err
+	}
+
+	if _, found := sm.Find("ColorSpace"); !found {
+		// Soft masks are always DeviceGray.
+		sm.InsertName("ColorSpace", model.DeviceGrayCS)
+	}
+
+	return sm, ir.ObjectNumber.Value(), nil
+}
+
+// extractSoftMask extracts the soft mask of the image sd as grayscale image named after the image resource.
+func extractSoftMask(ctx *model.Context, sd *types.StreamDict, resourceId string) (*model.Image, error) {
+	sm, objNr, err := softMaskDict(ctx.XRefTable, sd)
+	if err != nil || sm == nil {
+		return nil, err
+	}
+
+	return ExtractImage(ctx, sm, false, resourceId+"_smask", objNr, false)
+}
+
+// alpha returns the soft mask value at x,y scaled to 8 bits.
+func (im *imageSamples) alpha(x, y int, invert bool) uint8 {
+	row := im.data[y*im.stride():]
+
+	var v uint8
+	switch im.bpc {
+	case 16:
+		v = row[2*x]
+	case 8:
+		v = row[x]
+	default:
+		bit := x * im.bpc
+		v = row[bit/8] >> (8 - im.bpc - bit%8) & byte(maxValForBits(im.bpc))
+		v = scaleToBPC8(v, im.bpc)
+	}
+
+	if invert {
+		v = 255 - v
+	}
+	return v
+}
+
+// applySoftMask combines the rendered image img and the soft mask of the image sd into an RGBA PNG.
+// A soft mask of different size gets scaled to the size of the image.
+func applySoftMask(xRefTable *model.XRefTable, sd *types.StreamDict, img *model.Image) error {
+	sm, objNr, err := softMaskDict(xRefTable, sd)
+	if err != nil || sm == nil {
+		return err
+	}
+
+	// TODO Process optional "Matte".
+	mask, err := decodeImageSamples(xRefTable, sm)
+	if err != nil {
+		return err
+	}
+	if mask == nil || mask.comps != 1 {
+		log.Info.Printf("applySoftMask: obj#%d - ignoring unsupported soft mask\n", objNr)
+		return nil
+	}
+
+	var invert bool
+	if a := sm.ArrayEntry("Decode"); len(a) == 2 {
+		if f0, err := a.FloatNumber(0); err == nil {
+			if f1, err := a.FloatNumber(1); err == nil && f0 > f1 {
+				invert = true
+			}
+		}
+	}
+
+	var src image.Image
+	switch img.FileType {
+	case "jpg":
+		src, err = jpeg.Decode(img)
+	case "png":
+		src, err = png.Decode(img)
+	case "tif":
+		src, err = tiff.Decode(img)
+	default:
+		log.Info.Printf("applySoftMask: obj#%d - unsupported image type %s\n", objNr, img.FileType)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBAModel.Convert(src.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			c.A = mask.alpha(x*mask.w/w, y*mask.h/h, invert)
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return err
+	}
+
+	img.Reader, img.FileType = &buf, "png"
+
+	return nil
+}
+
 // ExtractPageImages extracts all images used by pageNr.
+// If ctx.ExtractSoftMasks is set soft masks get extracted as separate grayscale images.
 // Optionally return stubs only.
 func ExtractPageImages(ctx *model.Context, pageNr int, stub bool) (map[int]model.Image, error) {
 	m := map[int]model.Image{}
@@ -404,6 +535,17 @@ func ExtractPageImages(ctx *model.Context, pageNr int, stub bool) (map[int]model
 			img.PageNr = pageNr
 			m[objNr] = *img
 		}
+		if stub || ctx.Configuration == nil || !ctx.ExtractSoftMasks {
+			continue
+		}
+		sm, err := extractSoftMask(ctx, imageObj.ImageDict, imageObj.ResourceNames[0])
+		if err != nil {
+			return nil, err
+		}
+		if sm != nil {
+			sm.PageNr = pageNr
+			m[sm.ObjNr] = *sm
+		}
 	}
 	// Extract thumbnail for pageNr
 	if indRef, ok := ctx.PageThumbs[pageNr]; ok {
@@ -637,3 +779,107 @@ func ExtractMetadata(ctx *model.Context) ([]Metadata, error) {
 	}
 	return mm, nil
 }
+
+// ICCProfile is a Reader representing an embedded ICC profile.
+type ICCProfile struct {
+	io.Reader          // profile data
+	ObjNr       int    // ICC profile stream dict objNr
+	N           int    // number of color components
+	Description string // profile description
+	Usage       string // ICCBased or OutputIntent
+}
+
+func (p ICCProfile) String() string {
+	return fmt.Sprintf("obj#%d %s N=%d: %s", p.ObjNr, p.Usage, p.N, p.Description)
+}
+
+// collectICCProfileRefs records the ICC profile streams referenced by ICCBased color spaces and output intents within o.
+func collectICCProfileRefs(o types.Object, m map[int]string) {
+	switch o := o.(type) {
+
+	case types.Array:
+		if len(o) == 2 {
+			if n, ok := o[0].(types.Name); ok && n == "ICCBased" {
+				if ir, ok := o[1].(types.IndirectRef); ok {
+					if _, found := m[ir.ObjectNumber.Value()]; !found {
+						m[ir.ObjectNumber.Value()] = "ICCBased"
+					}
+				}
+			}
+		}
+		for _, o1 := range o {
+			collectICCProfileRefs(o1, m)
+		}
+
+	case types.Dict:
+		if ir := o.IndirectRefEntry("DestOutputProfile"); ir != nil {
+			m[ir.ObjectNumber.Value()] = "OutputIntent"
+		}
+		for _, o1 := range o {
+			collectICCProfileRefs(o1, m)
+		}
+
+	case types.StreamDict:
+		collectICCProfileRefs(o.Dict, m)
+	}
+}
+
+// ExtractICCProfiles returns all ICC profiles embedded in ctx used by ICCBased color spaces or output intents.
+func ExtractICCProfiles(ctx *model.Context) ([]ICCProfile, error) {
+	m := map[int]string{}
+	for _, v := range ctx.Table {
+		if v.Free || v.Object == nil {
+			continue
+		}
+		collectICCProfileRefs(v.Object, m)
+	}
+
+	objNrs := make([]int, 0, len(m))
+	for objNr := range m {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	pp := []ICCProfile{}
+	for _, objNr := range objNrs {
+		sd, _, err := ctx.DereferenceStreamDict(*types.NewIndirectRef(objNr, 0))
+		if err != nil {
+			return nil, err
+		}
+		if sd == nil {
+			continue
+		}
+		// Decode streamDict for supported filters only.
+		if err = sd.Decode(); err == filter.ErrUnsupportedFilter {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := ICCProfile{
+			Reader:      bytes.NewReader(sd.Content),
+			ObjNr:       objNr,
+			Description: iccProfile{b: sd.Content}.description(),
+			Usage:       m[objNr],
+		}
+		if n := sd.IntEntry("N"); n != nil {
+			p.N = *n
+		}
+		pp = append(pp, p)
+	}
+
+	return pp, nil
+}
+
+// ListICCProfiles returns a list of all ICC profiles embedded in ctx including their number of color components and description.
+func ListICCProfiles(ctx *model.Context) ([]string, error) {
+	pp, err := ExtractICCProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ss []string
+	for _, p := range pp {
+		ss = append(ss, p.String())
+	}
+	return ss, nil
+}
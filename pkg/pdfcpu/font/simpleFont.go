@@ -0,0 +1,409 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package font
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/internal/corefont/metrics"
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var winAnsiCodes map[string]byte
+
+// standardEncoding holds the StandardEncoding codes deviating from ASCII (see Annex D.2).
+var standardEncoding = map[byte]string{
+	0x27: "quoteright", 0x60: "quoteleft",
+	0xA1: "exclamdown", 0xA2: "cent", 0xA3: "sterling", 0xA4: "fraction", 0xA5: "yen", 0xA6: "florin", 0xA7: "section",
+	0xA8: "currency", 0xA9: "quotesingle", 0xAA: "quotedblleft", 0xAB: "guillemotleft", 0xAC: "guilsinglleft",
+	0xAD: "guilsinglright", 0xAE: "fi", 0xAF: "fl", 0xB1: "endash", 0xB2: "dagger", 0xB3: "daggerdbl",
+	0xB4: "periodcentered", 0xB6: "paragraph", 0xB7: "bullet", 0xB8: "quotesinglbase", 0xB9: "quotedblbase",
+	0xBA: "quotedblright", 0xBB: "guillemotright", 0xBC: "ellipsis", 0xBD: "perthousand", 0xBF: "questiondown",
+	0xC1: "grave", 0xC2: "acute", 0xC3: "circumflex", 0xC4: "tilde", 0xC5: "macron", 0xC6: "breve", 0xC7: "dotaccent",
+	0xC8: "dieresis", 0xCA: "ring", 0xCB: "cedilla", 0xCD: "hungarumlaut", 0xCE: "ogonek", 0xCF: "caron",
+	0xD0: "emdash", 0xE1: "AE", 0xE3: "ordfeminine", 0xE8: "Lslash", 0xE9: "Oslash", 0xEA: "OE", 0xEB: "ordmasculine",
+	0xF1: "ae", 0xF5: "dotlessi", 0xF8: "lslash", 0xF9: "oslash", 0xFA: "oe", 0xFB: "germandbls",
+}
+
+// glyphRunes holds Latin glyph names not covered by WinAnsiEncoding.
+var glyphRunes = map[string]rune{
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ',
+	"fraction": '⁄', "dotlessi": 'ı', "Lslash": 'Ł', "lslash": 'ł', "minus": '−',
+	"breve": '˘', "dotaccent": '˙', "ring": '˚', "hungarumlaut": '˝', "ogonek": '˛', "caron": 'ˇ',
+	"Delta": '∆', "Omega": 'Ω', "pi": 'π', "mu": 'µ', "partialdiff": '∂', "summation": '∑', "product": '∏',
+	"radical": '√', "infinity": '∞', "integral": '∫', "approxequal": '≈', "notequal": '≠', "lessequal": '≤',
+	"greaterequal": '≥', "lozenge": '◊', "apple": '\uF8FF',
+}
+
+func init() {
+	winAnsiCodes = map[string]byte{}
+	for code, glyphName := range metrics.WinAnsiGlyphMap {
+		// Some glyph names occur twice, eg. space and hyphen.
+		if c, ok := winAnsiCodes[glyphName]; !ok || code < int(c) {
+			winAnsiCodes[glyphName] = byte(code)
+		}
+	}
+}
+
+// SimpleFont returns true if d is a simple font dict using single byte char codes.
+func SimpleFont(d types.Dict) bool {
+	st := d.Subtype()
+	return st != nil && types.MemberOf(*st, []string{"Type1", "MMType1", "TrueType", "Type3"})
+}
+
+// Embedded returns true if the font program for font dict d is embedded.
+func Embedded(xRefTable *model.XRefTable, d types.Dict) (bool, error) {
+	if st := d.Subtype(); st != nil && *st == "Type3" {
+		// Glyphs are defined by content streams.
+		return true, nil
+	}
+
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		a, err := xRefTable.DereferenceArray(d["DescendantFonts"])
+		if err != nil || len(a) == 0 {
+			return false, err
+		}
+		if d, err = xRefTable.DereferenceDict(a[0]); err != nil || d == nil {
+			return false, err
+		}
+	}
+
+	fd, err := xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil || fd == nil {
+		return false, err
+	}
+
+	for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		if _, found := fd.Find(k); found {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func glyphNameToRune(glyphName string) (rune, bool) {
+	if code, ok := winAnsiCodes[glyphName]; ok {
+		return charmap.Windows1252.DecodeByte(code), true
+	}
+	if r, ok := glyphRunes[glyphName]; ok {
+		return r, true
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if strings.HasPrefix(glyphName, prefix) {
+			s := glyphName[len(prefix):]
+			if len(s) < 4 || len(s) > 6 {
+				continue
+			}
+			if i, err := strconv.ParseUint(s, 16, 32); err == nil {
+				return rune(i), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func baseEncodingCharMap(enc string) map[byte]rune {
+	m := map[byte]rune{}
+
+	switch enc {
+
+	case "WinAnsiEncoding":
+		for i := 0x20; i <= 0xFF; i++ {
+			if r := charmap.Windows1252.DecodeByte(byte(i)); r != '\uFFFD' {
+				m[byte(i)] = r
+			}
+		}
+
+	case "MacRomanEncoding":
+		for i := 0x20; i <= 0xFF; i++ {
+			m[byte(i)] = charmap.Macintosh.DecodeByte(byte(i))
+		}
+
+	default:
+		// StandardEncoding
+		for i := 0x20; i < 0x7F; i++ {
+			m[byte(i)] = rune(i)
+		}
+		for code, glyphName := range standardEncoding {
+			if r, ok := glyphNameToRune(glyphName); ok {
+				m[code] = r
+			}
+		}
+	}
+
+	return m
+}
+
+func applyDifferences(m map[byte]rune, a types.Array) {
+	code := -1
+	for _, o := range a {
+		switch o := o.(type) {
+		case types.Integer:
+			code = o.Value()
+		case types.Float:
+			code = int(o.Value())
+		case types.Name:
+			if code < 0 || code > 255 {
+				continue
+			}
+			if r, ok := glyphNameToRune(o.Value()); ok {
+				m[byte(code)] = r
+			} else {
+				delete(m, byte(code))
+			}
+			code++
+		}
+	}
+}
+
+// SimpleFontCharMap returns the mapping of char codes to Unicode implied by the encoding of the simple font dict d.
+// Symbolic fonts with a built-in encoding are not supported.
+func SimpleFontCharMap(xRefTable *model.XRefTable, d types.Dict) (map[byte]rune, error) {
+	if !SimpleFont(d) {
+		return nil, errors.New("pdfcpu: simple font dict expected")
+	}
+
+	o, err := xRefTable.Dereference(d["Encoding"])
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := o.(type) {
+
+	case nil:
+		return baseEncodingCharMap("StandardEncoding"), nil
+
+	case types.Name:
+		return baseEncodingCharMap(o.Value()), nil
+
+	case types.Dict:
+		enc := "StandardEncoding"
+		if be := o.NameEntry("BaseEncoding"); be != nil {
+			enc = *be
+		}
+		m := baseEncodingCharMap(enc)
+		a, err := xRefTable.DereferenceArray(o["Differences"])
+		if err != nil {
+			return nil, err
+		}
+		applyDifferences(m, a)
+		return m, nil
+	}
+
+	return nil, errors.New("pdfcpu: corrupt font encoding")
+}
+
+func baseEncodingGlyphNames(enc string) map[byte]string {
+	m := map[byte]string{}
+
+	switch enc {
+
+	case "WinAnsiEncoding":
+		for code, glyphName := range metrics.WinAnsiGlyphMap {
+			if code >= 0x20 && code <= 0xFF {
+				m[byte(code)] = glyphName
+			}
+		}
+
+	case "MacRomanEncoding":
+		for i := 0x20; i <= 0xFF; i++ {
+			r := charmap.Macintosh.DecodeByte(byte(i))
+			if c, ok := charmap.Windows1252.EncodeRune(r); ok {
+				if glyphName, ok := metrics.WinAnsiGlyphMap[int(c)]; ok {
+					m[byte(i)] = glyphName
+				}
+				continue
+			}
+			for glyphName, r1 := range glyphRunes {
+				if r1 == r {
+					m[byte(i)] = glyphName
+					break
+				}
+			}
+		}
+
+	default:
+		// StandardEncoding
+		for i := 0x20; i < 0x7F; i++ {
+			m[byte(i)] = metrics.WinAnsiGlyphMap[i]
+		}
+		for code, glyphName := range standardEncoding {
+			m[code] = glyphName
+		}
+	}
+
+	return m
+}
+
+// SimpleFontGlyphNames returns the mapping of char codes to glyph names implied by the encoding of the simple font dict d.
+// The result is nil for fonts using their built-in encoding.
+func SimpleFontGlyphNames(xRefTable *model.XRefTable, d types.Dict) (map[byte]string, error) {
+	o, err := xRefTable.Dereference(d["Encoding"])
+	if err != nil {
+		return nil, err
+	}
+
+	switch o := o.(type) {
+
+	case types.Name:
+		return baseEncodingGlyphNames(o.Value()), nil
+
+	case types.Dict:
+		enc := "StandardEncoding"
+		if be := o.NameEntry("BaseEncoding"); be != nil {
+			enc = *be
+		}
+		m := baseEncodingGlyphNames(enc)
+		a, err := xRefTable.DereferenceArray(o["Differences"])
+		if err != nil {
+			return nil, err
+		}
+		code := -1
+		for _, o := range a {
+			switch o := o.(type) {
+			case types.Integer:
+				code = o.Value()
+			case types.Float:
+				code = int(o.Value())
+			case types.Name:
+				if code >= 0 && code <= 255 {
+					m[byte(code)] = o.Value()
+				}
+				code++
+			}
+		}
+		return m, nil
+	}
+
+	return nil, nil
+}
+
+func standardEncodingDifferences() types.Array {
+	codes := make([]int, 0, len(standardEncoding))
+	for code := range standardEncoding {
+		codes = append(codes, int(code))
+	}
+	sort.Ints(codes)
+
+	a := types.Array{}
+	for i, code := range codes {
+		if i == 0 || codes[i-1] != code-1 {
+			a = append(a, types.Integer(code))
+		}
+		a = append(a, types.Name(standardEncoding[byte(code)]))
+	}
+	return a
+}
+
+func simpleFontWidths(ttf *font.TTFLight, m map[byte]rune) types.Array {
+	a := types.Array{}
+	for i := 0; i <= 255; i++ {
+		gid, ok := ttf.Chars[uint32(m[byte(i)])]
+		if !ok {
+			gid = 0
+		}
+		w := 0
+		if int(gid) < len(ttf.GlyphWidths) {
+			w = ttf.GlyphWidths[gid]
+		}
+		a = append(a, types.Integer(w))
+	}
+	return a
+}
+
+// EmbedTrueTypeFont embeds the TrueType font program bb into the non embedded simple font dict d.
+// The font program gets subsetted to the glyphs needed for codes.
+func EmbedTrueTypeFont(xRefTable *model.XRefTable, d types.Dict, ttf *font.TTFLight, bb []byte, codes map[byte]bool) error {
+	m, err := SimpleFontCharMap(xRefTable, d)
+	if err != nil {
+		return err
+	}
+
+	usedGIDs := map[uint16]bool{}
+	for c := range codes {
+		r, ok := m[c]
+		if !ok {
+			continue
+		}
+		if gid, ok := ttf.Chars[uint32(r)]; ok {
+			usedGIDs[gid] = true
+		}
+	}
+
+	fontName := ttf.PostscriptName
+
+	subset, err := font.SubsetFontFile(fontName, bb, usedGIDs)
+	if err != nil {
+		return err
+	}
+
+	fontFile, err := flateEncodedStreamIndRef(xRefTable, subset)
+	if err != nil {
+		return err
+	}
+
+	baseFontName := subFontPrefix() + "+" + fontName
+
+	fd := types.Dict(
+		map[string]types.Object{
+			"Ascent":      types.Integer(ttf.Ascent),
+			"CapHeight":   types.Integer(ttf.CapHeight),
+			"Descent":     types.Integer(ttf.Descent),
+			"Flags":       types.Integer(ttfFontDescriptorFlags(*ttf)),
+			"FontBBox":    types.NewNumberArray(ttf.LLx, ttf.LLy, ttf.URx, ttf.URy),
+			"FontFile2":   *fontFile,
+			"FontName":    types.Name(baseFontName),
+			"ItalicAngle": types.Float(ttf.ItalicAngle),
+			"StemV":       types.Integer(70), // Irrelevant for embedded files.
+			"Type":        types.Name("FontDescriptor"),
+		},
+	)
+
+	fdIndRef, err := xRefTable.IndRefForNewObject(fd)
+	if err != nil {
+		return err
+	}
+
+	if _, found := d.Find("Widths"); !found {
+		// Standard 14 fonts may omit their metrics.
+		d.InsertInt("FirstChar", 0)
+		d.InsertInt("LastChar", 255)
+		d.Insert("Widths", simpleFontWidths(ttf, m))
+	}
+
+	d.Update("Subtype", types.Name("TrueType"))
+	d.Update("BaseFont", types.Name(baseFontName))
+	d.Update("FontDescriptor", *fdIndRef)
+
+	if _, found := d.Find("Encoding"); !found {
+		// Emulate StandardEncoding.
+		d.Insert("Encoding", types.Dict(map[string]types.Object{
+			"Type":         types.Name("Encoding"),
+			"BaseEncoding": types.Name("WinAnsiEncoding"),
+			"Differences":  standardEncodingDifferences(),
+		}))
+	}
+
+	return nil
+}
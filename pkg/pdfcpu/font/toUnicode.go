@@ -0,0 +1,260 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package font
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func cMapTokens(s string) []string {
+	var tt []string
+	for len(s) > 0 {
+		switch c := s[0]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0x00:
+			s = s[1:]
+		case c == '%':
+			i := strings.IndexAny(s, "\r\n")
+			if i < 0 {
+				return tt
+			}
+			s = s[i:]
+		case c == '[' || c == ']':
+			tt = append(tt, s[:1])
+			s = s[1:]
+		case c == '<' && len(s) > 1 && s[1] == '<', c == '>' && len(s) > 1 && s[1] == '>':
+			tt = append(tt, s[:2])
+			s = s[2:]
+		case c == '<':
+			i := strings.IndexByte(s, '>')
+			if i < 0 {
+				return tt
+			}
+			tt = append(tt, s[:i+1])
+			s = s[i+1:]
+		default:
+			i := strings.IndexAny(s, " \t\r\n\f\x00[]<>%")
+			if i < 0 {
+				i = len(s)
+			}
+			if i == 0 {
+				i = 1
+			}
+			tt = append(tt, s[:i])
+			s = s[i:]
+		}
+	}
+	return tt
+}
+
+func cMapHex(tok string) ([]byte, bool) {
+	if len(tok) < 2 || tok[0] != '<' || tok[len(tok)-1] != '>' {
+		return nil, false
+	}
+	s := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" \t\r\n\f", r) {
+			return -1
+		}
+		return r
+	}, tok[1:len(tok)-1])
+	if len(s)%2 > 0 {
+		s += "0"
+	}
+	bb, err := hex.DecodeString(s)
+	return bb, err == nil
+}
+
+func cMapCode(bb []byte) int {
+	c := 0
+	for _, b := range bb {
+		c = c<<8 + int(b)
+	}
+	return c
+}
+
+func utf16BEString(bb []byte) string {
+	if len(bb)%2 > 0 {
+		bb = append(bb, 0)
+	}
+	u := make([]uint16, len(bb)/2)
+	for i := range u {
+		u[i] = uint16(bb[2*i])<<8 + uint16(bb[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+func incrementUTF16BE(bb []byte, n int) []byte {
+	// Increment the last byte pair.
+	bb = append([]byte(nil), bb...)
+	if l := len(bb); l >= 2 {
+		v := int(bb[l-2])<<8 + int(bb[l-1]) + n
+		bb[l-2], bb[l-1] = byte(v>>8), byte(v)
+	}
+	return bb
+}
+
+func parseBFRange(tt []string, m map[int]string) []string {
+	for len(tt) >= 3 && tt[0] != "endbfrange" {
+		lo, ok1 := cMapHex(tt[0])
+		hi, ok2 := cMapHex(tt[1])
+		if !ok1 || !ok2 {
+			return tt[1:]
+		}
+		from, thru := cMapCode(lo), cMapCode(hi)
+		if thru < from || thru-from > 0xFFFF {
+			return tt[2:]
+		}
+		if tt[2] == "[" {
+			tt = tt[3:]
+			for c := from; len(tt) > 0 && tt[0] != "]"; c++ {
+				if dst, ok := cMapHex(tt[0]); ok && c <= thru {
+					m[c] = utf16BEString(dst)
+				}
+				tt = tt[1:]
+			}
+			if len(tt) > 0 {
+				tt = tt[1:]
+			}
+			continue
+		}
+		dst, ok := cMapHex(tt[2])
+		if ok {
+			for c := from; c <= thru; c++ {
+				m[c] = utf16BEString(incrementUTF16BE(dst, c-from))
+			}
+		}
+		tt = tt[3:]
+	}
+	return tt
+}
+
+func parseBFChar(tt []string, m map[int]string) []string {
+	for len(tt) >= 2 && tt[0] != "endbfchar" {
+		src, ok1 := cMapHex(tt[0])
+		dst, ok2 := cMapHex(tt[1])
+		if ok1 && ok2 {
+			m[cMapCode(src)] = utf16BEString(dst)
+		}
+		tt = tt[2:]
+	}
+	return tt
+}
+
+// ParseToUnicodeCMap returns the char code to Unicode mapping of a ToUnicode CMap (see 9.10.3).
+func ParseToUnicodeCMap(bb []byte) map[int]string {
+	m := map[int]string{}
+	tt := cMapTokens(string(bb))
+	for len(tt) > 0 {
+		switch tt[0] {
+		case "beginbfchar":
+			tt = parseBFChar(tt[1:], m)
+		case "beginbfrange":
+			tt = parseBFRange(tt[1:], m)
+		default:
+			tt = tt[1:]
+		}
+	}
+	return m
+}
+
+// ToUnicodeMap returns the char code to Unicode mapping of the ToUnicode CMap of font dict d.
+// The result is nil if there is no ToUnicode CMap.
+func ToUnicodeMap(xRefTable *model.XRefTable, d types.Dict) (map[int]string, error) {
+	o, found := d.Find("ToUnicode")
+	if !found {
+		return nil, nil
+	}
+	if _, ok := o.(types.Name); ok {
+		// Predefined CMap like /Identity-H.
+		return nil, nil
+	}
+	sd, _, err := xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+	return ParseToUnicodeCMap(sd.Content), nil
+}
+
+// SimpleFontToUnicodeCMap returns a ToUnicode CMap for the char code to Unicode mapping m of a simple font.
+func SimpleFontToUnicodeCMap(m map[byte]rune) []byte {
+	codes := make([]int, 0, len(m))
+	for c := range m {
+		codes = append(codes, int(c))
+	}
+	sort.Ints(codes)
+
+	var b bytes.Buffer
+
+	b.WriteString(`/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+/CIDSystemInfo <<
+	/Registry (Adobe)
+	/Ordering (UCS)
+	/Supplement 0
+>> def
+/CMapName /Adobe-Identity-UCS def
+/CMapType 2 def
+1 begincodespacerange
+<00> <FF>
+endcodespacerange
+`)
+
+	// At most 100 entries per block.
+	for i := 0; i < len(codes); i += 100 {
+		j := i + 100
+		if j > len(codes) {
+			j = len(codes)
+		}
+		fmt.Fprintf(&b, "%d beginbfchar\n", j-i)
+		for _, c := range codes[i:j] {
+			fmt.Fprintf(&b, "<%02X> <", c)
+			for _, v := range utf16.Encode([]rune{m[byte(c)]}) {
+				fmt.Fprintf(&b, "%04X", v)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+
+	b.WriteString(`endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`)
+
+	return b.Bytes()
+}
+
+// SetSimpleFontToUnicode sets the ToUnicode CMap of the simple font dict d for the char code to Unicode mapping m.
+func SetSimpleFontToUnicode(xRefTable *model.XRefTable, d types.Dict, m map[byte]rune) error {
+	ir, err := flateEncodedStreamIndRef(xRefTable, SimpleFontToUnicodeCMap(m))
+	if err != nil {
+		return err
+	}
+	d.Update("ToUnicode", *ir)
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+func stripSubsetPrefix(fontName string) string {
+	if i := strings.IndexByte(fontName, '+'); i == 6 {
+		return fontName[7:]
+	}
+	return fontName
+}
+
+func fontDicts(ctx *model.Context) []int {
+	var objNrs []int
+	for objNr, entry := range ctx.Table {
+		if entry.Free || entry.Object == nil {
+			continue
+		}
+		d, ok := entry.Object.(types.Dict)
+		if !ok {
+			continue
+		}
+		if t := d.Type(); t != nil && *t == "Font" {
+			objNrs = append(objNrs, objNr)
+		}
+	}
+	sort.Ints(objNrs)
+	return objNrs
+}
+
+type fontProgram struct {
+	ttf *font.TTFLight
+	bb  []byte
+}
+
+// parseFontFiles parses all font files of fontFiles before any font gets embedded.
+func parseFontFiles(fontFiles map[string]string) (map[string]fontProgram, error) {
+	fns := make([]string, 0, len(fontFiles))
+	for fn := range fontFiles {
+		fns = append(fns, fn)
+	}
+	sort.Strings(fns)
+
+	fps := map[string]fontProgram{}
+	for _, fn := range fns {
+		// OpenType fonts based on CFF outlines get rejected.
+		ttf, bb, err := font.ParseTrueTypeFontFile(fontFiles[fn])
+		if err != nil {
+			return nil, err
+		}
+		fps[fn] = fontProgram{ttf, bb}
+	}
+
+	return fps, nil
+}
+
+// EmbedFonts embeds the font files of fontFiles (keyed by font name) into all matching non embedded simple fonts.
+// Supported are TrueType fonts (.ttf) and OpenType fonts based on TrueType outlines,
+// OpenType fonts based on CFF outlines (.otf with sfnt version OTTO) are not supported.
+// Font programs are subsetted to the glyphs used.
+// The result is a list of embedded fonts.
+func EmbedFonts(ctx *model.Context, fontFiles map[string]string) ([]string, error) {
+	if len(fontFiles) == 0 {
+		return nil, errors.New("pdfcpu: missing font files")
+	}
+
+	fps, err := parseFontFiles(fontFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	objNrs := fontDicts(ctx)
+
+	fs, err := usedFontStrings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, objNr := range objNrs {
+
+		d := ctx.Table[objNr].Object.(types.Dict)
+
+		fontName := d.NameEntry("BaseFont")
+		if fontName == nil {
+			continue
+		}
+		fn := stripSubsetPrefix(*fontName)
+
+		fontFile, ok := fontFiles[fn]
+		if !ok {
+			continue
+		}
+
+		if !pdffont.SimpleFont(d) {
+			ss = append(ss, fmt.Sprintf("obj#%d %s: skipped, composite fonts not supported", objNr, fn))
+			continue
+		}
+
+		embedded, err := pdffont.Embedded(ctx.XRefTable, d)
+		if err != nil {
+			return nil, err
+		}
+		if embedded {
+			continue
+		}
+
+		fp := fps[fn]
+
+		codes := map[byte]bool{}
+		for s := range fs[objNr] {
+			for i := 0; i < len(s); i++ {
+				codes[s[i]] = true
+			}
+		}
+
+		if err := pdffont.EmbedTrueTypeFont(ctx.XRefTable, d, fp.ttf, fp.bb, codes); err != nil {
+			return nil, err
+		}
+
+		ss = append(ss, fmt.Sprintf("obj#%d %s: embedded %s (%d chars)", objNr, fn, fontFile, len(codes)))
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,443 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+const maxMissingGlyphTextLen = 40
+
+// glyphChecker resolves the char codes of a font against its embedded font program.
+type glyphChecker struct {
+	fontName  string
+	cid       bool                             // 2 byte char codes
+	glyph     func(code int) (ok bool, r rune) // true if code maps to an actual glyph
+	unchecked string                           // reason for not checking this font
+}
+
+func (gc glyphChecker) codes(s string) []int {
+	var cc []int
+	if !gc.cid {
+		for i := 0; i < len(s); i++ {
+			cc = append(cc, int(s[i]))
+		}
+		return cc
+	}
+	for i := 0; i+1 < len(s); i += 2 {
+		cc = append(cc, int(s[i])<<8+int(s[i+1]))
+	}
+	return cc
+}
+
+func (gc glyphChecker) text(s string) string {
+	var sb strings.Builder
+	for _, c := range gc.codes(s) {
+		if _, r := gc.glyph(c); r > 0 && unicode.IsPrint(r) {
+			sb.WriteRune(r)
+			continue
+		}
+		sb.WriteRune('?')
+	}
+	t := sb.String()
+	if rr := []rune(t); len(rr) > maxMissingGlyphTextLen {
+		t = string(rr[:maxMissingGlyphTextLen]) + "..."
+	}
+	return t
+}
+
+func blankRune(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.Cf, r)
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func embeddedTrueTypeGlyphs(xRefTable *model.XRefTable, fd types.Dict) (*font.Glyphs, string, error) {
+	if fd == nil {
+		return nil, "not embedded", nil
+	}
+
+	o, found := fd.Find("FontFile2")
+	if !found {
+		if o, found = fd.Find("FontFile3"); !found {
+			if _, found = fd.Find("FontFile"); found {
+				return nil, "Type1 font program", nil
+			}
+			return nil, "not embedded", nil
+		}
+	}
+
+	sd, _, err := xRefTable.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, "", err
+	}
+	if st := sd.Subtype(); st != nil && *st != "OpenType" {
+		return nil, *st + " font program", nil
+	}
+	if err := sd.Decode(); err != nil {
+		return nil, "", err
+	}
+
+	g, err := font.ParseGlyphs(sd.Content)
+	if err != nil {
+		return nil, err.Error(), nil
+	}
+
+	return g, "", nil
+}
+
+func type3GlyphChecker(xRefTable *model.XRefTable, d types.Dict, gc *glyphChecker) error {
+	charProcs, err := xRefTable.DereferenceDict(d["CharProcs"])
+	if err != nil {
+		return err
+	}
+
+	enc, err := xRefTable.DereferenceDict(d["Encoding"])
+	if err != nil {
+		return err
+	}
+
+	names := map[int]string{}
+	if enc != nil {
+		a, err := xRefTable.DereferenceArray(enc["Differences"])
+		if err != nil {
+			return err
+		}
+		code := -1
+		for _, o := range a {
+			switch o := o.(type) {
+			case types.Integer:
+				code = o.Value()
+			case types.Name:
+				names[code] = o.Value()
+				code++
+			}
+		}
+	}
+
+	m, _ := pdffont.SimpleFontCharMap(xRefTable, d)
+
+	gc.glyph = func(code int) (bool, rune) {
+		r := m[byte(code)]
+		n, ok := names[code]
+		if !ok || charProcs == nil {
+			return false, r
+		}
+		_, found := charProcs.Find(n)
+		return found, r
+	}
+
+	return nil
+}
+
+func simpleFontGlyphChecker(xRefTable *model.XRefTable, d, fd types.Dict, gc *glyphChecker) error {
+	if st := d.Subtype(); st != nil && *st == "Type3" {
+		return type3GlyphChecker(xRefTable, d, gc)
+	}
+
+	g, reason, err := embeddedTrueTypeGlyphs(xRefTable, fd)
+	if err != nil || g == nil {
+		gc.unchecked = reason
+		return err
+	}
+
+	symbolic := false
+	if f := fd.IntEntry("Flags"); f != nil {
+		symbolic = *f&0x04 > 0
+	}
+
+	m, err := pdffont.SimpleFontCharMap(xRefTable, d)
+	if err != nil || symbolic {
+		// The encoding of symbolic fonts is meaningless.
+		m = map[byte]rune{}
+	}
+
+	toUnicode, err := pdffont.ToUnicodeMap(xRefTable, d)
+	if err != nil {
+		return err
+	}
+
+	gc.glyph = func(code int) (bool, rune) {
+		r, hasRune := m[byte(code)]
+		if s, ok := toUnicode[code]; ok && !hasRune {
+			r = firstRune(s)
+		}
+		var gid uint16
+		if hasRune && !symbolic && g.UnicodeMap != nil {
+			gid = g.UnicodeMap[uint32(r)]
+		}
+		if gid == 0 && g.SymbolicMap != nil {
+			if gid = g.SymbolicMap[uint32(0xF000+code)]; gid == 0 {
+				gid = g.SymbolicMap[uint32(code)]
+			}
+		}
+		if gid == 0 && g.UnicodeMap == nil && g.SymbolicMap == nil {
+			// No cmap: char codes are glyph indices.
+			gid = uint16(code)
+		}
+		if !g.HasGlyph(gid) {
+			return false, r
+		}
+		// Glyphs without outline are fine for blank and unknown characters.
+		return g.HasOutline(gid) || r == 0 || blankRune(r), r
+	}
+
+	return nil
+}
+
+func cidFontGlyphChecker(xRefTable *model.XRefTable, d types.Dict, gc *glyphChecker) error {
+	gc.cid = true
+
+	if enc := d.NameEntry("Encoding"); enc == nil || (*enc != "Identity-H" && *enc != "Identity-V") {
+		gc.unchecked = "unsupported encoding"
+		return nil
+	}
+
+	a, err := xRefTable.DereferenceArray(d["DescendantFonts"])
+	if err != nil || len(a) == 0 {
+		return err
+	}
+	df, err := xRefTable.DereferenceDict(a[0])
+	if err != nil || df == nil {
+		return err
+	}
+
+	fd, err := xRefTable.DereferenceDict(df["FontDescriptor"])
+	if err != nil {
+		return err
+	}
+
+	g, reason, err := embeddedTrueTypeGlyphs(xRefTable, fd)
+	if err != nil || g == nil {
+		gc.unchecked = reason
+		return err
+	}
+
+	var cidToGID []byte
+	if o, found := df.Find("CIDToGIDMap"); found {
+		if _, ok := o.(types.Name); !ok {
+			sd, _, err := xRefTable.DereferenceStreamDict(o)
+			if err != nil || sd == nil {
+				return err
+			}
+			if err := sd.Decode(); err != nil {
+				return err
+			}
+			cidToGID = sd.Content
+		}
+	}
+
+	toUnicode, err := pdffont.ToUnicodeMap(xRefTable, d)
+	if err != nil {
+		return err
+	}
+
+	gidToUnicode := map[uint16]rune{}
+	for c, gid := range g.UnicodeMap {
+		gidToUnicode[gid] = rune(c)
+	}
+
+	gc.glyph = func(cid int) (bool, rune) {
+		gid := uint16(cid)
+		if cidToGID != nil {
+			if 2*cid+1 >= len(cidToGID) {
+				return false, 0
+			}
+			gid = uint16(cidToGID[2*cid])<<8 + uint16(cidToGID[2*cid+1])
+		}
+		r := gidToUnicode[gid]
+		if s, ok := toUnicode[cid]; ok {
+			r = firstRune(s)
+		}
+		if !g.HasGlyph(gid) {
+			return false, r
+		}
+		// Glyphs without outline are fine for blank and unknown characters.
+		return g.HasOutline(gid) || r == 0 || blankRune(r), r
+	}
+
+	return nil
+}
+
+func newGlyphChecker(xRefTable *model.XRefTable, d types.Dict) (*glyphChecker, error) {
+	gc := &glyphChecker{}
+	if fn := d.NameEntry("BaseFont"); fn != nil {
+		gc.fontName = *fn
+	}
+
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		return gc, cidFontGlyphChecker(xRefTable, d, gc)
+	}
+
+	if !pdffont.SimpleFont(d) {
+		gc.unchecked = "unsupported font type"
+		return gc, nil
+	}
+
+	fd, err := xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil {
+		return nil, err
+	}
+
+	return gc, simpleFontGlyphChecker(xRefTable, d, fd, gc)
+}
+
+type missingGlyph struct {
+	pageNr int
+	code   int
+	text   string
+}
+
+func missingGlyphsForFont(gc *glyphChecker, pageNr int, ss map[string]bool, mm map[int]map[int]string) {
+	strs := make([]string, 0, len(ss))
+	for s := range ss {
+		strs = append(strs, s)
+	}
+	sort.Strings(strs)
+
+	for _, s := range strs {
+		for _, c := range gc.codes(s) {
+			if ok, _ := gc.glyph(c); ok {
+				continue
+			}
+			m, found := mm[pageNr]
+			if !found {
+				m = map[int]string{}
+				mm[pageNr] = m
+			}
+			if _, found := m[c]; !found {
+				m[c] = gc.text(s)
+			}
+		}
+	}
+}
+
+func missingGlyphsReport(objNr int, gc *glyphChecker, mm map[int]map[int]string) []string {
+	var mg []missingGlyph
+	for pageNr, m := range mm {
+		for c, t := range m {
+			mg = append(mg, missingGlyph{pageNr, c, t})
+		}
+	}
+	sort.Slice(mg, func(i, j int) bool {
+		if mg[i].pageNr != mg[j].pageNr {
+			return mg[i].pageNr < mg[j].pageNr
+		}
+		return mg[i].code < mg[j].code
+	})
+
+	codeFmt := "0x%02X"
+	if gc.cid {
+		codeFmt = "0x%04X"
+	}
+
+	ss := []string{fmt.Sprintf("obj#%d %s: %d missing glyphs", objNr, gc.fontName, len(mg))}
+	for _, g := range mg {
+		ss = append(ss, fmt.Sprintf("  page %d: code "+codeFmt+" in \"%s\"", g.pageNr, g.code, g.text))
+	}
+	return ss
+}
+
+// ListMissingGlyphs checks all text shown on selectedPages against the embedded font programs
+// and returns a report of char codes that do not map to an actual glyph and would render as .notdef.
+// Checking is supported for embedded TrueType based fonts and Type3 fonts.
+func ListMissingGlyphs(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	checkers := map[int]*glyphChecker{}
+	missing := map[int]map[int]map[int]string{} // objNr -> pageNr -> code -> text
+
+	for _, pageNr := range pageNrs {
+
+		fs, err := pageFontStrings(ctx, pageNr)
+		if err != nil {
+			return nil, err
+		}
+
+		for objNr, ss := range fs {
+
+			gc, ok := checkers[objNr]
+			if !ok {
+				d, err := ctx.DereferenceDict(*types.NewIndirectRef(objNr, 0))
+				if err != nil || d == nil {
+					continue
+				}
+				if gc, err = newGlyphChecker(ctx.XRefTable, d); err != nil {
+					return nil, err
+				}
+				checkers[objNr] = gc
+			}
+
+			if gc.glyph == nil {
+				continue
+			}
+
+			mm, found := missing[objNr]
+			if !found {
+				mm = map[int]map[int]string{}
+			}
+			missingGlyphsForFont(gc, pageNr, ss, mm)
+			if len(mm) > 0 {
+				missing[objNr] = mm
+			}
+		}
+	}
+
+	objNrs := make([]int, 0, len(checkers))
+	for objNr := range checkers {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	var ss []string
+	for _, objNr := range objNrs {
+		gc := checkers[objNr]
+		if gc.glyph == nil {
+			if gc.unchecked == "" {
+				gc.unchecked = "unsupported font program"
+			}
+			if gc.unchecked != "not embedded" {
+				ss = append(ss, fmt.Sprintf("obj#%d %s: not checked (%s)", objNr, gc.fontName, gc.unchecked))
+			}
+			continue
+		}
+		if mm, found := missing[objNr]; found {
+			ss = append(ss, missingGlyphsReport(objNr, gc, mm)...)
+		}
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// FontInfo describes a font used by a PDF document.
+type FontInfo struct {
+	ObjNr      int
+	Name       string // BaseFont including a possible subset prefix.
+	Subtype    string
+	Embedded   bool
+	Subset     bool  // Font name carries a subset tag like ABCDEF+
+	Size       int64 // Byte size of the embedded font program as stored in the file.
+	GlyphsUsed int   // Number of distinct char codes shown.
+}
+
+func (fi FontInfo) String() string {
+	s := "not embedded"
+	if fi.Subtype == "Type3" {
+		s = "glyph procedures"
+	} else if fi.Embedded {
+		kind := "full"
+		if fi.Subset {
+			kind = "subset"
+		}
+		s = fmt.Sprintf("%s, embedded %d bytes", kind, fi.Size)
+	}
+	return fmt.Sprintf("obj#%d %s (%s): %s, %d glyphs used", fi.ObjNr, fi.Name, fi.Subtype, s, fi.GlyphsUsed)
+}
+
+// subsetFontName returns true if fontName carries a subset tag consisting of six uppercase letters and a plus sign.
+func subsetFontName(fontName string) bool {
+	if len(fontName) < 8 || fontName[6] != '+' {
+		return false
+	}
+	for i := 0; i < 6; i++ {
+		if fontName[i] < 'A' || fontName[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func fontProgramSize(xRefTable *model.XRefTable, d types.Dict) (int64, bool, error) {
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		a, err := xRefTable.DereferenceArray(d["DescendantFonts"])
+		if err != nil || len(a) == 0 {
+			return 0, false, err
+		}
+		if d, err = xRefTable.DereferenceDict(a[0]); err != nil || d == nil {
+			return 0, false, err
+		}
+	}
+
+	fd, err := xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil || fd == nil {
+		return 0, false, err
+	}
+
+	for _, k := range []string{"FontFile", "FontFile2", "FontFile3"} {
+		o, found := fd.Find(k)
+		if !found {
+			continue
+		}
+		sd, _, err := xRefTable.DereferenceStreamDict(o)
+		if err != nil || sd == nil {
+			return 0, true, err
+		}
+		if sd.StreamLength != nil {
+			return *sd.StreamLength, true, nil
+		}
+		return int64(len(sd.Raw)), true, nil
+	}
+
+	return 0, false, nil
+}
+
+func distinctCodes(ss map[string]bool, codeLen int) int {
+	m := map[string]bool{}
+	for s := range ss {
+		for i := 0; i+codeLen <= len(s); i += codeLen {
+			m[s[i:i+codeLen]] = true
+		}
+	}
+	return len(m)
+}
+
+// DocumentFonts returns information about all fonts of ctx except descendant CIDFonts.
+// Composite fonts are assumed to use 2 byte char codes.
+func DocumentFonts(ctx *model.Context) ([]FontInfo, error) {
+	fs, err := usedFontStrings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ff []FontInfo
+
+	for _, objNr := range fontDicts(ctx) {
+
+		d := ctx.Table[objNr].Object.(types.Dict)
+
+		st := d.Subtype()
+		if st == nil || *st == "CIDFontType0" || *st == "CIDFontType2" {
+			continue
+		}
+
+		fi := FontInfo{ObjNr: objNr, Subtype: *st}
+
+		if fn := d.NameEntry("BaseFont"); fn != nil {
+			fi.Name = *fn
+			fi.Subset = subsetFontName(*fn)
+		} else if fn := d.NameEntry("Name"); fn != nil {
+			fi.Name = *fn
+		}
+
+		if *st == "Type3" {
+			// Glyphs are defined by content streams.
+			fi.Embedded = true
+		} else if fi.Size, fi.Embedded, err = fontProgramSize(ctx.XRefTable, d); err != nil {
+			return nil, err
+		}
+
+		codeLen := 1
+		if *st == "Type0" {
+			codeLen = 2
+		}
+		fi.GlyphsUsed = distinctCodes(fs[objNr], codeLen)
+
+		ff = append(ff, fi)
+	}
+
+	return ff, nil
+}
+
+// ListDocumentFonts returns a list of all fonts of ctx including the embedded font program size,
+// whether the font program is subsetted and the number of distinct glyphs used.
+// Fully embedded fonts are candidates for subsetting.
+func ListDocumentFonts(ctx *model.Context) ([]string, error) {
+	ff, err := DocumentFonts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ss   []string
+		full int64
+	)
+
+	for _, fi := range ff {
+		ss = append(ss, fi.String())
+		if fi.Embedded && !fi.Subset && fi.Subtype != "Type3" {
+			full += fi.Size
+		}
+	}
+
+	if full > 0 {
+		ss = append(ss, fmt.Sprintf("fully embedded font programs: %d bytes", full))
+	}
+
+	return ss, nil
+}
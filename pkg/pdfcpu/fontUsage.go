@@ -0,0 +1,295 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// fontStrings holds the strings shown per font dict object number.
+type fontStrings map[int]map[string]bool
+
+func (fs fontStrings) add(objNr int, s string) {
+	m, ok := fs[objNr]
+	if !ok {
+		m = map[string]bool{}
+		fs[objNr] = m
+	}
+	m[s] = true
+}
+
+func textOperandBytes(o types.Object) ([]byte, bool) {
+	switch o := o.(type) {
+	case types.StringLiteral:
+		bb, err := types.Unescape(o.Value(), false)
+		return bb, err == nil
+	case types.HexLiteral:
+		bb, err := o.Bytes()
+		return bb, err == nil
+	}
+	return nil, false
+}
+
+func resourceIndRef(xRefTable *model.XRefTable, resDict types.Dict, resType, resName string) (*types.IndirectRef, error) {
+	if resDict == nil {
+		return nil, nil
+	}
+	d, err := xRefTable.DereferenceDict(resDict[resType])
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return d.IndirectRefEntry(resName), nil
+}
+
+// gStateFont returns the font of the graphics state parameter dict resName, if any.
+func gStateFont(xRefTable *model.XRefTable, resDict types.Dict, resName string) (*types.IndirectRef, error) {
+	if resDict == nil {
+		return nil, nil
+	}
+	d, err := xRefTable.DereferenceDict(resDict["ExtGState"])
+	if err != nil || d == nil {
+		return nil, err
+	}
+	if d, err = xRefTable.DereferenceDict(d[resName]); err != nil || d == nil {
+		return nil, err
+	}
+	a, err := xRefTable.DereferenceArray(d["Font"])
+	if err != nil || len(a) != 2 {
+		return nil, err
+	}
+	if ir, ok := a[0].(types.IndirectRef); ok {
+		return &ir, nil
+	}
+	return nil, nil
+}
+
+// shownText calls fn for all strings shown by ops along with the font dict in effect, which may be nil.
+// The font in effect is part of the graphics state and gets set by Tf or gs (Font entry).
+// font is the font in effect initially eg. inherited by a form XObject.
+// If do is not nil, it gets called for each XObject painted along with the font in effect.
+func shownText(xRefTable *model.XRefTable, resDict types.Dict, ops []model.ContentOp, font *types.IndirectRef,
+	fn func(font *types.IndirectRef, bb []byte), do func(name string, font *types.IndirectRef) error) error {
+
+	// Fonts saved by q.
+	var fonts []*types.IndirectRef
+
+	for _, op := range ops {
+		switch op.Operator {
+		case "q":
+			fonts = append(fonts, font)
+		case "Q":
+			if n := len(fonts); n > 0 {
+				font, fonts = fonts[n-1], fonts[:n-1]
+			}
+		case "Tf":
+			if len(op.Operands) == 2 {
+				if n, ok := op.Operands[0].(types.Name); ok {
+					ir, err := resourceIndRef(xRefTable, resDict, "Font", n.Value())
+					if err != nil {
+						return err
+					}
+					font = ir
+				}
+			}
+		case "gs":
+			if len(op.Operands) == 1 {
+				if n, ok := op.Operands[0].(types.Name); ok {
+					ir, err := gStateFont(xRefTable, resDict, n.Value())
+					if err != nil {
+						return err
+					}
+					if ir != nil {
+						font = ir
+					}
+				}
+			}
+		case "Tj", "'", "\"":
+			if len(op.Operands) > 0 {
+				if bb, ok := textOperandBytes(op.Operands[len(op.Operands)-1]); ok {
+					fn(font, bb)
+				}
+			}
+		case "TJ":
+			if len(op.Operands) == 1 {
+				if a, ok := op.Operands[0].(types.Array); ok {
+					for _, o := range a {
+						if bb, ok := textOperandBytes(o); ok {
+							fn(font, bb)
+						}
+					}
+				}
+			}
+		case "Do":
+			if do == nil || len(op.Operands) != 1 {
+				continue
+			}
+			if n, ok := op.Operands[0].(types.Name); ok {
+				if err := do(n.Value(), font); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// formFont identifies a form XObject painted using an inherited font.
+type formFont struct {
+	objNr, fontObjNr int
+}
+
+func collectFontStringsForFormXObject(xRefTable *model.XRefTable, ir types.IndirectRef, resDict types.Dict, font *types.IndirectRef, fs fontStrings, visited map[formFont]bool) error {
+	k := formFont{objNr: ir.ObjectNumber.Value()}
+	if font != nil {
+		k.fontObjNr = font.ObjectNumber.Value()
+	}
+	if visited[k] {
+		return nil
+	}
+	visited[k] = true
+
+	sd, _, err := xRefTable.DereferenceStreamDict(ir)
+	if err != nil || sd == nil {
+		return err
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return nil
+	}
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if d, err := xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resDict = d
+	}
+
+	return collectFontStrings(xRefTable, sd.Content, resDict, font, fs, visited)
+}
+
+func collectFontStrings(xRefTable *model.XRefTable, content []byte, resDict types.Dict, font *types.IndirectRef, fs fontStrings, visited map[formFont]bool) error {
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps(content)
+
+	return shownText(xRefTable, resDict, ops, font,
+		func(font *types.IndirectRef, bb []byte) {
+			if font != nil {
+				fs.add(font.ObjectNumber.Value(), string(bb))
+			}
+		},
+		func(name string, font *types.IndirectRef) error {
+			ir, err := resourceIndRef(xRefTable, resDict, "XObject", name)
+			if err != nil || ir == nil {
+				return err
+			}
+			// Forms inherit the graphics state including the font in effect.
+			return collectFontStringsForFormXObject(xRefTable, *ir, resDict, font, fs, visited)
+		})
+}
+
+func collectFontStringsForAnnots(xRefTable *model.XRefTable, pageDict types.Dict, fs fontStrings, visited map[formFont]bool) error {
+	annots, err := xRefTable.DereferenceArray(pageDict["Annots"])
+	if err != nil {
+		return err
+	}
+
+	for _, o := range annots {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil || d == nil {
+			continue
+		}
+		ap, err := xRefTable.DereferenceDict(d["AP"])
+		if err != nil || ap == nil {
+			continue
+		}
+		o, found := ap.Find("N")
+		if !found {
+			continue
+		}
+		irs := []types.IndirectRef{}
+		if ir, ok := o.(types.IndirectRef); ok {
+			if d, err := xRefTable.DereferenceDict(ir); err == nil && d != nil {
+				// Appearance subdictionary.
+				for _, o := range d {
+					if ir, ok := o.(types.IndirectRef); ok {
+						irs = append(irs, ir)
+					}
+				}
+			} else {
+				irs = append(irs, ir)
+			}
+		} else if d, ok := o.(types.Dict); ok {
+			for _, o := range d {
+				if ir, ok := o.(types.IndirectRef); ok {
+					irs = append(irs, ir)
+				}
+			}
+		}
+		for _, ir := range irs {
+			if err := collectFontStringsForFormXObject(xRefTable, ir, nil, nil, fs, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func collectPageFontStrings(ctx *model.Context, pageNr int, fs fontStrings, visited map[formFont]bool) error {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return nil
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return err
+	}
+
+	if err := collectFontStrings(ctx.XRefTable, bb, inhPAttrs.Resources, nil, fs, visited); err != nil {
+		return err
+	}
+
+	return collectFontStringsForAnnots(ctx.XRefTable, d, fs, visited)
+}
+
+// pageFontStrings returns all strings shown on page pageNr per font dict.
+func pageFontStrings(ctx *model.Context, pageNr int) (fontStrings, error) {
+	fs := fontStrings{}
+	if err := collectPageFontStrings(ctx, pageNr, fs, map[formFont]bool{}); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// usedFontStrings returns all strings shown on pages, form XObjects and annotation appearances per font dict.
+func usedFontStrings(ctx *model.Context) (fontStrings, error) {
+	fs := fontStrings{}
+	visited := map[formFont]bool{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if err := collectPageFontStrings(ctx, i, fs, visited); err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
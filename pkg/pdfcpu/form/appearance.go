@@ -0,0 +1,294 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"fmt"
+
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/primitives"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// fieldValue returns the text value of the field d.
+func fieldValue(d types.Dict) (string, error) {
+	sl := d.StringLiteralEntry("V")
+	if sl == nil {
+		return "", nil
+	}
+	return types.StringLiteralToString(*sl)
+}
+
+func regenerateTextFieldAP(ctx *model.Context, d types.Dict, fonts map[string]types.IndirectRef) error {
+	v, err := fieldValue(d)
+	if err != nil {
+		return err
+	}
+
+	df, err := extractDateFormat(ctx.XRefTable, d)
+	if err != nil {
+		return err
+	}
+	if df != nil {
+		return primitives.EnsureDateFieldAP(ctx, d, v, fonts)
+	}
+
+	ff := d.IntEntry("Ff")
+	multiLine := ff != nil && uint(primitives.FieldFlags(*ff))&uint(primitives.FieldMultiline) > 0
+
+	return primitives.EnsureTextFieldAP(ctx, d, v, multiLine, fonts)
+}
+
+func regenerateChoiceFieldAP(ctx *model.Context, d types.Dict, fonts map[string]types.IndirectRef) error {
+	ff := d.IntEntry("Ff")
+	if ff == nil {
+		return errors.New("pdfcpu: corrupt form field: missing entry Ff")
+	}
+
+	opts, err := parseOptions(ctx.XRefTable, d)
+	if err != nil {
+		return err
+	}
+	if len(opts) == 0 {
+		return errors.New("pdfcpu: missing Opts")
+	}
+
+	if primitives.FieldFlags(*ff)&primitives.FieldCombo > 0 {
+		v, err := fieldValue(d)
+		if err != nil {
+			return err
+		}
+		return primitives.EnsureComboBoxAP(ctx, d, v, fonts)
+	}
+
+	var vv []string
+	if primitives.FieldFlags(*ff)&primitives.FieldMultiselect > 0 {
+		if vv, err = parseStringLiteralArray(ctx.XRefTable, d, "V"); err != nil {
+			return err
+		}
+	} else {
+		v, err := fieldValue(d)
+		if err != nil {
+			return err
+		}
+		vv = []string{v}
+	}
+
+	ind := types.Array{}
+	for _, v := range vv {
+		for i, o := range opts {
+			if o == v {
+				ind = append(ind, types.Integer(i))
+				break
+			}
+		}
+	}
+
+	return primitives.EnsureListBoxAP(ctx, d, opts, ind, fonts)
+}
+
+// syncRadioButtonGroupAS selects the appearance state of all radio buttons of the group d according to its value.
+func syncRadioButtonGroupAS(xRefTable *model.XRefTable, d types.Dict) error {
+	v := types.Name("Off")
+	if s := d.NameEntry("V"); s != nil {
+		v = types.Name(*s)
+	}
+
+	vraw, err := types.DecodeName(v.String())
+	if err != nil {
+		return err
+	}
+
+	for _, o := range d.ArrayEntry("Kids") {
+		d, err := xRefTable.DereferenceDict(o)
+		if err != nil {
+			return err
+		}
+		d1 := d.DictEntry("AP")
+		if d1 == nil {
+			return errors.New("pdfcpu: corrupt form field: missing entry AP")
+		}
+		d2 := d1.DictEntry("N")
+		if d2 == nil {
+			return errors.New("pdfcpu: corrupt AP field: missing entry N")
+		}
+		for k := range d2 {
+			k, err := types.DecodeName(k)
+			if err != nil {
+				return err
+			}
+			if k != "Off" {
+				d["AS"] = types.Name("Off")
+				if k == vraw {
+					d["AS"] = v
+				}
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncCheckBoxAS selects the appearance state of the checkbox d according to its value.
+func syncCheckBoxAS(d types.Dict) error {
+	if d.DictEntry("AP") == nil {
+		return errors.New("pdfcpu: corrupt form field: missing entry AP")
+	}
+
+	offName, yesName := primitives.CalcCheckBoxASNames(d)
+
+	d["AS"] = offName
+	if s := d.NameEntry("V"); s != nil && *s != "Off" {
+		d["AS"] = yesName
+	}
+
+	return nil
+}
+
+func regenerateButtonAP(xRefTable *model.XRefTable, d types.Dict) (bool, error) {
+	if ff := d.IntEntry("Ff"); ff != nil && primitives.FieldFlags(*ff)&primitives.FieldPushbutton > 0 {
+		// Push buttons have no value.
+		return false, nil
+	}
+
+	if len(d.ArrayEntry("Kids")) > 0 {
+		return true, syncRadioButtonGroupAS(xRefTable, d)
+	}
+
+	return true, syncCheckBoxAS(d)
+}
+
+func updateUserFonts(xRefTable *model.XRefTable, fonts map[string]types.IndirectRef) error {
+	for fName, indRef := range fonts {
+		if len(xRefTable.UsedGIDs[fName]) == 0 {
+			continue
+		}
+		fDict, err := xRefTable.DereferenceDict(indRef)
+		if err != nil {
+			return err
+		}
+		fr := model.FontResource{}
+		if err := pdffont.IndRefsForUserfontUpdate(xRefTable, fDict, "", &fr); err != nil {
+			return pdffont.ErrCorruptFontDict
+		}
+		if err := pdffont.UpdateUserfont(xRefTable, fName, fr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegenerateAppearances regenerates the appearance streams of all text and choice fields from their current value
+// and default appearance (/DA) and selects the appearance states of all checkboxes and radio buttons according to their value.
+// Once all fields carry up to date appearances /AcroForm /NeedAppearances gets removed
+// since many viewers ignore it anyway.
+// Fields whose appearance can't be regenerated get reported and left as they are.
+// The result is a list of all fields processed.
+func RegenerateAppearances(ctx *model.Context) ([]string, error) {
+	xRefTable := ctx.XRefTable
+
+	fields, err := fields(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ss     []string
+		failed bool
+	)
+
+	fonts := map[string]types.IndirectRef{}
+	pIndRefs := map[types.IndirectRef]bool{}
+
+	for i := 1; i <= xRefTable.PageCount; i++ {
+		wAnnots, found := xRefTable.PageAnnots[i][model.AnnWidget]
+		if !found {
+			continue
+		}
+
+		for _, ir := range *(wAnnots.IndRefs) {
+
+			found, pIndRef, id, ft, err := isField(xRefTable, ir, fields)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+
+			if pIndRef != nil {
+				if pIndRefs[*pIndRef] {
+					continue
+				}
+				pIndRefs[*pIndRef] = true
+				ir = *pIndRef
+			}
+
+			d, err := xRefTable.DereferenceDict(ir)
+			if err != nil {
+				return nil, err
+			}
+			if len(d) == 0 {
+				continue
+			}
+
+			if ft == nil {
+				if ft = d.NameEntry("FT"); ft == nil {
+					ss = append(ss, fmt.Sprintf("field %s: skipped: missing entry FT", id))
+					failed = true
+					continue
+				}
+			}
+
+			ok := true
+
+			switch *ft {
+			case "Tx":
+				err = regenerateTextFieldAP(ctx, d, fonts)
+			case "Ch":
+				err = regenerateChoiceFieldAP(ctx, d, fonts)
+			case "Btn":
+				ok, err = regenerateButtonAP(xRefTable, d)
+			default:
+				ok = false
+			}
+
+			if err != nil {
+				ss = append(ss, fmt.Sprintf("field %s: skipped: %v", id, err))
+				failed = true
+				continue
+			}
+
+			if ok {
+				ss = append(ss, fmt.Sprintf("field %s (%s): appearance regenerated", id, *ft))
+			}
+		}
+	}
+
+	if err := updateUserFonts(xRefTable, fonts); err != nil {
+		return nil, err
+	}
+
+	if !failed {
+		xRefTable.AcroForm.Delete("NeedAppearances")
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// fieldTriggers are the additional actions of form fields in the order of their execution, see 12.6.3 Table 196.
+var fieldTriggers = []struct {
+	key, name string
+}{
+	{"K", "keystroke"},
+	{"F", "format"},
+	{"V", "validate"},
+	{"C", "calculate"},
+}
+
+// Maximum length of JavaScript code listed.
+const maxScriptLen = 60
+
+type formField struct {
+	id string
+	ir types.IndirectRef
+	d  types.Dict
+}
+
+// walkFields returns all fields of the field tree rooted at o along with their fully qualified ids.
+// Widgets without a partial name are not considered to be fields.
+func walkFields(xRefTable *model.XRefTable, o types.Object, path string, visited map[types.IndirectRef]bool, ff *[]formField) error {
+	ir, ok := o.(types.IndirectRef)
+	if !ok || visited[ir] {
+		return nil
+	}
+	visited[ir] = true
+
+	d, err := xRefTable.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return err
+	}
+
+	s := d.StringOrHexLiteralEntry("T")
+	if s == nil && path != "" {
+		// Widget
+		return nil
+	}
+
+	id := ir.ObjectNumber.String()
+	if s != nil {
+		id = *s
+	}
+	if path != "" {
+		id = path + "." + id
+	}
+
+	*ff = append(*ff, formField{id: id, ir: ir, d: d})
+
+	for _, o := range d.ArrayEntry("Kids") {
+		if err := walkFields(xRefTable, o, id, visited, ff); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formFields(xRefTable *model.XRefTable) ([]formField, error) {
+	fields, err := fields(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ff []formField
+	visited := map[types.IndirectRef]bool{}
+
+	for _, o := range fields {
+		if err := walkFields(xRefTable, o, "", visited, &ff); err != nil {
+			return nil, err
+		}
+	}
+
+	return ff, nil
+}
+
+// CalculationOrder returns the ids of all fields listed in the calculation order (/AcroForm /CO) in order.
+func CalculationOrder(ctx *model.Context) ([]string, error) {
+	ff, err := formFields(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[types.IndirectRef]string{}
+	for _, f := range ff {
+		ids[f.ir] = f.id
+	}
+
+	co, err := ctx.DereferenceArray(ctx.AcroForm["CO"])
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]string, 0, len(co))
+	for _, o := range co {
+		ir, ok := o.(types.IndirectRef)
+		if !ok {
+			continue
+		}
+		id, ok := ids[ir]
+		if !ok {
+			id = fmt.Sprintf("obj#%d (not a field)", ir.ObjectNumber.Value())
+		}
+		ss = append(ss, id)
+	}
+
+	return ss, nil
+}
+
+func calculationAction(xRefTable *model.XRefTable, d types.Dict) bool {
+	aa, err := xRefTable.DereferenceDict(d["AA"])
+	if err != nil || aa == nil {
+		return false
+	}
+	_, found := aa.Find("C")
+	return found
+}
+
+// SetCalculationOrder sets the calculation order (/AcroForm /CO) to the fields fieldIDs.
+// All fields need a calculate action (/AA /C).
+// An empty fieldIDs removes the calculation order.
+func SetCalculationOrder(ctx *model.Context, fieldIDs []string) error {
+	if len(fieldIDs) == 0 {
+		ctx.AcroForm.Delete("CO")
+		return nil
+	}
+
+	ff, err := formFields(ctx.XRefTable)
+	if err != nil {
+		return err
+	}
+
+	m := map[string]formField{}
+	for _, f := range ff {
+		m[f.id] = f
+	}
+
+	co := types.Array{}
+	seen := map[string]bool{}
+
+	for _, id := range fieldIDs {
+		f, ok := m[id]
+		if !ok {
+			return errors.Errorf("pdfcpu: SetCalculationOrder: unknown field: %s", id)
+		}
+		if seen[id] {
+			return errors.Errorf("pdfcpu: SetCalculationOrder: duplicate field: %s", id)
+		}
+		if !calculationAction(ctx.XRefTable, f.d) {
+			return errors.Errorf("pdfcpu: SetCalculationOrder: field %s has no calculate action", id)
+		}
+		seen[id] = true
+		co = append(co, f.ir)
+	}
+
+	ctx.AcroForm["CO"] = co
+
+	return nil
+}
+
+func actionScript(xRefTable *model.XRefTable, d types.Dict) (string, error) {
+	o, found := d.Find("JS")
+	if !found {
+		return "", nil
+	}
+
+	o, err := xRefTable.Dereference(o)
+	if err != nil {
+		return "", err
+	}
+
+	var s string
+
+	switch o := o.(type) {
+	case types.StringLiteral:
+		if s, err = types.StringLiteralToString(o); err != nil {
+			return "", err
+		}
+	case types.HexLiteral:
+		if s, err = types.HexLiteralToString(o); err != nil {
+			return "", err
+		}
+	case types.StreamDict:
+		if err := o.Decode(); err != nil {
+			return "", err
+		}
+		s = string(o.Content)
+	}
+
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxScriptLen {
+		s = s[:maxScriptLen] + "..."
+	}
+
+	return s, nil
+}
+
+// FieldActions returns a list of all keystroke, format, validate and calculate actions (/AA) of form fields
+// along with their JavaScript code (truncated).
+func FieldActions(ctx *model.Context) ([]string, error) {
+	ff, err := formFields(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, f := range ff {
+		aa, err := ctx.DereferenceDict(f.d["AA"])
+		if err != nil {
+			return nil, err
+		}
+		if aa == nil {
+			continue
+		}
+		for _, t := range fieldTriggers {
+			d, err := ctx.DereferenceDict(aa[t.key])
+			if err != nil {
+				return nil, err
+			}
+			if d == nil {
+				continue
+			}
+			s := "?"
+			if n := d.NameEntry("S"); n != nil {
+				s = *n
+			}
+			if s == "JavaScript" {
+				js, err := actionScript(ctx.XRefTable, d)
+				if err != nil {
+					return nil, err
+				}
+				s += ": " + js
+			}
+			ss = append(ss, fmt.Sprintf("%s: %s: %s", f.id, t.name, s))
+		}
+	}
+
+	return ss, nil
+}
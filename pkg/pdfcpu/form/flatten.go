@@ -0,0 +1,387 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// widgetAppearance returns the normal appearance stream of the widget d selected by its appearance state or nil.
+func widgetAppearance(xRefTable *model.XRefTable, d types.Dict) (*types.IndirectRef, *types.StreamDict, error) {
+	ap, err := xRefTable.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		return nil, nil, err
+	}
+
+	o, found := ap.Find("N")
+	if !found {
+		return nil, nil, nil
+	}
+
+	if d1, err := xRefTable.DereferenceDict(o); err == nil && d1 != nil {
+		// Appearance subdictionary.
+		as := d.NameEntry("AS")
+		if as == nil {
+			return nil, nil, nil
+		}
+		if o, found = d1.Find(*as); !found {
+			return nil, nil, nil
+		}
+	}
+
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		return nil, nil, nil
+	}
+
+	sd, _, err := xRefTable.DereferenceStreamDict(ir)
+	if err != nil || sd == nil {
+		return nil, nil, err
+	}
+
+	return &ir, sd, nil
+}
+
+func normalizedRect(a types.Array) (*types.Rectangle, error) {
+	r, err := types.RectForArray(a)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewRectangle(
+		math.Min(r.LL.X, r.UR.X), math.Min(r.LL.Y, r.UR.Y),
+		math.Max(r.LL.X, r.UR.X), math.Max(r.LL.Y, r.UR.Y)), nil
+}
+
+// appearanceMatrix returns the matrix mapping the appearance stream sd onto the annotation rectangle r, see 12.5.5.
+func appearanceMatrix(sd *types.StreamDict, r *types.Rectangle) (matrix.Matrix, bool) {
+	a := sd.ArrayEntry("BBox")
+	if len(a) != 4 {
+		return matrix.IdentMatrix, false
+	}
+	bb, err := normalizedRect(a)
+	if err != nil {
+		return matrix.IdentMatrix, false
+	}
+
+	m := matrix.IdentMatrix
+	if a := sd.ArrayEntry("Matrix"); len(a) == 6 {
+		for i := range a {
+			f, err := a.FloatNumber(i)
+			if err != nil {
+				return matrix.IdentMatrix, false
+			}
+			m[i/2][i%2] = f
+		}
+	}
+
+	// Bounding box of the transformed form bounding box.
+	ll := types.Point{X: math.Inf(1), Y: math.Inf(1)}
+	ur := types.Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range []types.Point{bb.LL, {X: bb.UR.X, Y: bb.LL.Y}, bb.UR, {X: bb.LL.X, Y: bb.UR.Y}} {
+		p = m.Transform(p)
+		ll.X, ur.X = math.Min(ll.X, p.X), math.Max(ur.X, p.X)
+		ll.Y, ur.Y = math.Min(ll.Y, p.Y), math.Max(ur.Y, p.Y)
+	}
+
+	w, h := ur.X-ll.X, ur.Y-ll.Y
+	if w <= 0 || h <= 0 {
+		return matrix.IdentMatrix, false
+	}
+
+	sx, sy := r.Width()/w, r.Height()/h
+
+	return matrix.Matrix{{sx, 0, 0}, {0, sy, 0}, {r.LL.X - ll.X*sx, r.LL.Y - ll.Y*sy, 1}}, true
+}
+
+// flattener draws widget appearances into the content of a page.
+type flattener struct {
+	xRefTable *model.XRefTable
+	xObjects  types.Dict
+	buf       bytes.Buffer
+}
+
+func (f *flattener) addXObject(ir types.IndirectRef) string {
+	for k, o := range f.xObjects {
+		if ir1, ok := o.(types.IndirectRef); ok && ir1 == ir {
+			return k
+		}
+	}
+	var id string
+	for i := 0; ; i++ {
+		id = "Fm" + strconv.Itoa(i)
+		if _, found := f.xObjects.Find(id); !found {
+			break
+		}
+	}
+	f.xObjects[id] = ir
+	return id
+}
+
+// flattenWidget draws the appearance of the widget d and returns false if there is nothing to draw.
+func (f *flattener) flattenWidget(d types.Dict) (bool, error) {
+	if flags := d.IntEntry("F"); flags != nil && model.AnnotationFlags(*flags)&(model.AnnHidden|model.AnnInvisible) > 0 {
+		return false, nil
+	}
+
+	r, err := normalizedRect(d.ArrayEntry("Rect"))
+	if err != nil || r.Width() == 0 || r.Height() == 0 {
+		return false, nil
+	}
+
+	ir, sd, err := widgetAppearance(f.xRefTable, d)
+	if err != nil || sd == nil {
+		return false, err
+	}
+
+	m, ok := appearanceMatrix(sd, r)
+	if !ok {
+		return false, nil
+	}
+
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+
+	id := f.addXObject(*ir)
+
+	fmt.Fprintf(&f.buf, "q %.4f %.4f %.4f %.4f %.4f %.4f cm /%s Do Q ", m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1], id)
+
+	return true, nil
+}
+
+func (f *flattener) pageXObjects(d types.Dict, inhPAttrs *model.InheritedPageAttrs) error {
+	res, err := f.xRefTable.DereferenceDict(d["Resources"])
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		res = types.Dict{}
+		if inhPAttrs != nil && inhPAttrs.Resources != nil {
+			res = inhPAttrs.Resources.Clone().(types.Dict)
+		}
+		d["Resources"] = res
+	}
+
+	xObjects, err := f.xRefTable.DereferenceDict(res["XObject"])
+	if err != nil {
+		return err
+	}
+	if xObjects == nil {
+		xObjects = types.Dict{}
+		res["XObject"] = xObjects
+	}
+
+	f.xObjects = xObjects
+
+	return nil
+}
+
+func (f *flattener) flattenPage(pageNr int, fieldIDs map[types.IndirectRef]string) ([]string, error) {
+	d, _, inhPAttrs, err := f.xRefTable.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	annots, err := f.xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || len(annots) == 0 {
+		return nil, err
+	}
+
+	if err := f.pageXObjects(d, inhPAttrs); err != nil {
+		return nil, err
+	}
+
+	f.buf.Reset()
+
+	var (
+		ss      []string
+		annots1 types.Array
+	)
+
+	for _, o := range annots {
+		d1, err := f.xRefTable.DereferenceDict(o)
+		if err != nil {
+			return nil, err
+		}
+		if d1 == nil || d1.NameEntry("Subtype") == nil || *d1.NameEntry("Subtype") != "Widget" {
+			annots1 = append(annots1, o)
+			continue
+		}
+
+		drawn, err := f.flattenWidget(d1)
+		if err != nil {
+			return nil, err
+		}
+
+		id := "widget"
+		if ir, ok := o.(types.IndirectRef); ok {
+			if s, ok := fieldIDs[ir]; ok {
+				id = "field " + s
+			}
+		}
+
+		if drawn {
+			ss = append(ss, fmt.Sprintf("page %d: %s flattened", pageNr, id))
+		} else {
+			ss = append(ss, fmt.Sprintf("page %d: %s removed (no visible appearance)", pageNr, id))
+		}
+	}
+
+	if len(annots1) == len(annots) {
+		return nil, nil
+	}
+
+	if len(annots1) == 0 {
+		d.Delete("Annots")
+	} else {
+		d["Annots"] = annots1
+	}
+
+	if f.buf.Len() == 0 {
+		return ss, nil
+	}
+
+	bb, err := f.xRefTable.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return nil, err
+	}
+
+	// Isolate the page content from the appearances drawn on top.
+	var buf bytes.Buffer
+	if len(bb) > 0 {
+		buf.WriteString("q ")
+		buf.Write(bb)
+		buf.WriteString(" Q ")
+	}
+	buf.Write(f.buf.Bytes())
+
+	sd, _ := f.xRefTable.NewStreamDictForBuf(buf.Bytes())
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	ir, err := f.xRefTable.IndRefForNewObject(*sd)
+	if err != nil {
+		return nil, err
+	}
+
+	d["Contents"] = *ir
+
+	return ss, nil
+}
+
+// widgetFieldIDs maps all widgets of a form to the ids of their fields.
+func widgetFieldIDs(xRefTable *model.XRefTable) (map[types.IndirectRef]string, error) {
+	ff, err := formFields(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[types.IndirectRef]string{}
+
+	for _, f := range ff {
+		m[f.ir] = f.id
+		for _, o := range f.d.ArrayEntry("Kids") {
+			ir, ok := o.(types.IndirectRef)
+			if !ok {
+				continue
+			}
+			if _, found := m[ir]; !found {
+				m[ir] = f.id
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// needsAppearances returns true if the form relies on /NeedAppearances or any widget lacks an appearance.
+func needsAppearances(xRefTable *model.XRefTable) (bool, error) {
+	if b := xRefTable.AcroForm.BooleanEntry("NeedAppearances"); b != nil && *b {
+		return true, nil
+	}
+
+	for i := 1; i <= xRefTable.PageCount; i++ {
+		wAnnots, found := xRefTable.PageAnnots[i][model.AnnWidget]
+		if !found {
+			continue
+		}
+		for _, ir := range *(wAnnots.IndRefs) {
+			d, err := xRefTable.DereferenceDict(ir)
+			if err != nil {
+				return false, err
+			}
+			if d != nil && d.DictEntry("AP") == nil {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// Flatten draws the appearances of all form field widgets into the content of their pages and removes the form.
+// If the form relies on /NeedAppearances or any widget lacks an appearance all appearances get regenerated first.
+// Hidden widgets and widgets without an appearance get removed without being drawn.
+// The result is a list of all widgets processed.
+func Flatten(ctx *model.Context) ([]string, error) {
+	xRefTable := ctx.XRefTable
+
+	fieldIDs, err := widgetFieldIDs(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	regenerate, err := needsAppearances(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	if regenerate {
+		ss1, err := RegenerateAppearances(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, ss1...)
+	}
+
+	f := &flattener{xRefTable: xRefTable}
+
+	for i := 1; i <= xRefTable.PageCount; i++ {
+		ss1, err := f.flattenPage(i, fieldIDs)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, ss1...)
+		if xRefTable.PageAnnots[i] != nil {
+			delete(xRefTable.PageAnnots[i], model.AnnWidget)
+		}
+	}
+
+	xRefTable.RootDict.Delete("AcroForm")
+	xRefTable.AcroForm = nil
+
+	return ss, nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// FieldPosition represents the position of a form field widget on its rendered page
+// relative to the upper left corner of the page as displayed, taking into account CropBox and page rotation.
+// Positions are normalized to 0..1 or given in pixels for a specific resolution.
+type FieldPosition struct {
+	ID     string  `json:"id"`
+	Page   int     `json:"page"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// widgetPages maps all widgets to their page numbers.
+func widgetPages(xRefTable *model.XRefTable) map[types.IndirectRef]int {
+	m := map[types.IndirectRef]int{}
+	for i := 1; i <= xRefTable.PageCount; i++ {
+		wAnnots, found := xRefTable.PageAnnots[i][model.AnnWidget]
+		if !found {
+			continue
+		}
+		for _, ir := range *(wAnnots.IndRefs) {
+			m[ir] = i
+		}
+	}
+	return m
+}
+
+// fieldWidgets returns the widgets of the form field f.
+func fieldWidgets(xRefTable *model.XRefTable, f formField) []types.IndirectRef {
+	var irs []types.IndirectRef
+
+	if s := f.d.NameEntry("Subtype"); s != nil && *s == "Widget" {
+		// Field and widget dicts merged.
+		irs = append(irs, f.ir)
+	}
+
+	for _, o := range f.d.ArrayEntry("Kids") {
+		ir, ok := o.(types.IndirectRef)
+		if !ok {
+			continue
+		}
+		d, err := xRefTable.DereferenceDict(ir)
+		if err != nil || d == nil || d.StringOrHexLiteralEntry("T") != nil {
+			// Child field
+			continue
+		}
+		irs = append(irs, ir)
+	}
+
+	return irs
+}
+
+// displayedPoint maps p onto the page cropBox rotated by rot as displayed using a coordinate system with its origin in the upper left corner.
+func displayedPoint(p types.Point, cropBox *types.Rectangle, rot int) (float64, float64) {
+	w, h := cropBox.Width(), cropBox.Height()
+	x, y := p.X-cropBox.LL.X, cropBox.UR.Y-p.Y
+
+	switch rot {
+	case 90:
+		return h - y, x
+	case 180:
+		return w - x, h - y
+	case 270:
+		return y, w - x
+	}
+
+	return x, y
+}
+
+func fieldPosition(id string, pageNr int, r *types.Rectangle, pb model.PageBoundaries, dpi int) FieldPosition {
+	cropBox := pb.CropBox()
+
+	rot := (pb.Rot%360 + 360) % 360
+
+	x1, y1 := displayedPoint(r.LL, cropBox, rot)
+	x2, y2 := displayedPoint(r.UR, cropBox, rot)
+
+	w, h := cropBox.Width(), cropBox.Height()
+	if rot%180 != 0 {
+		w, h = h, w
+	}
+
+	// Normalize to 0..1
+	sx, sy := 1/w, 1/h
+	if dpi > 0 {
+		// user space units to pixels
+		sx = float64(dpi) / 72
+		sy = sx
+	}
+
+	return FieldPosition{
+		ID:     id,
+		Page:   pageNr,
+		X:      math.Min(x1, x2) * sx,
+		Y:      math.Min(y1, y2) * sy,
+		Width:  math.Abs(x2-x1) * sx,
+		Height: math.Abs(y2-y1) * sy,
+	}
+}
+
+// FieldPositions returns the positions of all form field widgets on their rendered pages sorted by page.
+// Positions are relative to the upper left corner of the page as displayed
+// and normalized to 0..1 for dpi <= 0 or in pixels otherwise.
+// Fields with multiple widgets like radio button groups result in multiple positions.
+func FieldPositions(ctx *model.Context, dpi int) ([]FieldPosition, error) {
+	xRefTable := ctx.XRefTable
+
+	ff, err := formFields(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	pbs, err := xRefTable.PageBoundaries()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := widgetPages(xRefTable)
+
+	var fps []FieldPosition
+
+	for _, f := range ff {
+		for _, ir := range fieldWidgets(xRefTable, f) {
+			pageNr, ok := pages[ir]
+			if !ok {
+				continue
+			}
+			d, err := xRefTable.DereferenceDict(ir)
+			if err != nil {
+				return nil, err
+			}
+			r, err := normalizedRect(d.ArrayEntry("Rect"))
+			if err != nil || pbs[pageNr-1].CropBox() == nil {
+				continue
+			}
+			fps = append(fps, fieldPosition(f.id, pageNr, r, pbs[pageNr-1], dpi))
+		}
+	}
+
+	sort.SliceStable(fps, func(i, j int) bool {
+		return fps[i].Page < fps[j].Page
+	})
+
+	return fps, nil
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// defaultAppearance is used for the form if variable text fields lack a default appearance.
+const defaultAppearance = "/Helv 0 Tf 0 g"
+
+// fallbackFonts maps font names commonly used in default appearances to core fonts.
+// Any other missing font falls back to Helvetica.
+var fallbackFonts = map[string]string{
+	"Helv": "Helvetica",
+	"HeBo": "Helvetica-Bold",
+	"TiRo": "Times-Roman",
+	"TiBo": "Times-Bold",
+	"Cour": "Courier",
+	"CoBo": "Courier-Bold",
+	"Symb": "Symbol",
+	"ZaDb": "ZapfDingbats",
+}
+
+// daFontName returns the name of the font selected by the default appearance string da.
+func daFontName(da string) string {
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps([]byte(da))
+
+	var fontName string
+	for _, op := range ops {
+		if op.Operator != "Tf" || len(op.Operands) != 2 {
+			continue
+		}
+		if n, ok := op.Operands[0].(types.Name); ok {
+			fontName = n.Value()
+		}
+	}
+
+	return fontName
+}
+
+// defaultResources collects the fonts used by the default appearances of all fields of a form.
+type defaultResources struct {
+	xRefTable *model.XRefTable
+	fonts     map[string][]string          // font names mapped to the fields using them
+	apFonts   map[string]types.IndirectRef // fonts found in appearance streams
+	noDA      []string                     // variable text fields without default appearance
+	visited   map[types.IndirectRef]bool
+}
+
+func (dr *defaultResources) collectAppearanceFonts(d types.Dict) {
+	ap, err := dr.xRefTable.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		return
+	}
+
+	o, found := ap.Find("N")
+	if !found {
+		return
+	}
+
+	var irs []types.IndirectRef
+	if ir, ok := o.(types.IndirectRef); ok {
+		irs = append(irs, ir)
+	}
+	if d, err := dr.xRefTable.DereferenceDict(o); err == nil && d != nil {
+		// Appearance subdictionary.
+		for _, o := range d {
+			if ir, ok := o.(types.IndirectRef); ok {
+				irs = append(irs, ir)
+			}
+		}
+	}
+
+	for _, ir := range irs {
+		sd, _, err := dr.xRefTable.DereferenceStreamDict(ir)
+		if err != nil || sd == nil {
+			continue
+		}
+		res, err := dr.xRefTable.DereferenceDict(sd.Dict["Resources"])
+		if err != nil || res == nil {
+			continue
+		}
+		fonts, err := dr.xRefTable.DereferenceDict(res["Font"])
+		if err != nil || fonts == nil {
+			continue
+		}
+		for k, o := range fonts {
+			if ir, ok := o.(types.IndirectRef); ok {
+				if _, found := dr.apFonts[k]; !found {
+					dr.apFonts[k] = ir
+				}
+			}
+		}
+	}
+}
+
+func (dr *defaultResources) collect(o types.Object, name, da, ft string) error {
+	ir, ok := o.(types.IndirectRef)
+	if ok {
+		if dr.visited[ir] {
+			return nil
+		}
+		dr.visited[ir] = true
+	}
+
+	d, err := dr.xRefTable.DereferenceDict(o)
+	if err != nil || d == nil {
+		return err
+	}
+
+	if s := d.StringOrHexLiteralEntry("T"); s != nil {
+		if name != "" {
+			name += "."
+		}
+		name += *s
+	}
+	if s := d.StringOrHexLiteralEntry("DA"); s != nil {
+		da = *s
+	}
+	if s := d.NameEntry("FT"); s != nil {
+		ft = *s
+	}
+
+	dr.collectAppearanceFonts(d)
+
+	kids := d.ArrayEntry("Kids")
+	if len(kids) == 0 {
+		// Terminal field or widget.
+		if ft != "Tx" && ft != "Ch" && da == "" {
+			return nil
+		}
+		if da == "" {
+			dr.noDA = append(dr.noDA, name)
+			return nil
+		}
+		if fontName := daFontName(da); fontName != "" {
+			if ff := dr.fonts[fontName]; len(ff) == 0 || ff[len(ff)-1] != name {
+				dr.fonts[fontName] = append(ff, name)
+			}
+		}
+		return nil
+	}
+
+	for _, o := range kids {
+		if err := dr.collect(o, name, da, ft); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func collectDefaultResources(xRefTable *model.XRefTable) (*defaultResources, error) {
+	fields, err := fields(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var da string
+	if s := xRefTable.AcroForm.StringOrHexLiteralEntry("DA"); s != nil {
+		da = *s
+	}
+
+	dr := &defaultResources{
+		xRefTable: xRefTable,
+		fonts:     map[string][]string{},
+		apFonts:   map[string]types.IndirectRef{},
+		visited:   map[types.IndirectRef]bool{},
+	}
+
+	for _, o := range fields {
+		if err := dr.collect(o, "", da, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return dr, nil
+}
+
+func (dr *defaultResources) fontNames() []string {
+	ss := make([]string, 0, len(dr.fonts))
+	for k := range dr.fonts {
+		ss = append(ss, k)
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// fontDict returns the font dict fontName of the default resources d or nil.
+func (dr *defaultResources) fontDict(d types.Dict, fontName string) types.Dict {
+	if d == nil {
+		return nil
+	}
+	fonts, err := dr.xRefTable.DereferenceDict(d["Font"])
+	if err != nil || fonts == nil {
+		return nil
+	}
+	fd, err := dr.xRefTable.DereferenceDict(fonts[fontName])
+	if err != nil {
+		return nil
+	}
+	return fd
+}
+
+func fieldList(ss []string) string {
+	const n = 3
+	if len(ss) > n {
+		return fmt.Sprintf("%s and %d more", strings.Join(ss[:n], ", "), len(ss)-n)
+	}
+	return strings.Join(ss, ", ")
+}
+
+// DefaultResourceIssues returns a list of fonts referenced by default appearances (/DA) of form fields
+// missing in the default resources (/AcroForm /DR) along with variable text fields lacking a default appearance.
+// Viewers need these fonts to generate the appearances of fields getting filled,
+// missing fonts are a common reason for fields showing no text until retyped.
+func DefaultResourceIssues(ctx *model.Context) ([]string, error) {
+	dr, err := collectDefaultResources(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	d, err := ctx.DereferenceDict(ctx.AcroForm["DR"])
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		ss = append(ss, "missing /AcroForm /DR")
+	}
+
+	for _, fontName := range dr.fontNames() {
+		if dr.fontDict(d, fontName) == nil {
+			ss = append(ss, fmt.Sprintf("font /%s missing in /DR, used by: %s", fontName, fieldList(dr.fonts[fontName])))
+		}
+	}
+
+	if len(dr.noDA) > 0 {
+		ss = append(ss, fmt.Sprintf("missing /DA for: %s", fieldList(dr.noDA)))
+	}
+
+	return ss, nil
+}
+
+// RepairDefaultResources ensures the default resources (/AcroForm /DR) include all fonts referenced
+// by default appearances (/DA) of form fields and returns a list of all repairs made.
+// A missing font gets taken over from field appearance streams using a font of the same name if possible,
+// otherwise a core font like Helvetica gets added as fallback.
+// The form gets the default appearance "/Helv 0 Tf 0 g" if variable text fields lack a default appearance.
+func RepairDefaultResources(ctx *model.Context) ([]string, error) {
+	dr, err := collectDefaultResources(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	if len(dr.noDA) > 0 && ctx.AcroForm.StringOrHexLiteralEntry("DA") == nil {
+		ctx.AcroForm["DA"] = types.StringLiteral(defaultAppearance)
+		dr.fonts[daFontName(defaultAppearance)] = dr.noDA
+		ss = append(ss, fmt.Sprintf("added /AcroForm /DA (%s)", defaultAppearance))
+	}
+
+	d, err := ctx.DereferenceDict(ctx.AcroForm["DR"])
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		d = types.Dict{}
+		ctx.AcroForm["DR"] = d
+		ss = append(ss, "added /AcroForm /DR")
+	}
+
+	fonts, err := ctx.DereferenceDict(d["Font"])
+	if err != nil {
+		return nil, err
+	}
+	if fonts == nil {
+		fonts = types.Dict{}
+		d["Font"] = fonts
+	}
+
+	// Core fonts added mapped to their indirect references.
+	coreFonts := map[string]types.IndirectRef{}
+
+	for _, fontName := range dr.fontNames() {
+		if dr.fontDict(d, fontName) != nil {
+			continue
+		}
+
+		if ir, ok := dr.apFonts[fontName]; ok {
+			if fd, err := ctx.DereferenceDict(ir); err == nil && fd != nil {
+				fonts[fontName] = ir
+				ss = append(ss, fmt.Sprintf("added font /%s (obj#%d) of field appearance to /DR", fontName, ir.ObjectNumber.Value()))
+				continue
+			}
+		}
+
+		baseFont, ok := fallbackFonts[fontName]
+		if !ok {
+			baseFont = "Helvetica"
+		}
+
+		ir, ok := coreFonts[baseFont]
+		if !ok {
+			indRef, err := font.EnsureFontDict(ctx.XRefTable, baseFont, "", "", false, false, nil)
+			if err != nil {
+				return nil, err
+			}
+			ir = *indRef
+			coreFonts[baseFont] = ir
+		}
+
+		fonts[fontName] = ir
+		ss = append(ss, fmt.Sprintf("added fallback font /%s (%s) to /DR", fontName, baseFont))
+	}
+
+	return ss, nil
+}
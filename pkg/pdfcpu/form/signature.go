@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package form
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func inheritedFieldType(xRefTable *model.XRefTable, d types.Dict) (*string, error) {
+	for d != nil {
+		if ft := d.NameEntry("FT"); ft != nil {
+			return ft, nil
+		}
+		ir := d.IndirectRefEntry("Parent")
+		if ir == nil {
+			break
+		}
+		var err error
+		if d, err = xRefTable.DereferenceDict(*ir); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+type sigField struct {
+	indRef  types.IndirectRef
+	id      string
+	signed  bool
+	widgets []types.IndirectRef
+}
+
+func signatureFields(xRefTable *model.XRefTable, fields types.Array) ([]sigField, error) {
+	indRefs, err := annotIndRefs(xRefTable, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var sfs []sigField
+
+	for _, indRef := range indRefs {
+		d, err := xRefTable.DereferenceDict(indRef)
+		if err != nil {
+			return nil, err
+		}
+		ft, err := inheritedFieldType(xRefTable, d)
+		if err != nil {
+			return nil, err
+		}
+		if ft == nil || *ft != "Sig" {
+			continue
+		}
+
+		var id string
+		if _, err := fullyQualifiedFieldName(xRefTable, indRef, fields, &id); err != nil {
+			return nil, err
+		}
+
+		sf := sigField{indRef: indRef, id: id}
+
+		if o, found := d.Find("V"); found {
+			sf.signed = true
+			if ir, ok := o.(types.IndirectRef); ok {
+				if err := xRefTable.FreeObject(ir.ObjectNumber.Value()); err != nil {
+					return nil, err
+				}
+			}
+			d.Delete("V")
+		}
+
+		o, found := d.Find("Kids")
+		if !found {
+			sf.widgets = []types.IndirectRef{indRef}
+		} else {
+			kids, err := xRefTable.DereferenceArray(o)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range kids {
+				if ir, ok := o.(types.IndirectRef); ok {
+					sf.widgets = append(sf.widgets, ir)
+				}
+			}
+		}
+
+		sfs = append(sfs, sf)
+	}
+
+	return sfs, nil
+}
+
+func removeWidgetsFromPages(xRefTable *model.XRefTable, m map[types.IndirectRef]bool) error {
+	for i := 1; i <= xRefTable.PageCount && len(m) > 0; i++ {
+
+		d, _, _, err := xRefTable.PageDict(i, false)
+		if err != nil {
+			return err
+		}
+
+		o, found := d.Find("Annots")
+		if !found {
+			continue
+		}
+
+		arr, err := xRefTable.DereferenceArray(o)
+		if err != nil {
+			return err
+		}
+
+		annots := types.Array{}
+		for _, v := range arr {
+			if ir, ok := v.(types.IndirectRef); ok && m[ir] {
+				delete(m, ir)
+				continue
+			}
+			annots = append(annots, v)
+		}
+
+		if len(annots) == 0 {
+			d.Delete("Annots")
+			continue
+		}
+		d.Update("Annots", annots)
+	}
+
+	return nil
+}
+
+// RemoveSignatures removes all signature fields including their signature values
+// as well as any document level permissions (DocMDP, usage rights) from ctx.
+// This invalidates all existing signatures.
+// The result is a list of removed signature fields.
+func RemoveSignatures(ctx *model.Context) ([]string, error) {
+
+	xRefTable := ctx.XRefTable
+
+	var ss []string
+
+	if xRefTable.AcroForm != nil {
+
+		o, found := xRefTable.AcroForm.Find("Fields")
+		if found {
+
+			fields, err := xRefTable.DereferenceArray(o)
+			if err != nil {
+				return nil, err
+			}
+
+			sfs, err := signatureFields(xRefTable, fields)
+			if err != nil {
+				return nil, err
+			}
+
+			indRefs := make([]types.IndirectRef, len(sfs))
+			m := map[types.IndirectRef]bool{}
+			for i, sf := range sfs {
+				indRefs[i] = sf.indRef
+				for _, ir := range sf.widgets {
+					m[ir] = true
+				}
+				s := sf.id + " (unsigned)"
+				if sf.signed {
+					s = sf.id + " (signed)"
+				}
+				ss = append(ss, s)
+			}
+
+			if len(sfs) > 0 {
+				if err := removeFromFields(xRefTable, &indRefs, &fields); err != nil {
+					return nil, err
+				}
+				xRefTable.AcroForm["Fields"] = fields
+				if err := removeWidgetsFromPages(xRefTable, m); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		xRefTable.AcroForm.Delete("SigFlags")
+	}
+
+	// root -> Perms -> DocMDP, UR3 = Sig dict
+	ctx.RootDict.Delete("Perms")
+
+	xRefTable.SignatureExist = false
+	xRefTable.AppendOnly = false
+
+	return ss, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// markReachable marks all objects reachable from o.
+func markReachable(xRefTable *model.XRefTable, o types.Object, reachable map[int]bool) {
+	for o != nil {
+		switch obj := o.(type) {
+
+		case types.IndirectRef:
+			objNr := obj.ObjectNumber.Value()
+			if reachable[objNr] {
+				return
+			}
+			reachable[objNr] = true
+			entry, found := xRefTable.FindTableEntryForIndRef(&obj)
+			if !found || entry.Free {
+				return
+			}
+			o = entry.Object
+			continue
+
+		case types.Dict:
+			for _, v := range obj {
+				markReachable(xRefTable, v, reachable)
+			}
+
+		case types.StreamDict:
+			for _, v := range obj.Dict {
+				markReachable(xRefTable, v, reachable)
+			}
+
+		case types.Array:
+			for _, v := range obj {
+				markReachable(xRefTable, v, reachable)
+			}
+		}
+
+		return
+	}
+}
+
+// GarbageCollect frees all objects not reachable from the document catalog, the document information dictionary,
+// the encryption dictionary or additional trailer streams.
+// Objects are freed as they are - streams remain encoded, duplicates remain in place and object numbers are kept.
+// The result is a list of all objects freed.
+func GarbageCollect(ctx *model.Context) ([]string, error) {
+	reachable := map[int]bool{}
+
+	for _, ir := range []*types.IndirectRef{ctx.Root, ctx.Info, ctx.Encrypt} {
+		if ir != nil {
+			markReachable(ctx.XRefTable, *ir, reachable)
+		}
+	}
+	if ctx.AdditionalStreams != nil {
+		markReachable(ctx.XRefTable, *ctx.AdditionalStreams, reachable)
+	}
+
+	objNrs := make([]int, 0, len(ctx.Table))
+	for objNr := range ctx.Table {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	var (
+		ss    []string
+		count int
+		size  int64
+	)
+
+	for _, objNr := range objNrs {
+		entry := ctx.Table[objNr]
+		if objNr == 0 || entry.Free || reachable[objNr] || entry.Object == nil {
+			continue
+		}
+		if ctx.Read.IsObjectStreamObject(objNr) || ctx.Read.IsXRefStreamObject(objNr) || ctx.IsLinearizationObject(objNr) {
+			// File structure gets rewritten anyway.
+			continue
+		}
+
+		s := fmt.Sprintf("obj#%d: %T", objNr, entry.Object)
+		if sd, ok := entry.Object.(types.StreamDict); ok {
+			size += int64(len(sd.Raw))
+			s = fmt.Sprintf("obj#%d: stream of %d bytes", objNr, len(sd.Raw))
+		} else if d, ok := entry.Object.(types.Dict); ok && d.Type() != nil {
+			s = fmt.Sprintf("obj#%d: %s", objNr, *d.Type())
+		}
+
+		if err := ctx.FreeObject(objNr); err != nil {
+			return nil, err
+		}
+
+		ss = append(ss, s)
+		count++
+	}
+
+	if count > 0 {
+		ss = append(ss, fmt.Sprintf("freed %d unreferenced objects including %d bytes of stream data", count, size))
+	}
+
+	return ss, nil
+}
@@ -20,6 +20,8 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/pkg/errors"
 )
@@ -231,6 +233,51 @@ func (p iccProfile) tagCount() int {
 	return int(binary.BigEndian.Uint32(p.b[128:]))
 }
 
+func (p iccProfile) valid() bool {
+	return len(p.b) >= 132 && string(p.b[36:40]) == "acsp" && 132+12*p.tagCount() <= len(p.b)
+}
+
+// description returns the profile description of a textDescriptionType (ICC v2) or multiLocalizedUnicodeType (ICC v4) desc tag.
+func (p iccProfile) description() string {
+	if !p.valid() {
+		return ""
+	}
+
+	off, size, err := p.tag("desc")
+	if err != nil || off < 0 || size < 12 || off+size > len(p.b) {
+		return ""
+	}
+	b := p.b[off : off+size]
+
+	switch string(b[0:4]) {
+
+	case "desc":
+		n := int(binary.BigEndian.Uint32(b[8:]))
+		if n > len(b)-12 {
+			n = len(b) - 12
+		}
+		return strings.TrimRight(string(b[12:12+n]), "\x00")
+
+	case "mluc":
+		if len(b) < 28 || binary.BigEndian.Uint32(b[8:]) == 0 {
+			return ""
+		}
+		// Use the first record.
+		l := int(binary.BigEndian.Uint32(b[20:]))
+		o := int(binary.BigEndian.Uint32(b[24:]))
+		if o+l > len(b) {
+			return ""
+		}
+		u := make([]uint16, l/2)
+		for i := range u {
+			u[i] = binary.BigEndian.Uint16(b[o+2*i:])
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	}
+
+	return ""
+}
+
 func (p iccProfile) String() string {
 
 	// profile size: 4 bytes at offset 0 (uintt32)
@@ -209,6 +209,12 @@ func ListImages(ctx *model.Context, selectedPages types.IntSet) ([]string, error
 
 // WriteImageToDisk returns a closure for writing img to disk.
 func WriteImageToDisk(outDir, fileName string) func(model.Image, bool, int) error {
+	return WriteImageToDiskUsing(outDir, fileName, nil)
+}
+
+// WriteImageToDiskUsing returns a closure for writing img to disk named according to conf.OutputNameTemplate.
+func WriteImageToDiskUsing(outDir, fileName string, conf *model.Configuration) func(model.Image, bool, int) error {
+	var index int
 	return func(img model.Image, singleImgPerPage bool, maxPageDigits int) error {
 		if img.Reader == nil {
 			return nil
@@ -225,7 +231,9 @@ func WriteImageToDisk(outDir, fileName string) func(model.Image, bool, int) erro
 		// 	}
 		// 	f = fmt.Sprintf(s+".%s", fileName, img.pageNr, img.FileType)
 		// }
-		outFile := filepath.Join(outDir, f)
+		index++
+		n := model.OutputName{Base: fileName, Page: img.PageNr, Index: index, From: img.PageNr, To: img.PageNr, Ext: img.FileType}
+		outFile := filepath.Join(outDir, conf.OutputFileName(f, n))
 		log.CLI.Printf("writing %s\n", outFile)
 		return WriteReader(outFile, img)
 	}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// imageGeometry returns the width, height, bits per component and number of color components declared by the image XObject sd.
+func imageGeometry(xRefTable *model.XRefTable, sd *types.StreamDict) (int, int, int, int, string) {
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil || *w <= 0 || *h <= 0 {
+		return 0, 0, 0, 0, "missing or invalid /Width or /Height"
+	}
+
+	comps, _, err := imageSampleComponents(xRefTable, sd)
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Sprintf("invalid /ColorSpace: %v", err)
+	}
+
+	if m := sd.BooleanEntry("ImageMask"); m != nil && *m {
+		return *w, *h, 1, 1, ""
+	}
+
+	bpc := sd.IntEntry("BitsPerComponent")
+	if bpc == nil {
+		return 0, 0, 0, 0, "missing /BitsPerComponent"
+	}
+
+	switch *bpc {
+	case 1, 2, 4, 8, 16:
+	default:
+		return 0, 0, 0, 0, fmt.Sprintf("invalid /BitsPerComponent: %d", *bpc)
+	}
+
+	return *w, *h, *bpc, comps, ""
+}
+
+func jpegComponents(cm color.Model) int {
+	switch cm {
+	case color.GrayModel:
+		return 1
+	case color.YCbCrModel:
+		return 3
+	case color.CMYKModel:
+		return 4
+	}
+	return 0
+}
+
+// jpegImageIssue decodes the JPEG data of the image XObject sd and checks it against its declared geometry.
+func jpegImageIssue(sd *types.StreamDict, w, h, comps int) string {
+	// Apply all filters preceding DCTDecode.
+	sd1 := sd.Clone().(types.StreamDict)
+	for i, f := range sd1.FilterPipeline {
+		if f.Name == filter.DCT {
+			sd1.FilterPipeline = sd1.FilterPipeline[:i]
+			break
+		}
+	}
+	sd1.Content = nil
+	if err := sd1.Decode(); err != nil {
+		return fmt.Sprintf("decoding failed: %v", err)
+	}
+
+	c, err := jpeg.DecodeConfig(bytes.NewReader(sd1.Content))
+	if err != nil {
+		return fmt.Sprintf("corrupt JPEG: %v", err)
+	}
+
+	if c.Width != w || c.Height != h {
+		return fmt.Sprintf("JPEG dimensions %dx%d inconsistent with /Width /Height %dx%d", c.Width, c.Height, w, h)
+	}
+
+	if n := jpegComponents(c.ColorModel); comps > 0 && n > 0 && n != comps {
+		return fmt.Sprintf("JPEG color components %d inconsistent with /ColorSpace components %d", n, comps)
+	}
+
+	if _, err := jpeg.Decode(bytes.NewReader(sd1.Content)); err != nil {
+		return fmt.Sprintf("corrupt JPEG: %v", err)
+	}
+
+	return ""
+}
+
+// imageIssue decodes the image XObject sd respecting its filters and returns a description of the first problem found.
+// The result is empty for sound images and images using unsupported filters like JPXDecode, JBIG2Decode or CCITTFaxDecode.
+func imageIssue(xRefTable *model.XRefTable, sd *types.StreamDict) string {
+	for _, f := range sd.FilterPipeline {
+		switch f.Name {
+		case filter.JPX, filter.JBIG2, filter.CCITTFax:
+			return ""
+		}
+	}
+
+	w, h, bpc, comps, s := imageGeometry(xRefTable, sd)
+	if s != "" {
+		return s
+	}
+
+	for _, f := range sd.FilterPipeline {
+		if f.Name == filter.DCT {
+			return jpegImageIssue(sd, w, h, comps)
+		}
+	}
+
+	if comps == 0 {
+		return "missing or unsupported /ColorSpace"
+	}
+
+	sd1 := sd.Clone().(types.StreamDict)
+	sd1.Content = nil
+	if err := sd1.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return ""
+		}
+		return fmt.Sprintf("decoding failed: %v", err)
+	}
+
+	want := (w*comps*bpc + 7) / 8 * h
+	if got := len(sd1.Content); got < want {
+		return fmt.Sprintf("truncated: %d of %d bytes for %dx%d, %d bits per component, %d components", got, want, w, h, bpc, comps)
+	}
+
+	return ""
+}
+
+// ImageIssues decodes all images of selected pages along with their soft masks
+// and returns a list of all images failing to decode, being truncated
+// or having sample data inconsistent with /Width, /Height, /BitsPerComponent and /ColorSpace.
+// Images using unsupported filters like JPXDecode, JBIG2Decode or CCITTFaxDecode are not checked.
+// Requires an optimized context.
+func ImageIssues(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+	checked := map[int]bool{}
+
+	check := func(pageNr, objNr int, kind string) (*types.StreamDict, error) {
+		checked[objNr] = true
+		o, err := ctx.FindObject(objNr)
+		if err != nil {
+			return nil, err
+		}
+		sd, ok := o.(types.StreamDict)
+		if !ok {
+			return nil, nil
+		}
+		if s := imageIssue(ctx.XRefTable, &sd); s != "" {
+			ss = append(ss, fmt.Sprintf("page %d: %s obj#%d: %s", pageNr, kind, objNr, s))
+		}
+		return &sd, nil
+	}
+
+	for _, i := range pageNrs {
+		objNrs := ImageObjNrs(ctx, i)
+		sort.Ints(objNrs)
+		for _, objNr := range objNrs {
+			if checked[objNr] {
+				continue
+			}
+			sd, err := check(i, objNr, "image")
+			if err != nil {
+				return nil, err
+			}
+			if sd == nil {
+				continue
+			}
+			ir := sd.IndirectRefEntry("SMask")
+			if ir == nil || checked[ir.ObjectNumber.Value()] {
+				continue
+			}
+			if _, err := check(i, ir.ObjectNumber.Value(), "soft mask"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ss, nil
+}
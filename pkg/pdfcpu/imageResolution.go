@@ -0,0 +1,232 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// PageResolution represents the effective resolution of the main image of a page,
+// the image covering the largest area of the page.
+type PageResolution struct {
+	PageNr   int
+	ImageID  string  // resource name of the image
+	ObjNr    int     // object number of the image
+	Width    int     // image width in pixels
+	Height   int     // image height in pixels
+	DPIX     float64 // horizontal resolution as rendered
+	DPIY     float64 // vertical resolution as rendered
+	BelowMin bool    // true if the resolution is below the required minimum
+}
+
+// DPI returns the lower one of the horizontal and vertical resolution.
+func (pr PageResolution) DPI() float64 {
+	return math.Min(pr.DPIX, pr.DPIY)
+}
+
+func (pr PageResolution) String() string {
+	s := fmt.Sprintf("page %d: %s obj#%d: %dx%d pixels, %.0f x %.0f dpi", pr.PageNr, pr.ImageID, pr.ObjNr, pr.Width, pr.Height, pr.DPIX, pr.DPIY)
+	if pr.BelowMin {
+		s += " (below minimum)"
+	}
+	return s
+}
+
+// imagePlacement is a rendering of an image XObject into the unit square transformed by ctm.
+type imagePlacement struct {
+	id    string
+	objNr int
+	sd    *types.StreamDict
+	ctm   matrix.Matrix
+}
+
+// area returns the area in user space covered by the image.
+func (ip imagePlacement) area() float64 {
+	m := ip.ctm
+	return math.Abs(m[0][0]*m[1][1] - m[0][1]*m[1][0])
+}
+
+type imagePlacementScan struct {
+	xRefTable  *model.XRefTable
+	placements []imagePlacement
+	forms      map[int]bool // form XObjects on the current path
+}
+
+func (sc *imagePlacementScan) scanForm(ir types.IndirectRef, sd *types.StreamDict, resDict types.Dict, ctm matrix.Matrix) error {
+	objNr := ir.ObjectNumber.Value()
+	if sc.forms[objNr] {
+		return nil
+	}
+	sc.forms[objNr] = true
+	defer delete(sc.forms, objNr)
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	if a, err := sc.xRefTable.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		if ff, ok := numbers(a); ok {
+			ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ctm)
+		}
+	}
+
+	if d, err := sc.xRefTable.DereferenceDict(sd.Dict["Resources"]); err == nil && d != nil {
+		resDict = d
+	}
+
+	return sc.scan(sd.Content, resDict, ctm)
+}
+
+func (sc *imagePlacementScan) scan(content []byte, resDict types.Dict, ctm matrix.Matrix) error {
+	// Best effort: process all operations up to a possible syntax error.
+	ops, _ := model.ParseContentOps(content)
+
+	ts := &textSizer{ctm: ctm, tm: matrix.IdentMatrix, tlm: matrix.IdentMatrix}
+
+	for _, op := range ops {
+		ts.process(op)
+
+		if op.Operator != "Do" || len(op.Operands) != 1 {
+			continue
+		}
+		n, ok := op.Operands[0].(types.Name)
+		if !ok {
+			continue
+		}
+		ir, err := resourceIndRef(sc.xRefTable, resDict, "XObject", n.Value())
+		if err != nil {
+			return err
+		}
+		if ir == nil {
+			continue
+		}
+		sd, _, err := sc.xRefTable.DereferenceStreamDict(*ir)
+		if err != nil {
+			return err
+		}
+		if sd == nil || sd.Subtype() == nil {
+			continue
+		}
+
+		switch *sd.Subtype() {
+		case "Image":
+			sc.placements = append(sc.placements, imagePlacement{id: n.Value(), objNr: ir.ObjectNumber.Value(), sd: sd, ctm: ts.ctm})
+		case "Form":
+			if err := sc.scanForm(*ir, sd, resDict, ts.ctm); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// mainImage returns the image covering the largest area of page pageNr or nil.
+func mainImage(ctx *model.Context, pageNr int) (*imagePlacement, float64, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return nil, 0, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		if err == model.ErrNoContent {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	userUnit := 1.
+	if f, ok := number(d["UserUnit"]); ok && f > 0 {
+		userUnit = f
+	}
+
+	sc := &imagePlacementScan{xRefTable: ctx.XRefTable, forms: map[int]bool{}}
+	if err := sc.scan(bb, inhPAttrs.Resources, matrix.IdentMatrix); err != nil {
+		return nil, 0, err
+	}
+
+	var ip *imagePlacement
+	for i, ip1 := range sc.placements {
+		if ip == nil || ip1.area() > ip.area() {
+			ip = &sc.placements[i]
+		}
+	}
+
+	return ip, userUnit, nil
+}
+
+// PageResolutions returns the effective resolution of the main image of selected pages,
+// the image XObject covering the largest area of the page, computed from its pixel dimensions and its size as rendered.
+// Page content and form XObjects get scanned, inline images are ignored.
+// Pages without images are omitted.
+// Resolutions below minDPI get flagged, minDPI <= 0 disables flagging.
+func PageResolutions(ctx *model.Context, selectedPages types.IntSet, minDPI float64) ([]PageResolution, error) {
+	pageNrs := []int{}
+	for k, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, k)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var prs []PageResolution
+
+	for _, i := range pageNrs {
+		ip, userUnit, err := mainImage(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if ip == nil {
+			continue
+		}
+
+		w, h := ip.sd.IntEntry("Width"), ip.sd.IntEntry("Height")
+		if w == nil || h == nil {
+			continue
+		}
+
+		// The rendered size of the image in inches.
+		m := ip.ctm
+		iw := math.Hypot(m[0][0], m[0][1]) * userUnit / 72
+		ih := math.Hypot(m[1][0], m[1][1]) * userUnit / 72
+		if iw == 0 || ih == 0 {
+			continue
+		}
+
+		pr := PageResolution{
+			PageNr:  i,
+			ImageID: ip.id,
+			ObjNr:   ip.objNr,
+			Width:   *w,
+			Height:  *h,
+			DPIX:    float64(*w) / iw,
+			DPIY:    float64(*h) / ih,
+		}
+		pr.BelowMin = minDPI > 0 && math.Round(pr.DPI()) < minDPI
+
+		prs = append(prs, pr)
+	}
+
+	return prs, nil
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"math"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// imageSamples represents the decoded sample data of an image XObject.
+type imageSamples struct {
+	w, h, bpc, comps int
+	data             []byte
+	dct              bool // decoded from DCTDecode
+	indexed          bool // samples are color table indices
+}
+
+func (im *imageSamples) stride() int {
+	return (im.w*im.comps*im.bpc + 7) / 8
+}
+
+// clear sets all samples within r to zero.
+// For less than 8 bits per component all bytes touched by r are cleared.
+func (im *imageSamples) clear(r image.Rectangle) {
+	r = r.Intersect(image.Rect(0, 0, im.w, im.h))
+	bitsPerPixel := im.comps * im.bpc
+	from, thru := r.Min.X*bitsPerPixel/8, (r.Max.X*bitsPerPixel+7)/8
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := im.data[y*im.stride():]
+		for i := from; i < thru; i++ {
+			row[i] = 0
+		}
+	}
+}
+
+func imageSampleComponents(xRefTable *model.XRefTable, sd *types.StreamDict) (int, bool, error) {
+	if im := sd.BooleanEntry("ImageMask"); im != nil && *im {
+		return 1, false, nil
+	}
+	o, err := xRefTable.Dereference(sd.Dict["ColorSpace"])
+	if err != nil {
+		return 0, false, err
+	}
+	if a, ok := o.(types.Array); ok && len(a) > 0 {
+		if n, ok := a[0].(types.Name); ok && n == model.IndexedCS {
+			return 1, true, nil
+		}
+	}
+	n, err := ColorSpaceComponents(xRefTable, sd)
+	return n, false, err
+}
+
+func decodeDCTSamples(im *imageSamples, raw []byte) bool {
+	img, err := jpeg.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return false
+	}
+
+	b := img.Bounds()
+	if b.Dx() != im.w || b.Dy() != im.h {
+		return false
+	}
+
+	switch img := img.(type) {
+
+	case *image.Gray:
+		if im.comps != 1 {
+			return false
+		}
+		im.data = make([]byte, 0, im.w*im.h)
+		for y := 0; y < im.h; y++ {
+			im.data = append(im.data, img.Pix[y*img.Stride:y*img.Stride+im.w]...)
+		}
+
+	case *image.YCbCr:
+		if im.comps != 3 {
+			return false
+		}
+		im.data = make([]byte, 0, 3*im.w*im.h)
+		for y := 0; y < im.h; y++ {
+			for x := 0; x < im.w; x++ {
+				r, g, b, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+				im.data = append(im.data, byte(r>>8), byte(g>>8), byte(b>>8))
+			}
+		}
+
+	default:
+		// CMYK JPEGs need special handling of Adobe inverted samples.
+		return false
+	}
+
+	im.bpc, im.dct = 8, true
+	return true
+}
+
+// pixelate replaces the samples within r by the average of blocks of size x size pixels.
+// For indexed images the first sample of a block is used instead.
+// The result is false for images using other than 8 bits per component.
+func (im *imageSamples) pixelate(r image.Rectangle, size int) bool {
+	if im.bpc != 8 {
+		return false
+	}
+
+	r = r.Intersect(image.Rect(0, 0, im.w, im.h))
+	sum := make([]int, im.comps)
+
+	for y0 := r.Min.Y; y0 < r.Max.Y; y0 += size {
+		y1 := y0 + size
+		if y1 > r.Max.Y {
+			y1 = r.Max.Y
+		}
+		for x0 := r.Min.X; x0 < r.Max.X; x0 += size {
+			x1 := x0 + size
+			if x1 > r.Max.X {
+				x1 = r.Max.X
+			}
+			for c := range sum {
+				sum[c] = 0
+			}
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					for c := range sum {
+						sum[c] += int(im.data[y*im.stride()+x*im.comps+c])
+					}
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			if im.indexed {
+				sum[0], n = int(im.data[y0*im.stride()+x0]), 1
+			}
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					for c := range sum {
+						im.data[y*im.stride()+x*im.comps+c] = byte(sum[c] / n)
+					}
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// decodeImageSamples returns the sample data of the image XObject sd.
+// The result is nil for images using unsupported filters like JPXDecode, JBIG2Decode, CCITTFaxDecode
+// or DCTDecode for anything other than gray and RGB JPEGs.
+func decodeImageSamples(xRefTable *model.XRefTable, sd *types.StreamDict) (*imageSamples, error) {
+	w, h := sd.IntEntry("Width"), sd.IntEntry("Height")
+	if w == nil || h == nil || *w <= 0 || *h <= 0 {
+		return nil, nil
+	}
+
+	comps, indexed, err := imageSampleComponents(xRefTable, sd)
+	if err != nil || comps == 0 {
+		return nil, err
+	}
+
+	im := &imageSamples{w: *w, h: *h, bpc: 8, comps: comps, indexed: indexed}
+	if bpc := sd.IntEntry("BitsPerComponent"); bpc != nil {
+		im.bpc = *bpc
+	}
+	if m := sd.BooleanEntry("ImageMask"); m != nil && *m {
+		im.bpc = 1
+	}
+
+	fpl := sd.FilterPipeline
+	if len(fpl) == 1 && fpl[0].Name == filter.DCT {
+		if !decodeDCTSamples(im, sd.Raw) {
+			return nil, nil
+		}
+		return im, nil
+	}
+
+	for _, f := range fpl {
+		switch f.Name {
+		case filter.DCT, filter.JPX, filter.JBIG2, filter.CCITTFax:
+			return nil, nil
+		}
+	}
+
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+
+	if len(sd.Content) < im.stride()*im.h {
+		return nil, nil
+	}
+
+	im.data = append([]byte{}, sd.Content[:im.stride()*im.h]...)
+
+	return im, nil
+}
+
+// streamDict returns a Flate encoded image XObject for im using the image attributes of sd.
+func (im *imageSamples) streamDict(xRefTable *model.XRefTable, sd *types.StreamDict) (*types.StreamDict, error) {
+	sd1, _ := xRefTable.NewStreamDictForBuf(im.data)
+	for k, v := range sd.Dict {
+		switch k {
+		case "Filter", "DecodeParms", "Length":
+			continue
+		}
+		sd1.Dict[k] = v
+	}
+	if im.dct {
+		sd1.Dict["BitsPerComponent"] = types.Integer(8)
+	}
+	if err := sd1.Encode(); err != nil {
+		return nil, err
+	}
+	return sd1, nil
+}
+
+// jpegStreamDict returns a DCT encoded image XObject of given quality for the gray or RGB samples of im using the image attributes of sd.
+func (im *imageSamples) jpegStreamDict(sd *types.StreamDict, quality int) (*types.StreamDict, error) {
+	var img image.Image
+	r := image.Rect(0, 0, im.w, im.h)
+
+	switch im.comps {
+	case 1:
+		img = &image.Gray{Pix: im.data, Stride: im.w, Rect: r}
+	case 3:
+		rgba := image.NewRGBA(r)
+		for i, j := 0, 0; i+2 < len(im.data); i, j = i+3, j+4 {
+			rgba.Pix[j], rgba.Pix[j+1], rgba.Pix[j+2], rgba.Pix[j+3] = im.data[i], im.data[i+1], im.data[i+2], 0xFF
+		}
+		img = rgba
+	default:
+		return nil, errors.Errorf("pdfcpu: unexpected number of color components for JPEG: %d", im.comps)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	sd1 := &types.StreamDict{Dict: types.NewDict(), Content: buf.Bytes()}
+	for k, v := range sd.Dict {
+		switch k {
+		case "Filter", "DecodeParms", "Length":
+			continue
+		}
+		sd1.Dict[k] = v
+	}
+	sd1.InsertName("Filter", filter.DCT)
+
+	// Calling Encode without FilterPipeline ensures an encoded stream in sd.Raw.
+	if err := sd1.Encode(); err != nil {
+		return nil, err
+	}
+
+	sd1.Content = nil
+	sd1.FilterPipeline = []types.PDFFilter{{Name: filter.DCT, DecodeParms: nil}}
+
+	return sd1, nil
+}
+
+// imagePixelRect returns the pixel region of an image of w x h pixels covered by r
+// for an image rendered into the unit square transformed by ctm.
+func imagePixelRect(ctm matrix.Matrix, w, h int, r types.Rectangle) (image.Rectangle, bool) {
+	inv, ok := ctm.Invert()
+	if !ok {
+		return image.Rectangle{}, false
+	}
+
+	u0, v0, u1, v1 := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range []types.Point{r.LL, {X: r.UR.X, Y: r.LL.Y}, r.UR, {X: r.LL.X, Y: r.UR.Y}} {
+		p = inv.Transform(p)
+		u0, u1 = math.Min(u0, p.X), math.Max(u1, p.X)
+		v0, v1 = math.Min(v0, p.Y), math.Max(v1, p.Y)
+	}
+
+	clamp := func(f float64) float64 { return math.Max(0, math.Min(1, f)) }
+	u0, u1, v0, v1 = clamp(u0), clamp(u1), clamp(v0), clamp(v1)
+
+	// Image space has its origin in the upper left corner.
+	pr := image.Rect(
+		int(math.Floor(u0*float64(w))), int(math.Floor((1-v1)*float64(h))),
+		int(math.Ceil(u1*float64(w))), int(math.Ceil((1-v0)*float64(h))))
+
+	return pr, !pr.Empty()
+}
@@ -153,6 +153,14 @@ func writeDocumentInfoDict(ctx *model.Context) error {
 		return err
 	}
 
+	if ctx.CanonicalInfoDict {
+		if err = writeCanonicalInfoDict(ctx, o, d); err != nil {
+			return err
+		}
+		log.Write.Printf("*** writeDocumentInfoDict end: offset=%d ***\n", ctx.Write.Offset)
+		return nil
+	}
+
 	_, _, err = writeDeepObject(ctx, o)
 	if err != nil {
 		return err
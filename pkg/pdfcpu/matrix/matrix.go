@@ -52,6 +52,21 @@ func (m Matrix) Transform(p types.Point) types.Point {
 	return types.Point{X: x, Y: y}
 }
 
+// Invert returns the inverse of the affine transformation m.
+// The result is false if m is not invertible.
+func (m Matrix) Invert() (Matrix, bool) {
+	a, b, c, d, e, f := m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1]
+	det := a*d - b*c
+	if det == 0 {
+		return Matrix{}, false
+	}
+	return Matrix{
+		{d / det, -b / det, 0},
+		{-c / det, a / det, 0},
+		{(c*f - d*e) / det, (b*e - a*f) / det, 1},
+	}, true
+}
+
 func (m Matrix) String() string {
 	return fmt.Sprintf("%3.2f %3.2f %3.2f\n%3.2f %3.2f %3.2f\n%3.2f %3.2f %3.2f\n",
 		m[0][0], m[0][1], m[0][2],
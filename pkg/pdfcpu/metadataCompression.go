@@ -0,0 +1,79 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// compressMetadataStream applies compression to the metadata stream sd
+// and returns false if there is nothing to do.
+func compressMetadataStream(sd *types.StreamDict, compress bool) (bool, error) {
+	if compress == (len(sd.FilterPipeline) > 0) {
+		return false, nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return false, nil
+		}
+		return false, err
+	}
+
+	sd.FilterPipeline = nil
+	sd.Delete("Filter")
+	sd.Delete("DecodeParms")
+
+	if compress {
+		sd.FilterPipeline = []types.PDFFilter{{Name: filter.Flate, DecodeParms: nil}}
+		sd.InsertName("Filter", filter.Flate)
+	}
+
+	return true, sd.Encode()
+}
+
+// applyMetadataCompression compresses or uncompresses all metadata streams according to ctx.CompressMetadata.
+func applyMetadataCompression(ctx *model.Context) error {
+	if ctx.CompressMetadata != model.MetadataCompress && ctx.CompressMetadata != model.MetadataUncompress {
+		return nil
+	}
+
+	compress := ctx.CompressMetadata == model.MetadataCompress
+
+	for objNr, entry := range ctx.Table {
+		if entry.Free || entry.Compressed {
+			continue
+		}
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || sd.Type() == nil || *sd.Type() != "Metadata" {
+			continue
+		}
+		ok, err := compressMetadataStream(&sd, compress)
+		if err != nil {
+			return err
+		}
+		if ok {
+			entry.Object = sd
+			log.Write.Printf("applyMetadataCompression: obj#%d compressed: %t\n", objNr, compress)
+		}
+	}
+
+	return nil
+}
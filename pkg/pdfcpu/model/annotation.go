@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ex-preman/pdfcpu/pkg/font"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/color"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
 )
@@ -532,3 +533,121 @@ func (ann LinkAnnotation) RenderDict(xRefTable *XRefTable, pageIndRef types.Indi
 	}
 	return d, nil
 }
+
+// SignatureFieldAnnotation represents an unsigned signature field merged with its widget annotation.
+type SignatureFieldAnnotation struct {
+	Annotation
+	FieldName string             // The partial field name.
+	Caption   string             // Placeholder text rendered into the appearance stream.
+	BorderCol *color.SimpleColor // Border color of the appearance box, no border if nil.
+}
+
+// NewSignatureFieldAnnotation returns a new signature field widget annotation.
+func NewSignatureFieldAnnotation(
+	rect types.Rectangle,
+	fieldName, caption, id string,
+	f AnnotationFlags,
+	bgCol, borderCol *color.SimpleColor) SignatureFieldAnnotation {
+
+	ann := NewAnnotation(AnnWidget, rect, "", nil, id, f, bgCol)
+
+	return SignatureFieldAnnotation{
+		Annotation: ann,
+		FieldName:  fieldName,
+		Caption:    caption,
+		BorderCol:  borderCol,
+	}
+}
+
+// ContentString returns a string representation of ann's content.
+func (ann SignatureFieldAnnotation) ContentString() string {
+	return "Signature field \"" + ann.FieldName + "\""
+}
+
+func (ann SignatureFieldAnnotation) appearanceStream(xRefTable *XRefTable) (*types.IndirectRef, error) {
+	w, h := ann.Rect.Width(), ann.Rect.Height()
+
+	fontName := "Helvetica"
+	fontSize := int(h / 3)
+	if fontSize > 12 {
+		fontSize = 12
+	}
+
+	s := "q "
+	if ann.C != nil {
+		s += fmt.Sprintf("%.2f %.2f %.2f rg 0 0 %.2f %.2f re f ", ann.C.R, ann.C.G, ann.C.B, w, h)
+	}
+	if ann.BorderCol != nil {
+		s += fmt.Sprintf("%.2f %.2f %.2f RG 1 w 0.5 0.5 %.2f %.2f re S ", ann.BorderCol.R, ann.BorderCol.G, ann.BorderCol.B, w-1, h-1)
+	}
+	if ann.Caption != "" && fontSize > 0 {
+		caption, err := types.Escape(ann.Caption)
+		if err != nil {
+			return nil, err
+		}
+		tw := font.TextWidth(ann.Caption, fontName, fontSize)
+		x := (w - tw) / 2
+		if x < 2 {
+			x = 2
+		}
+		y := (h-font.Ascent(fontName, fontSize))/2 + font.Descent(fontName, fontSize)
+		s += fmt.Sprintf("BT /F1 %d Tf 0.5 g %.2f %.2f Td (%s) Tj ET ", fontSize, x, y, *caption)
+	}
+	s += "Q"
+
+	fontDict := types.Dict(map[string]types.Object{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name(fontName),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	})
+	fontIndRef, err := xRefTable.IndRefForNewObject(fontDict)
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := xRefTable.NewStreamDictForBuf([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.InsertInt("FormType", 1)
+	sd.Insert("BBox", types.NewNumberArray(0, 0, w, h))
+	sd.Insert("Matrix", types.NewNumberArray(1, 0, 0, 1, 0, 0))
+	sd.Insert("Resources", types.Dict(map[string]types.Object{
+		"Font": types.Dict(map[string]types.Object{"F1": *fontIndRef}),
+	}))
+
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	return xRefTable.IndRefForNewObject(*sd)
+}
+
+// RenderDict renders ann into a merged signature field and widget annotation dict.
+func (ann SignatureFieldAnnotation) RenderDict(xRefTable *XRefTable, pageIndRef types.IndirectRef) (types.Dict, error) {
+	apIndRef, err := ann.appearanceStream(xRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	d := types.Dict(map[string]types.Object{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name(ann.TypeString()),
+		"FT":      types.Name("Sig"),
+		"T":       types.StringLiteral(ann.FieldName),
+		"Rect":    ann.Rect.Array(),
+		"P":       pageIndRef,
+		"F":       types.Integer(ann.F),
+		"AP":      types.Dict(map[string]types.Object{"N": *apIndRef}),
+	})
+
+	if ann.NM != "" {
+		d.InsertString("NM", ann.NM)
+	}
+
+	return d, nil
+}
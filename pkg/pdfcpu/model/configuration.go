@@ -39,6 +39,17 @@ const (
 	ValidationNone
 )
 
+const (
+	// MetadataPreserve writes metadata streams as read.
+	MetadataPreserve int = iota
+
+	// MetadataCompress writes all metadata streams compressed.
+	MetadataCompress
+
+	// MetadataUncompress writes all metadata streams uncompressed, as recommended for indexability.
+	MetadataUncompress
+)
+
 const (
 
 	// StatsFileNameDefault is the standard stats filename.
@@ -122,6 +133,78 @@ const (
 	INSTALLFONTS
 	LISTFONTS
 	RESIZE
+	ADDSIGNATUREFIELD
+	UNSIGN
+	EMBEDFONTS
+	LISTMISSINGGLYPHS
+	REPAIRTOUNICODE
+	MERGESELECTED
+	ASSEMBLE
+	REPAIRPAGES
+	RECOLORWATERMARKS
+	MEASURETEXT
+	EXTRACTEPS
+	NORMALIZEROTATION
+	CLEARRESTRICTIONS
+	HASHPAGES
+	PREPARETIMESTAMP
+	EMBEDTIMESTAMP
+	ADDDSS
+	LISTDEGENERATEPAGES
+	REMOVEDEGENERATEPAGES
+	LISTOVERSIZEDPAGES
+	CLAMPPAGES
+	LISTVIEWERPREFERENCES
+	SETPRINTPREFERENCES
+	FILLTEMPLATE
+	LISTDOCUMENTFONTS
+	CONVERTTYPE3FONTS
+	LISTSPLITCONTENT
+	RESPLITCONTENT
+	LISTBROKENDESTINATIONS
+	FIXBROKENDESTINATIONS
+	EXTRACTICCPROFILES
+	LISTREADINGORDERISSUES
+	AUTOTAG
+	EXTRACTPARAGRAPHS
+	REDACT
+	PIXELATE
+	SETSTRUCTLANG
+	LISTPAGEROTATIONS
+	FIXPAGEROTATIONS
+	GARBAGECOLLECT
+	LISTDANGLINGREFS
+	NULLDANGLINGREFS
+	TRUNCATEPAGES
+	COUNTPAGES
+	ESTIMATECOST
+	SHARERESOURCES
+	LISTOPERATORS
+	LISTARTIFACTCANDIDATES
+	TAGARTIFACTS
+	LISTFORMDRISSUES
+	REPAIRFORMDR
+	REGENERATEAPPEARANCES
+	LISTCALCORDER
+	SETCALCORDER
+	LISTFIELDACTIONS
+	FLATTENFORM
+	STRIPSTRUCTURE
+	CHECKIMAGES
+	CHECKANNOTATIONS
+	CLAMPANNOTATIONS
+	EXPORTFIELDPOSITIONS
+	CHECKSCANNED
+	TILEPAGE
+	LISTRESOLUTIONS
+	SETXMPTHUMBNAIL
+	LISTVERSIONFEATURES
+	ADDPAGENUMBERS
+	LISTDUPLICATEANNOTATIONS
+	REMOVEDUPLICATEANNOTATIONS
+	INLINENAMEDDESTINATIONS
+	NAMEEXPLICITDESTINATIONS
+	EXTRACTBUNDLE
 )
 
 // Configuration of a Context.
@@ -199,6 +282,43 @@ type Configuration struct {
 
 	// Optimize duplicate content streams across pages.
 	OptimizeDuplicateContentStreams bool
+
+	// Merge multi part page content streams into one when optimizing.
+	MergeContentStreams bool
+
+	// Write a balanced page tree.
+	BalancePageTree bool
+
+	// Maximum number of kids per page tree node for BalancePageTree, defaults to DefaultPageTreeBranchingFactor.
+	PageTreeBranchingFactor int
+
+	// File name template for commands writing multiple files, see OutputFileName.
+	// If empty each command uses its built-in naming scheme.
+	OutputNameTemplate string
+
+	// Maximum number of pages accepted when reading, 0 means no limit.
+	MaxPages int
+
+	// Maximum number of annotations accepted when reading, 0 means no limit.
+	MaxAnnotations int
+
+	// Maximum number of form fields accepted when reading, 0 means no limit.
+	MaxFormFields int
+
+	// Compression of metadata streams for writing: preserve, compress or uncompress.
+	CompressMetadata int
+
+	// Quality 1..100 for re-encoding DCT encoded images when optimizing, 0 means no re-encoding.
+	JPEGQuality int
+
+	// Extract the soft masks (/SMask) of images as separate grayscale images when extracting images.
+	ExtractSoftMasks bool
+
+	// Apply the soft masks (/SMask) of images as alpha channel resulting in RGBA PNGs when extracting images.
+	ApplySoftMasks bool
+
+	// Write the document info dict with standard entries in spec order followed by custom entries sorted and text values trimmed.
+	CanonicalInfoDict bool
 }
 
 // ConfigPath defines the location of pdfcpu's configuration directory.
@@ -270,6 +390,18 @@ func newDefaultConfiguration() *Configuration {
 		DateFormat:                      "2006-01-02",
 		HeaderBufSize:                   100,
 		OptimizeDuplicateContentStreams: false,
+		MergeContentStreams:             false,
+		BalancePageTree:                 false,
+		PageTreeBranchingFactor:         DefaultPageTreeBranchingFactor,
+		OutputNameTemplate:              "",
+		MaxPages:                        0,
+		MaxAnnotations:                  0,
+		MaxFormFields:                   0,
+		CompressMetadata:                MetadataPreserve,
+		JPEGQuality:                     0,
+		ExtractSoftMasks:                false,
+		ApplySoftMasks:                  false,
+		CanonicalInfoDict:               false,
 	}
 }
 
@@ -336,7 +468,19 @@ func (c Configuration) String() string {
 		"TimestampFormat:	%s\n"+
 		"DateFormat:		%s\n"+
 		"HeaderBufSize:		%d\n"+
-		"OptimizeDuplicateContentStreams %t\n",
+		"OptimizeDuplicateContentStreams %t\n"+
+		"MergeContentStreams: %t\n"+
+		"BalancePageTree:	%t\n"+
+		"PageTreeBranchingFactor: %d\n"+
+		"OutputNameTemplate: %s\n"+
+		"MaxPages:          %d\n"+
+		"MaxAnnotations:    %d\n"+
+		"MaxFormFields:     %d\n"+
+		"CompressMetadata:  %s\n"+
+		"JPEGQuality:       %d\n"+
+		"ExtractSoftMasks:  %t\n"+
+		"ApplySoftMasks:    %t\n"+
+		"CanonicalInfoDict: %t\n",
 		path,
 		c.CheckFileNameExt,
 		c.Reader15,
@@ -353,6 +497,18 @@ func (c Configuration) String() string {
 		c.DateFormat,
 		c.HeaderBufSize,
 		c.OptimizeDuplicateContentStreams,
+		c.MergeContentStreams,
+		c.BalancePageTree,
+		c.PageTreeBranchingFactor,
+		c.OutputNameTemplate,
+		c.MaxPages,
+		c.MaxAnnotations,
+		c.MaxFormFields,
+		c.CompressMetadataString(),
+		c.JPEGQuality,
+		c.ExtractSoftMasks,
+		c.ApplySoftMasks,
+		c.CanonicalInfoDict,
 	)
 }
 
@@ -381,6 +537,17 @@ func (c *Configuration) ValidationModeString() string {
 	return "none"
 }
 
+// CompressMetadataString returns a string rep for the metadata stream compression in effect.
+func (c *Configuration) CompressMetadataString() string {
+	switch c.CompressMetadata {
+	case MetadataCompress:
+		return "always"
+	case MetadataUncompress:
+		return "never"
+	}
+	return "preserve"
+}
+
 // UnitString returns a string rep for the display unit in effect.
 func (c *Configuration) UnitString() string {
 	var s string
@@ -400,7 +567,7 @@ func (c *Configuration) UnitString() string {
 // ApplyReducedFeatureSet returns true if complex entries like annotations shall not be written.
 func (c *Configuration) ApplyReducedFeatureSet() bool {
 	switch c.Cmd {
-	case SPLIT, TRIM, EXTRACTPAGES, MERGECREATE, MERGEAPPEND, IMPORTIMAGES:
+	case SPLIT, TRIM, EXTRACTPAGES, MERGECREATE, MERGEAPPEND, MERGESELECTED, ASSEMBLE, IMPORTIMAGES:
 		return true
 	}
 	return false
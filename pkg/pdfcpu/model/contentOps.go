@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+var errInlineImageCorrupt = errors.New("pdfcpu: corrupt inline image")
+
+// ContentOp represents a content stream operation: an operator along with its operands.
+type ContentOp struct {
+	Operator string
+	Operands []types.Object
+	Data     []byte // inline image data for BI operations.
+}
+
+func skipWhitespaceAndComments(s string) string {
+	for len(s) > 0 {
+		if whitespaceOrEOL(rune(s[0])) {
+			s = s[1:]
+			continue
+		}
+		if s[0] == '%' {
+			i := strings.IndexAny(s, "\x0A\x0D")
+			if i < 0 {
+				return ""
+			}
+			s = s[i:]
+			continue
+		}
+		break
+	}
+	return s
+}
+
+func nextContentKeyword(s string) (string, string) {
+	i, _ := positionToNextWhitespaceOrChar(s, "/<>()[]{}%")
+	if i <= 0 {
+		if i == 0 {
+			// Stray delimiter like { or }.
+			return s[:1], s[1:]
+		}
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func numericContentOperand(tok string) (types.Object, bool) {
+	if i, err := strconv.Atoi(tok); err == nil {
+		return types.Integer(i), true
+	}
+	if f, err := strconv.ParseFloat(tok, 64); err == nil {
+		return types.Float(f), true
+	}
+	return nil, false
+}
+
+func nextContentOperand(s *string) (types.Object, string, error) {
+	l := *s
+	switch l[0] {
+	case '[', '/', '(', '<':
+		o, err := ParseObject(&l)
+		if err != nil {
+			return nil, "", err
+		}
+		*s = l
+		return o, "", nil
+	}
+
+	tok, l := nextContentKeyword(l)
+	*s = l
+
+	if o, ok := numericContentOperand(tok); ok {
+		return o, "", nil
+	}
+
+	switch tok {
+	case "true":
+		return types.Boolean(true), "", nil
+	case "false":
+		return types.Boolean(false), "", nil
+	}
+
+	// operator
+	return nil, tok, nil
+}
+
+func inlineImageEnd(s string) int {
+	for i := 0; i+2 <= len(s); i++ {
+		if s[i] != 'E' || s[i+1] != 'I' {
+			continue
+		}
+		if i > 0 && !whitespaceOrEOL(rune(s[i-1])) {
+			continue
+		}
+		if i+2 < len(s) && !whitespaceOrEOL(rune(s[i+2])) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func parseInlineImage(s *string) (ContentOp, error) {
+	op := ContentOp{Operator: "BI"}
+	d := types.NewDict()
+	l := *s
+
+	for {
+		l = skipWhitespaceAndComments(l)
+		if len(l) == 0 {
+			return op, errInlineImageCorrupt
+		}
+		if strings.HasPrefix(l, "ID") && (len(l) == 2 || whitespaceOrEOL(rune(l[2]))) {
+			l = l[2:]
+			break
+		}
+		o, err := ParseObject(&l)
+		if err != nil {
+			return op, err
+		}
+		k, ok := o.(types.Name)
+		if !ok {
+			return op, errInlineImageCorrupt
+		}
+		l = skipWhitespaceAndComments(l)
+		if len(l) == 0 {
+			return op, errInlineImageCorrupt
+		}
+		v, tok, err := nextContentOperand(&l)
+		if err != nil {
+			return op, err
+		}
+		if tok != "" {
+			v = types.Name(tok)
+		}
+		d[string(k)] = v
+	}
+
+	// A single whitespace char separates ID from the image data.
+	if len(l) > 0 {
+		l = l[1:]
+	}
+
+	i := inlineImageEnd(l)
+	if i < 0 {
+		return op, errInlineImageCorrupt
+	}
+
+	data := l[:i]
+	if n := len(data); n > 0 && whitespaceOrEOL(rune(data[n-1])) {
+		data = data[:n-1]
+	}
+
+	op.Operands = []types.Object{d}
+	op.Data = []byte(data)
+	*s = l[i+2:]
+
+	return op, nil
+}
+
+// ParseContentOps parses the operations of a content stream.
+// On error the operations parsed so far are returned.
+func ParseContentOps(bb []byte) ([]ContentOp, error) {
+	var (
+		ops      []ContentOp
+		operands []types.Object
+	)
+
+	s := string(bb)
+
+	for {
+		s = skipWhitespaceAndComments(s)
+		if len(s) == 0 {
+			break
+		}
+
+		o, tok, err := nextContentOperand(&s)
+		if err != nil {
+			return ops, errors.Wrap(errPageContentCorrupt, err.Error())
+		}
+
+		if tok == "" {
+			operands = append(operands, o)
+			continue
+		}
+
+		if tok == "BI" {
+			if len(operands) > 0 {
+				return ops, errPageContentCorrupt
+			}
+			op, err := parseInlineImage(&s)
+			if err != nil {
+				return ops, err
+			}
+			ops = append(ops, op)
+			continue
+		}
+
+		if tok == "null" {
+			operands = append(operands, nil)
+			continue
+		}
+
+		ops = append(ops, ContentOp{Operator: tok, Operands: operands})
+		operands = nil
+	}
+
+	if len(operands) > 0 {
+		// Operands without operator.
+		return ops, errPageContentCorrupt
+	}
+
+	return ops, nil
+}
+
+func contentOperandString(o types.Object) string {
+	switch o := o.(type) {
+	case nil:
+		return "null"
+	case types.Float:
+		return strconv.FormatFloat(o.Value(), 'f', -1, 64)
+	case types.Array:
+		ss := make([]string, len(o))
+		for i, v := range o {
+			ss[i] = contentOperandString(v)
+		}
+		return "[" + strings.Join(ss, " ") + "]"
+	case types.Dict:
+		return "<<" + contentDictEntries(o) + ">>"
+	}
+	return o.PDFString()
+}
+
+func contentDictEntries(d types.Dict) string {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ss := make([]string, len(keys))
+	for i, k := range keys {
+		ss[i] = "/" + k + " " + contentOperandString(d[k])
+	}
+	return strings.Join(ss, " ")
+}
+
+// String returns a string representation of op as found in a content stream.
+func (op ContentOp) String() string {
+	if op.Operator == "BI" {
+		s := "BI"
+		if len(op.Operands) == 1 {
+			if d, ok := op.Operands[0].(types.Dict); ok && len(d) > 0 {
+				s += " " + contentDictEntries(d)
+			}
+		}
+		return s + " ID\n" + string(op.Data) + "\nEI"
+	}
+	ss := make([]string, 0, len(op.Operands)+1)
+	for _, o := range op.Operands {
+		ss = append(ss, contentOperandString(o))
+	}
+	ss = append(ss, op.Operator)
+	return strings.Join(ss, " ")
+}
+
+// ContentOpsBytes renders ops into a content stream.
+func ContentOpsBytes(ops []ContentOp) []byte {
+	var b bytes.Buffer
+	for _, op := range ops {
+		b.WriteString(op.String())
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
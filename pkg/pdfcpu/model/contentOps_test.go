@@ -0,0 +1,60 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContentOps(t *testing.T) {
+	s := `q 1 0 0 1 72.5 -3 cm 0.5 g 1 0 0 RG % comment
+	BT /F1 12 Tf 10 20 Td (Hello \) World) Tj [(A) -120 (B)] TJ ET
+	/Span <</ActualText <FEFF0041>>> BDC EMC
+	BI /W 2 /H 1 /BPC 8 /CS /G ID ab EI Q`
+
+	ops, err := ParseContentOps([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"q", "cm", "g", "RG", "BT", "Tf", "Td", "Tj", "TJ", "ET", "BDC", "EMC", "BI", "Q"}
+	if len(ops) != len(want) {
+		t.Fatalf("want %d ops, got %d: %v\n", len(want), len(ops), ops)
+	}
+	for i, op := range ops {
+		if op.Operator != want[i] {
+			t.Fatalf("op %d: want %s, got %s\n", i, want[i], op.Operator)
+		}
+	}
+	if string(ops[12].Data) != "ab" {
+		t.Fatalf("inline image data: want ab, got %s\n", ops[12].Data)
+	}
+
+	// Round trip.
+	ops2, err := ParseContentOps(ContentOpsBytes(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ops, ops2) {
+		t.Fatalf("round trip mismatch:\n%v\n%v\n", ops, ops2)
+	}
+
+	if _, err := ParseContentOps([]byte("q 1 0 0")); err == nil {
+		t.Fatal("expected error for operands without operator")
+	}
+}
@@ -217,6 +217,7 @@ type ReadContext struct {
 	ObjectStreams       types.IntSet  // All object numbers of any object streams found which need to be decoded.
 	UsingXRefStreams    bool          // File is using xref streams.
 	XRefStreams         types.IntSet  // All object numbers of any xref streams found.
+	XRefTableFree       types.IntSet  // Object numbers marked free by the xref table of the hybrid xref section being processed.
 }
 
 func newReadContext(rs io.ReadSeeker) (*ReadContext, error) {
@@ -225,6 +226,7 @@ func newReadContext(rs io.ReadSeeker) (*ReadContext, error) {
 		RS:            rs,
 		ObjectStreams: types.IntSet{},
 		XRefStreams:   types.IntSet{},
+		XRefTableFree: types.IntSet{},
 	}
 
 	fileSize, err := rs.Seek(0, io.SeekEnd)
@@ -0,0 +1,206 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// MergeSelection represents an input file along with the pages to be merged.
+type MergeSelection struct {
+	InFile        string
+	PageSelection []string // page sequence as used by collect, nil selects all pages.
+}
+
+// AssembleOp represents a single operation of an assemble manifest.
+//
+// insert:    appends the selected pages of File (all pages if Pages is empty).
+// blank:     appends Count blank pages of PaperSize (defaults to A4).
+// rotate:    rotates the selected pages assembled so far by Rotation degrees.
+// watermark: watermarks the selected pages assembled so far (see "pdfcpu watermark").
+type AssembleOp struct {
+	Op        string `json:"op" yaml:"op"`
+	File      string `json:"file" yaml:"file"`           // insert: inFile, watermark: image or PDF file.
+	Pages     string `json:"pages" yaml:"pages"`         // page selection, empty selects all pages.
+	Count     int    `json:"count" yaml:"count"`         // blank: number of pages.
+	PaperSize string `json:"paperSize" yaml:"paperSize"` // blank: paper size like A4 or LetterL.
+	Rotation  int    `json:"rotation" yaml:"rotation"`   // rotate: multiple of 90 degrees.
+	Mode      string `json:"mode" yaml:"mode"`           // watermark: text, image or pdf.
+	Text      string `json:"text" yaml:"text"`           // watermark: text for mode text.
+	Desc      string `json:"desc" yaml:"desc"`           // watermark: description.
+	OnTop     bool   `json:"onTop" yaml:"onTop"`         // watermark: stamp if true.
+}
+
+// AssembleManifest describes a document as an ordered list of operations applied in order.
+// insert and blank build the page sequence of the result.
+// The page selections of rotate and watermark refer to the pages assembled by preceding operations.
+type AssembleManifest struct {
+	Operations []AssembleOp `json:"operations" yaml:"operations"`
+}
+
+// PageDim returns the dimensions of blank pages, defaults to A4.
+// An appended L or P enforces landscape or portrait mode, eg. LetterL.
+func (op AssembleOp) PageDim() (*types.Dim, error) {
+	v := op.PaperSize
+	if v == "" {
+		v = "A4"
+	}
+
+	var landscape, portrait bool
+	if strings.HasSuffix(v, "L") {
+		v, landscape = v[:len(v)-1], true
+	} else if strings.HasSuffix(v, "P") {
+		v, portrait = v[:len(v)-1], true
+	}
+
+	d, ok := types.PaperSize[v]
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: assemble: blank: unknown paper size: %s", op.PaperSize)
+	}
+
+	dim := *d
+	if (dim.Portrait() && landscape) || (dim.Landscape() && portrait) {
+		dim.Width, dim.Height = dim.Height, dim.Width
+	}
+
+	return &dim, nil
+}
+
+func (op AssembleOp) validate() error {
+	switch op.Op {
+
+	case "insert":
+		if op.File == "" {
+			return errors.New("pdfcpu: assemble: insert: missing file")
+		}
+
+	case "blank":
+		if op.Count < 1 {
+			return errors.Errorf("pdfcpu: assemble: blank: invalid count: %d", op.Count)
+		}
+		if _, err := op.PageDim(); err != nil {
+			return err
+		}
+
+	case "rotate":
+		if op.Rotation == 0 || op.Rotation%90 != 0 {
+			return errors.Errorf("pdfcpu: assemble: rotate: rotation must be a multiple of 90: %d", op.Rotation)
+		}
+
+	case "watermark":
+		switch op.Mode {
+		case "", "text":
+			if op.Text == "" {
+				return errors.New("pdfcpu: assemble: watermark: missing text")
+			}
+		case "image", "pdf":
+			if op.File == "" {
+				return errors.New("pdfcpu: assemble: watermark: missing file")
+			}
+		default:
+			return errors.Errorf("pdfcpu: assemble: watermark: invalid mode: %s", op.Mode)
+		}
+
+	default:
+		return errors.Errorf("pdfcpu: assemble: unknown operation: %s", op.Op)
+	}
+
+	return nil
+}
+
+// Validate checks all operations of m.
+func (m *AssembleManifest) Validate() error {
+	if len(m.Operations) == 0 {
+		return errors.New("pdfcpu: assemble: missing operations")
+	}
+	inserts := false
+	for _, op := range m.Operations {
+		if err := op.validate(); err != nil {
+			return err
+		}
+		if op.Op == "insert" || op.Op == "blank" {
+			inserts = true
+			continue
+		}
+		if !inserts {
+			return errors.Errorf("pdfcpu: assemble: %s: no pages assembled yet", op.Op)
+		}
+	}
+	if !inserts {
+		return errors.New("pdfcpu: assemble: no pages to assemble")
+	}
+	return nil
+}
+
+// ParseAssembleManifest parses a JSON or YAML assemble manifest.
+func ParseAssembleManifest(r io.Reader, isJSON bool) (*AssembleManifest, error) {
+	bb, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &AssembleManifest{}
+
+	if isJSON {
+		err = json.Unmarshal(bb, m)
+	} else {
+		err = yaml.Unmarshal(bb, m)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: assemble: invalid manifest")
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ReadAssembleManifest reads a JSON or YAML assemble manifest from manifestFile.
+// Relative file names are resolved against the directory of manifestFile.
+func ReadAssembleManifest(manifestFile string) (*AssembleManifest, error) {
+	f, err := os.Open(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	isJSON := strings.ToLower(filepath.Ext(manifestFile)) == ".json"
+
+	m, err := ParseAssembleManifest(f, isJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(manifestFile)
+	for i, op := range m.Operations {
+		if op.File != "" && !filepath.IsAbs(op.File) {
+			m.Operations[i].File = filepath.Join(dir, op.File)
+		}
+	}
+
+	return m, nil
+}
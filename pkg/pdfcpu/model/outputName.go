@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OutputName holds the values available to Configuration.OutputNameTemplate.
+type OutputName struct {
+	Base  string // input file name without extension
+	Page  int    // page number
+	Index int    // 1-based sequence number of the file written
+	From  int    // first page of the page span
+	To    int    // last page of the page span
+	Ext   string // file extension without leading dot
+}
+
+// OutputFileName returns the file name for n using c.OutputNameTemplate.
+// The placeholders {base}, {page}, {index}, {from}, {to} and {ext} get replaced by the corresponding values of n.
+// If the template does not contain {ext} the extension gets appended.
+// If no template is configured defName is returned.
+func (c *Configuration) OutputFileName(defName string, n OutputName) string {
+	if c == nil || c.OutputNameTemplate == "" {
+		return defName
+	}
+
+	r := strings.NewReplacer(
+		"{base}", n.Base,
+		"{page}", strconv.Itoa(n.Page),
+		"{index}", strconv.Itoa(n.Index),
+		"{from}", strconv.Itoa(n.From),
+		"{to}", strconv.Itoa(n.To),
+		"{ext}", n.Ext,
+	)
+
+	s := r.Replace(c.OutputNameTemplate)
+	if !strings.Contains(c.OutputNameTemplate, "{ext}") && n.Ext != "" {
+		s += "." + n.Ext
+	}
+
+	return s
+}
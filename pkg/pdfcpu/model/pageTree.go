@@ -0,0 +1,189 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultPageTreeBranchingFactor is the maximum number of kids per page tree node used for balancing if not configured.
+const DefaultPageTreeBranchingFactor = 10
+
+// Inheritable page attributes, see 7.7.3.4
+var inheritablePageAttrs = []string{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+func inheritedAttrs(d, inh types.Dict) types.Dict {
+	m := types.Dict{}
+	for k, v := range inh {
+		m[k] = v
+	}
+	for _, k := range inheritablePageAttrs {
+		if o, found := d.Find(k); found && o != nil {
+			m[k] = o
+		}
+	}
+	return m
+}
+
+// collectPageLeaves returns the page dicts of the page tree node indRef in document order.
+// Any node without a Kids array is a page, pages referenced more than once are kept in order to preserve the page count.
+// Attributes inherited from intermediate nodes get pushed down into the page dicts.
+// path holds the obj#s of all ancestors of indRef.
+func (xRefTable *XRefTable) collectPageLeaves(indRef types.IndirectRef, inh types.Dict, leaves *[]types.IndirectRef, path map[int]bool) error {
+	objNr := indRef.ObjectNumber.Value()
+	if path[objNr] {
+		// Skip cycles.
+		return nil
+	}
+
+	d, err := xRefTable.DereferenceDict(indRef)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return errors.Errorf("pdfcpu: page tree: missing node obj#%d", objNr)
+	}
+
+	o, err := xRefTable.Dereference(d["Kids"])
+	if err != nil {
+		return err
+	}
+
+	kids, ok := o.(types.Array)
+	if !ok {
+		for _, k := range inheritablePageAttrs {
+			if _, found := d.Find(k); found {
+				continue
+			}
+			if o, ok := inh[k]; ok {
+				d[k] = o.Clone()
+			}
+		}
+		*leaves = append(*leaves, indRef)
+		return nil
+	}
+
+	inh = inheritedAttrs(d, inh)
+
+	path[objNr] = true
+	defer delete(path, objNr)
+
+	for _, o := range kids {
+		ir, ok := o.(types.IndirectRef)
+		if !ok {
+			return errors.New("pdfcpu: page tree: kid must be an indirect reference")
+		}
+		if err := xRefTable.collectPageLeaves(ir, inh, leaves, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebalancePageTree rebuilds the page tree as a balanced tree with at most n kids per node.
+// The page tree root is kept, intermediate nodes are replaced.
+// Pages referenced more than once are kept, only cycles get dropped, in line with pages repair.
+func (xRefTable *XRefTable) RebalancePageTree(n int) error {
+	if n < 2 {
+		n = DefaultPageTreeBranchingFactor
+	}
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return err
+	}
+
+	rootDict, err := xRefTable.DereferenceDict(*root)
+	if err != nil {
+		return err
+	}
+
+	var leaves []types.IndirectRef
+	if err := xRefTable.collectPageLeaves(*root, types.Dict{}, &leaves, map[int]bool{}); err != nil {
+		return err
+	}
+
+	// All inherited attributes now live in the page dicts.
+	for _, k := range inheritablePageAttrs {
+		rootDict.Delete(k)
+	}
+
+	type node struct {
+		indRef types.IndirectRef
+		count  int
+	}
+
+	nodes := make([]node, len(leaves))
+	for i, ir := range leaves {
+		nodes[i] = node{ir, 1}
+	}
+
+	for len(nodes) > n {
+		var parents []node
+		for i := 0; i < len(nodes); i += n {
+			j := i + n
+			if j > len(nodes) {
+				j = len(nodes)
+			}
+			kids, count := types.Array{}, 0
+			for _, nd := range nodes[i:j] {
+				kids = append(kids, nd.indRef)
+				count += nd.count
+			}
+			d := types.Dict(map[string]types.Object{
+				"Type":  types.Name("Pages"),
+				"Kids":  kids,
+				"Count": types.Integer(count),
+			})
+			ir, err := xRefTable.IndRefForNewObject(d)
+			if err != nil {
+				return err
+			}
+			for _, nd := range nodes[i:j] {
+				if err := xRefTable.setParent(nd.indRef, *ir); err != nil {
+					return err
+				}
+			}
+			parents = append(parents, node{*ir, count})
+		}
+		nodes = parents
+	}
+
+	kids := types.Array{}
+	for _, nd := range nodes {
+		kids = append(kids, nd.indRef)
+		if err := xRefTable.setParent(nd.indRef, *root); err != nil {
+			return err
+		}
+	}
+
+	rootDict.Update("Kids", kids)
+	rootDict.Update("Count", types.Integer(len(leaves)))
+
+	return nil
+}
+
+func (xRefTable *XRefTable) setParent(indRef, parent types.IndirectRef) error {
+	d, err := xRefTable.DereferenceDict(indRef)
+	if err != nil {
+		return err
+	}
+	d.Update("Parent", parent)
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+func newObject(t *testing.T, xRefTable *XRefTable, o types.Object) types.IndirectRef {
+	t.Helper()
+	ir, err := xRefTable.IndRefForNewObject(o)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	return *ir
+}
+
+func TestRebalancePageTree(t *testing.T) {
+	xRefTable := newXRefTable(ValidationRelaxed, false)
+	xRefTable.Table[0] = NewFreeHeadXRefTableEntry()
+	one := 1
+	xRefTable.Size = &one
+
+	root := newObject(t, xRefTable, types.Dict{"Type": types.Name("Pages")})
+	catalog := newObject(t, xRefTable, types.Dict{"Type": types.Name("Catalog"), "Pages": root})
+	xRefTable.Root = &catalog
+
+	// A page lacking /Type.
+	p1 := newObject(t, xRefTable, types.Dict{"Parent": root})
+	p2 := newObject(t, xRefTable, types.Dict{"Type": types.Name("Page"), "Parent": root})
+	p3 := newObject(t, xRefTable, types.Dict{"Type": types.Name("Page")})
+
+	// p2 appears twice, the reference back to root is a cycle.
+	node := newObject(t, xRefTable, types.Dict{"Type": types.Name("Pages"), "Kids": types.Array{p3, p2, root}, "Parent": root})
+
+	d, err := xRefTable.DereferenceDict(root)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	d["Kids"] = types.Array{p1, p2, node}
+	d["Count"] = types.Integer(4)
+	d["MediaBox"] = types.RectForFormat("A4").Array()
+
+	if err := xRefTable.RebalancePageTree(2); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	var leaves []types.IndirectRef
+	if err := xRefTable.collectPageLeaves(root, types.Dict{}, &leaves, map[int]bool{}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	want := []types.IndirectRef{p1, p2, p3, p2}
+	if len(leaves) != len(want) {
+		t.Fatalf("leaves: want %v, got: %v\n", want, leaves)
+	}
+	for i, ir := range want {
+		if leaves[i] != ir {
+			t.Fatalf("leaf %d: want %v, got: %v\n", i, ir, leaves[i])
+		}
+	}
+
+	if c := d.IntEntry("Count"); c == nil || *c != 4 {
+		t.Fatalf("Count: want 4, got: %v\n", d["Count"])
+	}
+
+	// Inherited attributes get pushed down into all pages.
+	for _, ir := range want {
+		d1, err := xRefTable.DereferenceDict(ir)
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+		if _, found := d1.Find("MediaBox"); !found {
+			t.Fatalf("page obj#%d: missing inherited MediaBox\n", ir.ObjectNumber)
+		}
+	}
+}
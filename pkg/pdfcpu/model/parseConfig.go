@@ -45,6 +45,18 @@ type configuration struct {
 	DateFormat                      string `yaml:"dateFormat"`
 	HeaderBufSize                   int    `yaml:"headerBufSize"`
 	OptimizeDuplicateContentStreams bool   `yaml:"optimizeDuplicateContentStreams"`
+	MergeContentStreams             bool   `yaml:"mergeContentStreams"`
+	BalancePageTree                 bool   `yaml:"balancePageTree"`
+	PageTreeBranchingFactor         int    `yaml:"pageTreeBranchingFactor"`
+	OutputNameTemplate              string `yaml:"outputNameTemplate"`
+	MaxPages                        int    `yaml:"maxPages"`
+	MaxAnnotations                  int    `yaml:"maxAnnotations"`
+	MaxFormFields                   int    `yaml:"maxFormFields"`
+	CompressMetadata                string `yaml:"compressMetadata"`
+	JPEGQuality                     int    `yaml:"jpegQuality"`
+	ExtractSoftMasks                bool   `yaml:"extractSoftMasks"`
+	ApplySoftMasks                  bool   `yaml:"applySoftMasks"`
+	CanonicalInfoDict               bool   `yaml:"canonicalInfoDict"`
 }
 
 func loadedConfig(c configuration, configPath string) *Configuration {
@@ -93,6 +105,27 @@ func loadedConfig(c configuration, configPath string) *Configuration {
 	conf.DateFormat = c.DateFormat
 	conf.HeaderBufSize = c.HeaderBufSize
 	conf.OptimizeDuplicateContentStreams = c.OptimizeDuplicateContentStreams
+	conf.MergeContentStreams = c.MergeContentStreams
+	conf.BalancePageTree = c.BalancePageTree
+	conf.PageTreeBranchingFactor = c.PageTreeBranchingFactor
+	conf.OutputNameTemplate = c.OutputNameTemplate
+	conf.MaxPages = c.MaxPages
+	conf.MaxAnnotations = c.MaxAnnotations
+	conf.MaxFormFields = c.MaxFormFields
+
+	switch c.CompressMetadata {
+	case "always":
+		conf.CompressMetadata = MetadataCompress
+	case "never":
+		conf.CompressMetadata = MetadataUncompress
+	default:
+		conf.CompressMetadata = MetadataPreserve
+	}
+
+	conf.JPEGQuality = c.JPEGQuality
+	conf.ExtractSoftMasks = c.ExtractSoftMasks
+	conf.ApplySoftMasks = c.ApplySoftMasks
+	conf.CanonicalInfoDict = c.CanonicalInfoDict
 
 	return &conf
 }
@@ -141,6 +174,35 @@ func parseConfigFile(r io.Reader, configPath string) error {
 		return errors.Errorf("headerBufSize must be >= 100, got: %d", c.HeaderBufSize)
 	}
 
+	// Enforce default for old config files.
+	if c.PageTreeBranchingFactor == 0 {
+		c.PageTreeBranchingFactor = DefaultPageTreeBranchingFactor
+	}
+
+	if c.PageTreeBranchingFactor < 2 {
+		return errors.Errorf("pageTreeBranchingFactor must be >= 2, got: %d", c.PageTreeBranchingFactor)
+	}
+
+	if c.CompressMetadata != "" && !types.MemberOf(c.CompressMetadata, []string{"always", "never", "preserve"}) {
+		return errors.Errorf("invalid compressMetadata: %s", c.CompressMetadata)
+	}
+
+	if c.MaxPages < 0 {
+		return errors.Errorf("maxPages must be >= 0, got: %d", c.MaxPages)
+	}
+
+	if c.MaxAnnotations < 0 {
+		return errors.Errorf("maxAnnotations must be >= 0, got: %d", c.MaxAnnotations)
+	}
+
+	if c.MaxFormFields < 0 {
+		return errors.Errorf("maxFormFields must be >= 0, got: %d", c.MaxFormFields)
+	}
+
+	if c.JPEGQuality < 0 || c.JPEGQuality > 100 {
+		return errors.Errorf("jpegQuality must be between 0 and 100, got: %d", c.JPEGQuality)
+	}
+
 	loadedDefaultConfig = loadedConfig(c, configPath)
 	return nil
 }
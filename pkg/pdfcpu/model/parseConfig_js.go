@@ -181,6 +181,132 @@ func handleOptimizeDuplicateContentStreams(k, v string, c *Configuration) error
 	return nil
 }
 
+func handleMergeContentStreams(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.MergeContentStreams = v == "true"
+	return nil
+}
+
+func handleBalancePageTree(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.BalancePageTree = v == "true"
+	return nil
+}
+
+func handlePageTreeBranchingFactor(k, v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return errors.Errorf("%s is numeric, got: %s", k, v)
+	}
+	if i < 2 {
+		return errors.Errorf("%s must be >= 2, got: %d", k, i)
+	}
+	c.PageTreeBranchingFactor = i
+	return nil
+}
+
+func handleOutputNameTemplate(v string, c *Configuration) error {
+	c.OutputNameTemplate = strings.Trim(v, "\"")
+	return nil
+}
+
+func parseLimit(k, v string) (int, error) {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Errorf("%s is numeric, got: %s", k, v)
+	}
+	if i < 0 {
+		return 0, errors.Errorf("%s must be >= 0, got: %d", k, i)
+	}
+	return i, nil
+}
+
+func handleMaxPages(k, v string, c *Configuration) error {
+	i, err := parseLimit(k, v)
+	if err != nil {
+		return err
+	}
+	c.MaxPages = i
+	return nil
+}
+
+func handleMaxAnnotations(k, v string, c *Configuration) error {
+	i, err := parseLimit(k, v)
+	if err != nil {
+		return err
+	}
+	c.MaxAnnotations = i
+	return nil
+}
+
+func handleMaxFormFields(k, v string, c *Configuration) error {
+	i, err := parseLimit(k, v)
+	if err != nil {
+		return err
+	}
+	c.MaxFormFields = i
+	return nil
+}
+
+func handleCompressMetadata(v string, c *Configuration) error {
+	switch strings.ToLower(v) {
+	case "always":
+		c.CompressMetadata = MetadataCompress
+	case "never":
+		c.CompressMetadata = MetadataUncompress
+	case "preserve":
+		c.CompressMetadata = MetadataPreserve
+	default:
+		return errors.Errorf("invalid compressMetadata: %s", v)
+	}
+	return nil
+}
+
+func handleJPEGQuality(k, v string, c *Configuration) error {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return errors.Errorf("%s is numeric, got: %s", k, v)
+	}
+	if i < 0 || i > 100 {
+		return errors.Errorf("%s must be between 0 and 100, got: %d", k, i)
+	}
+	c.JPEGQuality = i
+	return nil
+}
+
+func handleExtractSoftMasks(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.ExtractSoftMasks = v == "true"
+	return nil
+}
+
+func handleApplySoftMasks(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.ApplySoftMasks = v == "true"
+	return nil
+}
+
+func handleCanonicalInfoDict(k, v string, c *Configuration) error {
+	v = strings.ToLower(v)
+	if v != "true" && v != "false" {
+		return errors.Errorf("config key %s is boolean", k)
+	}
+	c.CanonicalInfoDict = v == "true"
+	return nil
+}
+
 func parseKeyValue(k, v string, c *Configuration) error {
 	var err error
 	switch k {
@@ -229,6 +355,42 @@ func parseKeyValue(k, v string, c *Configuration) error {
 
 	case "optimizeDuplicateContentStreams":
 		err = handleOptimizeDuplicateContentStreams(k, v, c)
+
+	case "mergeContentStreams":
+		err = handleMergeContentStreams(k, v, c)
+
+	case "balancePageTree":
+		err = handleBalancePageTree(k, v, c)
+
+	case "pageTreeBranchingFactor":
+		err = handlePageTreeBranchingFactor(k, v, c)
+
+	case "outputNameTemplate":
+		err = handleOutputNameTemplate(v, c)
+
+	case "maxPages":
+		err = handleMaxPages(k, v, c)
+
+	case "maxAnnotations":
+		err = handleMaxAnnotations(k, v, c)
+
+	case "maxFormFields":
+		err = handleMaxFormFields(k, v, c)
+
+	case "compressMetadata":
+		err = handleCompressMetadata(v, c)
+
+	case "jpegQuality":
+		err = handleJPEGQuality(k, v, c)
+
+	case "extractSoftMasks":
+		err = handleExtractSoftMasks(k, v, c)
+
+	case "applySoftMasks":
+		err = handleApplySoftMasks(k, v, c)
+
+	case "canonicalInfoDict":
+		err = handleCanonicalInfoDict(k, v, c)
 	}
 
 	return err
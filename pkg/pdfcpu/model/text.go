@@ -60,22 +60,42 @@ type TextDescriptor struct {
 	BorderStyle    types.LineJoinStyle // Border style, also visible if ShowBorder is false as long as ShowBackground is true.
 	BorderCol      color.SimpleColor   // Border color.
 	ParIndent      bool                // Indent first line of paragraphs or space between paragraphs.
+	LineSpacing    float64             // Line height multiplier, defaults to 1.
 	ShowLineBB     bool                // Render line bounding boxes in black (for HAlign != AlignJustify only)
 	ShowMargins    bool                // Render margins in light gray.
 	ShowPosition   bool                // Highlight position.
 	HairCross      bool                // Draw haircross at X,Y
 }
 
-func deltaAlignMiddle(fontName string, fontSize, lines int, mTop, mBot float64) float64 {
-	return -font.Ascent(fontName, fontSize) + (float64(lines)*font.LineHeight(fontName, fontSize)+mTop+mBot)/2 - mTop
+func (td TextDescriptor) lineSpacing() float64 {
+	if td.LineSpacing <= 0 {
+		return 1
+	}
+	return td.LineSpacing
+}
+
+// LinesHeight returns the height in user space units of n lines rendered using fontName, fontSize and a line height multiplier.
+func LinesHeight(n int, fontName string, fontSize int, lineSpacing float64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	if lineSpacing <= 0 {
+		lineSpacing = 1
+	}
+	lh := font.LineHeight(fontName, fontSize)
+	return lh + float64(n-1)*lh*lineSpacing
+}
+
+func deltaAlignMiddle(fontName string, fontSize, lines int, lineSpacing, mTop, mBot float64) float64 {
+	return -font.Ascent(fontName, fontSize) + (LinesHeight(lines, fontName, fontSize, lineSpacing)+mTop+mBot)/2 - mTop
 }
 
 func deltaAlignTop(fontName string, fontSize int, mTop float64) float64 {
 	return -font.Ascent(fontName, fontSize) - mTop
 }
 
-func deltaAlignBottom(fontName string, fontSize, lines int, mBot float64) float64 {
-	return -font.Ascent(fontName, fontSize) + float64(lines)*font.LineHeight(fontName, fontSize) + mBot
+func deltaAlignBottom(fontName string, fontSize, lines int, lineSpacing, mBot float64) float64 {
+	return -font.Ascent(fontName, fontSize) + LinesHeight(lines, fontName, fontSize, lineSpacing) + mBot
 }
 
 var unicodeToCP1252 = map[rune]byte{
@@ -154,7 +174,7 @@ func CalcBoundingBoxForRects(r1, r2 *types.Rectangle) *types.Rectangle {
 	return calcBoundingBoxForRectAndPoint(bbox, r2.UR)
 }
 
-func calcBoundingBoxForLines(lines []string, x, y float64, fontName string, fontSize int) (*types.Rectangle, string) {
+func calcBoundingBoxForLines(lines []string, x, y float64, fontName string, fontSize int, lineSpacing float64) (*types.Rectangle, string) {
 	var (
 		box      *types.Rectangle
 		maxLine  string
@@ -168,7 +188,7 @@ func calcBoundingBoxForLines(lines []string, x, y float64, fontName string, font
 			maxLine = s
 		}
 		box = CalcBoundingBoxForRects(box, bbox)
-		y -= bbox.Height()
+		y -= bbox.Height() * lineSpacing
 	}
 	return box, maxLine
 }
@@ -365,7 +385,7 @@ func preRenderJustifiedText(
 		if width > 0 {
 			ww = width * td.Scale
 		} else {
-			box, _ := calcBoundingBoxForLines(*lines, x, y, td.FontName, *fontSize)
+			box, _ := calcBoundingBoxForLines(*lines, x, y, td.FontName, *fontSize, 1)
 			ww = box.Width() * td.Scale
 		}
 	}
@@ -394,7 +414,7 @@ func scaleFontSize(r *types.Rectangle, lines []string, scaleAbs bool,
 	} else {
 		www := width
 		if width == 0 {
-			box, _ := calcBoundingBoxForLines(lines, x, y, fontName, *fontSize)
+			box, _ := calcBoundingBoxForLines(lines, x, y, fontName, *fontSize, 1)
 			www = box.Width() + mLeft + mRight + 2*borderWidth
 		}
 		*fontSize = int(r.Width() * scale * float64(*fontSize) / www)
@@ -466,13 +486,13 @@ func createBoundingBoxForColumn(xRefTable *XRefTable, r *types.Rectangle, x, y *
 	case types.AlignTop:
 		dy1 = deltaAlignTop(td.FontName, *fontSize, mTop+borderWidth)
 	case types.AlignMiddle:
-		dy1 = deltaAlignMiddle(td.FontName, *fontSize, len(*lines), mTop, mBot)
+		dy1 = deltaAlignMiddle(td.FontName, *fontSize, len(*lines), td.lineSpacing(), mTop, mBot)
 	case types.AlignBottom:
-		dy1 = deltaAlignBottom(td.FontName, *fontSize, len(*lines), mBot)
+		dy1 = deltaAlignBottom(td.FontName, *fontSize, len(*lines), td.lineSpacing(), mBot)
 	}
 	*y += math.Ceil(dy1)
 
-	box, maxLine := calcBoundingBoxForLines(*lines, *x, *y, td.FontName, *fontSize)
+	box, maxLine := calcBoundingBoxForLines(*lines, *x, *y, td.FontName, *fontSize, td.lineSpacing())
 	// maxLine for hAlign != AlignJustify only!
 	horizontalWrapUp(box, maxLine, td.HAlign, x, width, ww, mLeft, mRight, borderWidth, td.FontName, fontSize)
 
@@ -544,7 +564,7 @@ func renderBackgroundAndBorder(w io.Writer, td TextDescriptor, borderWidth float
 }
 
 func renderText(xRefTable *XRefTable, w io.Writer, lines []string, td TextDescriptor, x, y float64, fontSize int) {
-	lh := font.LineHeight(td.FontName, fontSize)
+	lh := font.LineHeight(td.FontName, fontSize) * td.lineSpacing()
 	for _, s := range lines {
 		if td.HAlign != types.AlignJustify {
 			lineBB := CalcBoundingBox(s, x, y, td.FontName, fontSize)
@@ -617,6 +637,41 @@ func fieldsFunc(s string, f func(rune) bool) []string {
 	return a
 }
 
+// WrapText word-wraps s into lines fitting width when rendered using fontName and fontSize.
+// Line breaks in s are kept, words wider than width occupy a line of their own.
+func WrapText(s, fontName string, fontSize int, width float64) []string {
+	textWidth := func(s string) float64 {
+		if font.IsCoreFont(fontName) && utf8.ValidString(s) {
+			s = DecodeUTF8ToByte(s)
+		}
+		return font.TextWidth(s, fontName, fontSize)
+	}
+	var lines []string
+	blankWidth := textWidth(" ")
+	for _, par := range SplitMultilineStr(s) {
+		var (
+			line      []string
+			lineWidth float64
+		)
+		for _, word := range strings.Fields(par) {
+			ww := textWidth(word)
+			bw := 0.
+			if len(line) > 0 {
+				bw = blankWidth
+			}
+			if len(line) == 0 || width-lineWidth-(ww+bw) > 0 {
+				line = append(line, word)
+				lineWidth += ww + bw
+				continue
+			}
+			lines = append(lines, strings.Join(line, " "))
+			line, lineWidth = []string{word}, ww
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+	return lines
+}
+
 func SplitMultilineStr(s string) []string {
 	s = strings.ReplaceAll(s, "\\n", "\n")
 	var lines []string
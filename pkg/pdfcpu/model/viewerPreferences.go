@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PrintPreferences represents the print related entries of the viewer preferences dict, see 12.2 Viewer Preferences.
+// Empty values leave the corresponding entry untouched.
+type PrintPreferences struct {
+	Scaling string // PrintScaling: None, AppDefault
+	Area    string // PrintArea: MediaBox, CropBox, BleedBox, TrimBox, ArtBox
+	Clip    string // PrintClip: MediaBox, CropBox, BleedBox, TrimBox, ArtBox
+}
+
+func parsePrintScaling(s string, pp *PrintPreferences) error {
+	switch strings.ToLower(s) {
+	case "none":
+		pp.Scaling = "None"
+	case "appdefault":
+		pp.Scaling = "AppDefault"
+	default:
+		return errors.New("pdfcpu: print scaling, please provide one of: none/appdefault")
+	}
+	return nil
+}
+
+func pageBoundaryName(s string) (string, error) {
+	k, err := resolveBoxType(strings.ToLower(s))
+	if err != nil || s == "" {
+		return "", errors.Errorf("pdfcpu: invalid page boundary: %s, please provide one of: media/crop/trim/bleed/art", s)
+	}
+	return strings.ToUpper(k[:1]) + k[1:] + "Box", nil
+}
+
+func parsePrintArea(s string, pp *PrintPreferences) (err error) {
+	pp.Area, err = pageBoundaryName(s)
+	return err
+}
+
+func parsePrintClip(s string, pp *PrintPreferences) (err error) {
+	pp.Clip, err = pageBoundaryName(s)
+	return err
+}
+
+type printPreferencesParameterMap map[string]func(string, *PrintPreferences) error
+
+var PrintPreferencesParamMap = printPreferencesParameterMap{
+	"scaling": parsePrintScaling,
+	"area":    parsePrintArea,
+	"clip":    parsePrintClip,
+}
+
+// Handle applies parameter completion and on success parse parameter values into pp.
+func (m printPreferencesParameterMap) Handle(paramPrefix, paramValueStr string, pp *PrintPreferences) error {
+
+	var param string
+
+	// Completion support
+	for k := range m {
+		if !strings.HasPrefix(k, strings.ToLower(paramPrefix)) {
+			continue
+		}
+		if len(param) > 0 {
+			return errors.Errorf("pdfcpu: ambiguous parameter prefix \"%s\"", paramPrefix)
+		}
+		param = k
+	}
+
+	if param == "" {
+		return errors.Errorf("pdfcpu: unknown parameter prefix \"%s\"", paramPrefix)
+	}
+
+	return m[param](paramValueStr, pp)
+}
@@ -91,6 +91,10 @@ type Watermark struct {
 	ScaleEff          float64             // effective scale factor
 	ScaleAbs          bool                // true for absolute scaling.
 	Update            bool                // true for updating instead of adding a page watermark.
+	BoxWidth          float64             // text box width, if > 0 text gets word-wrapped to fit.
+	BoxHeight         float64             // text box height.
+	ShrinkToFit       bool                // true for reducing the font size until text fits the text box height.
+	LineSpacing       float64             // line height multiplier for text.
 
 	// resources
 	Ocg, ExtGState, Font, Img *types.IndirectRef
@@ -143,6 +147,11 @@ func (wm *Watermark) Recycle() {
 	wm.FCache = formCache{}
 }
 
+// IsTextBox returns true if the watermark text gets word-wrapped to fit a text box.
+func (wm Watermark) IsTextBox() bool {
+	return wm.IsText() && wm.BoxWidth > 0
+}
+
 // IsText returns true if the watermark content is text.
 func (wm Watermark) IsText() bool {
 	return wm.Mode == WMText
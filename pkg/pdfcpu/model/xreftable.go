@@ -2301,6 +2301,32 @@ func (xRefTable *XRefTable) InsertBlankPages(pages types.IntSet, before bool) er
 	return err
 }
 
+// AppendBlankPages appends count blank pages of size mediaBox.
+func (xRefTable *XRefTable) AppendBlankPages(count int, mediaBox *types.Rectangle) error {
+
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return err
+	}
+
+	d, err := xRefTable.DereferenceDict(*root)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < count; i++ {
+		indRef, err := xRefTable.emptyPage(root, mediaBox)
+		if err != nil {
+			return err
+		}
+		if err := AppendPageTree(indRef, 1, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // StreamDictIndRef creates a new stream dict for bb.
 func (xRefTable *XRefTable) StreamDictIndRef(bb []byte) (*types.IndirectRef, error) {
 	sd, _ := xRefTable.NewStreamDictForBuf(bb)
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"sort"
 
+	"github.com/ex-preman/pdfcpu/pkg/filter"
 	"github.com/ex-preman/pdfcpu/pkg/log"
 	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
@@ -152,6 +153,70 @@ func optimizePageContent(ctx *model.Context, pageDict types.Dict, pageObjNumber
 	return nil
 }
 
+// mergePageContents replaces a page content array by a single content stream.
+func mergePageContents(ctx *model.Context, pageDict types.Dict, pageObjNumber int) error {
+	o, found := pageDict.Find("Contents")
+	if !found {
+		return nil
+	}
+
+	o, err := ctx.Dereference(o)
+	if err != nil {
+		return err
+	}
+
+	contentArr, ok := o.(types.Array)
+	if !ok {
+		return nil
+	}
+
+	var buf []byte
+	s := newContentScanner()
+
+	for i, o := range contentArr {
+		sd, _, err := ctx.DereferenceStreamDict(o)
+		if err != nil {
+			return err
+		}
+		if sd == nil {
+			continue
+		}
+		if err := sd.Decode(); err != nil {
+			if err == filter.ErrUnsupportedFilter {
+				log.Optimize.Printf("mergePageContents: obj#:%d unsupported filter, leaving content array as is\n", pageObjNumber)
+				return nil
+			}
+			return err
+		}
+		if len(buf) > 0 {
+			if s.splitWithinToken(buf, sd.Content) {
+				log.Optimize.Printf("mergePageContents: obj#:%d content parts %d and %d split within a token, joining them without separator\n", pageObjNumber, i, i+1)
+			} else {
+				buf = append(buf, 0x0A)
+			}
+		}
+		buf = append(buf, sd.Content...)
+	}
+
+	sd, err := ctx.NewStreamDictForBuf(buf)
+	if err != nil {
+		return err
+	}
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	pageDict["Contents"] = *ir
+	log.Optimize.Printf("mergePageContents: obj#:%d merged %d content streams into obj#:%d\n", pageObjNumber, len(contentArr), ir.ObjectNumber.Value())
+
+	return nil
+}
+
 // resourcesDictForPageDict returns the resource dict for a page dict if there is any.
 func resourcesDictForPageDict(xRefTable *model.XRefTable, pageDict types.Dict, pageObjNumber int) (types.Dict, error) {
 	o, found := pageDict.Find("Resources")
@@ -685,6 +750,12 @@ func parsePagesDict(ctx *model.Context, pagesDict types.Dict, pageNumber int) (i
 
 		// Process page dict.
 
+		if ctx.MergeContentStreams {
+			if err = mergePageContents(ctx, pageNodeDict, int(ir.ObjectNumber)); err != nil {
+				return 0, err
+			}
+		}
+
 		if err = optimizePageContent(ctx, pageNodeDict, int(ir.ObjectNumber)); err != nil {
 			return 0, err
 		}
@@ -1304,6 +1375,51 @@ func cacheFormFonts(ctx *model.Context) error {
 	return nil
 }
 
+// RecompressJPEGImages re-encodes all gray and RGB JPEG images using ctx.JPEGQuality
+// and keeps the result for images getting smaller.
+func RecompressJPEGImages(ctx *model.Context) error {
+	var (
+		n     int
+		saved int64
+	)
+
+	for objNr, entry := range ctx.Table {
+		if entry.Free || entry.Compressed {
+			continue
+		}
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || !sd.HasSoleFilterNamed(filter.DCT) || sd.Subtype() == nil || *sd.Subtype() != "Image" {
+			continue
+		}
+
+		im, err := decodeImageSamples(ctx.XRefTable, &sd)
+		if err != nil {
+			return err
+		}
+		if im == nil {
+			// CMYK or corrupt JPEG
+			continue
+		}
+
+		sd1, err := im.jpegStreamDict(&sd, ctx.JPEGQuality)
+		if err != nil {
+			return err
+		}
+		if len(sd1.Raw) >= len(sd.Raw) {
+			continue
+		}
+
+		log.Optimize.Printf("RecompressJPEGImages: obj#%d %d -> %d bytes\n", objNr, len(sd.Raw), len(sd1.Raw))
+		saved += int64(len(sd.Raw) - len(sd1.Raw))
+		entry.Object = *sd1
+		n++
+	}
+
+	log.Info.Printf("recompressed %d JPEG images using quality %d, saved %s\n", n, ctx.JPEGQuality, types.ByteSize(saved))
+
+	return nil
+}
+
 // OptimizeXRefTable optimizes an xRefTable by locating and getting rid of redundant embedded fonts and images.
 func OptimizeXRefTable(ctx *model.Context) error {
 	log.Info.Println("optimizing fonts & images")
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// MaxPageDim is the largest page width or height in user units, see 32000-1:2008 Annex C.2.
+const MaxPageDim = 14400.
+
+// Annotation entries holding coordinates.
+var annotCoordKeys = []string{"Rect", "QuadPoints", "Vertices", "L", "CL", "InkList"}
+
+func oversizedScale(r *types.Rectangle) float64 {
+	if r == nil {
+		return 1
+	}
+	d := math.Max(r.Width(), r.Height())
+	if d <= MaxPageDim {
+		return 1
+	}
+	// Round down for a content transform matching the scaled page boundaries.
+	return math.Floor(MaxPageDim/d*1e4) / 1e4
+}
+
+func scaleNumberArray(ctx *model.Context, o types.Object, sc float64) (types.Array, error) {
+	a, err := ctx.DereferenceArray(o)
+	if err != nil || a == nil {
+		return nil, err
+	}
+	a1 := make(types.Array, len(a))
+	for i, o := range a {
+		o, err := ctx.Dereference(o)
+		if err != nil {
+			return nil, err
+		}
+		switch o := o.(type) {
+		case types.Integer:
+			a1[i] = types.Float(float64(o.Value()) * sc)
+		case types.Float:
+			a1[i] = types.Float(o.Value() * sc)
+		case types.Array:
+			if a1[i], err = scaleNumberArray(ctx, o, sc); err != nil {
+				return nil, err
+			}
+		default:
+			a1[i] = o
+		}
+	}
+	return a1, nil
+}
+
+func scaleAnnotations(ctx *model.Context, d types.Dict, sc float64) error {
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return err
+	}
+	for _, o := range annots {
+		annotDict, err := ctx.DereferenceDict(o)
+		if err != nil || annotDict == nil {
+			continue
+		}
+		for _, k := range annotCoordKeys {
+			o, found := annotDict.Find(k)
+			if !found {
+				continue
+			}
+			a, err := scaleNumberArray(ctx, o, sc)
+			if err != nil {
+				return err
+			}
+			annotDict.Update(k, a)
+		}
+	}
+	return nil
+}
+
+func clampPage(ctx *model.Context, pageNr int) (float64, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return 0, err
+	}
+	if d == nil {
+		return 0, errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	sc := oversizedScale(inhPAttrs.MediaBox)
+	if sc == 1 {
+		return sc, nil
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return 0, err
+	}
+
+	if err == nil {
+		bb = append([]byte(fmt.Sprintf("q %.4f 0 0 %.4f 0 0 cm ", sc, sc)), bb...)
+		bb = append(bb, []byte(" Q")...)
+
+		sd, _ := ctx.NewStreamDictForBuf(bb)
+		if err := sd.Encode(); err != nil {
+			return 0, err
+		}
+
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			return 0, err
+		}
+
+		d["Contents"] = *ir
+	}
+
+	// Take over inherited page boundaries.
+	d.Update("MediaBox", inhPAttrs.MediaBox.Array())
+	if inhPAttrs.CropBox != nil {
+		d.Update("CropBox", inhPAttrs.CropBox.Array())
+	}
+
+	for _, k := range []string{"MediaBox", "CropBox", "BleedBox", "TrimBox", "ArtBox"} {
+		o, found := d.Find(k)
+		if !found {
+			continue
+		}
+		a, err := scaleNumberArray(ctx, o, sc)
+		if err != nil {
+			return 0, err
+		}
+		d.Update(k, a)
+	}
+
+	return sc, scaleAnnotations(ctx, d, sc)
+}
+
+// OversizedPages returns all pages with a media box width or height exceeding MaxPageDim.
+// The result is a list describing each page found.
+func OversizedPages(ctx *model.Context) ([]string, error) {
+	var ss []string
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		_, _, inhPAttrs, err := ctx.PageDict(i, false)
+		if err != nil {
+			return nil, err
+		}
+		if oversizedScale(inhPAttrs.MediaBox) == 1 {
+			continue
+		}
+		r := inhPAttrs.MediaBox
+		ss = append(ss, fmt.Sprintf("page %d: %.2f x %.2f exceeds %.0f", i, r.Width(), r.Height(), MaxPageDim))
+	}
+
+	return ss, nil
+}
+
+// ClampPages scales down all pages exceeding MaxPageDim to fit.
+// Page content gets wrapped into a scaling transform, page boundaries and annotation coordinates are scaled along.
+// The result is a list of all pages scaled.
+func ClampPages(ctx *model.Context) ([]string, error) {
+	var ss []string
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		sc, err := clampPage(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if sc != 1 {
+			ss = append(ss, fmt.Sprintf("page %d: scaled by %.4f", i, sc))
+		}
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+func degenerateMediaBox(r *types.Rectangle) string {
+	if r == nil {
+		return "missing media box"
+	}
+	w, h := r.UR.X-r.LL.X, r.UR.Y-r.LL.Y
+	if w < 0 || h < 0 {
+		return fmt.Sprintf("negative media box dimensions: %.2f x %.2f", w, h)
+	}
+	if w == 0 || h == 0 {
+		return fmt.Sprintf("zero area media box: %.2f x %.2f", w, h)
+	}
+	return ""
+}
+
+func hasAnnotations(ctx *model.Context, d types.Dict) bool {
+	a, err := ctx.DereferenceArray(d["Annots"])
+	return err == nil && len(a) > 0
+}
+
+func degeneratePage(ctx *model.Context, pageNr int) (string, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return "", err
+	}
+	if d == nil {
+		return "", errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	if s := degenerateMediaBox(inhPAttrs.MediaBox); s != "" {
+		return s, nil
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return "", err
+	}
+
+	if len(bytes.TrimSpace(bb)) == 0 && !hasAnnotations(ctx, d) {
+		return "no content", nil
+	}
+
+	return "", nil
+}
+
+// DegeneratePages returns all pages with missing, zero or negative media box dimensions
+// as well as all pages without content and annotations.
+// The result are the page numbers found and a list describing each of them.
+func DegeneratePages(ctx *model.Context) (types.IntSet, []string, error) {
+	pages := types.IntSet{}
+	var ss []string
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		s, err := degeneratePage(ctx, i)
+		if err != nil {
+			return nil, nil, err
+		}
+		if s == "" {
+			continue
+		}
+		pages[i] = true
+		ss = append(ss, fmt.Sprintf("page %d: %s", i, s))
+	}
+
+	return pages, ss, nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// pageHasher writes a canonical representation of page content and resources into a hash.
+// Object numbers, string encodings, number formatting, whitespace and stream filters do not contribute.
+type pageHasher struct {
+	ctx        *model.Context
+	digests    map[int]string // digests of indirect objects already visited.
+	inProgress map[int]bool
+}
+
+func hashNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (ph *pageHasher) writeBytes(h hash.Hash, bb []byte) {
+	h.Write([]byte("<"))
+	h.Write([]byte(hex.EncodeToString(bb)))
+	h.Write([]byte(">"))
+}
+
+func (ph *pageHasher) writeDict(h hash.Hash, d types.Dict, skip map[string]bool) error {
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		// Parent entries only point back up the object graph.
+		if k == "Parent" || skip[k] {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h.Write([]byte("<<"))
+	for _, k := range keys {
+		h.Write([]byte("/" + k + " "))
+		if err := ph.writeObject(h, d[k]); err != nil {
+			return err
+		}
+		h.Write([]byte(" "))
+	}
+	h.Write([]byte(">>"))
+
+	return nil
+}
+
+func (ph *pageHasher) writeStreamDict(h hash.Hash, sd types.StreamDict) error {
+	skip := map[string]bool{"Length": true, "DL": true}
+
+	// Content of JPEG and JPEG2000 images stays encoded.
+	encoded := false
+	for _, f := range sd.FilterPipeline {
+		if f.Name == filter.DCT || f.Name == filter.JPX {
+			encoded = true
+		}
+	}
+
+	bb := sd.Raw
+	if err := sd.Decode(); err == nil {
+		bb = sd.Content
+		if !encoded {
+			skip["Filter"], skip["DecodeParms"] = true, true
+		}
+	}
+
+	if err := ph.writeDict(h, sd.Dict, skip); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(bb)
+	h.Write([]byte("stream"))
+	ph.writeBytes(h, sum[:])
+
+	return nil
+}
+
+func (ph *pageHasher) digest(ir types.IndirectRef) (string, error) {
+	objNr := ir.ObjectNumber.Value()
+
+	if s, ok := ph.digests[objNr]; ok {
+		return s, nil
+	}
+
+	if ph.inProgress[objNr] {
+		return "cycle", nil
+	}
+	ph.inProgress[objNr] = true
+	defer delete(ph.inProgress, objNr)
+
+	o, err := ph.ctx.Dereference(ir)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if err := ph.writeObject(h, o); err != nil {
+		return "", err
+	}
+
+	s := hex.EncodeToString(h.Sum(nil))
+	ph.digests[objNr] = s
+
+	return s, nil
+}
+
+func (ph *pageHasher) writeObject(h hash.Hash, o types.Object) error {
+	switch o := o.(type) {
+
+	case nil:
+		h.Write([]byte("null"))
+
+	case types.Boolean:
+		h.Write([]byte(strconv.FormatBool(o.Value())))
+
+	case types.Integer:
+		h.Write([]byte(strconv.Itoa(o.Value())))
+
+	case types.Float:
+		h.Write([]byte(hashNumber(o.Value())))
+
+	case types.Name:
+		h.Write([]byte("/" + o.Value()))
+
+	case types.StringLiteral:
+		bb, err := types.Unescape(o.Value(), false)
+		if err != nil {
+			bb = []byte(o.Value())
+		}
+		ph.writeBytes(h, bb)
+
+	case types.HexLiteral:
+		bb, err := o.Bytes()
+		if err != nil {
+			bb = []byte(o.Value())
+		}
+		ph.writeBytes(h, bb)
+
+	case types.Array:
+		h.Write([]byte("["))
+		for _, o1 := range o {
+			if err := ph.writeObject(h, o1); err != nil {
+				return err
+			}
+			h.Write([]byte(" "))
+		}
+		h.Write([]byte("]"))
+
+	case types.Dict:
+		return ph.writeDict(h, o, nil)
+
+	case types.StreamDict:
+		return ph.writeStreamDict(h, o)
+
+	case types.IndirectRef:
+		s, err := ph.digest(o)
+		if err != nil {
+			return err
+		}
+		h.Write([]byte("@" + s))
+
+	default:
+		h.Write([]byte(o.PDFString()))
+	}
+
+	return nil
+}
+
+func (ph *pageHasher) writeContent(h hash.Hash, bb []byte) error {
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		// Fall back to the raw content with whitespace normalized.
+		h.Write(bytes.Join(bytes.Fields(bb), []byte(" ")))
+		return nil
+	}
+
+	for _, op := range ops {
+		for _, o := range op.Operands {
+			if err := ph.writeObject(h, o); err != nil {
+				return err
+			}
+			h.Write([]byte(" "))
+		}
+		if op.Operator == "BI" {
+			sum := sha256.Sum256(op.Data)
+			ph.writeBytes(h, sum[:])
+		}
+		h.Write([]byte(op.Operator + "\n"))
+	}
+
+	return nil
+}
+
+func (ph *pageHasher) writeRect(h hash.Hash, r *types.Rectangle) {
+	if r == nil {
+		h.Write([]byte("null"))
+		return
+	}
+	for _, f := range []float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y} {
+		h.Write([]byte(hashNumber(f) + " "))
+	}
+}
+
+func (ph *pageHasher) pageHash(pageNr int) (string, error) {
+	d, _, inhPAttrs, err := ph.ctx.PageDict(pageNr, false)
+	if err != nil {
+		return "", err
+	}
+	if d == nil {
+		return "", errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	h := sha256.New()
+
+	h.Write([]byte("content\n"))
+	bb, err := ph.ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return "", err
+	}
+	if err := ph.writeContent(h, bb); err != nil {
+		return "", err
+	}
+
+	h.Write([]byte("resources\n"))
+	var resDict types.Dict
+	if inhPAttrs != nil {
+		resDict = inhPAttrs.Resources
+	}
+	if o, found := d.Find("Resources"); found {
+		if resDict, err = ph.ctx.DereferenceDict(o); err != nil {
+			return "", err
+		}
+	}
+	if err := ph.writeObject(h, resDict); err != nil {
+		return "", err
+	}
+
+	h.Write([]byte("\nboxes\n"))
+	if inhPAttrs != nil {
+		ph.writeRect(h, inhPAttrs.MediaBox)
+		ph.writeRect(h, inhPAttrs.CropBox)
+		h.Write([]byte(strconv.Itoa((inhPAttrs.Rotate%360 + 360) % 360)))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// PageHashes returns a SHA-256 hash of content, resources and page boundaries for selected pages.
+// Hashes are stable across rewrites of the same document:
+// object numbers, whitespace, number and string formatting as well as stream compression are ignored.
+// Annotations do not contribute.
+func PageHashes(ctx *model.Context, selectedPages types.IntSet) (map[int]string, error) {
+	ph := &pageHasher{ctx: ctx, digests: map[int]string{}, inProgress: map[int]bool{}}
+
+	m := map[int]string{}
+
+	for k, v := range selectedPages {
+		if !v {
+			continue
+		}
+		s, err := ph.pageHash(k)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = s
+	}
+
+	return m, nil
+}
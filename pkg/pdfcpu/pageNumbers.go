@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// defaultPageNumberDesc renders page numbers as footer centered at the bottom of the page.
+const defaultPageNumberDesc = "pos:bc, off:0 20, scale:1 abs, points:10, rot:0"
+
+// PageNumbering represents the configuration for numbering the pages of a document.
+type PageNumbering struct {
+	Start   int      // physical page number of the first page to be numbered
+	FirstNr int      // number displayed on page Start
+	Skip    []string // page selection of the pages left unnumbered, these pages count nevertheless
+	Text    string   // %p gets replaced by the displayed number, %P by the last displayed number
+	Desc    string   // text stamp description for position and format, see pdfcpu help stamp
+}
+
+// DefaultPageNumbering returns the default page numbering configuration
+// numbering all pages from 1 at the bottom center of the page.
+func DefaultPageNumbering() *PageNumbering {
+	return &PageNumbering{
+		Start:   1,
+		FirstNr: 1,
+		Text:    "%p",
+		Desc:    defaultPageNumberDesc,
+	}
+}
+
+// ParsePageNumberingDetails parses a page numbering command string into an internal structure.
+// Supported parameters are start, first, skip (space separated page selection) and text,
+// all other parameters get passed on to the text stamp description.
+func ParsePageNumberingDetails(s string) (*PageNumbering, error) {
+	pn := DefaultPageNumbering()
+
+	if strings.TrimSpace(s) == "" {
+		return pn, nil
+	}
+
+	var desc []string
+
+	for _, s := range strings.Split(s, ",") {
+		ss := strings.SplitN(s, ":", 2)
+		if len(ss) != 2 {
+			return nil, errors.New("pdfcpu: Invalid page numbering configuration string. Please consult pdfcpu help pages")
+		}
+
+		k := strings.TrimSpace(ss[0])
+		v := strings.TrimSpace(ss[1])
+
+		switch k {
+
+		case "start":
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 1 {
+				return nil, errors.Errorf("pdfcpu: page numbering: invalid start page: %s", v)
+			}
+			pn.Start = i
+
+		case "first":
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 {
+				return nil, errors.Errorf("pdfcpu: page numbering: invalid first number: %s", v)
+			}
+			pn.FirstNr = i
+
+		case "skip":
+			pn.Skip = strings.Fields(v)
+
+		case "text":
+			if v == "" {
+				return nil, errors.New("pdfcpu: page numbering: missing text")
+			}
+			pn.Text = v
+
+		default:
+			desc = append(desc, s)
+		}
+	}
+
+	if len(desc) > 0 {
+		// Later parameters override defaults.
+		pn.Desc += "," + strings.Join(desc, ",")
+	}
+
+	return pn, nil
+}
+
+// PageNumberWatermarks returns text stamps for all numbered pages of a document with pageCount pages.
+// Page Start displays FirstNr and every following page including the skipped ones counts up by one.
+func PageNumberWatermarks(pn *PageNumbering, pageCount int, skip types.IntSet, u types.DisplayUnit) (map[int]*model.Watermark, error) {
+	if pn.Start < 1 || pn.Start > pageCount {
+		return nil, errors.Errorf("pdfcpu: page numbering: start page %d out of range 1..%d", pn.Start, pageCount)
+	}
+
+	last := strconv.Itoa(pn.FirstNr + pageCount - pn.Start)
+
+	m := map[int]*model.Watermark{}
+
+	for i := pn.Start; i <= pageCount; i++ {
+		if skip[i] {
+			continue
+		}
+		r := strings.NewReplacer("%p", strconv.Itoa(pn.FirstNr+i-pn.Start), "%P", last)
+		wm, err := ParseTextWatermarkDetails(r.Replace(pn.Text), pn.Desc, true, u)
+		if err != nil {
+			return nil, err
+		}
+		m[i] = wm
+	}
+
+	if len(m) == 0 {
+		return nil, errors.New("pdfcpu: page numbering: no pages left to number")
+	}
+
+	return m, nil
+}
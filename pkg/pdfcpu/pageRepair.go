@@ -0,0 +1,261 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+type pageRepair struct {
+	ctx    *model.Context
+	pageNr int
+	path   map[int]bool // obj#s of the ancestors of the current node
+	counts map[int]int  // page counts by obj# of all nodes repaired
+	ss     []string
+}
+
+func (pr *pageRepair) logf(format string, args ...interface{}) {
+	pr.ss = append(pr.ss, fmt.Sprintf(format, args...))
+}
+
+func (pr *pageRepair) validRectangle(o types.Object) bool {
+	a, err := pr.ctx.DereferenceArray(o)
+	if err != nil || len(a) != 4 {
+		return false
+	}
+	for _, o := range a {
+		o, err := pr.ctx.Dereference(o)
+		if err != nil {
+			return false
+		}
+		switch o.(type) {
+		case types.Integer, types.Float:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasMediaBox returns true if d has a usable media box and removes unusable ones.
+func (pr *pageRepair) hasMediaBox(d types.Dict, objNr int) bool {
+	o, found := d.Find("MediaBox")
+	if !found {
+		return false
+	}
+	if pr.validRectangle(o) {
+		return true
+	}
+	d.Delete("MediaBox")
+	pr.logf("obj#%d: removed invalid /MediaBox", objNr)
+	return false
+}
+
+func (pr *pageRepair) resolvableContent(o types.Object) bool {
+	o, err := pr.ctx.Dereference(o)
+	if err != nil {
+		return false
+	}
+	_, ok := o.(types.StreamDict)
+	return ok
+}
+
+func (pr *pageRepair) repairContents(d types.Dict, objNr int) error {
+	o, found := d.Find("Contents")
+	if found {
+		if a, ok := o.(types.Array); ok {
+			// Keep all resolvable content streams.
+			a1 := types.Array{}
+			for _, o := range a {
+				if pr.resolvableContent(o) {
+					a1 = append(a1, o)
+				}
+			}
+			if len(a1) == len(a) && len(a) > 0 {
+				return nil
+			}
+			if len(a1) > 0 {
+				d.Update("Contents", a1)
+				pr.logf("page %d (obj#%d): removed %d unresolvable /Contents", pr.pageNr, objNr, len(a)-len(a1))
+				return nil
+			}
+		} else if pr.resolvableContent(o) {
+			return nil
+		}
+	}
+
+	ir, err := pr.ctx.StreamDictIndRef([]byte{})
+	if err != nil {
+		return err
+	}
+	d.Update("Contents", *ir)
+
+	if found {
+		pr.logf("page %d (obj#%d): replaced unresolvable /Contents with empty content", pr.pageNr, objNr)
+	} else {
+		pr.logf("page %d (obj#%d): added empty /Contents", pr.pageNr, objNr)
+	}
+
+	return nil
+}
+
+func (pr *pageRepair) repairPage(d types.Dict, objNr int, parent types.IndirectRef, hasMediaBox bool) error {
+	pr.pageNr++
+
+	if t := d.Type(); t == nil || *t != "Page" {
+		d.Update("Type", types.Name("Page"))
+		pr.logf("page %d (obj#%d): set /Type /Page", pr.pageNr, objNr)
+	}
+
+	if !pr.hasMediaBox(d, objNr) && !hasMediaBox {
+		dim := types.PaperSize["Letter"]
+		d.Update("MediaBox", types.RectForDim(dim.Width, dim.Height).Array())
+		pr.logf("page %d (obj#%d): added /MediaBox (Letter)", pr.pageNr, objNr)
+	}
+
+	if err := pr.repairContents(d, objNr); err != nil {
+		return err
+	}
+
+	if ir := d.IndirectRefEntry("Parent"); ir == nil || *ir != parent {
+		d.Update("Parent", parent)
+		pr.logf("page %d (obj#%d): fixed /Parent", pr.pageNr, objNr)
+	}
+
+	return nil
+}
+
+// repairPagesDict repairs the page tree node d and returns the number of pages of its subtree.
+func (pr *pageRepair) repairPagesDict(d types.Dict, ir types.IndirectRef, hasMediaBox bool) (int, error) {
+	objNr := ir.ObjectNumber.Value()
+	pr.path[objNr] = true
+	defer delete(pr.path, objNr)
+
+	if t := d.Type(); t == nil || *t != "Pages" {
+		d.Update("Type", types.Name("Pages"))
+		pr.logf("obj#%d: set /Type /Pages", objNr)
+	}
+
+	hasMediaBox = pr.hasMediaBox(d, objNr) || hasMediaBox
+
+	kids := types.Array{}
+	count := 0
+
+	for _, o := range d.ArrayEntry("Kids") {
+
+		kidIndRef, ok := o.(types.IndirectRef)
+		if !ok {
+			pr.logf("obj#%d: removed direct kid", objNr)
+			continue
+		}
+
+		kidObjNr := kidIndRef.ObjectNumber.Value()
+		if pr.path[kidObjNr] {
+			pr.logf("obj#%d: removed cyclic kid obj#%d", objNr, kidObjNr)
+			continue
+		}
+		if c, ok := pr.counts[kidObjNr]; ok {
+			// Like RebalancePageTree keep repeated references in order to preserve the page count.
+			pr.logf("obj#%d: kept duplicate kid obj#%d", objNr, kidObjNr)
+			kids = append(kids, kidIndRef)
+			pr.pageNr += c
+			count += c
+			continue
+		}
+
+		kid, err := pr.ctx.DereferenceDict(kidIndRef)
+		if err != nil || kid == nil {
+			pr.logf("obj#%d: removed unresolvable kid obj#%d", objNr, kidObjNr)
+			continue
+		}
+
+		// A node without /Type is taken as a page tree node if it has kids.
+		t := kid.Type()
+		isPagesDict := t != nil && *t == "Pages" || t == nil && kid.ArrayEntry("Kids") != nil
+
+		if isPagesDict {
+			c, err := pr.repairPagesDict(kid, kidIndRef, hasMediaBox)
+			if err != nil {
+				return 0, err
+			}
+			if parent := kid.IndirectRefEntry("Parent"); parent == nil || *parent != ir {
+				kid.Update("Parent", ir)
+				pr.logf("obj#%d: fixed /Parent", kidObjNr)
+			}
+			pr.counts[kidObjNr] = c
+			kids = append(kids, kidIndRef)
+			count += c
+			continue
+		}
+
+		if err := pr.repairPage(kid, kidObjNr, ir, hasMediaBox); err != nil {
+			return 0, err
+		}
+		pr.counts[kidObjNr] = 1
+		kids = append(kids, kidIndRef)
+		count++
+	}
+
+	d.Update("Kids", kids)
+
+	if c := d.IntEntry("Count"); c == nil || *c != count {
+		d.Update("Count", types.Integer(count))
+		pr.logf("obj#%d: set /Count %d", objNr, count)
+	}
+
+	return count, nil
+}
+
+// RepairPages fixes pages missing required entries.
+// Missing /Type entries get set, pages without own or inherited /MediaBox get Letter size
+// and pages without resolvable /Contents get an empty content stream.
+// Kids causing cycles get removed. Pages and page tree nodes referenced more than once are kept and reported,
+// in line with XRefTable.RebalancePageTree.
+// The result is a list of all repairs made.
+func RepairPages(ctx *model.Context) ([]string, error) {
+	root, err := ctx.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.New("pdfcpu: RepairPages: missing page tree root")
+	}
+
+	pr := &pageRepair{ctx: ctx, path: map[int]bool{}, counts: map[int]int{}}
+
+	pageCount, err := pr.repairPagesDict(d, *root, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Delete("Parent") != nil {
+		pr.logf("obj#%d: removed /Parent of page tree root", root.ObjectNumber.Value())
+	}
+
+	ctx.PageCount = pageCount
+
+	return pr.ss, nil
+}
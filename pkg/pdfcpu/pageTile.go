@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// tileRects divides r into a grid of rows x cols tiles overlapping by overlap user space units
+// ordered by rows from top to bottom and within a row from left to right.
+func tileRects(r *types.Rectangle, rows, cols int, overlap float64) ([]*types.Rectangle, error) {
+	if rows < 1 || cols < 1 {
+		return nil, errors.Errorf("pdfcpu: TilePage: invalid grid: %dx%d", rows, cols)
+	}
+	if overlap < 0 {
+		return nil, errors.Errorf("pdfcpu: TilePage: invalid overlap: %.2f", overlap)
+	}
+
+	w := (r.Width() + float64(cols-1)*overlap) / float64(cols)
+	h := (r.Height() + float64(rows-1)*overlap) / float64(rows)
+	if (cols > 1 && overlap >= w) || (rows > 1 && overlap >= h) {
+		return nil, errors.Errorf("pdfcpu: TilePage: overlap %.2f exceeds tile size %.2f x %.2f", overlap, w, h)
+	}
+
+	rr := make([]*types.Rectangle, 0, rows*cols)
+
+	for i := 0; i < rows; i++ {
+		ury := r.UR.Y - float64(i)*(h-overlap)
+		for j := 0; j < cols; j++ {
+			llx := r.LL.X + float64(j)*(w-overlap)
+			rr = append(rr, types.NewRectangle(llx, ury-h, llx+w, ury))
+		}
+	}
+
+	return rr, nil
+}
+
+// tileContents returns the content of a page clipped to tile r by translating the shared page content c.
+func tileContents(xRefTable *model.XRefTable, c types.Object, r *types.Rectangle, suffix types.IndirectRef) (types.Array, error) {
+	prefix, err := xRefTable.StreamDictIndRef([]byte(fmt.Sprintf("q 1 0 0 1 %.4f %.4f cm\n", -r.LL.X, -r.LL.Y)))
+	if err != nil {
+		return nil, err
+	}
+
+	a := types.Array{*prefix}
+
+	switch c := c.(type) {
+	case types.IndirectRef:
+		o, err := xRefTable.Dereference(c)
+		if err != nil {
+			return nil, err
+		}
+		if a1, ok := o.(types.Array); ok {
+			a = append(a, a1...)
+		} else {
+			a = append(a, c)
+		}
+	case types.Array:
+		a = append(a, c...)
+	}
+
+	return append(a, suffix), nil
+}
+
+// TilePage creates a new PDF Context holding a grid of rows x cols pages each showing a tile of the visible region of page pageNr of ctx.
+// Adjacent tiles overlap by overlap user space units.
+// Tiles are ordered by rows from top to bottom and within a row from left to right, both in default user space.
+// All tiles share content and resources of the original page, each tile page translates the content and clips it to its tile.
+// The page rotation is carried over, annotations and form fields are not.
+func TilePage(ctx *model.Context, pageNr, rows, cols int, overlap float64) (*model.Context, error) {
+	pbs, err := ctx.PageBoundaries()
+	if err != nil {
+		return nil, err
+	}
+	if pageNr < 1 || pageNr > len(pbs) {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+
+	rr, err := tileRects(pbs[pageNr-1].CropBox(), rows, cols, overlap)
+	if err != nil {
+		return nil, err
+	}
+
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+	d.Delete("Annots")
+
+	pageNrs := make([]int, len(rr))
+	for i := range pageNrs {
+		pageNrs[i] = pageNr
+	}
+
+	ctxDest, err := ExtractPages(ctx, pageNrs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := ctxDest.StreamDictIndRef([]byte("\nQ"))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range rr {
+		d, _, _, err := ctxDest.PageDict(i+1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		a, err := tileContents(ctxDest.XRefTable, d["Contents"], r, *suffix)
+		if err != nil {
+			return nil, err
+		}
+		d["Contents"] = a
+
+		for _, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+			d.Delete(k)
+		}
+		box := types.NewRectangle(0, 0, r.Width(), r.Height())
+		d["MediaBox"] = box.Array()
+		d["CropBox"] = box.Array()
+	}
+
+	return ctxDest, nil
+}
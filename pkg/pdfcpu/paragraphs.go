@@ -0,0 +1,657 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/internal/corefont/metrics"
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Layout heuristics for grouping text runs, all relative to the font size.
+const (
+	textAscent      = .8  // glyph height above the baseline
+	textDescent     = .2  // glyph depth below the baseline
+	wordGap         = .15 // minimum horizontal gap between words
+	maxRunGap       = 3.  // maximum horizontal gap between runs of a line
+	maxLineDistance = 1.5 // maximum baseline distance between lines of a block
+	maxSizeRatio    = 1.2 // maximum font size ratio between lines of a block
+	maxFormDepth    = 8   // maximum nesting depth of form XObjects
+	maxOverprint    = .1  // maximum horizontal offset of text drawn repeatedly for a fake bold effect
+)
+
+// TextLine represents a line of text.
+type TextLine struct {
+	BBox     [4]float64 `json:"bbox"`
+	FontSize float64    `json:"fontSize"`
+	Text     string     `json:"text"`
+}
+
+// TextBlock represents a paragraph: a block of adjacent lines of text using similar font sizes.
+type TextBlock struct {
+	BBox     [4]float64 `json:"bbox"`
+	FontSize float64    `json:"fontSize"`
+	Text     string     `json:"text"`
+	Lines    []TextLine `json:"lines"`
+}
+
+// PageText represents the text blocks of a page.
+type PageText struct {
+	Page   int         `json:"page"`
+	Blocks []TextBlock `json:"blocks"`
+}
+
+// DocumentText represents the text blocks of a document organized by page.
+type DocumentText struct {
+	Source string     `json:"source"`
+	Pages  []PageText `json:"pages"`
+}
+
+// textRun is text shown by a single string operand in user space.
+type textRun struct {
+	s        string
+	x0, x1   float64 // horizontal extent
+	y0, y1   float64 // vertical extent
+	baseline float64
+	size     float64
+	font     *textFont
+	lastS    string  // the last fragment of a merged run
+	lastX0   float64 // the horizontal start of the last fragment
+}
+
+// mergeable returns true if r continues r0 using the same font, font size and baseline.
+func (r0 textRun) mergeable(r textRun) bool {
+	return r.font == r0.font &&
+		math.Abs(r.size-r0.size) <= .01*r0.size &&
+		math.Abs(r.baseline-r0.baseline) <= .05*r0.size
+}
+
+// overprints returns true if r repeats the last fragment of r0 at nearly the same position.
+func (r0 textRun) overprints(r textRun) bool {
+	return r.s == r0.lastS && math.Abs(r.x0-r0.lastX0) <= maxOverprint*r0.size
+}
+
+// mergeTextRuns joins adjacent runs using the same font, font size and baseline into words and lines,
+// eg. for generators showing each glyph by a separate string operand.
+// Runs repeating the previous run at nearly the same position to fake bold text get dropped.
+func mergeTextRuns(rr []textRun) []textRun {
+	var merged []textRun
+	for _, r := range rr {
+		n := len(merged)
+		if n == 0 || !merged[n-1].mergeable(r) {
+			r.lastS, r.lastX0 = r.s, r.x0
+			merged = append(merged, r)
+			continue
+		}
+		r0 := &merged[n-1]
+		if r0.overprints(r) {
+			continue
+		}
+		gap := r.x0 - r0.x1
+		if gap < -.5*r0.size || gap > maxRunGap*r0.size {
+			r.lastS, r.lastX0 = r.s, r.x0
+			merged = append(merged, r)
+			continue
+		}
+		if gap > wordGap*r0.size && !strings.HasSuffix(r0.s, " ") && !strings.HasPrefix(r.s, " ") {
+			r0.s += " "
+		}
+		r0.s += r.s
+		r0.x0, r0.x1 = math.Min(r0.x0, r.x0), math.Max(r0.x1, r.x1)
+		r0.y0, r0.y1 = math.Min(r0.y0, r.y0), math.Max(r0.y1, r.y1)
+		r0.lastS, r0.lastX0 = r.s, r.x0
+	}
+	return merged
+}
+
+// textFont provides glyph widths and Unicode values for the char codes of a font.
+type textFont struct {
+	twoByte      bool
+	widths       map[int]float64
+	dw           float64
+	missingWidth bool // dw is given by /MissingWidth
+	coreFont     string
+	toUnicode    map[int]string
+	charMap      map[byte]rune
+}
+
+func (f *textFont) width(code int) float64 {
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	if f.coreFont != "" {
+		return float64(metrics.CoreFontCharWidth(f.coreFont, code)) / 1000
+	}
+	return f.dw
+}
+
+// measured returns true if the width of the glyph for code is known rather than estimated.
+func (f *textFont) measured(code int) bool {
+	if _, ok := f.widths[code]; ok {
+		return true
+	}
+	return f.twoByte || f.coreFont != "" || f.missingWidth
+}
+
+func (f *textFont) text(code int) string {
+	if s, ok := f.toUnicode[code]; ok {
+		return s
+	}
+	if !f.twoByte {
+		if r, ok := f.charMap[byte(code)]; ok {
+			return string(r)
+		}
+		if code >= 0x20 && code < 0x7F {
+			return string(rune(code))
+		}
+	}
+	return string(rune(0xFFFD))
+}
+
+func (f *textFont) codes(bb []byte) []int {
+	var cc []int
+	if f.twoByte {
+		for i := 0; i+1 < len(bb); i += 2 {
+			cc = append(cc, int(bb[i])<<8+int(bb[i+1]))
+		}
+		return cc
+	}
+	for _, b := range bb {
+		cc = append(cc, int(b))
+	}
+	return cc
+}
+
+func loadTextFont(xRefTable *model.XRefTable, d types.Dict) *textFont {
+	f := &textFont{widths: map[int]float64{}}
+
+	f.toUnicode, _ = pdffont.ToUnicodeMap(xRefTable, d)
+
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		f.twoByte, f.dw = true, 1
+		if a, err := xRefTable.DereferenceArray(d["DescendantFonts"]); err == nil && len(a) > 0 {
+			if df, err := xRefTable.DereferenceDict(a[0]); err == nil && df != nil {
+				cidFontWidths(xRefTable, df, f.widths, &f.dw)
+			}
+		}
+		return f
+	}
+
+	scale := .001
+	if st := d.Subtype(); st != nil && *st == "Type3" {
+		if a, err := xRefTable.DereferenceArray(d["FontMatrix"]); err == nil && len(a) == 6 {
+			if sx, err := xRefTable.DereferenceNumber(a[0]); err == nil {
+				scale = sx
+			}
+		}
+	}
+
+	simpleFontWidths(xRefTable, d, scale, f.widths)
+
+	if fd, _ := xRefTable.DereferenceDict(d["FontDescriptor"]); fd != nil {
+		if mw, err := xRefTable.DereferenceNumber(fd["MissingWidth"]); err == nil {
+			f.dw, f.missingWidth = mw*scale, true
+		}
+	}
+
+	if fn := d.NameEntry("BaseFont"); fn != nil && len(f.widths) == 0 {
+		if s := stripSubsetPrefix(*fn); font.IsCoreFont(s) {
+			f.coreFont = s
+		}
+	}
+
+	f.charMap, _ = pdffont.SimpleFontCharMap(xRefTable, d)
+
+	return f
+}
+
+type textFontState struct {
+	font                     *textFont
+	fontSize, hScale         float64
+	charSpacing, wordSpacing float64
+	leading, rise            float64
+}
+
+type paragraphGraphicsState struct {
+	ctm matrix.Matrix
+	ts  textFontState
+}
+
+type paragraphCollector struct {
+	ctx     *model.Context
+	fonts   map[int]*textFont
+	runs    []textRun
+	gs      paragraphGraphicsState
+	gss     []paragraphGraphicsState
+	tm, tlm matrix.Matrix
+}
+
+// textFontForName returns the font for the font resource name of resDict using the font cache fonts.
+func textFontForName(xRefTable *model.XRefTable, fonts map[int]*textFont, name string, resDict types.Dict) *textFont {
+	ir, err := resourceIndRef(xRefTable, resDict, "Font", name)
+	if err != nil || ir == nil {
+		return nil
+	}
+	objNr := ir.ObjectNumber.Value()
+	if f, ok := fonts[objNr]; ok {
+		return f
+	}
+	d, err := xRefTable.DereferenceDict(*ir)
+	if err != nil || d == nil {
+		return nil
+	}
+	f := loadTextFont(xRefTable, d)
+	fonts[objNr] = f
+	return f
+}
+
+func (pc *paragraphCollector) nextLine(tx, ty float64) {
+	pc.tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(pc.tlm)
+	pc.tm = pc.tlm
+}
+
+func (pc *paragraphCollector) showText(bb []byte) {
+	ts := pc.gs.ts
+	f := ts.font
+	if f == nil {
+		return
+	}
+
+	var (
+		sb  strings.Builder
+		adv float64
+	)
+
+	for _, c := range f.codes(bb) {
+		adv += ts.advance(c)
+		sb.WriteString(f.text(c))
+	}
+
+	m := pc.tm.Multiply(pc.gs.ctm)
+	pc.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {adv, 0, 1}}.Multiply(pc.tm)
+
+	if sb.Len() == 0 {
+		return
+	}
+
+	box := ts.textBox(m, adv)
+
+	r := textRun{s: sb.String(), x0: box.LL.X, y0: box.LL.Y, x1: box.UR.X, y1: box.UR.Y, font: f}
+	r.size = ts.fontSize * math.Sqrt(math.Abs(m[0][0]*m[1][1]-m[0][1]*m[1][0]))
+	r.baseline = m.Transform(types.Point{Y: ts.rise}).Y
+
+	pc.runs = append(pc.runs, r)
+}
+
+func (pc *paragraphCollector) showTextArray(a types.Array) {
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			pc.showText(bb)
+			continue
+		}
+		if f, ok := number(o); ok {
+			tx := -f / 1000 * pc.gs.ts.fontSize * pc.gs.ts.hScale
+			pc.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.Multiply(pc.tm)
+		}
+	}
+}
+
+func (pc *paragraphCollector) formXObject(name string, resDict types.Dict, depth int) error {
+	ir, err := resourceIndRef(pc.ctx.XRefTable, resDict, "XObject", name)
+	if err != nil || ir == nil || depth >= maxFormDepth {
+		return err
+	}
+
+	sd, _, err := pc.ctx.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		return err
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	ops, err := model.ParseContentOps(sd.Content)
+	if err != nil {
+		return nil
+	}
+
+	res, err := pc.ctx.DereferenceDict(sd.Dict["Resources"])
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		res = resDict
+	}
+
+	gs, tm, tlm := pc.gs, pc.tm, pc.tlm
+	if a, err := pc.ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		if ff, ok := numbers(a); ok {
+			pc.gs.ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(pc.gs.ctm)
+		}
+	}
+
+	err = pc.process(ops, res, depth+1)
+
+	pc.gs, pc.tm, pc.tlm = gs, tm, tlm
+
+	return err
+}
+
+// advance returns the horizontal displacement in text space for showing the glyph for code.
+func (ts *textFontState) advance(code int) float64 {
+	tx := ts.font.width(code)*ts.fontSize + ts.charSpacing
+	if !ts.font.twoByte && code == 0x20 {
+		tx += ts.wordSpacing
+	}
+	return tx * ts.hScale
+}
+
+// textBox returns the approximate bounding box in user space of text showing a displacement of adv
+// starting at the origin of the text rendering matrix m.
+func (ts *textFontState) textBox(m matrix.Matrix, adv float64) types.Rectangle {
+	r := types.Rectangle{LL: types.Point{X: math.Inf(1), Y: math.Inf(1)}, UR: types.Point{X: math.Inf(-1), Y: math.Inf(-1)}}
+	for _, p := range []types.Point{
+		{X: 0, Y: ts.rise - textDescent*ts.fontSize},
+		{X: adv, Y: ts.rise - textDescent*ts.fontSize},
+		{X: 0, Y: ts.rise + textAscent*ts.fontSize},
+		{X: adv, Y: ts.rise + textAscent*ts.fontSize},
+	} {
+		p = m.Transform(p)
+		r.LL.X, r.UR.X = math.Min(r.LL.X, p.X), math.Max(r.UR.X, p.X)
+		r.LL.Y, r.UR.Y = math.Min(r.LL.Y, p.Y), math.Max(r.UR.Y, p.Y)
+	}
+	return r
+}
+
+// apply updates ts for the text state operation op using resDict and the font cache fonts.
+func (ts *textFontState) apply(op model.ContentOp, xRefTable *model.XRefTable, fonts map[int]*textFont, resDict types.Dict) {
+	if op.Operator == "Tf" {
+		if len(op.Operands) != 2 {
+			return
+		}
+		if name, ok := op.Operands[0].(types.Name); ok {
+			if fs, ok := number(op.Operands[1]); ok {
+				ts.font, ts.fontSize = textFontForName(xRefTable, fonts, name.Value(), resDict), fs
+			}
+		}
+		return
+	}
+
+	ff, ok := numOperands(op, 1)
+	if !ok {
+		return
+	}
+
+	switch op.Operator {
+	case "Tc":
+		ts.charSpacing = ff[0]
+	case "Tw":
+		ts.wordSpacing = ff[0]
+	case "Tz":
+		ts.hScale = ff[0] / 100
+	case "TL":
+		ts.leading = ff[0]
+	case "Ts":
+		ts.rise = ff[0]
+	}
+}
+
+func (pc *paragraphCollector) process(ops []model.ContentOp, resDict types.Dict, depth int) error {
+	for _, op := range ops {
+		ff, _ := numbers(op.Operands)
+
+		switch op.Operator {
+
+		case "q":
+			pc.gss = append(pc.gss, pc.gs)
+
+		case "Q":
+			if len(pc.gss) > 0 {
+				pc.gs, pc.gss = pc.gss[len(pc.gss)-1], pc.gss[:len(pc.gss)-1]
+			}
+
+		case "cm":
+			if len(ff) == 6 {
+				pc.gs.ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(pc.gs.ctm)
+			}
+
+		case "Do":
+			if len(op.Operands) == 1 {
+				if name, ok := op.Operands[0].(types.Name); ok {
+					if err := pc.formXObject(name.Value(), resDict, depth); err != nil {
+						return err
+					}
+				}
+			}
+
+		case "BT":
+			pc.tm, pc.tlm = matrix.IdentMatrix, matrix.IdentMatrix
+
+		case "Tf", "Tc", "Tw", "Tz", "TL", "Ts":
+			pc.gs.ts.apply(op, pc.ctx.XRefTable, pc.fonts, resDict)
+
+		case "Td":
+			if len(ff) == 2 {
+				pc.nextLine(ff[0], ff[1])
+			}
+
+		case "TD":
+			if len(ff) == 2 {
+				pc.gs.ts.leading = -ff[1]
+				pc.nextLine(ff[0], ff[1])
+			}
+
+		case "Tm":
+			if len(ff) == 6 {
+				pc.tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+				pc.tm = pc.tlm
+			}
+
+		case "T*":
+			pc.nextLine(0, -pc.gs.ts.leading)
+
+		case "Tj":
+			if len(op.Operands) == 1 {
+				if bb, ok := stringBytes(op.Operands[0]); ok {
+					pc.showText(bb)
+				}
+			}
+
+		case "'":
+			pc.nextLine(0, -pc.gs.ts.leading)
+			if len(op.Operands) == 1 {
+				if bb, ok := stringBytes(op.Operands[0]); ok {
+					pc.showText(bb)
+				}
+			}
+
+		case "\"":
+			if len(op.Operands) == 3 {
+				pc.gs.ts.wordSpacing, _ = number(op.Operands[0])
+				pc.gs.ts.charSpacing, _ = number(op.Operands[1])
+				pc.nextLine(0, -pc.gs.ts.leading)
+				if bb, ok := stringBytes(op.Operands[2]); ok {
+					pc.showText(bb)
+				}
+			}
+
+		case "TJ":
+			if len(op.Operands) == 1 {
+				if a, ok := op.Operands[0].(types.Array); ok {
+					pc.showTextArray(a)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func roundLayout(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+func layoutBox(x0, y0, x1, y1 float64) [4]float64 {
+	return [4]float64{roundLayout(x0), roundLayout(y0), roundLayout(x1), roundLayout(y1)}
+}
+
+type layoutLine struct {
+	textRun
+	sb strings.Builder
+}
+
+// continues returns true if r continues line l.
+func (l *layoutLine) continues(r textRun) bool {
+	size := math.Max(l.size, r.size)
+	if math.Abs(r.baseline-l.baseline) > .3*size {
+		return false
+	}
+	return r.x0 >= l.x1-.5*size && r.x0-l.x1 <= maxRunGap*size
+}
+
+func (l *layoutLine) add(r textRun) {
+	size := math.Max(l.size, r.size)
+	if r.x0-l.x1 > wordGap*size && !strings.HasSuffix(l.sb.String(), " ") && !strings.HasPrefix(r.s, " ") {
+		l.sb.WriteString(" ")
+	}
+	l.sb.WriteString(r.s)
+	l.x0, l.x1 = math.Min(l.x0, r.x0), math.Max(l.x1, r.x1)
+	l.y0, l.y1 = math.Min(l.y0, r.y0), math.Max(l.y1, r.y1)
+	l.size = size
+}
+
+func layoutLines(rr []textRun) []*layoutLine {
+	var ll []*layoutLine
+	for _, r := range rr {
+		if n := len(ll); n > 0 && ll[n-1].continues(r) {
+			ll[n-1].add(r)
+			continue
+		}
+		l := &layoutLine{textRun: r}
+		l.sb.WriteString(r.s)
+		ll = append(ll, l)
+	}
+	return ll
+}
+
+// continuesBlock returns true if line l continues the block b ending with line prev.
+func continuesBlock(b *TextBlock, prev, l *layoutLine) bool {
+	if math.Max(l.size, prev.size) > maxSizeRatio*math.Min(l.size, prev.size) {
+		return false
+	}
+	d := prev.baseline - l.baseline
+	if d <= 0 || d > maxLineDistance*math.Max(l.size, prev.size) {
+		return false
+	}
+	// Horizontal overlap
+	return l.x0 <= b.BBox[2] && l.x1 >= b.BBox[0]
+}
+
+func textBlocks(rr []textRun) []TextBlock {
+	var (
+		bb   []TextBlock
+		prev *layoutLine
+	)
+
+	for _, l := range layoutLines(mergeTextRuns(rr)) {
+		s := strings.TrimSpace(l.sb.String())
+		if s == "" {
+			continue
+		}
+		tl := TextLine{BBox: layoutBox(l.x0, l.y0, l.x1, l.y1), FontSize: roundLayout(l.size), Text: s}
+
+		if n := len(bb); n > 0 && continuesBlock(&bb[n-1], prev, l) {
+			b := &bb[n-1]
+			b.Lines = append(b.Lines, tl)
+			b.Text += " " + s
+			b.BBox = layoutBox(math.Min(b.BBox[0], l.x0), math.Min(b.BBox[1], l.y0), math.Max(b.BBox[2], l.x1), math.Max(b.BBox[3], l.y1))
+			b.FontSize = math.Max(b.FontSize, tl.FontSize)
+			prev = l
+			continue
+		}
+
+		bb = append(bb, TextBlock{BBox: tl.BBox, FontSize: tl.FontSize, Text: s, Lines: []TextLine{tl}})
+		prev = l
+	}
+
+	return bb
+}
+
+// Paragraphs returns the text of pages organized into blocks of adjacent lines.
+func Paragraphs(ctx *model.Context, pages []int) ([]PageText, error) {
+	pp := []PageText{}
+	for _, i := range pages {
+		bb, err := PageParagraphs(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if bb == nil {
+			bb = []TextBlock{}
+		}
+		pp = append(pp, PageText{Page: i, Blocks: bb})
+	}
+	return pp, nil
+}
+
+// PageParagraphs returns the text of a page organized into blocks of adjacent lines.
+// Adjacent text runs using the same font, font size and baseline get merged first,
+// text runs get joined into lines by baseline and horizontal proximity,
+// lines get joined into blocks by line spacing, font size and horizontal overlap, both in content stream order.
+// Bounding boxes are in user space and approximate glyph heights by font size.
+func PageParagraphs(ctx *model.Context, pageNr int) ([]TextBlock, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &paragraphCollector{
+		ctx:   ctx,
+		fonts: map[int]*textFont{},
+		gs:    paragraphGraphicsState{ctm: matrix.IdentMatrix, ts: textFontState{hScale: 1}},
+		tm:    matrix.IdentMatrix,
+		tlm:   matrix.IdentMatrix,
+	}
+
+	if err := pc.process(ops, inhPAttrs.Resources, 0); err != nil {
+		return nil, err
+	}
+
+	return textBlocks(pc.runs), nil
+}
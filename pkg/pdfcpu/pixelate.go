@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// minPixelBlocks is the number of blocks spanning the larger side of a region for automatic pixelation.
+const minPixelBlocks = 8
+
+// imageCTM returns the CTM in effect for the first rendering of the image XObject name by ops.
+func imageCTM(ops []model.ContentOp, name string) (matrix.Matrix, bool) {
+	ctm := matrix.IdentMatrix
+	var stack []matrix.Matrix
+
+	for _, op := range ops {
+		switch op.Operator {
+
+		case "q":
+			stack = append(stack, ctm)
+
+		case "Q":
+			if len(stack) > 0 {
+				ctm, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+
+		case "cm":
+			if ff, ok := numOperands(op, 6); ok {
+				ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ctm)
+			}
+
+		case "Do":
+			if len(op.Operands) == 1 {
+				if n, ok := op.Operands[0].(types.Name); ok && n.Value() == name {
+					return ctm, true
+				}
+			}
+		}
+	}
+
+	return matrix.Matrix{}, false
+}
+
+// Pixelate pixelates the regions of the image XObject id intersecting rects as rendered on page pageNr.
+// rects are given in user space. The samples of a region get averaged over blocks of blockSize x blockSize image pixels.
+// For blockSize <= 0 the block size gets chosen so a region spans at least 8 blocks.
+// The image gets re-encoded in place using the same codec, so all renderings of the image are affected.
+// Images using filters other than Flate, LZW, RunLength or DCT for gray or RGB JPEGs are not supported.
+// The result is a report of the pixel regions pixelated.
+func Pixelate(ctx *model.Context, pageNr int, id string, rects []types.Rectangle, blockSize int) ([]string, error) {
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return nil, errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d", pageNr)
+	}
+
+	ir, err := resourceIndRef(ctx.XRefTable, inhPAttrs.Resources, "XObject", id)
+	if err != nil {
+		return nil, err
+	}
+	if ir == nil {
+		return nil, errors.Errorf("pdfcpu: page %d: unknown image: %s", pageNr, id)
+	}
+
+	sd, _, err := ctx.DereferenceStreamDict(*ir)
+	if err != nil {
+		return nil, err
+	}
+	if sd == nil || sd.Subtype() == nil || *sd.Subtype() != "Image" {
+		return nil, errors.Errorf("pdfcpu: page %d: %s is not an image", pageNr, id)
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		if err == model.ErrNoContent {
+			return nil, errors.Errorf("pdfcpu: page %d: image %s is not rendered", pageNr, id)
+		}
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	ctm, ok := imageCTM(ops, id)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: page %d: image %s is not rendered by the page content", pageNr, id)
+	}
+
+	im, err := decodeImageSamples(ctx.XRefTable, sd)
+	if err != nil {
+		return nil, err
+	}
+	if im == nil {
+		return nil, errors.Errorf("pdfcpu: page %d: image %s: unsupported image encoding", pageNr, id)
+	}
+
+	var ss []string
+
+	for _, r := range rects {
+		pr, ok := imagePixelRect(ctm, im.w, im.h, r)
+		if !ok {
+			ss = append(ss, fmt.Sprintf("%s: no intersection with image %s", r.ShortString(), id))
+			continue
+		}
+		size := blockSize
+		if size <= 0 {
+			size = pr.Dx()
+			if pr.Dy() > size {
+				size = pr.Dy()
+			}
+			if size /= minPixelBlocks; size < 1 {
+				size = 1
+			}
+		}
+		if !im.pixelate(pr, size) {
+			return nil, errors.Errorf("pdfcpu: page %d: image %s: unsupported bits per component: %d", pageNr, id, im.bpc)
+		}
+		ss = append(ss, fmt.Sprintf("%s: pixelated image %s pixels %v using %dx%d blocks", r.ShortString(), id, pr, size, size))
+	}
+
+	var sd1 *types.StreamDict
+	if im.dct {
+		sd1, err = im.jpegStreamDict(sd, 90)
+	} else {
+		sd1, err = im.streamDict(ctx.XRefTable, sd)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := ctx.FindTableEntryForIndRef(ir)
+	if !ok {
+		return nil, errors.Errorf("pdfcpu: page %d: image %s: missing object %d", pageNr, id, ir.ObjectNumber.Value())
+	}
+	entry.Object = *sd1
+
+	return ss, nil
+}
@@ -37,8 +37,11 @@ const (
 )
 
 var (
-	ErrWrongPassword       = errors.New("pdfcpu: please provide the correct password")
-	zero             int64 = 0
+	ErrWrongPassword                = errors.New("pdfcpu: please provide the correct password")
+	ErrMaxPagesExceeded             = errors.New("pdfcpu: page count exceeds maxPages")
+	ErrMaxAnnotationsExceeded       = errors.New("pdfcpu: annotation count exceeds maxAnnotations")
+	ErrMaxFormFieldsExceeded        = errors.New("pdfcpu: form field count exceeds maxFormFields")
+	zero                      int64 = 0
 )
 
 // ReadFile reads in a PDF file and builds an internal structure holding its cross reference table aka the Context.
@@ -80,12 +83,24 @@ func Read(rs io.ReadSeeker, conf *model.Configuration) (*model.Context, error) {
 		return nil, errors.Wrap(err, "Read: xRefTable failed")
 	}
 
+	// Reject documents exceeding conf.MaxPages before loading all objects if possible.
+	maxPagesChecked, err := checkMaxPages(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make all objects explicitly available (load into memory) in corresponding xRefTable entries.
 	// Also decode any involved object streams.
 	if err = dereferenceXRefTable(ctx, conf); err != nil {
 		return nil, err
 	}
 
+	if !maxPagesChecked {
+		if _, err = checkMaxPages(ctx, true); err != nil {
+			return nil, err
+		}
+	}
+
 	// Some PDFWriters write an incorrect Size into trailer.
 	if *ctx.XRefTable.Size < len(ctx.XRefTable.Table) {
 		*ctx.XRefTable.Size = len(ctx.XRefTable.Table)
@@ -96,6 +111,212 @@ func Read(rs io.ReadSeeker, conf *model.Configuration) (*model.Context, error) {
 	return ctx, nil
 }
 
+// peekObject returns the object for ir without dereferencing the xRefTable.
+// The result is not cached, false means the object is not accessible, eg. because it lives in an encrypted object stream.
+func peekObject(ctx *model.Context, ir types.IndirectRef) (types.Object, bool) {
+	entry, found := ctx.FindTableEntryForIndRef(&ir)
+	if !found || entry.Free {
+		return nil, false
+	}
+	if entry.Object != nil {
+		return entry.Object, true
+	}
+	if entry.Compressed {
+		// Object streams of encrypted files need to be decrypted first.
+		if ctx.Encrypt != nil || entry.ObjectStream == nil || entry.ObjectStreamInd == nil {
+			return nil, false
+		}
+		osd, err := decodeObjectStream(ctx, *entry.ObjectStream, nil)
+		if err != nil {
+			return nil, false
+		}
+		o, err := osd.IndexedObject(*entry.ObjectStreamInd)
+		if err != nil || o == nil {
+			return nil, false
+		}
+		return o, true
+	}
+	if entry.Offset == nil || entry.Generation == nil {
+		return nil, false
+	}
+	o, err := ParseObject(ctx, *entry.Offset, ir.ObjectNumber.Value(), *entry.Generation)
+	if err != nil || o == nil {
+		return nil, false
+	}
+	return o, true
+}
+
+func peekDict(ctx *model.Context, o types.Object) (types.Dict, bool) {
+	if ir, ok := o.(types.IndirectRef); ok {
+		if o, ok = peekObject(ctx, ir); !ok {
+			return nil, false
+		}
+	}
+	d, ok := o.(types.Dict)
+	return d, ok
+}
+
+// peekPageCount returns the page count as recorded in the page tree root
+// by parsing just the catalog, the page tree root and its /Count.
+func peekPageCount(ctx *model.Context) (int, bool) {
+	if ctx.Root == nil {
+		return 0, false
+	}
+	d, ok := peekDict(ctx, *ctx.Root)
+	if !ok {
+		return 0, false
+	}
+	if d, ok = peekDict(ctx, d["Pages"]); !ok {
+		return 0, false
+	}
+	o := d["Count"]
+	if ir, ok := o.(types.IndirectRef); ok {
+		if o, ok = peekObject(ctx, ir); !ok {
+			return 0, false
+		}
+	}
+	i, ok := o.(types.Integer)
+	return i.Value(), ok
+}
+
+// checkMaxPages returns ErrMaxPagesExceeded if the page count of ctx exceeds conf.MaxPages.
+// Unless dereferenced the page count gets evaluated cheaply if possible,
+// false means the page count could not be evaluated yet.
+func checkMaxPages(ctx *model.Context, dereferenced bool) (bool, error) {
+	if ctx.MaxPages <= 0 || ctx.Cmd == model.TRUNCATEPAGES {
+		return true, nil
+	}
+
+	var pageCount int
+
+	if dereferenced {
+		if err := ctx.EnsurePageCount(); err != nil {
+			return true, err
+		}
+		pageCount = ctx.PageCount
+	} else {
+		var ok bool
+		if pageCount, ok = peekPageCount(ctx); !ok {
+			return false, nil
+		}
+	}
+
+	if pageCount > ctx.MaxPages {
+		return true, errors.Wrapf(ErrMaxPagesExceeded, "%d > %d", pageCount, ctx.MaxPages)
+	}
+
+	return true, nil
+}
+
+// objectLimits counts annotation and form field dicts while parsing objects and object streams
+// in order to enforce conf.MaxAnnotations and conf.MaxFormFields.
+// All objects parsed count including unreferenced ones eg. of previous revisions.
+type objectLimits struct {
+	maxAnnots, maxFields int
+	annots, fields       int
+}
+
+// newObjectLimits returns nil unless ctx limits annotations or form fields.
+func newObjectLimits(ctx *model.Context) *objectLimits {
+	if ctx.MaxAnnotations <= 0 && ctx.MaxFormFields <= 0 {
+		return nil
+	}
+	return &objectLimits{maxAnnots: ctx.MaxAnnotations, maxFields: ctx.MaxFormFields}
+}
+
+func isAnnotationDict(d types.Dict) bool {
+	if t := d.Type(); t != nil && *t == "Annot" {
+		return true
+	}
+	st := d.Subtype()
+	if st == nil {
+		return false
+	}
+	_, ok := model.AnnotTypes[*st]
+	_, hasRect := d.Find("Rect")
+	return ok && hasRect
+}
+
+func isFormFieldDict(d types.Dict) bool {
+	if _, ok := d.Find("FT"); ok {
+		return true
+	}
+	// Non terminal fields may inherit FT.
+	_, hasT := d.Find("T")
+	_, hasKids := d.Find("Kids")
+	_, hasParent := d.Find("Parent")
+	return hasT && (hasKids || hasParent)
+}
+
+// check counts o and returns ErrMaxAnnotationsExceeded or ErrMaxFormFieldsExceeded as soon as a limit has been exceeded.
+// Widget annotations merged with their field count as both.
+// A nil l checks nothing.
+func (l *objectLimits) check(o types.Object) error {
+	if l == nil {
+		return nil
+	}
+
+	d, ok := o.(types.Dict)
+	if !ok {
+		return nil
+	}
+
+	if l.maxAnnots > 0 && isAnnotationDict(d) {
+		if l.annots++; l.annots > l.maxAnnots {
+			return errors.Wrapf(ErrMaxAnnotationsExceeded, "more than %d", l.maxAnnots)
+		}
+	}
+
+	if l.maxFields > 0 && isFormFieldDict(d) {
+		if l.fields++; l.fields > l.maxFields {
+			return errors.Wrapf(ErrMaxFormFieldsExceeded, "more than %d", l.maxFields)
+		}
+	}
+
+	return nil
+}
+
+// ReadPageCount returns the page count of rs as recorded in the page tree root.
+// Only the cross reference table, the catalog and the page tree root get parsed if possible,
+// which is much cheaper than Read for large files.
+// Encrypted files using object streams need a full Read.
+func ReadPageCount(rs io.ReadSeeker, conf *model.Configuration) (int, error) {
+	if conf == nil {
+		conf = model.NewDefaultConfiguration()
+	}
+
+	ctx, err := model.NewContext(rs, conf)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = readXRefTable(ctx); err != nil {
+		return 0, errors.Wrap(err, "ReadPageCount: xRefTable failed")
+	}
+
+	if pageCount, ok := peekPageCount(ctx); ok {
+		return pageCount, nil
+	}
+
+	if _, err = rs.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	// Report the page count regardless of conf.MaxPages.
+	c := *conf
+	c.MaxPages = 0
+
+	if ctx, err = Read(rs, &c); err != nil {
+		return 0, err
+	}
+
+	if err = ctx.EnsurePageCount(); err != nil {
+		return 0, err
+	}
+
+	return ctx.PageCount, nil
+}
+
 // fillBuffer reads from r until buf is full or read returns an error.
 // Unlike io.ReadAtLeast fillBuffer does not return ErrUnexpectedEOF
 // if an EOF happens after reading some but not all the bytes.
@@ -227,14 +448,23 @@ func offsetLastXRefSection(ctx *model.Context, skip int64) (*int64, error) {
 
 	for i := 1; offset == 0; i++ {
 
-		off, err := rs.Seek(-int64(i)*bufSize-skip, io.SeekEnd)
+		// Files may be shorter than bufSize.
+		pos, n := ctx.Read.FileSize-int64(i)*bufSize-skip, bufSize
+		if pos < 0 {
+			pos, n = 0, n+pos
+		}
+		if n <= 0 {
+			return nil, errors.New("pdfcpu: can't find last xref section")
+		}
+
+		off, err := rs.Seek(pos, io.SeekStart)
 		if err != nil {
 			return nil, errors.New("pdfcpu: can't find last xref section")
 		}
 
 		log.Read.Printf("scanning for offsetLastXRefSection starting at %d\n", off)
 
-		curBuf := make([]byte, bufSize)
+		curBuf := make([]byte, n)
 
 		_, err = fillBuffer(rs, curBuf)
 		if err != nil {
@@ -271,7 +501,8 @@ func offsetLastXRefSection(ctx *model.Context, skip int64) (*int64, error) {
 }
 
 // Read next subsection entry and generate corresponding xref table entry.
-func parseXRefTableEntry(s *bufio.Scanner, xRefTable *model.XRefTable, objectNumber, repairOff int) error {
+// Free entries get recorded in free.
+func parseXRefTableEntry(s *bufio.Scanner, xRefTable *model.XRefTable, objectNumber, repairOff int, free types.IntSet) error {
 
 	log.Read.Println("parseXRefTableEntry: begin")
 
@@ -338,6 +569,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *model.XRefTable, objectNum
 				Offset:     &offset,
 				Generation: &generation}
 
+		free[objectNumber] = true
 	}
 
 	log.Read.Printf("parseXRefTableEntry: Insert new xreftable entry for Object %d\n", objectNumber)
@@ -350,7 +582,7 @@ func parseXRefTableEntry(s *bufio.Scanner, xRefTable *model.XRefTable, objectNum
 }
 
 // Process xRef table subsection and create corrresponding xRef table entries.
-func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *model.XRefTable, fields []string, repairOff int) error {
+func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *model.XRefTable, fields []string, repairOff int, free types.IntSet) error {
 
 	log.Read.Println("parseXRefTableSubSection: begin")
 
@@ -368,7 +600,7 @@ func parseXRefTableSubSection(s *bufio.Scanner, xRefTable *model.XRefTable, fiel
 
 	// Process all entries of this subsection into xRefTable entries.
 	for i := 0; i < objCount; i++ {
-		if err = parseXRefTableEntry(s, xRefTable, startObjNumber+i, repairOff); err != nil {
+		if err = parseXRefTableEntry(s, xRefTable, startObjNumber+i, repairOff, free); err != nil {
 			return err
 		}
 	}
@@ -406,7 +638,7 @@ func compressedObject(s string) (types.Object, error) {
 }
 
 // Parse all objects of an object stream and save them into objectStreamDict.ObjArray.
-func parseObjectStream(osd *types.ObjectStreamDict) error {
+func parseObjectStream(osd *types.ObjectStreamDict, limits *objectLimits) error {
 
 	log.Read.Printf("parseObjectStream begin: decoding %d objects.\n", osd.ObjCount)
 
@@ -446,6 +678,9 @@ func parseObjectStream(osd *types.ObjectStreamDict) error {
 			}
 
 			log.Read.Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2-1, objs[i-2], o)
+			if err := limits.check(o); err != nil {
+				return err
+			}
 			objArray = append(objArray, o)
 		}
 
@@ -458,6 +693,9 @@ func parseObjectStream(osd *types.ObjectStreamDict) error {
 			}
 
 			log.Read.Printf("parseObjectStream: [%d] = obj %s:\n%s\n", i/2, objs[i], o)
+			if err := limits.check(o); err != nil {
+				return err
+			}
 			objArray = append(objArray, o)
 		}
 
@@ -569,9 +807,11 @@ func extractXRefTableEntriesFromXRefStream(buf []byte, xsd *types.XRefStreamDict
 
 		}
 
-		if ctx.XRefTable.Exists(objectNumber) {
+		if ctx.XRefTable.Exists(objectNumber) && !(ctx.Read.XRefTableFree[objectNumber] && !xRefTableEntry.Free) {
 			log.Read.Printf("extractXRefTableEntriesFromXRefStream: Skip entry %d - already assigned\n", objectNumber)
 		} else {
+			// In hybrid files in use entries of the xref stream take precedence over free entries of the xref table of the same section.
+			delete(ctx.Read.XRefTableFree, objectNumber)
 			ctx.Table[objectNumber] = &xRefTableEntry
 		}
 
@@ -891,6 +1131,9 @@ func parseTrailerDict(trailerDict types.Dict, ctx *model.Context, offCurXRef *in
 		}
 	}
 
+	// Any previous xref section has its own xref table.
+	ctx.Read.XRefTableFree = types.IntSet{}
+
 	log.Read.Println("parseTrailerDict end")
 
 	return offset, nil
@@ -1083,10 +1326,13 @@ func parseXRefSection(s *bufio.Scanner, ctx *model.Context, ssCount *int, offCur
 
 	fields := strings.Fields(line)
 
+	// Hybrid files may mark objects living in object streams as free in the xref table.
+	ctx.Read.XRefTableFree = types.IntSet{}
+
 	// Process all sub sections of this xRef section.
 	for !strings.HasPrefix(line, "trailer") && len(fields) == 2 {
 
-		if err = parseXRefTableSubSection(s, ctx.XRefTable, fields, repairOff); err != nil {
+		if err = parseXRefTableSubSection(s, ctx.XRefTable, fields, repairOff, ctx.Read.XRefTableFree); err != nil {
 			return nil, err
 		}
 		*ssCount++
@@ -2204,86 +2450,101 @@ func logStream(o types.Object) {
 
 }
 
-// Decode all object streams so contained objects are ready to be used.
-func decodeObjectStreams(ctx *model.Context) error {
+// decodeObjectStream parses and decodes the object stream objectNumber.
+// Parsing aborts as soon as the contained objects exceed limits.
+func decodeObjectStream(ctx *model.Context, objectNumber int, limits *objectLimits) (*types.ObjectStreamDict, error) {
 
-	// Note:
-	// Entry "Extends" intentionally left out.
-	// No object stream collection validation necessary.
+	// Get XRefTableEntry.
+	entry := ctx.XRefTable.Table[objectNumber]
+	if entry == nil {
+		return nil, errors.Errorf("decodeObjectStream: missing entry for obj#%d\n", objectNumber)
+	}
 
-	log.Read.Println("decodeObjectStreams: begin")
+	log.Read.Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
 
-	// Get sorted slice of object numbers.
-	var keys []int
-	for k := range ctx.Read.ObjectStreams {
-		keys = append(keys, k)
+	// Parse object stream from file.
+	o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
+	if err != nil || o == nil {
+		return nil, errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
 	}
-	sort.Ints(keys)
 
-	for _, objectNumber := range keys {
+	// Ensure StreamDict
+	sd, ok := o.(types.StreamDict)
+	if !ok {
+		return nil, errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
+	}
 
-		// Get XRefTableEntry.
-		entry := ctx.XRefTable.Table[objectNumber]
-		if entry == nil {
-			return errors.Errorf("decodeObjectStream: missing entry for obj#%d\n", objectNumber)
-		}
+	// Load encoded stream content to xRefTable.
+	if _, err = loadEncodedStreamContent(ctx, &sd); err != nil {
+		return nil, errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
+	}
+
+	// Save decoded stream content to xRefTable.
+	if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, true); err != nil {
+		log.Read.Printf("obj %d: %s", objectNumber, err)
+		return nil, err
+	}
 
-		log.Read.Printf("decodeObjectStreams: parsing object stream for obj#%d\n", objectNumber)
+	// Ensure decoded objectArray for object stream dicts.
+	if !sd.IsObjStm() {
+		return nil, errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
+	}
 
-		// Parse object stream from file.
-		o, err := ParseObject(ctx, *entry.Offset, objectNumber, *entry.Generation)
-		if err != nil || o == nil {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	// We have an object stream.
+	log.Read.Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
 
-		// Ensure StreamDict
-		sd, ok := o.(types.StreamDict)
-		if !ok {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	// Create new object stream dict.
+	osd, err := model.ObjectStreamDict(&sd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
+	}
 
-		// Load encoded stream content to xRefTable.
-		if _, err = loadEncodedStreamContent(ctx, &sd); err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
-		}
+	log.Read.Printf("decodeObjectStreams: decoding object stream %d:\n", objectNumber)
 
-		// Save decoded stream content to xRefTable.
-		if err = saveDecodedStreamContent(ctx, &sd, objectNumber, *entry.Generation, true); err != nil {
-			log.Read.Printf("obj %d: %s", objectNumber, err)
-			return err
+	// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
+	if err = parseObjectStream(osd, limits); err != nil {
+		if errors.Is(err, ErrMaxAnnotationsExceeded) || errors.Is(err, ErrMaxFormFieldsExceeded) {
+			return nil, err
 		}
+		return nil, errors.Wrapf(err, "decodeObjectStreams: problem decoding object stream %d\n", objectNumber)
+	}
 
-		// Ensure decoded objectArray for object stream dicts.
-		if !sd.IsObjStm() {
-			return errors.New("pdfcpu: decodeObjectStreams: corrupt object stream")
-		}
+	if osd.ObjArray == nil {
+		return nil, errors.Wrap(err, "decodeObjectStreams: objArray should be set!")
+	}
 
-		// We have an object stream.
-		log.Read.Printf("decodeObjectStreams: object stream #%d\n", objectNumber)
+	log.Read.Printf("decodeObjectStreams: decoded object stream %d:\n", objectNumber)
 
-		ctx.Read.UsingObjectStreams = true
+	return osd, nil
+}
 
-		// Create new object stream dict.
-		osd, err := model.ObjectStreamDict(&sd)
-		if err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem dereferencing object stream %d", objectNumber)
-		}
+// Decode all object streams so contained objects are ready to be used.
+func decodeObjectStreams(ctx *model.Context, limits *objectLimits) error {
 
-		log.Read.Printf("decodeObjectStreams: decoding object stream %d:\n", objectNumber)
+	// Note:
+	// Entry "Extends" intentionally left out.
+	// No object stream collection validation necessary.
 
-		// Parse all objects of this object stream and save them to ObjectStreamDict.ObjArray.
-		if err = parseObjectStream(osd); err != nil {
-			return errors.Wrapf(err, "decodeObjectStreams: problem decoding object stream %d\n", objectNumber)
-		}
+	log.Read.Println("decodeObjectStreams: begin")
 
-		if osd.ObjArray == nil {
-			return errors.Wrap(err, "decodeObjectStreams: objArray should be set!")
+	// Get sorted slice of object numbers.
+	var keys []int
+	for k := range ctx.Read.ObjectStreams {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	for _, objectNumber := range keys {
+
+		osd, err := decodeObjectStream(ctx, objectNumber, limits)
+		if err != nil {
+			return err
 		}
 
-		log.Read.Printf("decodeObjectStreams: decoded object stream %d:\n", objectNumber)
+		ctx.Read.UsingObjectStreams = true
 
 		// Save object stream dict to xRefTableEntry.
-		entry.Object = *osd
+		ctx.XRefTable.Table[objectNumber].Object = *osd
 	}
 
 	log.Read.Println("decodeObjectStreams: end")
@@ -2372,7 +2633,9 @@ func updateBinaryTotalSize(ctx *model.Context, o types.Object) {
 
 }
 
-func dereferenceObject(ctx *model.Context, objNr int) error {
+// dereferenceObject parses object objNr unless compressed.
+// Objects from object streams have been checked against limits while decoding their object stream.
+func dereferenceObject(ctx *model.Context, objNr int, limits *objectLimits) error {
 
 	xRefTable := ctx.XRefTable
 	xRefTableSize := len(xRefTable.Table)
@@ -2407,6 +2670,9 @@ func dereferenceObject(ctx *model.Context, objNr int) error {
 
 	if o != nil {
 		// Already dereferenced.
+		if err := limits.check(o); err != nil {
+			return err
+		}
 		logStream(entry.Object)
 		updateBinaryTotalSize(ctx, o)
 		log.Read.Printf("handleCachedStreamDict: using cached object %d of %d\n<%s>\n", objNr, xRefTableSize, entry.Object)
@@ -2423,6 +2689,11 @@ func dereferenceObject(ctx *model.Context, objNr int) error {
 		return errors.Wrapf(err, "dereferenceObject: problem dereferencing object %d", objNr)
 	}
 
+	// Abort before loading any stream content.
+	if err := limits.check(o); err != nil {
+		return err
+	}
+
 	entry.Object = o
 
 	// Linearization dicts are validated and recorded for stats only.
@@ -2505,7 +2776,7 @@ func processRefCounts(xRefTable *model.XRefTable, o types.Object) {
 }
 
 // Dereferences all objects including compressed objects from object streams.
-func dereferenceObjects(ctx *model.Context) error {
+func dereferenceObjects(ctx *model.Context, limits *objectLimits) error {
 
 	log.Read.Println("dereferenceObjects: begin")
 
@@ -2520,7 +2791,7 @@ func dereferenceObjects(ctx *model.Context) error {
 	sort.Ints(keys)
 
 	for _, objNr := range keys {
-		err := dereferenceObject(ctx, objNr)
+		err := dereferenceObject(ctx, objNr, limits)
 		if err != nil {
 			return err
 		}
@@ -2592,14 +2863,17 @@ func dereferenceXRefTable(ctx *model.Context, conf *model.Configuration) error {
 	}
 	//fmt.Println("pw authenticated")
 
+	// Reject documents exceeding conf.MaxAnnotations or conf.MaxFormFields while parsing.
+	limits := newObjectLimits(ctx)
+
 	// Prepare decompressed objects.
-	err = decodeObjectStreams(ctx)
+	err = decodeObjectStreams(ctx, limits)
 	if err != nil {
 		return err
 	}
 
 	// For each xRefTableEntry assign a Object either by parsing from file or pointing to a decompressed object.
-	err = dereferenceObjects(ctx)
+	err = dereferenceObjects(ctx, limits)
 	if err != nil {
 		return err
 	}
@@ -2617,7 +2891,7 @@ func dereferenceXRefTable(ctx *model.Context, conf *model.Configuration) error {
 
 func handleUnencryptedFile(ctx *model.Context) error {
 
-	if ctx.Cmd == model.DECRYPT || ctx.Cmd == model.SETPERMISSIONS {
+	if ctx.Cmd == model.DECRYPT || ctx.Cmd == model.SETPERMISSIONS || ctx.Cmd == model.CLEARRESTRICTIONS {
 		return errors.New("pdfcpu: this file is not encrypted")
 	}
 
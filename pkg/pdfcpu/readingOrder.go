@@ -0,0 +1,362 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// readingOrderTolerance is the maximum vertical distance in user units for content considered to be on the same line.
+const readingOrderTolerance = 2.
+
+// structContent is a struct element owning marked content of a page.
+type structContent struct {
+	s      string // structure type
+	pageNr int
+	mcid   int // first MCID
+	d      types.Dict
+	objNr  int
+}
+
+type structTreeWalker struct {
+	ctx     *model.Context
+	pageNrs map[int]int
+	visited map[int]bool
+	cc      []structContent
+}
+
+func (w *structTreeWalker) pageNr(o types.Object, pageNr int) int {
+	if ir, ok := o.(types.IndirectRef); ok {
+		if i, ok := w.pageNrs[ir.ObjectNumber.Value()]; ok {
+			return i
+		}
+	}
+	return pageNr
+}
+
+// mcid returns the MCID and page number of a marked content reference within the kids of a struct element.
+func (w *structTreeWalker) mcid(o types.Object, pageNr int) (int, int, bool) {
+	o, err := w.ctx.Dereference(o)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch o := o.(type) {
+	case types.Integer:
+		return o.Value(), pageNr, true
+	case types.Dict:
+		if t := o.Type(); t != nil && *t == "MCR" {
+			if i := o.IntEntry("MCID"); i != nil {
+				return *i, w.pageNr(o["Pg"], pageNr), true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// walk collects struct elements owning marked content in logical order.
+func (w *structTreeWalker) walk(o types.Object, pageNr int) error {
+	objNr := 0
+	if ir, ok := o.(types.IndirectRef); ok {
+		objNr = ir.ObjectNumber.Value()
+		if w.visited[objNr] {
+			return nil
+		}
+		w.visited[objNr] = true
+	}
+
+	o, err := w.ctx.Dereference(o)
+	if err != nil || o == nil {
+		return err
+	}
+
+	if a, ok := o.(types.Array); ok {
+		for _, o := range a {
+			if err := w.walk(o, pageNr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	d, ok := o.(types.Dict)
+	if !ok {
+		return nil
+	}
+	if t := d.Type(); t != nil && (*t == "MCR" || *t == "OBJR") {
+		return nil
+	}
+
+	pageNr = w.pageNr(d["Pg"], pageNr)
+
+	k, err := w.ctx.Dereference(d["K"])
+	if err != nil {
+		return err
+	}
+	kids, ok := k.(types.Array)
+	if !ok {
+		kids = types.Array{k}
+	}
+
+	owner := false
+	for _, o := range kids {
+		if mcid, pageNr, ok := w.mcid(o, pageNr); ok {
+			if !owner && pageNr > 0 {
+				s := ""
+				if n := d.NameEntry("S"); n != nil {
+					s = *n
+				}
+				w.cc = append(w.cc, structContent{s: s, pageNr: pageNr, mcid: mcid, d: d, objNr: objNr})
+				owner = true
+			}
+			continue
+		}
+		if err := w.walk(o, pageNr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// markedContentPositions returns the position in user space of the first content within each marked content sequence of a page by MCID.
+func markedContentPositions(ctx *model.Context, pageNr int) (map[int]types.Point, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, true)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, err
+	}
+
+	var props types.Dict
+	if inhPAttrs.Resources != nil {
+		if props, err = ctx.DereferenceDict(inhPAttrs.Resources["Properties"]); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		ctm, tm, tlm = matrix.IdentMatrix, matrix.IdentMatrix, matrix.IdentMatrix
+		ctms         []matrix.Matrix
+		mcids        []int // marked content stack, -1 for sequences without MCID
+		leading      float64
+		m            = map[int]types.Point{}
+	)
+
+	mark := func(p types.Point) {
+		if len(mcids) == 0 {
+			return
+		}
+		mcid := mcids[len(mcids)-1]
+		if _, ok := m[mcid]; mcid >= 0 && !ok {
+			m[mcid] = p
+		}
+	}
+
+	nextLine := func(tx, ty float64) {
+		tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(tlm)
+		tm = tlm
+	}
+
+	for _, op := range ops {
+		ff, _ := numbers(op.Operands)
+
+		switch op.Operator {
+
+		case "q":
+			ctms = append(ctms, ctm)
+
+		case "Q":
+			if len(ctms) > 0 {
+				ctm, ctms = ctms[len(ctms)-1], ctms[:len(ctms)-1]
+			}
+
+		case "cm":
+			if len(ff) == 6 {
+				ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ctm)
+			}
+
+		case "BDC":
+			mcid := -1
+			if len(op.Operands) == 2 {
+				var d types.Dict
+				switch o := op.Operands[1].(type) {
+				case types.Dict:
+					d = o
+				case types.Name:
+					if props != nil {
+						d, _ = ctx.DereferenceDict(props[o.Value()])
+					}
+				}
+				if d != nil {
+					if i := d.IntEntry("MCID"); i != nil {
+						mcid = *i
+					}
+				}
+			}
+			mcids = append(mcids, mcid)
+
+		case "BMC":
+			mcids = append(mcids, -1)
+
+		case "EMC":
+			if len(mcids) > 0 {
+				mcids = mcids[:len(mcids)-1]
+			}
+
+		case "BT":
+			tm, tlm = matrix.IdentMatrix, matrix.IdentMatrix
+
+		case "TL":
+			if len(ff) == 1 {
+				leading = ff[0]
+			}
+
+		case "Td":
+			if len(ff) == 2 {
+				nextLine(ff[0], ff[1])
+			}
+
+		case "TD":
+			if len(ff) == 2 {
+				leading = -ff[1]
+				nextLine(ff[0], ff[1])
+			}
+
+		case "Tm":
+			if len(ff) == 6 {
+				tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+				tm = tlm
+			}
+
+		case "T*":
+			nextLine(0, -leading)
+
+		case "'", "\"":
+			nextLine(0, -leading)
+			mark(tm.Multiply(ctm).Transform(types.Point{}))
+
+		case "Tj", "TJ":
+			mark(tm.Multiply(ctm).Transform(types.Point{}))
+
+		case "m", "re":
+			if len(ff) >= 2 {
+				mark(ctm.Transform(types.Point{X: ff[0], Y: ff[1]}))
+			}
+
+		case "Do", "BI", "sh":
+			mark(ctm.Transform(types.Point{}))
+		}
+	}
+
+	return m, nil
+}
+
+// outOfReadingOrder returns true if content at c visually precedes content at p
+// using a top to bottom, left to right reading order.
+// Moving up and to the right is considered to be a column break.
+func outOfReadingOrder(p, c types.Point) bool {
+	if math.Abs(c.Y-p.Y) <= readingOrderTolerance {
+		return c.X < p.X-readingOrderTolerance
+	}
+	return c.Y > p.Y && c.X <= p.X+readingOrderTolerance
+}
+
+// structContents returns all struct elements owning marked content in logical order.
+// The result is nil for untagged documents.
+func structContents(ctx *model.Context) ([]structContent, error) {
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := ctx.DereferenceDict(root["StructTreeRoot"])
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	pageNrs, _, err := pageNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &structTreeWalker{ctx: ctx, pageNrs: pageNrs, visited: map[int]bool{}}
+	if err := w.walk(d["K"], 0); err != nil {
+		return nil, err
+	}
+
+	return w.cc, nil
+}
+
+// ReadingOrderIssues compares the logical order of the structure tree of a tagged PDF
+// against the on-page positions of the marked content sequences referenced.
+// The result is a list of all struct elements visually preceding their logical predecessor on the same page.
+// Marked content within form XObjects is not taken into account.
+func ReadingOrderIssues(ctx *model.Context) ([]string, error) {
+	cc, err := structContents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ss   []string
+		prev *structContent
+		pp   types.Point
+	)
+
+	positions := map[int]map[int]types.Point{}
+
+	for i, sc := range cc {
+		m, ok := positions[sc.pageNr]
+		if !ok {
+			if m, err = markedContentPositions(ctx, sc.pageNr); err != nil {
+				return nil, err
+			}
+			positions[sc.pageNr] = m
+		}
+
+		p, ok := m[sc.mcid]
+		if !ok {
+			// Empty or missing marked content.
+			continue
+		}
+
+		if prev != nil && prev.pageNr == sc.pageNr && outOfReadingOrder(pp, p) {
+			ss = append(ss, fmt.Sprintf("page %d: %s (MCID %d) at (%.2f, %.2f) precedes %s (MCID %d) at (%.2f, %.2f)",
+				sc.pageNr, sc.s, sc.mcid, p.X, p.Y, prev.s, prev.mcid, pp.X, pp.Y))
+		}
+
+		prev, pp = &cc[i], p
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,845 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// Redaction represents the areas to be redacted on a page.
+// Each rectangle is given by its lower left and upper right corner in user space: [llx lly urx ury].
+type Redaction struct {
+	Page  int          `json:"page"`
+	Rects [][4]float64 `json:"rects"`
+}
+
+// RedactionManifest represents a list of areas to be redacted.
+type RedactionManifest struct {
+	Redactions []Redaction `json:"redactions"`
+}
+
+// ParseRedactionManifest parses a JSON redaction manifest.
+func ParseRedactionManifest(r io.Reader) (*RedactionManifest, error) {
+	m := &RedactionManifest{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, errors.Wrap(err, "pdfcpu: invalid redaction manifest")
+	}
+	for _, red := range m.Redactions {
+		if red.Page < 1 {
+			return nil, errors.Errorf("pdfcpu: invalid redaction manifest: invalid page %d", red.Page)
+		}
+		for _, a := range red.Rects {
+			if a[0] == a[2] || a[1] == a[3] {
+				return nil, errors.Errorf("pdfcpu: invalid redaction manifest: page %d: empty rect %v", red.Page, a)
+			}
+		}
+	}
+	return m, nil
+}
+
+// pageRects returns the normalized rectangles to be redacted by page.
+func (m RedactionManifest) pageRects() map[int][]types.Rectangle {
+	rr := map[int][]types.Rectangle{}
+	for _, red := range m.Redactions {
+		for _, a := range red.Rects {
+			r := types.NewRectangle(math.Min(a[0], a[2]), math.Min(a[1], a[3]), math.Max(a[0], a[2]), math.Max(a[1], a[3]))
+			rr[red.Page] = append(rr[red.Page], *r)
+		}
+	}
+	return rr
+}
+
+// redactionCount counts the content removed for a rectangle.
+type redactionCount struct {
+	glyphs, paths, images, annots int
+}
+
+type redactor struct {
+	ctx    *model.Context
+	rects  []types.Rectangle
+	counts []redactionCount
+	fonts  map[int]*textFont
+}
+
+func intersects(r, r1 types.Rectangle) bool {
+	return r.LL.X < r1.UR.X && r1.LL.X < r.UR.X && r.LL.Y < r1.UR.Y && r1.LL.Y < r.UR.Y
+}
+
+func rectContains(r, r1 types.Rectangle) bool {
+	return r1.LL.X >= r.LL.X && r1.UR.X <= r.UR.X && r1.LL.Y >= r.LL.Y && r1.UR.Y <= r.UR.Y
+}
+
+// hit returns the index of the first rectangle to be redacted intersecting bb or -1.
+func (r *redactor) hit(bb types.Rectangle) int {
+	for i, r1 := range r.rects {
+		if intersects(r1, bb) {
+			return i
+		}
+	}
+	return -1
+}
+
+// covered returns the index of the first rectangle to be redacted containing bb or -1.
+func (r *redactor) covered(bb types.Rectangle) int {
+	for i, r1 := range r.rects {
+		if rectContains(r1, bb) {
+			return i
+		}
+	}
+	return -1
+}
+
+// transformedBox returns the bounding box of r transformed by m.
+func transformedBox(m matrix.Matrix, r types.Rectangle) types.Rectangle {
+	bb := types.Rectangle{LL: types.Point{X: math.Inf(1), Y: math.Inf(1)}, UR: types.Point{X: math.Inf(-1), Y: math.Inf(-1)}}
+	for _, p := range []types.Point{r.LL, {X: r.UR.X, Y: r.LL.Y}, r.UR, {X: r.LL.X, Y: r.UR.Y}} {
+		p = m.Transform(p)
+		bb.LL.X, bb.UR.X = math.Min(bb.LL.X, p.X), math.Max(bb.UR.X, p.X)
+		bb.LL.Y, bb.UR.Y = math.Min(bb.LL.Y, p.Y), math.Max(bb.UR.Y, p.Y)
+	}
+	return bb
+}
+
+var unitSquare = types.Rectangle{UR: types.Point{X: 1, Y: 1}}
+
+// redactImage returns a copy of the image XObject sd with all samples rendered into the rectangles to be redacted cleared.
+// The result is nil for images using unsupported filters.
+func (r *redactor) redactImage(sd *types.StreamDict, ctm matrix.Matrix) (*types.IndirectRef, error) {
+	im, err := decodeImageSamples(r.ctx.XRefTable, sd)
+	if err != nil || im == nil {
+		return nil, err
+	}
+
+	for _, rect := range r.rects {
+		if pr, ok := imagePixelRect(ctm, im.w, im.h, rect); ok {
+			im.clear(pr)
+		}
+	}
+
+	sd1, err := im.streamDict(r.ctx.XRefTable, sd)
+	if err != nil {
+		return nil, err
+	}
+
+	if o, found := sd.Find("SMask"); found {
+		// The soft mask carries the image outline.
+		sm, _, err := r.ctx.DereferenceStreamDict(o)
+		if err != nil {
+			return nil, err
+		}
+		sd1.Delete("SMask")
+		if sm != nil {
+			ir, err := r.redactImage(sm, ctm)
+			if err != nil {
+				return nil, err
+			}
+			if ir != nil {
+				sd1.Insert("SMask", *ir)
+			}
+		}
+	}
+
+	return r.ctx.IndRefForNewObject(*sd1)
+}
+
+type redactionGraphicsState struct {
+	ctm matrix.Matrix
+	ts  textFontState
+}
+
+// contentRedaction removes content within the rectangles to be redacted from a content stream.
+type contentRedaction struct {
+	r        *redactor
+	resDict  types.Dict
+	res      types.Dict // modified copy of resDict
+	depth    int
+	ops      []model.ContentOp
+	gs       redactionGraphicsState
+	gss      []redactionGraphicsState
+	tm, tlm  matrix.Matrix
+	path     []model.ContentOp
+	pathBox  types.Rectangle
+	changed  bool
+	replaced map[string]bool // names of XObjects replaced or removed
+}
+
+func newContentRedaction(r *redactor, resDict types.Dict, gs redactionGraphicsState, depth int) *contentRedaction {
+	return &contentRedaction{
+		r:        r,
+		resDict:  resDict,
+		depth:    depth,
+		gs:       gs,
+		replaced: map[string]bool{},
+		tm:       matrix.IdentMatrix,
+		tlm:      matrix.IdentMatrix,
+		pathBox:  types.Rectangle{LL: types.Point{X: math.Inf(1), Y: math.Inf(1)}, UR: types.Point{X: math.Inf(-1), Y: math.Inf(-1)}},
+	}
+}
+
+func (c *contentRedaction) emit(operator string, operands ...types.Object) {
+	c.ops = append(c.ops, model.ContentOp{Operator: operator, Operands: operands})
+}
+
+func (c *contentRedaction) nextLine(tx, ty float64) {
+	c.tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(c.tlm)
+	c.tm = c.tlm
+}
+
+// xObjects returns the XObject resources of a copy of the resources.
+func (c *contentRedaction) xObjects() (types.Dict, error) {
+	if c.res == nil {
+		c.res = types.NewDict()
+		for k, v := range c.resDict {
+			c.res[k] = v
+		}
+		xObjs, err := c.r.ctx.DereferenceDict(c.resDict["XObject"])
+		if err != nil {
+			return nil, err
+		}
+		d := types.NewDict()
+		for k, v := range xObjs {
+			d[k] = v
+		}
+		c.res["XObject"] = d
+	}
+	return c.res["XObject"].(types.Dict), nil
+}
+
+// addXObject adds ir to a copy of the resources and returns its resource name.
+func (c *contentRedaction) addXObject(ir types.IndirectRef) (string, error) {
+	d, err := c.xObjects()
+	if err != nil {
+		return "", err
+	}
+	name := d.NewIDForPrefix("Rx", 0)
+	d[name] = ir
+	return name, nil
+}
+
+// pruneXObjects removes all XObjects replaced or removed by the redaction from the resources unless still in use.
+// This makes sure the unredacted originals do not remain part of the page.
+func (c *contentRedaction) pruneXObjects() error {
+	if len(c.replaced) == 0 {
+		return nil
+	}
+
+	used := map[string]bool{}
+	for _, op := range c.ops {
+		if op.Operator == "Do" && len(op.Operands) == 1 {
+			if n, ok := op.Operands[0].(types.Name); ok {
+				used[n.Value()] = true
+			}
+		}
+	}
+
+	for name := range c.replaced {
+		if used[name] {
+			continue
+		}
+		d, err := c.xObjects()
+		if err != nil {
+			return err
+		}
+		delete(d, name)
+	}
+
+	return nil
+}
+
+func (c *contentRedaction) addPathPoints(ff []float64) {
+	for i := 0; i+1 < len(ff); i += 2 {
+		p := c.gs.ctm.Transform(types.Point{X: ff[i], Y: ff[i+1]})
+		c.pathBox.LL.X, c.pathBox.UR.X = math.Min(c.pathBox.LL.X, p.X), math.Max(c.pathBox.UR.X, p.X)
+		c.pathBox.LL.Y, c.pathBox.UR.Y = math.Min(c.pathBox.LL.Y, p.Y), math.Max(c.pathBox.UR.Y, p.Y)
+	}
+}
+
+// paint processes a path painting operation removing paths lying entirely within a rectangle to be redacted.
+func (c *contentRedaction) paint(op model.ContentOp) {
+	clip := false
+	for _, op := range c.path {
+		if op.Operator == "W" || op.Operator == "W*" {
+			clip = true
+		}
+	}
+
+	i := -1
+	if !clip && op.Operator != "n" && len(c.path) > 0 && !math.IsInf(c.pathBox.LL.X, 1) {
+		i = c.r.covered(c.pathBox)
+	}
+
+	if i >= 0 {
+		c.r.counts[i].paths++
+		c.changed = true
+	} else {
+		c.ops = append(c.ops, c.path...)
+		c.ops = append(c.ops, op)
+	}
+
+	c.path = nil
+	c.pathBox = types.Rectangle{LL: types.Point{X: math.Inf(1), Y: math.Inf(1)}, UR: types.Point{X: math.Inf(-1), Y: math.Inf(-1)}}
+}
+
+// unmeasuredTextLength is the length in user space assumed for text whose glyph widths are unknown.
+const unmeasuredTextLength = 100000
+
+// unmeasuredHit returns the index of the first rectangle to be redacted intersecting
+// the text line starting at the origin of the text rendering matrix m or -1.
+func (c *contentRedaction) unmeasuredHit(m matrix.Matrix) int {
+	sx := math.Hypot(m[0][0], m[0][1])
+	if sx == 0 {
+		return -1
+	}
+	return c.r.hit(c.gs.ts.textBox(m, unmeasuredTextLength/sx))
+}
+
+// showText removes all glyphs within rectangles to be redacted from a text showing operation
+// and compensates the displacement of the glyphs removed so the remaining glyphs keep their positions.
+// Glyph positions are unknown from the first glyph on whose width can't be resolved, eg. for missing fonts.
+// Failing closed these glyphs get removed if the rest of the text line intersects a rectangle to be redacted.
+func (c *contentRedaction) showText(op model.ContentOp, oo []types.Object) {
+	ts := &c.gs.ts
+	if ts.font == nil {
+		ts.font = &textFont{widths: map[int]float64{}, dw: .5}
+	}
+	f := ts.font
+
+	var (
+		a       types.Array
+		seg     []byte
+		removed float64 // pending displacement of removed glyphs
+		n       int
+		unknown bool // glyph positions are unknown
+		strip   = -1 // rectangle hit by the glyphs of unknown position
+	)
+
+	flush := func() {
+		if len(seg) > 0 {
+			a = append(a, types.NewHexLiteral(seg))
+			seg = nil
+		}
+		if removed != 0 && ts.fontSize != 0 && ts.hScale != 0 {
+			a = append(a, types.Float(-removed*1000/(ts.fontSize*ts.hScale)))
+		}
+		removed = 0
+	}
+
+	for _, o := range oo {
+		bb, ok := stringBytes(o)
+		if !ok {
+			if tj, ok := number(o); ok {
+				flush()
+				a = append(a, o)
+				tx := -tj / 1000 * ts.fontSize * ts.hScale
+				c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.Multiply(c.tm)
+			}
+			continue
+		}
+		codes := f.codes(bb)
+		size := 1
+		if len(codes) > 0 {
+			size = len(bb) / len(codes)
+		}
+		for j, code := range codes {
+			adv := ts.advance(code)
+			m := c.tm.Multiply(c.gs.ctm)
+			if !unknown && !f.measured(code) {
+				unknown, strip = true, c.unmeasuredHit(m)
+			}
+			i := strip
+			if !unknown {
+				i = c.r.hit(ts.textBox(m, adv))
+			}
+			if i >= 0 {
+				c.r.counts[i].glyphs++
+				removed += adv
+				n++
+			} else {
+				if removed != 0 {
+					flush()
+				}
+				seg = append(seg, bb[j*size:(j+1)*size]...)
+			}
+			c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {adv, 0, 1}}.Multiply(c.tm)
+		}
+	}
+
+	if n == 0 {
+		c.ops = append(c.ops, op)
+		return
+	}
+
+	flush()
+	c.changed = true
+
+	switch op.Operator {
+	case "'":
+		c.emit("T*")
+	case "\"":
+		c.emit("Tw", op.Operands[0])
+		c.emit("Tc", op.Operands[1])
+		c.emit("T*")
+	}
+	c.emit("TJ", a)
+}
+
+func (c *contentRedaction) textOp(op model.ContentOp) {
+	ts := &c.gs.ts
+	switch op.Operator {
+
+	case "Tj":
+		if len(op.Operands) == 1 {
+			c.showText(op, op.Operands)
+			return
+		}
+
+	case "TJ":
+		if len(op.Operands) == 1 {
+			if a, ok := op.Operands[0].(types.Array); ok {
+				c.showText(op, a)
+				return
+			}
+		}
+
+	case "'":
+		if len(op.Operands) == 1 {
+			c.nextLine(0, -ts.leading)
+			c.showText(op, op.Operands)
+			return
+		}
+
+	case "\"":
+		if len(op.Operands) == 3 {
+			ts.wordSpacing, _ = number(op.Operands[0])
+			ts.charSpacing, _ = number(op.Operands[1])
+			c.nextLine(0, -ts.leading)
+			c.showText(op, op.Operands[2:])
+			return
+		}
+	}
+
+	c.ops = append(c.ops, op)
+}
+
+func (c *contentRedaction) imageXObject(op model.ContentOp, sd *types.StreamDict) error {
+	bb := transformedBox(c.gs.ctm, unitSquare)
+	i := c.r.hit(bb)
+	if i < 0 {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+
+	c.r.counts[i].images++
+	c.changed = true
+
+	if c.r.covered(bb) >= 0 {
+		return nil
+	}
+
+	ir, err := c.r.redactImage(sd, c.gs.ctm)
+	if err != nil || ir == nil {
+		// Unsupported images get removed entirely.
+		return err
+	}
+
+	name, err := c.addXObject(*ir)
+	if err != nil {
+		return err
+	}
+
+	c.emit("Do", types.Name(name))
+	return nil
+}
+
+func (c *contentRedaction) formXObject(op model.ContentOp, sd *types.StreamDict) error {
+	ctm := c.gs.ctm
+	if a, err := c.r.ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(a) == 6 {
+		if ff, ok := numbers(a); ok {
+			ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(ctm)
+		}
+	}
+
+	bbox := unitSquare
+	if a, err := c.r.ctx.DereferenceArray(sd.Dict["BBox"]); err == nil && len(a) == 4 {
+		if ff, ok := numbers(a); ok {
+			bbox = *types.NewRectangle(math.Min(ff[0], ff[2]), math.Min(ff[1], ff[3]), math.Max(ff[0], ff[2]), math.Max(ff[1], ff[3]))
+		}
+	}
+
+	if c.r.hit(transformedBox(ctm, bbox)) < 0 || c.depth >= maxFormDepth {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	ops, err := model.ParseContentOps(sd.Content)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.r.ctx.DereferenceDict(sd.Dict["Resources"])
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		res = c.resDict
+	}
+
+	cr := newContentRedaction(c.r, res, redactionGraphicsState{ctm: ctm, ts: c.gs.ts}, c.depth+1)
+	if err := cr.process(ops); err != nil {
+		return err
+	}
+	if err := cr.pruneXObjects(); err != nil {
+		return err
+	}
+
+	if !cr.changed {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+
+	sd1, _ := c.r.ctx.NewStreamDictForBuf(model.ContentOpsBytes(cr.ops))
+	for k, v := range sd.Dict {
+		switch k {
+		case "Filter", "DecodeParms", "Length":
+			continue
+		}
+		sd1.Dict[k] = v
+	}
+	if cr.res != nil {
+		sd1.Dict["Resources"] = cr.res
+	}
+	if err := sd1.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := c.r.ctx.IndRefForNewObject(*sd1)
+	if err != nil {
+		return err
+	}
+
+	name, err := c.addXObject(*ir)
+	if err != nil {
+		return err
+	}
+
+	c.changed = true
+	c.emit("Do", types.Name(name))
+	return nil
+}
+
+func (c *contentRedaction) xObject(op model.ContentOp) error {
+	if len(op.Operands) != 1 {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+
+	name, ok := op.Operands[0].(types.Name)
+	if !ok {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+
+	ir, err := resourceIndRef(c.r.ctx.XRefTable, c.resDict, "XObject", name.Value())
+	if err != nil || ir == nil {
+		c.ops = append(c.ops, op)
+		return err
+	}
+
+	sd, _, err := c.r.ctx.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		c.ops = append(c.ops, op)
+		return err
+	}
+
+	n := len(c.ops)
+
+	switch st := sd.Subtype(); {
+	case st != nil && *st == "Image":
+		err = c.imageXObject(op, sd)
+	case st != nil && *st == "Form":
+		err = c.formXObject(op, sd)
+	default:
+		c.ops = append(c.ops, op)
+	}
+
+	if len(c.ops) == n || c.ops[n].Operands[0] != op.Operands[0] {
+		c.replaced[name.Value()] = true
+	}
+
+	return err
+}
+
+func (c *contentRedaction) process(ops []model.ContentOp) error {
+	for _, op := range ops {
+		ff, _ := numbers(op.Operands)
+
+		switch op.Operator {
+
+		case "m", "l", "c", "v", "y", "h", "W", "W*":
+			c.addPathPoints(ff)
+			c.path = append(c.path, op)
+
+		case "re":
+			if len(ff) == 4 {
+				c.addPathPoints([]float64{ff[0], ff[1], ff[0] + ff[2], ff[1] + ff[3], ff[0], ff[1] + ff[3], ff[0] + ff[2], ff[1]})
+			}
+			c.path = append(c.path, op)
+
+		case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+			c.paint(op)
+
+		case "q":
+			c.gss = append(c.gss, c.gs)
+			c.ops = append(c.ops, op)
+
+		case "Q":
+			if len(c.gss) > 0 {
+				c.gs, c.gss = c.gss[len(c.gss)-1], c.gss[:len(c.gss)-1]
+			}
+			c.ops = append(c.ops, op)
+
+		case "cm":
+			if len(ff) == 6 {
+				c.gs.ctm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}.Multiply(c.gs.ctm)
+			}
+			c.ops = append(c.ops, op)
+
+		case "BT":
+			c.tm, c.tlm = matrix.IdentMatrix, matrix.IdentMatrix
+			c.ops = append(c.ops, op)
+
+		case "Tf", "Tc", "Tw", "Tz", "TL", "Ts":
+			c.gs.ts.apply(op, c.r.ctx.XRefTable, c.r.fonts, c.resDict)
+			c.ops = append(c.ops, op)
+
+		case "Td", "TD":
+			if len(ff) == 2 {
+				if op.Operator == "TD" {
+					c.gs.ts.leading = -ff[1]
+				}
+				c.nextLine(ff[0], ff[1])
+			}
+			c.ops = append(c.ops, op)
+
+		case "Tm":
+			if len(ff) == 6 {
+				c.tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+				c.tm = c.tlm
+			}
+			c.ops = append(c.ops, op)
+
+		case "T*":
+			c.nextLine(0, -c.gs.ts.leading)
+			c.ops = append(c.ops, op)
+
+		case "Tj", "TJ", "'", "\"":
+			c.textOp(op)
+
+		case "Do":
+			if err := c.xObject(op); err != nil {
+				return err
+			}
+
+		case "BI":
+			if i := c.r.hit(transformedBox(c.gs.ctm, unitSquare)); i >= 0 {
+				c.r.counts[i].images++
+				c.changed = true
+				continue
+			}
+			c.ops = append(c.ops, op)
+
+		default:
+			c.ops = append(c.ops, op)
+		}
+	}
+
+	return nil
+}
+
+// redactAnnotations removes all annotations except widgets intersecting a rectangle to be redacted along with their popups.
+func (r *redactor) redactAnnotations(d types.Dict) error {
+	a, err := r.ctx.DereferenceArray(d["Annots"])
+	if err != nil || len(a) == 0 {
+		return err
+	}
+
+	removed := map[int]bool{}
+	keep := make([]bool, len(a))
+
+	for j, o := range a {
+		keep[j] = true
+		ad, err := r.ctx.DereferenceDict(o)
+		if err != nil || ad == nil {
+			continue
+		}
+		if st := ad.Subtype(); st != nil && *st == "Widget" {
+			continue
+		}
+		arr, err := r.ctx.DereferenceArray(ad["Rect"])
+		if err != nil || len(arr) != 4 {
+			continue
+		}
+		ff, ok := numbers(arr)
+		if !ok {
+			continue
+		}
+		rect := types.NewRectangle(math.Min(ff[0], ff[2]), math.Min(ff[1], ff[3]), math.Max(ff[0], ff[2]), math.Max(ff[1], ff[3]))
+		if i := r.hit(*rect); i >= 0 {
+			r.counts[i].annots++
+			keep[j] = false
+			if ir, ok := o.(types.IndirectRef); ok {
+				removed[ir.ObjectNumber.Value()] = true
+			}
+		}
+	}
+
+	var a1 types.Array
+	for j, o := range a {
+		if !keep[j] {
+			continue
+		}
+		if ad, err := r.ctx.DereferenceDict(o); err == nil && ad != nil {
+			if ir := ad.IndirectRefEntry("Parent"); ir != nil && removed[ir.ObjectNumber.Value()] {
+				continue
+			}
+		}
+		a1 = append(a1, o)
+	}
+
+	if len(a1) < len(a) {
+		d["Annots"] = a1
+	}
+
+	return nil
+}
+
+func (r *redactor) redactPage(pageNr int) error {
+	d, _, inhPAttrs, err := r.ctx.PageDict(pageNr, true)
+	if err != nil || d == nil {
+		return err
+	}
+
+	if err := r.redactAnnotations(d); err != nil {
+		return err
+	}
+
+	var ops []model.ContentOp
+
+	bb, err := r.ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return err
+	}
+
+	if err == nil {
+		if ops, err = model.ParseContentOps(bb); err != nil {
+			return err
+		}
+	}
+
+	gs := redactionGraphicsState{ctm: matrix.IdentMatrix, ts: textFontState{hScale: 1}}
+	c := newContentRedaction(r, inhPAttrs.Resources, gs, 0)
+	if err := c.process(ops); err != nil {
+		return err
+	}
+	if len(c.path) > 0 {
+		// Dangling path construction.
+		c.ops = append(c.ops, c.path...)
+	}
+	if err := c.pruneXObjects(); err != nil {
+		return err
+	}
+
+	// Cover all redacted areas.
+	ops1 := append([]model.ContentOp{{Operator: "q"}}, c.ops...)
+	ops1 = append(ops1, model.ContentOp{Operator: "Q"}, model.ContentOp{Operator: "q"})
+	ops1 = append(ops1, model.ContentOp{Operator: "g", Operands: []types.Object{types.Integer(0)}})
+	for _, rect := range r.rects {
+		ops1 = append(ops1, model.ContentOp{Operator: "re", Operands: []types.Object{
+			types.Float(rect.LL.X), types.Float(rect.LL.Y), types.Float(rect.Width()), types.Float(rect.Height())}})
+	}
+	ops1 = append(ops1, model.ContentOp{Operator: "f"}, model.ContentOp{Operator: "Q"})
+
+	sd, _ := r.ctx.NewStreamDictForBuf(model.ContentOpsBytes(ops1))
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := r.ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	d["Contents"] = *ir
+	if c.res != nil {
+		d["Resources"] = c.res
+	}
+
+	return nil
+}
+
+func (rc redactionCount) String() string {
+	return fmt.Sprintf("removed %d glyphs, %d paths, %d images, %d annotations", rc.glyphs, rc.paths, rc.images, rc.annots)
+}
+
+// Redact removes all content within the areas of m and covers these areas with black boxes.
+// Glyphs intersecting an area get removed from text showing operations,
+// glyphs of unknown width and all glyphs following them within a text showing operation get removed if their text line intersects an area,
+// paths and images lying entirely within an area get removed,
+// images partially covered get their samples within the area cleared
+// and annotations other than widgets intersecting an area get removed.
+// Images using filters other than Flate, LZW, RunLength or DCT for gray or RGB JPEGs get removed entirely if intersecting.
+// Form XObjects intersecting an area get redacted as copies.
+// The result is a report of the content removed per area.
+func Redact(ctx *model.Context, m RedactionManifest) ([]string, error) {
+	pageRects := m.pageRects()
+
+	pageNrs := make([]int, 0, len(pageRects))
+	for i := range pageRects {
+		if i > ctx.PageCount {
+			return nil, errors.Errorf("pdfcpu: invalid redaction manifest: invalid page %d", i)
+		}
+		pageNrs = append(pageNrs, i)
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+
+	for _, i := range pageNrs {
+		r := &redactor{ctx: ctx, rects: pageRects[i], counts: make([]redactionCount, len(pageRects[i])), fonts: map[int]*textFont{}}
+		if err := r.redactPage(i); err != nil {
+			return nil, err
+		}
+		for j, rect := range r.rects {
+			ss = append(ss, fmt.Sprintf("page %d %s: %s", i, rect.ShortString(), r.counts[j]))
+		}
+	}
+
+	return ss, nil
+}
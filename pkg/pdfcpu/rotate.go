@@ -17,9 +17,15 @@ limitations under the License.
 package pdfcpu
 
 import (
+	"fmt"
+	"math"
+	"sort"
+
 	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
 	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
 )
 
 func rotatePage(xRefTable *model.XRefTable, i, j int) error {
@@ -51,3 +57,400 @@ func RotatePages(ctx *model.Context, selectedPages types.IntSet, rotation int) e
 
 	return nil
 }
+
+// rotationMatrix returns the transform mapping the page space of mediaBox rotated clockwise by rot
+// onto a rotation free page space with origin (0,0).
+func rotationMatrix(mediaBox *types.Rectangle, rot int) matrix.Matrix {
+	llx, lly, urx, ury := mediaBox.LL.X, mediaBox.LL.Y, mediaBox.UR.X, mediaBox.UR.Y
+	switch rot {
+	case 90:
+		return matrix.Matrix{{0, -1, 0}, {1, 0, 0}, {-lly, urx, 1}}
+	case 180:
+		return matrix.Matrix{{-1, 0, 0}, {0, -1, 0}, {urx, ury, 1}}
+	case 270:
+		return matrix.Matrix{{0, 1, 0}, {-1, 0, 0}, {ury, -llx, 1}}
+	}
+	return matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {-llx, -lly, 1}}
+}
+
+func transformRect(m matrix.Matrix, r *types.Rectangle) *types.Rectangle {
+	p := m.Transform(r.LL)
+	q := m.Transform(r.UR)
+	return types.NewRectangle(math.Min(p.X, q.X), math.Min(p.Y, q.Y), math.Max(p.X, q.X), math.Max(p.Y, q.Y))
+}
+
+func transformBoxEntry(xRefTable *model.XRefTable, d types.Dict, key string, m matrix.Matrix) error {
+	a, err := xRefTable.DereferenceArray(d[key])
+	if err != nil || len(a) != 4 {
+		return err
+	}
+	r, err := types.RectForArray(a)
+	if err != nil {
+		return err
+	}
+	d.Update(key, transformRect(m, r).Array())
+	return nil
+}
+
+// rotateAppearances rotates the appearance streams of annotation d clockwise by rot.
+func rotateAppearances(xRefTable *model.XRefTable, d types.Dict, rot int, visited map[int]bool) error {
+	ap, err := xRefTable.DereferenceDict(d["AP"])
+	if err != nil || ap == nil {
+		return err
+	}
+
+	r := matrix.CalcRotateAndTranslateTransformMatrix(float64(-rot), 0, 0)
+
+	rotate := func(o types.Object) error {
+		ir, ok := o.(types.IndirectRef)
+		if !ok || visited[ir.ObjectNumber.Value()] {
+			return nil
+		}
+		visited[ir.ObjectNumber.Value()] = true
+		sd, _, err := xRefTable.DereferenceStreamDict(ir)
+		if err != nil || sd == nil {
+			return err
+		}
+		m := matrix.IdentMatrix
+		if a := sd.ArrayEntry("Matrix"); len(a) == 6 {
+			for i := 0; i < 6; i++ {
+				f, err := xRefTable.DereferenceNumber(a[i])
+				if err != nil {
+					return err
+				}
+				m[i/2][i%2] = f
+			}
+		}
+		m = m.Multiply(r)
+		sd.Dict.Update("Matrix", types.NewNumberArray(m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1]))
+		return nil
+	}
+
+	for _, k := range []string{"N", "R", "D"} {
+		o, found := ap.Find(k)
+		if !found {
+			continue
+		}
+		if d1, err := xRefTable.DereferenceDict(o); err == nil && d1 != nil {
+			// Appearance subdictionary keyed by state.
+			for _, o1 := range d1 {
+				if err := rotate(o1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := rotate(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rotateAnnotations(xRefTable *model.XRefTable, d types.Dict, m matrix.Matrix, rot int, visited map[int]bool) error {
+	a, err := xRefTable.DereferenceArray(d["Annots"])
+	if err != nil || a == nil {
+		return err
+	}
+
+	for _, o := range a {
+		d1, err := xRefTable.DereferenceDict(o)
+		if err != nil || d1 == nil {
+			continue
+		}
+		if err := transformBoxEntry(xRefTable, d1, "Rect", m); err != nil {
+			return err
+		}
+		if err := rotateAppearances(xRefTable, d1, rot, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizePageRotation bakes the rotation of page pageNr into its content and returns the rotation removed.
+func normalizePageRotation(ctx *model.Context, pageNr int, visited map[int]bool) (int, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return 0, err
+	}
+	if d == nil || inhPAttrs == nil {
+		return 0, errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	rot := (inhPAttrs.Rotate%360 + 360) % 360
+	if rot == 0 {
+		return 0, nil
+	}
+	if rot%90 != 0 {
+		return 0, errors.Errorf("pdfcpu: page %d: invalid rotation: %d", pageNr, inhPAttrs.Rotate)
+	}
+
+	mediaBox := inhPAttrs.MediaBox
+	if mediaBox == nil {
+		return 0, errors.Errorf("pdfcpu: page %d: missing mediaBox", pageNr)
+	}
+
+	m := rotationMatrix(mediaBox, rot)
+
+	bb, err := ctx.PageContent(d)
+	if err != nil && err != model.ErrNoContent {
+		return 0, err
+	}
+
+	if err == nil {
+		bb = append([]byte(fmt.Sprintf("q %.5f %.5f %.5f %.5f %.5f %.5f cm\n", m[0][0], m[0][1], m[1][0], m[1][1], m[2][0], m[2][1])), bb...)
+		bb = append(bb, []byte("\nQ")...)
+
+		sd, _ := ctx.NewStreamDictForBuf(bb)
+		if err := sd.Encode(); err != nil {
+			return 0, err
+		}
+
+		ir, err := ctx.IndRefForNewObject(*sd)
+		if err != nil {
+			return 0, err
+		}
+
+		d.Update("Contents", *ir)
+	}
+
+	d.Update("MediaBox", transformRect(m, mediaBox).Array())
+
+	if inhPAttrs.CropBox != nil {
+		d.Update("CropBox", transformRect(m, inhPAttrs.CropBox).Array())
+	}
+
+	for _, k := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		if err := transformBoxEntry(ctx.XRefTable, d, k, m); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := rotateAnnotations(ctx.XRefTable, d, m, rot, visited); err != nil {
+		return 0, err
+	}
+
+	d.Update("Rotate", types.Integer(0))
+
+	return rot, nil
+}
+
+// NormalizeRotation bakes the rotation of selected pages into their content
+// resulting in pages with rotation 0 and media boxes reflecting the visual page orientation.
+// Annotation rectangles and appearances get rotated along.
+// The result is a list of the pages processed.
+func NormalizeRotation(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	var ss []string
+
+	visited := map[int]bool{}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		if !selectedPages[i] {
+			continue
+		}
+		rot, err := normalizePageRotation(ctx, i, visited)
+		if err != nil {
+			return nil, err
+		}
+		if rot != 0 {
+			ss = append(ss, fmt.Sprintf("page %d: removed rotation %d", i, rot))
+		}
+	}
+
+	return ss, nil
+}
+
+// pageRotation describes the /Rotate in effect for a page.
+type pageRotation struct {
+	pageNr int
+	d      types.Dict
+	rot    int  // effective rotation
+	objNr  int  // page tree node defining the rotation, 0 if none
+	own    bool // defined by the page itself
+	valid  bool // a multiple of 90
+}
+
+// nodeRotation is an intermediate page tree node defining /Rotate.
+type nodeRotation struct {
+	d   types.Dict
+	rot int
+}
+
+type pageRotationCollector struct {
+	xRefTable *model.XRefTable
+	rr        []pageRotation
+	nodes     map[int]nodeRotation // by object number
+	visited   map[int]bool
+}
+
+// rotateEntry returns the value of /Rotate of d.
+func (c *pageRotationCollector) rotateEntry(d types.Dict) (int, bool, error) {
+	o, found := d.Find("Rotate")
+	if !found {
+		return 0, false, nil
+	}
+	o, err := c.xRefTable.Dereference(o)
+	if err != nil || o == nil {
+		return 0, false, err
+	}
+	f, ok := number(o)
+	if !ok {
+		return 0, false, errors.Errorf("pdfcpu: invalid /Rotate: %v", o)
+	}
+	return int(f), true, nil
+}
+
+func (c *pageRotationCollector) collect(ir types.IndirectRef, rot, objNr int) error {
+	nr := ir.ObjectNumber.Value()
+	if c.visited[nr] {
+		return nil
+	}
+	c.visited[nr] = true
+
+	d, err := c.xRefTable.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return err
+	}
+
+	r, found, err := c.rotateEntry(d)
+	if err != nil {
+		return err
+	}
+
+	kids := d.ArrayEntry("Kids")
+
+	if t := d.Type(); (t != nil && *t == "Page") || kids == nil {
+		pr := pageRotation{pageNr: len(c.rr) + 1, d: d, objNr: objNr, rot: rot}
+		if found {
+			pr.objNr, pr.own, pr.rot = nr, true, r
+		}
+		pr.valid = pr.rot%90 == 0
+		c.rr = append(c.rr, pr)
+		return nil
+	}
+
+	if found {
+		rot, objNr = r, nr
+		c.nodes[nr] = nodeRotation{d: d, rot: r}
+	}
+
+	for _, o := range kids {
+		if ir, ok := o.(types.IndirectRef); ok {
+			if err := c.collect(ir, rot, objNr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// pageRotations returns the rotation in effect for each page in page order
+// and all intermediate page tree nodes defining /Rotate.
+func pageRotations(xRefTable *model.XRefTable) ([]pageRotation, map[int]nodeRotation, error) {
+	root, err := xRefTable.Pages()
+	if err != nil {
+		return nil, nil, err
+	}
+	if root == nil {
+		return nil, nil, errors.New("pdfcpu: missing page tree root")
+	}
+
+	c := &pageRotationCollector{xRefTable: xRefTable, nodes: map[int]nodeRotation{}, visited: map[int]bool{}}
+	if err := c.collect(*root, 0, 0); err != nil {
+		return nil, nil, err
+	}
+
+	return c.rr, c.nodes, nil
+}
+
+// effectiveRotation returns rot as one of 0, 90, 180, 270.
+// Rotations not a multiple of 90 are ignored by viewers and therefore result in 0.
+func effectiveRotation(rot int) int {
+	if rot%90 != 0 {
+		return 0
+	}
+	return (rot%360 + 360) % 360
+}
+
+// PageRotations returns a list of the rotation in effect for each page resolving /Rotate inheritance.
+// Intermediate page tree nodes defining /Rotate are listed as well.
+func PageRotations(ctx *model.Context) ([]string, error) {
+	rr, nodes, err := pageRotations(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, pr := range rr {
+		s := fmt.Sprintf("page %d: %d", pr.pageNr, effectiveRotation(pr.rot))
+		switch {
+		case !pr.valid:
+			s += fmt.Sprintf(" (invalid /Rotate %d)", pr.rot)
+		case pr.own && pr.rot != effectiveRotation(pr.rot):
+			s += fmt.Sprintf(" (/Rotate %d)", pr.rot)
+		}
+		if pr.objNr > 0 && !pr.own {
+			s += fmt.Sprintf(" inherited from obj#%d", pr.objNr)
+		}
+		ss = append(ss, s)
+	}
+
+	objNrs := make([]int, 0, len(nodes))
+	for objNr := range nodes {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		ss = append(ss, fmt.Sprintf("obj#%d: page tree node defines /Rotate %d", objNr, nodes[objNr].rot))
+	}
+
+	return ss, nil
+}
+
+// NormalizeRotateInheritance sets /Rotate for each page to the rotation in effect
+// and removes /Rotate from all intermediate page tree nodes.
+// Values are normalized to one of 0, 90, 180, 270.
+// The result is a list of all pages and nodes modified.
+func NormalizeRotateInheritance(ctx *model.Context) ([]string, error) {
+	rr, nodes, err := pageRotations(ctx.XRefTable)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, pr := range rr {
+		rot := effectiveRotation(pr.rot)
+		if pr.own && pr.rot == rot {
+			continue
+		}
+		pr.d.Update("Rotate", types.Integer(rot))
+		switch {
+		case pr.own:
+			ss = append(ss, fmt.Sprintf("page %d: set /Rotate %d (was %d)", pr.pageNr, rot, pr.rot))
+		case pr.objNr > 0:
+			ss = append(ss, fmt.Sprintf("page %d: set /Rotate %d inherited from obj#%d", pr.pageNr, rot, pr.objNr))
+		default:
+			ss = append(ss, fmt.Sprintf("page %d: set /Rotate %d", pr.pageNr, rot))
+		}
+	}
+
+	objNrs := make([]int, 0, len(nodes))
+	for objNr := range nodes {
+		objNrs = append(objNrs, objNr)
+	}
+	sort.Ints(objNrs)
+
+	for _, objNr := range objNrs {
+		nodes[objNr].d.Delete("Rotate")
+		ss = append(ss, fmt.Sprintf("obj#%d: removed /Rotate %d", objNr, nodes[objNr].rot))
+	}
+
+	return ss, nil
+}
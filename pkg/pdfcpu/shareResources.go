@@ -0,0 +1,241 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// sharedResourceCategories are the resource categories getting deduplicated across pages.
+var sharedResourceCategories = []string{"ExtGState", "Font", "ColorSpace"}
+
+// sharedResource is the first occurrence of a resource, direct resources become indirect once shared.
+type sharedResource struct {
+	o     types.Object
+	ir    *types.IndirectRef
+	owner types.Dict
+	name  string
+}
+
+type resourceSharing struct {
+	ctx     *model.Context
+	shared  map[string][]*sharedResource
+	visited map[int]bool
+	ss      []string
+}
+
+func (rs *resourceSharing) logf(format string, args ...interface{}) {
+	rs.ss = append(rs.ss, fmt.Sprintf(format, args...))
+}
+
+// seen returns true if o is a reference to a dict already processed.
+func (rs *resourceSharing) seen(o types.Object) bool {
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		return false
+	}
+	objNr := ir.ObjectNumber.Value()
+	if rs.visited[objNr] {
+		return true
+	}
+	rs.visited[objNr] = true
+	return false
+}
+
+// share replaces the resource catDict[name] by a reference to an equal resource seen before.
+func (rs *resourceSharing) share(cat string, catDict types.Dict, name string, objNr int) error {
+	o := catDict[name]
+
+	if ir, ok := o.(types.IndirectRef); ok {
+		for _, sr := range rs.shared[cat] {
+			if sr.ir != nil && *sr.ir == ir {
+				return nil
+			}
+		}
+	}
+
+	for _, sr := range rs.shared[cat] {
+		ok, err := model.EqualObjects(o, sr.o, rs.ctx.XRefTable)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if sr.ir == nil {
+			if sr.ir, err = rs.ctx.IndRefForNewObject(sr.o); err != nil {
+				return err
+			}
+			sr.owner[sr.name] = *sr.ir
+		}
+		catDict[name] = *sr.ir
+		rs.logf("obj#%d: /%s/%s shares obj#%d", objNr, cat, name, sr.ir.ObjectNumber.Value())
+		return nil
+	}
+
+	sr := &sharedResource{o: o, owner: catDict, name: name}
+	if ir, ok := o.(types.IndirectRef); ok {
+		sr.ir = &ir
+	}
+	rs.shared[cat] = append(rs.shared[cat], sr)
+
+	return nil
+}
+
+// sharePageResources deduplicates the resources of the page d against resources of all pages seen before.
+func (rs *resourceSharing) sharePageResources(d types.Dict, objNr int) error {
+	if rs.seen(d["Resources"]) {
+		return nil
+	}
+
+	resDict, err := rs.ctx.DereferenceDict(d["Resources"])
+	if err != nil || resDict == nil {
+		return err
+	}
+
+	for _, cat := range sharedResourceCategories {
+		if rs.seen(resDict[cat]) {
+			continue
+		}
+		catDict, err := rs.ctx.DereferenceDict(resDict[cat])
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(catDict))
+		for name := range catDict {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := rs.share(cat, catDict, name, objNr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// promote moves the /Resources of all kids of the page tree node d to d if they are all equal.
+// Since /Resources gets inherited as a whole, there is nothing to promote if a single kid deviates.
+func (rs *resourceSharing) promote(d types.Dict, objNr int, kids []types.Dict) error {
+	if len(kids) < 2 {
+		return nil
+	}
+
+	res, found := kids[0].Find("Resources")
+	if !found {
+		return nil
+	}
+
+	for _, kid := range kids[1:] {
+		o, found := kid.Find("Resources")
+		if !found {
+			return nil
+		}
+		ok, err := model.EqualObjects(res, o, rs.ctx.XRefTable)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	d["Resources"] = res
+	for _, kid := range kids {
+		kid.Delete("Resources")
+	}
+
+	rs.logf("obj#%d: promoted /Resources of %d kids", objNr, len(kids))
+
+	return nil
+}
+
+func (rs *resourceSharing) sharePagesDict(d types.Dict, objNr int) error {
+	var kids []types.Dict
+
+	for _, o := range d.ArrayEntry("Kids") {
+
+		kidIndRef, ok := o.(types.IndirectRef)
+		if !ok {
+			continue
+		}
+
+		kidObjNr := kidIndRef.ObjectNumber.Value()
+		if rs.visited[kidObjNr] {
+			return errors.Errorf("pdfcpu: ShareResources: page tree: obj#%d referenced more than once", kidObjNr)
+		}
+		rs.visited[kidObjNr] = true
+
+		kid, err := rs.ctx.DereferenceDict(kidIndRef)
+		if err != nil {
+			return err
+		}
+		if kid == nil {
+			continue
+		}
+
+		if t := kid.Type(); t != nil && *t == "Pages" {
+			if err := rs.sharePagesDict(kid, kidObjNr); err != nil {
+				return err
+			}
+		} else if err := rs.sharePageResources(kid, kidObjNr); err != nil {
+			return err
+		}
+
+		kids = append(kids, kid)
+	}
+
+	return rs.promote(d, objNr, kids)
+}
+
+// ShareResources reduces the number of resource objects of documents repeating the same resources on every page.
+// Equal extended graphics states, fonts and color spaces of different pages get replaced by a single shared object.
+// Then page tree nodes whose kids all use equal resource dictionaries take over a single copy of it to be inherited by the kids.
+// The result is a list of all modifications made.
+func ShareResources(ctx *model.Context) ([]string, error) {
+	root, err := ctx.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := ctx.DereferenceDict(*root)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.New("pdfcpu: ShareResources: missing page tree root")
+	}
+
+	rs := &resourceSharing{
+		ctx:     ctx,
+		shared:  map[string][]*sharedResource{},
+		visited: map[int]bool{root.ObjectNumber.Value(): true},
+	}
+
+	if err := rs.sharePagesDict(d, root.ObjectNumber.Value()); err != nil {
+		return nil, err
+	}
+
+	return rs.ss, nil
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+func ensureAcroForm(ctx *model.Context) (types.Dict, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("AcroForm")
+	if !found {
+		d := types.Dict(map[string]types.Object{"Fields": types.Array{}})
+		ir, err := ctx.IndRefForNewObject(d)
+		if err != nil {
+			return nil, err
+		}
+		rootDict.Insert("AcroForm", *ir)
+		ctx.AcroForm = d
+		return d, nil
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return nil, errors.New("pdfcpu: corrupt AcroForm dict")
+	}
+	ctx.AcroForm = d
+
+	return d, nil
+}
+
+// fieldNameTaken returns true if fieldName is the fully qualified name of any field of the field tree made up by fields.
+func fieldNameTaken(ctx *model.Context, fields types.Array, path, fieldName string, visited map[int]bool) (bool, error) {
+	for _, o := range fields {
+		if ir, ok := o.(types.IndirectRef); ok {
+			if visited[ir.ObjectNumber.Value()] {
+				continue
+			}
+			visited[ir.ObjectNumber.Value()] = true
+		}
+		d, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return false, err
+		}
+		if d == nil {
+			continue
+		}
+		o, found := d.Find("T")
+		if !found {
+			// Widget
+			continue
+		}
+		s, err := ctx.DereferenceStringOrHexLiteral(o, model.V10, nil)
+		if err != nil {
+			return false, err
+		}
+		if path != "" {
+			s = path + "." + s
+		}
+		if s == fieldName {
+			return true, nil
+		}
+		kids, err := ctx.DereferenceArray(d["Kids"])
+		if err != nil {
+			return false, err
+		}
+		if taken, err := fieldNameTaken(ctx, kids, s, fieldName, visited); err != nil || taken {
+			return taken, err
+		}
+	}
+	return false, nil
+}
+
+func ensureUniqueFieldName(ctx *model.Context, acroForm types.Dict, fieldName string) error {
+	fields, err := ctx.DereferenceArray(acroForm["Fields"])
+	if err != nil {
+		return err
+	}
+
+	taken, err := fieldNameTaken(ctx, fields, "", fieldName, map[int]bool{})
+	if err != nil {
+		return err
+	}
+	if taken {
+		return errors.Errorf("pdfcpu: duplicate field name: %s", fieldName)
+	}
+
+	return nil
+}
+
+func addFieldToAcroForm(ctx *model.Context, acroForm types.Dict, indRef types.IndirectRef) error {
+	o, found := acroForm.Find("Fields")
+	if !found {
+		acroForm.Insert("Fields", types.Array{indRef})
+		return nil
+	}
+
+	fields, err := ctx.DereferenceArray(o)
+	if err != nil {
+		return err
+	}
+
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		acroForm.Update("Fields", append(fields, indRef))
+		return nil
+	}
+
+	entry, ok := ctx.FindTableEntryForIndRef(&ir)
+	if !ok {
+		return errors.Errorf("pdfcpu: can't dereference Fields indirect reference(obj#:%d)", ir.ObjectNumber)
+	}
+	entry.Object = append(fields, indRef)
+
+	return nil
+}
+
+// AddSignatureField adds an empty signature field along with its widget annotation to page pageNr.
+// The actual signing is left to external tools which fill in the signature value /V.
+func AddSignatureField(ctx *model.Context, pageNr int, sfa model.SignatureFieldAnnotation) error {
+	if sfa.FieldName == "" {
+		return errors.New("pdfcpu: missing signature field name")
+	}
+
+	if pageNr < 1 || pageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid page number: %d", pageNr)
+	}
+
+	pageDict, pageDictIndRef, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return err
+	}
+	if pageDict == nil {
+		return errors.Errorf("pdfcpu: unable to access page %d", pageNr)
+	}
+
+	acroForm, err := ensureAcroForm(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureUniqueFieldName(ctx, acroForm, sfa.FieldName); err != nil {
+		return err
+	}
+
+	// Create xreftable entry for the merged field/widget dict.
+	annotIndRef, err := createAnnot(ctx, sfa, pageDictIndRef)
+	if err != nil {
+		return err
+	}
+
+	if err := addFieldToAcroForm(ctx, acroForm, *annotIndRef); err != nil {
+		return err
+	}
+
+	_, err = addAnnotationToPage(ctx, annotIndRef, pageDictIndRef, pageDict, pageNr, sfa, false)
+	return err
+}
@@ -62,6 +62,7 @@ func textDescriptor(wm model.Watermark, timestampFormat string, pageNr, pageCoun
 		StrokeCol:      wm.StrokeColor,
 		FillCol:        wm.FillColor,
 		ShowBackground: true,
+		LineSpacing:    wm.LineSpacing,
 	}
 	if wm.BgColor != nil {
 		td.ShowTextBB = true
@@ -102,6 +103,7 @@ var wmParamMap = watermarkParamMap{
 	"diagonal":        parseDiagonal,
 	"fillcolor":       parseFillColor,
 	"fontname":        parseFontName,
+	"linespacing":     parseLineSpacing,
 	"margins":         parseMargins,
 	"mode":            parseRenderMode,
 	"offset":          parsePositionOffsetWM,
@@ -112,7 +114,9 @@ var wmParamMap = watermarkParamMap{
 	"rtl":             parseRightToLeft,
 	"rotation":        parseRotation,
 	"scalefactor":     parseScaleFactorWM,
+	"shrinktofit":     parseShrinkToFit,
 	"strokecolor":     parseStrokeColor,
+	"textbox":         parseTextBox,
 	"url":             parseURL,
 }
 
@@ -258,6 +262,58 @@ func parseRightToLeft(s string, wm *model.Watermark) error {
 	return nil
 }
 
+func parseTextBox(s string, wm *model.Watermark) error {
+	d := strings.Split(s, " ")
+	if len(d) != 2 {
+		return errors.Errorf("pdfcpu: illegal text box string: need 2 numeric values, %s\n", s)
+	}
+
+	w, err := strconv.ParseFloat(d[0], 64)
+	if err != nil {
+		return err
+	}
+
+	h, err := strconv.ParseFloat(d[1], 64)
+	if err != nil {
+		return err
+	}
+
+	if w <= 0 || h <= 0 {
+		return errors.Errorf("pdfcpu: illegal text box dimensions: %s\n", s)
+	}
+
+	wm.BoxWidth = types.ToUserSpace(w, wm.InpUnit)
+	wm.BoxHeight = types.ToUserSpace(h, wm.InpUnit)
+
+	return nil
+}
+
+func parseShrinkToFit(s string, wm *model.Watermark) error {
+	switch strings.ToLower(s) {
+	case "on", "true", "t":
+		wm.ShrinkToFit = true
+	case "off", "false", "f":
+		wm.ShrinkToFit = false
+	default:
+		return errors.New("pdfcpu: shrinktofit, please provide one of: on/off true/false t/f")
+	}
+
+	return nil
+}
+
+func parseLineSpacing(s string, wm *model.Watermark) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.Errorf("pdfcpu: line spacing must be a float value: %s\n", s)
+	}
+	if f <= 0 {
+		return errors.Errorf("pdfcpu: illegal line spacing: 0.0 < f, %s\n", s)
+	}
+	wm.LineSpacing = f
+
+	return nil
+}
+
 func parseStrokeColor(s string, wm *model.Watermark) error {
 	c, err := color.ParseColor(s)
 	if err != nil {
@@ -924,6 +980,31 @@ func setupTextDescriptor(wm model.Watermark, timestampFormat string, pageNr, pag
 	return td, unique
 }
 
+// setupTextBox word-wraps the text of td to fit the text box of wm.
+// The font size in points is shrunk as needed for the text to fit the text box height if requested.
+func setupTextBox(td *model.TextDescriptor, wm model.Watermark) {
+	w := wm.BoxWidth - td.MLeft - td.MRight - 2*td.BorderWidth
+	h := wm.BoxHeight - td.MTop - td.MBot - 2*td.BorderWidth
+
+	fontSize := wm.FontSize
+	lines := model.WrapText(td.Text, td.FontName, fontSize, w)
+
+	if wm.ShrinkToFit {
+		for fontSize > 1 && model.LinesHeight(len(lines), td.FontName, fontSize, wm.LineSpacing) > h {
+			fontSize--
+			lines = model.WrapText(td.Text, td.FontName, fontSize, w)
+		}
+	}
+
+	// Justified text gets wrapped during rendering.
+	if td.HAlign != types.AlignJustify {
+		td.Text = strings.Join(lines, "\n")
+	}
+
+	td.FontSize, td.Scale, td.ScaleAbs = fontSize, 1, true
+	td.MinHeight = wm.BoxHeight
+}
+
 func drawBoundingBox(b *bytes.Buffer, wm model.Watermark, bb *types.Rectangle) {
 	urx := bb.UR.X
 	ury := bb.UR.Y
@@ -951,7 +1032,12 @@ func calcFormBoundingBox(xRefTable *model.XRefTable, w io.Writer, timestampForma
 		var td model.TextDescriptor
 		td, unique = setupTextDescriptor(*wm, timestampFormat, pageNr, pageCount)
 		// Render td into b and return the bounding box.
-		wm.Bb = model.WriteMultiLine(xRefTable, w, types.RectForDim(wm.Vp.Width(), wm.Vp.Height()), nil, td)
+		if wm.IsTextBox() {
+			setupTextBox(&td, *wm)
+			wm.Bb = model.WriteColumn(xRefTable, w, types.RectForDim(wm.Vp.Width(), wm.Vp.Height()), nil, td, wm.BoxWidth)
+		} else {
+			wm.Bb = model.WriteMultiLine(xRefTable, w, types.RectForDim(wm.Vp.Width(), wm.Vp.Height()), nil, td)
+		}
 	}
 	return unique
 }
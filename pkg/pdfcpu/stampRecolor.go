@@ -0,0 +1,262 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+type wmRecolor struct {
+	ctx     *model.Context
+	gray    float64
+	opacity float64
+	visited map[int]bool
+}
+
+func watermarkArtifact(op model.ContentOp) bool {
+	if op.Operator != "BDC" || len(op.Operands) != 2 {
+		return false
+	}
+	if n, ok := op.Operands[0].(types.Name); !ok || n != "Artifact" {
+		return false
+	}
+	d, ok := op.Operands[1].(types.Dict)
+	if !ok {
+		return false
+	}
+	st := d.NameEntry("Subtype")
+	return st != nil && *st == "Watermark"
+}
+
+// watermarkResources returns the names of the extGStates and forms used by watermark artifacts of a content stream.
+func watermarkResources(bb []byte) (extGStates, forms []string) {
+	// Use whatever could be parsed.
+	ops, _ := model.ParseContentOps(bb)
+
+	depth := 0 // marked content nesting level within a watermark artifact
+
+	for _, op := range ops {
+		if depth == 0 {
+			if watermarkArtifact(op) {
+				depth = 1
+			}
+			continue
+		}
+		switch op.Operator {
+		case "BMC", "BDC":
+			depth++
+		case "EMC":
+			depth--
+		case "gs", "Do":
+			if len(op.Operands) != 1 {
+				continue
+			}
+			n, ok := op.Operands[0].(types.Name)
+			if !ok {
+				continue
+			}
+			if op.Operator == "gs" {
+				extGStates = append(extGStates, n.Value())
+			} else {
+				forms = append(forms, n.Value())
+			}
+		}
+	}
+
+	return extGStates, forms
+}
+
+// grayContentOps replaces all color operators of ops by DeviceGray ones for gray level g.
+func grayContentOps(ops []model.ContentOp, g float64) []model.ContentOp {
+	ops1 := make([]model.ContentOp, 0, len(ops))
+	for _, op := range ops {
+		switch op.Operator {
+		case "cs", "CS":
+			// Implied by g and G.
+			continue
+		case "g", "rg", "k", "sc", "scn":
+			op = model.ContentOp{Operator: "g", Operands: []types.Object{types.Float(g)}}
+		case "G", "RG", "K", "SC", "SCN":
+			op = model.ContentOp{Operator: "G", Operands: []types.Object{types.Float(g)}}
+		}
+		ops1 = append(ops1, op)
+	}
+	return ops1
+}
+
+func (wr *wmRecolor) recolorExtGState(resDict types.Dict, name string) error {
+	ir, err := resourceIndRef(wr.ctx.XRefTable, resDict, "ExtGState", name)
+	if err != nil || ir == nil {
+		return err
+	}
+	if wr.visited[ir.ObjectNumber.Value()] {
+		return nil
+	}
+	wr.visited[ir.ObjectNumber.Value()] = true
+
+	d, err := wr.ctx.DereferenceDict(*ir)
+	if err != nil || d == nil {
+		return err
+	}
+
+	d.Update("CA", types.Float(wr.opacity))
+	d.Update("ca", types.Float(wr.opacity))
+
+	return nil
+}
+
+func (wr *wmRecolor) recolorForm(resDict types.Dict, name string) error {
+	ir, err := resourceIndRef(wr.ctx.XRefTable, resDict, "XObject", name)
+	if err != nil || ir == nil {
+		return err
+	}
+	objNr := ir.ObjectNumber.Value()
+	if wr.visited[objNr] {
+		return nil
+	}
+	wr.visited[objNr] = true
+
+	sd, _, err := wr.ctx.DereferenceStreamDict(*ir)
+	if err != nil || sd == nil {
+		return err
+	}
+	if st := sd.Subtype(); st == nil || *st != "Form" {
+		// Image watermarks only get their opacity adjusted.
+		return nil
+	}
+
+	if err := sd.Decode(); err != nil {
+		return err
+	}
+
+	ops, err := model.ParseContentOps(sd.Content)
+	if err != nil {
+		log.Info.Printf("obj#%d: unable to parse watermark content: %v\n", objNr, err)
+		return nil
+	}
+
+	sd.Content = model.ContentOpsBytes(grayContentOps(ops, wr.gray))
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+	wr.ctx.Table[objNr].Object = *sd
+
+	// Recolor nested forms like the page content of PDF watermarks.
+	d, err := wr.ctx.DereferenceDict(sd.Dict["Resources"])
+	if err != nil || d == nil {
+		return err
+	}
+	xd, err := wr.ctx.DereferenceDict(d["XObject"])
+	if err != nil || xd == nil {
+		return err
+	}
+	for k := range xd {
+		if err := wr.recolorForm(d, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (wr *wmRecolor) recolorPage(pageNr int) (bool, error) {
+	d, _, inhPAttrs, err := wr.ctx.PageDict(pageNr, false)
+	if err != nil {
+		return false, err
+	}
+	if d == nil {
+		return false, errors.Errorf("pdfcpu: page %d: missing page dict", pageNr)
+	}
+
+	bb, err := wr.ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	extGStates, forms := watermarkResources(bb)
+	if len(extGStates) == 0 && len(forms) == 0 {
+		return false, nil
+	}
+
+	var resDict types.Dict
+	if inhPAttrs != nil {
+		resDict = inhPAttrs.Resources
+	}
+	if o, found := d.Find("Resources"); found {
+		if resDict, err = wr.ctx.DereferenceDict(o); err != nil {
+			return false, err
+		}
+	}
+
+	for _, id := range extGStates {
+		if err := wr.recolorExtGState(resDict, id); err != nil {
+			return false, err
+		}
+	}
+
+	for _, id := range forms {
+		if err := wr.recolorForm(resDict, id); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// RecolorWatermarks renders all watermarks and stamps of selected pages using gray level gray and opacity opacity.
+func RecolorWatermarks(ctx *model.Context, selectedPages types.IntSet, gray, opacity float64) error {
+	log.Debug.Printf("RecolorWatermarks\n")
+
+	if gray < 0 || gray > 1 {
+		return errors.Errorf("pdfcpu: gray level must be between 0 and 1: %.2f", gray)
+	}
+
+	if opacity < 0 || opacity > 1 {
+		return errors.Errorf("pdfcpu: opacity must be between 0 and 1: %.2f", opacity)
+	}
+
+	wr := &wmRecolor{ctx: ctx, gray: gray, opacity: opacity, visited: map[int]bool{}}
+
+	var recolored bool
+
+	for k, v := range selectedPages {
+		if !v {
+			continue
+		}
+
+		ok, err := wr.recolorPage(k)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			recolored = true
+		}
+	}
+
+	if !recolored {
+		return errNoWatermark
+	}
+
+	return nil
+}
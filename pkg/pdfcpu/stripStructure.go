@@ -0,0 +1,274 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// ErrNotTagged indicates a document without structure tree.
+var ErrNotTagged = errors.New("pdfcpu: document is not tagged")
+
+// structureReference returns true if the marked content sequence started by op references a struct element.
+func structureReference(xRefTable *model.XRefTable, op model.ContentOp, props types.Dict) bool {
+	if op.Operator != "BDC" || len(op.Operands) != 2 {
+		return false
+	}
+
+	d, ok := op.Operands[1].(types.Dict)
+	if !ok {
+		// Named property list
+		n, ok := op.Operands[1].(types.Name)
+		if !ok || props == nil {
+			return false
+		}
+		if d, _ = xRefTable.DereferenceDict(props[n.Value()]); d == nil {
+			return false
+		}
+	}
+
+	_, found := d.Find("MCID")
+	return found
+}
+
+// stripMarkedContent removes all marked content sequences referencing struct elements from ops
+// keeping the marked content itself and returns the number of sequences removed.
+func stripMarkedContent(xRefTable *model.XRefTable, ops []model.ContentOp, props types.Dict) ([]model.ContentOp, int) {
+	var (
+		stack []bool
+		n     int
+	)
+
+	ops1 := make([]model.ContentOp, 0, len(ops))
+
+	for _, op := range ops {
+		switch op.Operator {
+
+		case "BMC", "BDC":
+			strip := structureReference(xRefTable, op, props)
+			stack = append(stack, strip)
+			if strip {
+				n++
+				continue
+			}
+
+		case "EMC":
+			if len(stack) > 0 {
+				strip := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if strip {
+					continue
+				}
+			}
+		}
+
+		ops1 = append(ops1, op)
+	}
+
+	return ops1, n
+}
+
+func propertiesDict(xRefTable *model.XRefTable, o types.Object) types.Dict {
+	res, err := xRefTable.DereferenceDict(o)
+	if err != nil || res == nil {
+		return nil
+	}
+	d, _ := xRefTable.DereferenceDict(res["Properties"])
+	return d
+}
+
+// stripContent returns the content bb stripped of struct element references or nil if there is nothing to strip.
+func stripContent(xRefTable *model.XRefTable, bb []byte, props types.Dict) ([]byte, int, error) {
+	if !bytes.Contains(bb, []byte("BDC")) {
+		return nil, 0, nil
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ops, n := stripMarkedContent(xRefTable, ops, props)
+	if n == 0 {
+		return nil, 0, nil
+	}
+
+	return model.ContentOpsBytes(ops), n, nil
+}
+
+func stripPageStructure(ctx *model.Context, pageNr int) (int, int, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil {
+		return 0, 0, err
+	}
+
+	var annots int
+
+	d.Delete("StructParents")
+
+	arr, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, o := range arr {
+		d1, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return 0, 0, err
+		}
+		if _, found := d1.Find("StructParent"); found {
+			d1.Delete("StructParent")
+			annots++
+		}
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err != nil {
+		if err == model.ErrNoContent {
+			return 0, annots, nil
+		}
+		return 0, 0, err
+	}
+
+	props := propertiesDict(ctx.XRefTable, d["Resources"])
+	if props == nil && inhPAttrs != nil {
+		props = propertiesDict(ctx.XRefTable, inhPAttrs.Resources)
+	}
+
+	bb, n, err := stripContent(ctx.XRefTable, bb, props)
+	if err != nil || bb == nil {
+		return 0, annots, err
+	}
+
+	sd, _ := ctx.NewStreamDictForBuf(bb)
+	if err := sd.Encode(); err != nil {
+		return 0, 0, err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	d["Contents"] = *ir
+
+	return n, annots, nil
+}
+
+// stripXObjectStructure removes struct element references from all form XObjects
+// and returns the number of marked content sequences removed.
+func stripXObjectStructure(ctx *model.Context) (int, error) {
+	var n int
+
+	for _, entry := range ctx.Table {
+		if entry.Free || entry.Compressed {
+			continue
+		}
+		sd, ok := entry.Object.(types.StreamDict)
+		if !ok || sd.Subtype() == nil || (*sd.Subtype() != "Form" && *sd.Subtype() != "Image") {
+			continue
+		}
+
+		sd.Delete("StructParent")
+		sd.Delete("StructParents")
+
+		if *sd.Subtype() != "Form" {
+			continue
+		}
+
+		if err := sd.Decode(); err != nil {
+			if err == filter.ErrUnsupportedFilter {
+				continue
+			}
+			return 0, err
+		}
+
+		bb, n1, err := stripContent(ctx.XRefTable, sd.Content, propertiesDict(ctx.XRefTable, sd.Dict["Resources"]))
+		if err != nil {
+			return 0, err
+		}
+		if bb == nil {
+			continue
+		}
+
+		sd.Content = bb
+		if err := sd.Encode(); err != nil {
+			return 0, err
+		}
+		entry.Object = sd
+		n += n1
+	}
+
+	return n, nil
+}
+
+// StripStructure removes the structure tree (/StructTreeRoot) and the mark info (/MarkInfo) from the catalog
+// along with all struct element references (/StructParents, /StructParent and marked content carrying an /MCID)
+// resulting in an untagged document. This is the inverse of AutoTag.
+// The marked content itself remains in place.
+// The result is a list of all removals made.
+func StripStructure(ctx *model.Context) ([]string, error) {
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	_, tagged := root.Find("StructTreeRoot")
+	_, marked := root.Find("MarkInfo")
+	if !tagged && !marked {
+		return nil, ErrNotTagged
+	}
+
+	var ss []string
+
+	if tagged {
+		root.Delete("StructTreeRoot")
+		ss = append(ss, "removed /StructTreeRoot")
+	}
+	if marked {
+		root.Delete("MarkInfo")
+		ss = append(ss, "removed /MarkInfo")
+	}
+
+	for i := 1; i <= ctx.PageCount; i++ {
+		n, annots, err := stripPageStructure(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			ss = append(ss, fmt.Sprintf("page %d: removed %d marked content struct references", i, n))
+		}
+		if annots > 0 {
+			ss = append(ss, fmt.Sprintf("page %d: removed /StructParent from %d annotations", i, annots))
+		}
+	}
+
+	n, err := stripXObjectStructure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		ss = append(ss, fmt.Sprintf("form XObjects: removed %d marked content struct references", n))
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// langTag matches a language identifier as defined by RFC 3066 / BCP 47, eg. "fr", "en-US", "zh-Hant-TW".
+var langTag = regexp.MustCompile(`^[A-Za-z]{1,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// StructElementSelection selects struct elements either by object number
+// or by the region on a page their marked content starts within.
+type StructElementSelection struct {
+	ObjNr  int              // object number of a struct element
+	PageNr int              // page number for Region
+	Region *types.Rectangle // region in user space
+}
+
+func (sel StructElementSelection) validate(ctx *model.Context) error {
+	if sel.ObjNr > 0 {
+		return nil
+	}
+	if sel.Region == nil {
+		return errors.New("pdfcpu: please select a struct element by object number or page region")
+	}
+	if sel.PageNr < 1 || sel.PageNr > ctx.PageCount {
+		return errors.Errorf("pdfcpu: invalid page number: %d", sel.PageNr)
+	}
+	return nil
+}
+
+func setLang(d types.Dict, lang string) {
+	if lang == "" {
+		d.Delete("Lang")
+		return
+	}
+	d["Lang"] = types.StringLiteral(lang)
+}
+
+func langString(lang string) string {
+	if lang == "" {
+		return "removed /Lang"
+	}
+	return "set /Lang " + lang
+}
+
+func setStructElementLang(ctx *model.Context, objNr int, lang string) (string, error) {
+	d, err := ctx.DereferenceDict(*types.NewIndirectRef(objNr, 0))
+	if err != nil {
+		return "", err
+	}
+	if d == nil || d.NameEntry("S") == nil || (d.Type() != nil && *d.Type() != "StructElem") {
+		return "", errors.Errorf("pdfcpu: obj %d is not a struct element", objNr)
+	}
+
+	setLang(d, lang)
+
+	return fmt.Sprintf("obj %d: %s: %s", objNr, *d.NameEntry("S"), langString(lang)), nil
+}
+
+// SetStructLang sets the language of the struct elements selected by sel to lang,
+// so assistive technology gets the pronunciation right for multilingual content, eg. a French quote in an English document.
+// An empty lang removes /Lang which restores the language inherited from the parent element or the document.
+// Selecting by region takes the position of the first marked content of a struct element into account.
+// The result is a list of all struct elements modified.
+func SetStructLang(ctx *model.Context, sel StructElementSelection, lang string) ([]string, error) {
+	if lang != "" && !langTag.MatchString(lang) {
+		return nil, errors.Errorf("pdfcpu: invalid language identifier: %s", lang)
+	}
+
+	if err := sel.validate(ctx); err != nil {
+		return nil, err
+	}
+
+	if sel.ObjNr > 0 {
+		s, err := setStructElementLang(ctx, sel.ObjNr, lang)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+
+	cc, err := structContents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cc == nil {
+		return nil, errors.New("pdfcpu: document is not tagged")
+	}
+
+	m, err := markedContentPositions(ctx, sel.PageNr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []string
+
+	for _, sc := range cc {
+		if sc.pageNr != sel.PageNr {
+			continue
+		}
+		p, ok := m[sc.mcid]
+		if !ok || !sel.Region.Contains(p) {
+			continue
+		}
+		setLang(sc.d, lang)
+		s := fmt.Sprintf("page %d: %s (MCID %d): %s", sc.pageNr, sc.s, sc.mcid, langString(lang))
+		if sc.objNr > 0 {
+			s = fmt.Sprintf("obj %d: %s", sc.objNr, s)
+		}
+		ss = append(ss, s)
+	}
+
+	if len(ss) == 0 {
+		return nil, errors.Errorf("pdfcpu: page %d: no struct element within %s", sel.PageNr, sel.Region.ShortString())
+	}
+
+	return ss, nil
+}
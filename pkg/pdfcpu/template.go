@@ -0,0 +1,550 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"math"
+	"regexp"
+
+	"github.com/ex-preman/pdfcpu/pkg/font"
+	"github.com/ex-preman/pdfcpu/pkg/log"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// templateToken matches placeholders like {{name}}.
+var templateToken = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// templateFont holds glyph widths of a simple font in text space units for font size 1.
+type templateFont struct {
+	widths map[byte]float64
+	dw     float64
+}
+
+func (f *templateFont) width(b byte) float64 {
+	if f == nil {
+		return .5
+	}
+	if w, ok := f.widths[b]; ok {
+		return w
+	}
+	return f.dw
+}
+
+// textState holds the text state parameters affecting glyph displacement.
+type textState struct {
+	font *templateFont
+	size float64 // Tfs
+	tc   float64 // Tc
+	tw   float64 // Tw
+	th   float64 // Tz / 100
+}
+
+// templateGlyph is a single byte code followed by a TJ position adjustment in thousandths of text space units.
+type templateGlyph struct {
+	b    byte
+	kern float64
+}
+
+type templateFiller struct {
+	ctx      *model.Context
+	fontDict types.Dict
+	fonts    map[string]*templateFont
+	values   map[string]string
+}
+
+func (tf *templateFiller) loadFont(name string) *templateFont {
+	if f, ok := tf.fonts[name]; ok {
+		return f
+	}
+
+	var f *templateFont
+	defer func() { tf.fonts[name] = f }()
+
+	d, err := tf.ctx.DereferenceDict(tf.fontDict[name])
+	if err != nil || d == nil {
+		return nil
+	}
+
+	if st := d.Subtype(); st != nil && *st == "Type0" {
+		// Composite fonts use multi byte codes.
+		return nil
+	}
+
+	f = &templateFont{widths: map[byte]float64{}, dw: .5}
+
+	scale := .001
+	if st := d.Subtype(); st != nil && *st == "Type3" {
+		if a, err := tf.ctx.DereferenceArray(d["FontMatrix"]); err == nil && len(a) == 6 {
+			if sx, err := tf.ctx.DereferenceNumber(a[0]); err == nil {
+				scale = sx
+			}
+		}
+	}
+
+	if fd, _ := tf.ctx.DereferenceDict(d["FontDescriptor"]); fd != nil {
+		if mw, err := tf.ctx.DereferenceNumber(fd["MissingWidth"]); err == nil {
+			f.dw = mw * scale
+		}
+	}
+
+	fc, err1 := tf.ctx.DereferenceNumber(d["FirstChar"])
+	a, err2 := tf.ctx.DereferenceArray(d["Widths"])
+	if err1 == nil && err2 == nil && a != nil {
+		for i, o := range a {
+			if w, err := tf.ctx.DereferenceNumber(o); err == nil && int(fc)+i < 256 {
+				f.widths[byte(int(fc)+i)] = w * scale
+			}
+		}
+		return f
+	}
+
+	// Standard 14 fonts may come without widths.
+	if fn := d.NameEntry("BaseFont"); fn != nil && font.IsCoreFont(*fn) {
+		for i := 32; i < 256; i++ {
+			f.widths[byte(i)] = float64(font.CharWidth(*fn, rune(i))) / 1000
+		}
+	}
+
+	return f
+}
+
+// width returns the horizontal displacement of gg in text space units.
+func (ts textState) width(gg []templateGlyph) float64 {
+	var w float64
+	for _, g := range gg {
+		dx := ts.font.width(g.b)*ts.size + ts.tc
+		if g.b == ' ' {
+			dx += ts.tw
+		}
+		w += (dx - g.kern/1000*ts.size) * ts.th
+	}
+	return w
+}
+
+func encodeTemplateValue(s string) []templateGlyph {
+	// Simple fonts use single byte codes, values outside Latin-1 can't be encoded.
+	var gg []templateGlyph
+	for _, r := range s {
+		if r > 255 {
+			r = '?'
+		}
+		gg = append(gg, templateGlyph{b: byte(r)})
+	}
+	return gg
+}
+
+// substitute replaces all tokens in gg with their values.
+// Position adjustments within a token are dropped, the adjustment following a token is kept.
+func (tf *templateFiller) substitute(gg []templateGlyph, lead *float64) ([]templateGlyph, bool) {
+	bb := make([]byte, len(gg))
+	for i, g := range gg {
+		bb[i] = g.b
+	}
+
+	var (
+		res      []templateGlyph
+		replaced bool
+		i        int
+	)
+
+	for _, m := range templateToken.FindAllSubmatchIndex(bb, -1) {
+		v, ok := tf.values[string(bb[m[2]:m[3]])]
+		if !ok {
+			continue
+		}
+		res = append(res, gg[i:m[0]]...)
+		vv := encodeTemplateValue(v)
+		kern := gg[m[1]-1].kern
+		switch {
+		case len(vv) > 0:
+			vv[len(vv)-1].kern = kern
+			res = append(res, vv...)
+		case len(res) > 0:
+			res[len(res)-1].kern += kern
+		default:
+			*lead += kern
+		}
+		i = m[1]
+		replaced = true
+	}
+
+	if !replaced {
+		return gg, false
+	}
+
+	return append(res, gg[i:]...), true
+}
+
+func stringLiteral(gg []templateGlyph) (types.StringLiteral, error) {
+	bb := make([]byte, len(gg))
+	for i, g := range gg {
+		bb[i] = g.b
+	}
+	s, err := types.Escape(string(bb))
+	if err != nil {
+		return "", err
+	}
+	return types.StringLiteral(*s), nil
+}
+
+// fillString substitutes tokens in the string operand o of a text showing operator.
+func (tf *templateFiller) fillString(ts textState, o types.Object) (types.Object, float64, error) {
+	bb, ok := stringBytes(o)
+	if !ok {
+		return o, 0, nil
+	}
+
+	gg := make([]templateGlyph, len(bb))
+	for i, b := range bb {
+		gg[i].b = b
+	}
+
+	var lead float64
+	gg1, ok := tf.substitute(gg, &lead)
+	if !ok {
+		return o, 0, nil
+	}
+
+	sl, err := stringLiteral(gg1)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return sl, ts.width(gg1) - ts.width(gg), nil
+}
+
+// fillArray substitutes tokens in the TJ operand a, tokens may span multiple array elements.
+func (tf *templateFiller) fillArray(ts textState, a types.Array) (types.Array, float64, error) {
+	var (
+		gg   []templateGlyph
+		lead float64
+	)
+
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			for _, b := range bb {
+				gg = append(gg, templateGlyph{b: b})
+			}
+			continue
+		}
+		f, _ := number(o)
+		if len(gg) == 0 {
+			lead += f
+		} else {
+			gg[len(gg)-1].kern += f
+		}
+	}
+
+	lead0 := lead
+	gg1, ok := tf.substitute(gg, &lead)
+	if !ok {
+		return a, 0, nil
+	}
+
+	var a1 types.Array
+	if lead != 0 {
+		a1 = append(a1, templateFloat(lead))
+	}
+
+	start := 0
+	for i, g := range gg1 {
+		if g.kern == 0 && i < len(gg1)-1 {
+			continue
+		}
+		sl, err := stringLiteral(gg1[start : i+1])
+		if err != nil {
+			return nil, 0, err
+		}
+		a1 = append(a1, sl)
+		if g.kern != 0 {
+			a1 = append(a1, templateFloat(g.kern))
+		}
+		start = i + 1
+	}
+
+	delta := ts.width(gg1) - ts.width(gg) - (lead-lead0)/1000*ts.size*ts.th
+
+	return a1, delta, nil
+}
+
+// templateFloat rounds f to 4 decimals.
+func templateFloat(f float64) types.Float {
+	return types.Float(math.Round(f*1e4) / 1e4)
+}
+
+func number(o types.Object) (float64, bool) {
+	switch o := o.(type) {
+	case types.Integer:
+		return float64(o.Value()), true
+	case types.Float:
+		return o.Value(), true
+	}
+	return 0, false
+}
+
+func numbers(oo []types.Object) ([]float64, bool) {
+	ff := make([]float64, len(oo))
+	for i, o := range oo {
+		f, ok := number(o)
+		if !ok {
+			return nil, false
+		}
+		ff[i] = f
+	}
+	return ff, true
+}
+
+// fill substitutes tokens in ops.
+// Text following a replaced token on the same line gets moved by the width difference between token and value.
+func (tf *templateFiller) fill(ops []model.ContentOp) ([]model.ContentOp, error) {
+	var (
+		res   []model.ContentOp
+		stack []textState
+		shift float64   // displacement of the current point caused by substitutions in text space units.
+		tm    []float64 // last text matrix set.
+	)
+
+	ts := textState{th: 1}
+
+	resetLine := func() {
+		if shift != 0 {
+			res = append(res, model.ContentOp{Operator: "Td", Operands: []types.Object{templateFloat(-shift), types.Integer(0)}})
+		}
+		shift = 0
+	}
+
+	for _, op := range ops {
+
+		ff, numeric := numbers(op.Operands)
+
+		switch op.Operator {
+
+		case "q":
+			stack = append(stack, ts)
+
+		case "Q":
+			if len(stack) > 0 {
+				ts = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+
+		case "BT", "ET":
+			shift, tm = 0, nil
+
+		case "Tf":
+			if len(op.Operands) == 2 {
+				if n, ok := op.Operands[0].(types.Name); ok {
+					ts.font = tf.loadFont(n.Value())
+				}
+				if f, ok := number(op.Operands[1]); ok {
+					ts.size = f
+				}
+			}
+
+		case "Tc":
+			if numeric && len(ff) == 1 {
+				ts.tc = ff[0]
+			}
+
+		case "Tw":
+			if numeric && len(ff) == 1 {
+				ts.tw = ff[0]
+			}
+
+		case "Tz":
+			if numeric && len(ff) == 1 {
+				ts.th = ff[0] / 100
+			}
+
+		case "Td", "TD":
+			if shift != 0 && numeric && len(ff) == 2 {
+				if ff[1] == 0 {
+					op.Operands = []types.Object{templateFloat(ff[0] + shift), op.Operands[1]}
+				} else {
+					op.Operands = []types.Object{templateFloat(ff[0] - shift), op.Operands[1]}
+					shift = 0
+				}
+			}
+
+		case "Tm":
+			if numeric && len(ff) == 6 {
+				sameLine := tm != nil && ff[1] == 0 && ff[2] == 0 &&
+					ff[0] == tm[0] && ff[3] == tm[3] && ff[5] == tm[5] && tm[1] == 0 && tm[2] == 0
+				tm = ff
+				if shift != 0 && sameLine {
+					op.Operands = append(types.Array{}, op.Operands...)
+					op.Operands[4] = templateFloat(ff[4] + shift*ff[0])
+				} else {
+					shift = 0
+				}
+			}
+
+		case "T*":
+			resetLine()
+
+		case "Tj", "'", "\"":
+			if len(op.Operands) == 0 {
+				break
+			}
+			if op.Operator != "Tj" {
+				if op.Operator == "\"" && numeric && len(op.Operands) == 3 {
+					ts.tw, ts.tc = ff[0], ff[1]
+				}
+				resetLine()
+			}
+			i := len(op.Operands) - 1
+			o, delta, err := tf.fillString(ts, op.Operands[i])
+			if err != nil {
+				return nil, err
+			}
+			if delta != 0 || o != op.Operands[i] {
+				op.Operands = append(types.Array{}, op.Operands...)
+				op.Operands[i] = o
+			}
+			shift += delta
+
+		case "TJ":
+			if len(op.Operands) != 1 {
+				break
+			}
+			a, ok := op.Operands[0].(types.Array)
+			if !ok {
+				break
+			}
+			a1, delta, err := tf.fillArray(ts, a)
+			if err != nil {
+				return nil, err
+			}
+			op.Operands = []types.Object{a1}
+			shift += delta
+		}
+
+		res = append(res, op)
+	}
+
+	return res, nil
+}
+
+// templateTokens returns the names of all tokens in ops.
+func templateTokens(ops []model.ContentOp) map[string]bool {
+	m := map[string]bool{}
+	for _, op := range ops {
+		if op.Operator != "Tj" && op.Operator != "TJ" && op.Operator != "'" && op.Operator != "\"" {
+			continue
+		}
+		var bb []byte
+		for _, o := range op.Operands {
+			if a, ok := o.(types.Array); ok {
+				for _, o := range a {
+					b, _ := stringBytes(o)
+					bb = append(bb, b...)
+				}
+				continue
+			}
+			b, _ := stringBytes(o)
+			bb = append(bb, b...)
+		}
+		for _, sm := range templateToken.FindAllSubmatch(bb, -1) {
+			m[string(sm[1])] = true
+		}
+	}
+	return m
+}
+
+// FillTemplatePage creates a new PDF Context holding one copy of page pageNr of ctx for each record.
+// Tokens like {{name}} within text showing operators of the page content get replaced by the value of the record key name.
+// Tokens may span multiple TJ array elements but not multiple operators.
+// Values are encoded one byte per character and need to be covered by the glyphs of the font in use.
+// Annotations and form fields of the template page are not carried over.
+func FillTemplatePage(ctx *model.Context, pageNr int, records []map[string]string) (*model.Context, error) {
+	if len(records) == 0 {
+		return nil, errors.New("pdfcpu: missing template records")
+	}
+
+	d, _, _, err := ctx.PageDict(pageNr, false)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, errors.Errorf("pdfcpu: unknown page number: %d\n", pageNr)
+	}
+	d.Delete("Annots")
+
+	pageNrs := make([]int, len(records))
+	for i := range pageNrs {
+		pageNrs[i] = pageNr
+	}
+
+	ctxDest, err := ExtractPages(ctx, pageNrs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rec := range records {
+
+		d, _, inhPAttrs, err := ctxDest.PageDict(i+1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		bb, err := ctxDest.PageContent(d)
+		if err == model.ErrNoContent {
+			return nil, errors.New("pdfcpu: template page without content")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ops, err := model.ParseContentOps(bb)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			for k := range templateTokens(ops) {
+				if _, ok := rec[k]; !ok {
+					log.Info.Printf("pdfcpu: template token without value: %s\n", k)
+				}
+			}
+		}
+
+		tf := &templateFiller{ctx: ctxDest, fonts: map[string]*templateFont{}, values: rec}
+		if inhPAttrs.Resources != nil {
+			tf.fontDict, _ = ctxDest.DereferenceDict(inhPAttrs.Resources["Font"])
+		}
+
+		if ops, err = tf.fill(ops); err != nil {
+			return nil, err
+		}
+
+		sd, _ := ctxDest.NewStreamDictForBuf(model.ContentOpsBytes(ops))
+		if err := sd.Encode(); err != nil {
+			return nil, err
+		}
+
+		ir, err := ctxDest.IndRefForNewObject(*sd)
+		if err != nil {
+			return nil, err
+		}
+
+		d["Contents"] = *ir
+	}
+
+	return ctxDest, nil
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultDocTimeStampSize is the number of bytes reserved for a timestamp token if not specified.
+const DefaultDocTimeStampSize = 8192
+
+// byteRangePlaceholder reserves space for the final /ByteRange values (files up to 10GB).
+const byteRangePlaceholder = 9999999999
+
+// ErrNoPendingDocTimeStamp indicates a missing document timestamp placeholder.
+var ErrNoPendingDocTimeStamp = errors.New("pdfcpu: no document timestamp placeholder found")
+
+// SignatureSlots holds the offsets of the /ByteRange array and the /Contents hex string of a signature dict.
+// Start offsets point to the opening delimiter, end offsets past the closing delimiter.
+type SignatureSlots struct {
+	ByteRangeStart, ByteRangeEnd int64
+	ContentsStart, ContentsEnd   int64
+}
+
+// ByteRange returns the byte range covering all bytes of a file of size fileSize except the /Contents hex string.
+func (ss SignatureSlots) ByteRange(fileSize int64) [4]int64 {
+	return [4]int64{0, ss.ContentsStart, ss.ContentsEnd, fileSize - ss.ContentsEnd}
+}
+
+func slot(bb []byte, key string, open, close byte) (int, int, error) {
+	i := bytes.Index(bb, []byte("/"+key))
+	if i < 0 {
+		return 0, 0, errors.Errorf("pdfcpu: signature dict: missing /%s", key)
+	}
+	j := bytes.IndexByte(bb[i:], open)
+	if j < 0 {
+		return 0, 0, errors.Errorf("pdfcpu: signature dict: corrupt /%s", key)
+	}
+	k := bytes.IndexByte(bb[i+j:], close)
+	if k < 0 {
+		return 0, 0, errors.Errorf("pdfcpu: signature dict: corrupt /%s", key)
+	}
+	return i + j, i + j + k + 1, nil
+}
+
+// LocateSignatureSlots returns the slots of the signature dict object serialized at offset off of bb.
+func LocateSignatureSlots(bb []byte, off int64) (*SignatureSlots, error) {
+	bb = bb[off:]
+	if i := bytes.Index(bb, []byte("endobj")); i > 0 {
+		bb = bb[:i]
+	}
+
+	brStart, brEnd, err := slot(bb, "ByteRange", '[', ']')
+	if err != nil {
+		return nil, err
+	}
+
+	cStart, cEnd, err := slot(bb, "Contents", '<', '>')
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignatureSlots{
+		ByteRangeStart: off + int64(brStart),
+		ByteRangeEnd:   off + int64(brEnd),
+		ContentsStart:  off + int64(cStart),
+		ContentsEnd:    off + int64(cEnd),
+	}, nil
+}
+
+// ByteRangeBytes returns br rendered into a /ByteRange array padded to fit the slot of ss.
+func (ss SignatureSlots) ByteRangeBytes(br [4]int64) ([]byte, error) {
+	s := fmt.Sprintf("[%d %d %d %d", br[0], br[1], br[2], br[3])
+	w := int(ss.ByteRangeEnd - ss.ByteRangeStart)
+	if len(s)+1 > w {
+		return nil, errors.New("pdfcpu: byte range exceeds placeholder")
+	}
+	return []byte(s + strings.Repeat(" ", w-len(s)-1) + "]"), nil
+}
+
+func addWidgetToPage(ctx *model.Context, pageDict types.Dict, pageDictIndRef, annotIndRef types.IndirectRef) error {
+	o, found := pageDict.Find("Annots")
+	if !found {
+		pageDict.Insert("Annots", types.Array{annotIndRef})
+		ctx.Write.IncrementWithObjNr(pageDictIndRef.ObjectNumber.Value())
+		return nil
+	}
+
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		annots, _ := o.(types.Array)
+		pageDict.Update("Annots", append(annots, annotIndRef))
+		ctx.Write.IncrementWithObjNr(pageDictIndRef.ObjectNumber.Value())
+		return nil
+	}
+
+	annots, err := ctx.DereferenceArray(ir)
+	if err != nil {
+		return err
+	}
+	entry, ok := ctx.FindTableEntryForIndRef(&ir)
+	if !ok {
+		return errors.Errorf("pdfcpu: can't dereference Annots indirect reference(obj#:%d)", ir.ObjectNumber)
+	}
+	entry.Object = append(annots, annotIndRef)
+	ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+
+	return nil
+}
+
+func addFieldToAcroFormIncr(ctx *model.Context, fieldIndRef types.IndirectRef) error {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	o, found := rootDict.Find("AcroForm")
+	if !found {
+		d := types.Dict(map[string]types.Object{
+			"Fields":   types.Array{fieldIndRef},
+			"SigFlags": types.Integer(3),
+		})
+		ir, err := ctx.IndRefForNewObject(d)
+		if err != nil {
+			return err
+		}
+		rootDict.Insert("AcroForm", *ir)
+		ctx.AcroForm = d
+		ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+		ctx.Write.IncrementWithObjNr(ctx.Root.ObjectNumber.Value())
+		return nil
+	}
+
+	acroForm, err := ctx.DereferenceDict(o)
+	if err != nil || acroForm == nil {
+		return errors.New("pdfcpu: corrupt AcroForm dict")
+	}
+	ctx.AcroForm = acroForm
+
+	// Signatures exist, append only.
+	acroForm.Update("SigFlags", types.Integer(3))
+
+	if ir, ok := o.(types.IndirectRef); ok {
+		ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+	} else {
+		ctx.Write.IncrementWithObjNr(ctx.Root.ObjectNumber.Value())
+	}
+
+	o, found = acroForm.Find("Fields")
+	if !found {
+		acroForm.Insert("Fields", types.Array{fieldIndRef})
+		return nil
+	}
+
+	fields, err := ctx.DereferenceArray(o)
+	if err != nil {
+		return err
+	}
+
+	ir, ok := o.(types.IndirectRef)
+	if !ok {
+		acroForm.Update("Fields", append(fields, fieldIndRef))
+		return nil
+	}
+
+	entry, ok := ctx.FindTableEntryForIndRef(&ir)
+	if !ok {
+		return errors.Errorf("pdfcpu: can't dereference Fields indirect reference(obj#:%d)", ir.ObjectNumber)
+	}
+	entry.Object = append(fields, fieldIndRef)
+	ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+
+	return nil
+}
+
+func docTimeStampFieldName(ctx *model.Context) (string, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return "", err
+	}
+
+	var fields types.Array
+	if o, found := rootDict.Find("AcroForm"); found {
+		acroForm, err := ctx.DereferenceDict(o)
+		if err != nil {
+			return "", err
+		}
+		if fields, err = ctx.DereferenceArray(acroForm["Fields"]); err != nil {
+			return "", err
+		}
+	}
+
+	for i := 1; ; i++ {
+		fieldName := fmt.Sprintf("DocTimeStamp%d", i)
+		taken, err := fieldNameTaken(ctx, fields, "", fieldName, map[int]bool{})
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return fieldName, nil
+		}
+	}
+}
+
+// AddDocTimeStampField adds an invisible signature field to page 1 whose value is a
+// document timestamp signature dict (/Type /DocTimeStamp) reserving tokenSize bytes for an RFC 3161 timestamp token.
+// All objects touched are marked for writing a PDF increment.
+// The result is the indirect reference of the signature dict.
+func AddDocTimeStampField(ctx *model.Context, tokenSize int) (*types.IndirectRef, error) {
+	if ctx.Encrypt != nil {
+		return nil, errors.New("pdfcpu: document timestamps are not supported for encrypted files")
+	}
+
+	if tokenSize <= 0 {
+		tokenSize = DefaultDocTimeStampSize
+	}
+
+	fieldName, err := docTimeStampFieldName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sigDict := types.Dict(map[string]types.Object{
+		"Type":      types.Name("DocTimeStamp"),
+		"Filter":    types.Name("Adobe.PPKLite"),
+		"SubFilter": types.Name("ETSI.RFC3161"),
+		"ByteRange": types.Array{types.Integer(0), types.Integer(byteRangePlaceholder), types.Integer(byteRangePlaceholder), types.Integer(byteRangePlaceholder)},
+		"Contents":  types.HexLiteral(strings.Repeat("0", 2*tokenSize)),
+	})
+
+	sigIndRef, err := ctx.IndRefForNewObject(sigDict)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Write.IncrementWithObjNr(sigIndRef.ObjectNumber.Value())
+
+	pageDict, pageDictIndRef, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		return nil, err
+	}
+	if pageDict == nil {
+		return nil, errors.New("pdfcpu: unable to access page 1")
+	}
+
+	// Merged invisible field/widget dict.
+	fieldDict := types.Dict(map[string]types.Object{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Widget"),
+		"FT":      types.Name("Sig"),
+		"T":       types.StringLiteral(fieldName),
+		"V":       *sigIndRef,
+		"F":       types.Integer(model.AnnPrint + model.AnnLocked),
+		"Rect":    types.Array{types.Integer(0), types.Integer(0), types.Integer(0), types.Integer(0)},
+		"P":       *pageDictIndRef,
+	})
+
+	fieldIndRef, err := ctx.IndRefForNewObject(fieldDict)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Write.IncrementWithObjNr(fieldIndRef.ObjectNumber.Value())
+
+	if err := addFieldToAcroFormIncr(ctx, *fieldIndRef); err != nil {
+		return nil, err
+	}
+
+	if err := addWidgetToPage(ctx, pageDict, *pageDictIndRef, *fieldIndRef); err != nil {
+		return nil, err
+	}
+
+	return sigIndRef, nil
+}
+
+func pendingDocTimeStamp(d types.Dict) bool {
+	if t := d.Type(); t == nil || *t != "DocTimeStamp" {
+		return false
+	}
+	hl, ok := d["Contents"].(types.HexLiteral)
+	if !ok {
+		return false
+	}
+	return strings.Trim(hl.Value(), "0") == ""
+}
+
+// PendingDocTimeStamp returns the object number of the most recent document timestamp signature dict
+// still waiting for its timestamp token.
+func PendingDocTimeStamp(ctx *model.Context) (int, error) {
+	objNr := 0
+	for k, e := range ctx.Table {
+		if e == nil || e.Free || k <= objNr {
+			continue
+		}
+		d, ok := e.Object.(types.Dict)
+		if ok && pendingDocTimeStamp(d) {
+			objNr = k
+		}
+	}
+	if objNr == 0 {
+		return 0, ErrNoPendingDocTimeStamp
+	}
+	return objNr, nil
+}
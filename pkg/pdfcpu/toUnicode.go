@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"unicode"
+
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+var standardBaseEncodings = []string{"StandardEncoding", "WinAnsiEncoding", "MacRomanEncoding"}
+
+func symbolicFont(xRefTable *model.XRefTable, d types.Dict) (bool, error) {
+	if fn := d.NameEntry("BaseFont"); fn != nil {
+		if s := stripSubsetPrefix(*fn); s == "Symbol" || s == "ZapfDingbats" {
+			return true, nil
+		}
+	}
+	fd, err := xRefTable.DereferenceDict(d["FontDescriptor"])
+	if err != nil || fd == nil {
+		return false, err
+	}
+	f := fd.IntEntry("Flags")
+	return f != nil && *f&0x04 > 0, nil
+}
+
+// fontEncoding returns the base encoding of a simple font if it is a standard Latin encoding.
+func fontEncoding(xRefTable *model.XRefTable, d types.Dict) (string, bool, error) {
+	o, err := xRefTable.Dereference(d["Encoding"])
+	if err != nil {
+		return "", false, err
+	}
+
+	enc := "StandardEncoding"
+
+	switch o := o.(type) {
+	case nil:
+	case types.Name:
+		enc = o.Value()
+	case types.Dict:
+		if be := o.NameEntry("BaseEncoding"); be != nil {
+			enc = *be
+		}
+	default:
+		return "", false, nil
+	}
+
+	return enc, types.MemberOf(enc, standardBaseEncodings), nil
+}
+
+func validUnicodeMapping(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r == 0 || r == unicode.ReplacementChar || (unicode.IsControl(r) && !unicode.IsSpace(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// brokenToUnicode returns true if the ToUnicode CMap of d is unusable or maps char codes with a known Unicode value to garbage.
+func brokenToUnicode(xRefTable *model.XRefTable, d types.Dict, m map[byte]rune) bool {
+	tu, err := pdffont.ToUnicodeMap(xRefTable, d)
+	if err != nil || len(tu) == 0 {
+		return true
+	}
+	for c, r := range m {
+		if !unicode.IsPrint(r) {
+			continue
+		}
+		if s, ok := tu[int(c)]; ok && !validUnicodeMapping(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RepairToUnicode generates a ToUnicode CMap for all simple fonts using a standard Latin encoding
+// whose ToUnicode CMap is missing or broken.
+// The result is a list of the fonts processed.
+func RepairToUnicode(ctx *model.Context) ([]string, error) {
+	var ss []string
+
+	for _, objNr := range fontDicts(ctx) {
+
+		d := ctx.Table[objNr].Object.(types.Dict)
+
+		if !pdffont.SimpleFont(d) {
+			continue
+		}
+		if st := d.Subtype(); st != nil && *st == "Type3" {
+			continue
+		}
+
+		symbolic, err := symbolicFont(ctx.XRefTable, d)
+		if err != nil {
+			return nil, err
+		}
+		if symbolic {
+			continue
+		}
+
+		enc, ok, err := fontEncoding(ctx.XRefTable, d)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		m, err := pdffont.SimpleFontCharMap(ctx.XRefTable, d)
+		if err != nil {
+			return nil, err
+		}
+
+		action := "added"
+		if _, found := d.Find("ToUnicode"); found {
+			if !brokenToUnicode(ctx.XRefTable, d, m) {
+				continue
+			}
+			action = "replaced broken"
+		}
+
+		if err := pdffont.SetSimpleFontToUnicode(ctx.XRefTable, d, m); err != nil {
+			return nil, err
+		}
+
+		fontName := ""
+		if fn := d.NameEntry("BaseFont"); fn != nil {
+			fontName = *fn
+		}
+		ss = append(ss, fmt.Sprintf("obj#%d %s: %s ToUnicode (%s)", objNr, fontName, action, enc))
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,622 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	pdffont "github.com/ex-preman/pdfcpu/pkg/pdfcpu/font"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/matrix"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// type3Font holds everything needed to draw the glyphs of a Type3 font.
+type type3Font struct {
+	objNr      int
+	fontMatrix types.Array
+	a          float64                     // horizontal FontMatrix scale
+	widths     map[int]float64             // glyph widths in glyph space
+	glyphNames map[byte]string             // char code to glyph name
+	charProcs  types.Dict                  // glyph name to glyph procedure
+	bbox       types.Array                 // FontBBox
+	resources  types.Object                // glyph procedure resources
+	forms      map[byte]*types.IndirectRef // glyph forms by char code, nil for missing glyphs
+}
+
+// type3State holds the parts of the graphics state affecting text rendering.
+type type3State struct {
+	font        *type3Font    // nil for non Type3 fonts.
+	widths      *templateFont // widths of simple non Type3 fonts.
+	twoByte     bool          // composite font
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	hScale      float64
+	leading     float64
+	rise        float64
+	renderMode  int
+}
+
+type type3Converter struct {
+	ctx      *model.Context
+	fonts    map[int]*type3Font // Type3 fonts by font dict object number.
+	widths   *templateFiller    // width lookup for non Type3 fonts
+	resDict  types.Dict         // page resources
+	xObjects types.Dict         // page XObject resources
+	gs       type3State
+	stack    []type3State
+	tm       matrix.Matrix
+	tlm      matrix.Matrix
+	split    bool // The current text object got split, text positioning needs to be absolute.
+	reopened int  // number of ops after reopening the text object
+	ops      []model.ContentOp
+	glyphs   int // number of glyphs converted
+}
+
+func (c *type3Converter) loadFont(ir types.IndirectRef) *type3Font {
+	objNr := ir.ObjectNumber.Value()
+	if f, ok := c.fonts[objNr]; ok {
+		return f
+	}
+
+	var f *type3Font
+	defer func() { c.fonts[objNr] = f }()
+
+	d, err := c.ctx.DereferenceDict(ir)
+	if err != nil || d == nil {
+		return nil
+	}
+	if st := d.Subtype(); st == nil || *st != "Type3" {
+		return nil
+	}
+
+	fm, err := c.ctx.DereferenceArray(d["FontMatrix"])
+	if err != nil || len(fm) != 6 {
+		return nil
+	}
+	a, err := c.ctx.DereferenceNumber(fm[0])
+	if err != nil {
+		return nil
+	}
+
+	charProcs, err := c.ctx.DereferenceDict(d["CharProcs"])
+	if err != nil || charProcs == nil {
+		return nil
+	}
+
+	glyphNames, err := pdffont.SimpleFontGlyphNames(c.ctx.XRefTable, d)
+	if err != nil {
+		return nil
+	}
+
+	bbox, _ := c.ctx.DereferenceArray(d["FontBBox"])
+	if !type3BBoxValid(c.ctx, bbox) && a != 0 {
+		// Don't clip glyphs of fonts without a meaningful FontBBox.
+		em := 1 / a
+		if em < 0 {
+			em = -em
+		}
+		bbox = types.NewNumberArray(-em, -em, 2*em, 2*em)
+	}
+
+	f = &type3Font{
+		objNr:      objNr,
+		fontMatrix: fm,
+		a:          a,
+		widths:     map[int]float64{},
+		glyphNames: glyphNames,
+		charProcs:  charProcs,
+		bbox:       bbox,
+		resources:  d["Resources"],
+		forms:      map[byte]*types.IndirectRef{},
+	}
+
+	if fc, err := c.ctx.DereferenceNumber(d["FirstChar"]); err == nil {
+		if ww, err := c.ctx.DereferenceArray(d["Widths"]); err == nil {
+			for i, o := range ww {
+				if w, err := c.ctx.DereferenceNumber(o); err == nil {
+					f.widths[int(fc)+i] = w
+				}
+			}
+		}
+	}
+
+	return f
+}
+
+func type3BBoxValid(ctx *model.Context, a types.Array) bool {
+	if len(a) != 4 {
+		return false
+	}
+	ff := make([]float64, 4)
+	for i, o := range a {
+		f, err := ctx.DereferenceNumber(o)
+		if err != nil {
+			return false
+		}
+		ff[i] = f
+	}
+	return ff[0] != ff[2] && ff[1] != ff[3]
+}
+
+// glyphForm returns a form XObject drawing the glyph procedure for code.
+func (c *type3Converter) glyphForm(f *type3Font, code byte) (*types.IndirectRef, error) {
+	if ir, ok := f.forms[code]; ok {
+		return ir, nil
+	}
+
+	f.forms[code] = nil
+
+	o, found := f.charProcs.Find(f.glyphNames[code])
+	if !found {
+		return nil, nil
+	}
+
+	sd, _, err := c.ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+	if err := sd.Decode(); err != nil {
+		return nil, err
+	}
+
+	ops, err := model.ParseContentOps(sd.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// d0 and d1 are only allowed in glyph procedures.
+	var ops1 []model.ContentOp
+	for _, op := range ops {
+		if op.Operator != "d0" && op.Operator != "d1" {
+			ops1 = append(ops1, op)
+		}
+	}
+
+	form, _ := c.ctx.NewStreamDictForBuf(model.ContentOpsBytes(ops1))
+	form.InsertName("Type", "XObject")
+	form.InsertName("Subtype", "Form")
+	form.Insert("BBox", f.bbox)
+	form.Insert("Matrix", f.fontMatrix)
+	if f.resources != nil {
+		form.Insert("Resources", f.resources)
+	}
+
+	if err := form.Encode(); err != nil {
+		return nil, err
+	}
+
+	ir, err := c.ctx.IndRefForNewObject(*form)
+	if err != nil {
+		return nil, err
+	}
+
+	f.forms[code] = ir
+
+	return ir, nil
+}
+
+// xObjectName returns the name of the glyph form ir within the page XObject resources.
+func (c *type3Converter) xObjectName(f *type3Font, code byte, ir types.IndirectRef) string {
+	if c.xObjects == nil {
+		c.xObjects = types.NewDict()
+		if o, found := c.resDict.Find("XObject"); found {
+			if d, err := c.ctx.DereferenceDict(o); err == nil && d != nil {
+				c.xObjects = d
+			}
+		}
+		c.resDict.Update("XObject", c.xObjects)
+	}
+
+	name := fmt.Sprintf("T3_%d_%d", f.objNr, code)
+	for i := 1; ; i++ {
+		o, found := c.xObjects.Find(name)
+		if !found {
+			c.xObjects.Insert(name, ir)
+			return name
+		}
+		if ir1, ok := o.(types.IndirectRef); ok && ir1.ObjectNumber == ir.ObjectNumber {
+			return name
+		}
+		name = fmt.Sprintf("T3_%d_%d_%d", f.objNr, code, i)
+	}
+}
+
+func matrixOperands(m matrix.Matrix) []types.Object {
+	return []types.Object{
+		templateFloat(m[0][0]), templateFloat(m[0][1]),
+		templateFloat(m[1][0]), templateFloat(m[1][1]),
+		templateFloat(m[2][0]), templateFloat(m[2][1]),
+	}
+}
+
+func (c *type3Converter) emit(operator string, operands ...types.Object) {
+	c.ops = append(c.ops, model.ContentOp{Operator: operator, Operands: operands})
+}
+
+// showText draws the glyphs for bb as form XObjects outside of the current text object.
+func (c *type3Converter) showText(bb []byte) error {
+	f, gs := c.gs.font, c.gs
+
+	c.emit("ET")
+
+	for _, code := range bb {
+
+		if gs.renderMode != 3 && gs.renderMode != 7 && gs.fontSize != 0 {
+			ir, err := c.glyphForm(f, code)
+			if err != nil {
+				return err
+			}
+			if ir != nil {
+				trm := matrix.Matrix{{gs.fontSize * gs.hScale, 0, 0}, {0, gs.fontSize, 0}, {0, gs.rise, 1}}.Multiply(c.tm)
+				c.emit("q")
+				c.emit("cm", matrixOperands(trm)...)
+				c.emit("Do", types.Name(c.xObjectName(f, code, *ir)))
+				c.emit("Q")
+				c.glyphs++
+			}
+		}
+
+		tx := f.widths[int(code)]*f.a*gs.fontSize + gs.charSpacing
+		if code == ' ' {
+			tx += gs.wordSpacing
+		}
+		c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx * gs.hScale, 0, 1}}.Multiply(c.tm)
+	}
+
+	c.emit("BT")
+	c.emit("Tm", matrixOperands(c.tm)...)
+	c.split, c.reopened = true, len(c.ops)
+
+	return nil
+}
+
+func (c *type3Converter) showTextArray(a types.Array) error {
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			if err := c.showText(bb); err != nil {
+				return err
+			}
+			continue
+		}
+		if f, ok := epsNumber(o); ok {
+			tx := -f / 1000 * c.gs.fontSize * c.gs.hScale
+			c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.Multiply(c.tm)
+		}
+	}
+	return nil
+}
+
+// nextLine moves to the start of the next line offset by tx, ty.
+func (c *type3Converter) nextLine(tx, ty float64) {
+	c.tlm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, ty, 1}}.Multiply(c.tlm)
+	c.tm = c.tlm
+	if c.split {
+		// The line matrix got lost when splitting the text object.
+		c.emit("Tm", matrixOperands(c.tlm)...)
+	}
+}
+
+// advance updates the text matrix for text shown using a non Type3 font.
+func (c *type3Converter) advance(bb []byte) {
+	gs := c.gs
+	var tx float64
+	if gs.twoByte {
+		// Best effort: assume the default width for CIDs.
+		for i := 0; i+1 < len(bb); i += 2 {
+			tx += gs.fontSize + gs.charSpacing
+		}
+	} else {
+		for _, b := range bb {
+			tx += gs.widths.width(b)*gs.fontSize + gs.charSpacing
+			if b == ' ' {
+				tx += gs.wordSpacing
+			}
+		}
+	}
+	c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx * gs.hScale, 0, 1}}.Multiply(c.tm)
+}
+
+func (c *type3Converter) advanceArray(a types.Array) {
+	for _, o := range a {
+		if bb, ok := stringBytes(o); ok {
+			c.advance(bb)
+			continue
+		}
+		if f, ok := epsNumber(o); ok {
+			tx := -f / 1000 * c.gs.fontSize * c.gs.hScale
+			c.tm = matrix.Matrix{{1, 0, 0}, {0, 1, 0}, {tx, 0, 1}}.Multiply(c.tm)
+		}
+	}
+}
+
+func (c *type3Converter) textStateOp(op model.ContentOp) {
+	if op.Operator == "Tf" {
+		if len(op.Operands) != 2 {
+			return
+		}
+		n, ok := op.Operands[0].(types.Name)
+		if !ok {
+			return
+		}
+		if fs, ok := epsNumber(op.Operands[1]); ok {
+			c.gs.fontSize = fs
+		}
+		c.gs.font, c.gs.widths, c.gs.twoByte = nil, nil, false
+		ir, _ := resourceIndRef(c.ctx.XRefTable, c.resDict, "Font", n.Value())
+		if ir == nil {
+			return
+		}
+		if c.gs.font = c.loadFont(*ir); c.gs.font != nil {
+			return
+		}
+		if d, err := c.ctx.DereferenceDict(*ir); err == nil && d != nil {
+			if st := d.Subtype(); st != nil && *st == "Type0" {
+				c.gs.twoByte = true
+				return
+			}
+		}
+		c.gs.widths = c.widths.loadFont(n.Value())
+		return
+	}
+
+	ff, ok := numOperands(op, 1)
+	if !ok {
+		return
+	}
+
+	switch op.Operator {
+	case "Tc":
+		c.gs.charSpacing = ff[0]
+	case "Tw":
+		c.gs.wordSpacing = ff[0]
+	case "Tz":
+		c.gs.hScale = ff[0] / 100
+	case "TL":
+		c.gs.leading = ff[0]
+	case "Ts":
+		c.gs.rise = ff[0]
+	case "Tr":
+		c.gs.renderMode = int(ff[0])
+	}
+}
+
+// show processes a Tj operation.
+func (c *type3Converter) show(op model.ContentOp) error {
+	bb, ok := stringBytes(op.Operands[len(op.Operands)-1])
+	if !ok {
+		c.ops = append(c.ops, op)
+		return nil
+	}
+	if c.gs.font != nil {
+		return c.showText(bb)
+	}
+	c.ops = append(c.ops, op)
+	c.advance(bb)
+	return nil
+}
+
+// showNextLine processes ' and " operations.
+func (c *type3Converter) showNextLine(op model.ContentOp) error {
+	if op.Operator == "\"" {
+		ff, ok := numOperands(op, 3)
+		if !ok {
+			c.ops = append(c.ops, op)
+			return nil
+		}
+		c.gs.wordSpacing, c.gs.charSpacing = ff[0], ff[1]
+	}
+
+	if !c.split && c.gs.font == nil {
+		c.ops = append(c.ops, op)
+		c.nextLine(0, -c.gs.leading)
+		bb, _ := stringBytes(op.Operands[len(op.Operands)-1])
+		c.advance(bb)
+		return nil
+	}
+
+	if op.Operator == "\"" {
+		c.emit("Tw", op.Operands[0])
+		c.emit("Tc", op.Operands[1])
+	}
+	if !c.split {
+		c.emit("T*")
+	}
+	c.nextLine(0, -c.gs.leading)
+
+	return c.show(model.ContentOp{Operator: "Tj", Operands: op.Operands[len(op.Operands)-1:]})
+}
+
+func (c *type3Converter) convertOp(op model.ContentOp) error {
+	switch op.Operator {
+
+	case "q":
+		c.stack = append(c.stack, c.gs)
+
+	case "Q":
+		if n := len(c.stack); n > 0 {
+			c.gs, c.stack = c.stack[n-1], c.stack[:n-1]
+		}
+
+	case "BT":
+		c.tm, c.tlm, c.split = matrix.IdentMatrix, matrix.IdentMatrix, false
+
+	case "ET":
+		c.split = false
+		if n := len(c.ops); n > 0 && n == c.reopened {
+			// Drop the empty text object.
+			c.ops = c.ops[:n-2]
+			return nil
+		}
+
+	case "Tc", "Tw", "Tz", "TL", "Ts", "Tr", "Tf":
+		c.textStateOp(op)
+
+	case "Td", "TD":
+		ff, ok := numOperands(op, 2)
+		if !ok {
+			break
+		}
+		if op.Operator == "TD" {
+			c.gs.leading = -ff[1]
+		}
+		if c.split {
+			if op.Operator == "TD" {
+				c.emit("TL", templateFloat(-ff[1]))
+			}
+			c.nextLine(ff[0], ff[1])
+			return nil
+		}
+		c.nextLine(ff[0], ff[1])
+
+	case "Tm":
+		ff, ok := numOperands(op, 6)
+		if !ok {
+			break
+		}
+		c.tlm = matrix.Matrix{{ff[0], ff[1], 0}, {ff[2], ff[3], 0}, {ff[4], ff[5], 1}}
+		c.tm = c.tlm
+
+	case "T*":
+		if c.split {
+			c.nextLine(0, -c.gs.leading)
+			return nil
+		}
+		c.nextLine(0, -c.gs.leading)
+
+	case "Tj":
+		if len(op.Operands) == 1 {
+			return c.show(op)
+		}
+
+	case "'", "\"":
+		if len(op.Operands) > 0 {
+			return c.showNextLine(op)
+		}
+
+	case "TJ":
+		if len(op.Operands) != 1 {
+			break
+		}
+		a, ok := op.Operands[0].(types.Array)
+		if !ok {
+			break
+		}
+		if c.gs.font != nil {
+			return c.showTextArray(a)
+		}
+		c.advanceArray(a)
+	}
+
+	c.ops = append(c.ops, op)
+
+	return nil
+}
+
+func convertType3Page(ctx *model.Context, pageNr int, fonts map[int]*type3Font) (int, error) {
+	d, _, inhPAttrs, err := ctx.PageDict(pageNr, false)
+	if err != nil || d == nil || inhPAttrs.Resources == nil {
+		return 0, err
+	}
+
+	bb, err := ctx.PageContent(d)
+	if err == model.ErrNoContent {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	ops, err := model.ParseContentOps(bb)
+	if err != nil {
+		return 0, err
+	}
+
+	c := &type3Converter{
+		ctx:     ctx,
+		fonts:   fonts,
+		resDict: inhPAttrs.Resources,
+		widths:  &templateFiller{ctx: ctx, fonts: map[string]*templateFont{}},
+		gs:      type3State{hScale: 1},
+		tm:      matrix.IdentMatrix,
+		tlm:     matrix.IdentMatrix,
+	}
+
+	c.widths.fontDict, _ = ctx.DereferenceDict(inhPAttrs.Resources["Font"])
+
+	for _, op := range ops {
+		if err := c.convertOp(op); err != nil {
+			return 0, err
+		}
+	}
+
+	if c.glyphs == 0 {
+		return 0, nil
+	}
+
+	sd, _ := ctx.NewStreamDictForBuf(model.ContentOpsBytes(c.ops))
+	if err := sd.Encode(); err != nil {
+		return 0, err
+	}
+
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return 0, err
+	}
+
+	d["Contents"] = *ir
+	d.Update("Resources", inhPAttrs.Resources)
+
+	return c.glyphs, nil
+}
+
+// ConvertType3Fonts replaces text shown on selectedPages using Type3 fonts by vector outlines.
+// Each glyph procedure becomes a form XObject drawn in place of the glyph.
+// Text shown within form XObjects and annotation appearances remains untouched.
+// The result is a list of pages converted.
+func ConvertType3Fonts(ctx *model.Context, selectedPages types.IntSet) ([]string, error) {
+	fonts := map[int]*type3Font{}
+
+	var pageNrs []int
+	for i, v := range selectedPages {
+		if v {
+			pageNrs = append(pageNrs, i)
+		}
+	}
+	if selectedPages == nil {
+		for i := 1; i <= ctx.PageCount; i++ {
+			pageNrs = append(pageNrs, i)
+		}
+	}
+	sort.Ints(pageNrs)
+
+	var ss []string
+
+	for _, i := range pageNrs {
+		n, err := convertType3Page(ctx, i, fonts)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			ss = append(ss, fmt.Sprintf("page %d: converted %d glyphs", i, n))
+		}
+	}
+
+	return ss, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+)
+
+func TestRectangleContains(t *testing.T) {
+	r := *NewRectangle(10, 20, 110, 220)
+
+	tests := []struct {
+		name     string
+		p        Point
+		expected bool
+	}{
+		{"center", Point{60, 120}, true},
+		{"lower left corner", Point{10, 20}, true},
+		{"upper right corner", Point{110, 220}, true},
+		{"above lower left y", Point{60, 21}, true},
+		{"below", Point{60, 19}, false},
+		{"above", Point{60, 221}, false},
+		{"left", Point{9, 120}, false},
+		{"right", Point{111, 120}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := r.Contains(test.p); actual != test.expected {
+				t.Errorf("got %t; want %t", actual, test.expected)
+			}
+		})
+	}
+}
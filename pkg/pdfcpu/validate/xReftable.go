@@ -249,6 +249,29 @@ func validateViewerPreferences(xRefTable *model.XRefTable, rootDict types.Dict,
 	}
 
 	_, err = validateNameEntry(xRefTable, d, dictName, "ViewArea", OPTIONAL, model.V14, nil)
+	if err != nil {
+		return err
+	}
+
+	sinceVersion = model.V14
+	if xRefTable.ValidationMode == model.ValidationRelaxed {
+		sinceVersion = model.V10
+	}
+	validate = func(s string) bool {
+		return types.MemberOf(s, []string{"MediaBox", "CropBox", "BleedBox", "TrimBox", "ArtBox"})
+	}
+	for _, k := range []string{"PrintArea", "PrintClip"} {
+		if _, err = validateNameEntry(xRefTable, d, dictName, k, OPTIONAL, sinceVersion, validate); err != nil {
+			return err
+		}
+	}
+
+	sinceVersion = model.V16
+	if xRefTable.ValidationMode == model.ValidationRelaxed {
+		sinceVersion = model.V10
+	}
+	validate = func(s string) bool { return types.MemberOf(s, []string{"None", "AppDefault"}) }
+	_, err = validateNameEntry(xRefTable, d, dictName, "PrintScaling", OPTIONAL, sinceVersion, validate)
 
 	return err
 }
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+)
+
+// The version gated features taken into account.
+const (
+	FeatureObjectStreams = "object streams"
+	FeatureXRefStreams   = "xref streams"
+	FeatureTransparency  = "transparency"
+	FeatureJPX           = "JPX images"
+	Feature3D            = "3D annotations"
+	FeatureAES128        = "AES-128 encryption"
+	FeatureAES256        = "AES-256 encryption"
+)
+
+// featureVersions maps features to the PDF version introducing them.
+// AES-256 (security handler revision 5/6) was introduced by Adobe Extension Level 3 to PDF 1.7.
+var featureVersions = map[string]model.Version{
+	FeatureTransparency:  model.V14,
+	FeatureObjectStreams: model.V15,
+	FeatureXRefStreams:   model.V15,
+	FeatureJPX:           model.V15,
+	Feature3D:            model.V16,
+	FeatureAES128:        model.V16,
+	FeatureAES256:        model.V17,
+}
+
+// VersionFeature represents a version gated feature used by a PDF file.
+type VersionFeature struct {
+	Name    string
+	Version model.Version // the PDF version introducing this feature
+	Count   int           // number of objects using this feature, 0 for file level features
+}
+
+// VersionReport compares the PDF version declared by a PDF file to the minimum version required by the features it uses.
+type VersionReport struct {
+	Declared model.Version
+	Required model.Version
+	Features []VersionFeature // sorted by version, then by name
+}
+
+// UnderDeclared returns true if the file uses features not available in its declared version.
+func (vr VersionReport) UnderDeclared() bool {
+	return vr.Required > vr.Declared
+}
+
+// List returns a report of vr.
+func (vr VersionReport) List() []string {
+	ss := []string{
+		fmt.Sprintf("declared version: %s", vr.Declared),
+		fmt.Sprintf("required version: %s", vr.Required),
+	}
+
+	for _, f := range vr.Features {
+		s := fmt.Sprintf("%s: %s", f.Version, f.Name)
+		if f.Count > 0 {
+			s += fmt.Sprintf(" (%d objects)", f.Count)
+		}
+		ss = append(ss, s)
+	}
+
+	switch {
+	case vr.UnderDeclared():
+		ss = append(ss, fmt.Sprintf("under-declared: uses %s features", vr.Required))
+	case vr.Required < vr.Declared:
+		ss = append(ss, fmt.Sprintf("over-declared: scanned features allow %s", vr.Required))
+	}
+
+	return ss
+}
+
+// blendModeUsed returns true if o names a blend mode other than Normal.
+func blendModeUsed(o types.Object) bool {
+	switch o := o.(type) {
+	case types.Name:
+		return o != "Normal" && o != "Compatible"
+	case types.Array:
+		for _, o1 := range o {
+			if blendModeUsed(o1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usesTransparency returns true if d is a graphics state parameter dict, a group attributes dict or an image dict using transparency.
+func usesTransparency(xRefTable *model.XRefTable, d types.Dict) bool {
+	if s := d.NameEntry("S"); s != nil && *s == "Transparency" {
+		return true
+	}
+
+	if o, found := d.Find("SMask"); found {
+		if n, ok := o.(types.Name); !ok || n != "None" {
+			return true
+		}
+	}
+
+	for _, k := range []string{"CA", "ca"} {
+		o, err := xRefTable.Dereference(d[k])
+		if err != nil {
+			continue
+		}
+		if f, ok := number(o); ok && f < 1 {
+			return true
+		}
+	}
+
+	if o, found := d.Find("BM"); found {
+		o, err := xRefTable.Dereference(o)
+		return err == nil && blendModeUsed(o)
+	}
+
+	return false
+}
+
+// usesJPX returns true if sd is JPX encoded.
+func usesJPX(sd types.StreamDict) bool {
+	for _, f := range sd.FilterPipeline {
+		if f.Name == "JPXDecode" {
+			return true
+		}
+	}
+	return false
+}
+
+// objectFeatures returns the version gated features used by object o.
+func objectFeatures(xRefTable *model.XRefTable, o types.Object) []string {
+	var ff []string
+
+	switch o := o.(type) {
+
+	case types.StreamDict:
+		if usesJPX(o) {
+			ff = append(ff, FeatureJPX)
+		}
+		if st := o.Subtype(); st != nil && (*st == "Image" || *st == "Form") && usesTransparency(xRefTable, o.Dict) {
+			ff = append(ff, FeatureTransparency)
+		}
+
+	case types.Dict:
+		if st := o.Subtype(); st != nil && *st == "3D" {
+			ff = append(ff, Feature3D)
+		}
+		if t := o.Type(); t == nil || *t == "ExtGState" || *t == "Group" {
+			if usesTransparency(xRefTable, o) {
+				ff = append(ff, FeatureTransparency)
+			}
+		}
+	}
+
+	return ff
+}
+
+// fileFeatures returns the version gated features of the file structure and the security handler of ctx.
+func fileFeatures(ctx *model.Context) []string {
+	var ff []string
+
+	if ctx.Read.UsingObjectStreams {
+		ff = append(ff, FeatureObjectStreams)
+	}
+	if ctx.Read.UsingXRefStreams {
+		ff = append(ff, FeatureXRefStreams)
+	}
+
+	if ctx.E != nil {
+		switch {
+		case ctx.E.V == 5:
+			ff = append(ff, FeatureAES256)
+		case ctx.AES4Strings || ctx.AES4Streams:
+			ff = append(ff, FeatureAES128)
+		}
+	}
+
+	return ff
+}
+
+// VersionFeatures scans ctx for version gated features like object streams, AES encryption, transparency, JPX images and 3D annotations
+// and compares the minimum PDF version these features require to the declared version.
+// All objects in use get scanned including unreferenced ones.
+// The required version is 1.0 for files using none of the scanned features.
+func VersionFeatures(ctx *model.Context) *VersionReport {
+	counts := map[string]int{}
+	for _, f := range fileFeatures(ctx) {
+		counts[f] = 0
+	}
+
+	for _, entry := range ctx.Table {
+		if entry == nil || entry.Free || entry.Object == nil {
+			continue
+		}
+		for _, f := range objectFeatures(ctx.XRefTable, entry.Object) {
+			counts[f]++
+		}
+	}
+
+	vr := &VersionReport{Declared: ctx.Version(), Required: model.V10}
+
+	for f, c := range counts {
+		v := featureVersions[f]
+		vr.Features = append(vr.Features, VersionFeature{Name: f, Version: v, Count: c})
+		if v > vr.Required {
+			vr.Required = v
+		}
+	}
+
+	sort.Slice(vr.Features, func(i, j int) bool {
+		fi, fj := vr.Features[i], vr.Features[j]
+		if fi.Version != fj.Version {
+			return fi.Version < fj.Version
+		}
+		return fi.Name < fj.Name
+	})
+
+	return vr
+}
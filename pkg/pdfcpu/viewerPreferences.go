@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// ParsePrintPreferences parses a print preferences command string into an internal structure.
+// "scaling:none, area:crop, clip:crop"
+func ParsePrintPreferences(s string) (*model.PrintPreferences, error) {
+
+	if s == "" {
+		return nil, errors.New("pdfcpu: missing print preferences string")
+	}
+
+	pp := &model.PrintPreferences{}
+
+	for _, s := range strings.Split(s, ",") {
+
+		ss := strings.Split(s, ":")
+		if len(ss) != 2 {
+			return nil, errors.New("pdfcpu: Invalid print preferences string. Please consult pdfcpu help viewerpref")
+		}
+
+		paramPrefix := strings.TrimSpace(ss[0])
+		paramValueStr := strings.TrimSpace(ss[1])
+
+		if err := model.PrintPreferencesParamMap.Handle(paramPrefix, paramValueStr, pp); err != nil {
+			return nil, err
+		}
+	}
+
+	return pp, nil
+}
+
+func viewerPreferencesDict(ctx *model.Context, create bool) (types.Dict, error) {
+	rootDict, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	o, found := rootDict.Find("ViewerPreferences")
+	if !found {
+		if !create {
+			return nil, nil
+		}
+		d := types.NewDict()
+		rootDict.Insert("ViewerPreferences", d)
+		return d, nil
+	}
+
+	d, err := ctx.DereferenceDict(o)
+	if err != nil || d == nil {
+		return nil, errors.New("pdfcpu: corrupt ViewerPreferences dict")
+	}
+
+	return d, nil
+}
+
+// ViewerPreferences returns a list of all viewer preferences.
+func ViewerPreferences(ctx *model.Context) ([]string, error) {
+	d, err := viewerPreferencesDict(ctx, false)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	var ss []string
+	for k, v := range d {
+		o, err := ctx.Dereference(v)
+		if err != nil {
+			return nil, err
+		}
+		s := "null"
+		if o != nil {
+			s = o.PDFString()
+		}
+		ss = append(ss, fmt.Sprintf("%s: %s", k, s))
+	}
+	sort.Strings(ss)
+
+	return ss, nil
+}
+
+// SetPrintPreferences sets the print related viewer preferences of pp.
+func SetPrintPreferences(ctx *model.Context, pp *model.PrintPreferences) error {
+	if pp == nil || (pp.Scaling == "" && pp.Area == "" && pp.Clip == "") {
+		return errors.New("pdfcpu: missing print preferences")
+	}
+
+	d, err := viewerPreferencesDict(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	if pp.Scaling != "" {
+		d.Update("PrintScaling", types.Name(pp.Scaling))
+	}
+
+	if pp.Area != "" {
+		d.Update("PrintArea", types.Name(pp.Area))
+	}
+
+	if pp.Clip != "" {
+		d.Update("PrintClip", types.Name(pp.Clip))
+	}
+
+	// PrintArea, PrintClip since V1.4, PrintScaling since V1.6
+	v := model.V14
+	if pp.Scaling != "" {
+		v = model.V16
+	}
+	if ctx.Version() < v {
+		ctx.EnsureVersionForWriting()
+	}
+
+	return nil
+}
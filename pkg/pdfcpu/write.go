@@ -154,6 +154,10 @@ func prepareContextForWriting(ctx *model.Context) error {
 		return err
 	}
 
+	if err := applyMetadataCompression(ctx); err != nil {
+		return err
+	}
+
 	return handleEncryption(ctx)
 }
 
@@ -239,6 +243,13 @@ func writePages(ctx *model.Context, rootDict types.Dict) error {
 		return errors.New("pdfcpu: writePages: missing indirect obj for pages dict")
 	}
 
+	// TRIM, REMOVEPAGES modify the page tree during writing.
+	if ctx.BalancePageTree && len(ctx.Write.SelectedPages) == 0 {
+		if err := ctx.RebalancePageTree(ctx.PageTreeBranchingFactor); err != nil {
+			return err
+		}
+	}
+
 	// Embed all page tree objects into objects stream.
 	ctx.Write.WriteToObjectStream = true
 
@@ -947,9 +958,9 @@ func updateEncryption(ctx *model.Context) error {
 
 func handleEncryption(ctx *model.Context) error {
 
-	if ctx.Cmd == model.ENCRYPT || ctx.Cmd == model.DECRYPT {
+	if ctx.Cmd == model.ENCRYPT || ctx.Cmd == model.DECRYPT || ctx.Cmd == model.CLEARRESTRICTIONS {
 
-		if ctx.Cmd == model.DECRYPT {
+		if ctx.Cmd == model.DECRYPT || ctx.Cmd == model.CLEARRESTRICTIONS {
 
 			// Remove encryption.
 			ctx.EncKey = nil
@@ -0,0 +1,176 @@
+/*
+Copyright 2023 The pdfcpu Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pdfcpu
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"regexp"
+
+	"github.com/ex-preman/pdfcpu/pkg/filter"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/model"
+	"github.com/ex-preman/pdfcpu/pkg/pdfcpu/types"
+	"github.com/pkg/errors"
+)
+
+// xmpThumbnails matches an existing xmp:Thumbnails property including its deprecated xap: form.
+var xmpThumbnails = regexp.MustCompile(`(?s)<(xmp|xap):Thumbnails>.*?</(xmp|xap):Thumbnails>|<(xmp|xap):Thumbnails\s*/>`)
+
+// xmpEmptyDescription matches an rdf:Description left empty by removing a thumbnail.
+// Descriptions holding properties as attributes are left alone.
+var xmpEmptyDescription = regexp.MustCompile(`<rdf:Description[^>]*xmlns:xmpGImg=[^>]*>\s*</rdf:Description>\s*`)
+
+// xmpAbout matches the subject of an rdf:Description, all descriptions of an XMP packet share the same subject.
+var xmpAbout = regexp.MustCompile(`rdf:about\s*=\s*("[^"]*"|'[^']*')`)
+
+const xmpPacket = `<?xpacket begin="%s" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+%s</rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+// thumbnailJPEG returns the image read from rd as JPEG along with its dimensions.
+// Images other than JPEG get converted.
+func thumbnailJPEG(rd io.Reader) ([]byte, int, int, error) {
+	bb, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(bb))
+	if err != nil {
+		return nil, 0, 0, errors.Errorf("pdfcpu: thumbnail: %v", err)
+	}
+
+	if format != "jpeg" {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpeg.DefaultQuality}); err != nil {
+			return nil, 0, 0, err
+		}
+		bb = buf.Bytes()
+	}
+
+	r := img.Bounds()
+
+	return bb, r.Dx(), r.Dy(), nil
+}
+
+// xmpThumbnailDescription returns an rdf:Description holding the JPEG bb as xmp:Thumbnails, see XMP Specification Part 2, 1.2.2.
+func xmpThumbnailDescription(about string, bb []byte, w, h int) string {
+	return fmt.Sprintf(`<rdf:Description rdf:about=%s xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmlns:xmpGImg="http://ns.adobe.com/xap/1.0/g/img/">
+<xmp:Thumbnails>
+<rdf:Alt>
+<rdf:li rdf:parseType="Resource">
+<xmpGImg:width>%d</xmpGImg:width>
+<xmpGImg:height>%d</xmpGImg:height>
+<xmpGImg:format>JPEG</xmpGImg:format>
+<xmpGImg:image>%s</xmpGImg:image>
+</rdf:li>
+</rdf:Alt>
+</xmp:Thumbnails>
+</rdf:Description>
+`, about, w, h, base64.StdEncoding.EncodeToString(bb))
+}
+
+// catalogMetadata returns the decoded document metadata stream or nil.
+func catalogMetadata(ctx *model.Context, root types.Dict) (*types.StreamDict, error) {
+	o, found := root.Find("Metadata")
+	if !found || o == nil {
+		return nil, nil
+	}
+	sd, _, err := ctx.DereferenceStreamDict(o)
+	if err != nil || sd == nil {
+		return nil, err
+	}
+	if err := sd.Decode(); err != nil {
+		if err == filter.ErrUnsupportedFilter {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return sd, nil
+}
+
+// SetXMPThumbnail sets the image read from rd as document thumbnail (xmp:Thumbnails) of the XMP document metadata.
+// The image is embedded base64 encoded as JPEG, other image formats get converted.
+// An existing XMP thumbnail gets replaced, missing document metadata gets created.
+func SetXMPThumbnail(ctx *model.Context, rd io.Reader) error {
+	bb, w, h, err := thumbnailJPEG(rd)
+	if err != nil {
+		return err
+	}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	sd, err := catalogMetadata(ctx, root)
+	if err != nil {
+		return err
+	}
+
+	var xmp []byte
+	if sd != nil {
+		xmp = xmpThumbnails.ReplaceAll(sd.Content, nil)
+		xmp = xmpEmptyDescription.ReplaceAll(xmp, nil)
+		i := bytes.LastIndex(xmp, []byte("</rdf:RDF>"))
+		if i < 0 {
+			xmp = nil
+		} else {
+			about := `""`
+			if m := xmpAbout.FindSubmatch(xmp); m != nil {
+				about = string(m[1])
+			}
+			desc := xmpThumbnailDescription(about, bb, w, h)
+			xmp = append(xmp[:i:i], append([]byte(desc), xmp[i:]...)...)
+		}
+	}
+	if xmp == nil {
+		xmp = []byte(fmt.Sprintf(xmpPacket, "\ufeff", xmpThumbnailDescription(`""`, bb, w, h)))
+	}
+
+	sd1 := types.StreamDict{Dict: types.NewDict(), Content: xmp}
+	sd1.InsertName("Type", "Metadata")
+	sd1.InsertName("Subtype", "XML")
+	if err := sd1.Encode(); err != nil {
+		return err
+	}
+
+	ctx.EnsureVersionForWriting()
+
+	if ir, ok := root["Metadata"].(types.IndirectRef); ok && sd != nil {
+		if entry, found := ctx.FindTableEntryForIndRef(&ir); found {
+			entry.Object = sd1
+			return nil
+		}
+	}
+
+	ir, err := ctx.IndRefForNewObject(sd1)
+	if err != nil {
+		return err
+	}
+	root["Metadata"] = *ir
+
+	return nil
+}